@@ -0,0 +1,446 @@
+// Package ackermann implements a car-like base, with one or more drive motors and front wheels
+// steered together by a servo or a motor.
+//
+// Such a base cannot turn in place: it can only follow arcs no tighter than its minimum turning
+// radius, which it reports through KinematicConstraints so that motionplan.DubinsFromBase can set
+// up motionplan's Dubins RRT to plan paths it is able to drive. Spin is carried out as a multi-point turn.
+package ackermann
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("ackermann")
+
+const (
+	defaultServoCenterDeg   = 90
+	defaultSteeringMotorRPM = 60
+	// defaultMaxTurnArcDeg is how far the heading may change in each leg of a multi-point turn.
+	defaultMaxTurnArcDeg = 45
+)
+
+// AttrConfig is how you configure an ackermann base. Exactly one of steering_servo and
+// steering_motor must be set. Positive steering angles turn the base to the left.
+type AttrConfig struct {
+	WidthMM              int      `json:"width_mm"`
+	WheelbaseMM          int      `json:"wheelbase_mm"`
+	WheelCircumferenceMM int      `json:"wheel_circumference_mm"`
+	MaxSteeringAngleDeg  float64  `json:"max_steering_angle_degs"`
+	MinTurningRadiusMM   float64  `json:"min_turning_radius_mm,omitempty"`
+	DriveMotors          []string `json:"drive_motors"`
+
+	SteeringServo string `json:"steering_servo,omitempty"`
+	// ServoCenterDeg is the servo angle which points the wheels straight ahead.
+	ServoCenterDeg *float64 `json:"steering_servo_center_degs,omitempty"`
+	// InvertSteering is set when increasing the steering servo or motor position turns right.
+	InvertSteering bool `json:"invert_steering,omitempty"`
+
+	SteeringMotor string `json:"steering_motor,omitempty"`
+	// SteeringMotorRevsPerDeg is how many revolutions of the steering motor turn the wheels by a
+	// degree, counting from the motor's zero position with the wheels straight ahead.
+	SteeringMotorRevsPerDeg float64 `json:"steering_motor_revolutions_per_deg,omitempty"`
+	SteeringMotorRPM        float64 `json:"steering_motor_rpm,omitempty"`
+
+	MaxTurnArcDeg float64 `json:"max_turn_arc_degs,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *AttrConfig) Validate(path string) ([]string, error) {
+	if cfg.WidthMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "width_mm")
+	}
+	if cfg.WheelbaseMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheelbase_mm")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+	if cfg.MaxSteeringAngleDeg <= 0 || cfg.MaxSteeringAngleDeg >= 90 {
+		return nil, utils.NewConfigValidationError(path,
+			errors.New("max_steering_angle_degs must be between 0 and 90 degrees"))
+	}
+	if cfg.MinTurningRadiusMM < 0 || cfg.MaxTurnArcDeg < 0 || cfg.SteeringMotorRPM < 0 {
+		return nil, utils.NewConfigValidationError(path,
+			errors.New("min_turning_radius_mm, max_turn_arc_degs and steering_motor_rpm cannot be negative"))
+	}
+	if len(cfg.DriveMotors) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "drive_motors")
+	}
+
+	deps := append([]string{}, cfg.DriveMotors...)
+	switch {
+	case cfg.SteeringServo != "" && cfg.SteeringMotor != "":
+		return nil, utils.NewConfigValidationError(path, errors.New("only one of steering_servo and steering_motor can be set"))
+	case cfg.SteeringServo != "":
+		deps = append(deps, cfg.SteeringServo)
+	case cfg.SteeringMotor != "":
+		if cfg.SteeringMotorRevsPerDeg == 0 {
+			return nil, utils.NewConfigValidationFieldRequiredError(path, "steering_motor_revolutions_per_deg")
+		}
+		deps = append(deps, cfg.SteeringMotor)
+	default:
+		return nil, utils.NewConfigValidationError(path, errors.New("one of steering_servo and steering_motor must be set"))
+	}
+	return deps, nil
+}
+
+func init() {
+	registry.RegisterComponent(base.Subtype, modelname, registry.Component{
+		Constructor: func(
+			ctx context.Context, deps registry.Dependencies, cfg config.Component, logger golog.Logger,
+		) (interface{}, error) {
+			return CreateAckermannBase(deps, cfg, logger)
+		},
+	})
+	config.RegisterComponentAttributeMapConverter(
+		base.Subtype,
+		modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&AttrConfig{})
+}
+
+var (
+	_ = base.ConstrainedBase(&ackermannBase{})
+	_ = base.LocalBase(&ackermannBase{})
+)
+
+// steering points the front wheels at an angle in degrees, positive to the left.
+type steering interface {
+	steer(ctx context.Context, angleDeg float64) error
+	stop(ctx context.Context) error
+}
+
+type servoSteering struct {
+	servo  servo.Servo
+	center float64
+	sign   float64
+}
+
+func (s *servoSteering) steer(ctx context.Context, angleDeg float64) error {
+	pos := math.Round(s.center + s.sign*angleDeg)
+	if pos < 0 || pos > 180 {
+		return errors.Errorf("steering angle %.1f needs an out of range servo angle of %.0f", angleDeg, pos)
+	}
+	return s.servo.Move(ctx, uint32(pos), nil)
+}
+
+func (s *servoSteering) stop(ctx context.Context) error {
+	return s.servo.Stop(ctx, nil)
+}
+
+type motorSteering struct {
+	motor      motor.Motor
+	revsPerDeg float64
+	rpm        float64
+}
+
+func (s *motorSteering) steer(ctx context.Context, angleDeg float64) error {
+	return s.motor.GoTo(ctx, s.rpm, angleDeg*s.revsPerDeg, nil)
+}
+
+func (s *motorSteering) stop(ctx context.Context) error {
+	return s.motor.Stop(ctx, nil)
+}
+
+type ackermannBase struct {
+	generic.Unimplemented
+	widthMm              int
+	wheelbaseMm          float64
+	wheelCircumferenceMm float64
+	maxSteeringAngleDeg  float64
+	maxTurnArcDeg        float64
+
+	driveMotors []motor.Motor
+	steering    steering
+
+	opMgr  operation.SingleOperationManager
+	logger golog.Logger
+}
+
+// CreateAckermannBase returns a new ackermann base defined by the given config.
+func CreateAckermannBase(deps registry.Dependencies, cfg config.Component, logger golog.Logger) (base.LocalBase, error) {
+	attr, ok := cfg.ConvertedAttributes.(*AttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(attr, cfg.ConvertedAttributes)
+	}
+
+	b := &ackermannBase{
+		widthMm:              attr.WidthMM,
+		wheelbaseMm:          float64(attr.WheelbaseMM),
+		wheelCircumferenceMm: float64(attr.WheelCircumferenceMM),
+		maxSteeringAngleDeg:  attr.MaxSteeringAngleDeg,
+		maxTurnArcDeg:        attr.MaxTurnArcDeg,
+		logger:               logger,
+	}
+	if b.maxTurnArcDeg == 0 {
+		b.maxTurnArcDeg = defaultMaxTurnArcDeg
+	}
+	// A minimum turning radius wider than the steering allows, for instance to keep the base from
+	// tipping over at speed, limits the steering angle used.
+	if attr.MinTurningRadiusMM > 0 {
+		b.maxSteeringAngleDeg = math.Min(b.maxSteeringAngleDeg, rdkutils.RadToDeg(math.Atan(b.wheelbaseMm/attr.MinTurningRadiusMM)))
+	}
+
+	for _, name := range attr.DriveMotors {
+		m, err := motor.FromDependencies(deps, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no drive motor named (%s)", name)
+		}
+		b.driveMotors = append(b.driveMotors, m)
+	}
+
+	sign := 1.0
+	if attr.InvertSteering {
+		sign = -1
+	}
+	if attr.SteeringServo != "" {
+		s, err := servo.FromDependencies(deps, attr.SteeringServo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no steering servo named (%s)", attr.SteeringServo)
+		}
+		center := float64(defaultServoCenterDeg)
+		if attr.ServoCenterDeg != nil {
+			center = *attr.ServoCenterDeg
+		}
+		b.steering = &servoSteering{servo: s, center: center, sign: sign}
+	} else {
+		m, err := motor.FromDependencies(deps, attr.SteeringMotor)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no steering motor named (%s)", attr.SteeringMotor)
+		}
+		rpm := attr.SteeringMotorRPM
+		if rpm == 0 {
+			rpm = defaultSteeringMotorRPM
+		}
+		b.steering = &motorSteering{motor: m, revsPerDeg: sign * attr.SteeringMotorRevsPerDeg, rpm: rpm}
+	}
+
+	return b, nil
+}
+
+// KinematicConstraints returns the limits on the motion of the base.
+func (b *ackermannBase) KinematicConstraints(ctx context.Context) (base.KinematicConstraints, error) {
+	return base.KinematicConstraints{
+		WheelbaseMM:         b.wheelbaseMm,
+		MaxSteeringAngleDeg: b.maxSteeringAngleDeg,
+		MinTurningRadiusMM:  b.minTurningRadius(),
+	}, nil
+}
+
+func (b *ackermannBase) minTurningRadius() float64 {
+	return b.wheelbaseMm / math.Tan(rdkutils.DegToRad(b.maxSteeringAngleDeg))
+}
+
+// MoveStraight straightens the wheels and drives forward or backwards a given distance at a given speed.
+func (b *ackermannBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	b.logger.Debugf("received a MoveStraight with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
+
+	if math.Abs(mmPerSec) < 0.0001 || distanceMm == 0 {
+		return b.Stop(ctx, nil)
+	}
+	return b.drive(ctx, 0, float64(distanceMm), mmPerSec)
+}
+
+// Spin turns the base by a given angle at a given angular speed with a multi-point turn: it
+// alternates driving forwards and backwards on full lock, steering the opposite way each time,
+// so that every leg turns it the same way while it stays close to where it started.
+func (b *ackermannBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	b.logger.Debugf("received a Spin with angleDeg:%.2f, degsPerSec:%.2f", angleDeg, degsPerSec)
+
+	if math.Abs(degsPerSec) < 0.0001 || angleDeg == 0 {
+		return b.Stop(ctx, nil)
+	}
+
+	legs := turnLegs(angleDeg*sign(degsPerSec), b.maxTurnArcDeg)
+	radius := b.minTurningRadius()
+	speed := radius * rdkutils.DegToRad(math.Abs(degsPerSec))
+	for i, leg := range legs {
+		// forward legs steer into the turn, backward legs steer out of it
+		direction := 1.0
+		if i%2 == 1 {
+			direction = -1
+		}
+		arc := radius * rdkutils.DegToRad(math.Abs(leg))
+		if err := b.drive(ctx, direction*sign(leg)*b.maxSteeringAngleDeg, direction*arc, speed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// turnLegs splits a turn into legs of equal heading change no larger than maxArc degrees.
+func turnLegs(angleDeg, maxArcDeg float64) []float64 {
+	n := int(math.Ceil(math.Abs(angleDeg) / maxArcDeg))
+	legs := make([]float64, n)
+	for i := range legs {
+		legs[i] = angleDeg / float64(n)
+	}
+	return legs
+}
+
+// drive sets the steering angle and then runs the drive motors for the given distance, in mm, at
+// the given speed, in mm/sec.
+func (b *ackermannBase) drive(ctx context.Context, steeringDeg, distanceMm, mmPerSec float64) error {
+	if err := b.steering.steer(ctx, steeringDeg); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+
+	rpm := mmPerSec / b.wheelCircumferenceMm * 60
+	revolutions := distanceMm / b.wheelCircumferenceMm
+	fs := []rdkutils.SimpleFunc{}
+	for _, m := range b.driveMotors {
+		m := m
+		fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, rpm, revolutions, nil) })
+	}
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// SetVelocity drives at the given forward (Y) velocity in mm/sec, steering to turn at the given
+// angular (Z) velocity in degs/sec as closely as the steering allows.
+func (b *ackermannBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+
+	b.logger.Debugf(
+		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f (mmPerSec), angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	if math.Abs(linear.Y) < 0.0001 {
+		if math.Abs(angular.Z) > 0.0001 {
+			return multierr.Combine(errors.New("an ackermann base cannot turn without moving"), b.Stop(ctx, nil))
+		}
+		return b.Stop(ctx, nil)
+	}
+
+	steeringDeg := b.velocityMath(linear.Y, angular.Z)
+	if err := b.steering.steer(ctx, steeringDeg); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	rpm := linear.Y / b.wheelCircumferenceMm * 60
+	var err error
+	for _, m := range b.driveMotors {
+		err = multierr.Combine(err, m.GoFor(ctx, rpm, 0, nil))
+	}
+	if err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// velocityMath calculates the steering angle, in degrees, at which the base turns at the given
+// angular velocity in degs/sec when driving at the given speed in mm/sec, limited to full lock.
+func (b *ackermannBase) velocityMath(mmPerSec, degsPerSec float64) float64 {
+	angle := rdkutils.RadToDeg(math.Atan(b.wheelbaseMm * rdkutils.DegToRad(degsPerSec) / mmPerSec))
+	return math.Max(-b.maxSteeringAngleDeg, math.Min(angle, b.maxSteeringAngleDeg))
+}
+
+// SetPower runs the drive motors at the given forward (Y) power and steers by the given angular
+// (Z) power, where 1 is full lock to the left.
+func (b *ackermannBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+
+	b.logger.Debugf(
+		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f, angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	steer := math.Max(-1, math.Min(angular.Z, 1))
+	if err := b.steering.steer(ctx, steer*b.maxSteeringAngleDeg); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	var err error
+	for _, m := range b.driveMotors {
+		err = multierr.Combine(err, m.SetPower(ctx, linear.Y, extra))
+	}
+	if err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// DoCommand executes additional commands beyond the Base{} interface.
+func (b *ackermannBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case base.KinematicConstraintsCommand:
+		constraints, err := b.KinematicConstraints(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"wheelbase_mm":            constraints.WheelbaseMM,
+			"max_steering_angle_degs": constraints.MaxSteeringAngleDeg,
+			"min_turning_radius_mm":   constraints.MinTurningRadiusMM,
+		}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Stop stops the drive motors. The steering is left where it is.
+func (b *ackermannBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	var err error
+	for _, m := range b.driveMotors {
+		err = multierr.Combine(err, m.Stop(ctx, extra))
+	}
+	return err
+}
+
+func (b *ackermannBase) IsMoving(ctx context.Context) (bool, error) {
+	for _, m := range b.driveMotors {
+		isMoving, _, err := m.IsPowered(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		if isMoving {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Width returns the width of the base as configured by the user.
+func (b *ackermannBase) Width(ctx context.Context) (int, error) {
+	return b.widthMm, nil
+}
+
+// Close stops the base and its steering.
+func (b *ackermannBase) Close(ctx context.Context) error {
+	return multierr.Combine(b.Stop(ctx, nil), b.steering.stop(ctx))
+}
+
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,174 @@
+package ackermann
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/testutils/inject"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+type goFor struct {
+	rpm, revolutions float64
+}
+
+func newTestBase(t *testing.T) (*ackermannBase, *[]uint32, *[]goFor) {
+	t.Helper()
+	var angles []uint32
+	var moves []goFor
+	deps := registry.Dependencies{
+		motor.Named("drive"): &inject.Motor{
+			GoForFunc: func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+				moves = append(moves, goFor{rpm, revolutions})
+				return nil
+			},
+			StopFunc: func(ctx context.Context, extra map[string]interface{}) error {
+				return nil
+			},
+		},
+		servo.Named("steering"): &inject.Servo{
+			MoveFunc: func(ctx context.Context, angleDeg uint32, extra map[string]interface{}) error {
+				angles = append(angles, angleDeg)
+				return nil
+			},
+		},
+	}
+	attrs := &AttrConfig{
+		WidthMM:              200,
+		WheelbaseMM:          300,
+		WheelCircumferenceMM: 1000,
+		MaxSteeringAngleDeg:  45,
+		DriveMotors:          []string{"drive"},
+		SteeringServo:        "steering",
+	}
+	_, err := attrs.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	b, err := CreateAckermannBase(deps, config.Component{ConvertedAttributes: attrs}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return b.(*ackermannBase), &angles, &moves
+}
+
+func TestKinematics(t *testing.T) {
+	ctx := context.Background()
+	b, _, _ := newTestBase(t)
+
+	constraints, err := b.KinematicConstraints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, constraints.MinTurningRadiusMM, test.ShouldAlmostEqual, 300)
+	test.That(t, constraints.MaxSteeringAngleDeg, test.ShouldEqual, 45)
+
+	resp, err := b.DoCommand(ctx, map[string]interface{}{"command": "kinematic_constraints"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["min_turning_radius_mm"], test.ShouldAlmostEqual, 300)
+
+	// turning at 1 rad/sec while driving at 300 mm/sec needs a 300mm radius, which is full lock
+	test.That(t, b.velocityMath(300, rdkutils.RadToDeg(1)), test.ShouldAlmostEqual, 45)
+	test.That(t, b.velocityMath(300, -rdkutils.RadToDeg(10)), test.ShouldAlmostEqual, -45)
+	test.That(t, b.velocityMath(300, 0), test.ShouldAlmostEqual, 0)
+
+	test.That(t, turnLegs(100, 45), test.ShouldResemble, []float64{100.0 / 3, 100.0 / 3, 100.0 / 3})
+	test.That(t, turnLegs(-45, 45), test.ShouldResemble, []float64{-45})
+
+	// a minimum turning radius wider than the steering allows limits the steering
+	b.maxSteeringAngleDeg = rdkutils.RadToDeg(math.Atan(300.0 / 600))
+	constraints, err = b.KinematicConstraints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, constraints.MinTurningRadiusMM, test.ShouldAlmostEqual, 600)
+}
+
+func TestDubinsFromBase(t *testing.T) {
+	ctx := context.Background()
+	b, _, _ := newTestBase(t)
+
+	d, err := motionplan.DubinsFromBase(ctx, b, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Radius, test.ShouldAlmostEqual, 300)
+	test.That(t, d.PointSeparation, test.ShouldEqual, 10)
+
+	// the constraints are asked for through DoCommand when the base is wrapped, as it is in a robot
+	wrapped, err := base.WrapWithReconfigurable(b, base.Named("ackermann"))
+	test.That(t, err, test.ShouldBeNil)
+	d, err = motionplan.DubinsFromBase(ctx, wrapped.(base.Base), 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Radius, test.ShouldAlmostEqual, 300)
+
+	model, err := referenceframe.NewMobile2DFrame("ackermann", []referenceframe.Limit{
+		{Min: -10000, Max: 10000},
+		{Min: -10000, Max: 10000},
+	}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	planner, err := motionplan.NewDubinsRRTMotionPlanner(model, 1, golog.NewTestLogger(t), *d)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, planner.D.Radius, test.ShouldAlmostEqual, 300)
+}
+
+func TestMovement(t *testing.T) {
+	ctx := context.Background()
+	b, angles, moves := newTestBase(t)
+
+	test.That(t, b.MoveStraight(ctx, -500, 1000, nil), test.ShouldBeNil)
+	test.That(t, *angles, test.ShouldResemble, []uint32{90})
+	test.That(t, *moves, test.ShouldResemble, []goFor{{60, -0.5}})
+
+	// turning right faster than full lock allows is limited to full lock
+	*angles, *moves = nil, nil
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 1000}, r3.Vector{Z: -360}, nil), test.ShouldBeNil)
+	test.That(t, *angles, test.ShouldResemble, []uint32{45})
+	test.That(t, *moves, test.ShouldResemble, []goFor{{60, 0}})
+
+	err := b.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: 90}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot turn without moving")
+
+	// a 90 degree spin to the left is a forward leg on left lock and a backward leg on right lock
+	*angles, *moves = nil, nil
+	test.That(t, b.Spin(ctx, 90, 45, nil), test.ShouldBeNil)
+	test.That(t, *angles, test.ShouldResemble, []uint32{135, 45})
+	arc := 300 * math.Pi / 4 / 1000
+	rpm := 300 * math.Pi / 4 / 1000 * 60
+	test.That(t, len(*moves), test.ShouldEqual, 2)
+	test.That(t, (*moves)[0].rpm, test.ShouldAlmostEqual, rpm)
+	test.That(t, (*moves)[0].revolutions, test.ShouldAlmostEqual, arc)
+	test.That(t, (*moves)[1].rpm, test.ShouldAlmostEqual, rpm)
+	test.That(t, (*moves)[1].revolutions, test.ShouldAlmostEqual, -arc)
+}
+
+func TestValidate(t *testing.T) {
+	attrs := &AttrConfig{WidthMM: 200, WheelbaseMM: 300, WheelCircumferenceMM: 1000, MaxSteeringAngleDeg: 90}
+	_, err := attrs.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_steering_angle_degs")
+
+	attrs.MaxSteeringAngleDeg = 30
+	_, err = attrs.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "drive_motors")
+
+	attrs.DriveMotors = []string{"left", "right"}
+	_, err = attrs.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "one of steering_servo and steering_motor must be set")
+
+	attrs.SteeringMotor = "steering"
+	_, err = attrs.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "steering_motor_revolutions_per_deg")
+
+	attrs.SteeringMotorRevsPerDeg = 0.1
+	deps, err := attrs.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"left", "right", "steering"})
+}
@@ -357,3 +357,36 @@ func TestDoMove(t *testing.T) {
 		test.That(t, errors.Is(err, err1), test.ShouldBeTrue)
 	})
 }
+
+func TestKinematicConstraintsFromBase(t *testing.T) {
+	injectBase := &inject.Base{}
+	injectBase.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("no such command")
+	}
+	_, err := base.KinematicConstraintsFromBase(context.Background(), injectBase)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not report kinematic constraints")
+
+	injectBase.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		test.That(t, cmd["command"], test.ShouldEqual, base.KinematicConstraintsCommand)
+		return map[string]interface{}{"wheelbase_mm": 300.0}, nil
+	}
+	_, err = base.KinematicConstraintsFromBase(context.Background(), injectBase)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no minimum turning radius")
+
+	injectBase.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"wheelbase_mm":            300.0,
+			"max_steering_angle_degs": 45.0,
+			"min_turning_radius_mm":   300.0,
+		}, nil
+	}
+	constraints, err := base.KinematicConstraintsFromBase(context.Background(), injectBase)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, constraints, test.ShouldResemble, base.KinematicConstraints{
+		WheelbaseMM:         300,
+		MaxSteeringAngleDeg: 45,
+		MinTurningRadiusMM:  300,
+	})
+}
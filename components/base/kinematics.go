@@ -0,0 +1,61 @@
+package base
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// KinematicConstraintsCommand is the DoCommand command that a ConstrainedBase answers with its
+// KinematicConstraints, for when the base is reached through a wrapper or over the network.
+const KinematicConstraintsCommand = "kinematic_constraints"
+
+// KinematicConstraints describes the motions a base that cannot turn in place is capable of.
+type KinematicConstraints struct {
+	WheelbaseMM         float64 `json:"wheelbase_mm"`
+	MaxSteeringAngleDeg float64 `json:"max_steering_angle_degs"`
+	// MinTurningRadiusMM is the radius of the tightest circle the base can follow, which is the
+	// turning radius a Dubins planner needs.
+	MinTurningRadiusMM float64 `json:"min_turning_radius_mm"`
+}
+
+// A ConstrainedBase is a base that cannot move in arbitrary directions. It also answers
+// KinematicConstraintsCommand with its constraints.
+type ConstrainedBase interface {
+	Base
+	// KinematicConstraints returns the limits on the motion of the base.
+	KinematicConstraints(ctx context.Context) (KinematicConstraints, error)
+}
+
+// KinematicConstraintsFromBase returns the kinematic constraints of the base, asking it through
+// DoCommand if it is not a ConstrainedBase itself.
+func KinematicConstraintsFromBase(ctx context.Context, b Base) (KinematicConstraints, error) {
+	if cb, ok := b.(ConstrainedBase); ok {
+		return cb.KinematicConstraints(ctx)
+	}
+	resp, err := b.DoCommand(ctx, map[string]interface{}{"command": KinematicConstraintsCommand})
+	if err != nil {
+		return KinematicConstraints{}, errors.Wrap(err, "base does not report kinematic constraints")
+	}
+	var constraints KinematicConstraints
+	fields := map[string]*float64{
+		"wheelbase_mm":            &constraints.WheelbaseMM,
+		"max_steering_angle_degs": &constraints.MaxSteeringAngleDeg,
+		"min_turning_radius_mm":   &constraints.MinTurningRadiusMM,
+	}
+	for key, field := range fields {
+		value, ok := resp[key]
+		if !ok {
+			continue
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return KinematicConstraints{}, errors.Errorf("expected %s of kinematic constraints to be a number but got %T", key, value)
+		}
+		*field = f
+	}
+	if constraints.MinTurningRadiusMM <= 0 {
+		return KinematicConstraints{}, errors.New("base reported no minimum turning radius")
+	}
+	return constraints, nil
+}
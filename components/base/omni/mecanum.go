@@ -0,0 +1,106 @@
+package omni
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var mecanumModel = resource.NewDefaultModel("mecanum")
+
+// MecanumAttrConfig is how you configure a four wheeled mecanum base. The rollers are expected to
+// be in the usual X arrangement, where they form an X when the base is seen from above.
+type MecanumAttrConfig struct {
+	WidthMM              int    `json:"width_mm"`
+	WheelbaseMM          int    `json:"wheelbase_mm"`
+	WheelCircumferenceMM int    `json:"wheel_circumference_mm"`
+	FrontLeft            string `json:"front_left"`
+	FrontRight           string `json:"front_right"`
+	BackLeft             string `json:"back_left"`
+	BackRight            string `json:"back_right"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *MecanumAttrConfig) Validate(path string) ([]string, error) {
+	if cfg.WidthMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "width_mm")
+	}
+	if cfg.WheelbaseMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheelbase_mm")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+	if cfg.FrontLeft == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "front_left")
+	}
+	if cfg.FrontRight == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "front_right")
+	}
+	if cfg.BackLeft == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "back_left")
+	}
+	if cfg.BackRight == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "back_right")
+	}
+	return []string{cfg.FrontLeft, cfg.FrontRight, cfg.BackLeft, cfg.BackRight}, nil
+}
+
+func init() {
+	registry.RegisterComponent(base.Subtype, mecanumModel, registry.Component{
+		Constructor: func(
+			ctx context.Context, deps registry.Dependencies, cfg config.Component, logger golog.Logger,
+		) (interface{}, error) {
+			return CreateMecanumBase(deps, cfg, logger)
+		},
+	})
+	config.RegisterComponentAttributeMapConverter(
+		base.Subtype,
+		mecanumModel,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf MecanumAttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&MecanumAttrConfig{})
+}
+
+// CreateMecanumBase returns a new mecanum base defined by the given config.
+func CreateMecanumBase(deps registry.Dependencies, cfg config.Component, logger golog.Logger) (base.LocalBase, error) {
+	attr, ok := cfg.ConvertedAttributes.(*MecanumAttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(attr, cfg.ConvertedAttributes)
+	}
+
+	motors, err := motorsFromDependencies(deps, []string{attr.FrontLeft, attr.FrontRight, attr.BackLeft, attr.BackRight})
+	if err != nil {
+		return nil, err
+	}
+
+	// Turning moves each wheel by the sum of its distances from the center along both axes, and
+	// the rollers turn sideways motion into opposite rotations of diagonal neighbours.
+	k := float64(attr.WidthMM+attr.WheelbaseMM) / 2
+	coeffs := []r3.Vector{
+		{X: 1, Y: 1, Z: -k},
+		{X: -1, Y: 1, Z: k},
+		{X: -1, Y: 1, Z: -k},
+		{X: 1, Y: 1, Z: k},
+	}
+	b := &omniBase{
+		widthMm:              attr.WidthMM,
+		wheelCircumferenceMm: float64(attr.WheelCircumferenceMM),
+		rotationScale:        1 / k,
+		logger:               logger,
+	}
+	for i, m := range motors {
+		b.wheels = append(b.wheels, wheel{motor: m, coeff: coeffs[i]})
+	}
+	return b, nil
+}
@@ -0,0 +1,89 @@
+package omni
+
+import (
+	"context"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var omniModel = resource.NewDefaultModel("omni")
+
+// OmniAttrConfig is how you configure an omni wheeled base, such as a three wheeled kiwi drive.
+// The wheels are evenly spaced around the center of the base, each driving tangentially, and are
+// listed counter-clockwise as seen from above starting with the wheel at the front. Running a
+// motor forwards should turn the base counter-clockwise.
+type OmniAttrConfig struct {
+	RadiusMM             int      `json:"radius_mm"`
+	WheelCircumferenceMM int      `json:"wheel_circumference_mm"`
+	Motors               []string `json:"motors"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *OmniAttrConfig) Validate(path string) ([]string, error) {
+	if cfg.RadiusMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "radius_mm")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+	if len(cfg.Motors) < 3 {
+		return nil, utils.NewConfigValidationError(path, errors.Errorf("need at least 3 motors, not %d", len(cfg.Motors)))
+	}
+	return cfg.Motors, nil
+}
+
+func init() {
+	registry.RegisterComponent(base.Subtype, omniModel, registry.Component{
+		Constructor: func(
+			ctx context.Context, deps registry.Dependencies, cfg config.Component, logger golog.Logger,
+		) (interface{}, error) {
+			return CreateOmniBase(deps, cfg, logger)
+		},
+	})
+	config.RegisterComponentAttributeMapConverter(
+		base.Subtype,
+		omniModel,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf OmniAttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&OmniAttrConfig{})
+}
+
+// CreateOmniBase returns a new omni wheeled base defined by the given config.
+func CreateOmniBase(deps registry.Dependencies, cfg config.Component, logger golog.Logger) (base.LocalBase, error) {
+	attr, ok := cfg.ConvertedAttributes.(*OmniAttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(attr, cfg.ConvertedAttributes)
+	}
+
+	motors, err := motorsFromDependencies(deps, attr.Motors)
+	if err != nil {
+		return nil, err
+	}
+
+	radius := float64(attr.RadiusMM)
+	b := &omniBase{
+		widthMm:              2 * attr.RadiusMM,
+		wheelCircumferenceMm: float64(attr.WheelCircumferenceMM),
+		rotationScale:        1 / radius,
+		logger:               logger,
+	}
+	for i, m := range motors {
+		// each wheel drives along the tangent of the circle it sits on, counter-clockwise
+		angle := math.Pi/2 + 2*math.Pi*float64(i)/float64(len(motors))
+		sin, cos := math.Sincos(angle)
+		b.wheels = append(b.wheels, wheel{motor: m, coeff: r3.Vector{X: -sin, Y: cos, Z: radius}})
+	}
+	return b, nil
+}
@@ -0,0 +1,263 @@
+// Package omni implements holonomic bases, whose wheels let them move sideways as well as forwards
+// and turn: a four wheeled mecanum base and an omni wheeled base.
+//
+// Velocities follow the base frame used by the other bases: +Y is forwards, +X is to the right and
+// positive angular velocities about Z turn the base counter-clockwise (to the left).
+package omni
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/registry"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// A Strafer is a base that can move sideways without turning.
+type Strafer interface {
+	// Strafe moves the base sideways a given distance at a given speed. Positive distances move the
+	// base to the right. If a distance or speed of zero is given, the base will stop.
+	// This method blocks until completed or cancelled
+	Strafe(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error
+}
+
+var (
+	_ = base.LocalBase(&omniBase{})
+	_ = Strafer(&omniBase{})
+)
+
+// wheel is a motor driving a wheel whose rim speed, in mm/sec, is the dot product of its
+// coefficients with the base's lateral speed in mm/sec, forward speed in mm/sec and angular speed
+// in radians/sec.
+type wheel struct {
+	motor motor.Motor
+	coeff r3.Vector
+}
+
+// omniBase drives a holonomic base given the kinematics of each of its wheels.
+type omniBase struct {
+	generic.Unimplemented
+	wheels               []wheel
+	widthMm              int
+	wheelCircumferenceMm float64
+	// rotationScale turns an angular power into the same units as the linear powers, so that an
+	// angular power of 1 commands full power on the wheels only when turning.
+	rotationScale float64
+
+	opMgr  operation.SingleOperationManager
+	logger golog.Logger
+}
+
+func motorsFromDependencies(deps registry.Dependencies, names []string) ([]motor.Motor, error) {
+	motors := make([]motor.Motor, 0, len(names))
+	for _, name := range names {
+		m, err := motor.FromDependencies(deps, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no motor named (%s)", name)
+		}
+		motors = append(motors, m)
+	}
+	return motors, nil
+}
+
+// MoveStraight commands the base to drive forward or backwards a given distance at a given speed.
+func (b *omniBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	b.logger.Debugf("received a MoveStraight with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
+
+	if math.Abs(mmPerSec) < 0.0001 || distanceMm == 0 {
+		return b.Stop(ctx, nil)
+	}
+	return b.move(ctx, r3.Vector{Y: float64(distanceMm) * sign(mmPerSec)}, math.Abs(float64(distanceMm)/mmPerSec))
+}
+
+// Strafe commands the base to drive sideways a given distance at a given speed.
+func (b *omniBase) Strafe(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	b.logger.Debugf("received a Strafe with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
+
+	if math.Abs(mmPerSec) < 0.0001 || distanceMm == 0 {
+		return b.Stop(ctx, nil)
+	}
+	return b.move(ctx, r3.Vector{X: float64(distanceMm) * sign(mmPerSec)}, math.Abs(float64(distanceMm)/mmPerSec))
+}
+
+// Spin commands the base to turn about its center by a given angle at a given angular speed.
+func (b *omniBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	b.logger.Debugf("received a Spin with angleDeg:%.2f, degsPerSec:%.2f", angleDeg, degsPerSec)
+
+	if math.Abs(degsPerSec) < 0.0001 || angleDeg == 0 {
+		return b.Stop(ctx, nil)
+	}
+	return b.move(ctx, r3.Vector{Z: rdkutils.DegToRad(angleDeg) * sign(degsPerSec)}, math.Abs(angleDeg/degsPerSec))
+}
+
+// move runs every wheel the distance needed to displace the base by the given lateral and forward
+// mm and counter-clockwise radians, so that all of them finish after the given number of seconds.
+func (b *omniBase) move(ctx context.Context, displacement r3.Vector, seconds float64) error {
+	fs := []rdkutils.SimpleFunc{}
+	for _, w := range b.wheels {
+		m := w.motor
+		revolutions := w.coeff.Dot(displacement) / b.wheelCircumferenceMm
+		if math.Abs(revolutions) < 1e-6 {
+			fs = append(fs, func(ctx context.Context) error { return m.Stop(ctx, nil) })
+			continue
+		}
+		rpm := revolutions / seconds * 60
+		fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, rpm, math.Abs(revolutions), nil) })
+	}
+
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// SetVelocity commands the base to move at the given lateral (X) and forward (Y) velocities in
+// mm/sec while turning at the given angular (Z) velocity in degs/sec.
+func (b *omniBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+
+	b.logger.Debugf(
+		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f (mmPerSec), angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	rpms := b.velocityMath(linear.X, linear.Y, angular.Z)
+	fs := []rdkutils.SimpleFunc{}
+	for i, w := range b.wheels {
+		m, rpm := w.motor, rpms[i]
+		if math.Abs(rpm) < 1e-6 {
+			fs = append(fs, func(ctx context.Context) error { return m.Stop(ctx, nil) })
+			continue
+		}
+		fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, rpm, 0, nil) })
+	}
+
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// velocityMath calculates the rpm of each wheel from the lateral and forward velocities in mm/sec
+// and the angular velocity in degs/sec of the base.
+func (b *omniBase) velocityMath(lateral, forward, degsPerSec float64) []float64 {
+	v := r3.Vector{X: lateral, Y: forward, Z: rdkutils.DegToRad(degsPerSec)}
+	rpms := make([]float64, 0, len(b.wheels))
+	for _, w := range b.wheels {
+		rpms = append(rpms, w.coeff.Dot(v)/b.wheelCircumferenceMm*60)
+	}
+	return rpms
+}
+
+// SetPower commands the base motors to run at powers corresponding to the given lateral (X),
+// forward (Y) and angular (Z) powers, each between -1 and 1. When the combination asks more of a
+// wheel than full power, all wheels are scaled down together so the direction of motion is kept.
+func (b *omniBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+
+	b.logger.Debugf(
+		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f, angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	powers := b.powerMath(linear.X, linear.Y, angular.Z)
+
+	var err error
+	for i, w := range b.wheels {
+		err = multierr.Combine(err, w.motor.SetPower(ctx, powers[i], extra))
+	}
+	if err != nil {
+		return multierr.Combine(err, b.Stop(ctx, nil))
+	}
+	return nil
+}
+
+func (b *omniBase) powerMath(lateral, forward, angular float64) []float64 {
+	v := r3.Vector{X: lateral, Y: forward, Z: angular * b.rotationScale}
+	powers := make([]float64, 0, len(b.wheels))
+	largest := 1.0
+	for _, w := range b.wheels {
+		p := w.coeff.Dot(v)
+		largest = math.Max(largest, math.Abs(p))
+		powers = append(powers, p)
+	}
+	for i := range powers {
+		powers[i] /= largest
+	}
+	return powers
+}
+
+// DoCommand executes additional commands beyond the Base{} interface.
+func (b *omniBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "strafe":
+		distance, ok := cmd["distance_mm"].(float64)
+		if !ok {
+			return nil, errors.New("need a numeric distance_mm value for strafe")
+		}
+		speed, ok := cmd["mm_per_sec"].(float64)
+		if !ok {
+			return nil, errors.New("need a numeric mm_per_sec value for strafe")
+		}
+		return nil, b.Strafe(ctx, int(distance), speed, nil)
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Stop commands the base to stop moving.
+func (b *omniBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	var err error
+	for _, w := range b.wheels {
+		err = multierr.Combine(err, w.motor.Stop(ctx, extra))
+	}
+	return err
+}
+
+func (b *omniBase) IsMoving(ctx context.Context) (bool, error) {
+	for _, w := range b.wheels {
+		isMoving, _, err := w.motor.IsPowered(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		if isMoving {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Width returns the width of the base as configured by the user.
+func (b *omniBase) Width(ctx context.Context) (int, error) {
+	return b.widthMm, nil
+}
+
+// Close stops the base.
+func (b *omniBase) Close(ctx context.Context) error {
+	return b.Stop(ctx, nil)
+}
+
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,194 @@
+package omni
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/motor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+)
+
+func fakeMotorDependencies(t *testing.T, deps []string) registry.Dependencies {
+	t.Helper()
+	logger := golog.NewTestLogger(t)
+
+	result := make(registry.Dependencies)
+	for _, dep := range deps {
+		result[motor.Named(dep)] = &fake.Motor{
+			MaxRPM: 600,
+			Logger: logger,
+		}
+	}
+	return result
+}
+
+func newMecanum(t *testing.T) *omniBase {
+	t.Helper()
+	cfg := config.Component{
+		Name:  "test",
+		Type:  base.Subtype.ResourceSubtype,
+		Model: mecanumModel,
+		ConvertedAttributes: &MecanumAttrConfig{
+			WidthMM:              300,
+			WheelbaseMM:          200,
+			WheelCircumferenceMM: 1000,
+			FrontLeft:            "fl",
+			FrontRight:           "fr",
+			BackLeft:             "bl",
+			BackRight:            "br",
+		},
+	}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	motorDeps := fakeMotorDependencies(t, deps)
+
+	b, err := CreateMecanumBase(motorDeps, cfg, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return b.(*omniBase)
+}
+
+func TestMecanumMath(t *testing.T) {
+	b := newMecanum(t)
+
+	width, err := b.Width(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, width, test.ShouldEqual, 300)
+
+	// forward: every wheel forward at 60 rpm
+	test.That(t, b.velocityMath(0, 1000, 0), test.ShouldResemble, []float64{60, 60, 60, 60})
+
+	// strafing right: front left and back right forward, the other diagonal backwards
+	test.That(t, b.velocityMath(1000, 0, 0), test.ShouldResemble, []float64{60, -60, -60, 60})
+
+	// turning left: left wheels backwards, right wheels forwards
+	rpms := b.velocityMath(0, 0, 180)
+	// each wheel is 250mm from the center along the two axes
+	expected := 250 * math.Pi / 1000 * 60
+	test.That(t, rpms[0], test.ShouldAlmostEqual, -expected)
+	test.That(t, rpms[1], test.ShouldAlmostEqual, expected)
+	test.That(t, rpms[2], test.ShouldAlmostEqual, -expected)
+	test.That(t, rpms[3], test.ShouldAlmostEqual, expected)
+
+	// full power on two axes at once is scaled back so no wheel is over full power
+	powers := b.powerMath(1, 1, 0)
+	test.That(t, powers, test.ShouldResemble, []float64{1, 0, 0, 1})
+	powers = b.powerMath(0, 0, 1)
+	test.That(t, powers[0], test.ShouldAlmostEqual, -1)
+	test.That(t, powers[1], test.ShouldAlmostEqual, 1)
+	powers = b.powerMath(0, 1, 1)
+	test.That(t, powers[0], test.ShouldAlmostEqual, 0)
+	test.That(t, powers[1], test.ShouldAlmostEqual, 1)
+}
+
+func TestOmniMath(t *testing.T) {
+	cfg := config.Component{
+		Name:  "test",
+		Type:  base.Subtype.ResourceSubtype,
+		Model: omniModel,
+		ConvertedAttributes: &OmniAttrConfig{
+			RadiusMM:             100,
+			WheelCircumferenceMM: 1000,
+			Motors:               []string{"front", "back_left", "back_right"},
+		},
+	}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	lb, err := CreateOmniBase(fakeMotorDependencies(t, deps), cfg, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	b := lb.(*omniBase)
+
+	width, err := b.Width(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, width, test.ShouldEqual, 200)
+
+	// turning left runs every wheel forwards at the same speed
+	rpms := b.velocityMath(0, 0, 360)
+	for _, rpm := range rpms {
+		test.That(t, rpm, test.ShouldAlmostEqual, 2*math.Pi*100/1000*60)
+	}
+
+	// driving forwards leaves the front wheel idle
+	rpms = b.velocityMath(0, 1000, 0)
+	test.That(t, rpms[0], test.ShouldAlmostEqual, 0)
+	test.That(t, rpms[1], test.ShouldAlmostEqual, -60*math.Sqrt(3)/2)
+	test.That(t, rpms[2], test.ShouldAlmostEqual, 60*math.Sqrt(3)/2)
+
+	// strafing right runs the front wheel backwards
+	rpms = b.velocityMath(1000, 0, 0)
+	test.That(t, rpms[0], test.ShouldAlmostEqual, -60)
+	test.That(t, rpms[1], test.ShouldAlmostEqual, 30)
+	test.That(t, rpms[2], test.ShouldAlmostEqual, 30)
+}
+
+func TestMovement(t *testing.T) {
+	ctx := context.Background()
+	b := newMecanum(t)
+
+	t.Run("set velocity", func(t *testing.T) {
+		err := b.SetVelocity(ctx, r3.Vector{X: 1000}, r3.Vector{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		moving, err := b.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeTrue)
+
+		test.That(t, b.Stop(ctx, nil), test.ShouldBeNil)
+		moving, err = b.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("strafe", func(t *testing.T) {
+		_, err := b.DoCommand(ctx, map[string]interface{}{"command": "strafe"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		_, err = b.DoCommand(ctx, map[string]interface{}{"command": "strafe", "distance_mm": 10.0, "mm_per_sec": 100.0})
+		test.That(t, err, test.ShouldBeNil)
+		moving, err := b.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("straight and spin", func(t *testing.T) {
+		test.That(t, b.MoveStraight(ctx, 10, 100, nil), test.ShouldBeNil)
+		test.That(t, b.Spin(ctx, 5, 90, nil), test.ShouldBeNil)
+		test.That(t, b.MoveStraight(ctx, 0, 100, nil), test.ShouldBeNil)
+	})
+
+	_, err := b.DoCommand(ctx, map[string]interface{}{"command": "fly"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestValidate(t *testing.T) {
+	mecanum := &MecanumAttrConfig{}
+	_, err := mecanum.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "width_mm")
+
+	mecanum = &MecanumAttrConfig{WidthMM: 1, WheelbaseMM: 1, WheelCircumferenceMM: 1, FrontLeft: "fl", FrontRight: "fr", BackLeft: "bl"}
+	_, err = mecanum.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "back_right")
+
+	omni := &OmniAttrConfig{RadiusMM: 1, WheelCircumferenceMM: 1, Motors: []string{"a", "b"}}
+	_, err = omni.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 motors")
+
+	_, err = CreateOmniBase(registry.Dependencies{}, config.Component{
+		ConvertedAttributes: &OmniAttrConfig{RadiusMM: 1, WheelCircumferenceMM: 1, Motors: []string{"a", "b", "c"}},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = CreateMecanumBase(registry.Dependencies{}, config.Component{Model: resource.Model{Name: "mecanum"}}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,191 @@
+package board
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+)
+
+// ADS1x15 registers and config register fields.
+const (
+	ads1x15DefaultAddr    = 0x48
+	ads1x15ConversionReg  = 0x00
+	ads1x15ConfigReg      = 0x01
+	ads1x15StartBit       = 1 << 15 // starts a conversion when written, set when done when read
+	ads1x15MuxShift       = 12
+	ads1x15PGAShift       = 9
+	ads1x15SingleShotBit  = 1 << 8
+	ads1x15DataRateShift  = 5
+	ads1x15ComparatorOff  = 0x03
+	ads1x15ConversionPoll = 5
+)
+
+// ads1x15Muxes are the input multiplexer settings, by pin. Pins are single ended inputs, or
+// pairs of inputs for differential ones.
+var ads1x15Muxes = map[string]uint16{
+	"0-1": 0, "0-3": 1, "1-3": 2, "2-3": 3,
+	"0": 4, "1": 5, "2": 6, "3": 7,
+}
+
+// ads1x15Gains are the settings of the programmable gain amplifier, with a full scale range of
+// 4.096V divided by the gain.
+var ads1x15Gains = []float64{2. / 3, 1, 2, 4, 8, 16}
+
+// An ads1x15Model is a member of the ADS1x15 family.
+type ads1x15Model struct {
+	name            string
+	bits            uint
+	dataRates       []int // samples per second, by setting
+	defaultDataRate int
+}
+
+var (
+	ads1015 = ads1x15Model{
+		name:            AnalogReaderTypeADS1015,
+		bits:            12,
+		dataRates:       []int{128, 250, 490, 920, 1600, 2400, 3300},
+		defaultDataRate: 1600,
+	}
+	ads1115 = ads1x15Model{
+		name:            AnalogReaderTypeADS1115,
+		bits:            16,
+		dataRates:       []int{8, 16, 32, 64, 128, 250, 475, 860},
+		defaultDataRate: 128,
+	}
+)
+
+// ADS1x15AnalogReader implements a board.AnalogReader using a TI ADS1015 or ADS1115 ADC via
+// I2C. Each read is a single shot conversion, returning a signed count of the model's resolution
+// relative to the full scale range set by the gain.
+type ADS1x15AnalogReader struct {
+	Bus  I2C
+	Addr byte
+
+	model  ads1x15Model
+	config uint16
+	// conversionTime is how long a conversion at the data rate takes.
+	conversionTime time.Duration
+}
+
+func newADS1x15AnalogReader(model ads1x15Model) AnalogReaderConstructor {
+	return func(ctx context.Context, buses AnalogReaderBuses, cfg AnalogConfig, logger golog.Logger) (AnalogReader, error) {
+		bus, ok := buses.I2CByName(cfg.I2CBus)
+		if !ok {
+			return nil, errors.Errorf("can't find I2C bus (%s) requested by AnalogReader", cfg.I2CBus)
+		}
+		addr := cfg.I2CAddr
+		if addr == 0 {
+			addr = ads1x15DefaultAddr
+		}
+		return NewADS1x15AnalogReader(bus, byte(addr), model.name, cfg.Pin, cfg.Gain, cfg.DataRate)
+	}
+}
+
+// NewADS1x15AnalogReader returns a reader of the given pin of an ADS1015 or ADS1115 on the I2C
+// bus at the address. The pin is one of 0 to 3, or the differential pairs 0-1, 0-3, 1-3 and 2-3.
+// A gain of zero means 1, for a full scale range of 4.096V, and a data rate of zero means the
+// chip's default.
+func NewADS1x15AnalogReader(bus I2C, addr byte, typ, pin string, gain float64, dataRate int) (*ADS1x15AnalogReader, error) {
+	var model ads1x15Model
+	switch typ {
+	case AnalogReaderTypeADS1015:
+		model = ads1015
+	case AnalogReaderTypeADS1115:
+		model = ads1115
+	default:
+		return nil, errors.Errorf("unknown ADS1x15 type %q", typ)
+	}
+
+	mux, ok := ads1x15Muxes[pin]
+	if !ok {
+		return nil, errors.Errorf("bad analog pin (%s), expected one of 0-3 or a pair 0-1, 0-3, 1-3, 2-3", pin)
+	}
+
+	if gain == 0 {
+		gain = 1
+	}
+	pga := -1
+	for i, g := range ads1x15Gains {
+		if math.Abs(g-gain) < 0.01 {
+			pga = i
+		}
+	}
+	if pga < 0 {
+		return nil, errors.Errorf("bad gain %v, expected one of 2/3, 1, 2, 4, 8 or 16", gain)
+	}
+
+	if dataRate == 0 {
+		dataRate = model.defaultDataRate
+	}
+	dr := -1
+	for i, rate := range model.dataRates {
+		if rate == dataRate {
+			dr = i
+		}
+	}
+	if dr < 0 {
+		return nil, errors.Errorf("bad data rate %d for %s, expected one of %v", dataRate, model.name, model.dataRates)
+	}
+
+	return &ADS1x15AnalogReader{
+		Bus:  bus,
+		Addr: addr,
+
+		model: model,
+		config: ads1x15StartBit |
+			mux<<ads1x15MuxShift |
+			uint16(pga)<<ads1x15PGAShift |
+			ads1x15SingleShotBit |
+			uint16(dr)<<ads1x15DataRateShift |
+			ads1x15ComparatorOff,
+		conversionTime: time.Second / time.Duration(dataRate),
+	}, nil
+}
+
+func (ar *ADS1x15AnalogReader) Read(ctx context.Context, extra map[string]interface{}) (value int, err error) {
+	handle, err := ar.Bus.OpenHandle(ar.Addr)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		err = multierr.Combine(err, handle.Close())
+	}()
+
+	config := make([]byte, 2)
+	binary.BigEndian.PutUint16(config, ar.config)
+	if err := handle.WriteBlockData(ctx, ads1x15ConfigReg, config); err != nil {
+		return 0, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !goutils.SelectContextOrWait(ctx, ar.conversionTime) {
+			return 0, ctx.Err()
+		}
+		status, err := handle.ReadBlockData(ctx, ads1x15ConfigReg, 2)
+		if err != nil {
+			return 0, err
+		}
+		if len(status) == 2 && binary.BigEndian.Uint16(status)&ads1x15StartBit != 0 {
+			break
+		}
+		if attempt == ads1x15ConversionPoll {
+			return 0, errors.Errorf("%s conversion did not finish", ar.model.name)
+		}
+	}
+
+	data, err := handle.ReadBlockData(ctx, ads1x15ConversionReg, 2)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 2 {
+		return 0, errors.Errorf("expected 2 bytes from %s, got %d", ar.model.name, len(data))
+	}
+	// 12 bit conversions are left justified
+	return int(int16(binary.BigEndian.Uint16(data))) >> (16 - ar.model.bits), nil
+}
@@ -0,0 +1,147 @@
+package board_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+type testBuses struct {
+	i2cs map[string]board.I2C
+	spis map[string]board.SPI
+}
+
+func (b *testBuses) SPIByName(name string) (board.SPI, bool) {
+	s, ok := b.spis[name]
+	return s, ok
+}
+
+func (b *testBuses) I2CByName(name string) (board.I2C, bool) {
+	i, ok := b.i2cs[name]
+	return i, ok
+}
+
+func TestADS1x15AnalogReader(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	ads1115 := simdevice.NewADS1115()
+	ads1015 := simdevice.NewADS1015()
+	bus.Attach(0x48, ads1115)
+	bus.Attach(0x49, ads1015)
+	buses := &testBuses{i2cs: map[string]board.I2C{"i2c1": bus}}
+	for input, volts := range []float64{1, 1.5, 0.5, 3.3} {
+		ads1115.SetVoltage(input, volts)
+		ads1015.SetVoltage(input, volts)
+	}
+
+	t.Run("single ended", func(t *testing.T) {
+		reader, err := board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Name: "a", Type: "ads1115", I2CBus: "i2c1", Pin: "0",
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		value, err := reader.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, 8000)
+		// single shot, gain 1, 128 samples per second, comparator off
+		test.That(t, ads1115.Config(), test.ShouldEqual, 0xC383)
+		test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+	})
+
+	t.Run("differential with gain", func(t *testing.T) {
+		reader, err := board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Name: "a", Type: "ads1115", I2CBus: "i2c1", Pin: "0-1", Gain: 2, DataRate: 860,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		value, err := reader.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, -8000)
+		test.That(t, ads1115.Config(), test.ShouldEqual, 0x85E3)
+
+		reader, err = board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Name: "a", Type: "ads1115", I2CBus: "i2c1", Pin: "2-3", Gain: 16, DataRate: 860,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		value, err = reader.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		// clipped at the full scale range
+		test.That(t, value, test.ShouldEqual, -32768)
+	})
+
+	t.Run("ads1015", func(t *testing.T) {
+		reader, err := board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Name: "a", Type: "ads1015", I2CBus: "i2c1", I2CAddr: 0x49, Pin: "3", Gain: 2. / 3,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		value, err := reader.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, 1100)
+	})
+
+	t.Run("smoothed", func(t *testing.T) {
+		reader, err := board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Name: "a", Type: "ads1115", I2CBus: "i2c1", Pin: "0", DataRate: 860,
+			AverageOverMillis: 10, SamplesPerSecond: 100,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		smoother, ok := reader.(*board.AnalogSmoother)
+		test.That(t, ok, test.ShouldBeTrue)
+		smoother.Close()
+	})
+
+	t.Run("bad configs", func(t *testing.T) {
+		for _, tc := range []struct {
+			cfg board.AnalogConfig
+			err string
+		}{
+			{board.AnalogConfig{Type: "ads1115", I2CBus: "nope", Pin: "0"}, "can't find I2C bus (nope)"},
+			{board.AnalogConfig{Type: "ads1115", I2CBus: "i2c1", Pin: "1-2"}, "bad analog pin (1-2)"},
+			{board.AnalogConfig{Type: "ads1115", I2CBus: "i2c1", Pin: "0", Gain: 3}, "bad gain 3"},
+			{board.AnalogConfig{Type: "ads1015", I2CBus: "i2c1", Pin: "0", DataRate: 860}, "bad data rate 860 for ads1015"},
+			{board.AnalogConfig{Type: "ads1234", I2CBus: "i2c1", Pin: "0"}, `unknown analog reader type "ads1234"`},
+		} {
+			_, err := board.NewAnalogReader(ctx, buses, tc.cfg, logger)
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		}
+
+		reader, err := board.NewAnalogReader(ctx, buses, board.AnalogConfig{
+			Type: "ads1115", I2CBus: "i2c1", I2CAddr: 0x4A, Pin: "0",
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = reader.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeError, "no I2C device at address 0x4a")
+		test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+	})
+}
+
+func TestRegisterAnalogReader(t *testing.T) {
+	cfg := board.AnalogConfig{Name: "a", Type: "test-adc"}
+	err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown analog reader type "test-adc"`)
+
+	analog := &board.MCP3008AnalogReader{}
+	board.RegisterAnalogReader("test-adc", func(
+		ctx context.Context,
+		buses board.AnalogReaderBuses,
+		cfg board.AnalogConfig,
+		logger golog.Logger,
+	) (board.AnalogReader, error) {
+		return analog, nil
+	})
+	defer board.DeregisterAnalogReader("test-adc")
+	test.That(t, cfg.Validate("path"), test.ShouldBeNil)
+	reader, err := board.NewAnalogReader(context.Background(), &testBuses{}, cfg, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reader, test.ShouldEqual, analog)
+
+	test.That(t, func() {
+		board.RegisterAnalogReader(board.AnalogReaderTypeADS1115, nil)
+	}, test.ShouldPanic)
+}
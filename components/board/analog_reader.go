@@ -0,0 +1,98 @@
+package board
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+)
+
+// AnalogReaderBuses looks up the buses external ADCs are attached to. A LocalBoard is one.
+type AnalogReaderBuses interface {
+	SPIByName(name string) (SPI, bool)
+	I2CByName(name string) (I2C, bool)
+}
+
+// An AnalogReaderConstructor returns a reader for the ADC described by the config.
+type AnalogReaderConstructor func(
+	ctx context.Context,
+	buses AnalogReaderBuses,
+	cfg AnalogConfig,
+	logger golog.Logger,
+) (AnalogReader, error)
+
+// The types of external ADCs included.
+const (
+	AnalogReaderTypeMCP3008 = "mcp3008"
+	AnalogReaderTypeADS1015 = "ads1015"
+	AnalogReaderTypeADS1115 = "ads1115"
+)
+
+var (
+	analogReaderConstructorsMu sync.RWMutex
+	analogReaderConstructors   = map[string]AnalogReaderConstructor{
+		AnalogReaderTypeMCP3008: newMCP3008AnalogReader,
+		AnalogReaderTypeADS1015: newADS1x15AnalogReader(ads1015),
+		AnalogReaderTypeADS1115: newADS1x15AnalogReader(ads1115),
+	}
+)
+
+// RegisterAnalogReader registers a type of external ADC that analog readers can be configured
+// with.
+func RegisterAnalogReader(typ string, constructor AnalogReaderConstructor) {
+	analogReaderConstructorsMu.Lock()
+	defer analogReaderConstructorsMu.Unlock()
+	if _, ok := analogReaderConstructors[typ]; ok {
+		panic(errors.Errorf("trying to register two analog readers of the same type %q", typ))
+	}
+	analogReaderConstructors[typ] = constructor
+}
+
+func lookupAnalogReader(typ string) (AnalogReaderConstructor, bool) {
+	analogReaderConstructorsMu.RLock()
+	defer analogReaderConstructorsMu.RUnlock()
+	constructor, ok := analogReaderConstructors[typ]
+	return constructor, ok
+}
+
+// NewAnalogReader returns a reader for the external ADC described by the config, on one of the
+// given buses, smoothed if the config asks for it.
+func NewAnalogReader(
+	ctx context.Context,
+	buses AnalogReaderBuses,
+	cfg AnalogConfig,
+	logger golog.Logger,
+) (AnalogReader, error) {
+	typ := cfg.Type
+	if typ == "" {
+		typ = AnalogReaderTypeMCP3008
+	}
+	constructor, ok := lookupAnalogReader(typ)
+	if !ok {
+		return nil, errors.Errorf("unknown analog reader type %q", typ)
+	}
+	ar, err := constructor(ctx, buses, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return SmoothAnalogReader(ar, cfg, logger), nil
+}
+
+func newMCP3008AnalogReader(
+	ctx context.Context,
+	buses AnalogReaderBuses,
+	cfg AnalogConfig,
+	logger golog.Logger,
+) (AnalogReader, error) {
+	channel, err := strconv.Atoi(cfg.Pin)
+	if err != nil {
+		return nil, errors.Errorf("bad analog pin (%s)", cfg.Pin)
+	}
+	bus, ok := buses.SPIByName(cfg.SPIBus)
+	if !ok {
+		return nil, errors.Errorf("can't find SPI bus (%s) requested by AnalogReader", cfg.SPIBus)
+	}
+	return &MCP3008AnalogReader{channel, bus, cfg.ChipSelect}, nil
+}
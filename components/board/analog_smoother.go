@@ -106,6 +106,10 @@ func (as *AnalogSmoother) Start(ctx context.Context) {
 					break
 				}
 				as.logger.Infow("error reading analog", "error", err)
+				// readers that honor the context fail once it is done, so don't spin on them
+				if !goutils.SelectContextOrWait(ctx, time.Duration(nanosBetween)) {
+					return
+				}
 				continue
 			}
 
@@ -45,12 +45,19 @@ func init() {
 			return board.Status(ctx, nil)
 		},
 		RegisterRPCService: func(ctx context.Context, rpcServer rpc.Server, subtypeSvc subtype.Service) error {
-			return rpcServer.RegisterServiceServer(
+			server := NewServer(subtypeSvc)
+			if err := rpcServer.RegisterServiceServer(
 				ctx,
 				&pb.BoardService_ServiceDesc,
-				NewServer(subtypeSvc),
+				server,
 				pb.RegisterBoardServiceHandlerFromEndpoint,
-			)
+			); err != nil {
+				return err
+			}
+			if err := describeTickStreamService(); err != nil {
+				return err
+			}
+			return rpcServer.RegisterServiceServer(ctx, &TickStreamServiceDesc, server)
 		},
 		RPCServiceDesc: &pb.BoardService_ServiceDesc,
 		RPCClient: func(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) interface{} {
@@ -178,6 +185,8 @@ type PostProcessor func(raw int64) int64
 var (
 	_ = Board(&reconfigurableBoard{})
 	_ = LocalBoard(&reconfigurableLocalBoard{})
+	_ = SerialBoard(&reconfigurableBoard{})
+	_ = CANBoard(&reconfigurableBoard{})
 	_ = resource.Reconfigurable(&reconfigurableBoard{})
 	_ = resource.Reconfigurable(&reconfigurableLocalBoard{})
 	_ = viamutils.ContextCloser(&reconfigurableLocalBoard{})
@@ -213,6 +222,8 @@ type reconfigurableBoard struct {
 	actual   Board
 	analogs  map[string]*reconfigurableAnalogReader
 	digitals map[string]*reconfigurableDigitalInterrupt
+	serials  map[string]*reconfigurableSerial
+	cans     map[string]*reconfigurableCAN
 }
 
 func (r *reconfigurableBoard) Name() resource.Name {
@@ -251,6 +262,76 @@ func (r *reconfigurableBoard) DigitalInterruptByName(name string) (DigitalInterr
 	return d, ok
 }
 
+func (r *reconfigurableBoard) SerialByName(name string) (Serial, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.serials[name]
+	if ok {
+		return s, true
+	}
+	sb, ok := r.actual.(SerialBoard)
+	if !ok {
+		return nil, false
+	}
+	actualPart, ok := sb.SerialByName(name)
+	if !ok {
+		return nil, false
+	}
+	r.serials[name] = &reconfigurableSerial{actual: actualPart}
+	return r.serials[name], true
+}
+
+func (r *reconfigurableBoard) SerialNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sb, ok := r.actual.(SerialBoard)
+	if !ok {
+		return nil
+	}
+	return sb.SerialNames()
+}
+
+func (r *reconfigurableBoard) CANByName(name string) (CAN, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cans[name]
+	if ok {
+		return c, true
+	}
+	cb, ok := r.actual.(CANBoard)
+	if !ok {
+		return nil, false
+	}
+	actualPart, ok := cb.CANByName(name)
+	if !ok {
+		return nil, false
+	}
+	r.cans[name] = &reconfigurableCAN{actual: actualPart}
+	return r.cans[name], true
+}
+
+func (r *reconfigurableBoard) CANNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.actual.(CANBoard)
+	if !ok {
+		return nil
+	}
+	return cb.CANNames()
+}
+
+// streamTicks streams from the board directly if it can, without holding the lock for the
+// lifetime of the stream.
+func (r *reconfigurableBoard) streamTicks(ctx context.Context, opts TickStreamOptions, send func(TickEvent) error) error {
+	r.mu.RLock()
+	ts, ok := r.actual.(tickStreamer)
+	r.mu.RUnlock()
+	if ok {
+		return ts.streamTicks(ctx, opts, send)
+	}
+	return streamTicksLocally(ctx, r, opts, send)
+}
+
 func (r *reconfigurableBoard) GPIOPinByName(name string) (GPIOPin, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -313,6 +394,8 @@ func (r *reconfigurableBoard) reconfigure(ctx context.Context, newBoard resource
 
 	var oldAnalogReaderNames map[string]struct{}
 	var oldDigitalInterruptNames map[string]struct{}
+	var oldSerialNames map[string]struct{}
+	var oldCANNames map[string]struct{}
 
 	if len(r.analogs) != 0 {
 		oldAnalogReaderNames = make(map[string]struct{}, len(r.analogs))
@@ -326,6 +409,18 @@ func (r *reconfigurableBoard) reconfigure(ctx context.Context, newBoard resource
 			oldDigitalInterruptNames[name] = struct{}{}
 		}
 	}
+	if len(r.serials) != 0 {
+		oldSerialNames = make(map[string]struct{}, len(r.serials))
+		for name := range r.serials {
+			oldSerialNames[name] = struct{}{}
+		}
+	}
+	if len(r.cans) != 0 {
+		oldCANNames = make(map[string]struct{}, len(r.cans))
+		for name := range r.cans {
+			oldCANNames[name] = struct{}{}
+		}
+	}
 
 	for name, newPart := range actual.analogs {
 		oldPart, ok := r.analogs[name]
@@ -345,6 +440,24 @@ func (r *reconfigurableBoard) reconfigure(ctx context.Context, newBoard resource
 		}
 		r.digitals[name] = newPart
 	}
+	for name, newPart := range actual.serials {
+		oldPart, ok := r.serials[name]
+		delete(oldSerialNames, name)
+		if ok {
+			oldPart.reconfigure(ctx, newPart)
+			continue
+		}
+		r.serials[name] = newPart
+	}
+	for name, newPart := range actual.cans {
+		oldPart, ok := r.cans[name]
+		delete(oldCANNames, name)
+		if ok {
+			oldPart.reconfigure(ctx, newPart)
+			continue
+		}
+		r.cans[name] = newPart
+	}
 
 	for name := range oldAnalogReaderNames {
 		delete(r.analogs, name)
@@ -352,6 +465,12 @@ func (r *reconfigurableBoard) reconfigure(ctx context.Context, newBoard resource
 	for name := range oldDigitalInterruptNames {
 		delete(r.digitals, name)
 	}
+	for name := range oldSerialNames {
+		delete(r.serials, name)
+	}
+	for name := range oldCANNames {
+		delete(r.cans, name)
+	}
 
 	r.actual = actual.actual
 	return nil
@@ -479,6 +598,8 @@ func WrapWithReconfigurable(r interface{}, name resource.Name) (resource.Reconfi
 		actual:   board,
 		analogs:  map[string]*reconfigurableAnalogReader{},
 		digitals: map[string]*reconfigurableDigitalInterrupt{},
+		serials:  map[string]*reconfigurableSerial{},
+		cans:     map[string]*reconfigurableCAN{},
 	}
 
 	for _, name := range rb.actual.AnalogReaderNames() {
@@ -495,6 +616,24 @@ func WrapWithReconfigurable(r interface{}, name resource.Name) (resource.Reconfi
 		}
 		rb.digitals[name] = &reconfigurableDigitalInterrupt{actual: actualPart}
 	}
+	if sb, ok := rb.actual.(SerialBoard); ok && !rb.actual.ModelAttributes().Remote {
+		for _, name := range sb.SerialNames() {
+			actualPart, ok := sb.SerialByName(name)
+			if !ok {
+				continue
+			}
+			rb.serials[name] = &reconfigurableSerial{actual: actualPart}
+		}
+	}
+	if cb, ok := rb.actual.(CANBoard); ok {
+		for _, name := range cb.CANNames() {
+			actualPart, ok := cb.CANByName(name)
+			if !ok {
+				continue
+			}
+			rb.cans[name] = &reconfigurableCAN{actual: actualPart}
+		}
+	}
 
 	localBoard, ok := r.(LocalBoard)
 	if !ok {
@@ -579,6 +718,66 @@ func (r *reconfigurableI2C) OpenHandle(addr byte) (I2CHandle, error) {
 	return r.actual.OpenHandle(addr)
 }
 
+type reconfigurableSerial struct {
+	mu     sync.RWMutex
+	actual Serial
+}
+
+func (r *reconfigurableSerial) ProxyFor() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actual
+}
+
+func (r *reconfigurableSerial) reconfigure(ctx context.Context, newSerial Serial) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := newSerial.(*reconfigurableSerial)
+	if !ok {
+		panic(utils.NewUnexpectedTypeError(r, newSerial))
+	}
+	if err := viamutils.TryClose(ctx, r.actual); err != nil {
+		golog.Global().Errorw("error closing old", "error", err)
+	}
+	r.actual = actual.actual
+}
+
+func (r *reconfigurableSerial) OpenHandle() (SerialHandle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actual.OpenHandle()
+}
+
+type reconfigurableCAN struct {
+	mu     sync.RWMutex
+	actual CAN
+}
+
+func (r *reconfigurableCAN) ProxyFor() interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actual
+}
+
+func (r *reconfigurableCAN) reconfigure(ctx context.Context, newCAN CAN) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := newCAN.(*reconfigurableCAN)
+	if !ok {
+		panic(utils.NewUnexpectedTypeError(r, newCAN))
+	}
+	if err := viamutils.TryClose(ctx, r.actual); err != nil {
+		golog.Global().Errorw("error closing old", "error", err)
+	}
+	r.actual = actual.actual
+}
+
+func (r *reconfigurableCAN) OpenHandle(filters ...CANFilter) (CANHandle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actual.OpenHandle(filters...)
+}
+
 type reconfigurableAnalogReader struct {
 	mu     sync.RWMutex
 	actual AnalogReader
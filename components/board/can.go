@@ -0,0 +1,113 @@
+package board
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// CANConfig enumerates a specific, shareable CAN bus.
+type CANConfig struct {
+	Name string `json:"name"`
+	// Interface is the network interface of the bus, e.g. can0 or vcan0.
+	Interface string `json:"interface"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *CANConfig) Validate(path string) error {
+	if config.Name == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if config.Interface == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "interface")
+	}
+	return nil
+}
+
+// The maximum payload of a classic CAN frame.
+const canMaxDataLength = 8
+
+// A CANFrame is a single classic CAN frame.
+type CANFrame struct {
+	// ID is the 11-bit standard or 29-bit extended identifier.
+	ID       uint32
+	Extended bool
+	Remote   bool
+	Data     []byte
+}
+
+// Validate ensures the frame can be put on the bus.
+func (f *CANFrame) Validate() error {
+	if len(f.Data) > canMaxDataLength {
+		return errors.Errorf("CAN frames carry at most %d bytes of data, got %d", canMaxDataLength, len(f.Data))
+	}
+	if !f.Extended && f.ID > 0x7FF {
+		return errors.Errorf("standard CAN id %#x does not fit in 11 bits", f.ID)
+	}
+	if f.ID > 0x1FFFFFFF {
+		return errors.Errorf("extended CAN id %#x does not fit in 29 bits", f.ID)
+	}
+	return nil
+}
+
+// A CANFilter selects the frames a handle receives. A frame matches when its identifier
+// agrees with ID on every bit set in Mask and its Extended flag is the same as the filter's.
+type CANFilter struct {
+	ID       uint32
+	Mask     uint32
+	Extended bool
+	// Invert selects the frames that do not match instead.
+	Invert bool
+}
+
+// Matches returns whether the filter selects the given frame.
+func (f CANFilter) Matches(frame CANFrame) bool {
+	matches := frame.ID&f.Mask == f.ID&f.Mask && frame.Extended == f.Extended
+	return matches != f.Invert
+}
+
+// CANFiltersMatch returns whether any of the filters select the frame the way a CAN bus would,
+// with no filters selecting everything.
+func CANFiltersMatch(filters []CANFilter, frame CANFrame) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f.Matches(frame) {
+			return true
+		}
+	}
+	return false
+}
+
+// A CANBoard is a board with CAN buses that can be requested by name. CAN buses are
+// only available on local boards.
+type CANBoard interface {
+	// CANByName returns a CAN bus by name.
+	CANByName(name string) (CAN, bool)
+
+	// CANNames returns the names of all known CAN buses.
+	CANNames() []string
+}
+
+// CAN represents a shareable CAN bus on the board. Unlike other buses, any number of handles
+// can be open at once; each one sees every frame on the bus selected by its filters, except
+// for the frames it sent itself.
+type CAN interface {
+	// OpenHandle returns a handle receiving the frames selected by any of the filters, or
+	// all frames if there are none. It MUST be closed when done.
+	OpenHandle(filters ...CANFilter) (CANHandle, error)
+}
+
+// CANHandle sends and receives frames on a CAN bus.
+type CANHandle interface {
+	// Send puts a frame on the bus.
+	Send(ctx context.Context, frame CANFrame) error
+
+	// Receive blocks until a frame selected by the handle's filters arrives or the context is done.
+	Receive(ctx context.Context) (CANFrame, error)
+
+	// Close closes the handle.
+	Close() error
+}
@@ -0,0 +1,163 @@
+//go:build linux
+
+package board
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"golang.org/x/sys/unix"
+)
+
+// canReadPollInterval is how long a receive waits for a frame before checking whether its
+// context is done.
+const canReadPollInterval = 100 * time.Millisecond
+
+// rawCANFrame mirrors struct can_frame from linux/can.h.
+type rawCANFrame struct {
+	ID   uint32
+	Len  uint8
+	_    [3]uint8
+	Data [canMaxDataLength]uint8
+}
+
+const rawCANFrameSize = int(unsafe.Sizeof(rawCANFrame{}))
+
+// socketCAN is a CAN bus on the local machine reached through the SocketCAN raw protocol.
+type socketCAN struct {
+	iface string
+}
+
+// NewSocketCAN returns a shareable CAN bus for the SocketCAN interface described by the given config.
+func NewSocketCAN(config CANConfig) (CAN, error) {
+	if err := config.Validate("can"); err != nil {
+		return nil, err
+	}
+	return &socketCAN{iface: config.Interface}, nil
+}
+
+func (sc *socketCAN) OpenHandle(filters ...CANFilter) (CANHandle, error) {
+	iface, err := net.InterfaceByName(sc.iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find CAN interface %s", sc.iface)
+	}
+	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.CAN_RAW)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open CAN socket")
+	}
+	if len(filters) != 0 {
+		rawFilters := make([]unix.CanFilter, 0, len(filters))
+		for _, f := range filters {
+			rawFilters = append(rawFilters, toRawCANFilter(f))
+		}
+		if err := unix.SetsockoptCanRawFilter(fd, unix.SOL_CAN_RAW, unix.CAN_RAW_FILTER, rawFilters); err != nil {
+			utils.UncheckedError(unix.Close(fd))
+			return nil, errors.Wrap(err, "cannot set CAN filters")
+		}
+	}
+	if err := unix.Bind(fd, &unix.SockaddrCAN{Ifindex: iface.Index}); err != nil {
+		utils.UncheckedError(unix.Close(fd))
+		return nil, errors.Wrapf(err, "cannot bind to CAN interface %s", sc.iface)
+	}
+	// a nonblocking descriptor lets the runtime poller enforce read deadlines
+	return &socketCANHandle{file: os.NewFile(uintptr(fd), sc.iface)}, nil
+}
+
+func toRawCANFilter(f CANFilter) unix.CanFilter {
+	id := f.ID
+	mask := f.Mask | unix.CAN_EFF_FLAG
+	if f.Extended {
+		id |= unix.CAN_EFF_FLAG
+	}
+	if f.Invert {
+		id |= unix.CAN_INV_FILTER
+	}
+	return unix.CanFilter{Id: id, Mask: mask}
+}
+
+type socketCANHandle struct {
+	mu       sync.Mutex
+	file     *os.File
+	isClosed bool
+}
+
+func (h *socketCANHandle) Send(ctx context.Context, frame CANFrame) error {
+	if err := frame.Validate(); err != nil {
+		return err
+	}
+	raw := rawCANFrame{ID: frame.ID, Len: uint8(len(frame.Data))}
+	if frame.Extended {
+		raw.ID |= unix.CAN_EFF_FLAG
+	}
+	if frame.Remote {
+		raw.ID |= unix.CAN_RTR_FLAG
+	}
+	copy(raw.Data[:], frame.Data)
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := h.file.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	} else if err := h.file.SetWriteDeadline(time.Time{}); err != nil {
+		return err
+	}
+	_, err := h.file.Write((*[rawCANFrameSize]byte)(unsafe.Pointer(&raw))[:])
+	return err
+}
+
+func (h *socketCANHandle) Receive(ctx context.Context) (CANFrame, error) {
+	var raw rawCANFrame
+	buf := (*[rawCANFrameSize]byte)(unsafe.Pointer(&raw))[:]
+	for {
+		if err := ctx.Err(); err != nil {
+			return CANFrame{}, err
+		}
+		if err := h.file.SetReadDeadline(time.Now().Add(canReadPollInterval)); err != nil {
+			return CANFrame{}, err
+		}
+		n, err := h.file.Read(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			continue
+		}
+		if err != nil {
+			return CANFrame{}, err
+		}
+		if n != rawCANFrameSize {
+			return CANFrame{}, errors.Errorf("short CAN frame read of %d bytes", n)
+		}
+		if raw.ID&unix.CAN_ERR_FLAG != 0 {
+			// error frames are only delivered when requested, which we never do
+			continue
+		}
+		length := int(raw.Len)
+		if length > canMaxDataLength {
+			length = canMaxDataLength
+		}
+		frame := CANFrame{
+			Extended: raw.ID&unix.CAN_EFF_FLAG != 0,
+			Remote:   raw.ID&unix.CAN_RTR_FLAG != 0,
+			Data:     append([]byte{}, raw.Data[:length]...),
+		}
+		if frame.Extended {
+			frame.ID = raw.ID & unix.CAN_EFF_MASK
+		} else {
+			frame.ID = raw.ID & unix.CAN_SFF_MASK
+		}
+		return frame, nil
+	}
+}
+
+func (h *socketCANHandle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isClosed {
+		return nil
+	}
+	h.isClosed = true
+	return h.file.Close()
+}
@@ -0,0 +1,176 @@
+package board_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	"go.viam.com/rdk/resource"
+)
+
+func TestCANConfigValidate(t *testing.T) {
+	conf := board.CANConfig{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"name" is required`)
+
+	conf.Name = "can"
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"interface" is required`)
+
+	conf.Interface = "can0"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+}
+
+func TestCANFrameValidate(t *testing.T) {
+	frame := board.CANFrame{ID: 0x7FF, Data: make([]byte, 8)}
+	test.That(t, frame.Validate(), test.ShouldBeNil)
+
+	frame.Data = make([]byte, 9)
+	test.That(t, frame.Validate(), test.ShouldNotBeNil)
+
+	frame = board.CANFrame{ID: 0x800}
+	test.That(t, frame.Validate(), test.ShouldNotBeNil)
+	frame.Extended = true
+	test.That(t, frame.Validate(), test.ShouldBeNil)
+	frame.ID = 0x20000000
+	test.That(t, frame.Validate(), test.ShouldNotBeNil)
+}
+
+func TestCANFilters(t *testing.T) {
+	exact := board.CANFilter{ID: 0x181, Mask: 0x7FF}
+	test.That(t, exact.Matches(board.CANFrame{ID: 0x181}), test.ShouldBeTrue)
+	test.That(t, exact.Matches(board.CANFrame{ID: 0x182}), test.ShouldBeFalse)
+	test.That(t, exact.Matches(board.CANFrame{ID: 0x181, Extended: true}), test.ShouldBeFalse)
+
+	// the low 7 bits carry the CANopen node id
+	anyNode := board.CANFilter{ID: 0x580, Mask: 0x780}
+	test.That(t, anyNode.Matches(board.CANFrame{ID: 0x5A3}), test.ShouldBeTrue)
+	test.That(t, anyNode.Matches(board.CANFrame{ID: 0x6A3}), test.ShouldBeFalse)
+
+	anyNode.Invert = true
+	test.That(t, anyNode.Matches(board.CANFrame{ID: 0x5A3}), test.ShouldBeFalse)
+	test.That(t, anyNode.Matches(board.CANFrame{ID: 0x6A3}), test.ShouldBeTrue)
+
+	test.That(t, board.CANFiltersMatch(nil, board.CANFrame{ID: 0x123}), test.ShouldBeTrue)
+	filters := []board.CANFilter{exact, {ID: 0x1234, Mask: 0x1FFFFFFF, Extended: true}}
+	test.That(t, board.CANFiltersMatch(filters, board.CANFrame{ID: 0x1234, Extended: true}), test.ShouldBeTrue)
+	test.That(t, board.CANFiltersMatch(filters, board.CANFrame{ID: 0x1234}), test.ShouldBeFalse)
+}
+
+// testCANBus checks that frames sent on one handle reach the other handles that select them.
+func testCANBus(t *testing.T, bus board.CAN) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sender, err := bus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, sender.Close(), test.ShouldBeNil)
+	}()
+	all, err := bus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, all.Close(), test.ShouldBeNil)
+	}()
+	filtered, err := bus.OpenHandle(board.CANFilter{ID: 0x10, Mask: 0x7F0})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, filtered.Close(), test.ShouldBeNil)
+	}()
+
+	frames := []board.CANFrame{
+		{ID: 0x123, Data: []byte{1, 2, 3}},
+		{ID: 0x15, Data: []byte{}},
+		{ID: 0x1ABCDE, Extended: true, Data: []byte{0xff}},
+	}
+	for _, frame := range frames {
+		test.That(t, sender.Send(ctx, frame), test.ShouldBeNil)
+	}
+	for _, frame := range frames {
+		got, err := all.Receive(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, got, test.ShouldResemble, frame)
+	}
+	got, err := filtered.Receive(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, frames[1])
+
+	// the sender does not see its own frames
+	shortCtx, shortCancel := context.WithTimeout(ctx, 150*time.Millisecond)
+	defer shortCancel()
+	_, err = sender.Receive(shortCtx)
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+
+	err = sender.Send(ctx, board.CANFrame{ID: 0x123, Data: make([]byte, 9)})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFakeCAN(t *testing.T) {
+	testCANBus(t, fakeboard.NewCAN())
+}
+
+func TestSocketCAN(t *testing.T) {
+	// needs a virtual CAN interface: ip link add dev vcan0 type vcan && ip link set up vcan0
+	if _, err := net.InterfaceByName("vcan0"); err != nil {
+		t.Skip("vcan0 is not available")
+	}
+	bus, err := board.NewSocketCAN(board.CANConfig{Name: "can", Interface: "vcan0"})
+	test.That(t, err, test.ShouldBeNil)
+	testCANBus(t, bus)
+}
+
+func TestSocketCANMissingInterface(t *testing.T) {
+	bus, err := board.NewSocketCAN(board.CANConfig{Name: "can", Interface: "nosuchcan0"})
+	if err != nil {
+		// not supported on this platform
+		return
+	}
+	_, err = bus.OpenHandle()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "nosuchcan0")
+}
+
+func TestReconfigurableCAN(t *testing.T) {
+	oldBus := fakeboard.NewCAN()
+	reconfBoard, err := board.WrapWithReconfigurable(
+		&fakeboard.Board{CANs: map[string]*fakeboard.CAN{"can0": oldBus}}, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+
+	canBoard, ok := reconfBoard.(board.CANBoard)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, canBoard.CANNames(), test.ShouldResemble, []string{"can0"})
+	bus, ok := canBoard.CANByName("can0")
+	test.That(t, ok, test.ShouldBeTrue)
+	_, ok = canBoard.CANByName("can1")
+	test.That(t, ok, test.ShouldBeFalse)
+
+	newBus := fakeboard.NewCAN()
+	newBoard, err := board.WrapWithReconfigurable(
+		&fakeboard.Board{CANs: map[string]*fakeboard.CAN{"can0": newBus}}, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reconfBoard.Reconfigure(context.Background(), newBoard), test.ShouldBeNil)
+
+	// handles opened after reconfiguring are on the new bus
+	listener, err := newBus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, listener.Close(), test.ShouldBeNil)
+	}()
+	handle, err := bus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, handle.Close(), test.ShouldBeNil)
+	}()
+	test.That(t, handle.Send(context.Background(), board.CANFrame{ID: 1, Data: []byte{42}}), test.ShouldBeNil)
+	frame, err := listener.Receive(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame.Data, test.ShouldResemble, []byte{42})
+}
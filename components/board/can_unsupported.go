@@ -0,0 +1,10 @@
+//go:build !linux
+
+package board
+
+import "github.com/pkg/errors"
+
+// NewSocketCAN is only supported on Linux.
+func NewSocketCAN(config CANConfig) (CAN, error) {
+	return nil, errors.New("SocketCAN is only supported on linux")
+}
@@ -3,6 +3,7 @@ package board
 
 import (
 	"context"
+	"encoding/base64"
 	"math"
 	"sync"
 
@@ -20,6 +21,8 @@ import (
 // eventually be implemented server side or faked client side.
 var errUnimplemented = errors.New("unimplemented")
 
+var _ = SerialBoard(&client{})
+
 // client implements BoardServiceClient.
 type client struct {
 	conn   rpc.ClientConn
@@ -29,6 +32,10 @@ type client struct {
 	info           boardInfo
 	cachedStatus   *commonpb.BoardStatus
 	cachedStatusMu *sync.Mutex
+
+	interruptStreamsMu      *sync.Mutex
+	interruptStreams        map[string]*interruptStream
+	activeBackgroundWorkers *sync.WaitGroup
 }
 
 type boardInfo struct {
@@ -50,6 +57,10 @@ func NewClientFromConn(ctx context.Context, conn rpc.ClientConn, name string, lo
 		logger:         logger,
 		info:           info,
 		cachedStatusMu: &sync.Mutex{},
+
+		interruptStreamsMu:      &sync.Mutex{},
+		interruptStreams:        map[string]*interruptStream{},
+		activeBackgroundWorkers: &sync.WaitGroup{},
 	}
 	if err := c.refresh(ctx); err != nil {
 		c.logger.Warn(err)
@@ -81,6 +92,41 @@ func (c *client) GPIOPinByName(name string) (GPIOPin, error) {
 	}, nil
 }
 
+func (c *client) SerialByName(name string) (Serial, bool) {
+	// serial ports are not in the board status, so the remote board is asked for their names
+	found := false
+	for _, n := range c.SerialNames() {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, false
+	}
+	return &serialClient{
+		client:     c,
+		boardName:  c.info.name,
+		serialName: name,
+	}, true
+}
+
+func (c *client) SerialNames() []string {
+	resp, err := c.DoCommand(context.Background(), map[string]interface{}{"command": SerialNamesCommand})
+	if err != nil {
+		c.logger.Debugw("cannot get serial port names", "error", err)
+		return []string{}
+	}
+	raw, _ := resp["names"].([]interface{})
+	names := make([]string, 0, len(raw))
+	for _, n := range raw {
+		if name, ok := n.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (c *client) SPINames() []string {
 	if c.getCachedStatus() == nil {
 		c.logger.Debugw("no cached status")
@@ -241,12 +287,14 @@ func (dic *digitalInterruptClient) Tick(ctx context.Context, high bool, nanoseco
 	panic(errUnimplemented)
 }
 
+// AddCallback streams the interrupt's ticks from the remote board, sharing one stream
+// between all callbacks of the same interrupt.
 func (dic *digitalInterruptClient) AddCallback(c chan Tick) {
-	panic(errUnimplemented)
+	dic.client.addInterruptCallback(dic.digitalInterruptName, c)
 }
 
 func (dic *digitalInterruptClient) RemoveCallback(c chan Tick) {
-	panic(errUnimplemented)
+	dic.client.removeInterruptCallback(dic.digitalInterruptName, c)
 }
 
 func (dic *digitalInterruptClient) AddPostProcessor(pp PostProcessor) {
@@ -351,6 +399,65 @@ func (gpc *gpioPinClient) SetPWMFreq(ctx context.Context, freqHz uint, extra map
 	return err
 }
 
+// serialClient accesses a serial port on a remote board. Each read or write holds the port on the
+// remote board only for its own duration, so handles only exclude each other on this side.
+type serialClient struct {
+	mu         sync.Mutex
+	client     *client
+	boardName  string
+	serialName string
+}
+
+func (sc *serialClient) OpenHandle() (SerialHandle, error) {
+	sc.mu.Lock()
+	return &serialClientHandle{serial: sc}, nil
+}
+
+type serialClientHandle struct {
+	serial   *serialClient
+	isClosed bool
+}
+
+func (sch *serialClientHandle) Write(ctx context.Context, tx []byte) error {
+	if sch.isClosed {
+		return errors.New("can't use Write() on an already closed SerialHandle")
+	}
+	_, err := sch.serial.client.DoCommand(ctx, map[string]interface{}{
+		"command": SerialWriteCommand,
+		"serial":  sch.serial.serialName,
+		"data":    base64.StdEncoding.EncodeToString(tx),
+	})
+	return err
+}
+
+func (sch *serialClientHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	if sch.isClosed {
+		return nil, errors.New("can't use Read() on an already closed SerialHandle")
+	}
+	resp, err := sch.serial.client.DoCommand(ctx, map[string]interface{}{
+		"command": SerialReadCommand,
+		"serial":  sch.serial.serialName,
+		"count":   count,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded, ok := resp["data"].(string)
+	if !ok {
+		return nil, errors.New("serial read returned no data")
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func (sch *serialClientHandle) Close() error {
+	if sch.isClosed {
+		return nil
+	}
+	sch.isClosed = true
+	sch.serial.mu.Unlock()
+	return nil
+}
+
 // copyStringSlice is a helper to simply copy a string slice
 // so that no one mutates it.
 func copyStringSlice(src []string) []string {
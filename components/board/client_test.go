@@ -3,7 +3,9 @@ package board_test
 import (
 	"context"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
@@ -11,6 +13,7 @@ import (
 	"go.viam.com/test"
 	"go.viam.com/utils"
 	"go.viam.com/utils/rpc"
+	gotestutils "go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/generic"
@@ -304,3 +307,164 @@ func TestClientDialerOption(t *testing.T) {
 	test.That(t, conn1.Close(), test.ShouldBeNil)
 	test.That(t, conn2.Close(), test.ShouldBeNil)
 }
+
+func TestClientTickStream(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	injectBoard := &inject.Board{}
+	interrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "digital1", Pin: "1"})
+	test.That(t, err, test.ShouldBeNil)
+	injectBoard.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, bool) {
+		return interrupt, name == "digital1"
+	}
+	injectBoard.DigitalInterruptNamesFunc = func() []string {
+		return []string{"digital1"}
+	}
+	var pinMu sync.Mutex
+	var pinHigh bool
+	var pinGets int
+	injectGPIOPin := &inject.GPIOPin{}
+	injectGPIOPin.GetFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		pinMu.Lock()
+		defer pinMu.Unlock()
+		pinGets++
+		return pinHigh, nil
+	}
+	injectBoard.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		return injectGPIOPin, nil
+	}
+
+	listener, cleanup := setupService(t, injectBoard)
+	defer cleanup()
+
+	conn, err := viamgrpc.Dial(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	client := board.NewClientFromConn(context.Background(), conn, testBoardName, logger)
+
+	t.Run("digital interrupt callbacks", func(t *testing.T) {
+		digital1, ok := client.DigitalInterruptByName("digital1")
+		test.That(t, ok, test.ShouldBeTrue)
+		callback := make(chan board.Tick)
+		digital1.AddCallback(callback)
+		defer digital1.RemoveCallback(callback)
+
+		// ticks are dropped until the stream is set up on the server
+		for {
+			test.That(t, interrupt.Tick(context.Background(), true, 42), test.ShouldBeNil)
+			select {
+			case tick := <-callback:
+				test.That(t, tick, test.ShouldResemble, board.Tick{High: true, TimestampNanosec: 42})
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	t.Run("removing a callback that stopped reading", func(t *testing.T) {
+		digital1, ok := client.DigitalInterruptByName("digital1")
+		test.That(t, ok, test.ShouldBeTrue)
+		reading := make(chan board.Tick, 1)
+		digital1.AddCallback(reading)
+		defer digital1.RemoveCallback(reading)
+		for {
+			test.That(t, interrupt.Tick(context.Background(), true, 43), test.ShouldBeNil)
+			select {
+			case <-reading:
+			case <-time.After(10 * time.Millisecond):
+				continue
+			}
+			break
+		}
+
+		// the next tick is delivered to reading and then blocks on stalled, which is never read
+		stalled := make(chan board.Tick)
+		digital1.AddCallback(stalled)
+		test.That(t, interrupt.Tick(context.Background(), true, 44), test.ShouldBeNil)
+		test.That(t, (<-reading).TimestampNanosec, test.ShouldEqual, 44)
+
+		removed := make(chan struct{})
+		go func() {
+			digital1.RemoveCallback(stalled)
+			close(removed)
+		}()
+		select {
+		case <-removed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("removing a callback that stopped reading blocked")
+		}
+		test.That(t, interrupt.Tick(context.Background(), true, 45), test.ShouldBeNil)
+		test.That(t, (<-reading).TimestampNanosec, test.ShouldEqual, 45)
+	})
+
+	t.Run("GPIO pin edges", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := make(chan board.TickEvent)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- board.StreamTicks(ctx, client, board.TickStreamOptions{GPIOPins: []string{"one"}}, func(event board.TickEvent) error {
+				events <- event
+				return nil
+			})
+		}()
+
+		gotestutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			pinMu.Lock()
+			defer pinMu.Unlock()
+			test.That(tb, pinGets, test.ShouldBeGreaterThan, 0)
+		})
+		pinMu.Lock()
+		pinHigh = true
+		pinMu.Unlock()
+		event := <-events
+		test.That(t, event.GPIOPin, test.ShouldEqual, "one")
+		test.That(t, event.High, test.ShouldBeTrue)
+		test.That(t, event.Polled, test.ShouldBeTrue)
+		test.That(t, event.TimestampNanosec, test.ShouldBeGreaterThan, 0)
+
+		cancel()
+		test.That(t, <-errs, test.ShouldBeError, context.Canceled)
+	})
+
+	t.Run("GPIO pin edges from a digital interrupt", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := make(chan board.TickEvent)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- board.StreamTicks(ctx, client, board.TickStreamOptions{GPIOPins: []string{"1"}}, func(event board.TickEvent) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case events <- event:
+					return nil
+				}
+			})
+		}()
+
+		// the pin's edges come from the interrupt on it, which drops them until the stream is set
+		// up on the server
+		for {
+			test.That(t, interrupt.Tick(context.Background(), false, 46), test.ShouldBeNil)
+			select {
+			case event := <-events:
+				test.That(t, event, test.ShouldResemble, board.TickEvent{GPIOPin: "1", Tick: board.Tick{TimestampNanosec: 46}})
+			case <-time.After(10 * time.Millisecond):
+				continue
+			}
+			break
+		}
+
+		cancel()
+		test.That(t, <-errs, test.ShouldBeError, context.Canceled)
+	})
+
+	t.Run("unknown digital interrupt", func(t *testing.T) {
+		err := board.StreamTicks(context.Background(), client, board.TickStreamOptions{DigitalInterrupts: []string{"nope"}}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "unknown digital interrupt: nope")
+	})
+
+	test.That(t, utils.TryClose(context.Background(), client), test.ShouldBeNil)
+	test.That(t, conn.Close(), test.ShouldBeNil)
+}
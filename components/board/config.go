@@ -1,6 +1,7 @@
 package board
 
 import (
+	"github.com/pkg/errors"
 	"go.viam.com/utils"
 )
 
@@ -43,6 +44,13 @@ type AnalogConfig struct {
 	ChipSelect        string `json:"chip_select"` // the CS line for the ADC chip, typically a pin number on the board
 	AverageOverMillis int    `json:"average_over_ms,omitempty"`
 	SamplesPerSecond  int    `json:"samples_per_sec,omitempty"`
+
+	// Type is the external ADC chip, mcp3008 if unset. See RegisterAnalogReader.
+	Type     string  `json:"type,omitempty"`
+	I2CBus   string  `json:"i2c_bus,omitempty"`   // name of the I2C bus, for I2C ADCs
+	I2CAddr  int     `json:"i2c_addr,omitempty"`  // address of the ADC on the I2C bus, if not the chip's default
+	Gain     float64 `json:"gain,omitempty"`      // programmable gain of the ADC, if it has one
+	DataRate int     `json:"data_rate,omitempty"` // samples per second the ADC converts at, if it can be set
 }
 
 // Validate ensures all parts of the config are valid.
@@ -50,6 +58,11 @@ func (config *AnalogConfig) Validate(path string) error {
 	if config.Name == "" {
 		return utils.NewConfigValidationFieldRequiredError(path, "name")
 	}
+	if config.Type != "" {
+		if _, ok := lookupAnalogReader(config.Type); !ok {
+			return utils.NewConfigValidationError(path, errors.Errorf("unknown analog reader type %q", config.Type))
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,9 @@
+// export_test.go adds functionality to the board package that we only want to use and expose during testing.
+package board
+
+// DeregisterAnalogReader removes a previously registered analog reader type.
+func DeregisterAnalogReader(typ string) {
+	analogReaderConstructorsMu.Lock()
+	defer analogReaderConstructorsMu.Unlock()
+	delete(analogReaderConstructors, typ)
+}
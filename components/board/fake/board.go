@@ -4,7 +4,9 @@ package fake
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
@@ -19,7 +21,11 @@ import (
 	"go.viam.com/rdk/resource"
 )
 
-var _ = board.LocalBoard(&Board{})
+var (
+	_ = board.LocalBoard(&Board{})
+	_ = board.SerialBoard(&Board{})
+	_ = board.CANBoard(&Board{})
+)
 
 // A Config describes the configuration of an arduino board and all of its connected parts.
 type Config struct {
@@ -27,8 +33,15 @@ type Config struct {
 	SPIs              []board.SPIConfig              `json:"spis,omitempty"`
 	Analogs           []board.AnalogConfig           `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
+	Serials           []board.SerialConfig           `json:"serials,omitempty"`
+	CANs              []board.CANConfig              `json:"can_buses,omitempty"`
 	Attributes        config.AttributeMap            `json:"attributes,omitempty"`
 	FailNew           bool                           `json:"fail_new"`
+
+	// Signals simulated on the parts above.
+	AnalogSignals     []AnalogSignalConfig     `json:"analog_signals,omitempty"`
+	TickGenerators    []TickGeneratorConfig    `json:"tick_generators,omitempty"`
+	SimulatedEncoders []SimulatedEncoderConfig `json:"simulated_encoders,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -53,6 +66,20 @@ func (config *Config) Validate(path string) error {
 			return err
 		}
 	}
+	for idx, conf := range config.Serials {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "serials", idx)); err != nil {
+			return err
+		}
+	}
+	for idx, conf := range config.CANs {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "can_buses", idx)); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSimulation(config, path); err != nil {
+		return err
+	}
 
 	if config.FailNew {
 		return errors.New("whoops")
@@ -100,6 +127,8 @@ func NewBoard(ctx context.Context, config config.Component, logger golog.Logger)
 		Analogs:  map[string]*Analog{},
 		Digitals: map[string]board.DigitalInterrupt{},
 		GPIOPins: map[string]*GPIOPin{},
+		Serials:  map[string]*Serial{},
+		CANs:     map[string]*CAN{},
 	}
 
 	for _, c := range boardConfig.I2Cs {
@@ -114,6 +143,14 @@ func NewBoard(ctx context.Context, config config.Component, logger golog.Logger)
 		b.Analogs[c.Name] = &Analog{}
 	}
 
+	for _, c := range boardConfig.Serials {
+		b.Serials[c.Name] = NewSerial()
+	}
+
+	for _, c := range boardConfig.CANs {
+		b.CANs[c.Name] = NewCAN()
+	}
+
 	for _, c := range boardConfig.DigitalInterrupts {
 		var err error
 		b.Digitals[c.Name], err = board.CreateDigitalInterrupt(c)
@@ -122,6 +159,10 @@ func NewBoard(ctx context.Context, config config.Component, logger golog.Logger)
 		}
 	}
 
+	if err := b.startSimulation(boardConfig); err != nil {
+		return nil, multierr.Combine(err, b.Close(ctx))
+	}
+
 	return b, nil
 }
 
@@ -144,8 +185,13 @@ type Board struct {
 	Analogs  map[string]*Analog
 	Digitals map[string]board.DigitalInterrupt
 	GPIOPins map[string]*GPIOPin
+	Serials  map[string]*Serial
+	CANs     map[string]*CAN
 
 	CloseCount int
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
 }
 
 // SPIByName returns the SPI by the given name if it exists.
@@ -160,6 +206,18 @@ func (b *Board) I2CByName(name string) (board.I2C, bool) {
 	return s, ok
 }
 
+// SerialByName returns the serial port by the given name if it exists.
+func (b *Board) SerialByName(name string) (board.Serial, bool) {
+	s, ok := b.Serials[name]
+	return s, ok
+}
+
+// CANByName returns the CAN bus by the given name if it exists.
+func (b *Board) CANByName(name string) (board.CAN, bool) {
+	c, ok := b.CANs[name]
+	return c, ok
+}
+
 // AnalogReaderByName returns the analog reader by the given name if it exists.
 func (b *Board) AnalogReaderByName(name string) (board.AnalogReader, bool) {
 	a, ok := b.Analogs[name]
@@ -201,6 +259,24 @@ func (b *Board) I2CNames() []string {
 	return names
 }
 
+// SerialNames returns the name of all known serial ports.
+func (b *Board) SerialNames() []string {
+	names := []string{}
+	for k := range b.Serials {
+		names = append(names, k)
+	}
+	return names
+}
+
+// CANNames returns the name of all known CAN buses.
+func (b *Board) CANNames() []string {
+	names := []string{}
+	for k := range b.CANs {
+		names = append(names, k)
+	}
+	return names
+}
+
 // AnalogReaderNames returns the name of all known analog readers.
 func (b *Board) AnalogReaderNames() []string {
 	names := []string{}
@@ -241,6 +317,10 @@ func (b *Board) ModelAttributes() board.ModelAttributes {
 // Close attempts to cleanly close each part of the board.
 func (b *Board) Close(ctx context.Context) error {
 	b.CloseCount++
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.activeBackgroundWorkers.Wait()
 	var err error
 
 	for _, analog := range b.Analogs {
@@ -283,6 +363,139 @@ func (h *SPIHandle) Close() error {
 	return nil
 }
 
+// A Serial is a loopback serial port: everything written to it can be read back from it.
+type Serial struct {
+	mu   sync.Mutex
+	data chan byte
+}
+
+// NewSerial returns a new loopback serial port.
+func NewSerial() *Serial {
+	return &Serial{data: make(chan byte, 4096)}
+}
+
+// OpenHandle opens a handle to the port that must be later closed to release access to it.
+func (s *Serial) OpenHandle() (board.SerialHandle, error) {
+	s.mu.Lock()
+	return &SerialHandle{s}, nil
+}
+
+// A SerialHandle allows Write, Read and Close.
+type SerialHandle struct {
+	port *Serial
+}
+
+// Write queues the given data to be read back.
+func (h *SerialHandle) Write(ctx context.Context, tx []byte) error {
+	for _, b := range tx {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case h.port.data <- b:
+		}
+	}
+	return nil
+}
+
+// Read returns up to count of the bytes written, waiting for at least one.
+func (h *SerialHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	var rx []byte
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case b := <-h.port.data:
+		rx = append(rx, b)
+	}
+	for len(rx) < count {
+		select {
+		case b := <-h.port.data:
+			rx = append(rx, b)
+		default:
+			return rx, nil
+		}
+	}
+	return rx, nil
+}
+
+// Close releases access to the port.
+func (h *SerialHandle) Close() error {
+	h.port.mu.Unlock()
+	return nil
+}
+
+// canQueueSize is how many frames a CANHandle buffers before dropping new ones, like a
+// socket receive buffer would.
+const canQueueSize = 256
+
+// A CAN is an in-memory CAN bus: every frame sent on one handle is delivered to all the
+// other open handles whose filters select it.
+type CAN struct {
+	mu      sync.Mutex
+	handles map[*CANHandle]struct{}
+}
+
+// NewCAN returns a new in-memory CAN bus.
+func NewCAN() *CAN {
+	return &CAN{handles: map[*CANHandle]struct{}{}}
+}
+
+// OpenHandle opens a handle receiving the frames selected by the filters.
+func (c *CAN) OpenHandle(filters ...board.CANFilter) (board.CANHandle, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := &CANHandle{bus: c, filters: filters, frames: make(chan board.CANFrame, canQueueSize)}
+	c.handles[h] = struct{}{}
+	return h, nil
+}
+
+// A CANHandle allows Send, Receive and Close.
+type CANHandle struct {
+	bus     *CAN
+	filters []board.CANFilter
+	frames  chan board.CANFrame
+}
+
+// Send delivers the frame to all other handles on the bus.
+func (h *CANHandle) Send(ctx context.Context, frame board.CANFrame) error {
+	if err := frame.Validate(); err != nil {
+		return err
+	}
+	h.bus.mu.Lock()
+	defer h.bus.mu.Unlock()
+	if _, ok := h.bus.handles[h]; !ok {
+		return errors.New("can't use Send() on an already closed CANHandle")
+	}
+	for other := range h.bus.handles {
+		if other == h || !board.CANFiltersMatch(other.filters, frame) {
+			continue
+		}
+		frame.Data = append([]byte{}, frame.Data...)
+		select {
+		case other.frames <- frame:
+		default:
+		}
+	}
+	return nil
+}
+
+// Receive returns the next frame delivered to the handle.
+func (h *CANHandle) Receive(ctx context.Context) (board.CANFrame, error) {
+	select {
+	case <-ctx.Done():
+		return board.CANFrame{}, ctx.Err()
+	case frame := <-h.frames:
+		return frame, nil
+	}
+}
+
+// Close removes the handle from the bus.
+func (h *CANHandle) Close() error {
+	h.bus.mu.Lock()
+	defer h.bus.mu.Unlock()
+	delete(h.bus.handles, h)
+	return nil
+}
+
 // A I2C allows opening an I2CHandle.
 type I2C struct {
 	mu   sync.Mutex
@@ -337,24 +550,31 @@ func (h *I2CHandle) Close() error {
 	return nil
 }
 
-// A Analog reads back the same set value.
+// A Analog reads back the same set value, or follows a waveform if it has an analog signal.
 type Analog struct {
 	Value      int
 	CloseCount int
 	Mu         sync.RWMutex
+
+	signal func(time.Duration) float64
+	start  time.Time
 }
 
 func (a *Analog) Read(ctx context.Context, extra map[string]interface{}) (int, error) {
 	a.Mu.RLock()
 	defer a.Mu.RUnlock()
+	if a.signal != nil {
+		return int(math.Round(a.signal(time.Since(a.start)))), nil
+	}
 	return a.Value, nil
 }
 
-// Set is used during testing.
+// Set is used during testing. It stops any analog signal.
 func (a *Analog) Set(value int) {
 	a.Mu.Lock()
 	defer a.Mu.Unlock()
 	a.Value = value
+	a.signal = nil
 }
 
 // Close does nothing.
@@ -364,40 +584,70 @@ func (a *Analog) Close() {
 
 // A GPIOPin reads back the same set values.
 type GPIOPin struct {
+	mu      sync.Mutex
 	high    bool
 	pwm     float64
 	pwmFreq uint
+	// pwming is whether the pin was last set to a duty cycle rather than a level
+	pwming bool
 }
 
 // Set sets the pin to either low or high.
 func (gp *GPIOPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	gp.high = high
+	gp.pwming = false
 	return nil
 }
 
 // Get gets the high/low state of the pin.
 func (gp *GPIOPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	return gp.high, nil
 }
 
 // PWM gets the pin's given duty cycle.
 func (gp *GPIOPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	return gp.pwm, nil
 }
 
 // SetPWM sets the pin to the given duty cycle.
 func (gp *GPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	gp.pwm = dutyCyclePct
+	gp.pwming = true
 	return nil
 }
 
 // PWMFreq gets the PWM frequency of the pin.
 func (gp *GPIOPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	return gp.pwmFreq, nil
 }
 
 // SetPWMFreq sets the given pin to the given PWM frequency. 0 will use the board's default PWM frequency.
 func (gp *GPIOPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	gp.pwmFreq = freqHz
 	return nil
 }
+
+// level returns the average level of the pin, its duty cycle if it was last set to one.
+func (gp *GPIOPin) level() float64 {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	if gp.pwming {
+		return gp.pwm
+	}
+	if gp.high {
+		return 1
+	}
+	return 0
+}
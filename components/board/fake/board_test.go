@@ -90,3 +90,39 @@ func TestConfigValidate(t *testing.T) {
 	validConfig.DigitalInterrupts = []board.DigitalInterruptConfig{{Name: "bar", Pin: "3"}}
 	test.That(t, validConfig.Validate("path"), test.ShouldBeNil)
 }
+
+func TestSimulationConfigValidate(t *testing.T) {
+	validConfig := Config{}
+
+	validConfig.AnalogSignals = []AnalogSignalConfig{{Analog: "blue", Waveform: "square"}}
+	err := validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.analog_signals.0`)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown waveform "square"`)
+
+	validConfig.AnalogSignals = []AnalogSignalConfig{{Analog: "blue", Waveform: WaveformSine}}
+	err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `period_sec must be greater than zero`)
+
+	validConfig.AnalogSignals = []AnalogSignalConfig{{Analog: "blue", Waveform: WaveformSine, PeriodSec: 1}}
+	test.That(t, validConfig.Validate("path"), test.ShouldBeNil)
+
+	validConfig.TickGenerators = []TickGeneratorConfig{{Interrupt: "i1"}}
+	err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.tick_generators.0`)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `rate_hz must be greater than zero`)
+
+	validConfig.TickGenerators = []TickGeneratorConfig{{Interrupt: "i1", RateHz: 10}}
+	test.That(t, validConfig.Validate("path"), test.ShouldBeNil)
+
+	validConfig.SimulatedEncoders = []SimulatedEncoderConfig{{A: "ea", MaxRPM: 100, TicksPerRotation: 10}}
+	err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.simulated_encoders.0`)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `motor_pins needs pwm, or both a and b`)
+
+	validConfig.SimulatedEncoders[0].MotorPins.PWM = "12"
+	test.That(t, validConfig.Validate("path"), test.ShouldBeNil)
+}
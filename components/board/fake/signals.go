@@ -0,0 +1,439 @@
+package fake
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// simulationStep is how often tick generators and simulated encoders catch up with the
+// clock. Edges due in between are emitted together, with the timestamps they were due at.
+const simulationStep = time.Millisecond
+
+// tickTimeout is how long a simulated edge waits for an interrupt's callbacks before it is
+// dropped, so that a dependent that stopped reading while it closes never blocks the simulation.
+const tickTimeout = 100 * time.Millisecond
+
+// sendTick sends an edge to the interrupt, dropping it if a callback is not ready in time. It only
+// fails once the simulation is stopped.
+func sendTick(ctx context.Context, interrupt board.DigitalInterrupt, high bool, nanos uint64) error {
+	tickCtx, cancel := context.WithTimeout(ctx, tickTimeout)
+	defer cancel()
+	if err := interrupt.Tick(tickCtx, high, nanos); err != nil && ctx.Err() != nil {
+		return err
+	}
+	return nil
+}
+
+// Waveforms an analog reader can be driven by.
+const (
+	WaveformSine  = "sine"
+	WaveformRamp  = "ramp"
+	WaveformNoise = "noise"
+	WaveformCSV   = "csv"
+)
+
+// An AnalogSignalConfig drives an analog reader with a waveform between Min and Max. Sine and
+// ramp waves repeat every PeriodSec, and noise is uniform. A CSV file holds rows of a time in
+// seconds and a value, which are interpolated between and played back in a loop.
+type AnalogSignalConfig struct {
+	Analog    string  `json:"analog"`
+	Waveform  string  `json:"waveform"`
+	Min       float64 `json:"min,omitempty"`
+	Max       float64 `json:"max,omitempty"`
+	PeriodSec float64 `json:"period_sec,omitempty"`
+	File      string  `json:"file,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *AnalogSignalConfig) Validate(path string) error {
+	if config.Analog == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "analog")
+	}
+	switch config.Waveform {
+	case WaveformSine, WaveformRamp:
+		if config.PeriodSec <= 0 {
+			return utils.NewConfigValidationError(path, errors.New("period_sec must be greater than zero"))
+		}
+	case WaveformNoise:
+	case WaveformCSV:
+		if config.File == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "file")
+		}
+	case "":
+		return utils.NewConfigValidationFieldRequiredError(path, "waveform")
+	default:
+		return utils.NewConfigValidationError(path, errors.Errorf("unknown waveform %q", config.Waveform))
+	}
+	return nil
+}
+
+// signal returns the value of the waveform at the given time since it started.
+func (config *AnalogSignalConfig) signal() (func(time.Duration) float64, error) {
+	span := config.Max - config.Min
+	period := config.PeriodSec
+	switch config.Waveform {
+	case WaveformSine:
+		return func(t time.Duration) float64 {
+			return config.Min + span*(1+math.Sin(2*math.Pi*t.Seconds()/period))/2
+		}, nil
+	case WaveformRamp:
+		return func(t time.Duration) float64 {
+			return config.Min + span*math.Mod(t.Seconds(), period)/period
+		}, nil
+	case WaveformNoise:
+		//nolint:gosec
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+		return func(t time.Duration) float64 {
+			return config.Min + span*random.Float64()
+		}, nil
+	case WaveformCSV:
+		return csvSignal(config.File)
+	default:
+		return nil, errors.Errorf("unknown waveform %q", config.Waveform)
+	}
+}
+
+// csvSignal reads a recording of rows of a time in seconds and a value. A header row is
+// skipped.
+func csvSignal(path string) (func(time.Duration) float64, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	var times, values []float64
+	for i, row := range rows {
+		if len(row) != 2 {
+			return nil, errors.Errorf("%s:%d: expected a time and a value", path, i+1)
+		}
+		t, errTime := strconv.ParseFloat(strings.TrimSpace(row[0]), 64)
+		value, errValue := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if errTime != nil || errValue != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, errors.Errorf("%s:%d: expected numbers", path, i+1)
+		}
+		if len(times) > 0 && t <= times[len(times)-1] {
+			return nil, errors.Errorf("%s:%d: times must increase", path, i+1)
+		}
+		times = append(times, t)
+		values = append(values, value)
+	}
+	if len(times) == 0 {
+		return nil, errors.Errorf("%s has no samples", path)
+	}
+
+	start, length := times[0], times[len(times)-1]-times[0]
+	return func(d time.Duration) float64 {
+		t := start
+		if length > 0 {
+			t += math.Mod(d.Seconds(), length)
+		}
+		i := 1
+		for i < len(times) && times[i] <= t {
+			i++
+		}
+		if i == len(times) {
+			return values[len(values)-1]
+		}
+		fraction := (t - times[i-1]) / (times[i] - times[i-1])
+		return values[i-1] + fraction*(values[i]-values[i-1])
+	}, nil
+}
+
+// A TickGeneratorConfig ticks a digital interrupt high RateHz times a second, going low again
+// after DutyCycle of each period, or half of it if unset.
+type TickGeneratorConfig struct {
+	Interrupt string  `json:"interrupt"`
+	RateHz    float64 `json:"rate_hz"`
+	DutyCycle float64 `json:"duty_cycle,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *TickGeneratorConfig) Validate(path string) error {
+	if config.Interrupt == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "interrupt")
+	}
+	if config.RateHz <= 0 {
+		return utils.NewConfigValidationError(path, errors.New("rate_hz must be greater than zero"))
+	}
+	if config.DutyCycle < 0 || config.DutyCycle >= 1 {
+		return utils.NewConfigValidationError(path, errors.New("duty_cycle must be in [0, 1)"))
+	}
+	return nil
+}
+
+// MotorPins are the pins of a gpio motor driving a simulated encoder, named as in its config.
+type MotorPins struct {
+	A         string `json:"a,omitempty"`
+	B         string `json:"b,omitempty"`
+	Direction string `json:"dir,omitempty"`
+	PWM       string `json:"pwm,omitempty"`
+}
+
+// A SimulatedEncoderConfig ticks the interrupts of an encoder as fast as the motor on the given
+// pins would turn it, running at MaxRPM at full power. With only interrupt A the edges are those
+// of a single encoder, and with B as well those of a quadrature encoder.
+type SimulatedEncoderConfig struct {
+	MotorPins        MotorPins `json:"motor_pins"`
+	A                string    `json:"a"`
+	B                string    `json:"b,omitempty"`
+	MaxRPM           float64   `json:"max_rpm"`
+	TicksPerRotation int       `json:"ticks_per_rotation"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *SimulatedEncoderConfig) Validate(path string) error {
+	if config.MotorPins.PWM == "" && (config.MotorPins.A == "" || config.MotorPins.B == "") {
+		return utils.NewConfigValidationError(path, errors.New("motor_pins needs pwm, or both a and b"))
+	}
+	if config.A == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "a")
+	}
+	if config.MaxRPM <= 0 {
+		return utils.NewConfigValidationError(path, errors.New("max_rpm must be greater than zero"))
+	}
+	if config.TicksPerRotation <= 0 {
+		return utils.NewConfigValidationError(path, errors.New("ticks_per_rotation must be greater than zero"))
+	}
+	return nil
+}
+
+// A tickGenerator ticks an interrupt at a fixed rate.
+type tickGenerator struct {
+	interrupt board.DigitalInterrupt
+	period    time.Duration
+	high      time.Duration
+	pulses    int64
+}
+
+// step emits the edges due by the given time since start.
+func (g *tickGenerator) step(ctx context.Context, start time.Time, elapsed time.Duration) error {
+	for {
+		rise := time.Duration(g.pulses) * g.period
+		fall := rise + g.high
+		if fall > elapsed {
+			return nil
+		}
+		if err := sendTick(ctx, g.interrupt, true, uint64(start.Add(rise).UnixNano())); err != nil {
+			return err
+		}
+		if err := sendTick(ctx, g.interrupt, false, uint64(start.Add(fall).UnixNano())); err != nil {
+			return err
+		}
+		g.pulses++
+	}
+}
+
+// A simulatedEncoder turns the drive of a motor's pins into encoder edges.
+type simulatedEncoder struct {
+	a, b              board.DigitalInterrupt
+	motorA, motorB    *GPIOPin
+	direction, pwm    *GPIOPin
+	maxEdgesPerSecond float64
+
+	position float64 // in edges
+	state    int
+}
+
+// quadratureStates are the levels of A and B, in the order the incremental encoder counts up.
+var quadratureStates = [4][2]bool{{false, false}, {false, true}, {true, true}, {true, false}}
+
+// drive returns the fraction of full power the motor pins are driving at, negative for
+// backwards.
+func (e *simulatedEncoder) drive() float64 {
+	drive := 1.0
+	switch {
+	case e.motorA != nil && e.motorB != nil:
+		drive = e.motorA.level() - e.motorB.level()
+	case e.direction != nil:
+		if e.direction.level() == 0 {
+			drive = -1
+		}
+	}
+	if e.pwm != nil {
+		drive *= e.pwm.level()
+	}
+	return drive
+}
+
+// step moves the encoder by dt at the current drive, emitting the edges passed along the way.
+func (e *simulatedEncoder) step(ctx context.Context, now time.Time, dt time.Duration) error {
+	velocity := e.drive() * e.maxEdgesPerSecond
+	if velocity == 0 {
+		return nil
+	}
+	next := e.position + velocity*dt.Seconds()
+	for {
+		var edge float64
+		prevState := e.state
+		if velocity > 0 {
+			edge = math.Floor(e.position) + 1
+			if edge > next {
+				break
+			}
+			e.state++
+		} else {
+			edge = math.Ceil(e.position) - 1
+			if edge < next {
+				break
+			}
+			e.state--
+		}
+		at := now.Add(-time.Duration((next - edge) / velocity * float64(time.Second)))
+		e.position = edge
+		if err := e.tick(ctx, prevState, uint64(at.UnixNano())); err != nil {
+			return err
+		}
+	}
+	e.position = next
+	return nil
+}
+
+func (e *simulatedEncoder) tick(ctx context.Context, prevState int, nanos uint64) error {
+	if e.b == nil {
+		return sendTick(ctx, e.a, e.state%2 != 0, nanos)
+	}
+	// only one of the two levels changes with each edge
+	prev := quadratureStates[(prevState%4+4)%4]
+	next := quadratureStates[(e.state%4+4)%4]
+	if prev[0] != next[0] {
+		return sendTick(ctx, e.a, next[0], nanos)
+	}
+	return sendTick(ctx, e.b, next[1], nanos)
+}
+
+// startSimulation starts the analog signals, tick generators and simulated encoders of the
+// config.
+func (b *Board) startSimulation(config *Config) error {
+	start := time.Now()
+	for idx, c := range config.AnalogSignals {
+		analog, ok := b.Analogs[c.Analog]
+		if !ok {
+			return errors.Errorf("analog_signals.%d: unknown analog reader %s", idx, c.Analog)
+		}
+		signal, err := c.signal()
+		if err != nil {
+			return errors.Wrapf(err, "analog_signals.%d", idx)
+		}
+		analog.Mu.Lock()
+		analog.signal = signal
+		analog.start = start
+		analog.Mu.Unlock()
+	}
+
+	generators := make([]*tickGenerator, 0, len(config.TickGenerators))
+	for idx, c := range config.TickGenerators {
+		interrupt, ok := b.Digitals[c.Interrupt]
+		if !ok {
+			return errors.Errorf("tick_generators.%d: unknown digital interrupt %s", idx, c.Interrupt)
+		}
+		period := time.Duration(float64(time.Second) / c.RateHz)
+		dutyCycle := c.DutyCycle
+		if dutyCycle == 0 {
+			dutyCycle = 0.5
+		}
+		generators = append(generators, &tickGenerator{
+			interrupt: interrupt,
+			period:    period,
+			high:      time.Duration(float64(period) * dutyCycle),
+		})
+	}
+
+	encoders := make([]*simulatedEncoder, 0, len(config.SimulatedEncoders))
+	for idx, c := range config.SimulatedEncoders {
+		e := &simulatedEncoder{maxEdgesPerSecond: c.MaxRPM / 60 * float64(c.TicksPerRotation)}
+		var ok bool
+		if e.a, ok = b.Digitals[c.A]; !ok {
+			return errors.Errorf("simulated_encoders.%d: unknown digital interrupt %s", idx, c.A)
+		}
+		if c.B != "" {
+			if e.b, ok = b.Digitals[c.B]; !ok {
+				return errors.Errorf("simulated_encoders.%d: unknown digital interrupt %s", idx, c.B)
+			}
+			// a quadrature encoder counts a tick every two edges
+			e.maxEdgesPerSecond *= 2
+		}
+		pin := func(name string) *GPIOPin {
+			if name == "" {
+				return nil
+			}
+			p, _ := b.GPIOPinByName(name)
+			return p.(*GPIOPin)
+		}
+		e.motorA = pin(c.MotorPins.A)
+		e.motorB = pin(c.MotorPins.B)
+		e.direction = pin(c.MotorPins.Direction)
+		e.pwm = pin(c.MotorPins.PWM)
+		encoders = append(encoders, e)
+	}
+
+	if len(generators) == 0 && len(encoders) == 0 {
+		return nil
+	}
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		ticker := time.NewTicker(simulationStep)
+		defer ticker.Stop()
+		last := start
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case now := <-ticker.C:
+				for _, g := range generators {
+					if err := g.step(cancelCtx, start, now.Sub(start)); err != nil {
+						return
+					}
+				}
+				for _, e := range encoders {
+					if err := e.step(cancelCtx, now, now.Sub(last)); err != nil {
+						return
+					}
+				}
+				last = now
+			}
+		}
+	}, b.activeBackgroundWorkers.Done)
+	return nil
+}
+
+func validateSimulation(config *Config, path string) error {
+	for idx, conf := range config.AnalogSignals {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "analog_signals", idx)); err != nil {
+			return err
+		}
+	}
+	for idx, conf := range config.TickGenerators {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "tick_generators", idx)); err != nil {
+			return err
+		}
+	}
+	for idx, conf := range config.SimulatedEncoders {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "simulated_encoders", idx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
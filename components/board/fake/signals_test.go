@@ -0,0 +1,256 @@
+package fake
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+)
+
+func TestAnalogSignals(t *testing.T) {
+	sine := AnalogSignalConfig{Analog: "a", Waveform: WaveformSine, Min: 100, Max: 300, PeriodSec: 4}
+	signal, err := sine.signal()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, signal(0), test.ShouldAlmostEqual, 200)
+	test.That(t, signal(time.Second), test.ShouldAlmostEqual, 300)
+	test.That(t, signal(3*time.Second), test.ShouldAlmostEqual, 100)
+
+	ramp := AnalogSignalConfig{Analog: "a", Waveform: WaveformRamp, Max: 1000, PeriodSec: 2}
+	signal, err = ramp.signal()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, signal(500*time.Millisecond), test.ShouldAlmostEqual, 250)
+	test.That(t, signal(2500*time.Millisecond), test.ShouldAlmostEqual, 250)
+
+	noise := AnalogSignalConfig{Analog: "a", Waveform: WaveformNoise, Min: -5, Max: 5}
+	signal, err = noise.signal()
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 100; i++ {
+		value := signal(0)
+		test.That(t, value, test.ShouldBeBetweenOrEqual, -5, 5)
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.csv")
+	test.That(t, os.WriteFile(path, []byte("time,value\n1,10\n2,30\n4,10\n"), 0o600), test.ShouldBeNil)
+	recording := AnalogSignalConfig{Analog: "a", Waveform: WaveformCSV, File: path}
+	signal, err = recording.signal()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, signal(0), test.ShouldAlmostEqual, 10)
+	test.That(t, signal(500*time.Millisecond), test.ShouldAlmostEqual, 20)
+	test.That(t, signal(2*time.Second), test.ShouldAlmostEqual, 20)
+	// played back in a loop
+	test.That(t, signal(3500*time.Millisecond), test.ShouldAlmostEqual, 20)
+
+	test.That(t, os.WriteFile(path, []byte("1,10\n1,30\n"), 0o600), test.ShouldBeNil)
+	_, err = recording.signal()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "times must increase")
+
+	logger := golog.NewTestLogger(t)
+	cfg := config.Component{Name: "board1", ConvertedAttributes: &Config{
+		Analogs:       []board.AnalogConfig{{Name: "blue", Pin: "0"}},
+		AnalogSignals: []AnalogSignalConfig{{Analog: "blue", Waveform: WaveformNoise, Min: 10, Max: 20}},
+	}}
+	b, err := NewBoard(context.Background(), cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	analog, ok := b.AnalogReaderByName("blue")
+	test.That(t, ok, test.ShouldBeTrue)
+	value, err := analog.Read(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldBeBetweenOrEqual, 10, 20)
+	b.Analogs["blue"].Set(42)
+	value, err = analog.Read(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 42)
+	test.That(t, b.Close(context.Background()), test.ShouldBeNil)
+
+	cfg.ConvertedAttributes = &Config{
+		AnalogSignals: []AnalogSignalConfig{{Analog: "blue", Waveform: WaveformNoise}},
+	}
+	_, err = NewBoard(context.Background(), cfg, logger)
+	test.That(t, err, test.ShouldBeError, "analog_signals.0: unknown analog reader blue")
+}
+
+func TestTickGenerator(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	cfg := config.Component{Name: "board1", ConvertedAttributes: &Config{
+		DigitalInterrupts: []board.DigitalInterruptConfig{{Name: "i1", Pin: "35"}},
+		TickGenerators:    []TickGeneratorConfig{{Interrupt: "i1", RateHz: 1000}},
+	}}
+	b, err := NewBoard(context.Background(), cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	interrupt, ok := b.DigitalInterruptByName("i1")
+	test.That(t, ok, test.ShouldBeTrue)
+
+	callback := make(chan board.Tick, 3)
+	interrupt.AddCallback(callback)
+	rise := <-callback
+	if !rise.High {
+		rise = <-callback
+	}
+	fall := <-callback
+	interrupt.RemoveCallback(callback)
+	test.That(t, rise.High, test.ShouldBeTrue)
+	test.That(t, fall.High, test.ShouldBeFalse)
+	test.That(t, fall.TimestampNanosec-rise.TimestampNanosec, test.ShouldEqual, uint64(500*time.Microsecond))
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		count, err := interrupt.Value(context.Background(), nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, count, test.ShouldBeGreaterThan, 50)
+	})
+
+	test.That(t, b.Close(context.Background()), test.ShouldBeNil)
+	count, err := interrupt.Value(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	time.Sleep(10 * time.Millisecond)
+	stopped, err := interrupt.Value(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stopped, test.ShouldEqual, count)
+}
+
+func TestSimulatedEncoderStep(t *testing.T) {
+	a, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "a", Pin: "1"})
+	test.That(t, err, test.ShouldBeNil)
+	b, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "b", Pin: "2"})
+	test.That(t, err, test.ShouldBeNil)
+	chanA := make(chan board.Tick, 100)
+	chanB := make(chan board.Tick, 100)
+	a.AddCallback(chanA)
+	b.AddCallback(chanB)
+
+	dir, pwm := &GPIOPin{}, &GPIOPin{}
+	e := &simulatedEncoder{a: a, b: b, direction: dir, pwm: pwm, maxEdgesPerSecond: 1000}
+	ctx := context.Background()
+	now := time.Now()
+
+	// stopped
+	test.That(t, e.step(ctx, now, 10*time.Millisecond), test.ShouldBeNil)
+	test.That(t, chanA, test.ShouldBeEmpty)
+	test.That(t, chanB, test.ShouldBeEmpty)
+
+	// half power forwards is an edge every 2ms, B leading A
+	test.That(t, dir.Set(ctx, true, nil), test.ShouldBeNil)
+	test.That(t, pwm.SetPWM(ctx, 0.5, nil), test.ShouldBeNil)
+	test.That(t, e.step(ctx, now, 9*time.Millisecond), test.ShouldBeNil)
+	test.That(t, chanA, test.ShouldHaveLength, 2)
+	test.That(t, chanB, test.ShouldHaveLength, 2)
+	first := <-chanB
+	test.That(t, first.High, test.ShouldBeTrue)
+	test.That(t, first.TimestampNanosec, test.ShouldEqual, uint64(now.Add(-7*time.Millisecond).UnixNano()))
+	test.That(t, (<-chanA).High, test.ShouldBeTrue)
+	test.That(t, (<-chanB).High, test.ShouldBeFalse)
+	test.That(t, (<-chanA).High, test.ShouldBeFalse)
+	test.That(t, e.position, test.ShouldAlmostEqual, 4.5)
+
+	// and backwards retraces them
+	test.That(t, dir.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, pwm.SetPWM(ctx, 1, nil), test.ShouldBeNil)
+	test.That(t, e.step(ctx, now, 2*time.Millisecond), test.ShouldBeNil)
+	test.That(t, (<-chanA).High, test.ShouldBeTrue)
+	test.That(t, (<-chanB).High, test.ShouldBeTrue)
+	test.That(t, e.position, test.ShouldAlmostEqual, 2.5)
+
+	// a single encoder toggles A on every edge
+	e = &simulatedEncoder{a: a, pwm: pwm, maxEdgesPerSecond: 1000}
+	test.That(t, e.step(ctx, now, 3*time.Millisecond), test.ShouldBeNil)
+	test.That(t, (<-chanA).High, test.ShouldBeTrue)
+	test.That(t, (<-chanA).High, test.ShouldBeFalse)
+	test.That(t, (<-chanA).High, test.ShouldBeTrue)
+	test.That(t, chanB, test.ShouldBeEmpty)
+}
+
+func TestSendTickDropsUnreadEdges(t *testing.T) {
+	ctx := context.Background()
+	interrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "a", Pin: "1"})
+	test.That(t, err, test.ShouldBeNil)
+	// nothing reads this callback, like that of an encoder that is closing
+	callback := make(chan board.Tick)
+	interrupt.AddCallback(callback)
+
+	test.That(t, sendTick(ctx, interrupt, true, 1), test.ShouldBeNil)
+	count, err := interrupt.Value(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 1)
+
+	// the callback can be removed while the simulation waits on it
+	sent := make(chan error, 1)
+	go func() {
+		sent <- sendTick(ctx, interrupt, true, 2)
+	}()
+	interrupt.RemoveCallback(callback)
+	test.That(t, <-sent, test.ShouldBeNil)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	interrupt.AddCallback(callback)
+	test.That(t, sendTick(cancelCtx, interrupt, true, 3), test.ShouldNotBeNil)
+}
+
+func TestSimulatedEncoder(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	ctx := context.Background()
+	cfg := config.Component{Name: "board1", ConvertedAttributes: &Config{
+		DigitalInterrupts: []board.DigitalInterruptConfig{{Name: "ea", Pin: "11"}, {Name: "eb", Pin: "13"}},
+		SimulatedEncoders: []SimulatedEncoderConfig{{
+			MotorPins:        MotorPins{A: "37", B: "38"},
+			A:                "ea",
+			B:                "eb",
+			MaxRPM:           600,
+			TicksPerRotation: 100,
+		}},
+	}}
+	b, err := NewBoard(ctx, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	enc, err := encoder.NewIncrementalEncoder(
+		ctx,
+		registry.Dependencies{board.Named("board1"): b},
+		config.Component{ConvertedAttributes: &encoder.IncrementalConfig{
+			Pins:      encoder.IncrementalPins{A: "ea", B: "eb"},
+			BoardName: "board1",
+		}},
+		logger,
+	)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		// stop the simulation before the encoder stops reading its interrupts
+		test.That(t, b.Close(ctx), test.ShouldBeNil)
+		test.That(t, enc.Close(), test.ShouldBeNil)
+	}()
+
+	// drive the pins the way a gpio motor with only A and B pins does
+	pinA, err := b.GPIOPinByName("37")
+	test.That(t, err, test.ShouldBeNil)
+	pinB, err := b.GPIOPinByName("38")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pinA.Set(ctx, true, nil), test.ShouldBeNil)
+	test.That(t, pinB.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, pinB.SetPWM(ctx, 0.5, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		ticks, err := enc.TicksCount(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, ticks, test.ShouldBeGreaterThan, 50)
+	})
+
+	test.That(t, pinA.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, pinB.Set(ctx, true, nil), test.ShouldBeNil)
+	test.That(t, pinA.SetPWM(ctx, 0.5, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		ticks, err := enc.TicksCount(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, ticks, test.ShouldBeLessThan, -50)
+	})
+}
@@ -0,0 +1,999 @@
+// Package firmata implements a board that drives a microcontroller running Firmata firmware,
+// such as StandardFirmata, over a serial port.
+package firmata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edaniels/golog"
+	goserial "github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("firmata")
+
+const (
+	defaultBaudRate = 57600
+	// Many boards reset when their serial port is opened and take a moment to boot.
+	startupTimeout = 10 * time.Second
+	requestTimeout = time.Second
+	readPollMillis = 100
+
+	// Servo pins are driven with pulse widths, so they look like PWM pins at this frequency.
+	servoFrequencyHz = 50
+	servoMinPulseUs  = 544
+	servoMaxPulseUs  = 2500
+)
+
+// A Config describes the configuration of a Firmata board and all of its connected parts.
+type Config struct {
+	SerialPath string `json:"serial_path"`
+	BaudRate   int    `json:"baud_rate,omitempty"` // defaults to 57600
+	// SamplingIntervalMs is how often the board reports analog inputs, the firmware default if unset.
+	SamplingIntervalMs int                            `json:"sampling_interval_ms,omitempty"`
+	Analogs            []board.AnalogConfig           `json:"analogs,omitempty"`
+	DigitalInterrupts  []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
+	// ServoPins are driven with servo pulses instead of PWM.
+	ServoPins []string `json:"servo_pins,omitempty"`
+	// I2CBus names the microcontroller's I2C bus. I2C is only enabled when it is set, since
+	// it claims the SDA and SCL pins.
+	I2CBus string `json:"i2c_bus,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) error {
+	if conf.SerialPath == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "serial_path")
+	}
+	if conf.SamplingIntervalMs < 0 {
+		return utils.NewConfigValidationError(path, errors.New("sampling_interval_ms cannot be negative"))
+	}
+	for idx, c := range conf.Analogs {
+		analogPath := fmt.Sprintf("%s.%s.%d", path, "analogs", idx)
+		if err := c.Validate(analogPath); err != nil {
+			return err
+		}
+		if _, err := parseAnalogChannel(c.Pin); err != nil {
+			return utils.NewConfigValidationError(analogPath, err)
+		}
+	}
+	for idx, c := range conf.DigitalInterrupts {
+		interruptPath := fmt.Sprintf("%s.%s.%d", path, "digital_interrupts", idx)
+		if err := c.Validate(interruptPath); err != nil {
+			return err
+		}
+		if _, _, err := parsePin(c.Pin); err != nil {
+			return utils.NewConfigValidationError(interruptPath, err)
+		}
+	}
+	for idx, pin := range conf.ServoPins {
+		if _, _, err := parsePin(pin); err != nil {
+			return utils.NewConfigValidationError(fmt.Sprintf("%s.%s.%d", path, "servo_pins", idx), err)
+		}
+	}
+	return nil
+}
+
+// parsePin parses a digital pin number like "13", or an analog channel like "A0" which
+// refers to the pin that channel is on.
+func parsePin(name string) (int, bool, error) {
+	if strings.HasPrefix(name, "A") || strings.HasPrefix(name, "a") {
+		ch, err := strconv.Atoi(name[1:])
+		if err != nil || ch < 0 {
+			return 0, false, errors.Errorf("invalid analog pin %q", name)
+		}
+		return ch, true, nil
+	}
+	pin, err := strconv.Atoi(name)
+	if err != nil || pin < 0 || pin > 127 {
+		return 0, false, errors.Errorf("invalid pin %q", name)
+	}
+	return pin, false, nil
+}
+
+// parseAnalogChannel parses the channel of an analog reader, given with or without the "A" prefix.
+func parseAnalogChannel(name string) (int, error) {
+	ch, _, err := parsePin(name)
+	if err != nil {
+		return 0, err
+	}
+	// analog reporting can only be toggled for the first 16 channels
+	if ch > 15 {
+		return 0, errors.Errorf("analog channel %d is out of range", ch)
+	}
+	return ch, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		board.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			conf, ok := config.ConvertedAttributes.(*Config)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(conf, config.ConvertedAttributes)
+			}
+			return connect(ctx, conf, logger)
+		}})
+	config.RegisterComponentAttributeMapConverter(
+		board.Subtype,
+		modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&Config{})
+}
+
+// connect opens the serial port of the board and configures it.
+func connect(ctx context.Context, conf *Config, logger golog.Logger) (board.LocalBoard, error) {
+	options := goserial.OpenOptions{
+		PortName: conf.SerialPath,
+		BaudRate: uint(conf.BaudRate),
+		DataBits: 8,
+		StopBits: 1,
+		// Reads return empty handed after this long, giving the read loop a chance to notice
+		// the board closing.
+		InterCharacterTimeout: readPollMillis,
+	}
+	if options.BaudRate == 0 {
+		options.BaudRate = defaultBaudRate
+	}
+	port, err := goserial.Open(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open serial port %s", conf.SerialPath)
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	b := &firmataBoard{
+		port:          port,
+		logger:        logger,
+		ports:         map[int]*portState{},
+		analogStates:  map[int]*analogState{},
+		waiters:       map[byte]chan []byte{},
+		analogs:       map[string]board.AnalogReader{},
+		interrupts:    map[string]board.DigitalInterrupt{},
+		interruptPins: map[int][]board.DigitalInterrupt{},
+		servoPins:     map[int]bool{},
+		cancelCtx:     cancelCtx,
+		cancel:        cancel,
+	}
+	reader := newMessageReader(&portReader{b})
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		b.readLoop(reader)
+	}, b.activeBackgroundWorkers.Done)
+
+	if err := b.configure(ctx, conf); err != nil {
+		return nil, multierr.Combine(err, b.Close())
+	}
+	return b, nil
+}
+
+// pinState is what is known about a single pin of the microcontroller.
+type pinState struct {
+	// modes maps the modes the pin supports to their resolution in bits.
+	modes         map[byte]byte
+	analogChannel int
+	mode          byte
+	modeSet       bool
+	high          bool
+	duty          float64
+}
+
+// portState tracks the inputs of a group of eight pins reported together.
+type portState struct {
+	reporting bool
+	value     byte
+	// received is closed once a report arrives.
+	received chan struct{}
+	reported bool
+}
+
+type analogState struct {
+	value    int
+	received chan struct{}
+	reported bool
+}
+
+type firmataBoard struct {
+	generic.Unimplemented
+	port   io.ReadWriteCloser
+	logger golog.Logger
+
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	firmware     string
+	pins         []*pinState
+	ports        map[int]*portState
+	analogStates map[int]*analogState
+	waiters      map[byte]chan []byte
+
+	analogs       map[string]board.AnalogReader
+	interrupts    map[string]board.DigitalInterrupt
+	interruptPins map[int][]board.DigitalInterrupt
+	servoPins     map[int]bool
+	i2c           *i2cBus
+	i2cName       string
+
+	closed                  int32
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// portReader waits through the empty reads of the polled serial port until the board closes.
+type portReader struct {
+	b *firmataBoard
+}
+
+func (pr *portReader) Read(p []byte) (int, error) {
+	for {
+		n, err := pr.b.port.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if atomic.LoadInt32(&pr.b.closed) == 1 {
+			return 0, io.EOF
+		}
+	}
+}
+
+func (b *firmataBoard) configure(ctx context.Context, conf *Config) error {
+	if err := b.handshake(ctx); err != nil {
+		return err
+	}
+	if conf.SamplingIntervalMs > 0 {
+		if err := b.write(sysex(sysexSamplingInterval, splitValue(conf.SamplingIntervalMs, 2)...)); err != nil {
+			return err
+		}
+	}
+	if conf.I2CBus != "" {
+		if err := b.write(sysex(sysexI2CConfig, 0, 0)); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		for _, p := range b.pins {
+			if _, ok := p.modes[ModeI2C]; ok {
+				p.mode, p.modeSet = ModeI2C, true
+			}
+		}
+		b.mu.Unlock()
+		b.i2c = &i2cBus{b: b}
+		b.i2cName = conf.I2CBus
+	}
+	for _, name := range conf.ServoPins {
+		if err := b.configureServo(name); err != nil {
+			return err
+		}
+	}
+	for _, c := range conf.Analogs {
+		if err := b.configureAnalog(c); err != nil {
+			return err
+		}
+	}
+	for _, c := range conf.DigitalInterrupts {
+		if err := b.configureInterrupt(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handshake waits for the board to come up and learns which pins it has.
+func (b *firmataBoard) handshake(ctx context.Context) error {
+	startCtx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+	var firmware []byte
+	for {
+		var err error
+		firmware, err = b.request(startCtx, sysex(sysexReportFirmware), sysexReportFirmware)
+		if err == nil && len(firmware) >= 2 {
+			break
+		}
+		if err == nil {
+			err = errors.New("malformed firmware report")
+		}
+		if startCtx.Err() != nil {
+			return errors.Wrap(err, "firmata board did not report its firmware")
+		}
+	}
+	b.firmware = fmt.Sprintf("%s %d.%d", decode7(firmware[2:]), firmware[0], firmware[1])
+	b.logger.Debugw("connected to firmata board", "firmware", b.firmware)
+
+	capabilities, err := b.request(ctx, sysex(sysexCapabilityQuery), sysexCapabilityResp)
+	if err != nil {
+		return errors.Wrap(err, "failed to query pin capabilities")
+	}
+	pins := []*pinState{{modes: map[byte]byte{}, analogChannel: -1}}
+	for i := 0; i < len(capabilities); {
+		if capabilities[i] == pinCapabilitiesEnd {
+			pins = append(pins, &pinState{modes: map[byte]byte{}, analogChannel: -1})
+			i++
+			continue
+		}
+		if i+1 >= len(capabilities) {
+			break
+		}
+		pins[len(pins)-1].modes[capabilities[i]] = capabilities[i+1]
+		i += 2
+	}
+	// every pin, including the last, ends with a terminator
+	pins = pins[:len(pins)-1]
+
+	mapping, err := b.request(ctx, sysex(sysexAnalogMapping), sysexAnalogMappingResp)
+	if err != nil {
+		return errors.Wrap(err, "failed to query analog mapping")
+	}
+	for pin, ch := range mapping {
+		if pin < len(pins) && ch != noAnalogChannel {
+			pins[pin].analogChannel = int(ch)
+		}
+	}
+
+	b.mu.Lock()
+	b.pins = pins
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *firmataBoard) configureServo(name string) error {
+	pin, err := b.pinNumber(name)
+	if err != nil {
+		return err
+	}
+	if err := b.checkMode(pin, ModeServo); err != nil {
+		return err
+	}
+	// configuring a servo attaches it and puts the pin in servo mode
+	data := append([]byte{byte(pin)}, splitValue(servoMinPulseUs, 2)...)
+	data = append(data, splitValue(servoMaxPulseUs, 2)...)
+	if err := b.write(sysex(sysexServoConfig, data...)); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pins[pin].mode, b.pins[pin].modeSet = ModeServo, true
+	b.servoPins[pin] = true
+	return nil
+}
+
+func (b *firmataBoard) configureAnalog(c board.AnalogConfig) error {
+	ch, err := parseAnalogChannel(c.Pin)
+	if err != nil {
+		return err
+	}
+	pin := -1
+	b.mu.Lock()
+	for i, p := range b.pins {
+		if p.analogChannel == ch {
+			pin = i
+		}
+	}
+	b.analogStates[ch] = &analogState{received: make(chan struct{})}
+	b.mu.Unlock()
+	if pin < 0 {
+		return errors.Errorf("board has no analog channel %d", ch)
+	}
+	if err := b.setMode(pin, ModeAnalog); err != nil {
+		return err
+	}
+	if err := b.write([]byte{reportAnalog | byte(ch), 1}); err != nil {
+		return err
+	}
+	b.analogs[c.Name] = board.SmoothAnalogReader(&analogReader{b: b, channel: ch}, c, b.logger)
+	return nil
+}
+
+func (b *firmataBoard) configureInterrupt(c board.DigitalInterruptConfig) error {
+	pin, err := b.pinNumber(c.Pin)
+	if err != nil {
+		return err
+	}
+	di, err := board.CreateDigitalInterrupt(c)
+	if err != nil {
+		return err
+	}
+	if err := b.setMode(pin, ModeInput); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.interrupts[c.Name] = di
+	b.interruptPins[pin] = append(b.interruptPins[pin], di)
+	b.mu.Unlock()
+	_, err = b.reportPort(pin/8, true)
+	return err
+}
+
+// pinNumber resolves a pin name to the number of the pin on the board.
+func (b *firmataBoard) pinNumber(name string) (int, error) {
+	n, analog, err := parsePin(name)
+	if err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if analog {
+		for pin, p := range b.pins {
+			if p.analogChannel == n {
+				return pin, nil
+			}
+		}
+		return 0, errors.Errorf("board has no analog pin %s", name)
+	}
+	if n >= len(b.pins) {
+		return 0, errors.Errorf("board has no pin %s", name)
+	}
+	return n, nil
+}
+
+func (b *firmataBoard) write(msg []byte) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	_, err := b.port.Write(msg)
+	return err
+}
+
+// request sends a sysex message and waits for the sysex reply with the given command.
+func (b *firmataBoard) request(ctx context.Context, msg []byte, reply byte) ([]byte, error) {
+	ch := make(chan []byte, 1)
+	b.mu.Lock()
+	b.waiters[reply] = ch
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		if b.waiters[reply] == ch {
+			delete(b.waiters, reply)
+		}
+		b.mu.Unlock()
+	}()
+
+	if err := b.write(msg); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	select {
+	case data := <-ch:
+		return data, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "no response from firmata board to sysex command %#x", msg[1])
+	}
+}
+
+func (b *firmataBoard) readLoop(reader *messageReader) {
+	for {
+		msg, err := reader.next()
+		if err != nil {
+			if atomic.LoadInt32(&b.closed) == 0 {
+				b.logger.Errorw("lost connection to firmata board", "error", err)
+			}
+			return
+		}
+		switch {
+		case msg.sysex && msg.command == sysexStringData:
+			b.logger.Debugw("firmata board says", "message", string(decode7(msg.data)))
+		case msg.sysex:
+			b.mu.Lock()
+			ch, ok := b.waiters[msg.command]
+			delete(b.waiters, msg.command)
+			b.mu.Unlock()
+			if ok {
+				ch <- msg.data
+			}
+		case msg.command == digitalMessage:
+			b.handlePortReport(int(msg.channel), msg.data[0]|msg.data[1]<<7)
+		case msg.command == analogMessage:
+			b.handleAnalogReport(int(msg.channel), joinValue(msg.data))
+		}
+	}
+}
+
+func (b *firmataBoard) handlePortReport(port int, value byte) {
+	type tick struct {
+		interrupt board.DigitalInterrupt
+		high      bool
+	}
+	var ticks []tick
+
+	b.mu.Lock()
+	ps, ok := b.ports[port]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	// the first report only establishes what the inputs were
+	if ps.reported {
+		changed := ps.value ^ value
+		for bit := 0; bit < 8; bit++ {
+			if changed&(1<<bit) == 0 {
+				continue
+			}
+			for _, di := range b.interruptPins[port*8+bit] {
+				ticks = append(ticks, tick{di, value&(1<<bit) != 0})
+			}
+		}
+	}
+	ps.value = value
+	if !ps.reported {
+		ps.reported = true
+		close(ps.received)
+	}
+	b.mu.Unlock()
+
+	now := uint64(time.Now().UnixNano())
+	for _, t := range ticks {
+		if err := t.interrupt.Tick(b.cancelCtx, t.high, now); err != nil {
+			b.logger.Debugw("failed to deliver digital interrupt tick", "error", err)
+		}
+	}
+}
+
+func (b *firmataBoard) handleAnalogReport(channel, value int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	as, ok := b.analogStates[channel]
+	if !ok {
+		return
+	}
+	as.value = value
+	if !as.reported {
+		as.reported = true
+		close(as.received)
+	}
+}
+
+// reportPort turns on reporting of a port. When refresh is set, the port state is forgotten
+// and the board asked to report it again, e.g. after a pin changed to an input.
+func (b *firmataBoard) reportPort(port int, refresh bool) (*portState, error) {
+	b.mu.Lock()
+	ps, ok := b.ports[port]
+	if !ok {
+		ps = &portState{received: make(chan struct{})}
+		b.ports[port] = ps
+	}
+	if ps.reporting && !refresh {
+		b.mu.Unlock()
+		return ps, nil
+	}
+	if ps.reported {
+		ps.reported = false
+		ps.received = make(chan struct{})
+	}
+	ps.reporting = true
+	b.mu.Unlock()
+	return ps, b.write([]byte{reportDigital | byte(port), 1})
+}
+
+// checkMode returns an error if the pin does not support the mode.
+func (b *firmataBoard) checkMode(pin int, mode byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.pins[pin].modes[mode]; !ok {
+		return errors.Errorf("pin %d does not support %s mode", pin, modeName(mode))
+	}
+	return nil
+}
+
+func (b *firmataBoard) setMode(pin int, mode byte) error {
+	if err := b.checkMode(pin, mode); err != nil {
+		return err
+	}
+	if err := b.write([]byte{setPinMode, byte(pin), mode}); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pins[pin].mode, b.pins[pin].modeSet = mode, true
+	return nil
+}
+
+func modeName(mode byte) string {
+	switch mode {
+	case ModeInput:
+		return "input"
+	case ModeOutput:
+		return "output"
+	case ModeAnalog:
+		return "analog"
+	case ModePWM:
+		return "PWM"
+	case ModeServo:
+		return "servo"
+	case ModeI2C:
+		return "I2C"
+	case ModePullup:
+		return "pullup"
+	default:
+		return fmt.Sprintf("%#x", mode)
+	}
+}
+
+// SPIByName returns an SPI bus by name.
+func (b *firmataBoard) SPIByName(name string) (board.SPI, bool) {
+	return nil, false
+}
+
+// I2CByName returns an I2C bus by name.
+func (b *firmataBoard) I2CByName(name string) (board.I2C, bool) {
+	if b.i2c == nil || name != b.i2cName {
+		return nil, false
+	}
+	return b.i2c, true
+}
+
+// AnalogReaderByName returns an analog reader by name.
+func (b *firmataBoard) AnalogReaderByName(name string) (board.AnalogReader, bool) {
+	a, ok := b.analogs[name]
+	return a, ok
+}
+
+// DigitalInterruptByName returns a digital interrupt by name.
+func (b *firmataBoard) DigitalInterruptByName(name string) (board.DigitalInterrupt, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	d, ok := b.interrupts[name]
+	return d, ok
+}
+
+// SPINames returns the names of all known SPI buses.
+func (b *firmataBoard) SPINames() []string {
+	return nil
+}
+
+// I2CNames returns the names of all known I2C buses.
+func (b *firmataBoard) I2CNames() []string {
+	if b.i2c == nil {
+		return nil
+	}
+	return []string{b.i2cName}
+}
+
+// AnalogReaderNames returns the names of all known analog readers.
+func (b *firmataBoard) AnalogReaderNames() []string {
+	names := []string{}
+	for n := range b.analogs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DigitalInterruptNames returns the names of all known digital interrupts.
+func (b *firmataBoard) DigitalInterruptNames() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	names := []string{}
+	for n := range b.interrupts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GPIOPinNames returns the names of all pins usable as GPIOs.
+func (b *firmataBoard) GPIOPinNames() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	names := []string{}
+	for pin, p := range b.pins {
+		if _, ok := p.modes[ModeOutput]; ok {
+			names = append(names, strconv.Itoa(pin))
+		}
+	}
+	return names
+}
+
+// GPIOPinByName returns the GPIO pin by the given name, either a pin number or an analog
+// pin like "A0".
+func (b *firmataBoard) GPIOPinByName(name string) (board.GPIOPin, error) {
+	pin, err := b.pinNumber(name)
+	if err != nil {
+		return nil, err
+	}
+	return &gpioPin{b: b, pin: pin}, nil
+}
+
+// Status returns the current status of the board.
+func (b *firmataBoard) Status(ctx context.Context, extra map[string]interface{}) (*commonpb.BoardStatus, error) {
+	return board.CreateStatus(ctx, b, extra)
+}
+
+// ModelAttributes returns attributes related to the model of this board.
+func (b *firmataBoard) ModelAttributes() board.ModelAttributes {
+	return board.ModelAttributes{}
+}
+
+// Close stops listening to the board and closes its serial port.
+func (b *firmataBoard) Close() error {
+	if !atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		return nil
+	}
+	b.cancel()
+	for _, a := range b.analogs {
+		if smoother, ok := a.(*board.AnalogSmoother); ok {
+			smoother.Close()
+		}
+	}
+	err := b.port.Close()
+	b.activeBackgroundWorkers.Wait()
+	return err
+}
+
+type gpioPin struct {
+	b   *firmataBoard
+	pin int
+}
+
+// Set switches the pin to an output if needed and sets it high or low.
+func (gp *gpioPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	gp.b.mu.Lock()
+	p := gp.b.pins[gp.pin]
+	isOutput := p.modeSet && p.mode == ModeOutput
+	gp.b.mu.Unlock()
+	if !isOutput {
+		if err := gp.b.setMode(gp.pin, ModeOutput); err != nil {
+			return err
+		}
+	}
+	var value byte
+	if high {
+		value = 1
+	}
+	if err := gp.b.write([]byte{setDigitalPin, byte(gp.pin), value}); err != nil {
+		return err
+	}
+	gp.b.mu.Lock()
+	defer gp.b.mu.Unlock()
+	p.high = high
+	p.duty = float64(value)
+	return nil
+}
+
+// Get returns the last value set on output pins. Any other pin is switched to an input if
+// needed and reports the level last reported by the board.
+func (gp *gpioPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	gp.b.mu.Lock()
+	p := gp.b.pins[gp.pin]
+	if p.modeSet && p.mode == ModeOutput {
+		high := p.high
+		gp.b.mu.Unlock()
+		return high, nil
+	}
+	isInput := p.modeSet && (p.mode == ModeInput || p.mode == ModePullup)
+	gp.b.mu.Unlock()
+
+	if !isInput {
+		if err := gp.b.setMode(gp.pin, ModeInput); err != nil {
+			return false, err
+		}
+	}
+	ps, err := gp.b.reportPort(gp.pin/8, !isInput)
+	if err != nil {
+		return false, err
+	}
+	gp.b.mu.Lock()
+	received := ps.received
+	gp.b.mu.Unlock()
+	select {
+	case <-received:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-time.After(requestTimeout):
+		return false, errors.Errorf("firmata board did not report pin %d", gp.pin)
+	}
+	gp.b.mu.Lock()
+	defer gp.b.mu.Unlock()
+	return ps.value&(1<<(gp.pin%8)) != 0, nil
+}
+
+// PWM returns the last duty cycle set on the pin.
+func (gp *gpioPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	gp.b.mu.Lock()
+	defer gp.b.mu.Unlock()
+	return gp.b.pins[gp.pin].duty, nil
+}
+
+// SetPWM sets the duty cycle of the pin. Servo pins turn the duty cycle into a pulse width at
+// 50Hz, so servos driven by duty cycle work unchanged. Pins without PWM can still be fully on or off.
+func (gp *gpioPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	if dutyCyclePct < 0 || dutyCyclePct > 1 {
+		return errors.Errorf("duty cycle %v is out of range", dutyCyclePct)
+	}
+	gp.b.mu.Lock()
+	p := gp.b.pins[gp.pin]
+	isServo := gp.b.servoPins[gp.pin]
+	resolution, hasPWM := p.modes[ModePWM]
+	isPWM := p.modeSet && p.mode == ModePWM
+	gp.b.mu.Unlock()
+
+	var value int
+	switch {
+	case isServo:
+		value = int(math.Round(dutyCyclePct * 1e6 / servoFrequencyHz))
+	case hasPWM:
+		if !isPWM {
+			if err := gp.b.setMode(gp.pin, ModePWM); err != nil {
+				return err
+			}
+		}
+		value = int(math.Round(dutyCyclePct * float64(int(1)<<resolution-1)))
+	case dutyCyclePct == 0 || dutyCyclePct == 1:
+		return gp.Set(ctx, dutyCyclePct == 1, extra)
+	default:
+		return errors.Errorf("pin %d does not support PWM", gp.pin)
+	}
+
+	if err := gp.b.writeAnalog(gp.pin, value); err != nil {
+		return err
+	}
+	gp.b.mu.Lock()
+	defer gp.b.mu.Unlock()
+	p.duty = dutyCyclePct
+	return nil
+}
+
+// PWMFreq returns 50Hz for servo pins. Firmata does not expose the PWM frequency of other
+// pins, so they report 0 for the board's default.
+func (gp *gpioPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	gp.b.mu.Lock()
+	defer gp.b.mu.Unlock()
+	if gp.b.servoPins[gp.pin] {
+		return servoFrequencyHz, nil
+	}
+	return 0, nil
+}
+
+// SetPWMFreq only accepts the frequency the pin already runs at.
+func (gp *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	current, err := gp.PWMFreq(ctx, extra)
+	if err != nil {
+		return err
+	}
+	if freqHz == 0 || freqHz == current {
+		return nil
+	}
+	return errors.New("firmata boards do not support setting the PWM frequency")
+}
+
+// writeAnalog writes a PWM or servo value, falling back to an extended analog message for
+// pins and values the short message cannot carry.
+func (b *firmataBoard) writeAnalog(pin, value int) error {
+	if pin <= 15 && value < 1<<14 {
+		return b.write([]byte{analogMessage | byte(pin), byte(value) & 0x7F, byte(value>>7) & 0x7F})
+	}
+	return b.write(sysex(sysexExtendedAnalog, append([]byte{byte(pin)}, splitValue(value, 3)...)...))
+}
+
+type analogReader struct {
+	b       *firmataBoard
+	channel int
+}
+
+// Read returns the value last reported on the channel.
+func (ar *analogReader) Read(ctx context.Context, extra map[string]interface{}) (int, error) {
+	ar.b.mu.Lock()
+	as := ar.b.analogStates[ar.channel]
+	ar.b.mu.Unlock()
+	select {
+	case <-as.received:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(requestTimeout):
+		return 0, errors.Errorf("firmata board did not report analog channel %d", ar.channel)
+	}
+	ar.b.mu.Lock()
+	defer ar.b.mu.Unlock()
+	return as.value, nil
+}
+
+// i2cBus is the single I2C bus of the microcontroller.
+type i2cBus struct {
+	b  *firmataBoard
+	mu sync.Mutex
+}
+
+// OpenHandle locks the bus until the handle is closed.
+func (bus *i2cBus) OpenHandle(addr byte) (board.I2CHandle, error) {
+	if addr > 0x7F {
+		return nil, errors.Errorf("I2C address %#x does not fit in 7 bits", addr)
+	}
+	bus.mu.Lock()
+	return &i2cHandle{bus: bus, addr: addr}, nil
+}
+
+type i2cHandle struct {
+	bus      *i2cBus
+	addr     byte
+	isClosed bool
+}
+
+func (h *i2cHandle) Write(ctx context.Context, tx []byte) error {
+	if h.isClosed {
+		return errors.New("can't use Write() on an already closed I2CHandle")
+	}
+	return h.bus.b.write(sysex(sysexI2CRequest, append([]byte{h.addr, i2cModeWrite}, encode7(tx)...)...))
+}
+
+// read reads count bytes, starting at register unless it is negative.
+func (h *i2cHandle) read(ctx context.Context, register, count int) ([]byte, error) {
+	if h.isClosed {
+		return nil, errors.New("can't use Read() on an already closed I2CHandle")
+	}
+	data := []byte{h.addr, i2cModeReadOnce}
+	if register >= 0 {
+		data = append(data, encode7([]byte{byte(register)})...)
+	}
+	data = append(data, splitValue(count, 2)...)
+	reply, err := h.bus.b.request(ctx, sysex(sysexI2CRequest, data...), sysexI2CReply)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read from I2C device %#x", h.addr)
+	}
+	// the reply carries the address and register ahead of the data
+	decoded := decode7(reply)
+	if len(decoded) != count+2 || decoded[0] != h.addr {
+		return nil, errors.Errorf("unexpected I2C reply from device %#x", h.addr)
+	}
+	return decoded[2:], nil
+}
+
+func (h *i2cHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	return h.read(ctx, -1, count)
+}
+
+func (h *i2cHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	data, err := h.read(ctx, int(register), 1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+func (h *i2cHandle) WriteByteData(ctx context.Context, register, data byte) error {
+	return h.Write(ctx, []byte{register, data})
+}
+
+func (h *i2cHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+	return h.read(ctx, int(register), int(numBytes))
+}
+
+func (h *i2cHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
+	return h.Write(ctx, append([]byte{register}, data...))
+}
+
+func (h *i2cHandle) Close() error {
+	if h.isClosed {
+		return nil
+	}
+	h.isClosed = true
+	h.bus.mu.Unlock()
+	return nil
+}
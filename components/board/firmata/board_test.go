@@ -0,0 +1,322 @@
+//go:build linux
+
+package firmata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := Config{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"serial_path" is required`)
+
+	conf.SerialPath = "/dev/ttyACM0"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Analogs = []board.AnalogConfig{{Name: "a", Pin: "A16"}}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "out of range")
+	conf.Analogs = []board.AnalogConfig{{Name: "a", Pin: "3"}}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.DigitalInterrupts = []board.DigitalInterruptConfig{{Name: "i", Pin: "x"}}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `invalid pin "x"`)
+	conf.DigitalInterrupts = []board.DigitalInterruptConfig{{Name: "i", Pin: "A2"}}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.ServoPins = []string{"Ax"}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `invalid analog pin "Ax"`)
+}
+
+func TestProtocol(t *testing.T) {
+	test.That(t, decode7(encode7([]byte{0x00, 0x7F, 0x80, 0xFF})), test.ShouldResemble, []byte{0x00, 0x7F, 0x80, 0xFF})
+	test.That(t, splitValue(1500, 2), test.ShouldResemble, []byte{0x5C, 0x0B})
+	test.That(t, joinValue(splitValue(123456, 3)), test.ShouldEqual, 123456)
+}
+
+func newTestBoard(t *testing.T, conf *Config) (*firmataBoard, *Emulator) {
+	t.Helper()
+	emulator, err := NewEmulator()
+	test.That(t, err, test.ShouldBeNil)
+	conf.SerialPath = emulator.Path()
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	b, err := connect(context.Background(), conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return b.(*firmataBoard), emulator
+}
+
+func TestGPIO(t *testing.T) {
+	ctx := context.Background()
+	b, emulator := newTestBoard(t, &Config{ServoPins: []string{"9", "A3"}})
+	defer func() {
+		test.That(t, b.Close(), test.ShouldBeNil)
+		test.That(t, emulator.Close(), test.ShouldBeNil)
+	}()
+	test.That(t, b.firmware, test.ShouldEqual, "StandardFirmata.ino 2.5")
+	test.That(t, b.GPIOPinNames(), test.ShouldHaveLength, 18)
+
+	_, err := b.GPIOPinByName("42")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = b.GPIOPinByName("A9")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// the serial pins cannot be used
+	pin, err := b.GPIOPinByName("0")
+	test.That(t, err, test.ShouldBeNil)
+	err = pin.Set(ctx, true, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not support output mode")
+
+	t.Run("outputs", func(t *testing.T) {
+		pin, err := b.GPIOPinByName("13")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.PinMode(13), test.ShouldEqual, ModeOutput)
+			test.That(tb, emulator.DigitalOutput(13), test.ShouldBeTrue)
+		})
+		high, err := pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeTrue)
+
+		test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.DigitalOutput(13), test.ShouldBeFalse)
+		})
+	})
+
+	t.Run("inputs", func(t *testing.T) {
+		emulator.SetDigitalInput(7, true)
+		pin, err := b.GPIOPinByName("7")
+		test.That(t, err, test.ShouldBeNil)
+		high, err := pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeTrue)
+		test.That(t, emulator.PinMode(7), test.ShouldEqual, ModeInput)
+
+		emulator.SetDigitalInput(7, false)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			high, err := pin.Get(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, high, test.ShouldBeFalse)
+		})
+
+		// analog pins work as digital inputs too
+		emulator.SetDigitalInput(15, true)
+		pin, err = b.GPIOPinByName("A1")
+		test.That(t, err, test.ShouldBeNil)
+		high, err = pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeTrue)
+	})
+
+	t.Run("pwm", func(t *testing.T) {
+		pin, err := b.GPIOPinByName("3")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.SetPWM(ctx, 0.5, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.PinMode(3), test.ShouldEqual, ModePWM)
+			test.That(tb, emulator.AnalogOutput(3), test.ShouldEqual, 128)
+		})
+		duty, err := pin.PWM(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, duty, test.ShouldEqual, 0.5)
+		freq, err := pin.PWMFreq(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, freq, test.ShouldEqual, 0)
+		test.That(t, pin.SetPWMFreq(ctx, 0, nil), test.ShouldBeNil)
+		test.That(t, pin.SetPWMFreq(ctx, 1000, nil), test.ShouldNotBeNil)
+		test.That(t, pin.SetPWM(ctx, 1.5, nil), test.ShouldNotBeNil)
+
+		// pins without PWM can only be fully on or off
+		pin, err = b.GPIOPinByName("4")
+		test.That(t, err, test.ShouldBeNil)
+		err = pin.SetPWM(ctx, 0.5, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "does not support PWM")
+		test.That(t, pin.SetPWM(ctx, 1, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.DigitalOutput(4), test.ShouldBeTrue)
+		})
+	})
+
+	t.Run("servos", func(t *testing.T) {
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.PinMode(9), test.ShouldEqual, ModeServo)
+		})
+		pin, err := b.GPIOPinByName("9")
+		test.That(t, err, test.ShouldBeNil)
+		freq, err := pin.PWMFreq(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, freq, test.ShouldEqual, 50)
+		test.That(t, pin.SetPWMFreq(ctx, 50, nil), test.ShouldBeNil)
+		test.That(t, pin.SetPWM(ctx, 0.075, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.AnalogOutput(9), test.ShouldEqual, 1500)
+		})
+
+		// pins above 15 need extended analog messages
+		pin, err = b.GPIOPinByName("A3")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.SetPWM(ctx, 0.1, nil), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, emulator.PinMode(17), test.ShouldEqual, ModeServo)
+			test.That(tb, emulator.AnalogOutput(17), test.ShouldEqual, 2000)
+		})
+	})
+}
+
+func TestAnalogsAndInterrupts(t *testing.T) {
+	ctx := context.Background()
+	b, emulator := newTestBoard(t, &Config{
+		SamplingIntervalMs: 50,
+		Analogs:            []board.AnalogConfig{{Name: "pot", Pin: "A0"}, {Name: "light", Pin: "2"}},
+		DigitalInterrupts:  []board.DigitalInterruptConfig{{Name: "button", Pin: "2"}},
+	})
+	defer func() {
+		test.That(t, b.Close(), test.ShouldBeNil)
+		test.That(t, emulator.Close(), test.ShouldBeNil)
+	}()
+	test.That(t, b.AnalogReaderNames(), test.ShouldResemble, []string{"light", "pot"})
+	test.That(t, b.DigitalInterruptNames(), test.ShouldResemble, []string{"button"})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, emulator.SamplingInterval(), test.ShouldEqual, 50)
+		test.That(tb, emulator.PinMode(14), test.ShouldEqual, ModeAnalog)
+		test.That(tb, emulator.PinMode(16), test.ShouldEqual, ModeAnalog)
+		test.That(tb, emulator.PinMode(2), test.ShouldEqual, ModeInput)
+	})
+
+	pot, ok := b.AnalogReaderByName("pot")
+	test.That(t, ok, test.ShouldBeTrue)
+	v, err := pot.Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0)
+	emulator.SetAnalogInput(0, 1023)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		v, err := pot.Read(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, v, test.ShouldEqual, 1023)
+	})
+
+	button, ok := b.DigitalInterruptByName("button")
+	test.That(t, ok, test.ShouldBeTrue)
+	ticks := make(chan board.Tick, 4)
+	button.AddCallback(ticks)
+	defer button.RemoveCallback(ticks)
+	// changes only count once the board has reported where the input started
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		test.That(tb, b.ports[0].reported, test.ShouldBeTrue)
+	})
+	emulator.SetDigitalInput(2, true)
+	emulator.SetDigitalInput(2, false)
+	emulator.SetDigitalInput(2, true)
+	for _, high := range []bool{true, false, true} {
+		select {
+		case tick := <-ticks:
+			test.That(t, tick.High, test.ShouldEqual, high)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for tick")
+		}
+	}
+	count, err := button.Value(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, count, test.ShouldEqual, 2)
+}
+
+func TestI2C(t *testing.T) {
+	ctx := context.Background()
+	b, emulator := newTestBoard(t, &Config{I2CBus: "main"})
+	defer func() {
+		test.That(t, b.Close(), test.ShouldBeNil)
+		test.That(t, emulator.Close(), test.ShouldBeNil)
+	}()
+	test.That(t, b.I2CNames(), test.ShouldResemble, []string{"main"})
+	_, ok := b.I2CByName("other")
+	test.That(t, ok, test.ShouldBeFalse)
+	bus, ok := b.I2CByName("main")
+	test.That(t, ok, test.ShouldBeTrue)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, emulator.PinMode(18), test.ShouldEqual, ModeI2C)
+	})
+
+	emulator.SetI2CRegisters(0x48, 0x10, []byte{0x01, 0x82, 0xFF})
+	handle, err := bus.OpenHandle(0x48)
+	test.That(t, err, test.ShouldBeNil)
+
+	data, err := handle.ReadBlockData(ctx, 0x10, 3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte{0x01, 0x82, 0xFF})
+	v, err := handle.ReadByteData(ctx, 0x11)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0x82)
+
+	test.That(t, handle.WriteByteData(ctx, 0x20, 0xAB), test.ShouldBeNil)
+	test.That(t, handle.WriteBlockData(ctx, 0x30, []byte{1, 2}), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, emulator.I2CRegisters(0x48, 0x20, 1), test.ShouldResemble, []byte{0xAB})
+		test.That(tb, emulator.I2CRegisters(0x48, 0x30, 2), test.ShouldResemble, []byte{1, 2})
+	})
+
+	// a plain write sets the register pointer for plain reads
+	test.That(t, handle.Write(ctx, []byte{0x10}), test.ShouldBeNil)
+	data, err = handle.Read(ctx, 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte{0x01, 0x82})
+	test.That(t, handle.Close(), test.ShouldBeNil)
+	_, err = handle.Read(ctx, 1)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	handle, err = bus.OpenHandle(0x50)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, handle.Close(), test.ShouldBeNil)
+	}()
+	_, err = handle.ReadByteData(ctx, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "failed to read from I2C device 0x50")
+}
+
+func TestNoFirmware(t *testing.T) {
+	master, slave, err := pty.Open()
+	test.That(t, err, test.ShouldBeNil)
+	defer utils.UncheckedErrorFunc(master.Close)
+	defer utils.UncheckedErrorFunc(slave.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	_, err = connect(ctx, &Config{SerialPath: slave.Name()}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "did not report its firmware")
+}
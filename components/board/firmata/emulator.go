@@ -0,0 +1,407 @@
+//go:build linux
+
+package firmata
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/creack/pty"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"golang.org/x/sys/unix"
+)
+
+// The emulated board is laid out like an Arduino Uno: pins 0 and 1 are taken by the serial
+// port, pins 14 to 19 are analog channels 0 to 5 and pins 18 and 19 double as I2C.
+const (
+	emulatorPins        = 20
+	emulatorFirstAnalog = 14
+	emulatorAnalogs     = 6
+	emulatorAnalogBits  = 10
+	emulatorPWMBits     = 8
+	emulatorServoBits   = 14
+)
+
+var emulatorPWMPins = map[int]bool{3: true, 5: true, 6: true, 9: true, 10: true, 11: true}
+
+type emulatedPin struct {
+	mode        byte
+	output      bool
+	input       bool
+	analogValue int
+}
+
+// An Emulator acts like an Arduino Uno running StandardFirmata on the other end of a pseudo
+// terminal, so Firmata boards can be used without hardware. Configure a board with the
+// emulator's Path as its serial path.
+type Emulator struct {
+	master *os.File
+	slave  *os.File
+	fd     int
+
+	mu               sync.Mutex
+	pins             [emulatorPins]emulatedPin
+	reportPorts      map[int]bool
+	lastPorts        map[int]byte
+	reportAnalogs    map[int]bool
+	analogInputs     [emulatorAnalogs]int
+	samplingInterval int
+	i2cEnabled       bool
+	i2cDevices       map[byte]*emulatedI2CDevice
+
+	writeMu                 sync.Mutex
+	closed                  int32
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// emulatedI2CDevice is a register file whose register pointer is set by the first byte written.
+type emulatedI2CDevice struct {
+	registers [256]byte
+	pointer   byte
+}
+
+// NewEmulator starts an emulated board.
+func NewEmulator() (*Emulator, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	e := &Emulator{
+		master:        master,
+		slave:         slave,
+		reportPorts:   map[int]bool{},
+		lastPorts:     map[int]byte{},
+		reportAnalogs: map[int]bool{},
+		i2cDevices:    map[byte]*emulatedI2CDevice{},
+	}
+	if err := makeRaw(int(slave.Fd())); err != nil {
+		return nil, multierr.Combine(err, master.Close(), slave.Close())
+	}
+	e.fd = int(master.Fd())
+
+	reader := newMessageReader(&emulatorReader{e})
+	e.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for {
+			msg, err := reader.next()
+			if err != nil {
+				return
+			}
+			e.handle(msg)
+		}
+	}, e.activeBackgroundWorkers.Done)
+	return e, nil
+}
+
+// makeRaw turns off the line discipline so bytes written before the board opens the port
+// arrive untouched.
+func makeRaw(fd int) error {
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	return unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+}
+
+// emulatorReader polls the pseudo terminal so the emulator can notice being closed.
+type emulatorReader struct {
+	e *Emulator
+}
+
+func (er *emulatorReader) Read(p []byte) (int, error) {
+	for {
+		if atomic.LoadInt32(&er.e.closed) == 1 {
+			return 0, io.EOF
+		}
+		fds := []unix.PollFd{{Fd: int32(er.e.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, readPollMillis)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return 0, err
+		}
+		if n > 0 && fds[0].Revents&unix.POLLIN != 0 {
+			return er.e.master.Read(p)
+		}
+	}
+}
+
+// Path returns the path of the serial port the emulated board is connected to.
+func (e *Emulator) Path() string {
+	return e.slave.Name()
+}
+
+// Close stops the emulated board.
+func (e *Emulator) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return nil
+	}
+	e.activeBackgroundWorkers.Wait()
+	return multierr.Combine(e.master.Close(), e.slave.Close())
+}
+
+// SetDigitalInput sets the level seen by a pin when it is an input.
+func (e *Emulator) SetDigitalInput(pin int, high bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pins[pin].input = high
+	e.sendPortLocked(pin/8, false)
+}
+
+// SetAnalogInput sets the value read from an analog channel.
+func (e *Emulator) SetAnalogInput(channel, value int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.analogInputs[channel] = value
+	if e.reportAnalogs[channel] {
+		e.send([]byte{analogMessage | byte(channel), byte(value) & 0x7F, byte(value>>7) & 0x7F})
+	}
+}
+
+// PinMode returns the mode of a pin.
+func (e *Emulator) PinMode(pin int) byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pins[pin].mode
+}
+
+// DigitalOutput returns the level of an output pin.
+func (e *Emulator) DigitalOutput(pin int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pins[pin].output
+}
+
+// AnalogOutput returns the last PWM value, or servo pulse width in microseconds, written to a pin.
+func (e *Emulator) AnalogOutput(pin int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pins[pin].analogValue
+}
+
+// SamplingInterval returns the analog sampling interval requested by the host, 0 if never set.
+func (e *Emulator) SamplingInterval() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.samplingInterval
+}
+
+// SetI2CRegisters sets registers of an I2C device, adding the device to the bus if needed.
+func (e *Emulator) SetI2CRegisters(addr, register byte, data []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	dev := e.i2cDevice(addr)
+	for i, b := range data {
+		dev.registers[register+byte(i)] = b
+	}
+}
+
+// I2CRegisters returns registers of an I2C device, or nil if there is no such device.
+func (e *Emulator) I2CRegisters(addr, register byte, count int) []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	dev, ok := e.i2cDevices[addr]
+	if !ok {
+		return nil
+	}
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = dev.registers[register+byte(i)]
+	}
+	return out
+}
+
+func (e *Emulator) i2cDevice(addr byte) *emulatedI2CDevice {
+	dev, ok := e.i2cDevices[addr]
+	if !ok {
+		dev = &emulatedI2CDevice{}
+		e.i2cDevices[addr] = dev
+	}
+	return dev
+}
+
+func (e *Emulator) send(msg []byte) {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	//nolint:errcheck
+	e.master.Write(msg)
+}
+
+// sendPortLocked reports the inputs of a port if it is being reported and they changed.
+func (e *Emulator) sendPortLocked(port int, force bool) {
+	if !e.reportPorts[port] {
+		return
+	}
+	var value byte
+	for bit := 0; bit < 8; bit++ {
+		pin := port*8 + bit
+		if pin >= emulatorPins {
+			break
+		}
+		p := e.pins[pin]
+		if (p.mode == ModeInput || p.mode == ModePullup) && p.input {
+			value |= 1 << bit
+		}
+	}
+	if !force && value == e.lastPorts[port] {
+		return
+	}
+	e.lastPorts[port] = value
+	e.send([]byte{digitalMessage | byte(port), value & 0x7F, value >> 7})
+}
+
+func (e *Emulator) handle(msg message) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if msg.sysex {
+		e.handleSysex(msg.command, msg.data)
+		return
+	}
+	switch msg.command {
+	case setPinMode:
+		pin := int(msg.data[0])
+		if pin < emulatorPins {
+			e.pins[pin].mode = msg.data[1]
+			e.sendPortLocked(pin/8, false)
+		}
+	case setDigitalPin:
+		pin := int(msg.data[0])
+		if pin < emulatorPins && e.pins[pin].mode == ModeOutput {
+			e.pins[pin].output = msg.data[1] != 0
+		}
+	case digitalMessage:
+		value := msg.data[0] | msg.data[1]<<7
+		for bit := 0; bit < 8; bit++ {
+			pin := int(msg.channel)*8 + bit
+			if pin < emulatorPins && e.pins[pin].mode == ModeOutput {
+				e.pins[pin].output = value&(1<<bit) != 0
+			}
+		}
+	case analogMessage:
+		e.analogWrite(int(msg.channel), joinValue(msg.data))
+	case reportDigital:
+		port := int(msg.channel)
+		e.reportPorts[port] = msg.data[0] != 0
+		e.sendPortLocked(port, true)
+	case reportAnalog:
+		ch := int(msg.channel)
+		if ch < emulatorAnalogs {
+			e.reportAnalogs[ch] = msg.data[0] != 0
+			if e.reportAnalogs[ch] {
+				v := e.analogInputs[ch]
+				e.send([]byte{analogMessage | byte(ch), byte(v) & 0x7F, byte(v>>7) & 0x7F})
+			}
+		}
+	case systemReset:
+		e.reportPorts = map[int]bool{}
+		e.reportAnalogs = map[int]bool{}
+	}
+}
+
+func (e *Emulator) analogWrite(pin, value int) {
+	if pin >= emulatorPins {
+		return
+	}
+	if mode := e.pins[pin].mode; mode == ModePWM || mode == ModeServo {
+		e.pins[pin].analogValue = value
+	}
+}
+
+func (e *Emulator) handleSysex(command byte, data []byte) {
+	switch command {
+	case sysexReportFirmware:
+		e.send(sysex(sysexReportFirmware, append([]byte{2, 5}, encode7([]byte("StandardFirmata.ino"))...)...))
+	case sysexCapabilityQuery:
+		var resp []byte
+		for pin := 0; pin < emulatorPins; pin++ {
+			if pin >= 2 {
+				resp = append(resp, ModeInput, 1, ModeOutput, 1, ModePullup, 1, ModeServo, emulatorServoBits)
+			}
+			if emulatorPWMPins[pin] {
+				resp = append(resp, ModePWM, emulatorPWMBits)
+			}
+			if pin >= emulatorFirstAnalog {
+				resp = append(resp, ModeAnalog, emulatorAnalogBits)
+			}
+			if pin == 18 || pin == 19 {
+				resp = append(resp, ModeI2C, 1)
+			}
+			resp = append(resp, pinCapabilitiesEnd)
+		}
+		e.send(sysex(sysexCapabilityResp, resp...))
+	case sysexAnalogMapping:
+		resp := make([]byte, emulatorPins)
+		for pin := range resp {
+			resp[pin] = noAnalogChannel
+			if pin >= emulatorFirstAnalog {
+				resp[pin] = byte(pin - emulatorFirstAnalog)
+			}
+		}
+		e.send(sysex(sysexAnalogMappingResp, resp...))
+	case sysexSamplingInterval:
+		e.samplingInterval = joinValue(data)
+	case sysexExtendedAnalog:
+		if len(data) > 1 {
+			e.analogWrite(int(data[0]), joinValue(data[1:]))
+		}
+	case sysexServoConfig:
+		if len(data) > 0 && int(data[0]) < emulatorPins {
+			e.pins[data[0]].mode = ModeServo
+		}
+	case sysexI2CConfig:
+		e.i2cEnabled = true
+		e.pins[18].mode, e.pins[19].mode = ModeI2C, ModeI2C
+	case sysexI2CRequest:
+		e.handleI2C(data)
+	}
+}
+
+func (e *Emulator) handleI2C(data []byte) {
+	if !e.i2cEnabled || len(data) < 2 {
+		return
+	}
+	addr, mode := data[0], data[1]&0x18
+	dev, ok := e.i2cDevices[addr]
+	if !ok {
+		// what StandardFirmata reports when nothing acknowledges the address
+		e.send(sysex(sysexStringData, encode7([]byte("I2C: Too few bytes received"))...))
+		return
+	}
+	payload := data[2:]
+	switch mode {
+	case i2cModeWrite:
+		bytes := decode7(payload)
+		if len(bytes) == 0 {
+			return
+		}
+		dev.pointer = bytes[0]
+		for _, b := range bytes[1:] {
+			dev.registers[dev.pointer] = b
+			dev.pointer++
+		}
+	case i2cModeReadOnce:
+		register := byte(0xFF)
+		if len(payload) == 4 {
+			register = payload[0] | payload[1]<<7
+			dev.pointer = register
+			payload = payload[2:]
+		}
+		count := joinValue(payload)
+		reply := []byte{addr, register}
+		for i := 0; i < count; i++ {
+			reply = append(reply, dev.registers[dev.pointer])
+			dev.pointer++
+		}
+		e.send(sysex(sysexI2CReply, encode7(reply)...))
+	}
+}
@@ -0,0 +1,179 @@
+package firmata
+
+import (
+	"bufio"
+	"io"
+)
+
+// Firmata message commands. The low nibble of the channel commands carries a port, pin or
+// analog channel.
+const (
+	digitalMessage   = 0x90
+	analogMessage    = 0xE0
+	reportAnalog     = 0xC0
+	reportDigital    = 0xD0
+	setPinMode       = 0xF4
+	setDigitalPin    = 0xF5
+	protocolVersion  = 0xF9
+	systemReset      = 0xFF
+	startSysex       = 0xF0
+	endSysex         = 0xF7
+	channelCmdMask   = 0xF0
+	channelValueMask = 0x0F
+)
+
+// Sysex commands.
+const (
+	sysexExtendedAnalog    = 0x6F
+	sysexServoConfig       = 0x70
+	sysexStringData        = 0x71
+	sysexI2CRequest        = 0x76
+	sysexI2CReply          = 0x77
+	sysexI2CConfig         = 0x78
+	sysexReportFirmware    = 0x79
+	sysexSamplingInterval  = 0x7A
+	sysexAnalogMapping     = 0x69
+	sysexAnalogMappingResp = 0x6A
+	sysexCapabilityQuery   = 0x6B
+	sysexCapabilityResp    = 0x6C
+)
+
+// I2C request modes, shifted into bits 3 and 4 of the second address byte.
+const (
+	i2cModeWrite    = 0x00
+	i2cModeReadOnce = 0x08
+)
+
+// Pin modes as defined by the Firmata protocol.
+const (
+	ModeInput  = 0x00
+	ModeOutput = 0x01
+	ModeAnalog = 0x02
+	ModePWM    = 0x03
+	ModeServo  = 0x04
+	ModeI2C    = 0x06
+	ModePullup = 0x0B
+)
+
+// noAnalogChannel marks pins without an analog channel in an analog mapping response.
+const noAnalogChannel = 0x7F
+
+// Firmata only sends seven bits per data byte; the capability response ends each pin with this.
+const pinCapabilitiesEnd = 0x7F
+
+// A message is a single decoded Firmata message. For sysex messages the command is the sysex
+// command and data excludes the start and end bytes.
+type message struct {
+	command byte
+	channel byte
+	sysex   bool
+	data    []byte
+}
+
+// messageReader splits a Firmata byte stream into messages.
+type messageReader struct {
+	r *bufio.Reader
+}
+
+func newMessageReader(r io.Reader) *messageReader {
+	return &messageReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next message, skipping bytes that do not start a known one.
+func (mr *messageReader) next() (message, error) {
+	for {
+		b, err := mr.r.ReadByte()
+		if err != nil {
+			return message{}, err
+		}
+		if b < 0x80 {
+			continue
+		}
+		var msg message
+		var dataLen int
+		switch {
+		case b == startSysex:
+			return mr.readSysex()
+		case b&channelCmdMask == digitalMessage, b&channelCmdMask == analogMessage:
+			msg = message{command: b & channelCmdMask, channel: b & channelValueMask}
+			dataLen = 2
+		case b&channelCmdMask == reportAnalog, b&channelCmdMask == reportDigital:
+			msg = message{command: b & channelCmdMask, channel: b & channelValueMask}
+			dataLen = 1
+		case b == setPinMode, b == setDigitalPin, b == protocolVersion:
+			msg = message{command: b}
+			dataLen = 2
+		case b == systemReset:
+			return message{command: b}, nil
+		default:
+			continue
+		}
+		msg.data = make([]byte, dataLen)
+		if _, err := io.ReadFull(mr.r, msg.data); err != nil {
+			return message{}, err
+		}
+		return msg, nil
+	}
+}
+
+func (mr *messageReader) readSysex() (message, error) {
+	var data []byte
+	for {
+		b, err := mr.r.ReadByte()
+		if err != nil {
+			return message{}, err
+		}
+		if b == endSysex {
+			break
+		}
+		data = append(data, b)
+	}
+	if len(data) == 0 {
+		return mr.next()
+	}
+	return message{command: data[0], sysex: true, data: data[1:]}, nil
+}
+
+// sysex frames a sysex message.
+func sysex(command byte, data ...byte) []byte {
+	msg := make([]byte, 0, len(data)+3)
+	msg = append(msg, startSysex, command)
+	msg = append(msg, data...)
+	return append(msg, endSysex)
+}
+
+// encode7 splits each byte into two seven bit bytes, least significant first.
+func encode7(data []byte) []byte {
+	out := make([]byte, 0, len(data)*2)
+	for _, b := range data {
+		out = append(out, b&0x7F, b>>7)
+	}
+	return out
+}
+
+// decode7 joins pairs of seven bit bytes back into bytes.
+func decode7(data []byte) []byte {
+	out := make([]byte, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		out = append(out, data[i]|data[i+1]<<7)
+	}
+	return out
+}
+
+// splitValue returns the seven bit bytes of a value, least significant first.
+func splitValue(value int, bytes int) []byte {
+	out := make([]byte, bytes)
+	for i := range out {
+		out[i] = byte(value>>(7*i)) & 0x7F
+	}
+	return out
+}
+
+// joinValue is the inverse of splitValue.
+func joinValue(data []byte) int {
+	var value int
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<7 | int(data[i]&0x7F)
+	}
+	return value
+}
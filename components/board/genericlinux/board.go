@@ -29,7 +29,11 @@ import (
 	"go.viam.com/rdk/utils"
 )
 
-var _ = board.LocalBoard(&sysfsBoard{})
+var (
+	_ = board.LocalBoard(&sysfsBoard{})
+	_ = board.SerialBoard(&sysfsBoard{})
+	_ = board.CANBoard(&sysfsBoard{})
+)
 
 // A Config describes the configuration of a board and all of its connected parts.
 type Config struct {
@@ -37,6 +41,8 @@ type Config struct {
 	SPIs              []board.SPIConfig              `json:"spis,omitempty"`
 	Analogs           []board.AnalogConfig           `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
+	Serials           []board.SerialConfig           `json:"serials,omitempty"`
+	CANs              []board.CANConfig              `json:"can_buses,omitempty"`
 	Attributes        config.AttributeMap            `json:"attributes,omitempty"`
 }
 
@@ -76,22 +82,27 @@ func RegisterBoard(modelName string, gpioMappings map[int]GPIOBoardMapping, useP
 				}
 			}
 
-			var analogs map[string]board.AnalogReader
-			if len(conf.Analogs) != 0 {
-				analogs = make(map[string]board.AnalogReader, len(conf.Analogs))
-				for _, analogConf := range conf.Analogs {
-					channel, err := strconv.Atoi(analogConf.Pin)
+			var serials map[string]board.Serial
+			if len(conf.Serials) != 0 {
+				serials = make(map[string]board.Serial, len(conf.Serials))
+				for _, serialConf := range conf.Serials {
+					s, err := board.NewSerialBus(serialConf)
 					if err != nil {
-						return nil, errors.Errorf("bad analog pin (%s)", analogConf.Pin)
+						return nil, err
 					}
+					serials[serialConf.Name] = s
+				}
+			}
 
-					bus, ok := spis[analogConf.SPIBus]
-					if !ok {
-						return nil, errors.Errorf("can't find SPI bus (%s) requested by AnalogReader", analogConf.SPIBus)
+			var cans map[string]board.CAN
+			if len(conf.CANs) != 0 {
+				cans = make(map[string]board.CAN, len(conf.CANs))
+				for _, canConf := range conf.CANs {
+					c, err := board.NewSocketCAN(canConf)
+					if err != nil {
+						return nil, err
 					}
-
-					ar := &board.MCP3008AnalogReader{channel, bus, analogConf.ChipSelect}
-					analogs[analogConf.Name] = board.SmoothAnalogReader(ar, analogConf, logger)
+					cans[canConf.Name] = c
 				}
 			}
 
@@ -99,14 +110,30 @@ func RegisterBoard(modelName string, gpioMappings map[int]GPIOBoardMapping, useP
 			b := sysfsBoard{
 				gpioMappings:  gpioMappings,
 				spis:          spis,
-				analogs:       analogs,
 				pwms:          map[string]pwmSetting{},
 				i2cs:          i2cs,
+				serials:       serials,
+				cans:          cans,
 				usePeriphGpio: usePeriphGpio,
 				logger:        logger,
 				cancelCtx:     cancelCtx,
 				cancelFunc:    cancelFunc,
 			}
+			if len(conf.Analogs) != 0 {
+				b.analogs = make(map[string]board.AnalogReader, len(conf.Analogs))
+				for _, analogConf := range conf.Analogs {
+					ar, err := board.NewAnalogReader(ctx, &b, analogConf, logger)
+					if err != nil {
+						for _, built := range b.analogs {
+							err = multierr.Combine(err, goutils.TryClose(ctx, built))
+						}
+						cancelFunc()
+						return nil, err
+					}
+					b.analogs[analogConf.Name] = ar
+				}
+			}
+
 			if !usePeriphGpio {
 				// We currently have two implementations of GPIO pins on these boards: one using
 				// libraries from periph.io and one using an ioctl approach. If we're using the
@@ -114,6 +141,16 @@ func RegisterBoard(modelName string, gpioMappings map[int]GPIOBoardMapping, useP
 				b.gpios = gpioInitialize( // Defined in gpio.go
 					b.cancelCtx, gpioMappings, &b.activeBackgroundWorkers, b.logger)
 			}
+
+			// Digital interrupts always use the GPIO character device, whichever implementation
+			// of GPIO pins the board uses.
+			interrupts, err := digitalInterruptsInitialize( // Defined in digital_interrupts.go
+				b.cancelCtx, conf.DigitalInterrupts, gpioMappings, &b.activeBackgroundWorkers, b.logger)
+			if err != nil {
+				// Close also releases the analog readers, serial ports and GPIO pins opened above.
+				return nil, multierr.Combine(err, b.Close())
+			}
+			b.interrupts = interrupts
 			return &b, nil
 		}})
 	config.RegisterComponentAttributeMapConverter(
@@ -148,6 +185,16 @@ func (config *Config) Validate(path string) error {
 			return err
 		}
 	}
+	for idx, conf := range config.Serials {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "serials", idx)); err != nil {
+			return err
+		}
+	}
+	for idx, conf := range config.CANs {
+		if err := conf.Validate(fmt.Sprintf("%s.%s.%d", path, "can_buses", idx)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -162,10 +209,13 @@ type sysfsBoard struct {
 	analogs      map[string]board.AnalogReader
 	pwms         map[string]pwmSetting
 	i2cs         map[string]board.I2C
+	serials      map[string]board.Serial
+	cans         map[string]board.CAN
 	logger       golog.Logger
 
 	usePeriphGpio bool
 	gpios         map[string]*gpioPin // Only used for non-periph.io pins
+	interrupts    map[string]*digitalInterrupt
 
 	cancelCtx               context.Context
 	cancelFunc              func()
@@ -230,14 +280,27 @@ func (b *sysfsBoard) I2CByName(name string) (board.I2C, bool) {
 	return i, ok
 }
 
+func (b *sysfsBoard) SerialByName(name string) (board.Serial, bool) {
+	s, ok := b.serials[name]
+	return s, ok
+}
+
+func (b *sysfsBoard) CANByName(name string) (board.CAN, bool) {
+	c, ok := b.cans[name]
+	return c, ok
+}
+
 func (b *sysfsBoard) AnalogReaderByName(name string) (board.AnalogReader, bool) {
 	a, ok := b.analogs[name]
 	return a, ok
 }
 
 func (b *sysfsBoard) DigitalInterruptByName(name string) (board.DigitalInterrupt, bool) {
-	b.logger.Warn("Digital interrupts are not currently supported on sysfs boards.")
-	return nil, false
+	interrupt, ok := b.interrupts[name]
+	if !ok {
+		return nil, false
+	}
+	return interrupt.boardInterrupt, true
 }
 
 func (b *sysfsBoard) SPINames() []string {
@@ -262,6 +325,28 @@ func (b *sysfsBoard) I2CNames() []string {
 	return names
 }
 
+func (b *sysfsBoard) SerialNames() []string {
+	if len(b.serials) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(b.serials))
+	for k := range b.serials {
+		names = append(names, k)
+	}
+	return names
+}
+
+func (b *sysfsBoard) CANNames() []string {
+	if len(b.cans) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(b.cans))
+	for k := range b.cans {
+		names = append(names, k)
+	}
+	return names
+}
+
 func (b *sysfsBoard) AnalogReaderNames() []string {
 	names := []string{}
 	for k := range b.analogs {
@@ -271,7 +356,14 @@ func (b *sysfsBoard) AnalogReaderNames() []string {
 }
 
 func (b *sysfsBoard) DigitalInterruptNames() []string {
-	return nil
+	if len(b.interrupts) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(b.interrupts))
+	for name := range b.interrupts {
+		names = append(names, name)
+	}
+	return names
 }
 
 func (b *sysfsBoard) GPIOPinNames() []string {
@@ -487,14 +579,25 @@ func (b *sysfsBoard) Close() error {
 	b.mu.Lock()
 	b.cancelFunc()
 	b.mu.Unlock()
+
+	// Closing the interrupts' event lines is what stops their goroutines from waiting on events.
+	var err error
+	for _, interrupt := range b.interrupts {
+		err = multierr.Combine(err, interrupt.Close())
+	}
+	for _, s := range b.serials {
+		err = multierr.Combine(err, goutils.TryClose(context.Background(), s))
+	}
+	for _, analog := range b.analogs {
+		err = multierr.Combine(err, goutils.TryClose(context.Background(), analog))
+	}
 	b.activeBackgroundWorkers.Wait()
 
 	// For non-Periph boards, shut down all our open pins so we don't leak file descriptors
 	if b.usePeriphGpio {
-		return nil
+		return err
 	}
 
-	var err error
 	for _, pin := range b.gpios {
 		err = multierr.Combine(err, pin.Close())
 	}
@@ -0,0 +1,170 @@
+//go:build linux
+
+// Package genericlinux is for Linux boards, and this particular file is for digital interrupts
+// using the line events of the GPIO character device.
+package genericlinux
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"golang.org/x/sys/unix"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// These mirror the GPIO character device ABI in linux/gpio.h.
+const (
+	gpioGetLineEventIoctl     = 0xc030b404 // _IOWR(0xB4, 0x04, struct gpioevent_request)
+	gpioHandleRequestInput    = 1 << 0
+	gpioEventRequestBothEdges = (1 << 0) | (1 << 1)
+	gpioEventEventRisingEdge  = 0x01
+	gpioEventEventFallingEdge = 0x02
+)
+
+// gpioEventRequest is struct gpioevent_request.
+type gpioEventRequest struct {
+	lineOffset    uint32
+	handleFlags   uint32
+	eventFlags    uint32
+	consumerLabel [32]byte
+	fd            int32
+}
+
+// gpioEventData is struct gpioevent_data.
+type gpioEventData struct {
+	timestamp uint64
+	id        uint32
+	_         [4]byte
+}
+
+type digitalInterrupt struct {
+	boardInterrupt board.DigitalInterrupt
+	line           *os.File
+	cancelCtx      context.Context
+	logger         golog.Logger
+}
+
+// newDigitalInterrupt creates the configured interrupt and ticks it with the edges of its pin.
+func newDigitalInterrupt(cancelCtx context.Context, config board.DigitalInterruptConfig,
+	gpioMappings map[int]GPIOBoardMapping, waitGroup *sync.WaitGroup, logger golog.Logger,
+) (*digitalInterrupt, error) {
+	pin, err := strconv.Atoi(config.Pin)
+	if err != nil {
+		return nil, errors.Errorf("digital interrupt %q has a bad pin (%s)", config.Name, config.Pin)
+	}
+	mapping, ok := gpioMappings[pin]
+	if !ok {
+		return nil, errors.Errorf("digital interrupt %q uses unknown pin (%s)", config.Name, config.Pin)
+	}
+
+	boardInterrupt, err := board.CreateDigitalInterrupt(config)
+	if err != nil {
+		return nil, err
+	}
+
+	di, err := watchLineEvents(cancelCtx, boardInterrupt, mapping.GPIOChipDev, uint32(mapping.GPIO), waitGroup, logger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot request events for digital interrupt %q", config.Name)
+	}
+	return di, nil
+}
+
+// watchLineEvents requests edge events on a line of a GPIO chip and starts a goroutine that ticks
+// the interrupt with each of them.
+func watchLineEvents(cancelCtx context.Context, boardInterrupt board.DigitalInterrupt, chipDev string, offset uint32,
+	waitGroup *sync.WaitGroup, logger golog.Logger,
+) (*digitalInterrupt, error) {
+	line, err := openLineEvents(chipDev, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	di := &digitalInterrupt{
+		boardInterrupt: boardInterrupt,
+		line:           line,
+		cancelCtx:      cancelCtx,
+		logger:         logger,
+	}
+	waitGroup.Add(1)
+	utils.ManagedGo(func() { di.monitor(line) }, waitGroup.Done)
+	return di, nil
+}
+
+// openLineEvents requests both rising and falling edge events on a line of a GPIO chip. The
+// returned file is non-blocking, so closing it interrupts any read in progress.
+func openLineEvents(chipDev string, offset uint32) (*os.File, error) {
+	chipFd, err := unix.Open(filepath.Join("/dev", chipDev), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(func() error { return unix.Close(chipFd) })
+
+	req := gpioEventRequest{
+		lineOffset:  offset,
+		handleFlags: gpioHandleRequestInput,
+		eventFlags:  gpioEventRequestBothEdges,
+	}
+	copy(req.consumerLabel[:], "viam-interrupt")
+	if _, _, errno := unix.Syscall(
+		unix.SYS_IOCTL, uintptr(chipFd), gpioGetLineEventIoctl, uintptr(unsafe.Pointer(&req)),
+	); errno != 0 {
+		return nil, errno
+	}
+
+	if err := unix.SetNonblock(int(req.fd), true); err != nil {
+		utils.UncheckedError(unix.Close(int(req.fd)))
+		return nil, err
+	}
+	return os.NewFile(uintptr(req.fd), fmt.Sprintf("%s line %d events", chipDev, offset)), nil
+}
+
+// monitor ticks the interrupt with every event read until the reader is closed or exhausted.
+func (di *digitalInterrupt) monitor(r io.Reader) {
+	var event gpioEventData
+	buf := (*[unsafe.Sizeof(event)]byte)(unsafe.Pointer(&event))[:]
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if !errors.Is(err, os.ErrClosed) && !errors.Is(err, io.EOF) {
+				di.logger.Errorw("error reading digital interrupt events", "error", err)
+			}
+			return
+		}
+		// The kernel timestamps events with a monotonic clock, which is what Tick expects.
+		high := event.id == gpioEventEventRisingEdge
+		if err := di.boardInterrupt.Tick(di.cancelCtx, high, event.timestamp); err != nil {
+			// The only error Tick returns is for the context being cancelled.
+			return
+		}
+	}
+}
+
+func (di *digitalInterrupt) Close() error {
+	return di.line.Close()
+}
+
+func digitalInterruptsInitialize(cancelCtx context.Context, configs []board.DigitalInterruptConfig,
+	gpioMappings map[int]GPIOBoardMapping, waitGroup *sync.WaitGroup, logger golog.Logger,
+) (map[string]*digitalInterrupt, error) {
+	interrupts := make(map[string]*digitalInterrupt, len(configs))
+	for _, config := range configs {
+		di, err := newDigitalInterrupt(cancelCtx, config, gpioMappings, waitGroup, logger)
+		if err != nil {
+			for _, opened := range interrupts {
+				utils.UncheckedError(opened.Close())
+			}
+			return nil, err
+		}
+		interrupts[config.Name] = di
+	}
+	return interrupts, nil
+}
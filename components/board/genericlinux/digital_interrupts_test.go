@@ -0,0 +1,251 @@
+//go:build linux
+
+package genericlinux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"golang.org/x/sys/unix"
+
+	"go.viam.com/rdk/components/board"
+)
+
+func encodeEvents(events ...gpioEventData) []byte {
+	var buf bytes.Buffer
+	for i := range events {
+		buf.Write((*[unsafe.Sizeof(events[i])]byte)(unsafe.Pointer(&events[i]))[:])
+	}
+	return buf.Bytes()
+}
+
+func TestDigitalInterruptMonitor(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+
+	t.Run("basic interrupt counts rising edges", func(t *testing.T) {
+		boardInterrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "i", Pin: "3"})
+		test.That(t, err, test.ShouldBeNil)
+		ticks := make(chan board.Tick, 3)
+		boardInterrupt.AddCallback(ticks)
+
+		di := &digitalInterrupt{boardInterrupt: boardInterrupt, cancelCtx: context.Background(), logger: logger}
+		di.monitor(bytes.NewReader(encodeEvents(
+			gpioEventData{timestamp: 1000, id: gpioEventEventRisingEdge},
+			gpioEventData{timestamp: 2000, id: gpioEventEventFallingEdge},
+			gpioEventData{timestamp: 3000, id: gpioEventEventRisingEdge},
+		)))
+
+		value, err := boardInterrupt.Value(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, 2)
+		test.That(t, <-ticks, test.ShouldResemble, board.Tick{High: true, TimestampNanosec: 1000})
+		test.That(t, <-ticks, test.ShouldResemble, board.Tick{High: false, TimestampNanosec: 2000})
+		test.That(t, <-ticks, test.ShouldResemble, board.Tick{High: true, TimestampNanosec: 3000})
+	})
+
+	t.Run("servo interrupt measures pulse widths", func(t *testing.T) {
+		boardInterrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "s", Pin: "3", Type: "servo"})
+		test.That(t, err, test.ShouldBeNil)
+
+		// fill the rolling average with 1.5ms pulses every 20ms
+		var events []gpioEventData
+		for i := uint64(1); i <= board.ServoRollingAverageWindow; i++ {
+			events = append(events,
+				gpioEventData{timestamp: i * 20000000, id: gpioEventEventRisingEdge},
+				gpioEventData{timestamp: i*20000000 + 1500000, id: gpioEventEventFallingEdge},
+			)
+		}
+		di := &digitalInterrupt{boardInterrupt: boardInterrupt, cancelCtx: context.Background(), logger: logger}
+		di.monitor(bytes.NewReader(encodeEvents(events...)))
+
+		value, err := boardInterrupt.Value(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, 1500)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		boardInterrupt, err := board.CreateDigitalInterrupt(board.DigitalInterruptConfig{Name: "i", Pin: "3"})
+		test.That(t, err, test.ShouldBeNil)
+		// nobody reads this callback, so the first tick blocks until the context is cancelled
+		boardInterrupt.AddCallback(make(chan board.Tick))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		di := &digitalInterrupt{boardInterrupt: boardInterrupt, cancelCtx: ctx, logger: logger}
+		di.monitor(bytes.NewReader(encodeEvents(
+			gpioEventData{timestamp: 1000, id: gpioEventEventRisingEdge},
+			gpioEventData{timestamp: 3000, id: gpioEventEventRisingEdge},
+		)))
+
+		value, err := boardInterrupt.Value(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, 1)
+	})
+}
+
+func TestDigitalInterruptsInitialize(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	var wg sync.WaitGroup
+	mappings := map[int]GPIOBoardMapping{3: {GPIOChipDev: "gpiochip-does-not-exist", GPIO: 3}}
+
+	_, err := digitalInterruptsInitialize(context.Background(),
+		[]board.DigitalInterruptConfig{{Name: "i", Pin: "a"}}, mappings, &wg, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "bad pin")
+
+	_, err = digitalInterruptsInitialize(context.Background(),
+		[]board.DigitalInterruptConfig{{Name: "i", Pin: "5"}}, mappings, &wg, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown pin")
+
+	_, err = digitalInterruptsInitialize(context.Background(),
+		[]board.DigitalInterruptConfig{{Name: "i", Pin: "3"}}, mappings, &wg, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot request events")
+
+	interrupts, err := digitalInterruptsInitialize(context.Background(), nil, mappings, &wg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, interrupts, test.ShouldBeEmpty)
+	wg.Wait()
+}
+
+const gpioSimConfigDir = "/sys/kernel/config/gpio-sim"
+
+// newGPIOSimChip makes a chip with the gpio-sim module, returning the name of its character device
+// and the directory of its lines' attributes. The test is skipped if the module is unavailable.
+func newGPIOSimChip(t *testing.T, numLines int) (string, string) {
+	t.Helper()
+	if _, err := os.Stat(gpioSimConfigDir); err != nil {
+		t.Skipf("gpio-sim is unavailable (load the module and mount configfs to run this test): %v", err)
+	}
+	dir := filepath.Join(gpioSimConfigDir, fmt.Sprintf("rdk-test-%d", os.Getpid()))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Skipf("cannot make a gpio-sim chip: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(dir) })
+	bank := filepath.Join(dir, "bank0")
+	test.That(t, os.Mkdir(bank, 0o755), test.ShouldBeNil)
+	t.Cleanup(func() { os.Remove(bank) })
+	test.That(t, os.WriteFile(filepath.Join(bank, "num_lines"), []byte(fmt.Sprint(numLines)), 0o644), test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(dir, "live"), []byte("1"), 0o644), test.ShouldBeNil)
+	t.Cleanup(func() { os.WriteFile(filepath.Join(dir, "live"), []byte("0"), 0o644) })
+
+	chipName, err := os.ReadFile(filepath.Join(bank, "chip_name"))
+	test.That(t, err, test.ShouldBeNil)
+	devName, err := os.ReadFile(filepath.Join(dir, "dev_name"))
+	test.That(t, err, test.ShouldBeNil)
+	chip := strings.TrimSpace(string(chipName))
+	return chip, filepath.Join("/sys/devices/platform", strings.TrimSpace(string(devName)), chip)
+}
+
+func TestDigitalInterruptGPIOSim(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	chip, linesDir := newGPIOSimChip(t, 4)
+	pull := func(line int, value string) {
+		t.Helper()
+		path := filepath.Join(linesDir, fmt.Sprintf("sim_gpio%d", line), "pull")
+		test.That(t, os.WriteFile(path, []byte(value), 0o644), test.ShouldBeNil)
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	mappings := map[int]GPIOBoardMapping{7: {GPIOChipDev: chip, GPIO: 2}}
+	interrupts, err := digitalInterruptsInitialize(cancelCtx,
+		[]board.DigitalInterruptConfig{{Name: "i", Pin: "7"}}, mappings, &wg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	di := interrupts["i"]
+	ticks := make(chan board.Tick, 4)
+	di.boardInterrupt.AddCallback(ticks)
+
+	// line events are timestamped with the monotonic clock
+	var now unix.Timespec
+	test.That(t, unix.ClockGettime(unix.CLOCK_MONOTONIC, &now), test.ShouldBeNil)
+	before := uint64(now.Nano())
+	pull(2, "pull-up")
+	pull(2, "pull-down")
+	pull(2, "pull-up")
+
+	for _, high := range []bool{true, false, true} {
+		select {
+		case tick := <-ticks:
+			test.That(t, tick.High, test.ShouldEqual, high)
+			test.That(t, tick.TimestampNanosec, test.ShouldBeGreaterThanOrEqualTo, before)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an edge")
+		}
+	}
+	value, err := di.boardInterrupt.Value(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 2)
+
+	test.That(t, di.Close(), test.ShouldBeNil)
+	wg.Wait()
+}
+
+func TestGPIOPinWatchEdges(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	var wg sync.WaitGroup
+	pin := &gpioPin{devicePath: "gpiochip-does-not-exist", offset: 3, cancelCtx: context.Background(), waitGroup: &wg, logger: logger}
+	_, err := pin.WatchEdges(make(chan board.Tick))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, pin.edges, test.ShouldBeNil)
+	wg.Wait()
+}
+
+func TestGPIOPinWatchEdgesGPIOSim(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	chip, linesDir := newGPIOSimChip(t, 4)
+	pull := func(line int, value string) {
+		t.Helper()
+		path := filepath.Join(linesDir, fmt.Sprintf("sim_gpio%d", line), "pull")
+		test.That(t, os.WriteFile(path, []byte(value), 0o644), test.ShouldBeNil)
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	pin := gpioInitialize(cancelCtx, map[int]GPIOBoardMapping{7: {GPIOChipDev: chip, GPIO: 1}}, &wg, logger)["7"]
+	first, second := make(chan board.Tick, 2), make(chan board.Tick, 2)
+	stopFirst, err := pin.WatchEdges(first)
+	test.That(t, err, test.ShouldBeNil)
+	stopSecond, err := pin.WatchEdges(second)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the pin can't be an output while its edges are watched
+	err = pin.Set(context.Background(), true, nil)
+	test.That(t, err, test.ShouldBeError, errors.New("pin is in use watching its edges"))
+
+	pull(1, "pull-up")
+	for _, ticks := range []chan board.Tick{first, second} {
+		select {
+		case tick := <-ticks:
+			test.That(t, tick.High, test.ShouldBeTrue)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an edge")
+		}
+	}
+
+	// the line is released once nobody watches it
+	stopFirst()
+	test.That(t, pin.edges, test.ShouldNotBeNil)
+	stopSecond()
+	test.That(t, pin.edges, test.ShouldBeNil)
+	test.That(t, pin.Set(context.Background(), true, nil), test.ShouldBeNil)
+	_, err = pin.WatchEdges(first)
+	test.That(t, err, test.ShouldBeError, errors.New("pin is in use as an output"))
+
+	test.That(t, pin.Close(), test.ShouldBeNil)
+	wg.Wait()
+}
@@ -12,7 +12,10 @@ import (
 
 	"github.com/edaniels/golog"
 	"github.com/mkch/gpio"
+	"github.com/pkg/errors"
 	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
 )
 
 type gpioPin struct {
@@ -25,6 +28,10 @@ type gpioPin struct {
 	pwmRunning      bool
 	pwmFreqHz       uint
 	pwmDutyCyclePct float64
+	// edges is the interrupt ticked with the line events of the pin while its edges are watched,
+	// and edgeWatchers how many are watching them.
+	edges        *digitalInterrupt
+	edgeWatchers int
 
 	mu        sync.Mutex
 	cancelCtx context.Context
@@ -38,6 +45,9 @@ func (pin *gpioPin) openGpioFd() error {
 	if pin.line != nil {
 		return nil // If the pin is already opened, don't re-open it.
 	}
+	if pin.edges != nil {
+		return errors.New("pin is in use watching its edges")
+	}
 
 	chip, err := gpio.OpenChip(pin.devicePath)
 	if err != nil {
@@ -203,6 +213,39 @@ func (pin *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[strin
 	return pin.startSoftwarePWM()
 }
 
+// WatchEdges implements board.EdgeWatcher by requesting the line events of the pin, which can't
+// be done while it is open as an output. The pin can't be used as one until all watchers stop.
+func (pin *gpioPin) WatchEdges(ticks chan board.Tick) (func(), error) {
+	pin.mu.Lock()
+	defer pin.mu.Unlock()
+
+	if pin.line != nil {
+		return nil, errors.New("pin is in use as an output")
+	}
+	if pin.edges == nil {
+		edges, err := watchLineEvents(pin.cancelCtx, &board.BasicDigitalInterrupt{},
+			pin.devicePath, pin.offset, pin.waitGroup, pin.logger)
+		if err != nil {
+			return nil, err
+		}
+		pin.edges = edges
+	}
+	edges := pin.edges
+	edges.boardInterrupt.AddCallback(ticks)
+	pin.edgeWatchers++
+
+	return func() {
+		edges.boardInterrupt.RemoveCallback(ticks)
+		pin.mu.Lock()
+		defer pin.mu.Unlock()
+		pin.edgeWatchers--
+		if pin.edgeWatchers == 0 {
+			utils.UncheckedError(edges.Close())
+			pin.edges = nil
+		}
+	}, nil
+}
+
 func (pin *gpioPin) Close() error {
 	// We keep the gpio.Line object open indefinitely, so it holds its state for as long as this
 	// struct is around. This function is a way to close it when we're about to go out of scope, so
@@ -210,6 +253,10 @@ func (pin *gpioPin) Close() error {
 	pin.mu.Lock()
 	defer pin.mu.Unlock()
 
+	if pin.edges != nil {
+		utils.UncheckedError(pin.edges.Close())
+		pin.edges = nil
+	}
 	if pin.line == nil {
 		return nil // Never opened, so no need to close
 	}
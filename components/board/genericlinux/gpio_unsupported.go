@@ -9,6 +9,8 @@ import (
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
 )
 
 type gpioPin struct {
@@ -53,3 +55,22 @@ func gpioInitialize(cancelCtx context.Context, gpioMappings map[int]GPIOBoardMap
 	// unnecessary warning.
 	return map[string]*gpioPin{}
 }
+
+type digitalInterrupt struct {
+	// This struct is implemented in the Linux version. We have a dummy struct here just to get
+	// things to compile on non-Linux environments.
+	boardInterrupt board.DigitalInterrupt
+}
+
+func (di *digitalInterrupt) Close() error {
+	return errors.New("digital interrupts using ioctl are not supported on non-Linux boards")
+}
+
+func digitalInterruptsInitialize(cancelCtx context.Context, configs []board.DigitalInterruptConfig,
+	gpioMappings map[int]GPIOBoardMapping, waitGroup *sync.WaitGroup, logger golog.Logger,
+) (map[string]*digitalInterrupt, error) {
+	if len(configs) != 0 {
+		return nil, errors.New("digital interrupts using ioctl are not supported on non-Linux boards")
+	}
+	return map[string]*digitalInterrupt{}, nil
+}
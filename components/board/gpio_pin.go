@@ -22,3 +22,12 @@ type GPIOPin interface {
 	// SetPWMFreq sets the given pin to the given PWM frequency. 0 will use the board's default PWM frequency.
 	SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error
 }
+
+// An EdgeWatcher is a GPIOPin that can report its edges as they happen, like from a digital
+// interrupt on the same pin or the line events of the kernel, rather than having to be polled.
+type EdgeWatcher interface {
+	// WatchEdges sends a tick to ticks on every edge of the pin until stop is called. ticks has
+	// to be read until then. It fails if the pin's edges can't be watched right now, like when it
+	// is in use as an output.
+	WatchEdges(ticks chan Tick) (stop func(), err error)
+}
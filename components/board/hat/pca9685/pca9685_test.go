@@ -0,0 +1,56 @@
+package pca9685
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestPWM(t *testing.T) {
+	ctx := context.Background()
+	bus := simdevice.NewI2CBus()
+	chip := simdevice.NewPCA9685()
+	bus.Attach(0x60, chip)
+	test.That(t, chip.Sleeping(), test.ShouldBeTrue)
+
+	pca, err := New(ctx, bus, 0x60)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chip.Sleeping(), test.ShouldBeFalse)
+
+	pin, err := pca.GPIOPinByName("3")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pin.SetPWM(ctx, 0.25, nil), test.ShouldBeNil)
+	test.That(t, chip.DutyCycle(3), test.ShouldAlmostEqual, 0.25, 0.001)
+	test.That(t, chip.DutyCycle(2), test.ShouldEqual, 0)
+	duty, err := pin.PWM(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, duty, test.ShouldAlmostEqual, 0.25, 0.001)
+
+	test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+	test.That(t, chip.DutyCycle(3), test.ShouldEqual, 1)
+	high, err := pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeTrue)
+
+	test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, chip.DutyCycle(3), test.ShouldEqual, 0)
+	high, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+
+	test.That(t, pin.SetPWMFreq(ctx, 50, nil), test.ShouldBeNil)
+	test.That(t, chip.Frequency(), test.ShouldAlmostEqual, 50, 1)
+	test.That(t, chip.Sleeping(), test.ShouldBeFalse)
+	freq, err := pin.PWMFreq(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freq, test.ShouldEqual, 50)
+
+	test.That(t, pin.SetPWMFreq(ctx, 10000, nil), test.ShouldNotBeNil)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+
+	_, err = pca.GPIOPinByName("16")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,43 @@
+package board_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestMCP3008AnalogReader(t *testing.T) {
+	ctx := context.Background()
+	bus := simdevice.NewSPIBus()
+	adc := simdevice.NewMCP3008()
+	adc.SetChannel(3, 789)
+	adc.SetChannel(4, 1023)
+	bus.Attach("24", adc)
+
+	reader := &board.MCP3008AnalogReader{Channel: 3, Bus: bus, Chip: "24"}
+	value, err := reader.Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 789)
+
+	reader.Channel = 4
+	value, err = reader.Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 1023)
+
+	transfers := bus.Transfers()
+	test.That(t, transfers, test.ShouldHaveLength, 2)
+	test.That(t, transfers[0].Tx, test.ShouldResemble, []byte{0x01, 0xB0, 0x00})
+	test.That(t, transfers[0].Mode, test.ShouldEqual, 0)
+	test.That(t, transfers[0].Baud, test.ShouldEqual, 1000000)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+
+	reader.Chip = "26"
+	_, err = reader.Read(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no SPI device on chip select 26")
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+}
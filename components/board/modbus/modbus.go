@@ -0,0 +1,444 @@
+// Package modbus implements a Modbus RTU and TCP client shared by Modbus based components,
+// along with an in-memory Modbus TCP server that can stand in for a device.
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/registry"
+)
+
+// The supported transports.
+const (
+	ProtocolTCP = "tcp"
+	ProtocolRTU = "rtu"
+)
+
+// defaults assume an 8N1 serial line, which is what most RTU devices ship with.
+const (
+	baudRateDefault = 9600
+	dataBitsDefault = 8
+	stopBitsDefault = 1
+	parityDefault   = "N"
+	unitIDDefault   = 1
+	timeoutDefault  = time.Second
+)
+
+// Config describes how to reach a Modbus device.
+type Config struct {
+	// Protocol is either "tcp" or "rtu".
+	Protocol string `json:"protocol"`
+	// Address is the host:port of a Modbus TCP device.
+	Address string `json:"address,omitempty"`
+	// SerialPath is the serial device of a Modbus RTU bus that no board owns.
+	SerialPath string `json:"serial_path,omitempty"`
+	// Board and Serial name a serial port of a board to run a Modbus RTU bus over instead,
+	// sharing the port with the board. Its line settings then come from the board's config.
+	Board    string `json:"board,omitempty"`
+	Serial   string `json:"serial,omitempty"`
+	BaudRate int    `json:"serial_baud_rate,omitempty"`
+	DataBits int    `json:"serial_data_bits,omitempty"`
+	StopBits int    `json:"serial_stop_bits,omitempty"`
+	// Parity is one of "N", "E" or "O".
+	Parity string `json:"serial_parity,omitempty"`
+	// UnitID is the unit (slave) identifier of the device on the bus.
+	UnitID    int `json:"unit_id,omitempty"`
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) error {
+	switch conf.Protocol {
+	case ProtocolTCP:
+		if conf.Address == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "address")
+		}
+	case ProtocolRTU:
+		if conf.Serial != "" {
+			if conf.SerialPath != "" {
+				return utils.NewConfigValidationError(path, errors.New("cannot set both serial_path and serial"))
+			}
+			if conf.Board == "" {
+				return utils.NewConfigValidationFieldRequiredError(path, "board")
+			}
+			if conf.BaudRate != 0 || conf.DataBits != 0 || conf.StopBits != 0 || conf.Parity != "" {
+				return utils.NewConfigValidationError(path,
+					errors.New("the line settings of a board's serial port are set in the board's config"))
+			}
+		} else if conf.SerialPath == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "serial_path")
+		}
+	case "":
+		return utils.NewConfigValidationFieldRequiredError(path, "protocol")
+	default:
+		return utils.NewConfigValidationError(path, errors.Errorf("unsupported protocol %q, must be %q or %q",
+			conf.Protocol, ProtocolTCP, ProtocolRTU))
+	}
+	if conf.Protocol != ProtocolRTU && (conf.Board != "" || conf.Serial != "") {
+		return utils.NewConfigValidationError(path, errors.New("board and serial are only used by rtu"))
+	}
+	if conf.Board != "" && conf.Serial == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "serial")
+	}
+	if conf.UnitID < 0 || conf.UnitID > 247 {
+		return utils.NewConfigValidationError(path, errors.New("unit_id must be between 0 and 247"))
+	}
+	switch conf.Parity {
+	case "", "N", "E", "O":
+	default:
+		return utils.NewConfigValidationError(path, errors.Errorf("unsupported serial_parity %q", conf.Parity))
+	}
+	return nil
+}
+
+// Dependencies returns the names of the resources the client needs, which is the board whose
+// serial port it uses, if any.
+func (conf *Config) Dependencies() []string {
+	if conf.Board == "" {
+		return nil
+	}
+	return []string{conf.Board}
+}
+
+func (conf *Config) busKey() string {
+	if conf.Serial != "" {
+		return ProtocolRTU + ":" + conf.Board + "/" + conf.Serial
+	}
+	if conf.Protocol == ProtocolRTU {
+		return ProtocolRTU + ":" + conf.SerialPath
+	}
+	return ProtocolTCP + ":" + conf.Address
+}
+
+func (conf *Config) timeout() time.Duration {
+	if conf.TimeoutMs > 0 {
+		return time.Duration(conf.TimeoutMs) * time.Millisecond
+	}
+	return timeoutDefault
+}
+
+// A Client talks to a single unit on a Modbus bus. Register values are returned as
+// 16-bit words and bit values as booleans.
+type Client interface {
+	ReadCoils(ctx context.Context, address, quantity uint16) ([]bool, error)
+	ReadDiscreteInputs(ctx context.Context, address, quantity uint16) ([]bool, error)
+	ReadHoldingRegisters(ctx context.Context, address, quantity uint16) ([]uint16, error)
+	ReadInputRegisters(ctx context.Context, address, quantity uint16) ([]uint16, error)
+	WriteSingleCoil(ctx context.Context, address uint16, value bool) error
+	WriteSingleRegister(ctx context.Context, address, value uint16) error
+	WriteMultipleRegisters(ctx context.Context, address uint16, values []uint16) error
+	Close() error
+}
+
+// buses is global to all clients, mapped by transport address, so that several components
+// talking to different units on the same RTU line do not fight over the serial port.
+var (
+	globalMu sync.Mutex
+	buses    = map[string]*bus{}
+)
+
+type bus struct {
+	mu        sync.Mutex
+	key       string
+	refs      int
+	client    modbus.Client
+	setUnitID func(byte)
+	closeFunc func() error
+}
+
+// NewClient returns a client for the unit described by the config. Clients for
+// the same transport share a connection. A bus on a board's serial port needs the
+// board, so its clients are made with NewClientFromDependencies.
+func NewClient(conf Config) (Client, error) {
+	if conf.Serial != "" {
+		return nil, errors.Errorf("modbus bus on serial port %q of board %q needs the board", conf.Serial, conf.Board)
+	}
+	return newClient(conf, nil)
+}
+
+// NewClientFromDependencies returns a client for the unit described by the config, finding the
+// board whose serial port it uses, if any, in the dependencies.
+func NewClientFromDependencies(deps registry.Dependencies, conf Config) (Client, error) {
+	if conf.Serial == "" {
+		return NewClient(conf)
+	}
+	b, err := board.FromDependencies(deps, conf.Board)
+	if err != nil {
+		return nil, err
+	}
+	sb, ok := b.(board.SerialBoard)
+	if !ok {
+		return nil, errors.Errorf("board %q has no serial ports", conf.Board)
+	}
+	serial, ok := sb.SerialByName(conf.Serial)
+	if !ok {
+		return nil, errors.Errorf("board %q has no serial port %q", conf.Board, conf.Serial)
+	}
+	return newClient(conf, serial)
+}
+
+func newClient(conf Config, serial board.Serial) (Client, error) {
+	if err := conf.Validate("modbus"); err != nil {
+		return nil, err
+	}
+	unitID := conf.UnitID
+	if unitID == 0 {
+		unitID = unitIDDefault
+	}
+
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	b, ok := buses[conf.busKey()]
+	if !ok {
+		b = newBus(conf, serial)
+		buses[b.key] = b
+	}
+	b.refs++
+	return &client{bus: b, unitID: byte(unitID)}, nil
+}
+
+func newBus(conf Config, serial board.Serial) *bus {
+	b := &bus{key: conf.busKey()}
+	if serial != nil {
+		// the handler is only used to frame requests, the board's port carries them
+		handler := modbus.NewRTUClientHandler("")
+		b.client = modbus.NewClient2(handler, &serialTransporter{serial: serial, timeout: conf.timeout()})
+		b.setUnitID = func(id byte) { handler.SlaveId = id }
+		// the board owns the port and closes it
+		b.closeFunc = func() error { return nil }
+		return b
+	}
+	if conf.Protocol == ProtocolRTU {
+		handler := modbus.NewRTUClientHandler(conf.SerialPath)
+		handler.BaudRate = conf.BaudRate
+		if handler.BaudRate == 0 {
+			handler.BaudRate = baudRateDefault
+		}
+		handler.DataBits = conf.DataBits
+		if handler.DataBits == 0 {
+			handler.DataBits = dataBitsDefault
+		}
+		handler.StopBits = conf.StopBits
+		if handler.StopBits == 0 {
+			handler.StopBits = stopBitsDefault
+		}
+		handler.Parity = conf.Parity
+		if handler.Parity == "" {
+			handler.Parity = parityDefault
+		}
+		handler.Timeout = conf.timeout()
+		b.client = modbus.NewClient(handler)
+		b.setUnitID = func(id byte) { handler.SlaveId = id }
+		b.closeFunc = handler.Close
+		return b
+	}
+	handler := modbus.NewTCPClientHandler(conf.Address)
+	handler.Timeout = conf.timeout()
+	b.client = modbus.NewClient(handler)
+	b.setUnitID = func(id byte) { handler.SlaveId = id }
+	b.closeFunc = handler.Close
+	return b
+}
+
+type client struct {
+	bus    *bus
+	unitID byte
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// do runs a single request against the unit while holding the bus.
+func (c *client) do(ctx context.Context, f func(modbus.Client) ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, errors.New("modbus client is closed")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.bus.mu.Lock()
+	defer c.bus.mu.Unlock()
+	c.bus.setUnitID(c.unitID)
+	return f(c.bus.client)
+}
+
+func (c *client) ReadCoils(ctx context.Context, address, quantity uint16) ([]bool, error) {
+	res, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.ReadCoils(address, quantity)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %d coils at %d", quantity, address)
+	}
+	return unpackBits(res, quantity), nil
+}
+
+func (c *client) ReadDiscreteInputs(ctx context.Context, address, quantity uint16) ([]bool, error) {
+	res, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.ReadDiscreteInputs(address, quantity)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %d discrete inputs at %d", quantity, address)
+	}
+	return unpackBits(res, quantity), nil
+}
+
+func (c *client) ReadHoldingRegisters(ctx context.Context, address, quantity uint16) ([]uint16, error) {
+	res, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.ReadHoldingRegisters(address, quantity)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %d holding registers at %d", quantity, address)
+	}
+	return unpackWords(res, quantity)
+}
+
+func (c *client) ReadInputRegisters(ctx context.Context, address, quantity uint16) ([]uint16, error) {
+	res, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.ReadInputRegisters(address, quantity)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %d input registers at %d", quantity, address)
+	}
+	return unpackWords(res, quantity)
+}
+
+func (c *client) WriteSingleCoil(ctx context.Context, address uint16, value bool) error {
+	// the protocol encodes ON as 0xFF00 and OFF as 0x0000
+	var raw uint16
+	if value {
+		raw = 0xFF00
+	}
+	_, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.WriteSingleCoil(address, raw)
+	})
+	return errors.Wrapf(err, "error writing coil %d", address)
+}
+
+func (c *client) WriteSingleRegister(ctx context.Context, address, value uint16) error {
+	_, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.WriteSingleRegister(address, value)
+	})
+	return errors.Wrapf(err, "error writing holding register %d", address)
+}
+
+func (c *client) WriteMultipleRegisters(ctx context.Context, address uint16, values []uint16) error {
+	data := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(data[2*i:], v)
+	}
+	_, err := c.do(ctx, func(mc modbus.Client) ([]byte, error) {
+		return mc.WriteMultipleRegisters(address, uint16(len(values)), data)
+	})
+	return errors.Wrapf(err, "error writing %d holding registers at %d", len(values), address)
+}
+
+// Close releases the client, closing the underlying connection once no other client uses it.
+func (c *client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	c.bus.refs--
+	if c.bus.refs > 0 {
+		return nil
+	}
+	delete(buses, c.bus.key)
+	c.bus.mu.Lock()
+	defer c.bus.mu.Unlock()
+	return c.bus.closeFunc()
+}
+
+// serialTransporter sends RTU frames over a serial port of a board, holding the port for the
+// whole exchange so that nothing else on the board talks over a response.
+type serialTransporter struct {
+	serial  board.Serial
+	timeout time.Duration
+}
+
+// The sizes of RTU responses, in bytes, without their data: unit ID, function code and CRC, plus
+// the byte count of reads or the exception code of exceptions.
+const (
+	rtuReadHeaderSize = 3
+	rtuReadSize       = rtuReadHeaderSize + 2
+	rtuExceptionSize  = 5
+	rtuWriteSize      = 8
+)
+
+func (st *serialTransporter) Send(request []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), st.timeout)
+	defer cancel()
+	handle, err := st.serial.OpenHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(handle.Close)
+
+	if err := handle.Write(ctx, request); err != nil {
+		return nil, err
+	}
+	resp, err := readFull(ctx, handle, nil, rtuReadHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	size := rtuWriteSize
+	switch function := resp[1]; {
+	case function&0x80 != 0:
+		size = rtuExceptionSize
+	case function == modbus.FuncCodeReadCoils, function == modbus.FuncCodeReadDiscreteInputs,
+		function == modbus.FuncCodeReadHoldingRegisters, function == modbus.FuncCodeReadInputRegisters:
+		size = rtuReadSize + int(resp[2])
+	}
+	return readFull(ctx, handle, resp, size)
+}
+
+// readFull reads from the handle until buf holds size bytes.
+func readFull(ctx context.Context, handle board.SerialHandle, buf []byte, size int) ([]byte, error) {
+	for len(buf) < size {
+		rx, err := handle.Read(ctx, size-len(buf))
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading modbus response")
+		}
+		buf = append(buf, rx...)
+	}
+	return buf, nil
+}
+
+func unpackBits(data []byte, quantity uint16) []bool {
+	bits := make([]bool, quantity)
+	for i := range bits {
+		if i/8 >= len(data) {
+			break
+		}
+		bits[i] = data[i/8]&(1<<(uint(i)%8)) != 0
+	}
+	return bits
+}
+
+func unpackWords(data []byte, quantity uint16) ([]uint16, error) {
+	if len(data) != 2*int(quantity) {
+		return nil, fmt.Errorf("expected %d bytes of register data but got %d", 2*quantity, len(data))
+	}
+	words := make([]uint16, quantity)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+	return words, nil
+}
@@ -0,0 +1,338 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	"go.viam.com/rdk/registry"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := Config{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"protocol" is required`)
+
+	conf.Protocol = "udp"
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported protocol")
+
+	conf.Protocol = ProtocolTCP
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"address" is required`)
+	conf.Address = "localhost:502"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Protocol = ProtocolRTU
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"serial_path" is required`)
+	conf.SerialPath = "/dev/ttyUSB0"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Parity = "X"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.Parity = "E"
+	conf.UnitID = 300
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	conf = Config{Protocol: ProtocolRTU, Serial: "rs485"}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"board" is required`)
+	conf.Board = "board1"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.Dependencies(), test.ShouldResemble, []string{"board1"})
+	conf.SerialPath = "/dev/ttyUSB0"
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "both")
+	conf.SerialPath = ""
+	conf.BaudRate = 19200
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "board's config")
+
+	conf = Config{Protocol: ProtocolTCP, Address: "localhost:502", Board: "board1", Serial: "rs485"}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "only used by rtu")
+}
+
+func TestRegisterConfigValidate(t *testing.T) {
+	conf := RegisterConfig{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"name" is required`)
+
+	conf.Name = "temp"
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"table" is required`)
+
+	conf.Table = TableCoil
+	conf.Type = TypeInt16
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	conf.Table = TableInputRegister
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.Writable(), test.ShouldBeFalse)
+
+	conf.Type = "int64"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	server, err := NewServer("localhost:0", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+
+	c1, err := NewClient(Config{Protocol: ProtocolTCP, Address: server.Addr()})
+	test.That(t, err, test.ShouldBeNil)
+	c2, err := NewClient(Config{Protocol: ProtocolTCP, Address: server.Addr(), UnitID: 2})
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("registers", func(t *testing.T) {
+		server.SetInputRegister(10, 42)
+		server.SetInputRegister(11, 43)
+		words, err := c1.ReadInputRegisters(ctx, 10, 2)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, words, test.ShouldResemble, []uint16{42, 43})
+
+		test.That(t, c2.WriteSingleRegister(ctx, 5, 1234), test.ShouldBeNil)
+		test.That(t, server.HoldingRegister(5), test.ShouldEqual, 1234)
+		test.That(t, c1.WriteMultipleRegisters(ctx, 6, []uint16{1, 2, 3}), test.ShouldBeNil)
+		words, err = c2.ReadHoldingRegisters(ctx, 5, 4)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, words, test.ShouldResemble, []uint16{1234, 1, 2, 3})
+	})
+
+	t.Run("bits", func(t *testing.T) {
+		server.SetDiscreteInput(3, true)
+		bits, err := c1.ReadDiscreteInputs(ctx, 0, 10)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, bits, test.ShouldResemble, []bool{false, false, false, true, false, false, false, false, false, false})
+
+		test.That(t, c1.WriteSingleCoil(ctx, 9, true), test.ShouldBeNil)
+		test.That(t, server.Coil(9), test.ShouldBeTrue)
+		bits, err = c2.ReadCoils(ctx, 8, 2)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, bits, test.ShouldResemble, []bool{false, true})
+		test.That(t, c1.WriteSingleCoil(ctx, 9, false), test.ShouldBeNil)
+		test.That(t, server.Coil(9), test.ShouldBeFalse)
+	})
+
+	t.Run("exceptions", func(t *testing.T) {
+		resp := server.handle([]byte{0x03, 0x00, 0x00, 0x00, 0xc8})
+		test.That(t, resp, test.ShouldResemble, []byte{0x83, 0x03})
+		resp = server.handle([]byte{0x2b, 0x0e})
+		test.That(t, resp, test.ShouldResemble, []byte{0xab, 0x01})
+	})
+
+	t.Run("close", func(t *testing.T) {
+		test.That(t, c1.Close(), test.ShouldBeNil)
+		_, err := c1.ReadCoils(ctx, 0, 1)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "closed")
+
+		// the connection is still shared with the other client
+		_, err = c2.ReadCoils(ctx, 0, 1)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, c2.Close(), test.ShouldBeNil)
+		globalMu.Lock()
+		test.That(t, buses, test.ShouldBeEmpty)
+		globalMu.Unlock()
+	})
+}
+
+// rtuSerial is a serial port with a Modbus RTU device on the other end, answering from the tables
+// of a server.
+type rtuSerial struct {
+	mu     sync.Mutex
+	server *Server
+	rx     []byte
+}
+
+func (s *rtuSerial) OpenHandle() (board.SerialHandle, error) {
+	s.mu.Lock()
+	return &rtuSerialHandle{s}, nil
+}
+
+type rtuSerialHandle struct {
+	port *rtuSerial
+}
+
+func (h *rtuSerialHandle) Write(ctx context.Context, tx []byte) error {
+	if len(tx) < 4 || binary.LittleEndian.Uint16(tx[len(tx)-2:]) != crc16(tx[:len(tx)-2]) {
+		return errors.New("bad frame")
+	}
+	resp := append([]byte{tx[0]}, h.port.server.handle(tx[1:len(tx)-2])...)
+	h.port.rx = binary.LittleEndian.AppendUint16(resp, crc16(resp))
+	return nil
+}
+
+// Read returns at most two bytes at a time, like a slow line would.
+func (h *rtuSerialHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	if len(h.port.rx) == 0 {
+		return nil, errors.New("nothing to read")
+	}
+	n := count
+	if n > 2 {
+		n = 2
+	}
+	if n > len(h.port.rx) {
+		n = len(h.port.rx)
+	}
+	rx := h.port.rx[:n]
+	h.port.rx = h.port.rx[n:]
+	return rx, nil
+}
+
+func (h *rtuSerialHandle) Close() error {
+	h.port.mu.Unlock()
+	return nil
+}
+
+func crc16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+type serialBoard struct {
+	*fakeboard.Board
+	serials map[string]board.Serial
+}
+
+func (b *serialBoard) SerialByName(name string) (board.Serial, bool) {
+	s, ok := b.serials[name]
+	return s, ok
+}
+
+func TestBoardSerialClient(t *testing.T) {
+	ctx := context.Background()
+	server, err := NewServer("localhost:0", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+	b := &serialBoard{
+		Board:   &fakeboard.Board{},
+		serials: map[string]board.Serial{"rs485": &rtuSerial{server: server}},
+	}
+	deps := registry.Dependencies{board.Named("board1"): b}
+
+	conf := Config{Protocol: ProtocolRTU, Board: "board1", Serial: "rs485"}
+	_, err = NewClient(conf)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs the board")
+
+	conf.Serial = "missing"
+	_, err = NewClientFromDependencies(deps, conf)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no serial port")
+
+	conf.Serial = "rs485"
+	c, err := NewClientFromDependencies(deps, conf)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, c.Close(), test.ShouldBeNil)
+	}()
+
+	server.SetInputRegister(10, 42)
+	server.SetInputRegister(11, 43)
+	words, err := c.ReadInputRegisters(ctx, 10, 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, words, test.ShouldResemble, []uint16{42, 43})
+
+	test.That(t, c.WriteMultipleRegisters(ctx, 6, []uint16{1, 2, 3}), test.ShouldBeNil)
+	test.That(t, server.HoldingRegister(7), test.ShouldEqual, 2)
+	test.That(t, c.WriteSingleCoil(ctx, 9, true), test.ShouldBeNil)
+	test.That(t, server.Coil(9), test.ShouldBeTrue)
+
+	// other units on the same port share the bus
+	c2, err := NewClientFromDependencies(deps, Config{Protocol: ProtocolRTU, Board: "board1", Serial: "rs485", UnitID: 2})
+	test.That(t, err, test.ShouldBeNil)
+	globalMu.Lock()
+	test.That(t, buses, test.ShouldHaveLength, 1)
+	globalMu.Unlock()
+	bits, err := c2.ReadCoils(ctx, 8, 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bits, test.ShouldResemble, []bool{false, true})
+	test.That(t, c2.Close(), test.ShouldBeNil)
+}
+
+func TestRegisterReadWrite(t *testing.T) {
+	ctx := context.Background()
+	server, err := NewServer("localhost:0", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+	c, err := NewClient(Config{Protocol: ProtocolTCP, Address: server.Addr()})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, c.Close(), test.ShouldBeNil)
+	}()
+
+	for _, tc := range []struct {
+		reg   RegisterConfig
+		value float64
+		words []uint16
+	}{
+		{RegisterConfig{Type: TypeUint16, Scale: 0.1}, 123.4, []uint16{1234}},
+		{RegisterConfig{Type: TypeInt16, Offset: -40}, -50, []uint16{0xfff6}},
+		{RegisterConfig{Type: TypeUint32}, 70000, []uint16{0x0001, 0x1170}},
+		{RegisterConfig{Type: TypeUint32, WordSwap: true}, 70000, []uint16{0x1170, 0x0001}},
+		{RegisterConfig{Type: TypeInt32}, -2, []uint16{0xffff, 0xfffe}},
+		{RegisterConfig{Type: TypeFloat32}, 1.5, []uint16{0x3fc0, 0x0000}},
+	} {
+		reg := tc.reg
+		reg.Name = reg.Type
+		reg.Table = TableHoldingRegister
+		reg.Address = 100
+		test.That(t, reg.Write(ctx, c, tc.value), test.ShouldBeNil)
+		words, err := c.ReadHoldingRegisters(ctx, 100, uint16(len(tc.words)))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, words, test.ShouldResemble, tc.words)
+		v, err := reg.ReadFloat(ctx, c)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, v, test.ShouldAlmostEqual, tc.value)
+	}
+
+	reg := RegisterConfig{Name: "small", Table: TableHoldingRegister, Type: TypeUint16}
+	err = reg.Write(ctx, c, -1)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "out of range")
+
+	reg = RegisterConfig{Name: "alarm", Table: TableDiscreteInput, Address: 7}
+	server.SetDiscreteInput(7, true)
+	v, err := reg.Read(ctx, c)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, true)
+	err = reg.Write(ctx, c, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot write")
+}
@@ -0,0 +1,243 @@
+package modbus
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// The Modbus data tables a register can live in.
+const (
+	TableCoil            = "coil"
+	TableDiscreteInput   = "discrete_input"
+	TableHoldingRegister = "holding"
+	TableInputRegister   = "input"
+)
+
+// The value types a register can be decoded as. 32-bit types span two consecutive registers.
+const (
+	TypeBool    = "bool"
+	TypeUint16  = "uint16"
+	TypeInt16   = "int16"
+	TypeUint32  = "uint32"
+	TypeInt32   = "int32"
+	TypeFloat32 = "float32"
+)
+
+// RegisterConfig maps a value to a location in one of the Modbus data tables. Numeric
+// values are reported as raw * scale + offset.
+type RegisterConfig struct {
+	Name    string `json:"name"`
+	Table   string `json:"table"`
+	Address uint16 `json:"address"`
+	// Type defaults to "bool" for coils and discrete inputs and "uint16" otherwise.
+	Type string `json:"type,omitempty"`
+	// WordSwap puts the low word of 32-bit values in the first register.
+	WordSwap bool    `json:"word_swap,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+	Offset   float64 `json:"offset,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *RegisterConfig) Validate(path string) error {
+	if conf.Name == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	switch conf.Table {
+	case TableCoil, TableDiscreteInput:
+		if conf.Type != "" && conf.Type != TypeBool {
+			return utils.NewConfigValidationError(path, errors.Errorf("%s registers can only be of type %q", conf.Table, TypeBool))
+		}
+	case TableHoldingRegister, TableInputRegister:
+		switch conf.Type {
+		case "", TypeBool, TypeUint16, TypeInt16, TypeUint32, TypeInt32, TypeFloat32:
+		default:
+			return utils.NewConfigValidationError(path, errors.Errorf("unsupported type %q", conf.Type))
+		}
+	case "":
+		return utils.NewConfigValidationFieldRequiredError(path, "table")
+	default:
+		return utils.NewConfigValidationError(path, errors.Errorf("unsupported table %q", conf.Table))
+	}
+	return nil
+}
+
+// Writable returns whether the register lives in a table that can be written to.
+func (conf *RegisterConfig) Writable() bool {
+	return conf.Table == TableCoil || conf.Table == TableHoldingRegister
+}
+
+func (conf *RegisterConfig) valueType() string {
+	if conf.Type != "" {
+		return conf.Type
+	}
+	if conf.Table == TableCoil || conf.Table == TableDiscreteInput {
+		return TypeBool
+	}
+	return TypeUint16
+}
+
+func (conf *RegisterConfig) scale() float64 {
+	if conf.Scale == 0 {
+		return 1
+	}
+	return conf.Scale
+}
+
+func (conf *RegisterConfig) words() uint16 {
+	switch conf.valueType() {
+	case TypeUint32, TypeInt32, TypeFloat32:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Read reads the register and returns either a bool or a scaled float64.
+func (conf *RegisterConfig) Read(ctx context.Context, c Client) (interface{}, error) {
+	switch conf.Table {
+	case TableCoil, TableDiscreteInput:
+		read := c.ReadCoils
+		if conf.Table == TableDiscreteInput {
+			read = c.ReadDiscreteInputs
+		}
+		bits, err := read(ctx, conf.Address, 1)
+		if err != nil {
+			return nil, err
+		}
+		return bits[0], nil
+	case TableHoldingRegister, TableInputRegister:
+		read := c.ReadHoldingRegisters
+		if conf.Table == TableInputRegister {
+			read = c.ReadInputRegisters
+		}
+		words, err := read(ctx, conf.Address, conf.words())
+		if err != nil {
+			return nil, err
+		}
+		return conf.decode(words), nil
+	default:
+		return nil, errors.Errorf("unsupported table %q", conf.Table)
+	}
+}
+
+// ReadFloat reads the register as a number, reporting booleans as 0 or 1.
+func (conf *RegisterConfig) ReadFloat(ctx context.Context, c Client) (float64, error) {
+	v, err := conf.Read(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	case float64:
+		return val, nil
+	default:
+		return 0, errors.Errorf("unexpected register value %v", v)
+	}
+}
+
+// Write unscales the value and writes it to the register. For boolean registers
+// any nonzero value is true.
+func (conf *RegisterConfig) Write(ctx context.Context, c Client, value float64) error {
+	switch conf.Table {
+	case TableCoil:
+		return c.WriteSingleCoil(ctx, conf.Address, value != 0)
+	case TableHoldingRegister:
+		words, err := conf.encode(value)
+		if err != nil {
+			return err
+		}
+		if len(words) == 1 {
+			return c.WriteSingleRegister(ctx, conf.Address, words[0])
+		}
+		return c.WriteMultipleRegisters(ctx, conf.Address, words)
+	default:
+		return errors.Errorf("cannot write to %s register %q", conf.Table, conf.Name)
+	}
+}
+
+func (conf *RegisterConfig) decode(words []uint16) interface{} {
+	var raw float64
+	switch conf.valueType() {
+	case TypeBool:
+		return words[0] != 0
+	case TypeUint16:
+		raw = float64(words[0])
+	case TypeInt16:
+		raw = float64(int16(words[0]))
+	case TypeUint32:
+		raw = float64(conf.join(words))
+	case TypeInt32:
+		raw = float64(int32(conf.join(words)))
+	case TypeFloat32:
+		raw = float64(math.Float32frombits(conf.join(words)))
+	}
+	return raw*conf.scale() + conf.Offset
+}
+
+func (conf *RegisterConfig) encode(value float64) ([]uint16, error) {
+	raw := (value - conf.Offset) / conf.scale()
+	inRange := func(lo, hi float64) error {
+		if raw < lo || raw > hi {
+			return errors.Errorf("value %v is out of range for %s register %q", value, conf.valueType(), conf.Name)
+		}
+		return nil
+	}
+	switch conf.valueType() {
+	case TypeBool:
+		if value != 0 {
+			return []uint16{1}, nil
+		}
+		return []uint16{0}, nil
+	case TypeUint16:
+		raw = math.Round(raw)
+		if err := inRange(0, math.MaxUint16); err != nil {
+			return nil, err
+		}
+		return []uint16{uint16(raw)}, nil
+	case TypeInt16:
+		raw = math.Round(raw)
+		if err := inRange(math.MinInt16, math.MaxInt16); err != nil {
+			return nil, err
+		}
+		return []uint16{uint16(int16(raw))}, nil
+	case TypeUint32:
+		raw = math.Round(raw)
+		if err := inRange(0, math.MaxUint32); err != nil {
+			return nil, err
+		}
+		return conf.split(uint32(raw)), nil
+	case TypeInt32:
+		raw = math.Round(raw)
+		if err := inRange(math.MinInt32, math.MaxInt32); err != nil {
+			return nil, err
+		}
+		return conf.split(uint32(int32(raw))), nil
+	case TypeFloat32:
+		return conf.split(math.Float32bits(float32(raw))), nil
+	default:
+		return nil, errors.Errorf("unsupported type %q", conf.Type)
+	}
+}
+
+func (conf *RegisterConfig) join(words []uint16) uint32 {
+	hi, lo := words[0], words[1]
+	if conf.WordSwap {
+		hi, lo = lo, hi
+	}
+	return uint32(hi)<<16 | uint32(lo)
+}
+
+func (conf *RegisterConfig) split(v uint32) []uint16 {
+	hi, lo := uint16(v>>16), uint16(v)
+	if conf.WordSwap {
+		return []uint16{lo, hi}
+	}
+	return []uint16{hi, lo}
+}
@@ -0,0 +1,281 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/goburrow/modbus"
+	goutils "go.viam.com/utils"
+)
+
+const mbapHeaderLength = 7
+
+// Server is an in-memory Modbus TCP server. It answers every unit ID from the same
+// set of tables and is meant to stand in for a device in tests and simulations.
+type Server struct {
+	mu             sync.Mutex
+	coils          map[uint16]bool
+	discreteInputs map[uint16]bool
+	holding        map[uint16]uint16
+	input          map[uint16]uint16
+
+	listener                net.Listener
+	logger                  golog.Logger
+	cancelCtx               context.Context
+	cancelFunc              func()
+	activeBackgroundWorkers sync.WaitGroup
+	connsMu                 sync.Mutex
+	conns                   map[net.Conn]struct{}
+}
+
+// NewServer starts a Modbus TCP server listening on the given address, e.g. "localhost:0".
+func NewServer(address string, logger golog.Logger) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	s := &Server{
+		coils:          map[uint16]bool{},
+		discreteInputs: map[uint16]bool{},
+		holding:        map[uint16]uint16{},
+		input:          map[uint16]uint16{},
+		listener:       listener,
+		logger:         logger,
+		cancelCtx:      cancelCtx,
+		cancelFunc:     cancelFunc,
+		conns:          map[net.Conn]struct{}{},
+	}
+	s.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(s.acceptLoop, s.activeBackgroundWorkers.Done)
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// SetCoil sets the value of a coil.
+func (s *Server) SetCoil(address uint16, value bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coils[address] = value
+}
+
+// Coil returns the value of a coil.
+func (s *Server) Coil(address uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coils[address]
+}
+
+// SetDiscreteInput sets the value of a discrete input.
+func (s *Server) SetDiscreteInput(address uint16, value bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discreteInputs[address] = value
+}
+
+// SetHoldingRegister sets the value of a holding register.
+func (s *Server) SetHoldingRegister(address, value uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holding[address] = value
+}
+
+// HoldingRegister returns the value of a holding register.
+func (s *Server) HoldingRegister(address uint16) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holding[address]
+}
+
+// SetInputRegister sets the value of an input register.
+func (s *Server) SetInputRegister(address, value uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.input[address] = value
+}
+
+// Close stops the server and disconnects all clients.
+func (s *Server) Close() error {
+	s.cancelFunc()
+	err := s.listener.Close()
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		delete(s.conns, conn)
+		goutils.UncheckedError(conn.Close())
+	}
+	s.connsMu.Unlock()
+	s.activeBackgroundWorkers.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.cancelCtx.Err() == nil {
+				s.logger.Errorw("error accepting modbus connection", "error", err)
+			}
+			return
+		}
+		s.connsMu.Lock()
+		if s.cancelCtx.Err() != nil {
+			s.connsMu.Unlock()
+			goutils.UncheckedError(conn.Close())
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.connsMu.Unlock()
+		s.activeBackgroundWorkers.Add(1)
+		goutils.ManagedGo(func() {
+			s.serveConn(conn)
+		}, s.activeBackgroundWorkers.Done)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.connsMu.Lock()
+		defer s.connsMu.Unlock()
+		// Close may have already disconnected us
+		if _, ok := s.conns[conn]; ok {
+			delete(s.conns, conn)
+			goutils.UncheckedError(conn.Close())
+		}
+	}()
+	header := make([]byte, mbapHeaderLength)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint16(header[4:])
+		if length < 2 {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		resp := s.handle(pdu)
+		adu := make([]byte, mbapHeaderLength+len(resp))
+		copy(adu, header[:4])
+		binary.BigEndian.PutUint16(adu[4:], uint16(len(resp)+1))
+		adu[6] = header[6]
+		copy(adu[mbapHeaderLength:], resp)
+		if _, err := conn.Write(adu); err != nil {
+			return
+		}
+	}
+}
+
+var errIllegalDataValue = errors.New("illegal data value")
+
+// handle processes a request PDU and returns the response PDU.
+func (s *Server) handle(pdu []byte) []byte {
+	function := pdu[0]
+	data := pdu[1:]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		resp []byte
+		err  error
+	)
+	switch function {
+	case modbus.FuncCodeReadCoils:
+		resp, err = readBits(s.coils, data)
+	case modbus.FuncCodeReadDiscreteInputs:
+		resp, err = readBits(s.discreteInputs, data)
+	case modbus.FuncCodeReadHoldingRegisters:
+		resp, err = readWords(s.holding, data)
+	case modbus.FuncCodeReadInputRegisters:
+		resp, err = readWords(s.input, data)
+	case modbus.FuncCodeWriteSingleCoil:
+		if len(data) != 4 {
+			err = errIllegalDataValue
+			break
+		}
+		switch binary.BigEndian.Uint16(data[2:]) {
+		case 0xFF00:
+			s.coils[binary.BigEndian.Uint16(data)] = true
+		case 0x0000:
+			s.coils[binary.BigEndian.Uint16(data)] = false
+		default:
+			err = errIllegalDataValue
+		}
+		resp = data
+	case modbus.FuncCodeWriteSingleRegister:
+		if len(data) != 4 {
+			err = errIllegalDataValue
+			break
+		}
+		s.holding[binary.BigEndian.Uint16(data)] = binary.BigEndian.Uint16(data[2:])
+		resp = data
+	case modbus.FuncCodeWriteMultipleRegisters:
+		if len(data) < 5 {
+			err = errIllegalDataValue
+			break
+		}
+		address := binary.BigEndian.Uint16(data)
+		quantity := binary.BigEndian.Uint16(data[2:])
+		values := data[5:]
+		if int(data[4]) != len(values) || len(values) != 2*int(quantity) {
+			err = errIllegalDataValue
+			break
+		}
+		for i := uint16(0); i < quantity; i++ {
+			s.holding[address+i] = binary.BigEndian.Uint16(values[2*i:])
+		}
+		resp = data[:4]
+	default:
+		return []byte{function | 0x80, modbus.ExceptionCodeIllegalFunction}
+	}
+	if err != nil {
+		return []byte{function | 0x80, modbus.ExceptionCodeIllegalDataValue}
+	}
+	return append([]byte{function}, resp...)
+}
+
+func readBits(table map[uint16]bool, data []byte) ([]byte, error) {
+	if len(data) != 4 {
+		return nil, errIllegalDataValue
+	}
+	address := binary.BigEndian.Uint16(data)
+	quantity := binary.BigEndian.Uint16(data[2:])
+	if quantity == 0 || quantity > 2000 {
+		return nil, errIllegalDataValue
+	}
+	resp := make([]byte, 1+(quantity+7)/8)
+	resp[0] = byte(len(resp) - 1)
+	for i := uint16(0); i < quantity; i++ {
+		if table[address+i] {
+			resp[1+i/8] |= 1 << (i % 8)
+		}
+	}
+	return resp, nil
+}
+
+func readWords(table map[uint16]uint16, data []byte) ([]byte, error) {
+	if len(data) != 4 {
+		return nil, errIllegalDataValue
+	}
+	address := binary.BigEndian.Uint16(data)
+	quantity := binary.BigEndian.Uint16(data[2:])
+	if quantity == 0 || quantity > 125 {
+		return nil, errIllegalDataValue
+	}
+	resp := make([]byte, 1+2*quantity)
+	resp[0] = byte(2 * quantity)
+	for i := uint16(0); i < quantity; i++ {
+		binary.BigEndian.PutUint16(resp[1+2*i:], table[address+i])
+	}
+	return resp, nil
+}
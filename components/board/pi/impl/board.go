@@ -63,6 +63,8 @@ type piPigpio struct {
 	analogs         map[string]board.AnalogReader
 	i2cs            map[string]board.I2C
 	spis            map[string]board.SPI
+	serials         map[string]board.Serial
+	cans            map[string]board.CAN
 	interrupts      map[string]board.DigitalInterrupt
 	interruptsHW    map[uint]board.DigitalInterrupt
 	logger          golog.Logger
@@ -145,21 +147,41 @@ func NewPigpio(ctx context.Context, cfg *genericlinux.Config, logger golog.Logge
 		}
 	}
 
+	// setup serial ports
+	if len(cfg.Serials) != 0 {
+		piInstance.serials = make(map[string]board.Serial, len(cfg.Serials))
+		for _, sc := range cfg.Serials {
+			s, err := board.NewSerialBus(sc)
+			if err != nil {
+				return nil, err
+			}
+			piInstance.serials[sc.Name] = s
+		}
+	}
+
+	// setup CAN buses
+	if len(cfg.CANs) != 0 {
+		piInstance.cans = make(map[string]board.CAN, len(cfg.CANs))
+		for _, cc := range cfg.CANs {
+			c, err := board.NewSocketCAN(cc)
+			if err != nil {
+				return nil, err
+			}
+			piInstance.cans[cc.Name] = c
+		}
+	}
+
 	// setup analogs
 	piInstance.analogs = map[string]board.AnalogReader{}
 	for _, ac := range cfg.Analogs {
-		channel, err := strconv.Atoi(ac.Pin)
+		ar, err := board.NewAnalogReader(ctx, piInstance, ac, logger)
 		if err != nil {
-			return nil, errors.Errorf("bad analog pin (%s)", ac.Pin)
-		}
-
-		bus, have := piInstance.SPIByName(ac.SPIBus)
-		if !have {
-			return nil, errors.Errorf("can't find SPI bus (%s) requested by AnalogReader", ac.SPIBus)
+			for _, built := range piInstance.analogs {
+				err = multierr.Combine(err, utils.TryClose(ctx, built))
+			}
+			return nil, err
 		}
-
-		ar := &board.MCP3008AnalogReader{channel, bus, ac.ChipSelect}
-		piInstance.analogs[ac.Name] = board.SmoothAnalogReader(ar, ac, logger)
+		piInstance.analogs[ac.Name] = ar
 	}
 
 	// setup interrupts
@@ -452,6 +474,30 @@ func (pi *piPigpio) SPINames() []string {
 	return names
 }
 
+// SerialNames returns the names of all known serial ports.
+func (pi *piPigpio) SerialNames() []string {
+	if len(pi.serials) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pi.serials))
+	for k := range pi.serials {
+		names = append(names, k)
+	}
+	return names
+}
+
+// CANNames returns the names of all known CAN buses.
+func (pi *piPigpio) CANNames() []string {
+	if len(pi.cans) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pi.cans))
+	for k := range pi.cans {
+		names = append(names, k)
+	}
+	return names
+}
+
 // I2CNames returns the name of all known SPI buses.
 func (pi *piPigpio) I2CNames() []string {
 	if len(pi.i2cs) == 0 {
@@ -497,6 +543,16 @@ func (pi *piPigpio) I2CByName(name string) (board.I2C, bool) {
 	return s, ok
 }
 
+func (pi *piPigpio) SerialByName(name string) (board.Serial, bool) {
+	s, ok := pi.serials[name]
+	return s, ok
+}
+
+func (pi *piPigpio) CANByName(name string) (board.CAN, bool) {
+	c, ok := pi.cans[name]
+	return c, ok
+}
+
 func (pi *piPigpio) DigitalInterruptByName(name string) (board.DigitalInterrupt, bool) {
 	pi.mu.Lock()
 	defer pi.mu.Unlock()
@@ -562,6 +618,10 @@ func (pi *piPigpio) Close(ctx context.Context) error {
 		err = multierr.Combine(err, utils.TryClose(ctx, spi))
 	}
 
+	for _, s := range pi.serials {
+		err = multierr.Combine(err, utils.TryClose(ctx, s))
+	}
+
 	for _, analog := range pi.analogs {
 		err = multierr.Combine(err, utils.TryClose(ctx, analog))
 	}
@@ -6,6 +6,7 @@ import (
 	_ "go.viam.com/rdk/components/board/arduino"
 	_ "go.viam.com/rdk/components/board/beaglebone"
 	_ "go.viam.com/rdk/components/board/fake"
+	_ "go.viam.com/rdk/components/board/firmata"
 	_ "go.viam.com/rdk/components/board/hat/pca9685"
 	_ "go.viam.com/rdk/components/board/jetson"
 	_ "go.viam.com/rdk/components/board/numato"
@@ -0,0 +1,458 @@
+// Package regmap reads chips on an I2C or SPI bus that are described by a map of their
+// registers, so that supporting a new chip takes configuration instead of a driver.
+package regmap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erh/scheme"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+)
+
+const (
+	defaultSPIBaudRate = 1000000
+	defaultSPIReadMask = 0x80
+	// the widest value a field can hold without losing precision in a uint64
+	maxFieldBytes = 8
+)
+
+// Config describes where a chip is and what its registers mean.
+type Config struct {
+	Board   string `json:"board"`
+	I2CBus  string `json:"i2c_bus,omitempty"`
+	I2CAddr int    `json:"i2c_addr,omitempty"`
+
+	SPIBus      string `json:"spi_bus,omitempty"`
+	ChipSelect  string `json:"chip_select,omitempty"`
+	SPIBaudRate int    `json:"spi_baud_rate,omitempty"` // defaults to 1MHz
+	SPIMode     int    `json:"spi_mode,omitempty"`
+	// SPIReadMask is set in the register address of reads and cleared in that of writes.
+	// It defaults to 0x80, which is what most chips expect.
+	SPIReadMask *int `json:"spi_read_mask,omitempty"`
+	// SPIMultiByteMask is set in the register address of transfers of more than one byte,
+	// for chips that otherwise only transfer a single register.
+	SPIMultiByteMask int `json:"spi_multi_byte_mask,omitempty"`
+
+	// Identity is checked before anything is written to the chip.
+	Identity *IdentityConfig `json:"identity,omitempty"`
+	// Init is written to the chip in order when it is set up.
+	Init    []InitConfig    `json:"init,omitempty"`
+	Fields  []FieldConfig   `json:"fields"`
+	Derived []DerivedConfig `json:"derived,omitempty"`
+}
+
+// IdentityConfig is a register holding a fixed value that identifies the chip, usually
+// called WHO_AM_I or CHIP_ID in datasheets.
+type IdentityConfig struct {
+	Register int `json:"register"`
+	Value    int `json:"value"`
+}
+
+// InitConfig writes values to consecutive registers, then waits before the next step.
+type InitConfig struct {
+	Register int   `json:"register"`
+	Values   []int `json:"values,omitempty"`
+	DelayMs  int   `json:"delay_ms,omitempty"`
+}
+
+// FieldConfig is a value read from one or more consecutive registers. The value is
+// reported as raw * scale + offset.
+type FieldConfig struct {
+	Name     string `json:"name"`
+	Register int    `json:"register"`
+	Length   int    `json:"length,omitempty"` // in bytes, defaults to 1
+	// LittleEndian puts the least significant byte in the first register.
+	LittleEndian bool `json:"little_endian,omitempty"`
+	// Shift drops low bits that are not part of the value, and Bits is how many of the bits
+	// left are, all of them if unset.
+	Shift  int     `json:"shift,omitempty"`
+	Bits   int     `json:"bits,omitempty"`
+	Signed bool    `json:"signed,omitempty"`
+	Scale  float64 `json:"scale,omitempty"`
+	Offset float64 `json:"offset,omitempty"`
+}
+
+// DerivedConfig is a value computed from fields, and derived values before it, with a
+// formula such as "(+ (* temp 1.8) 32)".
+type DerivedConfig struct {
+	Name    string `json:"name"`
+	Formula string `json:"formula"`
+}
+
+func validateByte(path, field string, v int) error {
+	if v < 0 || v > 0xFF {
+		return utils.NewConfigValidationError(path, errors.Errorf("%s %d does not fit in a byte", field, v))
+	}
+	return nil
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) error {
+	if conf.Board == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	switch {
+	case conf.I2CBus != "" && conf.SPIBus != "":
+		return utils.NewConfigValidationError(path, errors.New("only one of i2c_bus and spi_bus can be set"))
+	case conf.I2CBus != "":
+		if conf.I2CAddr == 0 {
+			return utils.NewConfigValidationFieldRequiredError(path, "i2c_addr")
+		}
+		if conf.I2CAddr < 0 || conf.I2CAddr > 0x7F {
+			return utils.NewConfigValidationError(path, errors.Errorf("i2c_addr %#x does not fit in 7 bits", conf.I2CAddr))
+		}
+	case conf.SPIBus != "":
+		if conf.ChipSelect == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "chip_select")
+		}
+		if conf.SPIMode < 0 || conf.SPIMode > 3 {
+			return utils.NewConfigValidationError(path, errors.Errorf("spi_mode must be between 0 and 3, got %d", conf.SPIMode))
+		}
+		if conf.SPIReadMask != nil {
+			if err := validateByte(path, "spi_read_mask", *conf.SPIReadMask); err != nil {
+				return err
+			}
+		}
+		if err := validateByte(path, "spi_multi_byte_mask", conf.SPIMultiByteMask); err != nil {
+			return err
+		}
+	default:
+		return utils.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+
+	if conf.Identity != nil {
+		idPath := fmt.Sprintf("%s.%s", path, "identity")
+		if err := validateByte(idPath, "register", conf.Identity.Register); err != nil {
+			return err
+		}
+		if err := validateByte(idPath, "value", conf.Identity.Value); err != nil {
+			return err
+		}
+	}
+	for idx, init := range conf.Init {
+		initPath := fmt.Sprintf("%s.%s.%d", path, "init", idx)
+		if err := validateByte(initPath, "register", init.Register); err != nil {
+			return err
+		}
+		if len(init.Values) == 0 && init.DelayMs == 0 {
+			return utils.NewConfigValidationFieldRequiredError(initPath, "values")
+		}
+		for _, v := range init.Values {
+			if err := validateByte(initPath, "value", v); err != nil {
+				return err
+			}
+		}
+		if init.DelayMs < 0 {
+			return utils.NewConfigValidationError(initPath, errors.New("delay_ms cannot be negative"))
+		}
+	}
+
+	if len(conf.Fields) == 0 {
+		return utils.NewConfigValidationFieldRequiredError(path, "fields")
+	}
+	names := map[string]bool{}
+	for idx, field := range conf.Fields {
+		fieldPath := fmt.Sprintf("%s.%s.%d", path, "fields", idx)
+		if err := field.Validate(fieldPath); err != nil {
+			return err
+		}
+		if names[field.Name] {
+			return utils.NewConfigValidationError(fieldPath, errors.Errorf("duplicate name %q", field.Name))
+		}
+		names[field.Name] = true
+	}
+	for idx, derived := range conf.Derived {
+		derivedPath := fmt.Sprintf("%s.%s.%d", path, "derived", idx)
+		if derived.Name == "" {
+			return utils.NewConfigValidationFieldRequiredError(derivedPath, "name")
+		}
+		if names[derived.Name] {
+			return utils.NewConfigValidationError(derivedPath, errors.Errorf("duplicate name %q", derived.Name))
+		}
+		if derived.Formula == "" {
+			return utils.NewConfigValidationFieldRequiredError(derivedPath, "formula")
+		}
+		expr, err := scheme.Parse(derived.Formula)
+		if err != nil {
+			return utils.NewConfigValidationError(derivedPath, errors.Wrapf(err, "couldn't parse formula for %s", derived.Name))
+		}
+		// try the formula out on everything defined before it
+		scope := scheme.Scope{}
+		for name := range names {
+			one := 1.0
+			scope[name] = &scheme.Value{Float: &one}
+		}
+		if _, err := scheme.Eval(expr, scope); err != nil {
+			return utils.NewConfigValidationError(derivedPath, errors.Wrapf(err, "test exec failed for %s", derived.Name))
+		}
+		names[derived.Name] = true
+	}
+	return nil
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *FieldConfig) Validate(path string) error {
+	if conf.Name == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if err := validateByte(path, "register", conf.Register); err != nil {
+		return err
+	}
+	length := conf.length()
+	if length < 1 || length > maxFieldBytes {
+		return utils.NewConfigValidationError(path, errors.Errorf("length must be between 1 and %d bytes", maxFieldBytes))
+	}
+	if conf.Shift < 0 || conf.Shift >= length*8 {
+		return utils.NewConfigValidationError(path, errors.Errorf("shift must leave some of the %d bits", length*8))
+	}
+	if conf.Bits < 0 || conf.Bits > length*8-conf.Shift {
+		return utils.NewConfigValidationError(path, errors.Errorf("bits must be at most the %d bits left after shifting", length*8-conf.Shift))
+	}
+	return nil
+}
+
+func (conf *FieldConfig) length() int {
+	if conf.Length == 0 {
+		return 1
+	}
+	return conf.Length
+}
+
+// Decode turns the bytes of the field's registers into its value.
+func (conf *FieldConfig) Decode(data []byte) float64 {
+	var raw uint64
+	for i := range data {
+		b := data[i]
+		if conf.LittleEndian {
+			b = data[len(data)-1-i]
+		}
+		raw = raw<<8 | uint64(b)
+	}
+	raw >>= conf.Shift
+	bits := conf.Bits
+	if bits == 0 {
+		bits = len(data)*8 - conf.Shift
+	}
+	if bits < 64 {
+		raw &= 1<<bits - 1
+	}
+	value := float64(raw)
+	if conf.Signed {
+		// sign extend by moving the top bit of the value to the top of the word
+		shift := 64 - bits
+		value = float64(int64(raw<<shift) >> shift)
+	}
+	scale := conf.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return value*scale + conf.Offset
+}
+
+// transport moves register contents to and from the chip.
+type transport interface {
+	readRegisters(ctx context.Context, register byte, count int) ([]byte, error)
+	writeRegisters(ctx context.Context, register byte, data []byte) error
+}
+
+type i2cTransport struct {
+	bus  board.I2C
+	addr byte
+}
+
+func (t *i2cTransport) readRegisters(ctx context.Context, register byte, count int) (data []byte, err error) {
+	handle, err := t.bus.OpenHandle(t.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = multierr.Combine(err, handle.Close())
+	}()
+	return handle.ReadBlockData(ctx, register, uint8(count))
+}
+
+func (t *i2cTransport) writeRegisters(ctx context.Context, register byte, data []byte) (err error) {
+	handle, err := t.bus.OpenHandle(t.addr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, handle.Close())
+	}()
+	return handle.WriteBlockData(ctx, register, data)
+}
+
+type spiTransport struct {
+	bus           board.SPI
+	chipSelect    string
+	baud          uint
+	mode          uint
+	readMask      byte
+	multiByteMask byte
+}
+
+func (t *spiTransport) xfer(ctx context.Context, tx []byte) (rx []byte, err error) {
+	handle, err := t.bus.OpenHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = multierr.Combine(err, handle.Close())
+	}()
+	return handle.Xfer(ctx, t.baud, t.chipSelect, t.mode, tx)
+}
+
+func (t *spiTransport) address(register byte, count int) byte {
+	if count > 1 {
+		register |= t.multiByteMask
+	}
+	return register
+}
+
+func (t *spiTransport) readRegisters(ctx context.Context, register byte, count int) ([]byte, error) {
+	tx := make([]byte, count+1)
+	tx[0] = t.address(register, count) | t.readMask
+	rx, err := t.xfer(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rx) != len(tx) {
+		return nil, errors.Errorf("expected %d bytes from SPI transfer, got %d", len(tx), len(rx))
+	}
+	return rx[1:], nil
+}
+
+func (t *spiTransport) writeRegisters(ctx context.Context, register byte, data []byte) error {
+	tx := append([]byte{t.address(register, len(data)) &^ t.readMask}, data...)
+	_, err := t.xfer(ctx, tx)
+	return err
+}
+
+// A Device is a chip set up according to its register map.
+type Device struct {
+	conf      *Config
+	transport transport
+	formulas  []*scheme.Expression
+}
+
+// NewDevice finds the chip's bus on the board, checks the chip's identity and initializes it.
+func NewDevice(ctx context.Context, b board.Board, conf *Config) (*Device, error) {
+	localB, ok := b.(board.LocalBoard)
+	if !ok {
+		return nil, errors.Errorf("board %s is not local", conf.Board)
+	}
+	d := &Device{conf: conf}
+	if conf.I2CBus != "" {
+		bus, ok := localB.I2CByName(conf.I2CBus)
+		if !ok {
+			return nil, errors.Errorf("can't find I2C bus %s", conf.I2CBus)
+		}
+		d.transport = &i2cTransport{bus: bus, addr: byte(conf.I2CAddr)}
+	} else {
+		bus, ok := localB.SPIByName(conf.SPIBus)
+		if !ok {
+			return nil, errors.Errorf("can't find SPI bus %s", conf.SPIBus)
+		}
+		t := &spiTransport{
+			bus:           bus,
+			chipSelect:    conf.ChipSelect,
+			baud:          uint(conf.SPIBaudRate),
+			mode:          uint(conf.SPIMode),
+			readMask:      defaultSPIReadMask,
+			multiByteMask: byte(conf.SPIMultiByteMask),
+		}
+		if t.baud == 0 {
+			t.baud = defaultSPIBaudRate
+		}
+		if conf.SPIReadMask != nil {
+			t.readMask = byte(*conf.SPIReadMask)
+		}
+		d.transport = t
+	}
+
+	for _, derived := range conf.Derived {
+		expr, err := scheme.Parse(derived.Formula)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse formula for %s", derived.Name)
+		}
+		d.formulas = append(d.formulas, expr)
+	}
+
+	if id := conf.Identity; id != nil {
+		data, err := d.transport.readRegisters(ctx, byte(id.Register), 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read chip identity")
+		}
+		if data[0] != byte(id.Value) {
+			return nil, errors.Errorf("unexpected chip identity %#x in register %#x, expected %#x", data[0], id.Register, id.Value)
+		}
+	}
+	for _, init := range conf.Init {
+		if len(init.Values) > 0 {
+			data := make([]byte, len(init.Values))
+			for i, v := range init.Values {
+				data[i] = byte(v)
+			}
+			if err := d.transport.writeRegisters(ctx, byte(init.Register), data); err != nil {
+				return nil, errors.Wrapf(err, "failed to initialize register %#x", init.Register)
+			}
+		}
+		if init.DelayMs > 0 && !utils.SelectContextOrWait(ctx, time.Duration(init.DelayMs)*time.Millisecond) {
+			return nil, ctx.Err()
+		}
+	}
+	return d, nil
+}
+
+// Read reads every field and computes the derived values, keyed by name.
+func (d *Device) Read(ctx context.Context) (map[string]float64, error) {
+	values := make(map[string]float64, len(d.conf.Fields)+len(d.conf.Derived))
+	scope := scheme.Scope{}
+	for i := range d.conf.Fields {
+		field := &d.conf.Fields[i]
+		data, err := d.transport.readRegisters(ctx, byte(field.Register), field.length())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", field.Name)
+		}
+		v := field.Decode(data)
+		values[field.Name] = v
+		scope[field.Name] = &scheme.Value{Float: &v}
+	}
+	for i, expr := range d.formulas {
+		name := d.conf.Derived[i].Name
+		res, err := scheme.Eval(expr, scope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute %s", name)
+		}
+		v, err := res.ToFloat()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute %s", name)
+		}
+		values[name] = v
+		scope[name] = &scheme.Value{Float: &v}
+	}
+	return values, nil
+}
+
+// WriteRegisters writes to consecutive registers of the chip.
+func (d *Device) WriteRegisters(ctx context.Context, register byte, data []byte) error {
+	return d.transport.writeRegisters(ctx, register, data)
+}
+
+// Names returns the names of all fields and derived values.
+func (conf *Config) Names() map[string]bool {
+	names := map[string]bool{}
+	for _, f := range conf.Fields {
+		names[f.Name] = true
+	}
+	for _, d := range conf.Derived {
+		names[d.Name] = true
+	}
+	return names
+}
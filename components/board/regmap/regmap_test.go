@@ -0,0 +1,213 @@
+package regmap
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func i2cBoard(bus *simdevice.I2CBus) *inject.Board {
+	return &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) {
+		return bus, name == "main"
+	}}
+}
+
+func TestConfigValidate(t *testing.T) {
+	validConfig := func() *Config {
+		return &Config{
+			Board:   "b",
+			I2CBus:  "main",
+			I2CAddr: 0x76,
+			Fields:  []FieldConfig{{Name: "temp", Register: 0xFA, Length: 2}},
+			Derived: []DerivedConfig{{Name: "temp_f", Formula: "(+ (* temp 1.8) 32)"}},
+		}
+	}
+	test.That(t, validConfig().Validate("path"), test.ShouldBeNil)
+
+	for _, tc := range []struct {
+		name   string
+		modify func(conf *Config)
+		err    string
+	}{
+		{"no board", func(conf *Config) { conf.Board = "" }, `"board" is required`},
+		{"no bus", func(conf *Config) { conf.I2CBus = "" }, `"i2c_bus" is required`},
+		{"both buses", func(conf *Config) { conf.SPIBus = "spi" }, "only one of"},
+		{"no address", func(conf *Config) { conf.I2CAddr = 0 }, `"i2c_addr" is required`},
+		{"wide address", func(conf *Config) { conf.I2CAddr = 0x80 }, "7 bits"},
+		{"no chip select", func(conf *Config) { conf.I2CBus, conf.SPIBus = "", "spi" }, `"chip_select" is required`},
+		{"wide identity", func(conf *Config) { conf.Identity = &IdentityConfig{Register: 0xD0, Value: 0x160} }, "path.identity"},
+		{"empty init", func(conf *Config) { conf.Init = []InitConfig{{Register: 1}} }, "path.init.0"},
+		{"no fields", func(conf *Config) { conf.Fields = nil }, `"fields" is required`},
+		{"long field", func(conf *Config) { conf.Fields[0].Length = 9 }, "length"},
+		{"shifted out", func(conf *Config) { conf.Fields[0].Shift = 16 }, "shift"},
+		{"too many bits", func(conf *Config) { conf.Fields[0].Shift, conf.Fields[0].Bits = 4, 13 }, "bits"},
+		{"duplicate", func(conf *Config) { conf.Derived[0].Name = "temp" }, "duplicate"},
+		{"bad formula", func(conf *Config) { conf.Derived[0].Formula = "(+ temp" }, "couldn't parse"},
+		{"unknown name", func(conf *Config) { conf.Derived[0].Formula = "(+ pressure 1)" }, "test exec failed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := validConfig()
+			tc.modify(conf)
+			err := conf.Validate("path")
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	field := FieldConfig{Length: 2}
+	test.That(t, field.Decode([]byte{0x12, 0x34}), test.ShouldEqual, 0x1234)
+
+	field.LittleEndian = true
+	test.That(t, field.Decode([]byte{0x12, 0x34}), test.ShouldEqual, 0x3412)
+
+	field = FieldConfig{Length: 2, Signed: true, Scale: 0.5, Offset: 1}
+	test.That(t, field.Decode([]byte{0xFF, 0xFE}), test.ShouldEqual, 0)
+
+	// a 12 bit value left aligned in two registers, as many chips report temperature
+	field = FieldConfig{Length: 2, Shift: 4, Signed: true}
+	test.That(t, field.Decode([]byte{0x80, 0x00}), test.ShouldEqual, -2048)
+	test.That(t, field.Decode([]byte{0x7F, 0xF0}), test.ShouldEqual, 2047)
+
+	// the low six bits of a register
+	field = FieldConfig{Bits: 6}
+	test.That(t, field.Decode([]byte{0xC5}), test.ShouldEqual, 5)
+
+	field = FieldConfig{Length: 8}
+	test.That(t, field.Decode([]byte{0, 0, 0, 0, 0, 0, 1, 0}), test.ShouldEqual, 256)
+}
+
+func TestI2CDevice(t *testing.T) {
+	ctx := context.Background()
+	bus := simdevice.NewI2CBus()
+	chip := &simdevice.Registers{}
+	bus.Attach(0x76, chip)
+	chip.Set(0xD0, 0x60)
+	chip.Set(0xFA, 0x01, 0x90)
+
+	conf := &Config{
+		Board:    "b",
+		I2CBus:   "main",
+		I2CAddr:  0x76,
+		Identity: &IdentityConfig{Register: 0xD0, Value: 0x60},
+		Init: []InitConfig{
+			{Register: 0xE0, Values: []int{0xB6}, DelayMs: 1},
+			{Register: 0xF4, Values: []int{0x27, 0xA0}},
+		},
+		Fields: []FieldConfig{{Name: "temp", Register: 0xFA, Length: 2, Scale: 0.0625}},
+		Derived: []DerivedConfig{
+			{Name: "temp_f", Formula: "(+ (* temp 1.8) 32)"},
+			{Name: "double_f", Formula: "(* temp_f 2)"},
+		},
+	}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	d, err := NewDevice(ctx, i2cBoard(bus), conf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bus.Transactions(), test.ShouldResemble, []simdevice.I2CTransaction{
+		{Addr: 0x76, Write: true, Data: []byte{0xD0}},
+		{Addr: 0x76, Data: []byte{0x60}},
+		{Addr: 0x76, Write: true, Data: []byte{0xE0, 0xB6}},
+		{Addr: 0x76, Write: true, Data: []byte{0xF4, 0x27, 0xA0}},
+	})
+	test.That(t, chip.Get(0xF4, 2), test.ShouldResemble, []byte{0x27, 0xA0})
+
+	values, err := d.Read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, values, test.ShouldResemble, map[string]float64{"temp": 25, "temp_f": 77, "double_f": 154})
+
+	test.That(t, d.WriteRegisters(ctx, 0xFA, []byte{0x00, 0x10}), test.ShouldBeNil)
+	values, err = d.Read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, values["temp"], test.ShouldEqual, 1)
+
+	t.Run("wrong identity", func(t *testing.T) {
+		chip.Set(0xD0, 0x58)
+		defer chip.Set(0xD0, 0x60)
+		_, err := NewDevice(ctx, i2cBoard(bus), conf)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "unexpected chip identity 0x58")
+	})
+
+	t.Run("missing chip", func(t *testing.T) {
+		other := *conf
+		other.I2CAddr = 0x77
+		_, err := NewDevice(ctx, i2cBoard(bus), &other)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no I2C device at address 0x77")
+	})
+
+	t.Run("missing bus", func(t *testing.T) {
+		other := *conf
+		other.I2CBus = "aux"
+		_, err := NewDevice(ctx, i2cBoard(bus), &other)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "can't find I2C bus aux")
+	})
+}
+
+func TestSPIDevice(t *testing.T) {
+	ctx := context.Background()
+	registers := make([]byte, 0x80)
+	registers[0x0F] = 0x33
+	registers[0x28] = 0x10
+	registers[0x29] = 0xFF
+
+	var transfers [][]byte
+	handle := &inject.SPIHandle{
+		XferFunc: func(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+			test.That(t, baud, test.ShouldEqual, defaultSPIBaudRate)
+			test.That(t, chipSelect, test.ShouldEqual, "24")
+			test.That(t, mode, test.ShouldEqual, 3)
+			transfers = append(transfers, append([]byte{}, tx...))
+			// a chip with a read bit of 0x80 and an auto increment bit of 0x40
+			register := tx[0] & 0x3F
+			rx := make([]byte, len(tx))
+			for i := 1; i < len(tx); i++ {
+				if tx[0]&0x80 != 0 {
+					rx[i] = registers[register]
+				} else {
+					registers[register] = tx[i]
+				}
+				if tx[0]&0x40 != 0 {
+					register++
+				}
+			}
+			return rx, nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	spi := &inject.SPI{OpenHandleFunc: func() (board.SPIHandle, error) { return handle, nil }}
+	b := &inject.Board{SPIByNameFunc: func(name string) (board.SPI, bool) { return spi, name == "main" }}
+
+	conf := &Config{
+		Board:            "b",
+		SPIBus:           "main",
+		ChipSelect:       "24",
+		SPIMode:          3,
+		SPIMultiByteMask: 0x40,
+		Identity:         &IdentityConfig{Register: 0x0F, Value: 0x33},
+		Init:             []InitConfig{{Register: 0x20, Values: []int{0x67}}},
+		Fields:           []FieldConfig{{Name: "x", Register: 0x28, Length: 2, LittleEndian: true, Signed: true}},
+	}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	d, err := NewDevice(ctx, b, conf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, registers[0x20], test.ShouldEqual, 0x67)
+
+	values, err := d.Read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, values["x"], test.ShouldEqual, -240)
+	test.That(t, transfers, test.ShouldResemble, [][]byte{
+		{0x8F, 0},
+		{0x20, 0x67},
+		{0xE8, 0, 0},
+	})
+}
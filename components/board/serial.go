@@ -0,0 +1,250 @@
+package board
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"sync"
+
+	"github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// SerialConfig enumerates a specific, shareable serial port.
+type SerialConfig struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	BaudRate int    `json:"baud_rate,omitempty"` // defaults to 9600
+	DataBits int    `json:"data_bits,omitempty"` // defaults to 8
+	StopBits int    `json:"stop_bits,omitempty"` // defaults to 1
+	Parity   string `json:"parity,omitempty"`    // "none", "odd" or "even", defaults to none
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *SerialConfig) Validate(path string) error {
+	if config.Name == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if config.Path == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "path")
+	}
+	if config.BaudRate < 0 {
+		return utils.NewConfigValidationError(path, errors.New("baud_rate cannot be negative"))
+	}
+	if config.DataBits != 0 && (config.DataBits < 5 || config.DataBits > 8) {
+		return utils.NewConfigValidationError(path, errors.New("data_bits must be between 5 and 8"))
+	}
+	if config.StopBits != 0 && config.StopBits != 1 && config.StopBits != 2 {
+		return utils.NewConfigValidationError(path, errors.New("stop_bits must be 1 or 2"))
+	}
+	if _, err := parityMode(config.Parity); err != nil {
+		return utils.NewConfigValidationError(path, err)
+	}
+	return nil
+}
+
+func parityMode(parity string) (serial.ParityMode, error) {
+	switch parity {
+	case "", "none":
+		return serial.PARITY_NONE, nil
+	case "odd":
+		return serial.PARITY_ODD, nil
+	case "even":
+		return serial.PARITY_EVEN, nil
+	default:
+		return serial.PARITY_NONE, errors.Errorf("unknown parity %q, must be none, odd or even", parity)
+	}
+}
+
+// A SerialBoard is a board with serial ports that can be requested by name.
+type SerialBoard interface {
+	// SerialByName returns a serial port by name.
+	SerialByName(name string) (Serial, bool)
+
+	// SerialNames returns the names of all known serial ports.
+	SerialNames() []string
+}
+
+// Serial represents a shareable serial port on the board.
+type Serial interface {
+	// OpenHandle locks the shared port and returns a handle interface that MUST be closed when done.
+	OpenHandle() (SerialHandle, error)
+}
+
+// SerialHandle is similar to an io handle. It MUST be closed to release the port.
+type SerialHandle interface {
+	// Write writes all of the given bytes to the port.
+	Write(ctx context.Context, tx []byte) error
+
+	// Read reads up to count bytes from the port, blocking until at least one byte is available
+	// or the context is done.
+	Read(ctx context.Context, count int) ([]byte, error)
+
+	// Close closes the handle and releases the lock on the port.
+	Close() error
+}
+
+// readPollMillis is how long a read on a serial port waits for data before checking whether its
+// context is done. The terminal driver only supports multiples of 100ms.
+const readPollMillis = 100
+
+// serialBus is a serial port on the local machine that is opened on first use and kept open, so
+// that no data is lost between handles.
+type serialBus struct {
+	mu      sync.Mutex
+	options serial.OpenOptions
+	port    io.ReadWriteCloser
+}
+
+// NewSerialBus returns a shareable serial port for the device described by the given config.
+func NewSerialBus(config SerialConfig) (Serial, error) {
+	parity, err := parityMode(config.Parity)
+	if err != nil {
+		return nil, err
+	}
+	options := serial.OpenOptions{
+		PortName:              config.Path,
+		BaudRate:              uint(config.BaudRate),
+		DataBits:              uint(config.DataBits),
+		StopBits:              uint(config.StopBits),
+		ParityMode:            parity,
+		InterCharacterTimeout: readPollMillis,
+	}
+	if options.BaudRate == 0 {
+		options.BaudRate = 9600
+	}
+	if options.DataBits == 0 {
+		options.DataBits = 8
+	}
+	if options.StopBits == 0 {
+		options.StopBits = 1
+	}
+	return &serialBus{options: options}, nil
+}
+
+func (sb *serialBus) OpenHandle() (SerialHandle, error) {
+	sb.mu.Lock()
+	if sb.port == nil {
+		port, err := serial.Open(sb.options)
+		if err != nil {
+			sb.mu.Unlock()
+			return nil, errors.Wrapf(err, "cannot open serial port %s", sb.options.PortName)
+		}
+		sb.port = port
+	}
+	return &serialHandle{bus: sb}, nil
+}
+
+// Close closes the underlying port. The next handle opened will reopen it.
+func (sb *serialBus) Close() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.port == nil {
+		return nil
+	}
+	err := sb.port.Close()
+	sb.port = nil
+	return err
+}
+
+type serialHandle struct {
+	bus      *serialBus
+	isClosed bool
+}
+
+func (sh *serialHandle) Write(ctx context.Context, tx []byte) error {
+	if sh.isClosed {
+		return errors.New("can't use Write() on an already closed SerialHandle")
+	}
+	_, err := sh.bus.port.Write(tx)
+	return err
+}
+
+func (sh *serialHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	if sh.isClosed {
+		return nil, errors.New("can't use Read() on an already closed SerialHandle")
+	}
+	buf := make([]byte, count)
+	for {
+		// Reads return empty handed after readPollMillis without data, giving us a chance to
+		// notice the context being done.
+		n, err := sh.bus.port.Read(buf)
+		if n > 0 {
+			return buf[:n], nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (sh *serialHandle) Close() error {
+	if sh.isClosed {
+		return nil
+	}
+	sh.isClosed = true
+	sh.bus.mu.Unlock()
+	return nil
+}
+
+// Serial ports are not part of the board API, so remote boards expose them through DoCommand
+// with these commands. They are namespaced so they do not shadow the commands of board models.
+const (
+	SerialNamesCommand = "rdk:board:serial_names"
+	SerialWriteCommand = "rdk:board:serial_write"
+	SerialReadCommand  = "rdk:board:serial_read"
+)
+
+// doSerialCommand handles a serial port DoCommand on the given board. It returns false if the
+// command is not a serial port command.
+func doSerialCommand(ctx context.Context, b Board, cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	name := cmd["command"]
+	if name != SerialNamesCommand && name != SerialWriteCommand && name != SerialReadCommand {
+		return nil, false, nil
+	}
+	sb, ok := b.(SerialBoard)
+	if !ok {
+		return nil, true, errors.New("board does not support serial ports")
+	}
+	if name == SerialNamesCommand {
+		names := []interface{}{}
+		for _, n := range sb.SerialNames() {
+			names = append(names, n)
+		}
+		return map[string]interface{}{"names": names}, true, nil
+	}
+
+	serialName, _ := cmd["serial"].(string)
+	s, ok := sb.SerialByName(serialName)
+	if !ok {
+		return nil, true, errors.Errorf("unknown serial port %q", serialName)
+	}
+	handle, err := s.OpenHandle()
+	if err != nil {
+		return nil, true, err
+	}
+	defer utils.UncheckedErrorFunc(handle.Close)
+
+	if name == SerialWriteCommand {
+		encoded, _ := cmd["data"].(string)
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, true, errors.Wrap(err, "serial data must be base64 encoded")
+		}
+		return map[string]interface{}{}, true, handle.Write(ctx, data)
+	}
+
+	count, ok := cmd["count"].(float64)
+	if !ok || count < 1 {
+		return nil, true, errors.New("need a positive count to read from a serial port")
+	}
+	data, err := handle.Read(ctx, int(count))
+	if err != nil {
+		return nil, true, err
+	}
+	return map[string]interface{}{"data": base64.StdEncoding.EncodeToString(data)}, true, nil
+}
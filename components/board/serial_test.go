@@ -0,0 +1,192 @@
+package board_test
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	viamgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/subtype"
+)
+
+func TestSerialConfigValidate(t *testing.T) {
+	conf := board.SerialConfig{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"name" is required`)
+
+	conf.Name = "uart"
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"path" is required`)
+
+	conf.Path = "/dev/ttyS0"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.DataBits = 9
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.DataBits = 7
+
+	conf.StopBits = 3
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.StopBits = 2
+
+	conf.Parity = "mark"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.Parity = "even"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+}
+
+func TestSerialBus(t *testing.T) {
+	master, slave, err := pty.Open()
+	test.That(t, err, test.ShouldBeNil)
+	defer utils.UncheckedErrorFunc(master.Close)
+	defer utils.UncheckedErrorFunc(slave.Close)
+
+	s, err := board.NewSerialBus(board.SerialConfig{Name: "uart", Path: slave.Name(), BaudRate: 115200})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, utils.TryClose(context.Background(), s), test.ShouldBeNil)
+	}()
+
+	handle, err := s.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, handle.Write(context.Background(), []byte("ping")), test.ShouldBeNil)
+	buf := make([]byte, 4)
+	_, err = master.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(buf), test.ShouldEqual, "ping")
+
+	_, err = master.Write([]byte("pong"))
+	test.That(t, err, test.ShouldBeNil)
+	var rx []byte
+	for len(rx) < 4 {
+		data, err := handle.Read(context.Background(), 4-len(rx))
+		test.That(t, err, test.ShouldBeNil)
+		rx = append(rx, data...)
+	}
+	test.That(t, string(rx), test.ShouldEqual, "pong")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	_, err = handle.Read(ctx, 1)
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+
+	test.That(t, handle.Close(), test.ShouldBeNil)
+	err = handle.Write(context.Background(), []byte("x"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "closed")
+
+	// the port is released once the handle is closed
+	handle, err = s.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Close(), test.ShouldBeNil)
+}
+
+func TestRemoteSerial(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	rpcServer, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	fakeBoard := &fakeboard.Board{Serials: map[string]*fakeboard.Serial{
+		"uart1": fakeboard.NewSerial(),
+		"uart2": fakeboard.NewSerial(),
+	}}
+	boardSvc, err := subtype.New(map[resource.Name]interface{}{board.Named(testBoardName): fakeBoard})
+	test.That(t, err, test.ShouldBeNil)
+	resourceSubtype := registry.ResourceSubtypeLookup(board.Subtype)
+	resourceSubtype.RegisterRPCService(context.Background(), rpcServer, boardSvc)
+
+	go rpcServer.Serve(listener)
+	defer rpcServer.Stop()
+
+	conn, err := viamgrpc.Dial(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer utils.UncheckedErrorFunc(conn.Close)
+	client := board.NewClientFromConn(context.Background(), conn, testBoardName, logger)
+
+	serialBoard, ok := client.(board.SerialBoard)
+	test.That(t, ok, test.ShouldBeTrue)
+	names := serialBoard.SerialNames()
+	sort.Strings(names)
+	test.That(t, names, test.ShouldResemble, []string{"uart1", "uart2"})
+
+	s, ok := serialBoard.SerialByName("uart1")
+	test.That(t, ok, test.ShouldBeTrue)
+	handle, err := s.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Write(context.Background(), []byte{0x00, 0xff, 'a'}), test.ShouldBeNil)
+	rx, err := handle.Read(context.Background(), 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rx, test.ShouldResemble, []byte{0x00, 0xff})
+	rx, err = handle.Read(context.Background(), 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rx, test.ShouldResemble, []byte{'a'})
+	test.That(t, handle.Close(), test.ShouldBeNil)
+
+	_, ok = serialBoard.SerialByName("missing")
+	test.That(t, ok, test.ShouldBeFalse)
+
+	_, err = client.DoCommand(context.Background(), map[string]interface{}{
+		"command": board.SerialWriteCommand,
+		"serial":  "missing",
+		"data":    "eA==",
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown serial port")
+
+	// the serial commands are namespaced, so the board's own commands still get through
+	resp, err := client.DoCommand(context.Background(), map[string]interface{}{"command": "serial_names"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["command"], test.ShouldEqual, "serial_names")
+}
+
+func TestReconfigurableSerial(t *testing.T) {
+	fakeBoard := &fakeboard.Board{Serials: map[string]*fakeboard.Serial{"uart": fakeboard.NewSerial()}}
+	reconfBoard, err := board.WrapWithReconfigurable(fakeBoard, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+
+	serialBoard, ok := reconfBoard.(board.SerialBoard)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, serialBoard.SerialNames(), test.ShouldResemble, []string{"uart"})
+	s, ok := serialBoard.SerialByName("uart")
+	test.That(t, ok, test.ShouldBeTrue)
+	_, ok = serialBoard.SerialByName("missing")
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// the serial port keeps working after the board is reconfigured
+	newSerial := fakeboard.NewSerial()
+	newBoard, err := board.WrapWithReconfigurable(
+		&fakeboard.Board{Serials: map[string]*fakeboard.Serial{"uart": newSerial}}, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reconfBoard.Reconfigure(context.Background(), newBoard), test.ShouldBeNil)
+
+	handle, err := s.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Write(context.Background(), []byte("hi")), test.ShouldBeNil)
+	test.That(t, handle.Close(), test.ShouldBeNil)
+
+	newHandle, err := newSerial.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, newHandle.Close(), test.ShouldBeNil)
+	}()
+	rx, err := newHandle.Read(context.Background(), 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rx, test.ShouldResemble, []byte("hi"))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/board/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/subtype"
@@ -209,5 +210,16 @@ func (s *subtypeServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, b, req)
+	res, handled, err := doSerialCommand(ctx, b, req.GetCommand().AsMap())
+	if !handled {
+		return protoutils.DoFromResourceServer(ctx, b, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pbRes, err := structpb.NewStruct(res)
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: pbRes}, nil
 }
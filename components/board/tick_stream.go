@@ -0,0 +1,567 @@
+package board
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"go.viam.com/utils/protoutils"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	rprotoutils "go.viam.com/rdk/protoutils"
+)
+
+const (
+	defaultTickBufferSize      = 1024
+	defaultPinPollInterval     = 10 * time.Millisecond
+	interruptCallbackQueueSize = 64
+	tickStreamFileName         = "component/board/v1/tick_stream.proto"
+	tickStreamMethod           = "/" + tickStreamServiceName + "/StreamTicks"
+	tickStreamServiceName      = "rdk.component.board.v1.TickStreamService"
+	tickStreamRetryInterval    = time.Second
+)
+
+// TickStreamOptions selects what a tick stream carries.
+type TickStreamOptions struct {
+	DigitalInterrupts []string
+	// GPIOPins have their edges reported as they happen by a digital interrupt of the board on
+	// the same pin, or else by the pin if it is an EdgeWatcher. Other pins are sampled every
+	// PinPollInterval instead, 10ms if unset, and edges shorter than that can be missed.
+	GPIOPins        []string
+	PinPollInterval time.Duration
+	// BufferSize is how many events are held for a receiver that has fallen behind, 1024 if
+	// unset. When it fills up, the oldest events are dropped.
+	BufferSize int
+	Extra      map[string]interface{}
+}
+
+// A TickEvent is a tick of a digital interrupt or an edge of a GPIO pin. Exactly one of
+// DigitalInterrupt and GPIOPin is set.
+type TickEvent struct {
+	DigitalInterrupt string
+	GPIOPin          string
+	Tick
+	// Polled is whether the edge of a GPIO pin was found by sampling the pin, in which case it is
+	// timestamped when it was seen rather than by the board.
+	Polled bool
+	// Dropped is how many events were dropped just before this one because the receiver fell
+	// behind.
+	Dropped int
+}
+
+// tickStreamer is implemented by boards that can stream ticks more directly than through
+// their digital interrupts and pins, like remote boards.
+type tickStreamer interface {
+	streamTicks(ctx context.Context, opts TickStreamOptions, send func(TickEvent) error) error
+}
+
+// StreamTicks calls send with every tick of the selected digital interrupts and every edge of
+// the selected GPIO pins of the board, in order, until the context is done or send fails.
+// Ticks and edges are timestamped by the board, except polled edges, which are timestamped when
+// they are seen.
+func StreamTicks(ctx context.Context, b Board, opts TickStreamOptions, send func(TickEvent) error) error {
+	if ts, ok := b.(tickStreamer); ok {
+		return ts.streamTicks(ctx, opts, send)
+	}
+	return streamTicksLocally(ctx, b, opts, send)
+}
+
+func streamTicksLocally(ctx context.Context, b Board, opts TickStreamOptions, send func(TickEvent) error) error {
+	if len(opts.DigitalInterrupts) == 0 && len(opts.GPIOPins) == 0 {
+		return errors.New("no digital interrupts or GPIO pins to stream")
+	}
+	interrupts := make([]DigitalInterrupt, 0, len(opts.DigitalInterrupts))
+	for _, name := range opts.DigitalInterrupts {
+		di, ok := b.DigitalInterruptByName(name)
+		if !ok {
+			return errors.Errorf("unknown digital interrupt: %s", name)
+		}
+		interrupts = append(interrupts, di)
+	}
+	pins := make([]GPIOPin, 0, len(opts.GPIOPins))
+	for _, name := range opts.GPIOPins {
+		pin, err := b.GPIOPinByName(name)
+		if err != nil {
+			return err
+		}
+		pins = append(pins, pin)
+	}
+
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultTickBufferSize
+	}
+	queue := newTickQueue(bufferSize)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	var activeBackgroundWorkers sync.WaitGroup
+	defer func() {
+		cancel()
+		activeBackgroundWorkers.Wait()
+	}()
+
+	// forward pushes the ticks sent to callback until the stream ends. The callback has to be
+	// removed while it is still drained, because ticks block on it.
+	forward := func(callback chan Tick, event TickEvent) {
+		activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			for {
+				select {
+				case <-cancelCtx.Done():
+					return
+				case tick := <-callback:
+					event.Tick = tick
+					queue.push(event)
+				}
+			}
+		}, activeBackgroundWorkers.Done)
+	}
+	for i, di := range interrupts {
+		callback := make(chan Tick, interruptCallbackQueueSize)
+		di.AddCallback(callback)
+		defer di.RemoveCallback(callback)
+		forward(callback, TickEvent{DigitalInterrupt: opts.DigitalInterrupts[i]})
+	}
+
+	var polled []GPIOPin
+	var polledNames []string
+	for i, pin := range pins {
+		if di, ok := interruptOnPin(b, opts.GPIOPins[i]); ok {
+			callback := make(chan Tick, interruptCallbackQueueSize)
+			di.AddCallback(callback)
+			defer di.RemoveCallback(callback)
+			forward(callback, TickEvent{GPIOPin: opts.GPIOPins[i]})
+			continue
+		}
+		if watcher, ok := pin.(EdgeWatcher); ok {
+			callback := make(chan Tick, interruptCallbackQueueSize)
+			if stop, err := watcher.WatchEdges(callback); err == nil {
+				defer stop()
+				forward(callback, TickEvent{GPIOPin: opts.GPIOPins[i]})
+				continue
+			}
+		}
+		polled = append(polled, pin)
+		polledNames = append(polledNames, opts.GPIOPins[i])
+	}
+	if len(polled) > 0 {
+		interval := opts.PinPollInterval
+		if interval <= 0 {
+			interval = defaultPinPollInterval
+		}
+		activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			levels := make([]bool, len(polled))
+			for i, pin := range polled {
+				high, err := pin.Get(cancelCtx, opts.Extra)
+				if err != nil {
+					queue.fail(errors.Wrapf(err, "failed to read GPIO pin %s", polledNames[i]))
+					return
+				}
+				levels[i] = high
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-cancelCtx.Done():
+					return
+				case <-ticker.C:
+				}
+				for i, pin := range polled {
+					high, err := pin.Get(cancelCtx, opts.Extra)
+					if err != nil {
+						if cancelCtx.Err() == nil {
+							queue.fail(errors.Wrapf(err, "failed to read GPIO pin %s", polledNames[i]))
+						}
+						return
+					}
+					if high != levels[i] {
+						levels[i] = high
+						queue.push(TickEvent{
+							GPIOPin: polledNames[i],
+							Tick:    Tick{High: high, TimestampNanosec: uint64(time.Now().UnixNano())},
+							Polled:  true,
+						})
+					}
+				}
+			}
+		}, activeBackgroundWorkers.Done)
+	}
+
+	for {
+		event, err := queue.pop(ctx)
+		if err != nil {
+			return err
+		}
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+}
+
+// interruptOnPin returns the board's digital interrupt on the named pin, if it has one that can
+// have callbacks.
+func interruptOnPin(b Board, pin string) (DigitalInterrupt, bool) {
+	for _, name := range b.DigitalInterruptNames() {
+		di, ok := b.DigitalInterruptByName(name)
+		if basic, isBasic := di.(*BasicDigitalInterrupt); ok && isBasic && basic.cfg.Pin == pin {
+			return basic, true
+		}
+	}
+	return nil, false
+}
+
+// tickQueue is a bounded queue of events that drops the oldest event when it is full.
+type tickQueue struct {
+	mu     sync.Mutex
+	events []TickEvent
+	size   int
+	err    error
+	ready  chan struct{}
+}
+
+func newTickQueue(size int) *tickQueue {
+	return &tickQueue{size: size, ready: make(chan struct{}, 1)}
+}
+
+func (q *tickQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *tickQueue) push(event TickEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == q.size {
+		dropped := q.events[0].Dropped + 1
+		q.events = q.events[1:]
+		if len(q.events) > 0 {
+			q.events[0].Dropped += dropped
+		} else {
+			event.Dropped += dropped
+		}
+	}
+	q.events = append(q.events, event)
+	q.signal()
+}
+
+// fail makes pop return err once the events before it are taken.
+func (q *tickQueue) fail(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err == nil {
+		q.err = err
+	}
+	q.signal()
+}
+
+func (q *tickQueue) pop(ctx context.Context) (TickEvent, error) {
+	for {
+		q.mu.Lock()
+		if len(q.events) > 0 {
+			event := q.events[0]
+			q.events = q.events[1:]
+			if len(q.events) > 0 {
+				q.signal()
+			}
+			q.mu.Unlock()
+			return event, nil
+		}
+		err := q.err
+		q.mu.Unlock()
+		if err != nil {
+			return TickEvent{}, err
+		}
+		select {
+		case <-ctx.Done():
+			return TickEvent{}, ctx.Err()
+		case <-q.ready:
+		}
+	}
+}
+
+// The board API has no streaming methods, so ticks are served by a service of their own whose
+// messages are structs.
+type tickStreamServer interface {
+	StreamTicks(req *structpb.Struct, stream grpc.ServerStream) error
+}
+
+// TickStreamServiceDesc describes the service that streams ticks and edges of a board. It is
+// registered alongside the board service.
+var TickStreamServiceDesc = grpc.ServiceDesc{
+	ServiceName: tickStreamServiceName,
+	HandlerType: (*tickStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "StreamTicks",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := &structpb.Struct{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(tickStreamServer).StreamTicks(req, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: tickStreamFileName,
+}
+
+// describeTickStreamService registers the descriptor of the tick stream service, which has to be
+// done before TickStreamServiceDesc is registered with a server.
+func describeTickStreamService() error {
+	return rprotoutils.RegisterServiceDescriptor(tickStreamFileName, tickStreamServiceName, []rprotoutils.MethodDescription{
+		{Name: "StreamTicks", ServerStreams: true},
+	})
+}
+
+func tickStreamRequest(name string, opts TickStreamOptions) (*structpb.Struct, error) {
+	req := map[string]interface{}{
+		"name":               name,
+		"digital_interrupts": opts.DigitalInterrupts,
+		"gpio_pins":          opts.GPIOPins,
+		"pin_poll_interval":  opts.PinPollInterval.String(),
+		"buffer_size":        opts.BufferSize,
+	}
+	if opts.Extra != nil {
+		req["extra"] = opts.Extra
+	}
+	return protoutils.StructToStructPb(req)
+}
+
+func tickStreamOptionsFromRequest(req *structpb.Struct) (string, TickStreamOptions, error) {
+	fields := req.AsMap()
+	name, _ := fields["name"].(string)
+	if name == "" {
+		return "", TickStreamOptions{}, errors.New("tick stream request is missing the board name")
+	}
+	var opts TickStreamOptions
+	var err error
+	if opts.DigitalInterrupts, err = stringList(fields["digital_interrupts"]); err != nil {
+		return "", TickStreamOptions{}, errors.Wrap(err, "digital_interrupts")
+	}
+	if opts.GPIOPins, err = stringList(fields["gpio_pins"]); err != nil {
+		return "", TickStreamOptions{}, errors.Wrap(err, "gpio_pins")
+	}
+	if interval, ok := fields["pin_poll_interval"].(string); ok && interval != "" {
+		if opts.PinPollInterval, err = time.ParseDuration(interval); err != nil {
+			return "", TickStreamOptions{}, errors.Wrap(err, "pin_poll_interval")
+		}
+	}
+	if size, ok := fields["buffer_size"].(float64); ok {
+		opts.BufferSize = int(size)
+	}
+	opts.Extra, _ = fields["extra"].(map[string]interface{})
+	return name, opts, nil
+}
+
+func stringList(v interface{}) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected a list but got %T", v)
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, errors.Errorf("expected a string but got %T", item)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func tickEventToProto(event TickEvent) (*structpb.Struct, error) {
+	fields := map[string]interface{}{
+		"high": event.High,
+		// nanoseconds don't fit in the float64 of a struct number
+		"timestamp_ns": strconv.FormatUint(event.TimestampNanosec, 10),
+	}
+	if event.DigitalInterrupt != "" {
+		fields["digital_interrupt"] = event.DigitalInterrupt
+	} else {
+		fields["gpio_pin"] = event.GPIOPin
+	}
+	if event.Polled {
+		fields["polled"] = true
+	}
+	if event.Dropped > 0 {
+		fields["dropped"] = event.Dropped
+	}
+	return protoutils.StructToStructPb(fields)
+}
+
+func tickEventFromProto(msg *structpb.Struct) (TickEvent, error) {
+	fields := msg.AsMap()
+	var event TickEvent
+	event.DigitalInterrupt, _ = fields["digital_interrupt"].(string)
+	event.GPIOPin, _ = fields["gpio_pin"].(string)
+	event.High, _ = fields["high"].(bool)
+	event.Polled, _ = fields["polled"].(bool)
+	if dropped, ok := fields["dropped"].(float64); ok {
+		event.Dropped = int(dropped)
+	}
+	ts, _ := fields["timestamp_ns"].(string)
+	var err error
+	if event.TimestampNanosec, err = strconv.ParseUint(ts, 10, 64); err != nil {
+		return TickEvent{}, errors.Wrap(err, "malformed tick timestamp")
+	}
+	return event, nil
+}
+
+// StreamTicks streams ticks of the board named in the request until the client goes away.
+func (s *subtypeServer) StreamTicks(req *structpb.Struct, stream grpc.ServerStream) error {
+	name, opts, err := tickStreamOptionsFromRequest(req)
+	if err != nil {
+		return err
+	}
+	b, err := s.getBoard(name)
+	if err != nil {
+		return err
+	}
+	return StreamTicks(stream.Context(), b, opts, func(event TickEvent) error {
+		msg, err := tickEventToProto(event)
+		if err != nil {
+			return err
+		}
+		return stream.SendMsg(msg)
+	})
+}
+
+func (c *client) streamTicks(ctx context.Context, opts TickStreamOptions, send func(TickEvent) error) error {
+	req, err := tickStreamRequest(c.info.name, opts)
+	if err != nil {
+		return err
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.conn.NewStream(cancelCtx, &TickStreamServiceDesc.Streams[0], tickStreamMethod)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		msg := &structpb.Struct{}
+		if err := stream.RecvMsg(msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		event, err := tickEventFromProto(msg)
+		if err != nil {
+			return err
+		}
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+}
+
+// interruptStream feeds the callbacks of one remote digital interrupt from a tick stream.
+type interruptStream struct {
+	mu        sync.Mutex
+	callbacks []*interruptCallback
+	cancel    func()
+}
+
+// interruptCallback is a callback of a remote digital interrupt. done is closed when it is
+// removed, so that a tick blocked on a callback that is no longer read is given up.
+type interruptCallback struct {
+	ch   chan Tick
+	done chan struct{}
+}
+
+func (c *client) addInterruptCallback(name string, callback chan Tick) {
+	c.interruptStreamsMu.Lock()
+	defer c.interruptStreamsMu.Unlock()
+	cb := &interruptCallback{ch: callback, done: make(chan struct{})}
+	if is, ok := c.interruptStreams[name]; ok {
+		is.mu.Lock()
+		is.callbacks = append(is.callbacks, cb)
+		is.mu.Unlock()
+		return
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	is := &interruptStream{callbacks: []*interruptCallback{cb}, cancel: cancel}
+	c.interruptStreams[name] = is
+	c.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		opts := TickStreamOptions{DigitalInterrupts: []string{name}}
+		for {
+			err := c.streamTicks(cancelCtx, opts, func(event TickEvent) error {
+				// ticks are sent without holding the lock, so that a callback can be removed while
+				// a tick is blocked on it
+				is.mu.Lock()
+				callbacks := append([]*interruptCallback(nil), is.callbacks...)
+				is.mu.Unlock()
+				for _, cb := range callbacks {
+					select {
+					case <-cancelCtx.Done():
+						return cancelCtx.Err()
+					case <-cb.done:
+					case cb.ch <- event.Tick:
+					}
+				}
+				return nil
+			})
+			if cancelCtx.Err() != nil {
+				return
+			}
+			c.logger.Errorw("digital interrupt tick stream failed, retrying", "interrupt", name, "error", err)
+			if !utils.SelectContextOrWait(cancelCtx, tickStreamRetryInterval) {
+				return
+			}
+		}
+	}, c.activeBackgroundWorkers.Done)
+}
+
+func (c *client) removeInterruptCallback(name string, callback chan Tick) {
+	c.interruptStreamsMu.Lock()
+	defer c.interruptStreamsMu.Unlock()
+	is, ok := c.interruptStreams[name]
+	if !ok {
+		return
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	for i, cb := range is.callbacks {
+		if cb.ch == callback {
+			close(cb.done)
+			is.callbacks = append(is.callbacks[:i], is.callbacks[i+1:]...)
+			break
+		}
+	}
+	if len(is.callbacks) == 0 {
+		is.cancel()
+		delete(c.interruptStreams, name)
+	}
+}
+
+// Close stops streaming ticks to any digital interrupt callbacks.
+func (c *client) Close(ctx context.Context) error {
+	c.interruptStreamsMu.Lock()
+	for name, is := range c.interruptStreams {
+		is.cancel()
+		delete(c.interruptStreams, name)
+	}
+	c.interruptStreamsMu.Unlock()
+	c.activeBackgroundWorkers.Wait()
+	return nil
+}
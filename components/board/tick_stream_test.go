@@ -0,0 +1,234 @@
+package board
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+)
+
+func TestTickQueue(t *testing.T) {
+	ctx := context.Background()
+	q := newTickQueue(2)
+	for i := uint64(1); i <= 5; i++ {
+		q.push(TickEvent{DigitalInterrupt: "a", Tick: Tick{High: true, TimestampNanosec: i}})
+	}
+
+	event, err := q.pop(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, event.TimestampNanosec, test.ShouldEqual, 4)
+	test.That(t, event.Dropped, test.ShouldEqual, 3)
+
+	event, err = q.pop(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, event.TimestampNanosec, test.ShouldEqual, 5)
+	test.That(t, event.Dropped, test.ShouldEqual, 0)
+
+	q = newTickQueue(1)
+	q.push(TickEvent{Tick: Tick{TimestampNanosec: 1}})
+	q.push(TickEvent{Tick: Tick{TimestampNanosec: 2}})
+	event, err = q.pop(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, event.TimestampNanosec, test.ShouldEqual, 2)
+	test.That(t, event.Dropped, test.ShouldEqual, 1)
+
+	q.push(TickEvent{Tick: Tick{TimestampNanosec: 3}})
+	q.fail(errors.New("whoops"))
+	event, err = q.pop(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, event.TimestampNanosec, test.ShouldEqual, 3)
+	_, err = q.pop(ctx)
+	test.That(t, err, test.ShouldBeError, errors.New("whoops"))
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = newTickQueue(1).pop(cancelCtx)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+}
+
+type tickStreamTestPin struct {
+	GPIOPin
+	mu   sync.Mutex
+	high bool
+	gets int
+}
+
+func (p *tickStreamTestPin) set(high bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.high = high
+}
+
+func (p *tickStreamTestPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gets++
+	return p.high, nil
+}
+
+// tickStreamTestWatcher is a pin whose edges are watched, unless watching fails.
+type tickStreamTestWatcher struct {
+	tickStreamTestPin
+	fail    bool
+	watched chan chan Tick
+	stopped chan struct{}
+}
+
+func (p *tickStreamTestWatcher) WatchEdges(ticks chan Tick) (func(), error) {
+	if p.fail {
+		return nil, errors.New("busy")
+	}
+	p.watched <- ticks
+	return func() { close(p.stopped) }, nil
+}
+
+type tickStreamTestBoard struct {
+	Board
+	interrupt DigitalInterrupt
+	pins      map[string]GPIOPin
+}
+
+func (b *tickStreamTestBoard) DigitalInterruptNames() []string {
+	return []string{"di"}
+}
+
+func (b *tickStreamTestBoard) DigitalInterruptByName(name string) (DigitalInterrupt, bool) {
+	return b.interrupt, name == "di"
+}
+
+func (b *tickStreamTestBoard) GPIOPinByName(name string) (GPIOPin, error) {
+	pin, ok := b.pins[name]
+	if !ok {
+		return nil, errors.Errorf("unknown GPIO pin: %s", name)
+	}
+	return pin, nil
+}
+
+func TestStreamTicksLocally(t *testing.T) {
+	interrupt, err := CreateDigitalInterrupt(DigitalInterruptConfig{Name: "di", Pin: "1"})
+	test.That(t, err, test.ShouldBeNil)
+	pin := &tickStreamTestPin{}
+	b := &tickStreamTestBoard{interrupt: interrupt, pins: map[string]GPIOPin{"pin": pin}}
+
+	err = StreamTicks(context.Background(), b, TickStreamOptions{}, nil)
+	test.That(t, err, test.ShouldBeError, errors.New("no digital interrupts or GPIO pins to stream"))
+	err = StreamTicks(context.Background(), b, TickStreamOptions{DigitalInterrupts: []string{"nope"}}, nil)
+	test.That(t, err, test.ShouldBeError, errors.New("unknown digital interrupt: nope"))
+	err = StreamTicks(context.Background(), b, TickStreamOptions{GPIOPins: []string{"nope"}}, nil)
+	test.That(t, err, test.ShouldBeError, errors.New("unknown GPIO pin: nope"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan TickEvent)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- StreamTicks(ctx, b, TickStreamOptions{
+			DigitalInterrupts: []string{"di"},
+			GPIOPins:          []string{"pin"},
+		}, func(event TickEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// ticks are only delivered once the callback is added
+	// and edges once the first level is read
+	basic := interrupt.(*BasicDigitalInterrupt)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		basic.mu.RLock()
+		defer basic.mu.RUnlock()
+		test.That(tb, basic.callbacks, test.ShouldHaveLength, 1)
+		pin.mu.Lock()
+		defer pin.mu.Unlock()
+		test.That(tb, pin.gets, test.ShouldBeGreaterThan, 0)
+	})
+	test.That(t, interrupt.Tick(ctx, true, 7), test.ShouldBeNil)
+	event := <-events
+	test.That(t, event.DigitalInterrupt, test.ShouldEqual, "di")
+	test.That(t, event.Tick, test.ShouldResemble, Tick{High: true, TimestampNanosec: 7})
+
+	pin.set(true)
+	event = <-events
+	test.That(t, event.GPIOPin, test.ShouldEqual, "pin")
+	test.That(t, event.High, test.ShouldBeTrue)
+	test.That(t, event.Polled, test.ShouldBeTrue)
+	test.That(t, event.TimestampNanosec, test.ShouldBeGreaterThan, 0)
+	pin.set(false)
+	event = <-events
+	test.That(t, event.GPIOPin, test.ShouldEqual, "pin")
+	test.That(t, event.High, test.ShouldBeFalse)
+
+	cancel()
+	test.That(t, <-errs, test.ShouldBeError, context.Canceled)
+	// the callback is gone, so ticks no longer block
+	test.That(t, basic.callbacks, test.ShouldBeEmpty)
+	test.That(t, interrupt.Tick(context.Background(), true, 8), test.ShouldBeNil)
+}
+
+func TestStreamTicksWithoutPolling(t *testing.T) {
+	interrupt, err := CreateDigitalInterrupt(DigitalInterruptConfig{Name: "di", Pin: "1"})
+	test.That(t, err, test.ShouldBeNil)
+	watcher := &tickStreamTestWatcher{watched: make(chan chan Tick, 1), stopped: make(chan struct{})}
+	busy := &tickStreamTestWatcher{fail: true}
+	b := &tickStreamTestBoard{interrupt: interrupt, pins: map[string]GPIOPin{
+		"1":       &tickStreamTestPin{},
+		"watcher": watcher,
+		"busy":    busy,
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan TickEvent)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- StreamTicks(ctx, b, TickStreamOptions{GPIOPins: []string{"1", "watcher", "busy"}}, func(event TickEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// the pin with a digital interrupt on it gets its edges from the interrupt
+	basic := interrupt.(*BasicDigitalInterrupt)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		basic.mu.RLock()
+		defer basic.mu.RUnlock()
+		test.That(tb, basic.callbacks, test.ShouldHaveLength, 1)
+	})
+	test.That(t, interrupt.Tick(ctx, true, 7), test.ShouldBeNil)
+	test.That(t, <-events, test.ShouldResemble, TickEvent{GPIOPin: "1", Tick: Tick{High: true, TimestampNanosec: 7}})
+
+	// the pin that can watch its edges reports them
+	(<-watcher.watched) <- Tick{High: true, TimestampNanosec: 8}
+	test.That(t, <-events, test.ShouldResemble, TickEvent{GPIOPin: "watcher", Tick: Tick{High: true, TimestampNanosec: 8}})
+
+	// and the pin that can't is polled
+	busy.set(true)
+	event := <-events
+	test.That(t, event.GPIOPin, test.ShouldEqual, "busy")
+	test.That(t, event.Polled, test.ShouldBeTrue)
+
+	cancel()
+	test.That(t, <-errs, test.ShouldBeError, context.Canceled)
+	<-watcher.stopped
+	test.That(t, basic.callbacks, test.ShouldBeEmpty)
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	test.That(t, watcher.gets, test.ShouldEqual, 0)
+}
+
+func TestDescribeTickStreamService(t *testing.T) {
+	test.That(t, describeTickStreamService(), test.ShouldBeNil)
+	test.That(t, describeTickStreamService(), test.ShouldBeNil)
+
+	desc, err := grpcreflect.LoadServiceDescriptor(&TickStreamServiceDesc)
+	test.That(t, err, test.ShouldBeNil)
+	method := desc.FindMethodByName("StreamTicks")
+	test.That(t, method, test.ShouldNotBeNil)
+	test.That(t, method.IsServerStreaming(), test.ShouldBeTrue)
+}
@@ -268,6 +268,9 @@ func (vs *videoSource) DoCommand(ctx context.Context, cmd map[string]interface{}
 	if doer, ok := vs.videoSource.(generic.Generic); ok {
 		return doer.DoCommand(ctx, cmd)
 	}
+	if doer, ok := vs.actualSource.(generic.Generic); ok {
+		return doer.DoCommand(ctx, cmd)
+	}
 	return nil, generic.ErrUnimplemented
 }
 
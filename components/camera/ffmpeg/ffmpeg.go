@@ -12,6 +12,7 @@ import (
 
 	"github.com/edaniels/golog"
 	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapio"
@@ -21,6 +22,7 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/h264"
 	"go.viam.com/rdk/rimage/transform"
 	"go.viam.com/rdk/utils"
 )
@@ -34,6 +36,10 @@ type AttrConfig struct {
 	InputKWArgs          map[string]interface{}             `json:"input_kw_args,omitempty"`
 	Filters              []FilterAttrs                      `json:"filters,omitempty"`
 	OutputKWArgs         map[string]interface{}             `json:"output_kw_args,omitempty"`
+	// H264Passthrough has ffmpeg output H.264, copied from the video unless a vcodec is given in the
+	// output kw args, which is streamed without being re-encoded and only decoded when needed. The
+	// video should not have B-frames.
+	H264Passthrough bool `json:"h264_passthrough,omitempty"`
 }
 
 // FilterAttrs is a struct to used to configure ffmpeg filters.
@@ -94,8 +100,18 @@ func NewFFMPEGCamera(ctx context.Context, attrs *AttrConfig, logger golog.Logger
 	for key, value := range attrs.OutputKWArgs {
 		outArgs[key] = value
 	}
-	outArgs["update"] = 1        // always interpret the filename as just a filename, not a pattern
-	outArgs["format"] = "image2" // select image file muxer, used to write video frames to image files
+	if attrs.H264Passthrough {
+		if _, ok := outArgs["vcodec"]; !ok {
+			if len(attrs.Filters) != 0 {
+				return nil, errors.New("filters need a vcodec to be given in output_kw_args when h264_passthrough is set")
+			}
+			outArgs["vcodec"] = "copy"
+		}
+		outArgs["format"] = "h264" // select raw H.264 muxer, which writes an Annex-B byte stream
+	} else {
+		outArgs["update"] = 1        // always interpret the filename as just a filename, not a pattern
+		outArgs["format"] = "image2" // select image file muxer, used to write video frames to image files
+	}
 
 	// instantiate camera with cancellable context that will be applied to all spawned processes
 	cancelableCtx, cancel := context.WithCancel(context.Background())
@@ -140,13 +156,34 @@ func NewFFMPEGCamera(ctx context.Context, attrs *AttrConfig, logger golog.Logger
 	var latestFrame atomic.Value
 	var gotFirstFrameOnce bool
 	ffCam.activeBackgroundWorkers.Add(1)
+	readFrame := func() (image.Image, error) {
+		return jpeg.Decode(in)
+	}
+	closeFrames := func() error { return nil }
+	if attrs.H264Passthrough {
+		accessUnits := h264.NewAccessUnitReader(in)
+		var seq h264.Sequence
+		closeFrames = seq.Close
+		readFrame = func() (image.Image, error) {
+			au, err := accessUnits.Next()
+			if err != nil {
+				return nil, err
+			}
+			frame, err := seq.Add(au)
+			if err != nil || frame == nil {
+				return nil, err
+			}
+			return frame, nil
+		}
+	}
 	viamutils.ManagedGo(func() {
+		defer viamutils.UncheckedErrorFunc(closeFrames)
 		for {
 			if cancelableCtx.Err() != nil {
 				return
 			}
-			img, err := jpeg.Decode(in)
-			if err != nil {
+			img, err := readFrame()
+			if err != nil || img == nil {
 				continue
 			}
 			latestFrame.Store(img)
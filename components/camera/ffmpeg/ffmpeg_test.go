@@ -9,6 +9,8 @@ import (
 	"go.viam.com/test"
 	viamutils "go.viam.com/utils"
 	"go.viam.com/utils/artifact"
+
+	"go.viam.com/rdk/rimage/h264"
 )
 
 func TestFFMPEGCamera(t *testing.T) {
@@ -27,6 +29,31 @@ func TestFFMPEGCamera(t *testing.T) {
 	test.That(t, viamutils.TryClose(context.Background(), cam), test.ShouldBeNil)
 }
 
+func TestFFMPEGCameraH264Passthrough(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	ctx := context.Background()
+	path := artifact.MustPath("components/camera/ffmpeg/testsrc.mpg")
+	cam, err := NewFFMPEGCamera(ctx, &AttrConfig{
+		VideoPath:       path,
+		OutputKWArgs:    map[string]interface{}{"vcodec": "libx264", "bf": 0},
+		H264Passthrough: true,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	stream, err := cam.Stream(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 5; i++ {
+		img, _, err := stream.Next(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		frame, ok := img.(*h264.Frame)
+		test.That(t, ok, test.ShouldBeTrue)
+		decoded, err := frame.Decode()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decoded.Bounds(), test.ShouldResemble, frame.Bounds())
+	}
+	test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, viamutils.TryClose(context.Background(), cam), test.ShouldBeNil)
+}
+
 func TestFFMPEGNotFound(t *testing.T) {
 	oldpath := os.Getenv("PATH")
 	defer func() {
@@ -7,7 +7,9 @@ import (
 	_ "go.viam.com/rdk/components/camera/fake"
 	_ "go.viam.com/rdk/components/camera/ffmpeg"
 	_ "go.viam.com/rdk/components/camera/rtsp"
+	_ "go.viam.com/rdk/components/camera/stereo"
 	_ "go.viam.com/rdk/components/camera/transformpipeline"
 	_ "go.viam.com/rdk/components/camera/velodyne"
+	_ "go.viam.com/rdk/components/camera/videorecorder"
 	_ "go.viam.com/rdk/components/camera/videosource"
 )
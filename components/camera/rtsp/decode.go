@@ -6,8 +6,11 @@ import (
 	"image/jpeg"
 
 	"github.com/aler9/gortsplib/v2/pkg/format"
+	"github.com/aler9/gortsplib/v2/pkg/formatdecenc/rtph264"
 	"github.com/pion/rtp"
 	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage/h264"
 )
 
 type decoder func(pkt *rtp.Packet) (image.Image, error)
@@ -25,3 +28,33 @@ func mjpegDecoding() (*format.MJPEG, decoder) {
 	}
 	return &mjpeg, mjpegDecoder
 }
+
+// h264Decoding returns a decoder of the H.264 track's packets into frames that are kept encoded,
+// so that they can be streamed as they are, and the sequence of the frames, which must be closed to
+// stop decoding their pixels. It returns no image until a whole IDR frame arrives.
+func h264Decoding(h264Format *format.H264) (decoder, *h264.Sequence, error) {
+	// get the RTP->H264 decoder
+	rtpDec := h264Format.CreateDecoder()
+	seq := &h264.Sequence{}
+	// the parameter sets may only be given in the SDP
+	if sps, pps := h264Format.SafeSPS(), h264Format.SafePPS(); sps != nil && pps != nil {
+		if _, err := seq.Add([][]byte{sps, pps}); err != nil {
+			return nil, nil, err
+		}
+	}
+	h264Decoder := func(pkt *rtp.Packet) (image.Image, error) {
+		au, _, err := rtpDec.DecodeUntilMarker(pkt)
+		if err != nil {
+			if errors.Is(err, rtph264.ErrMorePacketsNeeded) {
+				return nil, nil
+			}
+			return nil, errors.Wrap(err, "rtp to h264 decoding failed")
+		}
+		frame, err := seq.Add(au)
+		if err != nil || frame == nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+	return h264Decoder, seq, nil
+}
@@ -0,0 +1,60 @@
+package rtsp
+
+import (
+	"image"
+	"testing"
+
+	"github.com/aler9/gortsplib/v2/pkg/format"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/h264"
+)
+
+func TestH264Decoding(t *testing.T) {
+	// a 352x288 SPS
+	sps := []byte{
+		0x67, 0x64, 0x00, 0x0c, 0xac, 0x3b, 0x50, 0xb0, 0x4b, 0x42, 0x00, 0x00,
+		0x03, 0x00, 0x02, 0x00, 0x00, 0x03, 0x00, 0x3d, 0x08,
+	}
+	pps := []byte{0x68, 0xee, 0x3c, 0x80}
+	// big enough to be fragmented over several packets
+	idr := make([]byte, 4000)
+	idr[0] = 0x65
+	for i := 1; i < len(idr); i++ {
+		idr[i] = 0x88
+	}
+	nonIDR := []byte{0x41, 0x9a, 0x80}
+
+	h264Format := &format.H264{PayloadTyp: 96, SPS: sps, PPS: pps, PacketizationMode: 1}
+	decode, seq, err := h264Decoding(h264Format)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, seq.Close(), test.ShouldBeNil)
+	}()
+	rtpEnc := h264Format.CreateEncoder()
+
+	// the parameter sets come from the format
+	var frames []image.Image
+	for _, au := range [][][]byte{{nonIDR}, {idr}, {nonIDR}} {
+		pkts, err := rtpEnc.Encode(au, 0)
+		test.That(t, err, test.ShouldBeNil)
+		for i, pkt := range pkts {
+			img, err := decode(pkt)
+			test.That(t, err, test.ShouldBeNil)
+			if i != len(pkts)-1 {
+				test.That(t, img, test.ShouldBeNil)
+				continue
+			}
+			if img != nil {
+				frames = append(frames, img)
+			}
+		}
+	}
+	test.That(t, frames, test.ShouldHaveLength, 2)
+	frame, ok := frames[1].(*h264.Frame)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, frame.Bounds(), test.ShouldResemble, image.Rect(0, 0, 352, 288))
+	data, err := frame.AnnexBSince(frames[0].(*h264.Frame))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, append([]byte{0x00, 0x00, 0x00, 0x01}, nonIDR...))
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"image"
 	"io"
+	"os/exec"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/aler9/gortsplib/v2"
 	"github.com/aler9/gortsplib/v2/pkg/base"
+	"github.com/aler9/gortsplib/v2/pkg/format"
 	"github.com/aler9/gortsplib/v2/pkg/liberrors"
 	"github.com/aler9/gortsplib/v2/pkg/url"
 	"github.com/edaniels/golog"
@@ -25,6 +27,7 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/h264"
 	"go.viam.com/rdk/rimage/transform"
 	"go.viam.com/rdk/utils"
 )
@@ -94,7 +97,9 @@ type rtspCamera struct {
 	gotFirstFrameOnce       bool
 	gotFirstFrame           chan struct{}
 	latestFrame             atomic.Value
-	logger                  golog.Logger
+	// seq is the sequence of the H.264 frames of the current client, if its track is H.264.
+	seq    *h264.Sequence
+	logger golog.Logger
 }
 
 // Close closes the camera. It always returns nil, but because of Close() interface, it needs to return an error.
@@ -104,9 +109,21 @@ func (rc *rtspCamera) Close(ctx context.Context) error {
 	if err := rc.client.Close(); err != nil && !errors.Is(err, liberrors.ErrClientTerminated{}) {
 		rc.logger.Infow("error while closing rtsp client:", "error", err)
 	}
+	rc.closeSequence()
 	return nil
 }
 
+// closeSequence stops decoding the frames of the last client, if they are H.264.
+func (rc *rtspCamera) closeSequence() {
+	if rc.seq == nil {
+		return
+	}
+	if err := rc.seq.Close(); err != nil {
+		rc.logger.Debugw("error while closing h264 decoder", "error", err)
+	}
+	rc.seq = nil
+}
+
 // clientReconnectBackgroundWorker checks every 5 sec to see if the client is connected to the server, and reconnects if not.
 func (rc *rtspCamera) clientReconnectBackgroundWorker() {
 	rc.activeBackgroundWorkers.Add(1)
@@ -151,6 +168,7 @@ func (rc *rtspCamera) reconnectClient() (err error) {
 			rc.logger.Debugw("error while closing rtsp client:", "error", err)
 		}
 	}
+	rc.closeSequence()
 	// replace the client with a new one, but close it if setup is not successful
 	client := &gortsplib.Client{}
 	rc.client = client
@@ -166,22 +184,40 @@ func (rc *rtspCamera) reconnectClient() (err error) {
 	if err != nil {
 		return err
 	}
-	mjpegFormat, mjpegDecoder := mjpegDecoding()
 	tracks, baseURL, _, err := rc.client.Describe(rc.u)
 	if err != nil {
 		return err
 	}
-	track := tracks.FindFormat(&mjpegFormat)
-	if track == nil {
-		return errors.New("MJPEG track not found")
+	// prefer H.264, which is kept encoded, over MJPEG
+	var forma format.Format
+	var decode decoder
+	var h264Format *format.H264
+	track := tracks.FindFormat(&h264Format)
+	if track != nil {
+		forma = h264Format
+		decode, rc.seq, err = h264Decoding(h264Format)
+		if err != nil {
+			return err
+		}
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			rc.logger.Warnw("ffmpeg is not installed, so the camera's h264 frames can be streamed but not decoded", "url", rc.u)
+		}
+	} else {
+		var mjpegFormat *format.MJPEG
+		mjpegFormat, decode = mjpegDecoding()
+		track = tracks.FindFormat(&mjpegFormat)
+		if track == nil {
+			return errors.New("H264 or MJPEG track not found")
+		}
+		forma = mjpegFormat
 	}
 	_, err = rc.client.Setup(track, baseURL, 0, 0)
 	if err != nil {
 		return err
 	}
 	// On packet retreival, turn it into an image, and store it in shared memory
-	rc.client.OnPacketRTP(track, mjpegFormat, func(pkt *rtp.Packet) {
-		img, err := mjpegDecoder(pkt)
+	rc.client.OnPacketRTP(track, forma, func(pkt *rtp.Packet) {
+		img, err := decode(pkt)
 		if err != nil {
 			return
 		}
@@ -203,7 +239,8 @@ func (rc *rtspCamera) reconnectClient() (err error) {
 }
 
 // NewRTSPCamera creates a camera client using RTSP given the server URL.
-// Right now, only supports servers that have MJPEG video tracks.
+// Right now, only supports servers that have H264 or MJPEG video tracks. H264 frames are only
+// decoded when their pixels are needed, and are otherwise streamed as they are.
 func NewRTSPCamera(ctx context.Context, attrs *Attrs, logger golog.Logger) (camera.Camera, error) {
 	u, err := url.Parse(attrs.Address)
 	if err != nil {
@@ -0,0 +1,248 @@
+// Package stereo defines a depth camera made from a calibrated pair of color cameras.
+package stereo
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("stereo_depth")
+
+const (
+	defaultMaxDisparity    = 64
+	defaultBlockSize       = 5
+	defaultUniquenessRatio = 0.05
+)
+
+func init() {
+	registry.RegisterComponent(camera.Subtype, model,
+		registry.Component{Constructor: func(ctx context.Context, deps registry.Dependencies,
+			config config.Component, logger golog.Logger,
+		) (interface{}, error) {
+			attrs, ok := config.ConvertedAttributes.(*stereoAttrs)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attrs, config.ConvertedAttributes)
+			}
+			left, err := camera.FromDependencies(deps, attrs.Left)
+			if err != nil {
+				return nil, fmt.Errorf("no left camera (%s): %w", attrs.Left, err)
+			}
+			right, err := camera.FromDependencies(deps, attrs.Right)
+			if err != nil {
+				return nil, fmt.Errorf("no right camera (%s): %w", attrs.Right, err)
+			}
+			return newStereoDepth(ctx, left, right, attrs, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(camera.Subtype, model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf stereoAttrs
+			attrs, err := config.TransformAttributeMapToStruct(&conf, attributes)
+			if err != nil {
+				return nil, err
+			}
+			result, ok := attrs.(*stereoAttrs)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(result, attrs)
+			}
+			return result, nil
+		}, &stereoAttrs{})
+}
+
+// stereoAttrs is the attribute struct for stereo depth cameras.
+type stereoAttrs struct {
+	Left        string                       `json:"left_camera_name"`
+	Right       string                       `json:"right_camera_name"`
+	Calibration *transform.StereoCalibration `json:"stereo_parameters"`
+	// ImageType is depth by default, or color for the rectified left image.
+	ImageType       string  `json:"output_image_type,omitempty"`
+	Method          string  `json:"matcher,omitempty"`
+	MaxDisparity    int     `json:"max_disparity,omitempty"`
+	BlockSize       int     `json:"block_size,omitempty"`
+	UniquenessRatio float64 `json:"uniqueness_ratio,omitempty"`
+	Debug           bool    `json:"debug,omitempty"`
+}
+
+func (cfg *stereoAttrs) Validate(path string) ([]string, error) {
+	if cfg.Left == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "left_camera_name")
+	}
+	if cfg.Right == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "right_camera_name")
+	}
+	if cfg.Calibration == nil {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "stereo_parameters")
+	}
+	if err := cfg.Calibration.CheckValid(); err != nil {
+		return nil, utils.NewConfigValidationError(path, err)
+	}
+	switch camera.ImageType(cfg.ImageType) {
+	case camera.UnspecifiedStream, camera.DepthStream, camera.ColorStream:
+	default:
+		return nil, utils.NewConfigValidationError(path, camera.NewUnsupportedImageTypeError(camera.ImageType(cfg.ImageType)))
+	}
+	return []string{cfg.Left, cfg.Right}, nil
+}
+
+// stereoDepth rectifies the images of a stereo pair and matches them to find the depth of the scene.
+type stereoDepth struct {
+	left, right         gostream.VideoStream
+	leftName, rightName string
+	rectifier           *transform.StereoRectifier
+	matcher             rimage.DisparityConfig
+	imageType           camera.ImageType
+	debug               bool
+	logger              golog.Logger
+}
+
+// newStereoDepth creates a camera that streams the depth seen by the left camera of the stereo pair.
+func newStereoDepth(ctx context.Context, left, right camera.Camera, attrs *stereoAttrs, logger golog.Logger,
+) (camera.Camera, error) {
+	rectifier, err := transform.NewStereoRectifier(attrs.Calibration)
+	if err != nil {
+		return nil, err
+	}
+	imgType := camera.ImageType(attrs.ImageType)
+	if imgType == camera.UnspecifiedStream {
+		imgType = camera.DepthStream
+	}
+	matcher := rimage.DisparityConfig{
+		Method:          rimage.DisparityMethod(attrs.Method),
+		MaxDisparity:    attrs.MaxDisparity,
+		BlockSize:       attrs.BlockSize,
+		UniquenessRatio: attrs.UniquenessRatio,
+	}
+	if matcher.Method == "" {
+		matcher.Method = rimage.SemiGlobalMatching
+	}
+	if matcher.MaxDisparity == 0 {
+		matcher.MaxDisparity = defaultMaxDisparity
+	}
+	if matcher.BlockSize == 0 {
+		matcher.BlockSize = defaultBlockSize
+	}
+	if matcher.UniquenessRatio == 0 {
+		matcher.UniquenessRatio = defaultUniquenessRatio
+	}
+	// check the matcher config now rather than on the first image
+	tiny := image.NewGray(image.Rect(0, 0, 1, 1))
+	if _, err := rimage.ComputeDisparity(tiny, tiny, matcher); err != nil {
+		return nil, err
+	}
+	videoSrc := &stereoDepth{
+		left:      gostream.NewEmbeddedVideoStream(left),
+		leftName:  attrs.Left,
+		right:     gostream.NewEmbeddedVideoStream(right),
+		rightName: attrs.Right,
+		rectifier: rectifier,
+		matcher:   matcher,
+		imageType: imgType,
+		debug:     attrs.Debug,
+		logger:    logger,
+	}
+	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(rectifier.Intrinsics(), nil)
+	return camera.NewFromReader(ctx, videoSrc, &cameraModel, imgType)
+}
+
+// Read returns the depth map seen by the rectified left camera, or its color image.
+func (sd *stereoDepth) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "stereo::stereoDepth::Read")
+	defer span.End()
+	switch sd.imageType {
+	case camera.ColorStream:
+		left, _, err := sd.nextRectified(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return left, func() {}, nil
+	case camera.DepthStream:
+		_, dm, err := sd.nextColorDepth(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dm, func() {}, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(sd.imageType)
+	}
+}
+
+func (sd *stereoDepth) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "stereo::stereoDepth::NextPointCloud")
+	defer span.End()
+	col, dm, err := sd.nextColorDepth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sd.rectifier.Intrinsics().RGBDToPointCloud(col, dm)
+}
+
+// nextColorDepth returns the rectified left image and the depth map matched from the pair.
+func (sd *stereoDepth) nextColorDepth(ctx context.Context) (*rimage.Image, *rimage.DepthMap, error) {
+	left, right, err := sd.nextRectified(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, span := trace.StartSpan(ctx, "stereo::stereoDepth::ComputeDisparity")
+	disparity, err := rimage.ComputeDisparity(rimage.MakeGray(left), rimage.MakeGray(right), sd.matcher)
+	span.End()
+	if err != nil {
+		return nil, nil, err
+	}
+	dm, err := sd.rectifier.DisparityToDepth(disparity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sd.debug {
+		lo, hi := dm.MinMax()
+		sd.logger.Debugf("stereo depth from %q and %q ranges from %dmm to %dmm", sd.leftName, sd.rightName, lo, hi)
+	}
+	return left, dm, nil
+}
+
+// nextRectified gets the images of both cameras at the same time and rectifies them.
+func (sd *stereoDepth) nextRectified(ctx context.Context) (*rimage.Image, *rimage.Image, error) {
+	var wg sync.WaitGroup
+	var left, right *rimage.Image
+	var leftErr, rightErr error
+	next := func(stream gostream.VideoStream, name string, out **rimage.Image, outErr *error) {
+		defer wg.Done()
+		img, release, err := stream.Next(ctx)
+		if err != nil {
+			*outErr = errors.Wrapf(err, "could not get image from camera %q for stereo_depth camera", name)
+			return
+		}
+		defer release()
+		// copy the image since it is released before it is rectified
+		*out = rimage.CloneImage(img)
+	}
+	wg.Add(2)
+	utils.PanicCapturingGo(func() { next(sd.left, sd.leftName, &left, &leftErr) })
+	utils.PanicCapturingGo(func() { next(sd.right, sd.rightName, &right, &rightErr) })
+	wg.Wait()
+	if err := multierr.Combine(leftErr, rightErr); err != nil {
+		return nil, nil, err
+	}
+	return sd.rectifier.Rectify(left, right)
+}
+
+func (sd *stereoDepth) Close(ctx context.Context) error {
+	return multierr.Combine(sd.left.Close(ctx), sd.right.Close(ctx))
+}
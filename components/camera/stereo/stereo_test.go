@@ -0,0 +1,131 @@
+package stereo
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+)
+
+// a textured wall seen by two identical cameras side by side, 60mm apart, with a disparity of 12
+// pixels, so the wall is 1000mm away
+const (
+	testFocal     = 200.
+	testBaseline  = 60.
+	testDisparity = 12
+)
+
+func stereoImages() (*rimage.Image, *rimage.Image) {
+	rng := rand.New(rand.NewSource(1))
+	width, height := 120, 90
+	texture := make([]uint8, (width+testDisparity)*height)
+	for i := range texture {
+		texture[i] = uint8(rng.Intn(256))
+	}
+	left, right := rimage.NewImage(width, height), rimage.NewImage(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			l := texture[y*(width+testDisparity)+x]
+			r := texture[y*(width+testDisparity)+x+testDisparity]
+			left.SetXY(x, y, rimage.NewColor(l, l, l))
+			right.SetXY(x, y, rimage.NewColor(r, r, r))
+		}
+	}
+	return left, right
+}
+
+func testAttrs() *stereoAttrs {
+	intrinsics := transform.PinholeCameraIntrinsics{Width: 120, Height: 90, Fx: testFocal, Fy: testFocal, Ppx: 60, Ppy: 45}
+	return &stereoAttrs{
+		Left:  "left",
+		Right: "right",
+		Calibration: &transform.StereoCalibration{
+			Left:        intrinsics,
+			Right:       intrinsics,
+			Rotation:    []float64{1, 0, 0, 0, 1, 0, 0, 0, 1},
+			Translation: []float64{-testBaseline, 0, 0},
+		},
+		MaxDisparity: 24,
+	}
+}
+
+func TestStereoDepth(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	leftImg, rightImg := stereoImages()
+	left, err := camera.NewFromReader(ctx, &videosource.StaticSource{ColorImg: leftImg}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	defer left.Close(ctx)
+	right, err := camera.NewFromReader(ctx, &videosource.StaticSource{ColorImg: rightImg}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	defer right.Close(ctx)
+
+	attrs := testAttrs()
+	cam, err := newStereoDepth(ctx, left, right, attrs, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	img, _, err := camera.ReadImage(ctx, cam)
+	test.That(t, err, test.ShouldBeNil)
+	dm, ok := img.(*rimage.DepthMap)
+	test.That(t, ok, test.ShouldBeTrue)
+	var good, total int
+	// away from the left edge, which the right camera doesn't see
+	for y := 5; y < 85; y++ {
+		for x := 30; x < 115; x++ {
+			total++
+			if d := float64(dm.GetDepth(x, y)); d > 990 && d < 1010 {
+				good++
+			}
+		}
+	}
+	test.That(t, float64(good)/float64(total), test.ShouldBeGreaterThan, 0.95)
+
+	pc, err := cam.NextPointCloud(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldBeGreaterThan, total*9/10)
+
+	props, err := cam.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Fx, test.ShouldEqual, testFocal)
+	test.That(t, cam.Close(ctx), test.ShouldBeNil)
+
+	// the rectified left image can be streamed instead
+	attrs.ImageType = string(camera.ColorStream)
+	colorCam, err := newStereoDepth(ctx, left, right, attrs, logger)
+	test.That(t, err, test.ShouldBeNil)
+	img, _, err = camera.ReadImage(ctx, colorCam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rimage.ConvertImage(img).GetXY(60, 45), test.ShouldResemble, leftImg.GetXY(60, 45))
+	test.That(t, colorCam.Close(ctx), test.ShouldBeNil)
+
+	attrs.Method = "nope"
+	_, err = newStereoDepth(ctx, left, right, attrs, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStereoAttrsValidate(t *testing.T) {
+	attrs := testAttrs()
+	deps, err := attrs.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"left", "right"})
+
+	for _, modify := range []func(*stereoAttrs){
+		func(a *stereoAttrs) { a.Left = "" },
+		func(a *stereoAttrs) { a.Right = "" },
+		func(a *stereoAttrs) { a.Calibration = nil },
+		func(a *stereoAttrs) { a.Calibration.Translation = nil },
+		func(a *stereoAttrs) { a.ImageType = "nope" },
+	} {
+		bad := testAttrs()
+		modify(bad)
+		_, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
@@ -0,0 +1,206 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// colorFilterSource applies a filter that does not move any pixels to each color image of the
+// original stream.
+type colorFilterSource struct {
+	originalStream gostream.VideoStream
+	name           string
+	filter         func(image.Image) image.Image
+}
+
+// newColorFilter creates a transform that applies the filter to a color stream. The filter does
+// not change the size of the image, so the intrinsics of the source are kept.
+func newColorFilter(
+	ctx context.Context,
+	source gostream.VideoSource,
+	stream camera.ImageType,
+	name string,
+	filter func(image.Image) image.Image,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream == camera.DepthStream {
+		return nil, camera.UnspecifiedStream, errors.Errorf("%s transform needs a color image stream", name)
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &colorFilterSource{gostream.NewEmbeddedVideoStream(source), name, filter}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, camera.ColorStream)
+	return cam, camera.ColorStream, err
+}
+
+// Read applies the filter to the next image of the original stream.
+func (cfs *colorFilterSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::"+cfs.name+"::Read")
+	defer span.End()
+	orig, release, err := cfs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if release != nil {
+		defer release()
+	}
+	return cfs.filter(orig), func() {}, nil
+}
+
+// Close closes the original stream.
+func (cfs *colorFilterSource) Close(ctx context.Context) error {
+	return cfs.originalStream.Close(ctx)
+}
+
+// newGrayscaleTransform creates a transform that turns a color image into shades of gray.
+func newGrayscaleTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType,
+) (gostream.VideoSource, camera.ImageType, error) {
+	return newColorFilter(ctx, source, stream, string(transformTypeGrayscale), func(img image.Image) image.Image {
+		return imaging.Grayscale(img)
+	})
+}
+
+// colorSpaceAttrs are the attributes for a color space transform.
+type colorSpaceAttrs struct {
+	// Space is the color space the red, green and blue channels of the image are changed to hold,
+	// one of "bgr", "hsv" and "ycbcr".
+	Space string `json:"space"`
+}
+
+// colorSpaces are the conversions of the color space transform, from the 8 bit red, green and
+// blue of a pixel to the three channels of the space.
+var colorSpaces = map[string]func(r, g, b uint8) (uint8, uint8, uint8){
+	"bgr": func(r, g, b uint8) (uint8, uint8, uint8) {
+		return b, g, r
+	},
+	"hsv": func(r, g, b uint8) (uint8, uint8, uint8) {
+		h, s, v := rimage.NewColor(r, g, b).HsvNormal()
+		return uint8(math.Round(h * 255 / 360)), uint8(math.Round(s * 255)), uint8(math.Round(v * 255))
+	},
+	"ycbcr": color.RGBToYCbCr,
+}
+
+// newColorSpaceTransform creates a transform that converts the colors of an image to another
+// color space, stored in the channels of an RGBA image.
+func newColorSpaceTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(colorSpaceAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*colorSpaceAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	convert, ok := colorSpaces[attrs.Space]
+	if !ok {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("space for color_space transform must be one of bgr, hsv or ycbcr, got %q", attrs.Space)
+	}
+	return newColorFilter(ctx, source, stream, string(transformTypeColorSpace), func(img image.Image) image.Image {
+		converted := imaging.Clone(img)
+		for i := 0; i < len(converted.Pix); i += 4 {
+			converted.Pix[i], converted.Pix[i+1], converted.Pix[i+2] = convert(
+				converted.Pix[i], converted.Pix[i+1], converted.Pix[i+2])
+		}
+		return converted
+	})
+}
+
+// colorAdjustAttrs are the attributes for a color adjustment transform.
+type colorAdjustAttrs struct {
+	// Brightness and Contrast are percentages from -100 to 100, where 0 leaves the image as it is.
+	Brightness float64 `json:"brightness_pct,omitempty"`
+	Contrast   float64 `json:"contrast_pct,omitempty"`
+	// Gamma is the gamma correction, where less than 1 darkens the image and more than 1 lightens
+	// it. 0 is taken to be 1.
+	Gamma float64 `json:"gamma,omitempty"`
+}
+
+// newColorAdjustTransform creates a transform that adjusts the brightness, contrast and gamma of
+// an image.
+func newColorAdjustTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(colorAdjustAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*colorAdjustAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if math.Abs(attrs.Brightness) > 100 || math.Abs(attrs.Contrast) > 100 {
+		return nil, camera.UnspecifiedStream,
+			errors.New("brightness_pct and contrast_pct for color_adjust transform must be between -100 and 100")
+	}
+	if attrs.Gamma < 0 {
+		return nil, camera.UnspecifiedStream, errors.New("gamma for color_adjust transform cannot be negative")
+	}
+	return newColorFilter(ctx, source, stream, string(transformTypeColorAdjust), func(img image.Image) image.Image {
+		adjusted := imaging.Clone(img)
+		if attrs.Brightness != 0 {
+			adjusted = imaging.AdjustBrightness(adjusted, attrs.Brightness)
+		}
+		if attrs.Contrast != 0 {
+			adjusted = imaging.AdjustContrast(adjusted, attrs.Contrast)
+		}
+		if attrs.Gamma != 0 && attrs.Gamma != 1 {
+			adjusted = imaging.AdjustGamma(adjusted, attrs.Gamma)
+		}
+		return adjusted
+	})
+}
+
+// sigmaAttrs are the attributes for the blur and sharpen transforms.
+type sigmaAttrs struct {
+	// Sigma is the standard deviation, in pixels, of the gaussian the image is blurred with, or
+	// that it is sharpened against.
+	Sigma float64 `json:"sigma_px"`
+}
+
+// newBlurTransform creates a transform that blurs an image. If sharpen is true, it sharpens the
+// image instead.
+func newBlurTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap, sharpen bool,
+) (gostream.VideoSource, camera.ImageType, error) {
+	name, filter := string(transformTypeBlur), imaging.Blur
+	if sharpen {
+		name, filter = string(transformTypeSharpen), imaging.Sharpen
+	}
+	conf, err := config.TransformAttributeMapToStruct(&(sigmaAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*sigmaAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if attrs.Sigma <= 0 {
+		return nil, camera.UnspecifiedStream, errors.Errorf("sigma_px for %s transform must be more than 0", name)
+	}
+	return newColorFilter(ctx, source, stream, name, func(img image.Image) image.Image {
+		return filter(img, attrs.Sigma)
+	})
+}
@@ -0,0 +1,139 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/edaniels/gostream"
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+)
+
+func newColorTestSource(c color.Color) gostream.VideoSource {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return gostream.NewVideoSource(&videosource.StaticSource{ColorImg: img}, prop.Video{})
+}
+
+func readRGB(t *testing.T, source gostream.VideoSource, x, y int) (uint8, uint8, uint8) {
+	t.Helper()
+	out, _, err := camera.ReadImage(context.Background(), source)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Bounds().Dx(), test.ShouldEqual, 20)
+	test.That(t, out.Bounds().Dy(), test.ShouldEqual, 10)
+	return rimage.NewColorFromColor(out.At(out.Bounds().Min.X+x, out.Bounds().Min.Y+y)).RGB255()
+}
+
+func TestGrayscale(t *testing.T) {
+	source := newColorTestSource(color.RGBA{200, 100, 50, 255})
+	_, _, err := newGrayscaleTransform(context.Background(), source, camera.DepthStream)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs a color image stream")
+
+	gs, stream, err := newGrayscaleTransform(context.Background(), source, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	r, g, b := readRGB(t, gs, 5, 5)
+	test.That(t, r, test.ShouldEqual, g)
+	test.That(t, g, test.ShouldEqual, b)
+	test.That(t, gs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestColorSpace(t *testing.T) {
+	source := newColorTestSource(color.RGBA{200, 100, 50, 255})
+	_, _, err := newColorSpaceTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"space": "cmyk"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be one of")
+
+	cs, _, err := newColorSpaceTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"space": "bgr"})
+	test.That(t, err, test.ShouldBeNil)
+	r, g, b := readRGB(t, cs, 0, 0)
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{50, 100, 200})
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	cs, _, err = newColorSpaceTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"space": "ycbcr"})
+	test.That(t, err, test.ShouldBeNil)
+	r, g, b = readRGB(t, cs, 0, 0)
+	y, cb, cr := color.RGBToYCbCr(200, 100, 50)
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{y, cb, cr})
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	cs, _, err = newColorSpaceTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"space": "hsv"})
+	test.That(t, err, test.ShouldBeNil)
+	_, s, v := readRGB(t, cs, 0, 0)
+	test.That(t, v, test.ShouldEqual, 200)
+	test.That(t, s, test.ShouldEqual, 191)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestColorAdjust(t *testing.T) {
+	source := newColorTestSource(color.RGBA{100, 100, 100, 255})
+	_, _, err := newColorAdjustTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"brightness_pct": 150})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "between -100 and 100")
+	_, _, err = newColorAdjustTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"gamma": -1})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot be negative")
+
+	cs, _, err := newColorAdjustTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{})
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ := readRGB(t, cs, 0, 0)
+	test.That(t, r, test.ShouldEqual, 100)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	cs, _, err = newColorAdjustTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"brightness_pct": 20})
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ = readRGB(t, cs, 0, 0)
+	test.That(t, r, test.ShouldBeGreaterThan, 100)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	cs, _, err = newColorAdjustTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"gamma": 0.5})
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ = readRGB(t, cs, 0, 0)
+	test.That(t, r, test.ShouldBeLessThan, 100)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestBlurSharpen(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 10; x < 20; x++ {
+			img.Set(x, y, color.RGBA{200, 200, 200, 255})
+		}
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.RGBA{100, 100, 100, 255})
+		}
+	}
+	source := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: img}, prop.Video{})
+	_, _, err := newBlurTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{}, false)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "sigma_px for blur")
+
+	// blurring softens the edge, and sharpening makes it stronger
+	bs, _, err := newBlurTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"sigma_px": 2}, false)
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ := readRGB(t, bs, 9, 5)
+	test.That(t, r, test.ShouldBeGreaterThan, 100)
+	test.That(t, bs.Close(context.Background()), test.ShouldBeNil)
+
+	bs, _, err = newBlurTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"sigma_px": 2}, true)
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ = readRGB(t, bs, 9, 5)
+	test.That(t, r, test.ShouldBeLessThan, 100)
+	test.That(t, bs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
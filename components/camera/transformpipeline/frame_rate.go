@@ -0,0 +1,88 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// frameRateLimitAttrs are the attributes for a frame rate limit transform.
+type frameRateLimitAttrs struct {
+	MaxFPS float64 `json:"max_fps"`
+}
+
+type frameRateLimitSource struct {
+	originalStream gostream.VideoStream
+	interval       time.Duration
+
+	mu        sync.Mutex
+	lastFrame time.Time
+}
+
+// newFrameRateLimitTransform creates a new transform that reads images no more often than the
+// maximum frame rate, waiting for the next one to be due. It works on any stream type.
+func newFrameRateLimitTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(frameRateLimitAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*frameRateLimitAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if attrs.MaxFPS <= 0 {
+		return nil, camera.UnspecifiedStream, errors.New("max_fps for frame_rate_limit transform must be more than 0")
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &frameRateLimitSource{
+		originalStream: gostream.NewEmbeddedVideoStream(source),
+		interval:       time.Duration(float64(time.Second) / attrs.MaxFPS),
+	}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, stream)
+	return cam, stream, err
+}
+
+// Read waits until the next image is due and then reads it from the original stream.
+func (frs *frameRateLimitSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::frame_rate_limit::Read")
+	defer span.End()
+	frs.mu.Lock()
+	defer frs.mu.Unlock()
+	if wait := time.Until(frs.lastFrame.Add(frs.interval)); wait > 0 {
+		if !utils.SelectContextOrWait(ctx, wait) {
+			return nil, nil, ctx.Err()
+		}
+	}
+	img, release, err := frs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	frs.lastFrame = time.Now()
+	return img, release, nil
+}
+
+// Close closes the original stream.
+func (frs *frameRateLimitSource) Close(ctx context.Context) error {
+	return frs.originalStream.Close(ctx)
+}
@@ -0,0 +1,38 @@
+package transformpipeline
+
+import (
+	"context"
+	"image/color"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+)
+
+func TestFrameRateLimit(t *testing.T) {
+	source := newColorTestSource(color.RGBA{0, 0, 0, 255})
+	_, _, err := newFrameRateLimitTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be more than 0")
+
+	fs, stream, err := newFrameRateLimitTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"max_fps": 10})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _, err = camera.ReadImage(context.Background(), fs)
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 200*time.Millisecond)
+
+	// waiting for the next image stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = camera.ReadImage(ctx, fs)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
@@ -0,0 +1,217 @@
+package transformpipeline
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"sync"
+
+	"github.com/edaniels/gostream"
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/chess"
+)
+
+// The DoCommand commands of the intrinsic calibration transform, given as the "command" key.
+const (
+	// CalibrationCaptureCommand adds the checkerboard in the next image to the calibration views.
+	CalibrationCaptureCommand = "capture"
+	// CalibrationCalibrateCommand computes the intrinsics from the captured views.
+	CalibrationCalibrateCommand = "calibrate"
+	// CalibrationResetCommand forgets the captured views.
+	CalibrationResetCommand = "reset"
+	// CalibrationStatusCommand returns how many views have been captured.
+	CalibrationStatusCommand = "status"
+)
+
+// intrinsicCalibrationAttrs describes the checkerboard used to calibrate the camera.
+type intrinsicCalibrationAttrs struct {
+	// BoardRows and BoardCols are the number of inner corners of the checkerboard, where its
+	// squares meet, along each side.
+	BoardRows    int     `json:"board_rows"`
+	BoardCols    int     `json:"board_cols"`
+	SquareSizeMm float64 `json:"square_size_mm"`
+}
+
+// intrinsicCalibrationSource overlays the corners of the checkerboard it finds on the images from
+// the camera, and collects views of the checkerboard on command to calibrate the camera with.
+type intrinsicCalibrationSource struct {
+	source       gostream.VideoSource
+	stream       gostream.VideoStream
+	rows, cols   int
+	targetPoints []r2.Point
+
+	mu            sync.Mutex
+	views         [][]r2.Point
+	width, height int
+}
+
+func newIntrinsicCalibrationTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream == camera.DepthStream {
+		return nil, camera.UnspecifiedStream, errors.New("intrinsic calibration needs a color image stream")
+	}
+	conf, err := config.TransformAttributeMapToStruct(&(intrinsicCalibrationAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*intrinsicCalibrationAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if attrs.BoardRows < 2 || attrs.BoardCols < 2 {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("board_rows and board_cols must be at least 2, got %d and %d", attrs.BoardRows, attrs.BoardCols)
+	}
+	squareSize := attrs.SquareSizeMm
+	if squareSize <= 0 {
+		squareSize = 1
+	}
+
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	calibration := &intrinsicCalibrationSource{
+		source:       source,
+		stream:       gostream.NewEmbeddedVideoStream(source),
+		rows:         attrs.BoardRows,
+		cols:         attrs.BoardCols,
+		targetPoints: transform.CheckerboardPoints(attrs.BoardRows, attrs.BoardCols, squareSize),
+	}
+	cam, err := camera.NewFromReader(ctx, calibration, &cameraModel, camera.ColorStream)
+	return cam, camera.ColorStream, err
+}
+
+// Read returns the image with the corners of the checkerboard drawn on it, if it was found.
+func (ics *intrinsicCalibrationSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::intrinsic_calibration::Read")
+	defer span.End()
+	img, release, err := ics.stream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	corners, err := chess.FindCheckerboardCorners(img, ics.rows, ics.cols)
+	if err != nil {
+		return img, release, nil
+	}
+	return drawCheckerboardCorners(img, corners), release, nil
+}
+
+// drawCheckerboardCorners draws the corners joined in the order they were found.
+func drawCheckerboardCorners(img image.Image, corners []r2.Point) image.Image {
+	dc := gg.NewContextForImage(img)
+	dc.SetColor(rimage.Green)
+	dc.SetLineWidth(2)
+	for i, c := range corners {
+		if i == 0 {
+			dc.MoveTo(c.X, c.Y)
+		} else {
+			dc.LineTo(c.X, c.Y)
+		}
+	}
+	dc.Stroke()
+	dc.SetColor(rimage.Red)
+	for _, c := range corners {
+		dc.DrawCircle(c.X, c.Y, 4)
+		dc.Stroke()
+	}
+	return dc.Image()
+}
+
+// DoCommand captures views of the checkerboard and calibrates the camera from them.
+func (ics *intrinsicCalibrationSource) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case CalibrationCaptureCommand:
+		return ics.capture(ctx)
+	case CalibrationCalibrateCommand:
+		return ics.calibrate()
+	case CalibrationResetCommand:
+		ics.mu.Lock()
+		defer ics.mu.Unlock()
+		ics.views = nil
+		return ics.status(), nil
+	case CalibrationStatusCommand:
+		ics.mu.Lock()
+		defer ics.mu.Unlock()
+		return ics.status(), nil
+	default:
+		return nil, errors.Errorf("no such command: %v", name)
+	}
+}
+
+// status must be called with the lock held.
+func (ics *intrinsicCalibrationSource) status() map[string]interface{} {
+	return map[string]interface{}{
+		"views":        len(ics.views),
+		"views_needed": transform.MinIntrinsicCalibrationViews,
+	}
+}
+
+func (ics *intrinsicCalibrationSource) capture(ctx context.Context) (map[string]interface{}, error) {
+	img, release, err := camera.ReadImage(ctx, ics.source)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	corners, err := chess.FindCheckerboardCorners(img, ics.rows, ics.cols)
+	if err != nil {
+		return nil, err
+	}
+	ics.mu.Lock()
+	defer ics.mu.Unlock()
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if len(ics.views) > 0 && (width != ics.width || height != ics.height) {
+		return nil, errors.Errorf("image is %dx%d but earlier views were %dx%d, reset to start over",
+			width, height, ics.width, ics.height)
+	}
+	ics.width, ics.height = width, height
+	ics.views = append(ics.views, corners)
+	return ics.status(), nil
+}
+
+func (ics *intrinsicCalibrationSource) calibrate() (map[string]interface{}, error) {
+	ics.mu.Lock()
+	views := append([][]r2.Point(nil), ics.views...)
+	width, height := ics.width, ics.height
+	ics.mu.Unlock()
+
+	cal, err := transform.CalibratePinholeIntrinsics(ics.targetPoints, views, width, height)
+	if err != nil {
+		return nil, err
+	}
+	// round trip through JSON so the result holds only types a DoCommand response can
+	b, err := json.Marshal(cal)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Close closes the original stream.
+func (ics *intrinsicCalibrationSource) Close(ctx context.Context) error {
+	return ics.stream.Close(ctx)
+}
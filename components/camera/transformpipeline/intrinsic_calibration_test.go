@@ -0,0 +1,114 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/edaniels/gostream"
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+)
+
+// checkerboardImage draws a checkerboard with rows by cols inner corners and squares of 30 pixels,
+// straight on, with the first inner corner at (130, 110).
+func checkerboardImage(rows, cols int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			col, row := (x-100)/30, (y-80)/30
+			shade := uint8(235)
+			if x >= 100 && y >= 80 && col <= cols && row <= rows && (col+row)%2 == 0 {
+				shade = 20
+			}
+			img.SetGray(x, y, color.Gray{shade})
+		}
+	}
+	return img
+}
+
+func TestIntrinsicCalibrationSetup(t *testing.T) {
+	ctx := context.Background()
+	source := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: checkerboardImage(4, 5)}, prop.Video{})
+	defer source.Close(ctx)
+
+	_, _, err := newIntrinsicCalibrationTransform(ctx, source, camera.ColorStream, config.AttributeMap{"board_rows": 4})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 2")
+
+	am := config.AttributeMap{"board_rows": 4, "board_cols": 5, "square_size_mm": 30}
+	_, _, err = newIntrinsicCalibrationTransform(ctx, source, camera.DepthStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cal, stream, err := newIntrinsicCalibrationTransform(ctx, source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+
+	// the corners are drawn on the image
+	img, release, err := camera.ReadImage(ctx, cal)
+	test.That(t, err, test.ShouldBeNil)
+	defer release()
+	test.That(t, rimage.ConvertImage(img).GetXY(130, 110), test.ShouldNotResemble, rimage.NewColor(235, 235, 235))
+	test.That(t, cal.Close(ctx), test.ShouldBeNil)
+}
+
+func TestIntrinsicCalibrationCommands(t *testing.T) {
+	ctx := context.Background()
+	source := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: checkerboardImage(4, 5)}, prop.Video{})
+	defer source.Close(ctx)
+
+	// commands reach the transform through the pipeline's camera
+	cfg := &transformConfig{
+		Pipeline: []Transformation{
+			{Type: "identity"},
+			{
+				Type:       "intrinsic_calibration",
+				Attributes: config.AttributeMap{"board_rows": 4, "board_cols": 5, "square_size_mm": 30},
+			},
+		},
+	}
+	cam, err := newTransformPipeline(ctx, source, cfg, nil)
+	test.That(t, err, test.ShouldBeNil)
+	defer cam.Close(ctx)
+
+	_, err = cam.DoCommand(ctx, map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = cam.DoCommand(ctx, map[string]interface{}{"command": "nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such command")
+
+	resp, err := cam.DoCommand(ctx, map[string]interface{}{"command": CalibrationStatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"views": 0, "views_needed": 3})
+
+	resp, err = cam.DoCommand(ctx, map[string]interface{}{"command": CalibrationCaptureCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, 1)
+	resp, err = cam.DoCommand(ctx, map[string]interface{}{"command": CalibrationCaptureCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, 2)
+
+	_, err = cam.DoCommand(ctx, map[string]interface{}{"command": CalibrationCalibrateCommand})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 views")
+
+	resp, err = cam.DoCommand(ctx, map[string]interface{}{"command": CalibrationResetCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, 0)
+
+	// a board of the wrong size can't be captured
+	wrong := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: checkerboardImage(3, 5)}, prop.Video{})
+	defer wrong.Close(ctx)
+	wrongCam, err := newTransformPipeline(ctx, wrong, cfg, nil)
+	test.That(t, err, test.ShouldBeNil)
+	defer wrongCam.Close(ctx)
+	_, err = wrongCam.DoCommand(ctx, map[string]interface{}{"command": CalibrationCaptureCommand})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not find a 4x5 checkerboard")
+}
@@ -0,0 +1,161 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"sync"
+
+	"github.com/edaniels/gostream"
+	"github.com/fogleman/gg"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"golang.org/x/image/draw"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// maskAttrs are the attributes for a mask transform.
+type maskAttrs struct {
+	// Polygons are the areas masked out of the image, each a list of at least 3 [x, y] points in
+	// pixels.
+	Polygons [][][2]float64 `json:"polygons"`
+	// Invert masks out everything but the polygons instead.
+	Invert bool `json:"invert,omitempty"`
+	// Color is the hex color masked out areas of a color image are filled with, black by default.
+	// Masked out areas of a depth map are set to 0.
+	Color string `json:"color,omitempty"`
+}
+
+type maskSource struct {
+	originalStream gostream.VideoStream
+	stream         camera.ImageType
+	polygons       [][][2]float64
+	invert         bool
+	color          rimage.Color
+
+	mu   sync.Mutex
+	mask *image.Alpha
+}
+
+// newMaskTransform creates a new mask transform, which hides polygons of the image.
+func newMaskTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(maskAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*maskAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if len(attrs.Polygons) == 0 {
+		return nil, camera.UnspecifiedStream, errors.New("mask transform needs at least one polygon")
+	}
+	for i, polygon := range attrs.Polygons {
+		if len(polygon) < 3 {
+			return nil, camera.UnspecifiedStream,
+				errors.Errorf("polygon %d of mask transform needs at least 3 points, got %d", i, len(polygon))
+		}
+	}
+	maskColor := rimage.NewColor(0, 0, 0)
+	if attrs.Color != "" {
+		maskColor, err = rimage.NewColorFromHex(attrs.Color)
+		if err != nil {
+			return nil, camera.UnspecifiedStream, errors.Wrap(err, "invalid color for mask transform")
+		}
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &maskSource{
+		originalStream: gostream.NewEmbeddedVideoStream(source),
+		stream:         stream,
+		polygons:       attrs.Polygons,
+		invert:         attrs.Invert,
+		color:          maskColor,
+	}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, stream)
+	return cam, stream, err
+}
+
+// maskFor returns the mask of the areas to hide for an image of the given size, which is only
+// drawn again when the size changes.
+func (ms *maskSource) maskFor(width, height int) *image.Alpha {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.mask != nil && ms.mask.Bounds().Dx() == width && ms.mask.Bounds().Dy() == height {
+		return ms.mask
+	}
+	dc := gg.NewContext(width, height)
+	for _, polygon := range ms.polygons {
+		dc.MoveTo(polygon[0][0], polygon[0][1])
+		for _, pt := range polygon[1:] {
+			dc.LineTo(pt[0], pt[1])
+		}
+		dc.ClosePath()
+	}
+	if ms.invert {
+		dc.SetFillRuleEvenOdd()
+		dc.DrawRectangle(0, 0, float64(width), float64(height))
+	}
+	dc.SetRGB(1, 1, 1)
+	dc.Fill()
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	draw.Draw(mask, mask.Bounds(), dc.Image(), image.Point{}, draw.Src)
+	ms.mask = mask
+	return mask
+}
+
+// Read masks the 2D image depending on the stream type.
+func (ms *maskSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::mask::Read")
+	defer span.End()
+	orig, release, err := ms.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if release != nil {
+		defer release()
+	}
+	bounds := orig.Bounds()
+	mask := ms.maskFor(bounds.Dx(), bounds.Dy())
+	switch ms.stream {
+	case camera.ColorStream, camera.UnspecifiedStream:
+		masked := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(masked, masked.Bounds(), orig, bounds.Min, draw.Src)
+		draw.DrawMask(masked, masked.Bounds(), image.NewUniform(ms.color), image.Point{}, mask, image.Point{}, draw.Over)
+		return masked, func() {}, nil
+	case camera.DepthStream:
+		dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+		if err != nil {
+			return nil, nil, err
+		}
+		masked := rimage.NewEmptyDepthMap(dm.Width(), dm.Height())
+		for y := 0; y < dm.Height(); y++ {
+			for x := 0; x < dm.Width(); x++ {
+				if mask.AlphaAt(x, y).A < 128 {
+					masked.Set(x, y, dm.GetDepth(x, y))
+				}
+			}
+		}
+		return masked, func() {}, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(ms.stream)
+	}
+}
+
+// Close closes the original stream.
+func (ms *maskSource) Close(ctx context.Context) error {
+	return ms.originalStream.Close(ctx)
+}
@@ -0,0 +1,78 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/edaniels/gostream"
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+)
+
+func TestMaskColor(t *testing.T) {
+	source := newColorTestSource(color.RGBA{100, 100, 100, 255})
+	_, _, err := newMaskTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one polygon")
+	_, _, err = newMaskTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{
+		"polygons": [][][2]float64{{{0, 0}, {10, 0}}},
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 points")
+
+	square := [][][2]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+	ms, stream, err := newMaskTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{
+		"polygons": square,
+		"color":    "#ff0000",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	r, g, _ := readRGB(t, ms, 5, 5)
+	test.That(t, []uint8{r, g}, test.ShouldResemble, []uint8{255, 0})
+	r, g, _ = readRGB(t, ms, 15, 5)
+	test.That(t, []uint8{r, g}, test.ShouldResemble, []uint8{100, 100})
+	test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+
+	ms, _, err = newMaskTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{
+		"polygons": square,
+		"invert":   true,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _ = readRGB(t, ms, 5, 5)
+	test.That(t, r, test.ShouldEqual, 100)
+	r, _, _ = readRGB(t, ms, 15, 5)
+	test.That(t, r, test.ShouldEqual, 0)
+	test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestMaskDepth(t *testing.T) {
+	dm := rimage.NewEmptyDepthMap(20, 10)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			dm.Set(x, y, 1000)
+		}
+	}
+	source := gostream.NewVideoSource(&videosource.StaticSource{DepthImg: dm}, prop.Video{})
+	ms, stream, err := newMaskTransform(context.Background(), source, camera.DepthStream, config.AttributeMap{
+		"polygons": [][][2]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	out, _, err := camera.ReadImage(context.Background(), ms)
+	test.That(t, err, test.ShouldBeNil)
+	masked, err := rimage.ConvertImageToDepthMap(context.Background(), out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, masked.Bounds(), test.ShouldResemble, image.Rect(0, 0, 20, 10))
+	test.That(t, masked.GetDepth(5, 5), test.ShouldEqual, rimage.Depth(0))
+	test.That(t, masked.GetDepth(15, 5), test.ShouldEqual, rimage.Depth(1000))
+	test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
@@ -136,3 +136,191 @@ func (rs *resizeSource) Read(ctx context.Context) (image.Image, func(), error) {
 func (rs *resizeSource) Close(ctx context.Context) error {
 	return rs.originalStream.Close(ctx)
 }
+
+// cropAttrs are the attributes for a crop transform, the corners of the region of interest.
+type cropAttrs struct {
+	XMin int `json:"x_min_px"`
+	YMin int `json:"y_min_px"`
+	XMax int `json:"x_max_px"`
+	YMax int `json:"y_max_px"`
+}
+
+type cropSource struct {
+	originalStream gostream.VideoStream
+	stream         camera.ImageType
+	region         image.Rectangle
+}
+
+// newCropTransform creates a new crop transform, which keeps a region of interest of the image.
+func newCropTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(cropAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*cropAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if attrs.XMin < 0 || attrs.YMin < 0 {
+		return nil, camera.UnspecifiedStream, errors.New("x_min_px and y_min_px for crop transform cannot be negative")
+	}
+	if attrs.XMax <= attrs.XMin || attrs.YMax <= attrs.YMin {
+		return nil, camera.UnspecifiedStream, errors.New("x_max_px and y_max_px for crop transform must be more than x_min_px and y_min_px")
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	if props.IntrinsicParams != nil {
+		// the principal point moves with the corner of the image
+		intrinsics := *props.IntrinsicParams
+		intrinsics.Width = attrs.XMax - attrs.XMin
+		intrinsics.Height = attrs.YMax - attrs.YMin
+		intrinsics.Ppx -= float64(attrs.XMin)
+		intrinsics.Ppy -= float64(attrs.YMin)
+		cameraModel.PinholeCameraIntrinsics = &intrinsics
+	}
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &cropSource{
+		gostream.NewEmbeddedVideoStream(source),
+		stream,
+		image.Rect(attrs.XMin, attrs.YMin, attrs.XMax, attrs.YMax),
+	}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, stream)
+	return cam, stream, err
+}
+
+// Read crops the 2D image depending on the stream type.
+func (cs *cropSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::crop::Read")
+	defer span.End()
+	orig, release, err := cs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := orig.Bounds()
+	region := cs.region.Add(bounds.Min).Intersect(bounds)
+	if region.Empty() {
+		return nil, nil, errors.Errorf("crop region %v is outside of the image %v", cs.region, bounds)
+	}
+	switch cs.stream {
+	case camera.ColorStream, camera.UnspecifiedStream:
+		return imaging.Crop(orig, region), release, nil
+	case camera.DepthStream:
+		dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dm.SubImage(region.Sub(bounds.Min)), release, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(cs.stream)
+	}
+}
+
+// Close closes the original stream.
+func (cs *cropSource) Close(ctx context.Context) error {
+	return cs.originalStream.Close(ctx)
+}
+
+// flipAttrs are the attributes for a flip transform.
+type flipAttrs struct {
+	// Direction is "horizontal", to mirror the image left to right, or "vertical", to flip it
+	// upside down.
+	Direction string `json:"direction"`
+}
+
+type flipSource struct {
+	originalStream gostream.VideoStream
+	stream         camera.ImageType
+	horizontal     bool
+}
+
+// newFlipTransform creates a new flip transform.
+func newFlipTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(flipAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*flipAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	var horizontal bool
+	switch attrs.Direction {
+	case "horizontal":
+		horizontal = true
+	case "vertical":
+	default:
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("direction for flip transform must be horizontal or vertical, got %q", attrs.Direction)
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	if props.IntrinsicParams != nil {
+		// the principal point is mirrored with the image
+		intrinsics := *props.IntrinsicParams
+		if horizontal {
+			intrinsics.Ppx = float64(intrinsics.Width) - intrinsics.Ppx
+		} else {
+			intrinsics.Ppy = float64(intrinsics.Height) - intrinsics.Ppy
+		}
+		cameraModel.PinholeCameraIntrinsics = &intrinsics
+	}
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &flipSource{gostream.NewEmbeddedVideoStream(source), stream, horizontal}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, stream)
+	return cam, stream, err
+}
+
+// Read flips the 2D image depending on the stream type.
+func (fs *flipSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::flip::Read")
+	defer span.End()
+	orig, release, err := fs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch fs.stream {
+	case camera.ColorStream, camera.UnspecifiedStream:
+		if fs.horizontal {
+			return imaging.FlipH(orig), release, nil
+		}
+		return imaging.FlipV(orig), release, nil
+	case camera.DepthStream:
+		dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+		if err != nil {
+			return nil, nil, err
+		}
+		width, height := dm.Width(), dm.Height()
+		flipped := rimage.NewEmptyDepthMap(width, height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if fs.horizontal {
+					flipped.Set(width-1-x, y, dm.GetDepth(x, y))
+				} else {
+					flipped.Set(x, height-1-y, dm.GetDepth(x, y))
+				}
+			}
+		}
+		return flipped, release, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(fs.stream)
+	}
+}
+
+// Close closes the original stream.
+func (fs *flipSource) Close(ctx context.Context) error {
+	return fs.originalStream.Close(ctx)
+}
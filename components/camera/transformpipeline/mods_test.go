@@ -3,6 +3,7 @@ package transformpipeline
 import (
 	"context"
 	"image"
+	"image/color"
 	"testing"
 
 	"github.com/edaniels/golog"
@@ -16,6 +17,7 @@ import (
 	"go.viam.com/rdk/components/camera/videosource"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
 )
 
 var outDir string
@@ -187,3 +189,130 @@ func BenchmarkDepthRotate(b *testing.B) {
 	test.That(b, rs.Close(context.Background()), test.ShouldBeNil)
 	test.That(b, source.Close(context.Background()), test.ShouldBeNil)
 }
+
+func TestCropColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	img.Set(10, 5, color.RGBA{255, 0, 0, 255})
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 40, Height: 30, Fx: 10, Fy: 10, Ppx: 20, Ppy: 15}
+	source, err := camera.NewFromReader(
+		context.Background(),
+		&videosource.StaticSource{ColorImg: img},
+		&transform.PinholeCameraModel{PinholeCameraIntrinsics: intrinsics},
+		camera.ColorStream,
+	)
+	test.That(t, err, test.ShouldBeNil)
+
+	// bad region
+	am := config.AttributeMap{"x_min_px": 10, "y_min_px": 5, "x_max_px": 10, "y_max_px": 20}
+	_, _, err = newCropTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be more than")
+
+	// region outside of the image
+	am = config.AttributeMap{"x_min_px": 50, "y_min_px": 5, "x_max_px": 60, "y_max_px": 20}
+	cs, _, err := newCropTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	_, _, err = camera.ReadImage(context.Background(), cs)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "outside of the image")
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	am = config.AttributeMap{"x_min_px": 10, "y_min_px": 5, "x_max_px": 30, "y_max_px": 20}
+	cs, stream, err := newCropTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	out, _, err := camera.ReadImage(context.Background(), cs)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Bounds().Dx(), test.ShouldEqual, 20)
+	test.That(t, out.Bounds().Dy(), test.ShouldEqual, 15)
+	r, _, _, _ := out.At(out.Bounds().Min.X, out.Bounds().Min.Y).RGBA()
+	test.That(t, r, test.ShouldEqual, 0xffff)
+
+	props, err := cs.(camera.Camera).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Width, test.ShouldEqual, 20)
+	test.That(t, props.IntrinsicParams.Height, test.ShouldEqual, 15)
+	test.That(t, props.IntrinsicParams.Ppx, test.ShouldEqual, 10)
+	test.That(t, props.IntrinsicParams.Ppy, test.ShouldEqual, 10)
+	test.That(t, intrinsics.Ppx, test.ShouldEqual, 20)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestCropDepth(t *testing.T) {
+	dm := rimage.NewEmptyDepthMap(40, 30)
+	dm.Set(10, 5, 1234)
+	source := gostream.NewVideoSource(&videosource.StaticSource{DepthImg: dm}, prop.Video{})
+	am := config.AttributeMap{"x_min_px": 10, "y_min_px": 5, "x_max_px": 30, "y_max_px": 20}
+	cs, stream, err := newCropTransform(context.Background(), source, camera.DepthStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	out, _, err := camera.ReadImage(context.Background(), cs)
+	test.That(t, err, test.ShouldBeNil)
+	cropped, err := rimage.ConvertImageToDepthMap(context.Background(), out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cropped.Width(), test.ShouldEqual, 20)
+	test.That(t, cropped.Height(), test.ShouldEqual, 15)
+	test.That(t, cropped.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(1234))
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestFlipColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 40, Height: 30, Fx: 10, Fy: 10, Ppx: 15, Ppy: 10}
+	source, err := camera.NewFromReader(
+		context.Background(),
+		&videosource.StaticSource{ColorImg: img},
+		&transform.PinholeCameraModel{PinholeCameraIntrinsics: intrinsics},
+		camera.ColorStream,
+	)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, _, err = newFlipTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"direction": "diagonal"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "horizontal or vertical")
+
+	fs, _, err := newFlipTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"direction": "horizontal"})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err := camera.ReadImage(context.Background(), fs)
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _, _ := out.At(39, 0).RGBA()
+	test.That(t, r, test.ShouldEqual, 0xffff)
+	props, err := fs.(camera.Camera).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Ppx, test.ShouldEqual, 25)
+	test.That(t, props.IntrinsicParams.Ppy, test.ShouldEqual, 10)
+	test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+
+	fs, _, err = newFlipTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{"direction": "vertical"})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err = camera.ReadImage(context.Background(), fs)
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _, _ = out.At(0, 29).RGBA()
+	test.That(t, r, test.ShouldEqual, 0xffff)
+	props, err = fs.(camera.Camera).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Ppx, test.ShouldEqual, 15)
+	test.That(t, props.IntrinsicParams.Ppy, test.ShouldEqual, 20)
+	test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestFlipDepth(t *testing.T) {
+	dm := rimage.NewEmptyDepthMap(40, 30)
+	dm.Set(0, 0, 1234)
+	source := gostream.NewVideoSource(&videosource.StaticSource{DepthImg: dm}, prop.Video{})
+	fs, stream, err := newFlipTransform(context.Background(), source, camera.DepthStream, config.AttributeMap{"direction": "vertical"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	out, _, err := camera.ReadImage(context.Background(), fs)
+	test.That(t, err, test.ShouldBeNil)
+	flipped, err := rimage.ConvertImageToDepthMap(context.Background(), out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, flipped.GetDepth(0, 29), test.ShouldEqual, rimage.Depth(1234))
+	test.That(t, flipped.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(0))
+	test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
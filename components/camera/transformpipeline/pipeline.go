@@ -15,6 +15,7 @@ import (
 	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/generic"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/registry"
 	"go.viam.com/rdk/resource"
@@ -130,6 +131,22 @@ func (tp transformPipeline) Read(ctx context.Context) (image.Image, func(), erro
 	return tp.stream.Next(ctx)
 }
 
+// DoCommand sends the command to the last transform in the pipeline that takes commands.
+func (tp transformPipeline) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	for i := len(tp.pipeline) - 1; i >= 0; i-- {
+		doer, ok := tp.pipeline[i].(generic.Generic)
+		if !ok {
+			continue
+		}
+		resp, err := doer.DoCommand(ctx, cmd)
+		if errors.Is(err, generic.ErrUnimplemented) {
+			continue
+		}
+		return resp, err
+	}
+	return nil, generic.ErrUnimplemented
+}
+
 func (tp transformPipeline) Close(ctx context.Context) error {
 	var errs error
 	for _, src := range tp.pipeline {
@@ -0,0 +1,122 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"time"
+
+	"github.com/edaniels/gostream"
+	"github.com/fogleman/gg"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	defaultTimestampFormat = "2006-01-02 15:04:05"
+	defaultTextFontSize    = 20
+	defaultTextColor       = "#ffffff"
+)
+
+// textOverlayAttrs are the attributes for a text overlay transform.
+type textOverlayAttrs struct {
+	Text string `json:"text,omitempty"`
+	// Timestamp draws the time each image is read at after the text, in TimestampFormat, a Go time
+	// layout that defaults to "2006-01-02 15:04:05".
+	Timestamp       bool   `json:"timestamp,omitempty"`
+	TimestampFormat string `json:"timestamp_format,omitempty"`
+	// X and Y are the position of the top left of the text.
+	X        int     `json:"x_px,omitempty"`
+	Y        int     `json:"y_px,omitempty"`
+	FontSize float64 `json:"font_size,omitempty"`
+	Color    string  `json:"color,omitempty"`
+}
+
+type textOverlaySource struct {
+	originalStream gostream.VideoStream
+	attrs          *textOverlayAttrs
+	color          rimage.Color
+}
+
+// newTextOverlayTransform creates a new transform that draws text and the time on a color image.
+func newTextOverlayTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream == camera.DepthStream {
+		return nil, camera.UnspecifiedStream, errors.New("text_overlay transform needs a color image stream")
+	}
+	conf, err := config.TransformAttributeMapToStruct(&(textOverlayAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*textOverlayAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	if attrs.Text == "" && !attrs.Timestamp {
+		return nil, camera.UnspecifiedStream, errors.New("text_overlay transform needs text or timestamp")
+	}
+	if attrs.FontSize < 0 {
+		return nil, camera.UnspecifiedStream, errors.New("font_size for text_overlay transform cannot be negative")
+	}
+	if attrs.FontSize == 0 {
+		attrs.FontSize = defaultTextFontSize
+	}
+	if attrs.TimestampFormat == "" {
+		attrs.TimestampFormat = defaultTimestampFormat
+	}
+	if attrs.Color == "" {
+		attrs.Color = defaultTextColor
+	}
+	textColor, err := rimage.NewColorFromHex(attrs.Color)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, errors.Wrap(err, "invalid color for text_overlay transform")
+	}
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &textOverlaySource{gostream.NewEmbeddedVideoStream(source), attrs, textColor}
+	cam, err := camera.NewFromReader(ctx, reader, &cameraModel, camera.ColorStream)
+	return cam, camera.ColorStream, err
+}
+
+// Read draws the text on the next image of the original stream.
+func (tos *textOverlaySource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::text_overlay::Read")
+	defer span.End()
+	orig, release, err := tos.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if release != nil {
+		defer release()
+	}
+	text := tos.attrs.Text
+	if tos.attrs.Timestamp {
+		if text != "" {
+			text += " "
+		}
+		text += time.Now().Format(tos.attrs.TimestampFormat)
+	}
+	dc := gg.NewContextForImage(orig)
+	// text is drawn from its baseline, which is about a font size below its top
+	pt := image.Pt(tos.attrs.X, tos.attrs.Y+int(tos.attrs.FontSize))
+	rimage.DrawString(dc, text, pt, tos.color, tos.attrs.FontSize)
+	return dc.Image(), func() {}, nil
+}
+
+// Close closes the original stream.
+func (tos *textOverlaySource) Close(ctx context.Context) error {
+	return tos.originalStream.Close(ctx)
+}
@@ -0,0 +1,50 @@
+package transformpipeline
+
+import (
+	"context"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+)
+
+func TestTextOverlay(t *testing.T) {
+	source := newColorTestSource(color.RGBA{0, 0, 0, 255})
+	_, _, err := newTextOverlayTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs text or timestamp")
+	_, _, err = newTextOverlayTransform(context.Background(), source, camera.DepthStream, config.AttributeMap{"text": "hi"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs a color image stream")
+	_, _, err = newTextOverlayTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{
+		"text":  "hi",
+		"color": "white",
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid color")
+
+	ts, stream, err := newTextOverlayTransform(context.Background(), source, camera.ColorStream, config.AttributeMap{
+		"text":      "##",
+		"timestamp": true,
+		"font_size": 10,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	out, _, err := camera.ReadImage(context.Background(), ts)
+	test.That(t, err, test.ShouldBeNil)
+	var drawn bool
+	for y := 0; y < 10 && !drawn; y++ {
+		for x := 0; x < 20; x++ {
+			if r, _, _, _ := out.At(x, y).RGBA(); r > 0 {
+				drawn = true
+				break
+			}
+		}
+	}
+	test.That(t, drawn, test.ShouldBeTrue)
+	test.That(t, ts.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
@@ -0,0 +1,180 @@
+package transformpipeline
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/edaniels/gostream"
+	"github.com/fogleman/gg"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	rdkutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+const defaultTrailLength = 30
+
+// tracksAttrs is the attribute struct for tracks (the name of a tracking detector as found in the
+// vision service).
+type tracksAttrs struct {
+	DetectorName        string  `json:"detector_name"`
+	ConfidenceThreshold float64 `json:"confidence_threshold"`
+	// TrailLength is how many of its last positions are drawn behind each tracked object.
+	TrailLength int `json:"trail_length,omitempty"`
+}
+
+// tracksSource takes an image from the camera, and overlays the tracked detections from the
+// detector, with their track IDs and the trails of where they have been.
+type tracksSource struct {
+	stream       gostream.VideoStream
+	detectorName string
+	confFilter   objectdetection.Postprocessor
+	r            robot.Robot
+	trails       *trails
+}
+
+func newTracksTransform(
+	ctx context.Context,
+	source gostream.VideoSource, r robot.Robot, am config.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := config.TransformAttributeMapToStruct(&(tracksAttrs{}), am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	attrs, ok := conf.(*tracksAttrs)
+	if !ok {
+		return nil, camera.UnspecifiedStream, rdkutils.NewUnexpectedTypeError(attrs, conf)
+	}
+	trailLength := attrs.TrailLength
+	if trailLength == 0 {
+		trailLength = defaultTrailLength
+	}
+
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	tracks := &tracksSource{
+		gostream.NewEmbeddedVideoStream(source),
+		attrs.DetectorName,
+		objectdetection.NewScoreFilter(attrs.ConfidenceThreshold),
+		r,
+		newTrails(trailLength),
+	}
+	cam, err := camera.NewFromReader(ctx, tracks, &cameraModel, camera.ColorStream)
+	return cam, camera.ColorStream, err
+}
+
+// Read returns the image overlaid with the tracked detections and their trails.
+func (ts *tracksSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::tracks::Read")
+	defer span.End()
+	srv, err := vision.FirstFromRobot(ts.r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source_tracks cant find vision service: %w", err)
+	}
+	img, release, err := ts.stream.Next(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get next source image: %w", err)
+	}
+	dets, err := srv.Detections(ctx, img, ts.detectorName, map[string]interface{}{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get detections: %w", err)
+	}
+	for _, d := range dets {
+		if _, ok := d.(objectdetection.TrackedDetection); !ok {
+			return nil, nil, fmt.Errorf(
+				"detector %q returned a detection that is not tracked, source_tracks needs a tracking detector", ts.detectorName)
+		}
+	}
+	dets = ts.confFilter(dets)
+	res, err := objectdetection.Overlay(img, dets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not overlay bounding boxes: %w", err)
+	}
+	return drawTrails(res, ts.trails.update(dets)), release, nil
+}
+
+func (ts *tracksSource) Close(ctx context.Context) error {
+	return ts.stream.Close(ctx)
+}
+
+// trails are the recent centers of the bounding boxes of tracked objects.
+type trails struct {
+	mu     sync.Mutex
+	length int
+	byID   map[int][]image.Point
+	unseen map[int]int
+}
+
+func newTrails(length int) *trails {
+	return &trails{length: length, byID: map[int][]image.Point{}, unseen: map[int]int{}}
+}
+
+// update adds the centers of the tracked detections to their trails, forgetting the trails of
+// tracks unseen for as many images as a trail is long, and returns a copy of the trails.
+func (t *trails) update(dets []objectdetection.Detection) map[int][]image.Point {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seen := map[int]bool{}
+	for _, d := range dets {
+		tracked, ok := d.(objectdetection.TrackedDetection)
+		if !ok {
+			continue
+		}
+		id := tracked.TrackID()
+		box := d.BoundingBox()
+		trail := append(t.byID[id], image.Point{(box.Min.X + box.Max.X) / 2, (box.Min.Y + box.Max.Y) / 2})
+		if len(trail) > t.length {
+			trail = trail[len(trail)-t.length:]
+		}
+		t.byID[id] = trail
+		seen[id] = true
+	}
+	out := make(map[int][]image.Point, len(t.byID))
+	for id, trail := range t.byID {
+		if seen[id] {
+			t.unseen[id] = 0
+		} else {
+			t.unseen[id]++
+			if t.unseen[id] >= t.length {
+				delete(t.byID, id)
+				delete(t.unseen, id)
+				continue
+			}
+		}
+		out[id] = append([]image.Point(nil), trail...)
+	}
+	return out
+}
+
+// drawTrails draws the trails on the image as lines.
+func drawTrails(img image.Image, trails map[int][]image.Point) image.Image {
+	if len(trails) == 0 {
+		return img
+	}
+	dc := gg.NewContextForImage(img)
+	dc.SetColor(color.NRGBA{0, 255, 0, 255})
+	dc.SetLineWidth(2)
+	for _, trail := range trails {
+		for i := 1; i < len(trail); i++ {
+			dc.DrawLine(float64(trail[i-1].X), float64(trail[i-1].Y), float64(trail[i].X), float64(trail[i].Y))
+		}
+		dc.Stroke()
+	}
+	return dc.Image()
+}
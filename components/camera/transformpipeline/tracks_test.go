@@ -0,0 +1,69 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/edaniels/gostream"
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestTrails(t *testing.T) {
+	tracker, err := objectdetection.NewTracker(&objectdetection.TrackerConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	trails := newTrails(3)
+	now := time.Now()
+	var drawn map[int][]image.Point
+	for i := 0; i < 5; i++ {
+		tracked := tracker.Update([]objectdetection.Detection{
+			objectdetection.NewDetection(image.Rect(10*i, 0, 10*i+20, 20), 1, "a"),
+		}, now.Add(time.Duration(i)*time.Second))
+		dets := []objectdetection.Detection{tracked[0], objectdetection.NewDetection(image.Rect(0, 0, 5, 5), 1, "untracked")}
+		drawn = trails.update(dets)
+	}
+	test.That(t, drawn, test.ShouldHaveLength, 1)
+	test.That(t, drawn[1], test.ShouldResemble, []image.Point{{30, 10}, {40, 10}, {50, 10}})
+
+	img := image.NewRGBA(image.Rect(0, 0, 60, 20))
+	res := drawTrails(img, drawn)
+	test.That(t, res.At(45, 10), test.ShouldResemble, color.RGBA{0, 255, 0, 255})
+
+	// forgotten once unseen for as many images as it is long
+	test.That(t, trails.update(nil), test.ShouldHaveLength, 1)
+	test.That(t, trails.update(nil), test.ShouldHaveLength, 1)
+	test.That(t, trails.update(nil), test.ShouldBeEmpty)
+}
+
+func TestTracksSourceNeedsTrackedDetections(t *testing.T) {
+	injectVision := &inject.VisionService{}
+	injectVision.DetectionsFunc = func(ctx context.Context, img image.Image, detectorName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		return []objectdetection.Detection{objectdetection.NewDetection(image.Rect(0, 0, 5, 5), 1, "untracked")}, nil
+	}
+	r := &inject.Robot{}
+	r.MockResourcesFromMap(map[resource.Name]interface{}{vision.Named("vision"): injectVision})
+
+	source := gostream.NewVideoSource(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+		return image.NewRGBA(image.Rect(0, 0, 20, 20)), func() {}, nil
+	}), prop.Video{})
+	tracks := &tracksSource{
+		gostream.NewEmbeddedVideoStream(source),
+		"detector",
+		objectdetection.NewScoreFilter(0),
+		r,
+		newTrails(3),
+	}
+	defer tracks.Close(context.Background())
+	_, _, err := tracks.Read(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not tracked")
+}
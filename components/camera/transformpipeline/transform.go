@@ -25,9 +25,21 @@ const (
 	transformTypeOverlay         = transformType("overlay")
 	transformTypeUndistort       = transformType("undistort")
 	transformTypeDetections      = transformType("detections")
+	transformTypeTracks          = transformType("tracks")
 	transformTypeClassifications = transformType("classifications")
 	transformTypeDepthEdges      = transformType("depth_edges")
 	transformTypeDepthPreprocess = transformType("depth_preprocess")
+	transformTypeIntrinsicCalib  = transformType("intrinsic_calibration")
+	transformTypeCrop            = transformType("crop")
+	transformTypeFlip            = transformType("flip")
+	transformTypeGrayscale       = transformType("grayscale")
+	transformTypeColorSpace      = transformType("color_space")
+	transformTypeColorAdjust     = transformType("color_adjust")
+	transformTypeBlur            = transformType("blur")
+	transformTypeSharpen         = transformType("sharpen")
+	transformTypeMask            = transformType("mask")
+	transformTypeTextOverlay     = transformType("text_overlay")
+	transformTypeFrameRateLimit  = transformType("frame_rate_limit")
 )
 
 // emptyAttrs is for transforms that have no attribute fields.
@@ -77,6 +89,11 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 		&detectorAttrs{},
 		"Overlays object detections on the image. Can use any detector registered in the vision service.",
 	},
+	transformTypeTracks: {
+		string(transformTypeTracks),
+		&tracksAttrs{},
+		"Overlays tracked objects on the image with their track IDs and trails. Uses a tracking detector registered in the vision service.",
+	},
 	transformTypeClassifications: {
 		string(transformTypeClassifications),
 		&classifierAttrs{},
@@ -92,6 +109,61 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 		&emptyAttrs{},
 		"Applies some basic hole-filling and edge smoothing to a depth map.",
 	},
+	transformTypeIntrinsicCalib: {
+		string(transformTypeIntrinsicCalib),
+		&intrinsicCalibrationAttrs{},
+		"Overlays the corners of a checkerboard on the image, and captures views of it through DoCommand to calibrate the camera's intrinsics.",
+	},
+	transformTypeCrop: {
+		string(transformTypeCrop),
+		&cropAttrs{},
+		"Crops the image to the region of interest between the min and max corners, and shifts the intrinsics to match.",
+	},
+	transformTypeFlip: {
+		string(transformTypeFlip),
+		&flipAttrs{},
+		"Flips the image horizontally or vertically. Used when the camera sees the scene through a mirror.",
+	},
+	transformTypeGrayscale: {
+		string(transformTypeGrayscale),
+		&emptyAttrs{},
+		"Turns a color image into shades of gray.",
+	},
+	transformTypeColorSpace: {
+		string(transformTypeColorSpace),
+		&colorSpaceAttrs{},
+		"Converts a color image to the bgr, hsv or ycbcr color space, with the channels stored in place of red, green and blue.",
+	},
+	transformTypeColorAdjust: {
+		string(transformTypeColorAdjust),
+		&colorAdjustAttrs{},
+		"Adjusts the brightness, contrast and gamma of a color image.",
+	},
+	transformTypeBlur: {
+		string(transformTypeBlur),
+		&sigmaAttrs{},
+		"Applies a gaussian blur to a color image.",
+	},
+	transformTypeSharpen: {
+		string(transformTypeSharpen),
+		&sigmaAttrs{},
+		"Sharpens a color image.",
+	},
+	transformTypeMask: {
+		string(transformTypeMask),
+		&maskAttrs{},
+		"Fills polygons of the image with a color, or everything but the polygons if inverted. Masked out depth is set to 0.",
+	},
+	transformTypeTextOverlay: {
+		string(transformTypeTextOverlay),
+		&textOverlayAttrs{},
+		"Draws text and the current time on a color image.",
+	},
+	transformTypeFrameRateLimit: {
+		string(transformTypeFrameRateLimit),
+		&frameRateLimitAttrs{},
+		"Reads images no more often than the maximum frame rate, waiting for the next image to be due.",
+	},
 }
 
 // Transformation states the type of transformation and the attributes that are specific to the given type.
@@ -134,12 +206,36 @@ func buildTransform(
 		return newUndistortTransform(ctx, source, stream, tr.Attributes)
 	case transformTypeDetections:
 		return newDetectionsTransform(ctx, source, r, tr.Attributes)
+	case transformTypeTracks:
+		return newTracksTransform(ctx, source, r, tr.Attributes)
 	case transformTypeClassifications:
 		return newClassificationsTransform(ctx, source, r, tr.Attributes)
 	case transformTypeDepthEdges:
 		return newDepthEdgesTransform(ctx, source, tr.Attributes)
 	case transformTypeDepthPreprocess:
 		return newDepthPreprocessTransform(ctx, source)
+	case transformTypeIntrinsicCalib:
+		return newIntrinsicCalibrationTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeCrop:
+		return newCropTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeFlip:
+		return newFlipTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeGrayscale:
+		return newGrayscaleTransform(ctx, source, stream)
+	case transformTypeColorSpace:
+		return newColorSpaceTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeColorAdjust:
+		return newColorAdjustTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeBlur:
+		return newBlurTransform(ctx, source, stream, tr.Attributes, false)
+	case transformTypeSharpen:
+		return newBlurTransform(ctx, source, stream, tr.Attributes, true)
+	case transformTypeMask:
+		return newMaskTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeTextOverlay:
+		return newTextOverlayTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeFrameRateLimit:
+		return newFrameRateLimitTransform(ctx, source, stream, tr.Attributes)
 	default:
 		return nil, camera.UnspecifiedStream, errors.Errorf("do not know camera transform of type %q", tr.Type)
 	}
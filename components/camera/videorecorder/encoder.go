@@ -0,0 +1,92 @@
+package videorecorder
+
+import (
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapio"
+	viamutils "go.viam.com/utils"
+)
+
+// The container formats segments can be recorded in.
+const (
+	// formatMKV is motion JPEG in a Matroska file, which is written without any other tools.
+	formatMKV = "mkv"
+	// formatMP4 is H.264 in an MP4 file, which needs ffmpeg to be installed.
+	formatMP4 = "mp4"
+)
+
+// segmentEncoder writes the frames of one segment of video to a file.
+type segmentEncoder interface {
+	// WriteFrame adds the JPEG, taken at the given time, to the video.
+	WriteFrame(jpegData []byte, t time.Time) error
+	// Close finishes the file.
+	Close() error
+}
+
+// newSegmentEncoder creates the file of a segment of video of the given size.
+type newSegmentEncoder func(path string, width, height int) (segmentEncoder, error)
+
+func encoderForFormat(format string, fps float64, logger golog.Logger) (newSegmentEncoder, error) {
+	switch format {
+	case formatMKV, "":
+		return func(path string, width, height int) (segmentEncoder, error) {
+			return newMKVWriter(path, width, height)
+		}, nil
+	case formatMP4:
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			return nil, errors.Wrap(err, "recording mp4 needs ffmpeg")
+		}
+		return func(path string, width, height int) (segmentEncoder, error) {
+			return newFFMPEGEncoder(path, fps, logger)
+		}, nil
+	default:
+		return nil, errors.Errorf("no such video format %q, must be %q or %q", format, formatMKV, formatMP4)
+	}
+}
+
+// ffmpegEncoder pipes the JPEGs into ffmpeg to encode them as H.264. ffmpeg assumes the frames come
+// at a steady rate.
+type ffmpegEncoder struct {
+	in     *io.PipeWriter
+	done   chan error
+	output *zapio.Writer
+}
+
+func newFFMPEGEncoder(path string, fps float64, logger golog.Logger) (*ffmpegEncoder, error) {
+	out, in := io.Pipe()
+	output := &zapio.Writer{Log: logger.Desugar(), Level: zap.DebugLevel}
+	cmd := ffmpeg.Input("pipe:", ffmpeg.KwArgs{"format": "image2pipe", "vcodec": "mjpeg", "framerate": fps}).
+		Output(path, ffmpeg.KwArgs{"vcodec": "libx264", "pix_fmt": "yuv420p", "format": formatMP4}).
+		OverWriteOutput().
+		WithInput(out).
+		WithErrorOutput(output).
+		Compile()
+	if err := cmd.Start(); err != nil {
+		return nil, multierr.Combine(err, output.Close())
+	}
+	enc := &ffmpegEncoder{in: in, done: make(chan error, 1), output: output}
+	viamutils.PanicCapturingGo(func() {
+		err := cmd.Wait()
+		// stop writes from blocking if ffmpeg quit early
+		viamutils.UncheckedError(out.CloseWithError(errors.New("ffmpeg exited")))
+		enc.done <- err
+	})
+	return enc, nil
+}
+
+func (enc *ffmpegEncoder) WriteFrame(jpegData []byte, t time.Time) error {
+	_, err := enc.in.Write(jpegData)
+	return err
+}
+
+func (enc *ffmpegEncoder) Close() error {
+	err := enc.in.Close()
+	return multierr.Combine(err, <-enc.done, enc.output.Close())
+}
@@ -0,0 +1,259 @@
+package videorecorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// Matroska element IDs, see https://www.matroska.org/technical/elements.html.
+const (
+	mkvEBML               = 0x1A45DFA3
+	mkvEBMLVersion        = 0x4286
+	mkvEBMLReadVersion    = 0x42F7
+	mkvEBMLMaxIDLength    = 0x42F2
+	mkvEBMLMaxSizeLength  = 0x42F3
+	mkvDocType            = 0x4282
+	mkvDocTypeVersion     = 0x4287
+	mkvDocTypeReadVersion = 0x4285
+	mkvSegment            = 0x18538067
+	mkvInfo               = 0x1549A966
+	mkvTimecodeScale      = 0x2AD7B1
+	mkvDuration           = 0x4489
+	mkvMuxingApp          = 0x4D80
+	mkvWritingApp         = 0x5741
+	mkvTracks             = 0x1654AE6B
+	mkvTrackEntry         = 0xAE
+	mkvTrackNumber        = 0xD7
+	mkvTrackUID           = 0x73C5
+	mkvTrackType          = 0x83
+	mkvFlagLacing         = 0x9C
+	mkvCodecID            = 0x86
+	mkvVideo              = 0xE0
+	mkvPixelWidth         = 0xB0
+	mkvPixelHeight        = 0xBA
+	mkvCluster            = 0x1F43B675
+	mkvTimecode           = 0xE7
+	mkvSimpleBlock        = 0xA3
+)
+
+const (
+	// timecodes are in milliseconds.
+	mkvTimecodeScaleNs = int64(time.Millisecond)
+	// clusters are written out every second, so a crash loses at most a second of video.
+	mkvClusterDuration = time.Second
+	mkvVideoTrack      = 1
+	mkvMuxer           = "go.viam.com/rdk"
+)
+
+// mkvUnknownSize marks the size of the segment until it is closed, so that a file that is never
+// closed can still be played.
+var mkvUnknownSize = []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+
+// mkvWriter writes JPEG frames to a Matroska file as a motion JPEG video track.
+type mkvWriter struct {
+	f *os.File
+	// offsets in the file of the data of the segment, of its size and of the duration, which are
+	// filled in when the file is closed
+	segmentStart, segmentSizeOffset, durationOffset int64
+
+	start        time.Time
+	last         time.Duration
+	cluster      bytes.Buffer
+	clusterStart time.Duration
+	clusterOpen  bool
+}
+
+// newMKVWriter creates the file and writes the header for a video of the given size.
+func newMKVWriter(path string, width, height int) (*mkvWriter, error) {
+	//nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &mkvWriter{f: f}
+	if err := w.writeHeader(width, height); err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	return w, nil
+}
+
+func (w *mkvWriter) writeHeader(width, height int) error {
+	var buf bytes.Buffer
+	writeElement(&buf, mkvEBML, concat(
+		uintElement(mkvEBMLVersion, 1),
+		uintElement(mkvEBMLReadVersion, 1),
+		uintElement(mkvEBMLMaxIDLength, 4),
+		uintElement(mkvEBMLMaxSizeLength, 8),
+		stringElement(mkvDocType, "matroska"),
+		uintElement(mkvDocTypeVersion, 4),
+		uintElement(mkvDocTypeReadVersion, 2),
+	))
+	writeID(&buf, mkvSegment)
+	w.segmentSizeOffset = int64(buf.Len())
+	buf.Write(mkvUnknownSize)
+	w.segmentStart = int64(buf.Len())
+
+	info := concat(
+		uintElement(mkvTimecodeScale, uint64(mkvTimecodeScaleNs)),
+		floatElement(mkvDuration, 0),
+		stringElement(mkvMuxingApp, mkvMuxer),
+		stringElement(mkvWritingApp, mkvMuxer),
+	)
+	writeID(&buf, mkvInfo)
+	writeSize(&buf, uint64(len(info)))
+	// the duration is the first element of the info, after its ID and size
+	w.durationOffset = int64(buf.Len()) + int64(len(uintElement(mkvTimecodeScale, uint64(mkvTimecodeScaleNs)))) + 3
+	buf.Write(info)
+
+	writeElement(&buf, mkvTracks, element(mkvTrackEntry, concat(
+		uintElement(mkvTrackNumber, mkvVideoTrack),
+		uintElement(mkvTrackUID, mkvVideoTrack),
+		uintElement(mkvTrackType, 1),
+		uintElement(mkvFlagLacing, 0),
+		stringElement(mkvCodecID, "V_MJPEG"),
+		element(mkvVideo, concat(
+			uintElement(mkvPixelWidth, uint64(width)),
+			uintElement(mkvPixelHeight, uint64(height)),
+		)),
+	)))
+	_, err := w.f.Write(buf.Bytes())
+	return err
+}
+
+// WriteFrame adds the JPEG to the video at the time it was taken.
+func (w *mkvWriter) WriteFrame(jpegData []byte, t time.Time) error {
+	if w.start.IsZero() {
+		w.start = t
+	}
+	offset := t.Sub(w.start)
+	if offset < w.last {
+		return errors.Errorf("frame at %v is before the last frame at %v", offset, w.last)
+	}
+	if w.clusterOpen && offset-w.clusterStart >= mkvClusterDuration {
+		if err := w.flushCluster(); err != nil {
+			return err
+		}
+	}
+	if !w.clusterOpen {
+		w.clusterOpen = true
+		w.clusterStart = offset
+		w.cluster.Write(uintElement(mkvTimecode, uint64(offset.Milliseconds())))
+	}
+	block := make([]byte, 4, 4+len(jpegData))
+	block[0] = 0x80 | mkvVideoTrack
+	binary.BigEndian.PutUint16(block[1:3], uint16((offset - w.clusterStart).Milliseconds()))
+	// every motion JPEG frame is a keyframe
+	block[3] = 0x80
+	block = append(block, jpegData...)
+	writeElement(&w.cluster, mkvSimpleBlock, block)
+	w.last = offset
+	return nil
+}
+
+func (w *mkvWriter) flushCluster() error {
+	var buf bytes.Buffer
+	writeElement(&buf, mkvCluster, w.cluster.Bytes())
+	w.cluster.Reset()
+	w.clusterOpen = false
+	_, err := w.f.Write(buf.Bytes())
+	return err
+}
+
+// Close writes out the last frames, fills in the size and duration of the video and closes the file.
+func (w *mkvWriter) Close() error {
+	err := w.finish()
+	return multierr.Combine(err, w.f.Close())
+}
+
+func (w *mkvWriter) finish() error {
+	if w.clusterOpen {
+		if err := w.flushCluster(); err != nil {
+			return err
+		}
+	}
+	end, err := w.f.Seek(0, 1)
+	if err != nil {
+		return err
+	}
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(end-w.segmentStart))
+	size[0] = 0x01
+	if _, err := w.f.WriteAt(size, w.segmentSizeOffset); err != nil {
+		return err
+	}
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(float64(w.last.Milliseconds())))
+	_, err = w.f.WriteAt(duration, w.durationOffset)
+	return err
+}
+
+// writeID writes the element ID, whose length is given by its leading bits.
+func writeID(buf *bytes.Buffer, id uint32) {
+	switch {
+	case id > 0xFFFFFF:
+		buf.Write([]byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)})
+	case id > 0xFFFF:
+		buf.Write([]byte{byte(id >> 16), byte(id >> 8), byte(id)})
+	case id > 0xFF:
+		buf.Write([]byte{byte(id >> 8), byte(id)})
+	default:
+		buf.WriteByte(byte(id))
+	}
+}
+
+// writeSize writes the size as a variable length integer of as few bytes as it fits in.
+func writeSize(buf *bytes.Buffer, size uint64) {
+	n := 1
+	// a size with all of its bits set means unknown, so it needs another byte
+	for size >= 1<<(7*n)-1 {
+		n++
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := byte(size >> (8 * i))
+		if i == n-1 {
+			b |= 1 << (8 - n)
+		}
+		buf.WriteByte(b)
+	}
+}
+
+func writeElement(buf *bytes.Buffer, id uint32, data []byte) {
+	writeID(buf, id)
+	writeSize(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+func element(id uint32, data []byte) []byte {
+	var buf bytes.Buffer
+	writeElement(&buf, id, data)
+	return buf.Bytes()
+}
+
+func uintElement(id uint32, v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	for len(data) > 1 && data[0] == 0 {
+		data = data[1:]
+	}
+	return element(id, data)
+}
+
+func floatElement(id uint32, v float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(v))
+	return element(id, data)
+}
+
+func stringElement(id uint32, v string) []byte {
+	return element(id, []byte(v))
+}
+
+func concat(elements ...[]byte) []byte {
+	return bytes.Join(elements, nil)
+}
@@ -0,0 +1,139 @@
+package videorecorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// ebmlElement is an element read back from a Matroska file.
+type ebmlElement struct {
+	id   uint32
+	data []byte
+}
+
+// readVint reads a variable length integer, keeping its length marker if it is an ID.
+func readVint(data []byte, keepMarker bool) (uint64, int) {
+	n := 1
+	for n <= 8 && data[0]&(0x80>>(n-1)) == 0 {
+		n++
+	}
+	v := uint64(data[0])
+	if !keepMarker {
+		v &= uint64(0xFF >> n)
+	}
+	for i := 1; i < n; i++ {
+		v = v<<8 | uint64(data[i])
+	}
+	return v, n
+}
+
+func readElements(data []byte) []ebmlElement {
+	var elements []ebmlElement
+	for len(data) > 0 {
+		id, idLen := readVint(data, true)
+		size, sizeLen := readVint(data[idLen:], false)
+		start := idLen + sizeLen
+		elements = append(elements, ebmlElement{uint32(id), data[start : start+int(size)]})
+		data = data[start+int(size):]
+	}
+	return elements
+}
+
+func findElement(elements []ebmlElement, id uint32) []byte {
+	for _, e := range elements {
+		if e.id == id {
+			return e.data
+		}
+	}
+	return nil
+}
+
+func testJPEG(t *testing.T, shade uint8) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 32, 24))
+	for i := range img.Pix {
+		img.Pix[i] = shade
+	}
+	var buf bytes.Buffer
+	test.That(t, jpeg.Encode(&buf, img, nil), test.ShouldBeNil)
+	return buf.Bytes()
+}
+
+func TestMKVWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mkv")
+	w, err := newMKVWriter(path, 32, 24)
+	test.That(t, err, test.ShouldBeNil)
+	start := time.Now()
+	// two and a half seconds of frames, so they fill three clusters
+	for i := 0; i < 26; i++ {
+		test.That(t, w.WriteFrame(testJPEG(t, uint8(i*10)), start.Add(time.Duration(i)*100*time.Millisecond)), test.ShouldBeNil)
+	}
+	test.That(t, w.WriteFrame(testJPEG(t, 0), start), test.ShouldNotBeNil)
+	test.That(t, w.Close(), test.ShouldBeNil)
+
+	data, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	top := readElements(data)
+	test.That(t, top, test.ShouldHaveLength, 2)
+	test.That(t, string(findElement(readElements(top[0].data), mkvDocType)), test.ShouldEqual, "matroska")
+	test.That(t, top[1].id, test.ShouldEqual, mkvSegment)
+
+	segment := readElements(top[1].data)
+	info := readElements(findElement(segment, mkvInfo))
+	test.That(t, math.Float64frombits(binary.BigEndian.Uint64(findElement(info, mkvDuration))), test.ShouldEqual, 2500)
+	track := readElements(findElement(readElements(findElement(segment, mkvTracks)), mkvTrackEntry))
+	test.That(t, string(findElement(track, mkvCodecID)), test.ShouldEqual, "V_MJPEG")
+	video := readElements(findElement(track, mkvVideo))
+	test.That(t, findElement(video, mkvPixelWidth), test.ShouldResemble, []byte{32})
+
+	var clusters, frames int
+	var lastBlock []byte
+	for _, e := range segment {
+		if e.id != mkvCluster {
+			continue
+		}
+		clusters++
+		for _, c := range readElements(e.data) {
+			if c.id == mkvSimpleBlock {
+				frames++
+				lastBlock = c.data
+			}
+		}
+	}
+	test.That(t, clusters, test.ShouldEqual, 3)
+	test.That(t, frames, test.ShouldEqual, 26)
+	// the last frame is half a second into the last cluster
+	test.That(t, binary.BigEndian.Uint16(lastBlock[1:3]), test.ShouldEqual, 500)
+	img, err := jpeg.Decode(bytes.NewReader(lastBlock[4:]))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, color.GrayModel.Convert(img.At(5, 5)).(color.Gray).Y, test.ShouldAlmostEqual, 250, 2)
+}
+
+func TestWriteSize(t *testing.T) {
+	for _, tc := range []struct {
+		size     uint64
+		expected []byte
+	}{
+		{0, []byte{0x80}},
+		{126, []byte{0xFE}},
+		// all ones means unknown, so 127 needs two bytes
+		{127, []byte{0x40, 0x7F}},
+		{1000, []byte{0x43, 0xE8}},
+		{1 << 20, []byte{0x30, 0x00, 0x00}},
+		{1 << 21, []byte{0x10, 0x20, 0x00, 0x00}},
+	} {
+		var buf bytes.Buffer
+		writeSize(&buf, tc.size)
+		test.That(t, buf.Bytes(), test.ShouldResemble, tc.expected)
+	}
+}
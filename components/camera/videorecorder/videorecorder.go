@@ -0,0 +1,465 @@
+// Package videorecorder defines a camera that records the stream of another camera to video files.
+package videorecorder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	viamutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("video_recorder")
+
+// The DoCommand commands of the video recorder, given as the "command" key.
+const (
+	// StartCommand starts recording, beginning with the pre-roll.
+	StartCommand = "start"
+	// StopCommand stops recording and finishes the current segment.
+	StopCommand = "stop"
+	// StatusCommand returns whether the camera is recording and the segment being written.
+	StatusCommand = "status"
+)
+
+const (
+	defaultFPS            = 10.
+	defaultSegmentSeconds = 60.
+	defaultJPEGQuality    = 75
+	// segmentTimeFormat names segments by when they start, in an order that sorts.
+	segmentTimeFormat = "2006-01-02T15-04-05.000Z"
+)
+
+var viamRecordingsDotDir = filepath.Join(os.Getenv("HOME"), ".viam", "recordings")
+
+func init() {
+	registry.RegisterComponent(camera.Subtype, model,
+		registry.Component{Constructor: func(ctx context.Context, deps registry.Dependencies,
+			config config.Component, logger golog.Logger,
+		) (interface{}, error) {
+			attrs, ok := config.ConvertedAttributes.(*recorderAttrs)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attrs, config.ConvertedAttributes)
+			}
+			source, err := camera.FromDependencies(deps, attrs.Source)
+			if err != nil {
+				return nil, fmt.Errorf("no source camera (%s): %w", attrs.Source, err)
+			}
+			return newVideoRecorder(ctx, config.Name, source, attrs, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(camera.Subtype, model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf recorderAttrs
+			attrs, err := config.TransformAttributeMapToStruct(&conf, attributes)
+			if err != nil {
+				return nil, err
+			}
+			result, ok := attrs.(*recorderAttrs)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(result, attrs)
+			}
+			return result, nil
+		}, &recorderAttrs{})
+}
+
+// recorderAttrs is the attribute struct for video recorders.
+type recorderAttrs struct {
+	Source string `json:"camera_name"`
+	// Dir is where segments are written, ~/.viam/recordings/<name> by default.
+	Dir string `json:"dir,omitempty"`
+	// Format is mkv, the default, or mp4.
+	Format         string  `json:"format,omitempty"`
+	FPS            float64 `json:"fps,omitempty"`
+	SegmentSeconds float64 `json:"segment_seconds,omitempty"`
+	// PreRollSeconds of video from before recording starts are kept and added to the recording.
+	PreRollSeconds float64 `json:"pre_roll_seconds,omitempty"`
+	// MaxSegments is how many finished segments are kept in Dir, deleting the oldest ones. All of
+	// them are kept if it is 0.
+	MaxSegments int `json:"max_segments,omitempty"`
+	// SyncDir is where finished segments are moved, such as a directory in the data manager's
+	// additional_sync_paths, so that they are uploaded.
+	SyncDir       string `json:"sync_dir,omitempty"`
+	RecordOnStart bool   `json:"record_on_start,omitempty"`
+	JPEGQuality   int    `json:"jpeg_quality,omitempty"`
+}
+
+func (cfg *recorderAttrs) Validate(path string) ([]string, error) {
+	if cfg.Source == "" {
+		return nil, viamutils.NewConfigValidationFieldRequiredError(path, "camera_name")
+	}
+	if cfg.FPS < 0 {
+		return nil, viamutils.NewConfigValidationError(path, errors.New("fps cannot be negative"))
+	}
+	if cfg.SegmentSeconds < 0 {
+		return nil, viamutils.NewConfigValidationError(path, errors.New("segment_seconds cannot be negative"))
+	}
+	if cfg.PreRollSeconds < 0 {
+		return nil, viamutils.NewConfigValidationError(path, errors.New("pre_roll_seconds cannot be negative"))
+	}
+	if cfg.MaxSegments < 0 {
+		return nil, viamutils.NewConfigValidationError(path, errors.New("max_segments cannot be negative"))
+	}
+	if cfg.JPEGQuality < 0 || cfg.JPEGQuality > 100 {
+		return nil, viamutils.NewConfigValidationError(path, errors.New("jpeg_quality must be between 1 and 100"))
+	}
+	return []string{cfg.Source}, nil
+}
+
+// frame is an image from the camera, encoded as a JPEG.
+type frame struct {
+	data          []byte
+	t             time.Time
+	width, height int
+}
+
+// videoRecorder streams the images of its source camera as they are, and records them to segments of
+// video while it is told to.
+type videoRecorder struct {
+	name       string
+	source     camera.Camera
+	stream     gostream.VideoStream
+	newEncoder newSegmentEncoder
+	ext        string
+	dir        string
+	syncDir    string
+	fps        float64
+	segmentLen time.Duration
+	preRollLen time.Duration
+	maxSegs    int
+	quality    int
+	logger     golog.Logger
+
+	cancelCtx               context.Context
+	cancel                  context.CancelFunc
+	activeBackgroundWorkers sync.WaitGroup
+
+	mu           sync.Mutex
+	recording    bool
+	preRoll      []frame
+	segment      segmentEncoder
+	segmentPath  string
+	segmentStart time.Time
+	lastErr      error
+}
+
+func newVideoRecorder(
+	ctx context.Context, name string, source camera.Camera, attrs *recorderAttrs, logger golog.Logger,
+) (camera.Camera, error) {
+	vr := &videoRecorder{
+		name:       name,
+		source:     source,
+		dir:        attrs.Dir,
+		syncDir:    attrs.SyncDir,
+		fps:        attrs.FPS,
+		segmentLen: time.Duration(attrs.SegmentSeconds * float64(time.Second)),
+		preRollLen: time.Duration(attrs.PreRollSeconds * float64(time.Second)),
+		maxSegs:    attrs.MaxSegments,
+		quality:    attrs.JPEGQuality,
+		logger:     logger,
+	}
+	if vr.dir == "" {
+		vr.dir = filepath.Join(viamRecordingsDotDir, name)
+	}
+	if vr.fps == 0 {
+		vr.fps = defaultFPS
+	}
+	if vr.segmentLen == 0 {
+		vr.segmentLen = time.Duration(defaultSegmentSeconds * float64(time.Second))
+	}
+	if vr.quality == 0 {
+		vr.quality = defaultJPEGQuality
+	}
+	vr.ext = attrs.Format
+	if vr.ext == "" {
+		vr.ext = formatMKV
+	}
+	var err error
+	if vr.newEncoder, err = encoderForFormat(vr.ext, vr.fps, logger); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{vr.dir, vr.syncDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	props, err := source.Properties(ctx)
+	if err != nil {
+		return nil, camera.NewPropertiesError("source camera")
+	}
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = props.IntrinsicParams
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	vr.stream = gostream.NewEmbeddedVideoStream(source)
+	vr.recording = attrs.RecordOnStart
+
+	vr.cancelCtx, vr.cancel = context.WithCancel(context.Background())
+	vr.activeBackgroundWorkers.Add(1)
+	viamutils.ManagedGo(vr.captureLoop, vr.activeBackgroundWorkers.Done)
+	return camera.NewFromReader(ctx, vr, &cameraModel, props.ImageType)
+}
+
+// Read returns the next image of the source camera.
+func (vr *videoRecorder) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "videorecorder::videoRecorder::Read")
+	defer span.End()
+	return vr.stream.Next(ctx)
+}
+
+// captureLoop captures frames at the configured rate while they are being recorded or kept for the
+// pre-roll.
+func (vr *videoRecorder) captureLoop() {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / vr.fps))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-vr.cancelCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		vr.mu.Lock()
+		wanted := vr.recording || vr.preRollLen > 0
+		vr.mu.Unlock()
+		if !wanted {
+			continue
+		}
+		f, err := vr.capture(vr.cancelCtx)
+		if err != nil {
+			if vr.cancelCtx.Err() == nil {
+				vr.logger.Debugw("could not capture frame to record", "error", err)
+			}
+			continue
+		}
+		vr.addFrame(f)
+	}
+}
+
+func (vr *videoRecorder) capture(ctx context.Context) (frame, error) {
+	img, release, err := camera.ReadImage(ctx, vr.source)
+	if err != nil {
+		return frame{}, err
+	}
+	defer release()
+	t := time.Now()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: vr.quality}); err != nil {
+		return frame{}, err
+	}
+	return frame{data: buf.Bytes(), t: t, width: img.Bounds().Dx(), height: img.Bounds().Dy()}, nil
+}
+
+// addFrame records the frame, or keeps it for the pre-roll when not recording.
+func (vr *videoRecorder) addFrame(f frame) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if !vr.recording {
+		vr.preRoll = append(vr.preRoll, f)
+		drop := 0
+		for drop < len(vr.preRoll) && f.t.Sub(vr.preRoll[drop].t) > vr.preRollLen {
+			drop++
+		}
+		vr.preRoll = vr.preRoll[drop:]
+		return
+	}
+	vr.writeFrame(f)
+}
+
+// writeFrame must be called with the lock held.
+func (vr *videoRecorder) writeFrame(f frame) {
+	if vr.segment != nil && (f.t.Sub(vr.segmentStart) >= vr.segmentLen || f.t.Before(vr.segmentStart)) {
+		vr.finishSegment()
+	}
+	if vr.segment == nil {
+		path := filepath.Join(vr.dir, fmt.Sprintf("%s_%s.%s", vr.name, f.t.UTC().Format(segmentTimeFormat), vr.ext))
+		enc, err := vr.newEncoder(path, f.width, f.height)
+		if err != nil {
+			vr.setErr(errors.Wrap(err, "could not start segment"))
+			return
+		}
+		vr.segment, vr.segmentPath, vr.segmentStart = enc, path, f.t
+	}
+	if err := vr.segment.WriteFrame(f.data, f.t); err != nil {
+		vr.setErr(errors.Wrapf(err, "could not write to segment %q", vr.segmentPath))
+		// start over with a new segment rather than keep writing to a broken one
+		vr.finishSegment()
+	}
+}
+
+// finishSegment closes the current segment, hands it off to the sync directory and deletes the
+// oldest segments. It must be called with the lock held.
+func (vr *videoRecorder) finishSegment() {
+	if vr.segment == nil {
+		return
+	}
+	path := vr.segmentPath
+	err := vr.segment.Close()
+	vr.segment, vr.segmentPath = nil, ""
+	if err != nil {
+		vr.setErr(errors.Wrapf(err, "could not finish segment %q", path))
+	}
+	if vr.syncDir != "" {
+		if err := moveFile(path, filepath.Join(vr.syncDir, filepath.Base(path))); err != nil {
+			vr.setErr(errors.Wrapf(err, "could not move segment %q to sync directory", path))
+		}
+	}
+	if err := vr.deleteOldSegments(); err != nil {
+		vr.setErr(errors.Wrap(err, "could not delete old segments"))
+	}
+}
+
+func (vr *videoRecorder) setErr(err error) {
+	vr.lastErr = err
+	vr.logger.Error(err)
+}
+
+// deleteOldSegments deletes the oldest finished segments beyond the maximum. It must be called with
+// the lock held.
+func (vr *videoRecorder) deleteOldSegments() error {
+	if vr.maxSegs == 0 {
+		return nil
+	}
+	segments, err := vr.finishedSegments()
+	if err != nil {
+		return err
+	}
+	var errs error
+	for len(segments) > vr.maxSegs {
+		errs = multierr.Combine(errs, os.Remove(filepath.Join(vr.dir, segments[0])))
+		segments = segments[1:]
+	}
+	return errs
+}
+
+// finishedSegments returns the names of the finished segments in the directory, oldest first. It must
+// be called with the lock held.
+func (vr *videoRecorder) finishedSegments() ([]string, error) {
+	entries, err := os.ReadDir(vr.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, vr.name+"_") || filepath.Ext(name) != "."+vr.ext {
+			continue
+		}
+		if filepath.Join(vr.dir, name) == vr.segmentPath {
+			continue
+		}
+		segments = append(segments, name)
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// moveFile renames the file, or copies it when it is on another filesystem. The copy is written under a
+// hidden name first so the destination directory never holds part of a file.
+func moveFile(from, to string) (err error) {
+	if os.Rename(from, to) == nil {
+		return nil
+	}
+	//nolint:gosec
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer viamutils.UncheckedErrorFunc(src.Close)
+	tmp := filepath.Join(filepath.Dir(to), "."+filepath.Base(to))
+	//nolint:gosec
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return multierr.Combine(err, dst.Close(), os.Remove(tmp))
+	}
+	if err := dst.Close(); err != nil {
+		return multierr.Combine(err, os.Remove(tmp))
+	}
+	if err := os.Rename(tmp, to); err != nil {
+		return multierr.Combine(err, os.Remove(tmp))
+	}
+	return os.Remove(from)
+}
+
+// DoCommand starts and stops recording.
+func (vr *videoRecorder) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	switch name {
+	case StartCommand:
+		if !vr.recording {
+			vr.recording = true
+			preRoll := vr.preRoll
+			vr.preRoll = nil
+			for _, f := range preRoll {
+				vr.writeFrame(f)
+			}
+		}
+	case StopCommand:
+		vr.recording = false
+		vr.finishSegment()
+	case StatusCommand:
+	default:
+		return nil, errors.Errorf("no such command: %v", name)
+	}
+	return vr.status()
+}
+
+// status must be called with the lock held.
+func (vr *videoRecorder) status() (map[string]interface{}, error) {
+	segments, err := vr.finishedSegments()
+	if err != nil {
+		return nil, err
+	}
+	status := map[string]interface{}{
+		"recording":         vr.recording,
+		"segment":           vr.segmentPath,
+		"finished_segments": len(segments),
+		"pre_roll_frames":   len(vr.preRoll),
+	}
+	if vr.lastErr != nil {
+		status["last_error"] = vr.lastErr.Error()
+	}
+	return status, nil
+}
+
+// Close stops recording, finishing the current segment.
+func (vr *videoRecorder) Close(ctx context.Context) error {
+	vr.cancel()
+	vr.activeBackgroundWorkers.Wait()
+	vr.mu.Lock()
+	vr.finishSegment()
+	vr.mu.Unlock()
+	return vr.stream.Close(ctx)
+}
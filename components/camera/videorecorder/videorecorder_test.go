@@ -0,0 +1,196 @@
+package videorecorder
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/rimage"
+)
+
+// fakeEncoder counts the frames written to a segment.
+type fakeEncoder struct {
+	path   string
+	frames *map[string]int
+}
+
+func (enc *fakeEncoder) WriteFrame(jpegData []byte, t time.Time) error {
+	(*enc.frames)[enc.path]++
+	return nil
+}
+
+func (enc *fakeEncoder) Close() error {
+	return nil
+}
+
+// newTestRecorder makes a recorder of 10 second segments, that keeps 3 of them and has 2 seconds of
+// pre-roll, without its capture loop.
+func newTestRecorder(t *testing.T) (*videoRecorder, map[string]int) {
+	t.Helper()
+	frames := map[string]int{}
+	vr := &videoRecorder{
+		name: "cam",
+		dir:  t.TempDir(),
+		ext:  formatMKV,
+		newEncoder: func(path string, width, height int) (segmentEncoder, error) {
+			if err := os.WriteFile(path, nil, 0o600); err != nil {
+				return nil, err
+			}
+			return &fakeEncoder{path: path, frames: &frames}, nil
+		},
+		segmentLen: 10 * time.Second,
+		preRollLen: 2 * time.Second,
+		maxSegs:    3,
+		logger:     golog.NewTestLogger(t),
+	}
+	return vr, frames
+}
+
+func TestVideoRecorderSegments(t *testing.T) {
+	ctx := context.Background()
+	vr, frames := newTestRecorder(t)
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds float64) frame {
+		return frame{t: start.Add(time.Duration(seconds * float64(time.Second))), width: 4, height: 3}
+	}
+
+	// before recording, only the last 2 seconds of frames are kept
+	for s := 0.; s < 5; s += 0.5 {
+		vr.addFrame(at(s))
+	}
+	status, err := vr.DoCommand(ctx, map[string]interface{}{"command": StatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status, test.ShouldResemble, map[string]interface{}{
+		"recording": false, "segment": "", "finished_segments": 0, "pre_roll_frames": 5,
+	})
+
+	// recording starts with the pre-roll
+	status, err = vr.DoCommand(ctx, map[string]interface{}{"command": StartCommand})
+	test.That(t, err, test.ShouldBeNil)
+	firstPath := filepath.Join(vr.dir, "cam_2023-03-01T12-00-02.500Z.mkv")
+	test.That(t, status["segment"], test.ShouldEqual, firstPath)
+	test.That(t, status["pre_roll_frames"], test.ShouldEqual, 0)
+	test.That(t, frames[firstPath], test.ShouldEqual, 5)
+
+	// segments are cut at the first frame 10 seconds in, and only the newest 3 finished ones are kept
+	for s := 5.; s < 50; s++ {
+		vr.addFrame(at(s))
+	}
+	test.That(t, frames[firstPath], test.ShouldEqual, 13)
+	status, err = vr.DoCommand(ctx, map[string]interface{}{"command": StatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["recording"], test.ShouldBeTrue)
+	test.That(t, status["segment"], test.ShouldEqual, filepath.Join(vr.dir, "cam_2023-03-01T12-00-43.000Z.mkv"))
+	test.That(t, status["finished_segments"], test.ShouldEqual, 3)
+	_, err = os.Stat(firstPath)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	status, err = vr.DoCommand(ctx, map[string]interface{}{"command": StopCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["recording"], test.ShouldBeFalse)
+	test.That(t, status["segment"], test.ShouldEqual, "")
+	test.That(t, status["finished_segments"], test.ShouldEqual, 3)
+	segments, err := vr.finishedSegments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldResemble, []string{
+		"cam_2023-03-01T12-00-23.000Z.mkv", "cam_2023-03-01T12-00-33.000Z.mkv", "cam_2023-03-01T12-00-43.000Z.mkv",
+	})
+
+	_, err = vr.DoCommand(ctx, map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = vr.DoCommand(ctx, map[string]interface{}{"command": "nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestVideoRecorderSyncAndErrors(t *testing.T) {
+	ctx := context.Background()
+	vr, _ := newTestRecorder(t)
+	vr.syncDir = t.TempDir()
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := vr.DoCommand(ctx, map[string]interface{}{"command": StartCommand})
+	test.That(t, err, test.ShouldBeNil)
+	vr.addFrame(frame{t: start})
+	_, err = vr.DoCommand(ctx, map[string]interface{}{"command": StopCommand})
+	test.That(t, err, test.ShouldBeNil)
+	// finished segments are handed off to be synced
+	_, err = os.Stat(filepath.Join(vr.syncDir, "cam_2023-03-01T12-00-00.000Z.mkv"))
+	test.That(t, err, test.ShouldBeNil)
+	segments, err := vr.finishedSegments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldBeEmpty)
+
+	// failures are reported, and recording carries on
+	vr.newEncoder = func(path string, width, height int) (segmentEncoder, error) {
+		return nil, errors.New("disk full")
+	}
+	_, err = vr.DoCommand(ctx, map[string]interface{}{"command": StartCommand})
+	test.That(t, err, test.ShouldBeNil)
+	vr.addFrame(frame{t: start.Add(time.Second)})
+	status, err := vr.DoCommand(ctx, map[string]interface{}{"command": StatusCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["recording"], test.ShouldBeTrue)
+	test.That(t, status["last_error"], test.ShouldContainSubstring, "disk full")
+}
+
+func TestVideoRecorder(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	img := rimage.NewImage(64, 48)
+	source, err := camera.NewFromReader(ctx, &videosource.StaticSource{ColorImg: img}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	defer source.Close(ctx)
+
+	attrs := &recorderAttrs{Source: "source", Dir: t.TempDir(), FPS: 50, RecordOnStart: true}
+	deps, err := attrs.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"source"})
+	cam, err := newVideoRecorder(ctx, "recorder", source, attrs, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// images pass through
+	out, _, err := camera.ReadImage(ctx, cam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Bounds(), test.ShouldResemble, image.Rect(0, 0, 64, 48))
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := cam.DoCommand(ctx, map[string]interface{}{"command": StatusCommand})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["segment"], test.ShouldNotEqual, "")
+	})
+	status, err := cam.DoCommand(ctx, map[string]interface{}{"command": StopCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["finished_segments"], test.ShouldEqual, 1)
+	test.That(t, cam.Close(ctx), test.ShouldBeNil)
+
+	entries, err := os.ReadDir(attrs.Dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 1)
+	data, err := os.ReadFile(filepath.Join(attrs.Dir, entries[0].Name()))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readElements(data)[1].id, test.ShouldEqual, mkvSegment)
+
+	attrs.Format = "avi"
+	_, err = newVideoRecorder(ctx, "recorder", source, attrs, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	for _, bad := range []*recorderAttrs{
+		{},
+		{Source: "source", FPS: -1},
+		{Source: "source", PreRollSeconds: -1},
+		{Source: "source", JPEGQuality: 101},
+	} {
+		_, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
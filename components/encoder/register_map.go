@@ -0,0 +1,226 @@
+package encoder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/regmap"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var regmapModelName = resource.NewDefaultModel("register-map")
+
+// how often a wrapping count is read so that no wrap is missed.
+const regmapPollInterval = 20 * time.Millisecond
+
+func init() {
+	registry.RegisterComponent(
+		Subtype,
+		regmapModelName,
+		registry.Component{
+			Constructor: func(
+				ctx context.Context,
+				deps registry.Dependencies,
+				config config.Component,
+				logger golog.Logger,
+			) (interface{}, error) {
+				attr, ok := config.ConvertedAttributes.(*RegisterMapConfig)
+				if !ok {
+					return nil, rdkutils.NewUnexpectedTypeError(attr, config.ConvertedAttributes)
+				}
+				b, err := board.FromDependencies(deps, attr.Board)
+				if err != nil {
+					return nil, err
+				}
+				return newRegisterMapEncoder(ctx, b, attr, logger)
+			},
+		},
+	)
+	config.RegisterComponentAttributeMapConverter(
+		Subtype,
+		regmapModelName,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf RegisterMapConfig
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: &conf})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(attributes); err != nil {
+				return nil, err
+			}
+			return &conf, nil
+		},
+		&RegisterMapConfig{},
+	)
+}
+
+// RegisterMapConfig describes an encoder on an I2C or SPI chip by a map of its registers.
+type RegisterMapConfig struct {
+	regmap.Config
+	// Ticks is the register map value holding the count.
+	Ticks string `json:"ticks"`
+	// TicksPerRotation is set for counts that wrap around to zero once per rotation, such as
+	// those of absolute encoders. The count is then polled and unwrapped in the background.
+	TicksPerRotation int `json:"ticks_per_rotation,omitempty"`
+}
+
+// Validate checks the attributes of an initialized config
+// for proper values.
+func (conf *RegisterMapConfig) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	if conf.Ticks == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "ticks")
+	}
+	if !conf.Names()[conf.Ticks] {
+		return nil, utils.NewConfigValidationError(path, errors.Errorf("ticks %q is not in the register map", conf.Ticks))
+	}
+	if conf.TicksPerRotation < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("ticks_per_rotation cannot be negative"))
+	}
+	return []string{conf.Board}, nil
+}
+
+// RegisterMapEncoder is an encoder whose count is read from a chip described by a register map.
+type RegisterMapEncoder struct {
+	mu     sync.Mutex
+	device *regmap.Device
+	attr   *RegisterMapConfig
+	logger golog.Logger
+
+	// only used for wrapping counts
+	lastRaw   float64
+	total     float64
+	readErr   error
+	zero      float64
+	cancelCtx context.Context
+	cancel    context.CancelFunc
+
+	activeBackgroundWorkers sync.WaitGroup
+	generic.Unimplemented
+}
+
+func newRegisterMapEncoder(
+	ctx context.Context, b board.Board, attr *RegisterMapConfig, logger golog.Logger,
+) (*RegisterMapEncoder, error) {
+	device, err := regmap.NewDevice(ctx, b, &attr.Config)
+	if err != nil {
+		return nil, err
+	}
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	enc := &RegisterMapEncoder{
+		device:    device,
+		attr:      attr,
+		logger:    logger,
+		cancelCtx: cancelCtx,
+		cancel:    cancel,
+	}
+	raw, err := enc.readRaw(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	enc.lastRaw = raw
+	enc.total = raw
+	if attr.TicksPerRotation > 0 {
+		enc.startPolling()
+	}
+	return enc, nil
+}
+
+func (enc *RegisterMapEncoder) readRaw(ctx context.Context) (float64, error) {
+	values, err := enc.device.Read(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return values[enc.attr.Ticks], nil
+}
+
+func (enc *RegisterMapEncoder) startPolling() {
+	enc.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for {
+			if !utils.SelectContextOrWait(enc.cancelCtx, regmapPollInterval) {
+				return
+			}
+			raw, err := enc.readRaw(enc.cancelCtx)
+			enc.mu.Lock()
+			enc.readErr = err
+			if err == nil {
+				enc.total += unwrapDelta(raw-enc.lastRaw, float64(enc.attr.TicksPerRotation))
+				enc.lastRaw = raw
+			}
+			enc.mu.Unlock()
+		}
+	}, enc.activeBackgroundWorkers.Done)
+}
+
+// unwrapDelta takes the change between two readings of a count that wraps every period and
+// returns the smallest change that explains it.
+func unwrapDelta(delta, period float64) float64 {
+	switch {
+	case delta > period/2:
+		return delta - period
+	case delta < -period/2:
+		return delta + period
+	default:
+		return delta
+	}
+}
+
+// TicksCount returns the count since the last reset.
+func (enc *RegisterMapEncoder) TicksCount(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if enc.attr.TicksPerRotation > 0 {
+		enc.mu.Lock()
+		defer enc.mu.Unlock()
+		if enc.readErr != nil {
+			return 0, enc.readErr
+		}
+		return enc.total - enc.zero, nil
+	}
+	raw, err := enc.readRaw(ctx)
+	if err != nil {
+		return 0, err
+	}
+	enc.mu.Lock()
+	defer enc.mu.Unlock()
+	return raw - enc.zero, nil
+}
+
+// Reset makes the current count read as offset. Nothing is written to the chip.
+func (enc *RegisterMapEncoder) Reset(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	var current float64
+	if enc.attr.TicksPerRotation > 0 {
+		enc.mu.Lock()
+		current = enc.total
+		enc.mu.Unlock()
+	} else {
+		raw, err := enc.readRaw(ctx)
+		if err != nil {
+			return err
+		}
+		current = raw
+	}
+	enc.mu.Lock()
+	defer enc.mu.Unlock()
+	enc.zero = current - offset
+	return nil
+}
+
+// Close stops polling the chip.
+func (enc *RegisterMapEncoder) Close() {
+	enc.cancel()
+	enc.activeBackgroundWorkers.Wait()
+}
@@ -0,0 +1,98 @@
+package encoder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/regmap"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestUnwrapDelta(t *testing.T) {
+	test.That(t, unwrapDelta(10, 360), test.ShouldEqual, 10)
+	test.That(t, unwrapDelta(-10, 360), test.ShouldEqual, -10)
+	test.That(t, unwrapDelta(-350, 360), test.ShouldEqual, 10)
+	test.That(t, unwrapDelta(350, 360), test.ShouldEqual, -10)
+}
+
+func TestRegisterMapEncoder(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	chip := &simdevice.Registers{}
+	bus.Attach(0x40, chip)
+	b := &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) { return bus, true }}
+
+	// the 14 bit angle of an AS5048, split over two registers
+	attr := &RegisterMapConfig{
+		Config: regmap.Config{
+			Board:   "b",
+			I2CBus:  "main",
+			I2CAddr: 0x40,
+			Fields:  []regmap.FieldConfig{{Name: "angle", Register: 0xFE, Length: 2, Bits: 14}},
+		},
+		Ticks: "angle",
+	}
+	deps, err := attr.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"b"})
+
+	t.Run("counting", func(t *testing.T) {
+		chip.Set(0xFE, 0x00, 0x64)
+		enc, err := newRegisterMapEncoder(ctx, b, attr, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer enc.Close()
+
+		ticks, err := enc.TicksCount(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ticks, test.ShouldEqual, 100)
+
+		test.That(t, enc.Reset(ctx, 5, nil), test.ShouldBeNil)
+		chip.Set(0xFE, 0x00, 0x70)
+		ticks, err = enc.TicksCount(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ticks, test.ShouldEqual, 17)
+	})
+
+	t.Run("wrapping", func(t *testing.T) {
+		wrapping := *attr
+		wrapping.TicksPerRotation = 1 << 14
+		chip.Set(0xFE, 0x3F, 0x00)
+		enc, err := newRegisterMapEncoder(ctx, b, &wrapping, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer enc.Close()
+		test.That(t, enc.Reset(ctx, 0, nil), test.ShouldBeNil)
+
+		// forwards past zero
+		chip.Set(0xFE, 0x00, 0x10)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			ticks, err := enc.TicksCount(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, ticks, test.ShouldEqual, 0x100+0x10)
+		})
+
+		// and back again
+		chip.Set(0xFE, 0x3E, 0x00)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			ticks, err := enc.TicksCount(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, ticks, test.ShouldEqual, -0x100)
+		})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		bad := *attr
+		bad.Ticks = "position"
+		_, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `"position" is not in the register map`)
+	})
+}
@@ -0,0 +1,552 @@
+// Package cia402 implements a motor driven by a CANopen servo drive following the CiA-402 device profile.
+// The drive is commanded over SDO in profile velocity and profile position modes.
+package cia402
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("cia402")
+
+// Objects of the CiA-402 object dictionary.
+const (
+	objErrorCode        = 0x603F
+	objControlword      = 0x6040
+	objStatusword       = 0x6041
+	objModesOfOperation = 0x6060
+	objPositionActual   = 0x6064
+	objTargetPosition   = 0x607A
+	objProfileVelocity  = 0x6081
+	objTargetVelocity   = 0x60FF
+)
+
+// Modes of operation.
+const (
+	modeProfilePosition = 1
+	modeProfileVelocity = 3
+)
+
+const (
+	sdoTimeoutDefault    = 500 * time.Millisecond
+	stateChangeWait      = 10 * time.Millisecond
+	positionPollInterval = 50 * time.Millisecond
+	maxStateMachineSteps = 10
+	velocityUnitsDefault = 1.0
+)
+
+// Controlword commands driving the state machine, and the bits used in profile position mode.
+const (
+	cwDisableVoltage    uint16 = 0x0000
+	cwShutdown          uint16 = 0x0006
+	cwSwitchOn          uint16 = 0x0007
+	cwEnableOperation   uint16 = 0x000F
+	cwFaultReset        uint16 = 0x0080
+	cwNewSetPoint       uint16 = 0x0010
+	cwChangeImmediately uint16 = 0x0020
+	cwHalt              uint16 = 0x0100
+)
+
+// Statusword bits.
+const (
+	swFault               uint16 = 1 << 3
+	swWarning             uint16 = 1 << 7
+	swTargetReached       uint16 = 1 << 10
+	swSetPointAcknowledge uint16 = 1 << 12
+)
+
+// driveState is a state of the CiA-402 power state machine.
+type driveState int
+
+const (
+	stateNotReadyToSwitchOn driveState = iota
+	stateSwitchOnDisabled
+	stateReadyToSwitchOn
+	stateSwitchedOn
+	stateOperationEnabled
+	stateQuickStopActive
+	stateFaultReactionActive
+	stateFault
+	stateUnknown
+)
+
+func (s driveState) String() string {
+	switch s {
+	case stateNotReadyToSwitchOn:
+		return "not ready to switch on"
+	case stateSwitchOnDisabled:
+		return "switch on disabled"
+	case stateReadyToSwitchOn:
+		return "ready to switch on"
+	case stateSwitchedOn:
+		return "switched on"
+	case stateOperationEnabled:
+		return "operation enabled"
+	case stateQuickStopActive:
+		return "quick stop active"
+	case stateFaultReactionActive:
+		return "fault reaction active"
+	case stateFault:
+		return "fault"
+	case stateUnknown:
+		fallthrough
+	default:
+		return "unknown"
+	}
+}
+
+// decodeState returns the state encoded in a statusword, as laid out in CiA-402 table 30.
+func decodeState(status uint16) driveState {
+	switch {
+	case status&0x4F == 0x00:
+		return stateNotReadyToSwitchOn
+	case status&0x4F == 0x40:
+		return stateSwitchOnDisabled
+	case status&0x6F == 0x21:
+		return stateReadyToSwitchOn
+	case status&0x6F == 0x23:
+		return stateSwitchedOn
+	case status&0x6F == 0x27:
+		return stateOperationEnabled
+	case status&0x6F == 0x07:
+		return stateQuickStopActive
+	case status&0x4F == 0x0F:
+		return stateFaultReactionActive
+	case status&0x4F == 0x08:
+		return stateFault
+	default:
+		return stateUnknown
+	}
+}
+
+// Config describes how to reach the drive and how to convert its units.
+type Config struct {
+	Board  string  `json:"board"`
+	CANBus string  `json:"can_bus"`
+	NodeID int     `json:"node_id"`
+	MaxRPM float64 `json:"max_rpm"`
+
+	// TicksPerRotation converts positions to drive units and is required for position control.
+	TicksPerRotation int `json:"ticks_per_rotation,omitempty"`
+	// VelocityUnitsPerRPM converts speeds to drive units, defaults to 1.
+	VelocityUnitsPerRPM float64 `json:"velocity_units_per_rpm,omitempty"`
+
+	SDOTimeoutMs int `json:"sdo_timeout_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	if conf.Board == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	deps = append(deps, conf.Board)
+	if conf.CANBus == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "can_bus")
+	}
+	if conf.NodeID < 1 || conf.NodeID > 127 {
+		return nil, utils.NewConfigValidationError(path, errors.New("node_id must be between 1 and 127"))
+	}
+	if conf.MaxRPM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "max_rpm")
+	}
+	if conf.TicksPerRotation < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("ticks_per_rotation cannot be negative"))
+	}
+	return deps, nil
+}
+
+func init() {
+	registry.RegisterComponent(motor.Subtype, modelname, registry.Component{
+		Constructor: func(ctx context.Context, deps registry.Dependencies, config config.Component, logger golog.Logger) (interface{}, error) {
+			conf, ok := config.ConvertedAttributes.(*Config)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(conf, config.ConvertedAttributes)
+			}
+			b, err := board.FromDependencies(deps, conf.Board)
+			if err != nil {
+				return nil, err
+			}
+			return NewMotor(ctx, b, conf, config.Name, logger)
+		},
+	})
+
+	config.RegisterComponentAttributeMapConverter(motor.Subtype, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		}, &Config{})
+}
+
+var (
+	_ = motor.LocalMotor(&Motor{})
+	_ = motor.DiagnosticsReporter(&Motor{})
+)
+
+// Motor is a CiA-402 drive on a CAN bus.
+type Motor struct {
+	generic.Unimplemented
+	name   string
+	cfg    Config
+	sdo    *sdoClient
+	logger golog.Logger
+
+	mu              sync.Mutex
+	mode            int8
+	isOn            bool
+	currentPowerPct float64
+	zeroPosition    float64
+
+	opMgr operation.SingleOperationManager
+}
+
+// NewMotor connects to the drive on the board's CAN bus and brings it to the operation enabled state.
+func NewMotor(ctx context.Context, b board.Board, conf *Config, name string, logger golog.Logger) (motor.LocalMotor, error) {
+	cb, ok := b.(board.CANBoard)
+	if !ok {
+		return nil, errors.Errorf("board %s has no CAN buses", conf.Board)
+	}
+	bus, ok := cb.CANByName(conf.CANBus)
+	if !ok {
+		return nil, errors.Errorf("can't find CAN bus (%s) requested by motor (%s)", conf.CANBus, name)
+	}
+	timeout := sdoTimeoutDefault
+	if conf.SDOTimeoutMs > 0 {
+		timeout = time.Duration(conf.SDOTimeoutMs) * time.Millisecond
+	}
+	sdo, err := newSDOClient(bus, uint8(conf.NodeID), timeout)
+	if err != nil {
+		return nil, err
+	}
+	cfg := *conf
+	if cfg.VelocityUnitsPerRPM == 0 {
+		cfg.VelocityUnitsPerRPM = velocityUnitsDefault
+	}
+	m := &Motor{name: name, cfg: cfg, sdo: sdo, logger: logger}
+	if err := m.enable(ctx); err != nil {
+		return nil, multierr.Combine(err, sdo.close())
+	}
+	if err := m.Stop(ctx, nil); err != nil {
+		return nil, multierr.Combine(err, sdo.close())
+	}
+	return m, nil
+}
+
+// state reads the drive's statusword and decodes its state.
+func (m *Motor) state(ctx context.Context) (driveState, uint16, error) {
+	status, err := m.sdo.readUint16(ctx, objStatusword, 0)
+	if err != nil {
+		return stateUnknown, 0, err
+	}
+	return decodeState(status), status, nil
+}
+
+// enable walks the drive through its state machine to operation enabled, resetting any fault on the way.
+func (m *Motor) enable(ctx context.Context) error {
+	for i := 0; i < maxStateMachineSteps; i++ {
+		state, _, err := m.state(ctx)
+		if err != nil {
+			return err
+		}
+		var cw uint16
+		switch state {
+		case stateOperationEnabled:
+			return nil
+		case stateFault:
+			// fault reset acts on the rising edge of bit 7
+			if err := m.sdo.writeUint16(ctx, objControlword, 0, cwDisableVoltage); err != nil {
+				return err
+			}
+			cw = cwFaultReset
+		case stateSwitchOnDisabled:
+			cw = cwShutdown
+		case stateReadyToSwitchOn:
+			cw = cwSwitchOn
+		case stateSwitchedOn:
+			cw = cwEnableOperation | cwHalt
+		case stateQuickStopActive:
+			cw = cwDisableVoltage
+		case stateNotReadyToSwitchOn, stateFaultReactionActive, stateUnknown:
+			// the drive moves on by itself
+			if !utils.SelectContextOrWait(ctx, stateChangeWait) {
+				return ctx.Err()
+			}
+			continue
+		}
+		if err := m.sdo.writeUint16(ctx, objControlword, 0, cw); err != nil {
+			return err
+		}
+		if !utils.SelectContextOrWait(ctx, stateChangeWait) {
+			return ctx.Err()
+		}
+	}
+	state, _, err := m.state(ctx)
+	if err != nil {
+		return err
+	}
+	return errors.Errorf("motor (%s) drive did not enable, stuck in state %q", m.name, state)
+}
+
+// Must be run inside a lock.
+func (m *Motor) setMode(ctx context.Context, mode int8) error {
+	if m.mode == mode {
+		return nil
+	}
+	if err := m.sdo.write(ctx, objModesOfOperation, 0, []byte{byte(mode)}); err != nil {
+		return errors.Wrapf(err, "error setting mode of motor (%s)", m.name)
+	}
+	m.mode = mode
+	return nil
+}
+
+// SetPower runs the drive in profile velocity mode at the given fraction of max_rpm.
+func (m *Motor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	m.opMgr.CancelRunning(ctx)
+	powerPct = math.Max(-1, math.Min(1, powerPct))
+	if powerPct == 0 {
+		return m.Stop(ctx, extra)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enable(ctx); err != nil {
+		return err
+	}
+	if err := m.setMode(ctx, modeProfileVelocity); err != nil {
+		return err
+	}
+	velocity := int32(math.Round(powerPct * m.cfg.MaxRPM * m.cfg.VelocityUnitsPerRPM))
+	if err := m.sdo.writeUint32(ctx, objTargetVelocity, 0, uint32(velocity)); err != nil {
+		return errors.Wrapf(err, "error setting velocity of motor (%s)", m.name)
+	}
+	if err := m.sdo.writeUint16(ctx, objControlword, 0, cwEnableOperation); err != nil {
+		return err
+	}
+	m.isOn = true
+	m.currentPowerPct = powerPct
+	return nil
+}
+
+// GoFor moves the given number of revolutions at the given rpm, or runs at that rpm
+// indefinitely if revolutions is 0.
+func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+	if rpm == 0 {
+		return motor.NewZeroRPMError()
+	}
+	if revolutions == 0 {
+		return m.SetPower(ctx, math.Max(-1, math.Min(1, rpm/m.cfg.MaxRPM)), extra)
+	}
+	pos, err := m.Position(ctx, extra)
+	if err != nil {
+		return err
+	}
+	dir := rpm * revolutions / math.Abs(rpm*revolutions)
+	return m.GoTo(ctx, rpm, pos+dir*math.Abs(revolutions), extra)
+}
+
+// GoTo moves to the given position in profile position mode and waits for the drive to report it reached it.
+func (m *Motor) GoTo(ctx context.Context, rpm, positionRevolutions float64, extra map[string]interface{}) error {
+	if m.cfg.TicksPerRotation == 0 {
+		return motor.NewGoToUnsupportedError(m.name)
+	}
+	if rpm == 0 {
+		return motor.NewZeroRPMError()
+	}
+	pos, err := m.Position(ctx, extra)
+	if err != nil {
+		return err
+	}
+	powerPct := math.Min(math.Abs(rpm), m.cfg.MaxRPM) / m.cfg.MaxRPM
+	if positionRevolutions < pos {
+		powerPct *= -1
+	}
+	m.opMgr.CancelRunning(ctx)
+	ctx, done := m.opMgr.New(ctx)
+	defer done()
+
+	if err := m.startMove(ctx, powerPct, positionRevolutions); err != nil {
+		return err
+	}
+	var setPointAcknowledged bool
+	for err == nil {
+		state, status, readErr := m.state(ctx)
+		if readErr != nil {
+			err = readErr
+			break
+		}
+		if state == stateFault {
+			err = errors.Errorf("motor (%s) drive faulted while moving", m.name)
+			break
+		}
+		if !setPointAcknowledged && status&swSetPointAcknowledge != 0 {
+			// the drive took the new set-point, so release the handshake bit
+			setPointAcknowledged = true
+			m.mu.Lock()
+			err = m.sdo.writeUint16(ctx, objControlword, 0, cwEnableOperation|cwChangeImmediately)
+			m.mu.Unlock()
+			continue
+		}
+		if setPointAcknowledged && status&swTargetReached != 0 {
+			break
+		}
+		if !utils.SelectContextOrWait(ctx, positionPollInterval) {
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// another operation took over
+			return nil
+		}
+		return multierr.Combine(err, m.stop(context.Background()))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isOn = false
+	m.currentPowerPct = 0
+	return nil
+}
+
+// startMove sends a new absolute set-point to the drive, to be reached at the speed given by powerPct.
+func (m *Motor) startMove(ctx context.Context, powerPct, positionRevolutions float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enable(ctx); err != nil {
+		return err
+	}
+	if err := m.setMode(ctx, modeProfilePosition); err != nil {
+		return err
+	}
+	ticks := float64(m.cfg.TicksPerRotation)
+	target := int32(math.Round((positionRevolutions + m.zeroPosition) * ticks))
+	velocity := uint32(math.Round(math.Abs(powerPct) * m.cfg.MaxRPM * m.cfg.VelocityUnitsPerRPM))
+	if err := m.sdo.writeUint32(ctx, objProfileVelocity, 0, velocity); err != nil {
+		return errors.Wrapf(err, "error setting profile velocity of motor (%s)", m.name)
+	}
+	if err := m.sdo.writeUint32(ctx, objTargetPosition, 0, uint32(target)); err != nil {
+		return errors.Wrapf(err, "error setting target position of motor (%s)", m.name)
+	}
+	if err := m.sdo.writeUint16(ctx, objControlword, 0, cwEnableOperation|cwChangeImmediately|cwNewSetPoint); err != nil {
+		return err
+	}
+	m.isOn = true
+	m.currentPowerPct = powerPct
+	return nil
+}
+
+// GoTillStop is unsupported.
+func (m *Motor) GoTillStop(ctx context.Context, rpm float64, stopFunc func(ctx context.Context) bool) error {
+	return motor.NewGoTillStopUnsupportedError(m.name)
+}
+
+// ResetZeroPosition defines the current position to be the given offset.
+func (m *Motor) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	if m.cfg.TicksPerRotation == 0 {
+		return motor.NewResetZeroPositionUnsupportedError(m.name)
+	}
+	raw, err := m.sdo.readInt32(ctx, objPositionActual, 0)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.zeroPosition = float64(raw)/float64(m.cfg.TicksPerRotation) - offset
+	return nil
+}
+
+// Position reports the position in revolutions, or 0 without ticks_per_rotation.
+func (m *Motor) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if m.cfg.TicksPerRotation == 0 {
+		return 0, nil
+	}
+	raw, err := m.sdo.readInt32(ctx, objPositionActual, 0)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error reading position of motor (%s)", m.name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return float64(raw)/float64(m.cfg.TicksPerRotation) - m.zeroPosition, nil
+}
+
+// Properties returns the additional features supported by this motor.
+func (m *Motor) Properties(ctx context.Context, extra map[string]interface{}) (map[motor.Feature]bool, error) {
+	return map[motor.Feature]bool{
+		motor.PositionReporting: m.cfg.TicksPerRotation != 0,
+	}, nil
+}
+
+// Stop halts the drive, which decelerates with its profile deceleration and holds position.
+func (m *Motor) Stop(ctx context.Context, extra map[string]interface{}) error {
+	_, done := m.opMgr.New(ctx)
+	defer done()
+	return m.stop(ctx)
+}
+
+func (m *Motor) stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.sdo.writeUint16(ctx, objControlword, 0, cwEnableOperation|cwHalt); err != nil {
+		return errors.Wrapf(err, "error stopping motor (%s)", m.name)
+	}
+	m.isOn = false
+	m.currentPowerPct = 0
+	return nil
+}
+
+// IsPowered returns whether the drive has been commanded to move and at what power.
+func (m *Motor) IsPowered(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isOn, m.currentPowerPct, nil
+}
+
+// IsMoving returns whether the drive has been commanded to move.
+func (m *Motor) IsMoving(ctx context.Context) (bool, error) {
+	on, _, err := m.IsPowered(ctx, nil)
+	return on, err
+}
+
+// Diagnostics reports the drive's fault and warning bits along with its error code.
+func (m *Motor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+	state, status, err := m.state(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error in Diagnostics from motor (%s)", m.name)
+	}
+	d := &motor.Diagnostics{}
+	if status&swFault != 0 {
+		code, err := m.sdo.readUint16(ctx, objErrorCode, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in Diagnostics from motor (%s)", m.name)
+		}
+		d.Faults = append(d.Faults, fmt.Sprintf("drive %s with error code %#04x", state, code))
+	}
+	if status&swWarning != 0 {
+		d.Faults = append(d.Faults, "drive warning")
+	}
+	return d, nil
+}
+
+// Close halts the drive, disables its power stage and releases the bus.
+func (m *Motor) Close(ctx context.Context) error {
+	err := m.Stop(ctx, nil)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err = multierr.Combine(err, m.sdo.writeUint16(ctx, objControlword, 0, cwShutdown))
+	return multierr.Combine(err, m.sdo.close())
+}
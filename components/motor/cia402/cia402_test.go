@@ -0,0 +1,368 @@
+package cia402
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	"go.viam.com/rdk/components/motor"
+)
+
+const testNodeID = 5
+
+// simDrive answers SDO requests like a CiA-402 drive, moving instantly to any new set-point.
+type simDrive struct {
+	mu      sync.Mutex
+	state   driveState
+	objects map[uint32]uint32
+	control uint16
+	ack     bool
+	reached bool
+	writes  []uint16
+
+	handle                  board.CANHandle
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func objKey(index uint16, subIndex uint8) uint32 {
+	return uint32(index)<<8 | uint32(subIndex)
+}
+
+func newSimDrive(t *testing.T, bus board.CAN) *simDrive {
+	t.Helper()
+	handle, err := bus.OpenHandle(board.CANFilter{ID: sdoRequestBase + testNodeID, Mask: 0x7FF})
+	test.That(t, err, test.ShouldBeNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &simDrive{
+		state:   stateSwitchOnDisabled,
+		objects: map[uint32]uint32{},
+		handle:  handle,
+		cancel:  cancel,
+	}
+	d.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for {
+			frame, err := handle.Receive(ctx)
+			if err != nil {
+				return
+			}
+			resp := d.handleSDO(frame.Data)
+			if err := handle.Send(ctx, board.CANFrame{ID: sdoResponseBase + testNodeID, Data: resp}); err != nil {
+				return
+			}
+		}
+	}, d.activeBackgroundWorkers.Done)
+	return d
+}
+
+func (d *simDrive) close() {
+	d.cancel()
+	d.activeBackgroundWorkers.Wait()
+	utils.UncheckedError(d.handle.Close())
+}
+
+func (d *simDrive) statusword() uint16 {
+	var status uint16
+	switch d.state {
+	case stateSwitchOnDisabled:
+		status = 0x40
+	case stateReadyToSwitchOn:
+		status = 0x21
+	case stateSwitchedOn:
+		status = 0x23
+	case stateOperationEnabled:
+		status = 0x27
+	case stateFault:
+		status = 0x08
+	case stateNotReadyToSwitchOn, stateQuickStopActive, stateFaultReactionActive, stateUnknown:
+	}
+	if d.ack {
+		status |= swSetPointAcknowledge
+	}
+	if d.reached {
+		status |= swTargetReached
+	}
+	return status
+}
+
+func (d *simDrive) setControlword(cw uint16) {
+	prev := d.control
+	d.control = cw
+	d.writes = append(d.writes, cw)
+	switch {
+	case d.state == stateFault:
+		if prev&cwFaultReset == 0 && cw&cwFaultReset != 0 {
+			d.state = stateSwitchOnDisabled
+		}
+	case cw&0x02 == 0:
+		d.state = stateSwitchOnDisabled
+	case cw&0x0F == cwShutdown:
+		d.state = stateReadyToSwitchOn
+	case cw&0x0F == cwSwitchOn && d.state == stateReadyToSwitchOn:
+		d.state = stateSwitchedOn
+	case cw&0x0F == cwEnableOperation && (d.state == stateSwitchedOn || d.state == stateOperationEnabled):
+		d.state = stateOperationEnabled
+	}
+	if d.state != stateOperationEnabled || d.objects[objKey(objModesOfOperation, 0)] != modeProfilePosition {
+		return
+	}
+	switch {
+	case prev&cwNewSetPoint == 0 && cw&cwNewSetPoint != 0:
+		d.ack = true
+		d.reached = false
+	case prev&cwNewSetPoint != 0 && cw&cwNewSetPoint == 0 && d.ack:
+		d.ack = false
+		d.reached = true
+		d.objects[objKey(objPositionActual, 0)] = d.objects[objKey(objTargetPosition, 0)]
+	}
+}
+
+func (d *simDrive) handleSDO(req []byte) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp := make([]byte, 8)
+	copy(resp[1:4], req[1:4])
+	index := binary.LittleEndian.Uint16(req[1:])
+	key := objKey(index, req[3])
+	switch {
+	case req[0] == sdoUploadRequest:
+		resp[0] = 0x43
+		value := d.objects[key]
+		if index == objStatusword {
+			value = uint32(d.statusword())
+		}
+		binary.LittleEndian.PutUint32(resp[4:], value)
+	case req[0]&sdoCommandMask == 0x20:
+		resp[0] = sdoDownloadResponse
+		value := binary.LittleEndian.Uint32(req[4:])
+		if index == objControlword {
+			d.setControlword(uint16(value))
+			break
+		}
+		d.objects[key] = value
+	default:
+		resp[0] = sdoAbort
+		binary.LittleEndian.PutUint32(resp[4:], 0x05040001)
+	}
+	return resp
+}
+
+func (d *simDrive) object(index uint16) uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.objects[objKey(index, 0)]
+}
+
+func (d *simDrive) setObject(index uint16, value uint32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.objects[objKey(index, 0)] = value
+}
+
+func (d *simDrive) controlword() uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.control
+}
+
+func (d *simDrive) currentState() driveState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+func (d *simDrive) fault(code uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state = stateFault
+	d.objects[objKey(objErrorCode, 0)] = uint32(code)
+}
+
+func newTestMotor(t *testing.T, ticksPerRotation int) (*Motor, *simDrive) {
+	t.Helper()
+	bus := fakeboard.NewCAN()
+	drive := newSimDrive(t, bus)
+	b := &fakeboard.Board{CANs: map[string]*fakeboard.CAN{"can0": bus}}
+	conf := &Config{
+		Board:               "b",
+		CANBus:              "can0",
+		NodeID:              testNodeID,
+		MaxRPM:              3000,
+		TicksPerRotation:    ticksPerRotation,
+		VelocityUnitsPerRPM: 10,
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"b"})
+	m, err := NewMotor(context.Background(), b, conf, "servo", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return m.(*Motor), drive
+}
+
+func TestDecodeState(t *testing.T) {
+	test.That(t, decodeState(0x0250), test.ShouldEqual, stateSwitchOnDisabled)
+	test.That(t, decodeState(0x0231), test.ShouldEqual, stateReadyToSwitchOn)
+	test.That(t, decodeState(0x0233), test.ShouldEqual, stateSwitchedOn)
+	test.That(t, decodeState(0x0637), test.ShouldEqual, stateOperationEnabled)
+	test.That(t, decodeState(0x0217), test.ShouldEqual, stateQuickStopActive)
+	test.That(t, decodeState(0x021F), test.ShouldEqual, stateFaultReactionActive)
+	test.That(t, decodeState(0x0218), test.ShouldEqual, stateFault)
+	test.That(t, decodeState(0x0000), test.ShouldEqual, stateNotReadyToSwitchOn)
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"board" is required`)
+
+	conf.Board = "b"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"can_bus" is required`)
+
+	conf.CANBus = "can0"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "node_id")
+
+	conf.NodeID = 1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"max_rpm" is required`)
+
+	conf.MaxRPM = 100
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestVelocity(t *testing.T) {
+	ctx := context.Background()
+	m, drive := newTestMotor(t, 0)
+	defer drive.close()
+
+	// the drive is enabled but halted on startup
+	test.That(t, drive.currentState(), test.ShouldEqual, stateOperationEnabled)
+	test.That(t, drive.controlword()&cwHalt, test.ShouldNotEqual, 0)
+
+	test.That(t, m.SetPower(ctx, -0.5, nil), test.ShouldBeNil)
+	test.That(t, drive.object(objModesOfOperation), test.ShouldEqual, modeProfileVelocity)
+	test.That(t, int32(drive.object(objTargetVelocity)), test.ShouldEqual, -15000)
+	test.That(t, drive.controlword(), test.ShouldEqual, cwEnableOperation)
+	on, powerPct, err := m.IsPowered(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, on, test.ShouldBeTrue)
+	test.That(t, powerPct, test.ShouldEqual, -0.5)
+
+	test.That(t, m.GoFor(ctx, 600, 0, nil), test.ShouldBeNil)
+	test.That(t, int32(drive.object(objTargetVelocity)), test.ShouldEqual, 6000)
+
+	test.That(t, m.Stop(ctx, nil), test.ShouldBeNil)
+	test.That(t, drive.controlword()&cwHalt, test.ShouldNotEqual, 0)
+	moving, err := m.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	props, err := m.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props[motor.PositionReporting], test.ShouldBeFalse)
+	test.That(t, m.GoTo(ctx, 100, 1, nil), test.ShouldBeError, motor.NewGoToUnsupportedError("servo"))
+
+	test.That(t, m.Close(ctx), test.ShouldBeNil)
+	test.That(t, drive.currentState(), test.ShouldEqual, stateReadyToSwitchOn)
+}
+
+func TestPosition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	m, drive := newTestMotor(t, 1000)
+	defer drive.close()
+	defer func() {
+		test.That(t, m.Close(ctx), test.ShouldBeNil)
+	}()
+
+	drive.setObject(objPositionActual, 2500)
+	pos, err := m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 2.5)
+
+	test.That(t, m.ResetZeroPosition(ctx, 1, nil), test.ShouldBeNil)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 1)
+
+	test.That(t, m.GoTo(ctx, 60, -2, nil), test.ShouldBeNil)
+	test.That(t, drive.object(objModesOfOperation), test.ShouldEqual, modeProfilePosition)
+	test.That(t, int32(drive.object(objTargetPosition)), test.ShouldEqual, -500)
+	test.That(t, drive.object(objProfileVelocity), test.ShouldEqual, 600)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, -2)
+	on, _, err := m.IsPowered(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, on, test.ShouldBeFalse)
+
+	// backwards by 3 revolutions
+	test.That(t, m.GoFor(ctx, -60, 3, nil), test.ShouldBeNil)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, -5)
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+	m, drive := newTestMotor(t, 0)
+	defer drive.close()
+	defer func() {
+		test.That(t, m.Close(ctx), test.ShouldBeNil)
+	}()
+
+	d, err := m.Diagnostics(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Faults, test.ShouldBeEmpty)
+
+	drive.fault(0x2310)
+	d, err = m.Diagnostics(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Faults, test.ShouldResemble, []string{"drive fault with error code 0x2310"})
+
+	// commanding the motor resets the fault
+	test.That(t, m.SetPower(ctx, 0.1, nil), test.ShouldBeNil)
+	test.That(t, drive.currentState(), test.ShouldEqual, stateOperationEnabled)
+}
+
+func TestSDOAbort(t *testing.T) {
+	ctx := context.Background()
+	bus := fakeboard.NewCAN()
+	drive := newSimDrive(t, bus)
+	defer drive.close()
+	client, err := newSDOClient(bus, testNodeID, time.Second)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.close(), test.ShouldBeNil)
+	}()
+
+	// a segmented upload request is refused by the simulator
+	_, err = client.transfer(ctx, 0x1008, 0, []byte{0x60, 0, 0, 0, 0, 0, 0, 0}, sdoUploadResponse)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "aborted with code 0x05040001")
+
+	// nothing answers node 6
+	other, err := newSDOClient(bus, testNodeID+1, 100*time.Millisecond)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, other.close(), test.ShouldBeNil)
+	}()
+	_, err = other.readUint16(ctx, objStatusword, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no SDO response from node 6")
+}
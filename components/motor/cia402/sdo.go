@@ -0,0 +1,160 @@
+package cia402
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// CANopen function codes of the SDO channel; the node id is added to get the frame id.
+const (
+	sdoRequestBase  = 0x600
+	sdoResponseBase = 0x580
+)
+
+// SDO command specifiers.
+const (
+	sdoUploadRequest     = 0x40
+	sdoUploadResponse    = 0x40
+	sdoDownloadResponse  = 0x60
+	sdoAbort             = 0x80
+	sdoCommandMask       = 0xE0
+	sdoExpedited         = 0x02
+	sdoSizeIndicated     = 0x01
+	sdoDownloadExpedited = 0x23
+)
+
+// sdoClient reads and writes the object dictionary of a single node with expedited SDO transfers,
+// which carry up to four bytes and cover every object a CiA-402 drive needs.
+type sdoClient struct {
+	mu      sync.Mutex
+	handle  board.CANHandle
+	nodeID  uint8
+	timeout time.Duration
+}
+
+func newSDOClient(bus board.CAN, nodeID uint8, timeout time.Duration) (*sdoClient, error) {
+	handle, err := bus.OpenHandle(board.CANFilter{ID: sdoResponseBase + uint32(nodeID), Mask: 0x7FF})
+	if err != nil {
+		return nil, err
+	}
+	return &sdoClient{handle: handle, nodeID: nodeID, timeout: timeout}, nil
+}
+
+// sdoAbortError is returned when the node refuses a transfer.
+type sdoAbortError struct {
+	index    uint16
+	subIndex uint8
+	code     uint32
+}
+
+func (e *sdoAbortError) Error() string {
+	return fmt.Sprintf("SDO transfer of object %#04x:%d aborted with code %#08x", e.index, e.subIndex, e.code)
+}
+
+// write downloads up to four bytes to an object.
+func (c *sdoClient) write(ctx context.Context, index uint16, subIndex uint8, data []byte) error {
+	if len(data) == 0 || len(data) > 4 {
+		return errors.Errorf("expedited SDO writes carry 1 to 4 bytes, got %d", len(data))
+	}
+	req := make([]byte, 8)
+	// the command encodes how many of the four data bytes are unused
+	req[0] = sdoDownloadExpedited | byte(4-len(data))<<2
+	copy(req[4:], data)
+	_, err := c.transfer(ctx, index, subIndex, req, sdoDownloadResponse)
+	return err
+}
+
+// read uploads the value of an object.
+func (c *sdoClient) read(ctx context.Context, index uint16, subIndex uint8) ([]byte, error) {
+	req := make([]byte, 8)
+	req[0] = sdoUploadRequest
+	resp, err := c.transfer(ctx, index, subIndex, req, sdoUploadResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp[0]&sdoExpedited == 0 {
+		return nil, errors.Errorf("object %#04x:%d is too large for an expedited SDO upload", index, subIndex)
+	}
+	size := 4
+	if resp[0]&sdoSizeIndicated != 0 {
+		size = 4 - int(resp[0]>>2&0x03)
+	}
+	return resp[4 : 4+size], nil
+}
+
+func (c *sdoClient) transfer(ctx context.Context, index uint16, subIndex uint8, req []byte, command byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	binary.LittleEndian.PutUint16(req[1:], index)
+	req[3] = subIndex
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if err := c.handle.Send(ctx, board.CANFrame{ID: sdoRequestBase + uint32(c.nodeID), Data: req}); err != nil {
+		return nil, err
+	}
+	for {
+		frame, err := c.handle.Receive(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no SDO response from node %d for object %#04x:%d", c.nodeID, index, subIndex)
+		}
+		resp := frame.Data
+		// skip anything left over from an earlier transfer that timed out
+		if len(resp) != 8 || binary.LittleEndian.Uint16(resp[1:]) != index || resp[3] != subIndex {
+			continue
+		}
+		switch resp[0] & sdoCommandMask {
+		case sdoAbort:
+			return nil, &sdoAbortError{index: index, subIndex: subIndex, code: binary.LittleEndian.Uint32(resp[4:])}
+		case command:
+			return resp, nil
+		default:
+			continue
+		}
+	}
+}
+
+func (c *sdoClient) writeUint16(ctx context.Context, index uint16, subIndex uint8, v uint16) error {
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, v)
+	return c.write(ctx, index, subIndex, data)
+}
+
+func (c *sdoClient) writeUint32(ctx context.Context, index uint16, subIndex uint8, v uint32) error {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, v)
+	return c.write(ctx, index, subIndex, data)
+}
+
+func (c *sdoClient) readUint16(ctx context.Context, index uint16, subIndex uint8) (uint16, error) {
+	data, err := c.read(ctx, index, subIndex)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 2 {
+		return 0, errors.Errorf("object %#04x:%d is %d bytes, expected 2", index, subIndex, len(data))
+	}
+	return binary.LittleEndian.Uint16(data), nil
+}
+
+func (c *sdoClient) readInt32(ctx context.Context, index uint16, subIndex uint8) (int32, error) {
+	data, err := c.read(ctx, index, subIndex)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, errors.Errorf("object %#04x:%d is %d bytes, expected 4", index, subIndex, len(data))
+	}
+	return int32(binary.LittleEndian.Uint32(data)), nil
+}
+
+func (c *sdoClient) close() error {
+	return c.handle.Close()
+}
@@ -142,6 +142,19 @@ func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map
 	return rprotoutils.DoFromResourceClient(ctx, c.client, c.name, cmd)
 }
 
+// Diagnostics requests the motor's diagnostics through DoCommand, since the motor API has no
+// dedicated method for them.
+func (c *client) Diagnostics(ctx context.Context, extra map[string]interface{}) (*Diagnostics, error) {
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	resp, err := c.DoCommand(ctx, map[string]interface{}{DiagnosticsCommand: extra})
+	if err != nil {
+		return nil, err
+	}
+	return DiagnosticsFromMap(resp)
+}
+
 func (c *client) IsMoving(ctx context.Context) (bool, error) {
 	resp, err := c.client.IsMoving(ctx, &pb.IsMovingRequest{Name: c.name})
 	if err != nil {
@@ -71,6 +71,11 @@ func TestClient(t *testing.T) {
 		actualExtra = extra
 		return true, actualPowerPct, nil
 	}
+	current := 1.5
+	workingMotor.DiagnosticsFunc = func(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+		actualExtra = extra
+		return &motor.Diagnostics{CurrentAmps: &current, Stalled: true, Faults: []string{"over current warning"}}, nil
+	}
 
 	failingMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
 		return errors.New("set power failed")
@@ -96,6 +101,9 @@ func TestClient(t *testing.T) {
 	failingMotor.IsPoweredFunc = func(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
 		return false, 0.0, errors.New("is on unavailable")
 	}
+	failingMotor.DiagnosticsFunc = func(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+		return nil, errors.New("diagnostics unavailable")
+	}
 
 	resourceMap := map[resource.Name]interface{}{
 		motor.Named(testMotorName): workingMotor,
@@ -162,6 +170,15 @@ func TestClient(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, actualExtra, test.ShouldResemble, map[string]interface{}{"foo": "bar", "baz": []interface{}{1., 2., 3.}})
 
+		diag, err := workingMotorClient.(motor.DiagnosticsReporter).Diagnostics(context.Background(), map[string]interface{}{"foo": "bar"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, diag, test.ShouldResemble, &motor.Diagnostics{
+			CurrentAmps: &current,
+			Stalled:     true,
+			Faults:      []string{"over current warning"},
+		})
+		test.That(t, actualExtra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+
 		test.That(t, utils.TryClose(context.Background(), workingMotorClient), test.ShouldBeNil)
 
 		test.That(t, conn.Close(), test.ShouldBeNil)
@@ -200,6 +217,10 @@ func TestClient(t *testing.T) {
 		err = failingMotorClient.Stop(context.Background(), nil)
 		test.That(t, err, test.ShouldNotBeNil)
 
+		diag, err := failingMotorClient.(motor.DiagnosticsReporter).Diagnostics(context.Background(), nil)
+		test.That(t, diag, test.ShouldBeNil)
+		test.That(t, err, test.ShouldNotBeNil)
+
 		test.That(t, utils.TryClose(context.Background(), failingMotorClient), test.ShouldBeNil)
 	})
 
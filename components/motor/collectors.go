@@ -13,6 +13,7 @@ type method int64
 const (
 	position method = iota
 	isPowered
+	diagnostics
 )
 
 func (m method) String() string {
@@ -21,6 +22,8 @@ func (m method) String() string {
 		return "Position"
 	case isPowered:
 		return "IsPowered"
+	case diagnostics:
+		return "Diagnostics"
 	}
 	return "Unknown"
 }
@@ -68,6 +71,22 @@ func newIsPoweredCollector(resource interface{}, params data.CollectorParams) (d
 	return data.NewCollector(cFunc, params)
 }
 
+func newDiagnosticsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	motor, err := assertMotor(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		v, err := DiagnosticsFromMotor(ctx, motor, params.ComponentName, nil)
+		if err != nil {
+			return nil, data.FailedToReadErr(params.ComponentName, diagnostics.String(), err)
+		}
+		return v, nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
 func assertMotor(resource interface{}) (Motor, error) {
 	motor, ok := resource.(Motor)
 	if !ok {
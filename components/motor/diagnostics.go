@@ -0,0 +1,110 @@
+package motor
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// DiagnosticsCommand is the DoCommand key used to request diagnostics from a motor over the
+// network, since the motor API has no dedicated method for them. Its value is the extra map of
+// the request. It is namespaced so it does not shadow the commands of motor models.
+const DiagnosticsCommand = "rdk:motor:diagnostics"
+
+// Diagnostics describes the health of a motor and its driver. Any of the measurements a driver
+// cannot read are left nil.
+type Diagnostics struct {
+	// CurrentAmps is the current drawn by the motor.
+	CurrentAmps *float64
+	// TemperatureCelsius is the temperature of the motor driver.
+	TemperatureCelsius *float64
+	// VoltageVolts is the supply voltage seen by the motor driver.
+	VoltageVolts *float64
+	// Stalled is true when the driver has detected that the motor is stalled.
+	Stalled bool
+	// Faults lists any error or warning conditions reported by the driver.
+	Faults []string
+}
+
+// A DiagnosticsReporter is a motor that can report on its current draw, temperature and faults.
+type DiagnosticsReporter interface {
+	// Diagnostics returns the latest diagnostics read from the motor driver.
+	Diagnostics(ctx context.Context, extra map[string]interface{}) (*Diagnostics, error)
+}
+
+// DiagnosticsFromMotor returns the diagnostics of the given motor, or an error if it cannot report them.
+func DiagnosticsFromMotor(ctx context.Context, m Motor, name string, extra map[string]interface{}) (*Diagnostics, error) {
+	reporter, ok := m.(DiagnosticsReporter)
+	if !ok {
+		return nil, NewDiagnosticsUnsupportedError(name)
+	}
+	return reporter.Diagnostics(ctx, extra)
+}
+
+// DiagnosticsToMap converts diagnostics to the map returned from a DoCommand.
+func DiagnosticsToMap(d *Diagnostics) map[string]interface{} {
+	faults := make([]interface{}, 0, len(d.Faults))
+	for _, f := range d.Faults {
+		faults = append(faults, f)
+	}
+	out := map[string]interface{}{
+		"stalled": d.Stalled,
+		"faults":  faults,
+	}
+	if d.CurrentAmps != nil {
+		out["current_amps"] = *d.CurrentAmps
+	}
+	if d.TemperatureCelsius != nil {
+		out["temperature_celsius"] = *d.TemperatureCelsius
+	}
+	if d.VoltageVolts != nil {
+		out["voltage_volts"] = *d.VoltageVolts
+	}
+	return out
+}
+
+// DiagnosticsFromMap converts the map returned from a DoCommand back into diagnostics.
+func DiagnosticsFromMap(m map[string]interface{}) (*Diagnostics, error) {
+	d := &Diagnostics{}
+	var err error
+	if d.CurrentAmps, err = optionalFloat(m, "current_amps"); err != nil {
+		return nil, err
+	}
+	if d.TemperatureCelsius, err = optionalFloat(m, "temperature_celsius"); err != nil {
+		return nil, err
+	}
+	if d.VoltageVolts, err = optionalFloat(m, "voltage_volts"); err != nil {
+		return nil, err
+	}
+	if v, ok := m["stalled"]; ok {
+		if d.Stalled, ok = v.(bool); !ok {
+			return nil, errors.Errorf("expected stalled to be a bool but got %T", v)
+		}
+	}
+	if v, ok := m["faults"]; ok {
+		faults, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected faults to be a list but got %T", v)
+		}
+		for _, f := range faults {
+			s, ok := f.(string)
+			if !ok {
+				return nil, errors.Errorf("expected fault to be a string but got %T", f)
+			}
+			d.Faults = append(d.Faults, s)
+		}
+	}
+	return d, nil
+}
+
+func optionalFloat(m map[string]interface{}, key string) (*float64, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a number but got %T", key, v)
+	}
+	return &f, nil
+}
@@ -44,7 +44,10 @@ type controller struct {
 	address      int // 128-135
 }
 
-// Motor is a single axis/motor/component instance.
+// Motor is a single axis/motor/component instance. It does not report diagnostics
+// (motor.DiagnosticsReporter), since the packetized serial protocol of the Sabertooth 2x60 and
+// its siblings only carries commands to the controller and cannot read back current,
+// temperature or faults.
 type Motor struct {
 	// A reference to the actual controller that needs to be commanded for the motor to run
 	c *controller
@@ -50,6 +50,8 @@ type controller struct {
 	testChan     chan string
 }
 
+var _ = motor.DiagnosticsReporter(&Motor{})
+
 // Motor is a single axis/motor/component instance.
 type Motor struct {
 	c                *controller
@@ -691,6 +693,53 @@ func (m *Motor) isStopped(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// Diagnostics reports the amplifier error status of the motor's axis.
+// https://www.galil.com/download/comref/com4103/index.html#tell_amplifier_error_status.html
+func (m *Motor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+	m.c.mu.Lock()
+	defer m.c.mu.Unlock()
+
+	axis := int(m.Axis[0] - 'A')
+	// TA0 has one set of amplifier flags for axes A-D in the low nibble and another for E-H
+	ampShift := 0
+	if axis >= 4 {
+		ampShift = 4
+	}
+
+	var flags [4]int
+	for i := range flags {
+		ret, err := m.c.sendCmd(fmt.Sprintf("TA%d", i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in Diagnostics from motor (%s)", m.motorName)
+		}
+		f, err := strconv.ParseFloat(ret, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in Diagnostics from motor (%s)", m.motorName)
+		}
+		flags[i] = int(f)
+	}
+
+	d := &motor.Diagnostics{}
+	for _, status := range []struct {
+		flags int
+		mask  int
+		fault string
+	}{
+		{flags[0], 1 << ampShift, "amplifier over-current"},
+		{flags[0], 2 << ampShift, "amplifier over-voltage"},
+		{flags[0], 4 << ampShift, "amplifier over-temperature"},
+		{flags[0], 8 << ampShift, "amplifier under-voltage"},
+		{flags[1], 1 << axis, "peak current"},
+		{flags[2], 1 << axis, "hall error"},
+		{flags[3], 1 << ampShift, "electronic lockout"},
+	} {
+		if status.flags&status.mask != 0 {
+			d.Faults = append(d.Faults, status.fault)
+		}
+	}
+	return d, nil
+}
+
 // Home runs the dmc homing routine.
 func (m *Motor) Home(ctx context.Context) error {
 	ctx, done := m.opMgr.New(ctx)
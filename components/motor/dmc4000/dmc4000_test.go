@@ -184,6 +184,19 @@ func TestDMC4000Motor(t *testing.T) {
 		waitTx(t, resChan)
 	})
 
+	t.Run("motor diagnostics testing", func(t *testing.T) {
+		txMu.Lock()
+		go checkRx(resChan, c,
+			[]string{"TA0", "TA1", "TA2", "TA3"},
+			[]string{" 4\r\n:", " 0\r\n:", " 0\r\n:", " 0\r\n:"},
+		)
+		diag, err := _motor.(motor.DiagnosticsReporter).Diagnostics(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, diag.Faults, test.ShouldResemble, []string{"amplifier over-temperature"})
+		test.That(t, diag.Stalled, test.ShouldBeFalse)
+		waitTx(t, resChan)
+	})
+
 	t.Run("motor GoFor with positive rpm and positive revolutions", func(t *testing.T) {
 		// Check with position at 0.0 revolutions
 		txMu.Lock()
@@ -30,3 +30,8 @@ func NewZeroRPMError() error {
 func NewGoToUnsupportedError(motorName string) error {
 	return errors.Errorf("motor with name %s does not support GoTo", motorName)
 }
+
+// NewDiagnosticsUnsupportedError returns error when a motor is required to report diagnostics.
+func NewDiagnosticsUnsupportedError(motorName string) error {
+	return errors.Errorf("motor with name %s does not support Diagnostics", motorName)
+}
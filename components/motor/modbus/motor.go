@@ -0,0 +1,385 @@
+// Package modbus implements a motor driven through the registers of a Modbus device, such as a VFD.
+package modbus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	mb "go.viam.com/rdk/components/board/modbus"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("modbus")
+
+// how often the position register is polled while going to a position.
+const positionPollInterval = 50 * time.Millisecond
+
+// Config describes the connection to the drive and the registers used to command it.
+type Config struct {
+	mb.Config
+
+	// SpeedRegister is written with the commanded speed in RPM, after applying its scale and offset.
+	SpeedRegister mb.RegisterConfig `json:"speed_register"`
+
+	// RunRegister, if set, is written with run_value to start the drive and stop_value to stop it.
+	// run_value defaults to 1.
+	RunRegister *mb.RegisterConfig `json:"run_register,omitempty"`
+	RunValue    float64            `json:"run_value,omitempty"`
+	StopValue   float64            `json:"stop_value,omitempty"`
+
+	// DirectionRegister, if set, is written with forward_value or reverse_value and the speed
+	// register only receives the magnitude of the speed. Otherwise the speed is written signed.
+	// reverse_value defaults to 1.
+	DirectionRegister *mb.RegisterConfig `json:"direction_register,omitempty"`
+	ForwardValue      float64            `json:"forward_value,omitempty"`
+	ReverseValue      float64            `json:"reverse_value,omitempty"`
+
+	// PositionRegister, if set, reports the position of the motor in revolutions.
+	PositionRegister *mb.RegisterConfig `json:"position_register,omitempty"`
+
+	// The maximum speed of the motor, used to convert power percentages to speeds.
+	MaxRPM float64 `json:"max_rpm"`
+
+	// Flip the direction of the signal sent to the drive.
+	DirectionFlip bool `json:"dir_flip,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid and returns the board it depends on, if any.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	if conf.MaxRPM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "max_rpm")
+	}
+	writable := []struct {
+		name string
+		reg  *mb.RegisterConfig
+	}{
+		{"speed_register", &conf.SpeedRegister},
+		{"run_register", conf.RunRegister},
+		{"direction_register", conf.DirectionRegister},
+	}
+	for _, w := range writable {
+		if w.reg == nil {
+			continue
+		}
+		regPath := fmt.Sprintf("%s.%s", path, w.name)
+		if err := w.reg.Validate(regPath); err != nil {
+			return nil, err
+		}
+		if !w.reg.Writable() {
+			return nil, utils.NewConfigValidationError(regPath, errors.Errorf("%s registers cannot be written to", w.reg.Table))
+		}
+	}
+	if conf.PositionRegister != nil {
+		if err := conf.PositionRegister.Validate(fmt.Sprintf("%s.%s", path, "position_register")); err != nil {
+			return nil, err
+		}
+	}
+	return conf.Config.Dependencies(), nil
+}
+
+func init() {
+	registry.RegisterComponent(motor.Subtype, modelname, registry.Component{
+		Constructor: func(ctx context.Context, deps registry.Dependencies, config config.Component, logger golog.Logger) (interface{}, error) {
+			conf, ok := config.ConvertedAttributes.(*Config)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(conf, config.ConvertedAttributes)
+			}
+			return NewMotor(ctx, deps, conf, config.Name, logger)
+		},
+	})
+
+	config.RegisterComponentAttributeMapConverter(
+		motor.Subtype,
+		modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf Config
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: &conf})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(attributes); err != nil {
+				return nil, err
+			}
+			return &conf, nil
+		},
+		&Config{},
+	)
+}
+
+// Motor is a motor whose drive is commanded over Modbus.
+type Motor struct {
+	generic.Unimplemented
+	name   string
+	cfg    Config
+	client mb.Client
+	logger golog.Logger
+
+	mu              sync.Mutex
+	isOn            bool
+	currentPowerPct float64
+	zeroPosition    float64
+
+	opMgr operation.SingleOperationManager
+}
+
+// NewMotor connects to the drive and makes sure it is stopped. The dependencies only need the
+// board of a drive on a board's serial port.
+func NewMotor(
+	ctx context.Context,
+	deps registry.Dependencies,
+	conf *Config,
+	name string,
+	logger golog.Logger,
+) (motor.LocalMotor, error) {
+	client, err := mb.NewClientFromDependencies(deps, conf.Config)
+	if err != nil {
+		return nil, err
+	}
+	cfg := *conf
+	if cfg.RunValue == 0 {
+		cfg.RunValue = 1
+	}
+	if cfg.ReverseValue == 0 {
+		cfg.ReverseValue = 1
+	}
+	m := &Motor{name: name, cfg: cfg, client: client, logger: logger}
+	if err := m.Stop(ctx, nil); err != nil {
+		return nil, multierr.Combine(err, client.Close())
+	}
+	return m, nil
+}
+
+// SetPower runs the drive at the given fraction of its maximum speed.
+func (m *Motor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	m.opMgr.CancelRunning(ctx)
+	return m.setPower(ctx, powerPct)
+}
+
+func (m *Motor) setPower(ctx context.Context, powerPct float64) error {
+	powerPct = math.Max(-1, math.Min(1, powerPct))
+	if powerPct == 0 {
+		return m.stop(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dirPct := powerPct
+	if m.cfg.DirectionFlip {
+		dirPct *= -1
+	}
+	speed := dirPct * m.cfg.MaxRPM
+	if m.cfg.DirectionRegister != nil {
+		dir := m.cfg.ForwardValue
+		if speed < 0 {
+			dir = m.cfg.ReverseValue
+		}
+		if err := m.cfg.DirectionRegister.Write(ctx, m.client, dir); err != nil {
+			return errors.Wrapf(err, "error setting direction of motor (%s)", m.name)
+		}
+		speed = math.Abs(speed)
+	}
+	if err := m.cfg.SpeedRegister.Write(ctx, m.client, speed); err != nil {
+		return errors.Wrapf(err, "error setting speed of motor (%s)", m.name)
+	}
+	if m.cfg.RunRegister != nil {
+		if err := m.cfg.RunRegister.Write(ctx, m.client, m.cfg.RunValue); err != nil {
+			return errors.Wrapf(err, "error starting motor (%s)", m.name)
+		}
+	}
+	m.isOn = true
+	m.currentPowerPct = powerPct
+	return nil
+}
+
+// GoFor runs the drive at the given rpm. Without a position register the distance traveled is
+// estimated from the time spent at the requested speed.
+func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+	if rpm == 0 {
+		return motor.NewZeroRPMError()
+	}
+	powerPct, waitDur := goForMath(m.cfg.MaxRPM, rpm, revolutions)
+	if revolutions == 0 {
+		return m.SetPower(ctx, powerPct, extra)
+	}
+	if m.cfg.PositionRegister != nil {
+		pos, err := m.Position(ctx, extra)
+		if err != nil {
+			return err
+		}
+		return m.goTo(ctx, powerPct, pos+math.Copysign(revolutions, powerPct))
+	}
+
+	if err := m.SetPower(ctx, powerPct, extra); err != nil {
+		return errors.Wrapf(err, "error in GoFor from motor (%s)", m.name)
+	}
+	if m.opMgr.NewTimedWaitOp(ctx, waitDur) {
+		return m.Stop(ctx, extra)
+	}
+	return nil
+}
+
+// GoTo runs the drive until the position register reports the target position.
+func (m *Motor) GoTo(ctx context.Context, rpm, positionRevolutions float64, extra map[string]interface{}) error {
+	if m.cfg.PositionRegister == nil {
+		return motor.NewGoToUnsupportedError(m.name)
+	}
+	if rpm == 0 {
+		return motor.NewZeroRPMError()
+	}
+	pos, err := m.Position(ctx, extra)
+	if err != nil {
+		return err
+	}
+	powerPct := math.Min(math.Abs(rpm), m.cfg.MaxRPM) / m.cfg.MaxRPM
+	if positionRevolutions < pos {
+		powerPct *= -1
+	}
+	return m.goTo(ctx, powerPct, positionRevolutions)
+}
+
+// goTo runs at powerPct until the position passes target, then stops.
+func (m *Motor) goTo(ctx context.Context, powerPct, target float64) error {
+	m.opMgr.CancelRunning(ctx)
+	if err := m.setPower(ctx, powerPct); err != nil {
+		return err
+	}
+	err := m.opMgr.WaitForSuccess(ctx, positionPollInterval, func(ctx context.Context) (bool, error) {
+		pos, err := m.Position(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		if powerPct > 0 {
+			return pos >= target, nil
+		}
+		return pos <= target, nil
+	})
+	if err != nil {
+		// the move was either interrupted by another command or failed; only stop in the latter case
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return multierr.Combine(err, m.stop(ctx))
+	}
+	return m.stop(ctx)
+}
+
+// GoTillStop is unsupported.
+func (m *Motor) GoTillStop(ctx context.Context, rpm float64, stopFunc func(ctx context.Context) bool) error {
+	return motor.NewGoTillStopUnsupportedError(m.name)
+}
+
+// ResetZeroPosition defines the current position to be the given offset.
+func (m *Motor) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	if m.cfg.PositionRegister == nil {
+		return motor.NewResetZeroPositionUnsupportedError(m.name)
+	}
+	raw, err := m.cfg.PositionRegister.ReadFloat(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.zeroPosition = raw - offset
+	return nil
+}
+
+// Position reports the position in revolutions, or 0 if there is no position register.
+func (m *Motor) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if m.cfg.PositionRegister == nil {
+		return 0, nil
+	}
+	raw, err := m.cfg.PositionRegister.ReadFloat(ctx, m.client)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error reading position of motor (%s)", m.name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return raw - m.zeroPosition, nil
+}
+
+// Properties returns the additional features supported by this motor.
+func (m *Motor) Properties(ctx context.Context, extra map[string]interface{}) (map[motor.Feature]bool, error) {
+	return map[motor.Feature]bool{
+		motor.PositionReporting: m.cfg.PositionRegister != nil,
+	}, nil
+}
+
+// Stop commands the drive to stop.
+func (m *Motor) Stop(ctx context.Context, extra map[string]interface{}) error {
+	_, done := m.opMgr.New(ctx)
+	defer done()
+	return m.stop(ctx)
+}
+
+func (m *Motor) stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var err error
+	if m.cfg.RunRegister != nil {
+		err = m.cfg.RunRegister.Write(ctx, m.client, m.cfg.StopValue)
+	}
+	err = multierr.Combine(err, m.cfg.SpeedRegister.Write(ctx, m.client, 0))
+	if err != nil {
+		return errors.Wrapf(err, "error stopping motor (%s)", m.name)
+	}
+	m.isOn = false
+	m.currentPowerPct = 0
+	return nil
+}
+
+// IsPowered returns whether the drive has been commanded to run and at what power.
+func (m *Motor) IsPowered(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isOn, m.currentPowerPct, nil
+}
+
+// IsMoving returns whether the drive has been commanded to run.
+func (m *Motor) IsMoving(ctx context.Context) (bool, error) {
+	on, _, err := m.IsPowered(ctx, nil)
+	return on, err
+}
+
+// Close stops the motor and releases the connection to the drive.
+func (m *Motor) Close(ctx context.Context) error {
+	return multierr.Combine(m.Stop(ctx, nil), m.client.Close())
+}
+
+// If revolutions is 0, the returned wait duration will be 0 representing that
+// the motor should run indefinitely.
+func goForMath(maxRPM, rpm, revolutions float64) (float64, time.Duration) {
+	// need to do this so time is reasonable
+	if rpm > maxRPM {
+		rpm = maxRPM
+	} else if rpm < -1*maxRPM {
+		rpm = -1 * maxRPM
+	}
+
+	if revolutions == 0 {
+		powerPct := rpm / maxRPM
+		return powerPct, 0
+	}
+
+	dir := rpm * revolutions / math.Abs(revolutions*rpm)
+	powerPct := math.Abs(rpm) / maxRPM * dir
+	waitDur := time.Duration(math.Abs(revolutions/rpm)*60*1000) * time.Millisecond
+	return powerPct, waitDur
+}
@@ -0,0 +1,192 @@
+package modbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	mb "go.viam.com/rdk/components/board/modbus"
+	"go.viam.com/rdk/components/motor"
+)
+
+const (
+	runCoil           = 0
+	speedRegister     = 1
+	directionRegister = 2
+	positionRegister  = 20
+)
+
+func newTestMotor(t *testing.T, server *mb.Server, withPosition bool) *Motor {
+	t.Helper()
+	conf := &Config{
+		Config:            mb.Config{Protocol: mb.ProtocolTCP, Address: server.Addr()},
+		SpeedRegister:     mb.RegisterConfig{Name: "speed", Table: mb.TableHoldingRegister, Address: speedRegister, Scale: 0.1},
+		RunRegister:       &mb.RegisterConfig{Name: "run", Table: mb.TableCoil, Address: runCoil},
+		DirectionRegister: &mb.RegisterConfig{Name: "direction", Table: mb.TableHoldingRegister, Address: directionRegister},
+		ReverseValue:      2,
+		MaxRPM:            300,
+	}
+	if withPosition {
+		conf.PositionRegister = &mb.RegisterConfig{
+			Name: "position", Table: mb.TableInputRegister, Address: positionRegister, Type: mb.TypeInt32, Scale: 0.01,
+		}
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+	m, err := NewMotor(context.Background(), nil, conf, "vfd", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return m.(*Motor)
+}
+
+func setPosition(server *mb.Server, revolutions float64) {
+	raw := uint32(int32(revolutions * 100))
+	server.SetInputRegister(positionRegister, uint16(raw>>16))
+	server.SetInputRegister(positionRegister+1, uint16(raw))
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := Config{Config: mb.Config{Protocol: mb.ProtocolTCP, Address: "localhost:502"}}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"max_rpm" is required`)
+
+	conf.MaxRPM = 100
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "path.speed_register")
+
+	conf.SpeedRegister = mb.RegisterConfig{Name: "speed", Table: mb.TableInputRegister}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot be written")
+
+	conf.SpeedRegister.Table = mb.TableHoldingRegister
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	conf.Config = mb.Config{Protocol: mb.ProtocolRTU, Board: "board1", Serial: "rs485"}
+	deps, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"board1"})
+}
+
+func TestMotor(t *testing.T) {
+	ctx := context.Background()
+	server, err := mb.NewServer("localhost:0", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+	server.SetCoil(runCoil, true)
+	server.SetHoldingRegister(speedRegister, 100)
+
+	m := newTestMotor(t, server, false)
+	defer func() {
+		test.That(t, m.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// the drive is stopped on startup
+	test.That(t, server.Coil(runCoil), test.ShouldBeFalse)
+	test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 0)
+
+	props, err := m.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props[motor.PositionReporting], test.ShouldBeFalse)
+
+	t.Run("set power", func(t *testing.T) {
+		test.That(t, m.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+		test.That(t, server.Coil(runCoil), test.ShouldBeTrue)
+		test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 1500)
+		test.That(t, server.HoldingRegister(directionRegister), test.ShouldEqual, 0)
+		on, powerPct, err := m.IsPowered(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, on, test.ShouldBeTrue)
+		test.That(t, powerPct, test.ShouldEqual, 0.5)
+
+		test.That(t, m.SetPower(ctx, -2, nil), test.ShouldBeNil)
+		test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 3000)
+		test.That(t, server.HoldingRegister(directionRegister), test.ShouldEqual, 2)
+
+		test.That(t, m.Stop(ctx, nil), test.ShouldBeNil)
+		test.That(t, server.Coil(runCoil), test.ShouldBeFalse)
+		test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 0)
+		moving, err := m.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("go for", func(t *testing.T) {
+		test.That(t, m.GoFor(ctx, 0, 1, nil), test.ShouldBeError, motor.NewZeroRPMError())
+
+		// 0.5 revolutions at 300 rpm takes 100ms
+		test.That(t, m.GoFor(ctx, -600, 0.5, nil), test.ShouldBeNil)
+		test.That(t, server.Coil(runCoil), test.ShouldBeFalse)
+		test.That(t, server.HoldingRegister(directionRegister), test.ShouldEqual, 2)
+
+		test.That(t, m.GoFor(ctx, 60, 0, nil), test.ShouldBeNil)
+		test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 600)
+		test.That(t, server.Coil(runCoil), test.ShouldBeTrue)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		test.That(t, m.GoTo(ctx, 10, 1, nil), test.ShouldBeError, motor.NewGoToUnsupportedError("vfd"))
+		test.That(t, m.ResetZeroPosition(ctx, 0, nil), test.ShouldBeError, motor.NewResetZeroPositionUnsupportedError("vfd"))
+	})
+}
+
+func TestMotorPosition(t *testing.T) {
+	ctx := context.Background()
+	server, err := mb.NewServer("localhost:0", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+	setPosition(server, 12.5)
+
+	m := newTestMotor(t, server, true)
+	defer func() {
+		test.That(t, m.Close(ctx), test.ShouldBeNil)
+	}()
+
+	props, err := m.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props[motor.PositionReporting], test.ShouldBeTrue)
+
+	pos, err := m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldAlmostEqual, 12.5)
+
+	test.That(t, m.ResetZeroPosition(ctx, 2, nil), test.ShouldBeNil)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldAlmostEqual, 2)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- m.GoTo(ctx, 30, 0, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, server.Coil(runCoil), test.ShouldBeTrue)
+	})
+	test.That(t, server.HoldingRegister(speedRegister), test.ShouldEqual, 300)
+	test.That(t, server.HoldingRegister(directionRegister), test.ShouldEqual, 2)
+
+	setPosition(server, 10.4)
+	select {
+	case err := <-errs:
+		test.That(t, err, test.ShouldBeNil)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for GoTo")
+	}
+	test.That(t, server.Coil(runCoil), test.ShouldBeFalse)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldAlmostEqual, -0.1)
+}
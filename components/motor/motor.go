@@ -45,6 +45,10 @@ func init() {
 		Subtype:    Subtype,
 		MethodName: isPowered.String(),
 	}, newIsPoweredCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		Subtype:    Subtype,
+		MethodName: diagnostics.String(),
+	}, newDiagnosticsCollector)
 }
 
 // SubtypeName is a constant that identifies the component resource subtype string "motor".
@@ -117,6 +121,7 @@ func Named(name string) resource.Name {
 
 var (
 	_ = Motor(&reconfigurableMotor{})
+	_ = DiagnosticsReporter(&reconfigurableMotor{})
 	_ = LocalMotor(&reconfigurableLocalMotor{})
 	_ = resource.Reconfigurable(&reconfigurableMotor{})
 	_ = resource.Reconfigurable(&reconfigurableLocalMotor{})
@@ -264,6 +269,12 @@ func (r *reconfigurableMotor) IsPowered(ctx context.Context, extra map[string]in
 	return r.actual.IsPowered(ctx, extra)
 }
 
+func (r *reconfigurableMotor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*Diagnostics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return DiagnosticsFromMotor(ctx, r.actual, r.name.ShortName(), extra)
+}
+
 func (r *reconfigurableMotor) Close(ctx context.Context) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
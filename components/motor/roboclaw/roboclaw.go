@@ -122,7 +122,52 @@ func newRoboClaw(deps registry.Dependencies, config config.Component, logger gol
 	return &roboclawMotor{name: config.Name, conn: c, conf: motorConfig, addr: uint8(motorConfig.Address), logger: logger}, nil
 }
 
-var _ = motor.LocalMotor(&roboclawMotor{})
+var (
+	_ = motor.LocalMotor(&roboclawMotor{})
+	_ = motor.DiagnosticsReporter(&roboclawMotor{})
+)
+
+// statusBit is a bit of the roboclaw's status register. Bits with a motor number only concern
+// that motor, the rest concern the whole controller.
+type statusBit struct {
+	mask  uint32
+	motor int
+	fault string
+}
+
+var statusBits = []statusBit{
+	{0x000001, 0, "e-stop"},
+	{0x000002, 0, "temperature error"},
+	{0x000004, 0, "temperature 2 error"},
+	{0x000008, 0, "main voltage high error"},
+	{0x000010, 0, "logic voltage high error"},
+	{0x000020, 0, "logic voltage low error"},
+	{0x000040, 1, "driver fault"},
+	{0x000080, 2, "driver fault"},
+	{0x000100, 1, "speed error"},
+	{0x000200, 2, "speed error"},
+	{0x000400, 1, "position error"},
+	{0x000800, 2, "position error"},
+	{0x001000, 1, "current error"},
+	{0x002000, 2, "current error"},
+	{0x010000, 1, "over current warning"},
+	{0x020000, 2, "over current warning"},
+	{0x040000, 0, "main voltage high warning"},
+	{0x080000, 0, "main voltage low warning"},
+	{0x100000, 0, "temperature warning"},
+	{0x200000, 0, "temperature 2 warning"},
+}
+
+// statusFaults returns the faults in a status register value that concern the given motor.
+func statusFaults(status uint32, number int) []string {
+	var faults []string
+	for _, b := range statusBits {
+		if status&b.mask != 0 && (b.motor == 0 || b.motor == number) {
+			faults = append(faults, b.fault)
+		}
+	}
+	return faults
+}
 
 type roboclawMotor struct {
 	name string
@@ -252,6 +297,46 @@ func (m *roboclawMotor) IsPowered(ctx context.Context, extra map[string]interfac
 	}
 }
 
+// Diagnostics reads the motor's current along with the controller's temperature, battery
+// voltage and status flags.
+func (m *roboclawMotor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+	current1, current2, err := m.conn.ReadCurrents(m.addr)
+	if err != nil {
+		return nil, err
+	}
+	var current float64
+	switch m.conf.Number {
+	case 1:
+		current = float64(current1) / 100
+	case 2:
+		current = float64(current2) / 100
+	default:
+		return nil, m.conf.wrongNumberError()
+	}
+	temp, err := m.conn.ReadTemp(m.addr)
+	if err != nil {
+		return nil, err
+	}
+	voltage, err := m.conn.ReadMainBatteryVoltage(m.addr)
+	if err != nil {
+		return nil, err
+	}
+	status, err := m.conn.ReadError(m.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the roboclaw reports currents in 10mA, and temperatures and voltages in tenths
+	tempC := float64(temp) / 10
+	volts := float64(voltage) / 10
+	return &motor.Diagnostics{
+		CurrentAmps:        &current,
+		TemperatureCelsius: &tempC,
+		VoltageVolts:       &volts,
+		Faults:             statusFaults(status, m.conf.Number),
+	}, nil
+}
+
 func (m *roboclawMotor) GoTillStop(ctx context.Context, rpm float64, stopFunc func(ctx context.Context) bool) error {
 	return motor.NewGoTillStopUnsupportedError(m.name)
 }
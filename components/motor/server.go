@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/motor/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/protoutils"
@@ -194,5 +195,18 @@ func (server *subtypeServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	cmd := req.GetCommand().AsMap()
+	if value, ok := cmd[DiagnosticsCommand]; ok {
+		extra, _ := value.(map[string]interface{})
+		diag, err := DiagnosticsFromMotor(ctx, motor, req.GetName(), extra)
+		if err != nil {
+			return nil, err
+		}
+		res, err := structpb.NewStruct(DiagnosticsToMap(diag))
+		if err != nil {
+			return nil, err
+		}
+		return &commonpb.DoCommandResponse{Result: res}, nil
+	}
 	return protoutils.DoFromResourceServer(ctx, motor, req)
 }
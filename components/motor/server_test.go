@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/motor/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils/protoutils"
@@ -247,6 +248,52 @@ func TestServerResetZeroPosition(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestServerDiagnostics(t *testing.T) {
+	motorServer, workingMotor, failingMotor, _ := newServer()
+
+	cmd, err := protoutils.StructToStructPb(map[string]interface{}{motor.DiagnosticsCommand: map[string]interface{}{}})
+	test.That(t, err, test.ShouldBeNil)
+
+	// fails on a bad motor
+	resp, err := motorServer.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: fakeMotorName, Command: cmd})
+	test.That(t, resp, test.ShouldBeNil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// fails on a motor that cannot report diagnostics
+	resp, err = motorServer.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: failMotorName, Command: cmd})
+	test.That(t, resp, test.ShouldBeNil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	failingMotor.DiagnosticsFunc = func(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+		return nil, errors.New("diagnostics failed")
+	}
+	resp, err = motorServer.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: failMotorName, Command: cmd})
+	test.That(t, resp, test.ShouldBeNil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	temp := 45.5
+	workingMotor.DiagnosticsFunc = func(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+		return &motor.Diagnostics{TemperatureCelsius: &temp, Faults: []string{"temperature warning"}}, nil
+	}
+	resp, err = motorServer.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: testMotorName, Command: cmd})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Result.AsMap(), test.ShouldResemble, map[string]interface{}{
+		"temperature_celsius": 45.5,
+		"stalled":             false,
+		"faults":              []interface{}{"temperature warning"},
+	})
+
+	// a model's own diagnostics command is passed through to it
+	workingMotor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"model": cmd["command"]}, nil
+	}
+	modelCmd, err := protoutils.StructToStructPb(map[string]interface{}{"command": "diagnostics"})
+	test.That(t, err, test.ShouldBeNil)
+	resp, err = motorServer.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: testMotorName, Command: modelCmd})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Result.AsMap(), test.ShouldResemble, map[string]interface{}{"model": "diagnostics"})
+}
+
 func TestServerExtraParams(t *testing.T) {
 	motorServer, workingMotor, _, _ := newServer()
 
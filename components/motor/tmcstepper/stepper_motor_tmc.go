@@ -109,6 +109,8 @@ type Motor struct {
 	motorName   string
 }
 
+var _ = motor.DiagnosticsReporter(&Motor{})
+
 // TMC5072 Values.
 const (
 	baseClk = 13200000 // Nominal 13.2mhz internal clock speed
@@ -377,6 +379,38 @@ func (m *Motor) readReg(ctx context.Context, addr uint8) (int32, error) {
 	return value, nil
 }
 
+// DRV_STATUS flags reported as faults by Diagnostics.
+var drvStatusFaults = []struct {
+	mask  int32
+	fault string
+}{
+	{1 << 25, "overtemperature"},
+	{1 << 26, "overtemperature prewarning"},
+	{1 << 27, "short to ground on phase A"},
+	{1 << 28, "short to ground on phase B"},
+	{1 << 29, "open load on phase A"},
+	{1 << 30, "open load on phase B"},
+}
+
+// drvStatusStallGuard is the DRV_STATUS flag set when StallGuard detects a stall.
+const drvStatusStallGuard = 1 << 24
+
+// Diagnostics reports the stall and fault flags of the driver's DRV_STATUS register. The TMC5072
+// measures neither current nor temperature, so those are left unset.
+func (m *Motor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+	status, err := m.readReg(ctx, drvStatus)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error in Diagnostics from motor (%s)", m.motorName)
+	}
+	d := &motor.Diagnostics{Stalled: status&drvStatusStallGuard != 0}
+	for _, f := range drvStatusFaults {
+		if status&f.mask != 0 {
+			d.Faults = append(d.Faults, f.fault)
+		}
+	}
+	return d, nil
+}
+
 // GetSG returns the current StallGuard reading (effectively an indication of motor load.)
 func (m *Motor) GetSG(ctx context.Context) (int32, error) {
 	rawRead, err := m.readReg(ctx, drvStatus)
@@ -146,6 +146,25 @@ func TestTMCStepperMotor(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 	})
 
+	t.Run("motor diagnostics testing", func(t *testing.T) {
+		// stallguard and overtemperature prewarning set
+		go checkRx(t, c,
+			[][]byte{
+				{111, 0, 0, 0, 0},
+				{111, 0, 0, 0, 0},
+			},
+			[][]byte{
+				{0, 0, 0, 0, 0},
+				{0, 5, 0, 0, 0},
+			},
+		)
+		diag, err := _motor.(motor.DiagnosticsReporter).Diagnostics(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, diag.Stalled, test.ShouldBeTrue)
+		test.That(t, diag.Faults, test.ShouldResemble, []string{"overtemperature prewarning"})
+		test.That(t, diag.CurrentAmps, test.ShouldBeNil)
+	})
+
 	t.Run("motor GoFor with positive rpm and positive revolutions", func(t *testing.T) {
 		// Check with position at 0.0 revolutions
 		go checkRx(t, c,
@@ -307,6 +307,9 @@ func (adxl *adxl345) Properties(ctx context.Context, extra map[string]interface{
 
 // Puts the chip into standby mode.
 func (adxl *adxl345) Close(ctx context.Context) {
+	adxl.cancelFunc()
+	adxl.activeBackgroundWorkers.Wait()
+
 	adxl.mu.Lock()
 	defer adxl.mu.Unlock()
 	// Put the chip into standby mode by setting the Power Control register (0x2D) to 0.
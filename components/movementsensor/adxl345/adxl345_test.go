@@ -0,0 +1,56 @@
+package adxl345
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestLinearAcceleration(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	chip := simdevice.NewADXL345()
+	chip.SetLinearAcceleration(r3.Vector{X: 0, Y: -9810, Z: 4905})
+	bus.Attach(0x1D, chip)
+	deps := registry.Dependencies{
+		board.Named("board"): &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) {
+			return bus, name == "main"
+		}},
+	}
+	cfg := config.Component{
+		Name:                "accel",
+		ConvertedAttributes: &AttrConfig{BoardName: "board", I2cBus: "main", UseAlternateI2CAddress: true},
+	}
+
+	ms, err := NewAdxl345(ctx, deps, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chip.Measuring(), test.ShouldBeTrue)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		accel, err := ms.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, accel, test.ShouldResemble, r3.Vector{X: 0, Y: -9810, Z: 4905})
+	})
+
+	ms.(*adxl345).Close(ctx)
+	test.That(t, chip.Measuring(), test.ShouldBeFalse)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+
+	// a different chip at the address is refused
+	bus.Attach(0x1D, simdevice.NewMPU6050())
+	_, err = NewAdxl345(ctx, deps, cfg, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unexpected I2C device instead of ADXL345")
+}
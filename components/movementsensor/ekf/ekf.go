@@ -0,0 +1,498 @@
+// Package ekf implements a movement sensor that fuses the readings of other movement sensors, such
+// as GPS receivers, IMUs and wheel odometry, with an extended Kalman filter.
+//
+// The filter tracks planar motion: position, compass heading, forward speed and turn rate. Each
+// configured source contributes only the quantities it has a standard deviation configured for, so
+// a noisy magnetometer or a slow GPS can be weighted against better sources. Readings that are too
+// far from the current estimate, given its covariance and the source's, are rejected as outliers.
+//
+// Positions are taken to be geographic, as reported by GPS receivers. They are tracked in a local
+// east/north frame centered on the first position received and converted back on output.
+package ekf
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("ekf")
+
+const (
+	defaultUpdateRateHz          = 20
+	defaultAccelStdDevMMPerSec2  = 500
+	defaultAngularAccelStdDevDeg = 90
+	defaultOutlierThreshold      = 5
+	// maxConsecutiveRejections is the number of outliers in a row from a single source after which
+	// its next reading is accepted regardless, so that the filter can recover if it is the estimate,
+	// not the source, that is wrong.
+	maxConsecutiveRejections = 10
+	// sourceFailureThreshold is how long a source can fail to be read before the error is
+	// returned from the fused readings. Shorter failures are expected from sources such as GPS
+	// receivers losing their fix for a moment, and the filter carries on predicting through them.
+	sourceFailureThreshold = 2 * time.Second
+	earthRadiusMM          = 6371e6
+)
+
+// SourceConfig describes how to use the readings of a single movement sensor. Only the quantities
+// with a positive standard deviation are read from the sensor.
+type SourceConfig struct {
+	Name                            string  `json:"name"`
+	PositionStdDevMM                float64 `json:"position_std_dev_mm,omitempty"`
+	CompassHeadingStdDevDegs        float64 `json:"compass_heading_std_dev_degs,omitempty"`
+	LinearVelocityStdDevMMPerSec    float64 `json:"linear_velocity_std_dev_mm_per_sec,omitempty"`
+	AngularVelocityStdDevDegsPerSec float64 `json:"angular_velocity_std_dev_degs_per_sec,omitempty"`
+}
+
+// AttrConfig is used for converting config attributes of a fused movement sensor.
+type AttrConfig struct {
+	Sources []SourceConfig `json:"sources"`
+	// UpdateRateHz is how often the sources are read.
+	UpdateRateHz float64 `json:"update_rate_hz,omitempty"`
+	// AccelStdDevMMPerSec2 and AngularAccelStdDevDegsPerSec2 describe how quickly the speed and
+	// turn rate of the robot can change, which sets how much the filter trusts its own prediction.
+	AccelStdDevMMPerSec2          float64 `json:"accel_std_dev_mm_per_sec2,omitempty"`
+	AngularAccelStdDevDegsPerSec2 float64 `json:"angular_accel_std_dev_degs_per_sec2,omitempty"`
+	// OutlierThreshold is the Mahalanobis distance beyond which a reading is rejected.
+	OutlierThreshold float64 `json:"outlier_threshold,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *AttrConfig) Validate(path string) ([]string, error) {
+	if len(cfg.Sources) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "sources")
+	}
+	var deps []string
+	for i, src := range cfg.Sources {
+		if src.Name == "" {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("sources.%d needs a name", i))
+		}
+		if src.PositionStdDevMM < 0 || src.CompassHeadingStdDevDegs < 0 ||
+			src.LinearVelocityStdDevMMPerSec < 0 || src.AngularVelocityStdDevDegsPerSec < 0 {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("source %q has a negative standard deviation", src.Name))
+		}
+		if src.PositionStdDevMM == 0 && src.CompassHeadingStdDevDegs == 0 &&
+			src.LinearVelocityStdDevMMPerSec == 0 && src.AngularVelocityStdDevDegsPerSec == 0 {
+			return nil, utils.NewConfigValidationError(path,
+				errors.Errorf("source %q needs a standard deviation for at least one quantity", src.Name))
+		}
+		deps = append(deps, src.Name)
+	}
+	if cfg.UpdateRateHz < 0 || cfg.AccelStdDevMMPerSec2 < 0 || cfg.AngularAccelStdDevDegsPerSec2 < 0 || cfg.OutlierThreshold < 0 {
+		return nil, utils.NewConfigValidationError(path,
+			errors.New("update_rate_hz, accel_std_dev_mm_per_sec2, angular_accel_std_dev_degs_per_sec2 "+
+				"and outlier_threshold cannot be negative"))
+	}
+	return deps, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		model,
+		registry.Component{
+			Constructor: func(
+				ctx context.Context,
+				deps registry.Dependencies,
+				cfg config.Component,
+				logger golog.Logger,
+			) (interface{}, error) {
+				return newFusedMovementSensor(deps, cfg, logger)
+			},
+		})
+	config.RegisterComponentAttributeMapConverter(
+		movementsensor.Subtype,
+		model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&AttrConfig{})
+}
+
+type source struct {
+	SourceConfig
+	sensor movementsensor.MovementSensor
+
+	lastPosition        *geo.Point
+	failingSince        time.Time
+	failureLogged       bool
+	rejectedPositions   int
+	rejectedHeadings    int
+	rejectedLinearVels  int
+	rejectedAngularVels int
+}
+
+type fusedMovementSensor struct {
+	generic.Unimplemented
+	sources []*source
+
+	mu                 sync.RWMutex
+	filter             *filter
+	origin             *geo.Point
+	headingInitialized bool
+	lastPredict        time.Time
+	// err is what failed in the last step. Every read reports it until a step succeeds, so it is
+	// not cleared on read like a movementsensor.LastError.
+	err error
+
+	updateInterval          time.Duration
+	cancelCtx               context.Context
+	cancelFunc              func()
+	activeBackgroundWorkers sync.WaitGroup
+	logger                  golog.Logger
+}
+
+func newFusedMovementSensor(
+	deps registry.Dependencies,
+	cfg config.Component,
+	logger golog.Logger,
+) (movementsensor.MovementSensor, error) {
+	conf, ok := cfg.ConvertedAttributes.(*AttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(conf, cfg.ConvertedAttributes)
+	}
+
+	rate := conf.UpdateRateHz
+	if rate == 0 {
+		rate = defaultUpdateRateHz
+	}
+	accel := conf.AccelStdDevMMPerSec2
+	if accel == 0 {
+		accel = defaultAccelStdDevMMPerSec2
+	}
+	angularAccel := conf.AngularAccelStdDevDegsPerSec2
+	if angularAccel == 0 {
+		angularAccel = defaultAngularAccelStdDevDeg
+	}
+	gate := conf.OutlierThreshold
+	if gate == 0 {
+		gate = defaultOutlierThreshold
+	}
+
+	fms := &fusedMovementSensor{
+		filter:         newFilter(accel, rdkutils.DegToRad(angularAccel), gate),
+		updateInterval: time.Duration(float64(time.Second) / rate),
+		logger:         logger,
+	}
+	for _, srcConf := range conf.Sources {
+		ms, err := movementsensor.FromDependencies(deps, srcConf.Name)
+		if err != nil {
+			return nil, err
+		}
+		fms.sources = append(fms.sources, &source{SourceConfig: srcConf, sensor: ms})
+	}
+
+	fms.cancelCtx, fms.cancelFunc = context.WithCancel(context.Background())
+	fms.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(fms.run, fms.activeBackgroundWorkers.Done)
+	return fms, nil
+}
+
+func (fms *fusedMovementSensor) run() {
+	ticker := time.NewTicker(fms.updateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-fms.cancelCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		fms.step(fms.cancelCtx, time.Now())
+	}
+}
+
+// step predicts the state up to now and then corrects it with the current readings of every source.
+func (fms *fusedMovementSensor) step(ctx context.Context, now time.Time) {
+	fms.mu.Lock()
+	if !fms.lastPredict.IsZero() {
+		fms.filter.predict(now.Sub(fms.lastPredict).Seconds())
+	}
+	fms.lastPredict = now
+	fms.mu.Unlock()
+
+	// an error is only reported until the step after the sources recover
+	var failing error
+	for _, src := range fms.sources {
+		err := fms.updateFromSource(ctx, src)
+		if err == nil {
+			src.failingSince = time.Time{}
+			src.failureLogged = false
+			continue
+		}
+		if errors.Is(err, context.Canceled) {
+			continue
+		}
+		if src.failingSince.IsZero() {
+			src.failingSince = now
+			fms.logger.Debugw("failed to read from fusion source", "source", src.Name, "error", err)
+		}
+		if failingFor := now.Sub(src.failingSince); failingFor >= sourceFailureThreshold {
+			if !src.failureLogged {
+				src.failureLogged = true
+				fms.logger.Warnw("fusion source keeps failing, fusing the other sources without it", "source", src.Name, "error", err)
+			}
+			failing = multierr.Combine(failing, errors.Wrapf(err, "fusion source %q has been failing for %v", src.Name, failingFor))
+		}
+	}
+	fms.mu.Lock()
+	fms.err = failing
+	fms.mu.Unlock()
+}
+
+// updateFromSource corrects the state with each of the quantities read from the source, returning
+// the errors reading any of them.
+func (fms *fusedMovementSensor) updateFromSource(ctx context.Context, src *source) error {
+	var errs error
+	if src.PositionStdDevMM > 0 {
+		// Low rate sources such as GPS report the same position until they get a new fix, so a
+		// repeated position is not new information.
+		pos, _, err := src.sensor.Position(ctx, nil)
+		if err != nil {
+			errs = multierr.Combine(errs, err)
+		} else if pos != nil && (src.lastPosition == nil || *pos != *src.lastPosition) {
+			src.lastPosition = pos
+			fms.updatePosition(src, pos)
+		}
+	}
+	if src.CompassHeadingStdDevDegs > 0 {
+		heading, err := src.sensor.CompassHeading(ctx, nil)
+		if err != nil {
+			errs = multierr.Combine(errs, err)
+		} else {
+			fms.updateHeading(src, heading)
+		}
+	}
+	if src.LinearVelocityStdDevMMPerSec > 0 {
+		vel, err := src.sensor.LinearVelocity(ctx, nil)
+		if err != nil {
+			errs = multierr.Combine(errs, err)
+		} else {
+			fms.updateScalar(stateSpeed, vel.Y, src.LinearVelocityStdDevMMPerSec, &src.rejectedLinearVels)
+		}
+	}
+	if src.AngularVelocityStdDevDegsPerSec > 0 {
+		angVel, err := src.sensor.AngularVelocity(ctx, nil)
+		if err != nil {
+			errs = multierr.Combine(errs, err)
+		} else {
+			// angular velocities are counter-clockwise, the heading rate is clockwise
+			fms.updateScalar(stateHeadingRate, -rdkutils.DegToRad(angVel.Z),
+				rdkutils.DegToRad(src.AngularVelocityStdDevDegsPerSec), &src.rejectedAngularVels)
+		}
+	}
+	return errs
+}
+
+func (fms *fusedMovementSensor) updatePosition(src *source, pos *geo.Point) {
+	fms.mu.Lock()
+	defer fms.mu.Unlock()
+
+	variance := src.PositionStdDevMM * src.PositionStdDevMM
+	if fms.origin == nil {
+		fms.origin = pos
+		fms.filter.reset(stateEast, 0, variance)
+		fms.filter.reset(stateNorth, 0, variance)
+		return
+	}
+	east, north := fms.toLocal(pos)
+	m := measurement{
+		z: mat.NewVecDense(2, []float64{east, north}),
+		h: mat.NewDense(2, stateSize, nil),
+		r: mat.NewSymDense(2, []float64{variance, 0, 0, variance}),
+	}
+	m.h.Set(0, stateEast, 1)
+	m.h.Set(1, stateNorth, 1)
+	fms.applyLocked(m, &src.rejectedPositions)
+}
+
+func (fms *fusedMovementSensor) updateHeading(src *source, headingDeg float64) {
+	fms.mu.Lock()
+	defer fms.mu.Unlock()
+
+	heading := normalizeAngle(rdkutils.DegToRad(headingDeg))
+	stdDev := rdkutils.DegToRad(src.CompassHeadingStdDevDegs)
+	if !fms.headingInitialized {
+		fms.headingInitialized = true
+		fms.filter.reset(stateHeading, heading, stdDev*stdDev)
+		return
+	}
+	m := scalarMeasurement(stateHeading, heading, stdDev)
+	m.angles = []int{0}
+	fms.applyLocked(m, &src.rejectedHeadings)
+}
+
+func (fms *fusedMovementSensor) updateScalar(index int, value, stdDev float64, rejected *int) {
+	fms.mu.Lock()
+	defer fms.mu.Unlock()
+	fms.applyLocked(scalarMeasurement(index, value, stdDev), rejected)
+}
+
+// applyLocked updates the filter with a measurement, keeping count of the consecutive rejections.
+func (fms *fusedMovementSensor) applyLocked(m measurement, rejected *int) {
+	if fms.filter.update(m, *rejected >= maxConsecutiveRejections) {
+		*rejected = 0
+		return
+	}
+	*rejected++
+}
+
+func scalarMeasurement(index int, value, stdDev float64) measurement {
+	h := mat.NewDense(1, stateSize, nil)
+	h.Set(0, index, 1)
+	return measurement{
+		z: mat.NewVecDense(1, []float64{value}),
+		h: h,
+		r: mat.NewSymDense(1, []float64{stdDev * stdDev}),
+	}
+}
+
+// toLocal converts a geographic point to mm east and north of the origin. An equirectangular
+// projection is plenty accurate over the distances a robot covers.
+func (fms *fusedMovementSensor) toLocal(pos *geo.Point) (float64, float64) {
+	north := rdkutils.DegToRad(pos.Lat()-fms.origin.Lat()) * earthRadiusMM
+	east := rdkutils.DegToRad(pos.Lng()-fms.origin.Lng()) * earthRadiusMM * math.Cos(rdkutils.DegToRad(fms.origin.Lat()))
+	return east, north
+}
+
+func (fms *fusedMovementSensor) fromLocal(east, north float64) *geo.Point {
+	lat := fms.origin.Lat() + rdkutils.RadToDeg(north/earthRadiusMM)
+	lng := fms.origin.Lng() + rdkutils.RadToDeg(east/(earthRadiusMM*math.Cos(rdkutils.DegToRad(fms.origin.Lat()))))
+	return geo.NewPoint(lat, lng)
+}
+
+// Position returns the fused geographic position.
+func (fms *fusedMovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	if fms.origin == nil {
+		if !fms.supports(func(src *source) bool { return src.PositionStdDevMM > 0 }) {
+			return nil, 0, movementsensor.ErrMethodUnimplementedPosition
+		}
+		if err := fms.err; err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, errors.New("no position has been received from any source yet")
+	}
+	return fms.fromLocal(fms.filter.x.AtVec(stateEast), fms.filter.x.AtVec(stateNorth)), 0, fms.err
+}
+
+// CompassHeading returns the fused heading in degrees clockwise from north.
+func (fms *fusedMovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	if !fms.headingInitialized {
+		if !fms.supports(func(src *source) bool { return src.CompassHeadingStdDevDegs > 0 }) {
+			return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+		}
+		if err := fms.err; err != nil {
+			return 0, err
+		}
+		return 0, errors.New("no heading has been received from any source yet")
+	}
+	heading := rdkutils.RadToDeg(fms.filter.x.AtVec(stateHeading))
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, fms.err
+}
+
+// Orientation returns the fused heading as a yaw, counter-clockwise from north.
+func (fms *fusedMovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	if !fms.headingInitialized {
+		if !fms.supports(func(src *source) bool { return src.CompassHeadingStdDevDegs > 0 }) {
+			return nil, movementsensor.ErrMethodUnimplementedOrientation
+		}
+		if err := fms.err; err != nil {
+			return nil, err
+		}
+		return nil, errors.New("no heading has been received from any source yet")
+	}
+	return &spatialmath.EulerAngles{Yaw: -fms.filter.x.AtVec(stateHeading)}, fms.err
+}
+
+// LinearVelocity returns the fused forward speed in mm/sec.
+func (fms *fusedMovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	return r3.Vector{Y: fms.filter.x.AtVec(stateSpeed)}, fms.err
+}
+
+// AngularVelocity returns the fused turn rate in degrees/sec, counter-clockwise.
+func (fms *fusedMovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	return spatialmath.AngularVelocity{Z: -rdkutils.RadToDeg(fms.filter.x.AtVec(stateHeadingRate))}, fms.err
+}
+
+func (fms *fusedMovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+// Accuracy reports the covariance of the fused estimate. The position variances and covariance
+// are in mm^2, the heading variance in degrees^2, the linear velocity variance in (mm/sec)^2 and
+// the angular velocity variance in (degrees/sec)^2.
+func (fms *fusedMovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	fms.mu.RLock()
+	defer fms.mu.RUnlock()
+	p := fms.filter.p
+	degSq := rdkutils.RadToDeg(1) * rdkutils.RadToDeg(1)
+	return map[string]float32{
+		"position_east_variance":         float32(p.At(stateEast, stateEast)),
+		"position_north_variance":        float32(p.At(stateNorth, stateNorth)),
+		"position_east_north_covariance": float32(p.At(stateEast, stateNorth)),
+		"compass_heading_variance":       float32(p.At(stateHeading, stateHeading) * degSq),
+		"linear_velocity_variance":       float32(p.At(stateSpeed, stateSpeed)),
+		"angular_velocity_variance":      float32(p.At(stateHeadingRate, stateHeadingRate) * degSq),
+	}, nil
+}
+
+func (fms *fusedMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.Readings(ctx, fms, extra)
+}
+
+func (fms *fusedMovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	headingSupported := fms.supports(func(src *source) bool { return src.CompassHeadingStdDevDegs > 0 })
+	return &movementsensor.Properties{
+		PositionSupported:        fms.supports(func(src *source) bool { return src.PositionStdDevMM > 0 }),
+		CompassHeadingSupported:  headingSupported,
+		OrientationSupported:     headingSupported,
+		LinearVelocitySupported:  fms.supports(func(src *source) bool { return src.LinearVelocityStdDevMMPerSec > 0 }),
+		AngularVelocitySupported: fms.supports(func(src *source) bool { return src.AngularVelocityStdDevDegsPerSec > 0 }),
+	}, nil
+}
+
+// supports returns whether any source satisfies the given predicate.
+func (fms *fusedMovementSensor) supports(pred func(src *source) bool) bool {
+	for _, src := range fms.sources {
+		if pred(src) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops reading from the sources.
+func (fms *fusedMovementSensor) Close() {
+	fms.cancelFunc()
+	fms.activeBackgroundWorkers.Wait()
+}
@@ -0,0 +1,207 @@
+package ekf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	cfg := &AttrConfig{}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "sources")
+
+	cfg.Sources = []SourceConfig{{Name: "gps"}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one quantity")
+
+	cfg.Sources = []SourceConfig{{Name: "gps", PositionStdDevMM: -1}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "negative")
+
+	cfg.Sources = []SourceConfig{
+		{Name: "gps", PositionStdDevMM: 2000},
+		{Name: "imu", CompassHeadingStdDevDegs: 10, AngularVelocityStdDevDegsPerSec: 1},
+	}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"gps", "imu"})
+}
+
+func TestFusion(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	origin := geo.NewPoint(40.7, -73.98)
+	gpsPos := origin
+	gps := &inject.MovementSensor{
+		PositionFunc: func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+			return gpsPos, 0, nil
+		},
+	}
+	heading := 90.0
+	imu := &inject.MovementSensor{
+		CompassHeadingFunc: func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+			return heading, nil
+		},
+		AngularVelocityFunc: func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+			return spatialmath.AngularVelocity{}, nil
+		},
+	}
+	odometry := &inject.MovementSensor{
+		LinearVelocityFunc: func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+			return r3.Vector{Y: 1000}, nil
+		},
+	}
+	deps := registry.Dependencies{
+		movementsensor.Named("gps"):      gps,
+		movementsensor.Named("imu"):      imu,
+		movementsensor.Named("odometry"): odometry,
+	}
+	attrs := &AttrConfig{
+		Sources: []SourceConfig{
+			{Name: "gps", PositionStdDevMM: 1000},
+			{Name: "imu", CompassHeadingStdDevDegs: 5, AngularVelocityStdDevDegsPerSec: 1},
+			{Name: "odometry", LinearVelocityStdDevMMPerSec: 50},
+		},
+		// slow enough that only the steps below update the filter
+		UpdateRateHz: 0.001,
+	}
+	ms, err := newFusedMovementSensor(deps, config.Component{ConvertedAttributes: attrs}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	fms := ms.(*fusedMovementSensor)
+	defer fms.Close()
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		PositionSupported:        true,
+		CompassHeadingSupported:  true,
+		OrientationSupported:     true,
+		LinearVelocitySupported:  true,
+		AngularVelocitySupported: true,
+	})
+
+	_, _, err = ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// drive east at 1 m/sec for 10 seconds, with a 1 Hz GPS
+	start := time.Now()
+	for i := 0; i <= 100; i++ {
+		if i > 0 && i%10 == 0 {
+			gpsPos = fms.fromLocal(float64(i)*100, 0)
+		}
+		fms.step(ctx, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+
+	pos, _, err := ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos.GreatCircleDistance(gpsPos)*1000, test.ShouldBeLessThan, 1)
+
+	compass, err := ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, compass, test.ShouldAlmostEqual, 90, 1)
+
+	vel, err := ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.Y, test.ShouldAlmostEqual, 1000, 10)
+
+	// a wild heading reading is rejected as an outlier
+	heading = 270
+	fms.step(ctx, start.Add(10100*time.Millisecond))
+	compass, err = ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, compass, test.ShouldAlmostEqual, 90, 1)
+
+	accuracy, err := ms.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accuracy["position_east_variance"], test.ShouldBeLessThan, 1000*1000)
+	test.That(t, accuracy["compass_heading_variance"], test.ShouldBeLessThan, 5*5)
+
+	_, err = ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestFailingSource(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	gpsErr := errors.New("no fix")
+	gps := &inject.MovementSensor{
+		PositionFunc: func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+			return nil, 0, gpsErr
+		},
+	}
+	odometry := &inject.MovementSensor{
+		LinearVelocityFunc: func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+			return r3.Vector{Y: 1000}, nil
+		},
+		AngularVelocityFunc: func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+			return spatialmath.AngularVelocity{}, errors.New("no gyro")
+		},
+	}
+	deps := registry.Dependencies{
+		movementsensor.Named("gps"):      gps,
+		movementsensor.Named("odometry"): odometry,
+	}
+	attrs := &AttrConfig{
+		Sources: []SourceConfig{
+			{Name: "gps", PositionStdDevMM: 1000},
+			{Name: "odometry", LinearVelocityStdDevMMPerSec: 50, AngularVelocityStdDevDegsPerSec: 1},
+		},
+		UpdateRateHz: 0.001,
+	}
+	ms, err := newFusedMovementSensor(deps, config.Component{ConvertedAttributes: attrs}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	fms := ms.(*fusedMovementSensor)
+	defer fms.Close()
+
+	// the healthy quantities keep updating the filter while the others fail
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		fms.step(ctx, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	vel, err := ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.Y, test.ShouldAlmostEqual, 1000, 10)
+
+	// until the sources have been failing for long enough, which is then reported
+	fms.step(ctx, start.Add(sourceFailureThreshold))
+	_, err = ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "has been failing for")
+
+	// to every read, not just the first one after the step
+	for i := 0; i < 2; i++ {
+		_, err = ms.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "has been failing for")
+	}
+
+	// and stops being reported once they recover
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return geo.NewPoint(40.7, -73.98), 0, nil
+	}
+	odometry.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{}, nil
+	}
+	fms.step(ctx, start.Add(sourceFailureThreshold+100*time.Millisecond))
+	_, err = ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fms.sources[0].failingSince.IsZero(), test.ShouldBeTrue)
+}
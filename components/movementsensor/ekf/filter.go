@@ -0,0 +1,186 @@
+package ekf
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Indices into the state vector. The state describes planar motion in a local east/north frame:
+// position in mm, compass heading in radians clockwise from north, forward speed in mm/sec and
+// heading rate in radians/sec clockwise.
+const (
+	stateEast = iota
+	stateNorth
+	stateHeading
+	stateSpeed
+	stateHeadingRate
+	stateSize
+)
+
+// initialVariance is the variance assigned to a state element nothing has been measured about yet.
+const initialVariance = 1e12
+
+// filter is an extended Kalman filter over a constant speed, constant turn rate motion model.
+type filter struct {
+	x *mat.VecDense
+	p *mat.SymDense
+
+	// accelStdDev is the standard deviation, in mm/sec^2, of the unmodelled forward acceleration.
+	accelStdDev float64
+	// angularAccelStdDev is the standard deviation, in radians/sec^2, of the unmodelled angular acceleration.
+	angularAccelStdDev float64
+	// gate is the Mahalanobis distance beyond which a measurement is rejected as an outlier.
+	gate float64
+}
+
+func newFilter(accelStdDev, angularAccelStdDev, gate float64) *filter {
+	p := mat.NewSymDense(stateSize, nil)
+	for i := 0; i < stateSize; i++ {
+		p.SetSym(i, i, initialVariance)
+	}
+	return &filter{
+		x:                  mat.NewVecDense(stateSize, nil),
+		p:                  p,
+		accelStdDev:        accelStdDev,
+		angularAccelStdDev: angularAccelStdDev,
+		gate:               gate,
+	}
+}
+
+// predict advances the state by dt seconds.
+func (f *filter) predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	heading := f.x.AtVec(stateHeading)
+	speed := f.x.AtVec(stateSpeed)
+	sin, cos := math.Sincos(heading)
+
+	f.x.SetVec(stateEast, f.x.AtVec(stateEast)+speed*sin*dt)
+	f.x.SetVec(stateNorth, f.x.AtVec(stateNorth)+speed*cos*dt)
+	f.x.SetVec(stateHeading, normalizeAngle(heading+f.x.AtVec(stateHeadingRate)*dt))
+
+	// jacobian of the motion model
+	jac := mat.NewDense(stateSize, stateSize, nil)
+	for i := 0; i < stateSize; i++ {
+		jac.Set(i, i, 1)
+	}
+	jac.Set(stateEast, stateHeading, speed*cos*dt)
+	jac.Set(stateEast, stateSpeed, sin*dt)
+	jac.Set(stateNorth, stateHeading, -speed*sin*dt)
+	jac.Set(stateNorth, stateSpeed, cos*dt)
+	jac.Set(stateHeading, stateHeadingRate, dt)
+
+	// process noise from unmodelled forward and angular accelerations
+	half := dt * dt / 2
+	g := mat.NewDense(stateSize, 2, []float64{
+		half * sin, 0,
+		half * cos, 0,
+		0, half,
+		dt, 0,
+		0, dt,
+	})
+	accel := mat.NewDiagDense(2, []float64{
+		f.accelStdDev * f.accelStdDev,
+		f.angularAccelStdDev * f.angularAccelStdDev,
+	})
+
+	var fp, fpft, gq, q mat.Dense
+	fp.Mul(jac, f.p)
+	fpft.Mul(&fp, jac.T())
+	gq.Mul(g, accel)
+	q.Mul(&gq, g.T())
+	fpft.Add(&fpft, &q)
+	f.p = symmetrize(&fpft)
+}
+
+// measurement is a linear observation of part of the state.
+type measurement struct {
+	z *mat.VecDense
+	h *mat.Dense
+	r *mat.SymDense
+	// angles lists the rows of z that are angles, so their residuals get wrapped.
+	angles []int
+}
+
+// update corrects the state with the given measurement, unless it is rejected as an outlier. It
+// returns whether or not the measurement was applied.
+func (f *filter) update(m measurement, force bool) bool {
+	var residual mat.VecDense
+	residual.MulVec(m.h, f.x)
+	residual.SubVec(m.z, &residual)
+	for _, i := range m.angles {
+		residual.SetVec(i, normalizeAngle(residual.AtVec(i)))
+	}
+
+	var hp, s mat.Dense
+	hp.Mul(m.h, f.p)
+	s.Mul(&hp, m.h.T())
+	s.Add(&s, m.r)
+
+	var sInv mat.Dense
+	if err := sInv.Inverse(&s); err != nil {
+		return false
+	}
+
+	if !force && f.gate > 0 {
+		var sInvY mat.VecDense
+		sInvY.MulVec(&sInv, &residual)
+		if math.Sqrt(mat.Dot(&residual, &sInvY)) > f.gate {
+			return false
+		}
+	}
+
+	// P is symmetric, so P*H' is the transpose of H*P
+	var gain mat.Dense
+	gain.Mul(hp.T(), &sInv)
+
+	var correction mat.VecDense
+	correction.MulVec(&gain, &residual)
+	f.x.AddVec(f.x, &correction)
+	f.x.SetVec(stateHeading, normalizeAngle(f.x.AtVec(stateHeading)))
+
+	// Joseph form keeps the covariance positive definite in the face of rounding errors.
+	identity := mat.NewDiagDense(stateSize, nil)
+	for i := 0; i < stateSize; i++ {
+		identity.SetDiag(i, 1)
+	}
+	var kh, ikh, ikhp, pNew, kr, krkt mat.Dense
+	kh.Mul(&gain, m.h)
+	ikh.Sub(identity, &kh)
+	ikhp.Mul(&ikh, f.p)
+	pNew.Mul(&ikhp, ikh.T())
+	kr.Mul(&gain, m.r)
+	krkt.Mul(&kr, gain.T())
+	pNew.Add(&pNew, &krkt)
+	f.p = symmetrize(&pNew)
+
+	return true
+}
+
+// reset sets a state element to a value with the given variance, forgetting any correlation with
+// the rest of the state. It is used to initialize elements on their first measurement.
+func (f *filter) reset(i int, value, variance float64) {
+	f.x.SetVec(i, value)
+	for j := 0; j < stateSize; j++ {
+		f.p.SetSym(i, j, 0)
+	}
+	f.p.SetSym(i, i, variance)
+}
+
+func symmetrize(m mat.Matrix) *mat.SymDense {
+	n, _ := m.Dims()
+	s := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			s.SetSym(i, j, (m.At(i, j)+m.At(j, i))/2)
+		}
+	}
+	return s
+}
+
+// normalizeAngle wraps an angle in radians to [-pi, pi].
+func normalizeAngle(a float64) float64 {
+	return math.Remainder(a, 2*math.Pi)
+}
@@ -0,0 +1,57 @@
+package ekf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestFilterConvergesOnStraightLine(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	f := newFilter(100, 0.1, 5)
+	f.reset(stateEast, 0, 1e6)
+	f.reset(stateNorth, 0, 1e6)
+	f.reset(stateHeading, 0, 0.01)
+
+	// drive north at 1 m/sec with noisy position and speed readings
+	const dt = 0.1
+	for i := 1; i <= 200; i++ {
+		f.predict(dt)
+		truth := 1000 * dt * float64(i)
+		pos := scalarMeasurement(stateNorth, truth+rng.NormFloat64()*1000, 1000)
+		f.update(pos, false)
+		f.update(scalarMeasurement(stateSpeed, 1000+rng.NormFloat64()*50, 50), false)
+	}
+	test.That(t, f.x.AtVec(stateSpeed), test.ShouldAlmostEqual, 1000, 50)
+	test.That(t, f.x.AtVec(stateNorth), test.ShouldAlmostEqual, 20000, 500)
+	test.That(t, f.x.AtVec(stateEast), test.ShouldAlmostEqual, 0, 500)
+	test.That(t, f.p.At(stateNorth, stateNorth), test.ShouldBeLessThan, 1000*1000)
+}
+
+func TestFilterRejectsOutliers(t *testing.T) {
+	f := newFilter(100, 0.1, 3)
+	f.reset(stateSpeed, 1000, 10*10)
+
+	test.That(t, f.update(scalarMeasurement(stateSpeed, 5000, 10), false), test.ShouldBeFalse)
+	test.That(t, f.x.AtVec(stateSpeed), test.ShouldEqual, 1000)
+
+	test.That(t, f.update(scalarMeasurement(stateSpeed, 1010, 10), false), test.ShouldBeTrue)
+	test.That(t, f.x.AtVec(stateSpeed), test.ShouldAlmostEqual, 1005)
+
+	// a forced update is applied even when it is an outlier
+	test.That(t, f.update(scalarMeasurement(stateSpeed, 5000, 10), true), test.ShouldBeTrue)
+	test.That(t, f.x.AtVec(stateSpeed), test.ShouldBeGreaterThan, 1005)
+}
+
+func TestFilterWrapsHeading(t *testing.T) {
+	f := newFilter(100, 0.1, 0)
+	f.reset(stateHeading, math.Pi-0.05, 0.01)
+
+	// a heading just past south is close to one just before it, not a full turn away
+	m := scalarMeasurement(stateHeading, -math.Pi+0.05, 0.1)
+	m.angles = []int{0}
+	test.That(t, f.update(m, false), test.ShouldBeTrue)
+	test.That(t, math.Abs(f.x.AtVec(stateHeading)), test.ShouldBeGreaterThan, math.Pi-0.05)
+}
@@ -341,12 +341,13 @@ func (mpu *mpu6050) Properties(ctx context.Context, extra map[string]interface{}
 }
 
 func (mpu *mpu6050) Close(ctx context.Context) {
-	mpu.mu.Lock()
-	defer mpu.mu.Unlock()
-
+	// Stop the background goroutine before taking the lock, because it takes the lock too.
 	mpu.cancelFunc()
 	mpu.activeBackgroundWorkers.Wait()
 
+	mpu.mu.Lock()
+	defer mpu.mu.Unlock()
+
 	// Set the Sleep bit (bit 6) in the power control register (register 107).
 	err := mpu.writeByte(ctx, 107, 1<<6)
 	if err != nil {
@@ -0,0 +1,88 @@
+package mpu6050
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func setupDependencies(bus board.I2C) registry.Dependencies {
+	return registry.Dependencies{
+		board.Named("board"): &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) {
+			return bus, name == "main"
+		}},
+	}
+}
+
+func TestMovementSensor(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	chip := simdevice.NewMPU6050()
+	chip.SetLinearAcceleration(r3.Vector{X: 9810, Y: -4905, Z: 0})
+	chip.SetAngularVelocity(spatialmath.AngularVelocity{X: 125, Y: 0, Z: -62.5})
+	chip.SetTemperature(20)
+	bus.Attach(0x68, chip)
+	cfg := config.Component{
+		Name:                "imu",
+		ConvertedAttributes: &AttrConfig{BoardName: "board", I2cBus: "main"},
+	}
+
+	ms, err := NewMpu6050(ctx, setupDependencies(bus), cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chip.Sleeping(), test.ShouldBeFalse)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		accel, err := ms.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, accel.X, test.ShouldAlmostEqual, 9810, 1)
+		test.That(tb, accel.Y, test.ShouldAlmostEqual, -4905, 1)
+		test.That(tb, accel.Z, test.ShouldEqual, 0)
+	})
+	angVel, err := ms.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.X, test.ShouldAlmostEqual, 125, 0.01)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, -62.5, 0.01)
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temperature_celsius"], test.ShouldAlmostEqual, 20, 0.01)
+
+	// losing the chip is reported
+	bus.Detach(0x68)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := ms.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldNotBeNil)
+	})
+	bus.Attach(0x68, chip)
+
+	ms.(*mpu6050).Close(ctx)
+	test.That(t, chip.Sleeping(), test.ShouldBeTrue)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+}
+
+func TestWrongChip(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	bus.Attach(0x68, simdevice.NewADXL345())
+	cfg := config.Component{
+		Name:                "imu",
+		ConvertedAttributes: &AttrConfig{BoardName: "board", I2cBus: "main"},
+	}
+	_, err := NewMpu6050(ctx, setupDependencies(bus), cfg, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unexpected non-MPU6050 device")
+}
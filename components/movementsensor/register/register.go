@@ -5,10 +5,13 @@ import (
 	// Load all movementsensors.
 	_ "go.viam.com/rdk/components/movementsensor/adxl345"
 	_ "go.viam.com/rdk/components/movementsensor/cameramono"
+	_ "go.viam.com/rdk/components/movementsensor/ekf"
 	_ "go.viam.com/rdk/components/movementsensor/fake"
 	_ "go.viam.com/rdk/components/movementsensor/gpsnmea"
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtk"
 	_ "go.viam.com/rdk/components/movementsensor/imuvectornav"
 	_ "go.viam.com/rdk/components/movementsensor/imuwit"
 	_ "go.viam.com/rdk/components/movementsensor/mpu6050"
+	_ "go.viam.com/rdk/components/movementsensor/regmap"
+	_ "go.viam.com/rdk/components/movementsensor/wheeledodometry"
 )
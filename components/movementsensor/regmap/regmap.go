@@ -0,0 +1,230 @@
+// Package regmap implements a movement sensor on an I2C or SPI chip described by a map of its
+// registers. The values of the register map are assigned to the movement sensor's axes by name.
+package regmap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/regmap"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("register-map")
+
+// VectorConfig names the register map values of each axis. Axes left unset read as zero.
+type VectorConfig struct {
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+	Z string `json:"z,omitempty"`
+}
+
+func (conf *VectorConfig) validate(path string, names map[string]bool) error {
+	if conf.X == "" && conf.Y == "" && conf.Z == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "x")
+	}
+	for _, name := range []string{conf.X, conf.Y, conf.Z} {
+		if name != "" && !names[name] {
+			return utils.NewConfigValidationError(path, errors.Errorf("%q is not in the register map", name))
+		}
+	}
+	return nil
+}
+
+func (conf *VectorConfig) vector(values map[string]float64) r3.Vector {
+	return r3.Vector{X: values[conf.X], Y: values[conf.Y], Z: values[conf.Z]}
+}
+
+// AttrConfig is used for converting config attributes. The register map is given directly in
+// the attributes, alongside which of its values make up the readings of the movement sensor.
+type AttrConfig struct {
+	regmap.Config
+	LinearAcceleration *VectorConfig `json:"linear_acceleration,omitempty"` // mm/s^2
+	AngularVelocity    *VectorConfig `json:"angular_velocity,omitempty"`    // deg/s
+	LinearVelocity     *VectorConfig `json:"linear_velocity,omitempty"`     // mm/s
+	CompassHeading     string        `json:"compass_heading,omitempty"`     // degrees
+}
+
+// Validate ensures all parts of the config are valid and returns the board it depends on.
+func (conf *AttrConfig) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	names := conf.Names()
+	vectors := []struct {
+		name string
+		conf *VectorConfig
+	}{
+		{"linear_acceleration", conf.LinearAcceleration},
+		{"angular_velocity", conf.AngularVelocity},
+		{"linear_velocity", conf.LinearVelocity},
+	}
+	for _, v := range vectors {
+		if v.conf == nil {
+			continue
+		}
+		if err := v.conf.validate(fmt.Sprintf("%s.%s", path, v.name), names); err != nil {
+			return nil, err
+		}
+	}
+	if conf.CompassHeading != "" && !names[conf.CompassHeading] {
+		return nil, utils.NewConfigValidationError(path, errors.Errorf("compass_heading %q is not in the register map", conf.CompassHeading))
+	}
+	if conf.LinearAcceleration == nil && conf.AngularVelocity == nil && conf.LinearVelocity == nil && conf.CompassHeading == "" {
+		return nil, utils.NewConfigValidationError(path,
+			errors.New("at least one of linear_acceleration, angular_velocity, linear_velocity or compass_heading is required"))
+	}
+	return []string{conf.Board}, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			attr, ok := config.ConvertedAttributes.(*AttrConfig)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attr, config.ConvertedAttributes)
+			}
+			b, err := board.FromDependencies(deps, attr.Board)
+			if err != nil {
+				return nil, err
+			}
+			return newMovementSensor(ctx, b, attr, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(movementsensor.Subtype, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: &conf})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(attributes); err != nil {
+				return nil, err
+			}
+			return &conf, nil
+		}, &AttrConfig{})
+}
+
+func newMovementSensor(ctx context.Context, b board.Board, attr *AttrConfig, logger golog.Logger) (movementsensor.MovementSensor, error) {
+	device, err := regmap.NewDevice(ctx, b, &attr.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &regmapMovementSensor{device: device, attr: attr, logger: logger}, nil
+}
+
+// regmapMovementSensor reads the chip on every call, so it only reports what it was configured with.
+type regmapMovementSensor struct {
+	generic.Unimplemented
+	device *regmap.Device
+	attr   *AttrConfig
+	logger golog.Logger
+}
+
+func (ms *regmapMovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return geo.NewPoint(0, 0), 0, movementsensor.ErrMethodUnimplementedPosition
+}
+
+func (ms *regmapMovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if ms.attr.LinearVelocity == nil {
+		return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+	}
+	values, err := ms.device.Read(ctx)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return ms.attr.LinearVelocity.vector(values), nil
+}
+
+func (ms *regmapMovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	if ms.attr.AngularVelocity == nil {
+		return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	values, err := ms.device.Read(ctx)
+	if err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	return spatialmath.AngularVelocity(ms.attr.AngularVelocity.vector(values)), nil
+}
+
+func (ms *regmapMovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if ms.attr.LinearAcceleration == nil {
+		return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+	}
+	values, err := ms.device.Read(ctx)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return ms.attr.LinearAcceleration.vector(values), nil
+}
+
+func (ms *regmapMovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if ms.attr.CompassHeading == "" {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	values, err := ms.device.Read(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return values[ms.attr.CompassHeading], nil
+}
+
+func (ms *regmapMovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return spatialmath.NewOrientationVector(), movementsensor.ErrMethodUnimplementedOrientation
+}
+
+func (ms *regmapMovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return map[string]float32{}, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+func (ms *regmapMovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		LinearAccelerationSupported: ms.attr.LinearAcceleration != nil,
+		AngularVelocitySupported:    ms.attr.AngularVelocity != nil,
+		LinearVelocitySupported:     ms.attr.LinearVelocity != nil,
+		CompassHeadingSupported:     ms.attr.CompassHeading != "",
+	}, nil
+}
+
+// Readings reads the chip once and reports the configured movement values.
+func (ms *regmapMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	values, err := ms.device.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readings := map[string]interface{}{}
+	if ms.attr.LinearAcceleration != nil {
+		readings["linear_acceleration"] = ms.attr.LinearAcceleration.vector(values)
+	}
+	if ms.attr.AngularVelocity != nil {
+		readings["angular_velocity"] = spatialmath.AngularVelocity(ms.attr.AngularVelocity.vector(values))
+	}
+	if ms.attr.LinearVelocity != nil {
+		readings["linear_velocity"] = ms.attr.LinearVelocity.vector(values)
+	}
+	if ms.attr.CompassHeading != "" {
+		readings["compass"] = values[ms.attr.CompassHeading]
+	}
+	return readings, nil
+}
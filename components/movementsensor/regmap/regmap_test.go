@@ -0,0 +1,98 @@
+package regmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/regmap"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func accelConfig() *AttrConfig {
+	field := func(name string, register int) regmap.FieldConfig {
+		return regmap.FieldConfig{Name: name, Register: register, Length: 2, LittleEndian: true, Signed: true, Scale: 10}
+	}
+	return &AttrConfig{
+		Config: regmap.Config{
+			Board:    "b",
+			I2CBus:   "main",
+			I2CAddr:  0x53,
+			Identity: &regmap.IdentityConfig{Register: 0x00, Value: 0xE5},
+			Fields:   []regmap.FieldConfig{field("ax", 0x32), field("ay", 0x34), field("az", 0x36), field("gz", 0x38)},
+		},
+		LinearAcceleration: &VectorConfig{X: "ax", Y: "ay", Z: "az"},
+		AngularVelocity:    &VectorConfig{Z: "gz"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	attr := accelConfig()
+	deps, err := attr.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"b"})
+
+	attr.AngularVelocity = &VectorConfig{X: "gx"}
+	_, err = attr.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "path.angular_velocity")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"gx" is not in the register map`)
+
+	attr.AngularVelocity = &VectorConfig{}
+	_, err = attr.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	attr = accelConfig()
+	attr.LinearAcceleration, attr.AngularVelocity = nil, nil
+	_, err = attr.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one of")
+}
+
+func TestMovementSensor(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	bus := simdevice.NewI2CBus()
+	chip := &simdevice.Registers{}
+	bus.Attach(0x53, chip)
+	chip.Set(0x00, 0xE5)
+	chip.Set(0x32, 0x01, 0x00, 0xFF, 0xFF, 0x64, 0x00, 0x03, 0x00)
+	b := &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) { return bus, true }}
+
+	ms, err := newMovementSensor(ctx, b, accelConfig(), logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		LinearAccelerationSupported: true,
+		AngularVelocitySupported:    true,
+	})
+
+	accel, err := ms.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accel, test.ShouldResemble, r3.Vector{X: 10, Y: -10, Z: 1000})
+
+	angVel, err := ms.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel, test.ShouldResemble, spatialmath.AngularVelocity{Z: 30})
+
+	_, err = ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedLinearVelocity)
+	_, err = ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{
+		"linear_acceleration": r3.Vector{X: 10, Y: -10, Z: 1000},
+		"angular_velocity":    spatialmath.AngularVelocity{Z: 30},
+	})
+}
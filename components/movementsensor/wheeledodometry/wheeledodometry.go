@@ -0,0 +1,339 @@
+// Package wheeledodometry implements an odometry movement sensor that integrates the encoder
+// positions of a wheeled base's motors into a pose estimate.
+//
+// The pose is reported in a local frame whose origin is the position of the base when the sensor
+// was constructed: +Y is the direction the base was initially facing and +X is to its right. Since
+// this frame has nothing to do with the globe, Position returns a point whose latitude holds the X
+// coordinate and whose longitude holds the Y coordinate, both in meters.
+package wheeledodometry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("wheeled-odometry")
+
+const defaultTimeIntervalMSecs = 500
+
+// AttrConfig is used for converting config attributes of a wheeled odometry movement sensor.
+type AttrConfig struct {
+	Base                 string   `json:"base"`
+	LeftMotors           []string `json:"left_motors"`
+	RightMotors          []string `json:"right_motors"`
+	WheelCircumferenceMM int      `json:"wheel_circumference_mm"`
+	TimeIntervalMSecs    float64  `json:"time_interval_msecs,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *AttrConfig) Validate(path string) ([]string, error) {
+	var deps []string
+
+	if cfg.Base == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if len(cfg.LeftMotors) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "left_motors")
+	}
+	if len(cfg.RightMotors) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "right_motors")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+	if cfg.TimeIntervalMSecs < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("time_interval_msecs cannot be negative"))
+	}
+
+	deps = append(deps, cfg.Base)
+	deps = append(deps, cfg.LeftMotors...)
+	deps = append(deps, cfg.RightMotors...)
+	return deps, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		movementsensor.Subtype,
+		model,
+		registry.Component{
+			Constructor: func(
+				ctx context.Context,
+				deps registry.Dependencies,
+				cfg config.Component,
+				logger golog.Logger,
+			) (interface{}, error) {
+				return newWheeledOdometry(ctx, deps, cfg, logger)
+			},
+		})
+	config.RegisterComponentAttributeMapConverter(
+		movementsensor.Subtype,
+		model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&AttrConfig{})
+}
+
+// pose is the planar state of the base in the local odometry frame.
+type pose struct {
+	x, y    float64 // mm
+	heading float64 // radians, counter-clockwise from +Y
+}
+
+type odometry struct {
+	generic.Unimplemented
+	leftMotors           []motor.Motor
+	rightMotors          []motor.Motor
+	widthMM              float64
+	wheelCircumferenceMM float64
+	timeInterval         time.Duration
+
+	mu        sync.RWMutex
+	pose      pose
+	linearVel r3.Vector
+	angVel    spatialmath.AngularVelocity
+
+	lastLeft, lastRight float64
+	lastTime            time.Time
+
+	err                     movementsensor.LastError
+	cancelCtx               context.Context
+	cancelFunc              func()
+	activeBackgroundWorkers sync.WaitGroup
+	logger                  golog.Logger
+}
+
+func newWheeledOdometry(
+	ctx context.Context,
+	deps registry.Dependencies,
+	cfg config.Component,
+	logger golog.Logger,
+) (movementsensor.MovementSensor, error) {
+	conf, ok := cfg.ConvertedAttributes.(*AttrConfig)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(conf, cfg.ConvertedAttributes)
+	}
+
+	b, err := base.FromDependencies(deps, conf.Base)
+	if err != nil {
+		return nil, err
+	}
+	localBase, ok := b.(base.LocalBase)
+	if !ok {
+		return nil, base.NewUnimplementedLocalInterfaceError(b)
+	}
+	width, err := localBase.Width(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get width of base %q", conf.Base)
+	}
+	if width <= 0 {
+		return nil, errors.Errorf("base %q reports an invalid width of %d mm", conf.Base, width)
+	}
+
+	o := &odometry{
+		widthMM:              float64(width),
+		wheelCircumferenceMM: float64(conf.WheelCircumferenceMM),
+		timeInterval:         time.Duration(defaultTimeIntervalMSecs) * time.Millisecond,
+		logger:               logger,
+	}
+	if conf.TimeIntervalMSecs > 0 {
+		o.timeInterval = time.Duration(conf.TimeIntervalMSecs * float64(time.Millisecond))
+	}
+
+	if o.leftMotors, err = motorsWithEncoders(ctx, deps, conf.LeftMotors); err != nil {
+		return nil, err
+	}
+	if o.rightMotors, err = motorsWithEncoders(ctx, deps, conf.RightMotors); err != nil {
+		return nil, err
+	}
+
+	if o.lastLeft, o.lastRight, err = o.wheelPositions(ctx); err != nil {
+		return nil, err
+	}
+	o.lastTime = time.Now()
+
+	o.cancelCtx, o.cancelFunc = context.WithCancel(context.Background())
+	o.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(o.trackPosition, o.activeBackgroundWorkers.Done)
+
+	return o, nil
+}
+
+// motorsWithEncoders gets the named motors from the dependencies, ensuring each can report its position.
+func motorsWithEncoders(ctx context.Context, deps registry.Dependencies, names []string) ([]motor.Motor, error) {
+	motors := make([]motor.Motor, 0, len(names))
+	for _, name := range names {
+		m, err := motor.FromDependencies(deps, name)
+		if err != nil {
+			return nil, err
+		}
+		props, err := m.Properties(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get properties of motor %q", name)
+		}
+		if !props[motor.PositionReporting] {
+			return nil, errors.Errorf("motor %q does not support position reporting", name)
+		}
+		motors = append(motors, m)
+	}
+	return motors, nil
+}
+
+// wheelPositions returns the average position, in revolutions, of the left and right motors.
+func (o *odometry) wheelPositions(ctx context.Context) (float64, float64, error) {
+	left, err := averagePosition(ctx, o.leftMotors)
+	if err != nil {
+		return 0, 0, err
+	}
+	right, err := averagePosition(ctx, o.rightMotors)
+	if err != nil {
+		return 0, 0, err
+	}
+	return left, right, nil
+}
+
+func averagePosition(ctx context.Context, motors []motor.Motor) (float64, error) {
+	var sum float64
+	for _, m := range motors {
+		pos, err := m.Position(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		sum += pos
+	}
+	return sum / float64(len(motors)), nil
+}
+
+// trackPosition polls the motor positions at the configured interval and integrates them into the pose.
+func (o *odometry) trackPosition() {
+	ticker := time.NewTicker(o.timeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.cancelCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		left, right, err := o.wheelPositions(o.cancelCtx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				o.err.Set(err)
+			}
+			continue
+		}
+		now := time.Now()
+		o.update(left, right, now.Sub(o.lastTime).Seconds())
+		o.lastLeft, o.lastRight, o.lastTime = left, right, now
+	}
+}
+
+// update advances the pose given the current wheel positions in revolutions and the seconds
+// elapsed since the previous positions were read.
+func (o *odometry) update(left, right, dt float64) {
+	leftDist := (left - o.lastLeft) * o.wheelCircumferenceMM
+	rightDist := (right - o.lastRight) * o.wheelCircumferenceMM
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pose = integrate(o.pose, leftDist, rightDist, o.widthMM)
+	if dt > 0 {
+		o.linearVel = r3.Vector{Y: (leftDist + rightDist) / 2 / dt}
+		o.angVel = spatialmath.AngularVelocity{Z: rdkutils.RadToDeg((rightDist - leftDist) / o.widthMM / dt)}
+	}
+}
+
+// integrate applies a differential drive motion, given the distances in mm travelled by the left
+// and right wheels, to a pose. The heading used for the translation is the midpoint of the
+// heading before and after the motion, which is exact for constant curvature arcs over short
+// intervals.
+func integrate(p pose, leftDist, rightDist, width float64) pose {
+	dist := (leftDist + rightDist) / 2
+	dTheta := (rightDist - leftDist) / width
+	mid := p.heading + dTheta/2
+	return pose{
+		x:       p.x - dist*math.Sin(mid),
+		y:       p.y + dist*math.Cos(mid),
+		heading: math.Remainder(p.heading+dTheta, 2*math.Pi),
+	}
+}
+
+// Position returns the position of the base in the local odometry frame, with X as the latitude
+// and Y as the longitude in meters.
+func (o *odometry) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return geo.NewPoint(o.pose.x/1000, o.pose.y/1000), 0, o.err.Get()
+}
+
+// Orientation returns the heading of the base relative to its starting heading.
+func (o *odometry) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return &spatialmath.EulerAngles{Yaw: o.pose.heading}, o.err.Get()
+}
+
+// LinearVelocity returns the forward velocity of the base in mm/sec.
+func (o *odometry) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.linearVel, o.err.Get()
+}
+
+// AngularVelocity returns the rate of turn of the base in degrees/sec.
+func (o *odometry) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.angVel, o.err.Get()
+}
+
+func (o *odometry) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+func (o *odometry) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+}
+
+func (o *odometry) Accuracy(ctx context.Context, extra map[string]interface{}) (map[string]float32, error) {
+	return map[string]float32{}, movementsensor.ErrMethodUnimplementedAccuracy
+}
+
+func (o *odometry) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.Readings(ctx, o, extra)
+}
+
+func (o *odometry) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        true,
+		OrientationSupported:     true,
+		LinearVelocitySupported:  true,
+		AngularVelocitySupported: true,
+	}, nil
+}
+
+// Close stops the background position tracking.
+func (o *odometry) Close() {
+	o.cancelFunc()
+	o.activeBackgroundWorkers.Wait()
+}
@@ -0,0 +1,143 @@
+package wheeledodometry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	cfg := &AttrConfig{}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "base")
+
+	cfg.Base = "base"
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "left_motors")
+
+	cfg.LeftMotors = []string{"fl", "bl"}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "right_motors")
+
+	cfg.RightMotors = []string{"fr", "br"}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "wheel_circumference_mm")
+
+	cfg.WheelCircumferenceMM = 100
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "fl", "bl", "fr", "br"})
+}
+
+func TestIntegrate(t *testing.T) {
+	// driving straight moves along +Y without turning
+	p := integrate(pose{}, 100, 100, 200)
+	test.That(t, p.x, test.ShouldAlmostEqual, 0)
+	test.That(t, p.y, test.ShouldAlmostEqual, 100)
+	test.That(t, p.heading, test.ShouldAlmostEqual, 0)
+
+	// spinning in place a quarter turn to the left
+	quarter := 200 * math.Pi / 4
+	p = integrate(pose{}, -quarter, quarter, 200)
+	test.That(t, p.x, test.ShouldAlmostEqual, 0)
+	test.That(t, p.y, test.ShouldAlmostEqual, 0)
+	test.That(t, p.heading, test.ShouldAlmostEqual, math.Pi/2)
+
+	// driving straight after turning left moves along -X
+	p = integrate(p, 100, 100, 200)
+	test.That(t, p.x, test.ShouldAlmostEqual, -100)
+	test.That(t, p.y, test.ShouldAlmostEqual, 0)
+}
+
+func TestWheeledOdometry(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	var mu sync.Mutex
+	positions := map[string]float64{}
+	newMotor := func(name string, reportsPosition bool) *inject.Motor {
+		return &inject.Motor{
+			PropertiesFunc: func(ctx context.Context, extra map[string]interface{}) (map[motor.Feature]bool, error) {
+				return map[motor.Feature]bool{motor.PositionReporting: reportsPosition}, nil
+			},
+			PositionFunc: func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return positions[name], nil
+			},
+		}
+	}
+	setPositions := func(left, right float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		positions["left"] = left
+		positions["right"] = right
+	}
+
+	deps := registry.Dependencies{
+		base.Named("base"): &inject.Base{
+			WidthFunc: func(ctx context.Context) (int, error) { return 200, nil },
+		},
+		motor.Named("left"):       newMotor("left", true),
+		motor.Named("right"):      newMotor("right", true),
+		motor.Named("no-encoder"): newMotor("no-encoder", false),
+	}
+	attrs := &AttrConfig{
+		Base:                 "base",
+		LeftMotors:           []string{"left"},
+		RightMotors:          []string{"no-encoder"},
+		WheelCircumferenceMM: 100,
+		TimeIntervalMSecs:    5,
+	}
+	_, err := newWheeledOdometry(ctx, deps, config.Component{ConvertedAttributes: attrs}, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "position reporting")
+
+	attrs.RightMotors = []string{"right"}
+	ms, err := newWheeledOdometry(ctx, deps, config.Component{ConvertedAttributes: attrs}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer ms.(*odometry).Close()
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.PositionSupported, test.ShouldBeTrue)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeFalse)
+
+	// ten revolutions forward is one meter along +Y
+	setPositions(10, 10)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		pos, _, err := ms.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pos.Lat(), test.ShouldAlmostEqual, 0)
+		test.That(tb, pos.Lng(), test.ShouldAlmostEqual, 1)
+	})
+
+	// a half turn to the left in place
+	half := 200 * math.Pi / 2 / 100
+	setPositions(10-half, 10+half)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		ori, err := ms.Orientation(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, math.Abs(ori.EulerAngles().Yaw), test.ShouldAlmostEqual, math.Pi)
+		pos, _, err := ms.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pos.Lng(), test.ShouldAlmostEqual, 1)
+	})
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["angular_velocity"], test.ShouldHaveSameTypeAs, spatialmath.AngularVelocity{})
+}
@@ -0,0 +1,430 @@
+package fiducial
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+
+	"go.viam.com/rdk/rimage/transform"
+)
+
+const (
+	// thresholdOffset is how much darker than its neighborhood a pixel must be to be dark.
+	thresholdOffset = 7
+	// minMarkerPixels is the fewest dark pixels a marker's border may have.
+	minMarkerPixels = 64
+	// minSidePixels is the shortest a side of a marker may be.
+	minSidePixels = 8
+	// maxHullToQuadArea is how much larger than the quadrilateral of its corners the convex hull of
+	// a marker may be.
+	maxHullToQuadArea = 1.15
+	// edgeFitDistance is how far in pixels from a side of a marker's quadrilateral border pixels
+	// may be to refine the side with.
+	edgeFitDistance = 2
+	// minContrast is the least difference between a marker's border and its surroundings.
+	minContrast = 30
+	// cellSampleOffset is how far from the center of a cell, in cells, its outer samples are.
+	cellSampleOffset = 0.2
+	// quietZoneOffset is how far outside of a marker, in cells, its surroundings are sampled.
+	quietZoneOffset = 0.3
+)
+
+// A marker is a marker found in an image.
+type marker struct {
+	id int
+	// corners are the image coordinates of the marker's top left, top right, bottom right and
+	// bottom left corners, in its upright orientation.
+	corners [4]r2.Point
+}
+
+// detectMarkers finds the markers of the dictionary in the image. Markers are found by their
+// black border, so they need a lighter quiet zone around them, and must not touch the image's
+// edges.
+func detectMarkers(img image.Image, dict Dictionary) []marker {
+	gray := toGray(img)
+	dark := threshold(gray)
+	var markers []marker
+	for _, boundary := range darkComponents(dark, gray.Rect.Dx(), gray.Rect.Dy()) {
+		quad, ok := fitQuad(boundary)
+		if !ok {
+			continue
+		}
+		if m, ok := decode(gray, refineCorners(quad, boundary), dict); ok {
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	if gray, ok := img.(*image.Gray); ok && bounds.Min == (image.Point{}) {
+		return gray
+	}
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Rect, img, bounds.Min, draw.Src)
+	return gray
+}
+
+// threshold returns which pixels are darker than the mean of their neighborhood by at least the
+// threshold offset.
+func threshold(gray *image.Gray) []bool {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	stride := width + 1
+	integral := make([]int64, stride*(height+1))
+	for y := 0; y < height; y++ {
+		var row int64
+		for x := 0; x < width; x++ {
+			row += int64(gray.Pix[y*gray.Stride+x])
+			integral[(y+1)*stride+x+1] = integral[y*stride+x+1] + row
+		}
+	}
+
+	radius := width
+	if height < radius {
+		radius = height
+	}
+	radius = int(math.Max(5, math.Min(50, float64(radius/30))))
+	dark := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		y0, y1 := maxInt(0, y-radius), minInt(height, y+radius+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := maxInt(0, x-radius), minInt(width, x+radius+1)
+			sum := integral[y1*stride+x1] - integral[y0*stride+x1] - integral[y1*stride+x0] + integral[y0*stride+x0]
+			count := int64((x1 - x0) * (y1 - y0))
+			dark[y*width+x] = int64(gray.Pix[y*gray.Stride+x])*count < sum-thresholdOffset*count
+		}
+	}
+	return dark
+}
+
+// darkComponents returns the centers of the boundary pixels of each 4-connected component of dark
+// pixels that is large enough to be a marker's border and doesn't touch the image's edges.
+func darkComponents(dark []bool, width, height int) [][]r2.Point {
+	visited := make([]bool, len(dark))
+	var components [][]r2.Point
+	var queue []int
+	for start, isDark := range dark {
+		if !isDark || visited[start] {
+			continue
+		}
+		visited[start] = true
+		queue = append(queue[:0], start)
+		var boundary []r2.Point
+		touchesEdge := false
+		for i := 0; i < len(queue); i++ {
+			x, y := queue[i]%width, queue[i]/width
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				touchesEdge = true
+			}
+			onBoundary := false
+			for _, n := range [4]image.Point{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n.X < 0 || n.Y < 0 || n.X >= width || n.Y >= height {
+					continue
+				}
+				j := n.Y*width + n.X
+				if !dark[j] {
+					onBoundary = true
+				} else if !visited[j] {
+					visited[j] = true
+					queue = append(queue, j)
+				}
+			}
+			if onBoundary {
+				boundary = append(boundary, r2.Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			}
+		}
+		if !touchesEdge && len(queue) >= minMarkerPixels {
+			components = append(components, boundary)
+		}
+	}
+	return components
+}
+
+// fitQuad returns the corners of the quadrilateral the points outline, clockwise in the image,
+// or false if they don't outline one.
+func fitQuad(points []r2.Point) ([4]r2.Point, bool) {
+	var quad [4]r2.Point
+	hull := convexHull(points)
+	if len(hull) < 4 {
+		return quad, false
+	}
+
+	var centroid r2.Point
+	for _, p := range hull {
+		centroid = centroid.Add(p)
+	}
+	centroid = centroid.Mul(1 / float64(len(hull)))
+	a := farthest(hull, func(p r2.Point) float64 { return p.Sub(centroid).Norm() })
+	c := farthest(hull, func(p r2.Point) float64 { return p.Sub(a).Norm() })
+	diagonal := c.Sub(a)
+	b := farthest(hull, func(p r2.Point) float64 { return diagonal.Cross(p.Sub(a)) })
+	d := farthest(hull, func(p r2.Point) float64 { return -diagonal.Cross(p.Sub(a)) })
+	if diagonal.Cross(b.Sub(a)) <= 0 || diagonal.Cross(d.Sub(a)) >= 0 {
+		return quad, false
+	}
+
+	quad = [4]r2.Point{a, b, c, d}
+	area := polygonArea(quad[:])
+	if area < 0 {
+		quad = [4]r2.Point{a, d, c, b}
+		area = -area
+	}
+	for i := range quad {
+		if quad[(i+1)%4].Sub(quad[i]).Norm() < minSidePixels {
+			return quad, false
+		}
+	}
+	if math.Abs(polygonArea(hull)) > maxHullToQuadArea*area {
+		return quad, false
+	}
+	return quad, true
+}
+
+// refineCorners fits a line to the points along each side of the quadrilateral, on the outside
+// edge of the pixels, and moves its corners to where the lines meet.
+func refineCorners(quad [4]r2.Point, points []r2.Point) [4]r2.Point {
+	type line struct{ point, direction r2.Point }
+	var lines [4]line
+	for i := range quad {
+		start := quad[i]
+		side := quad[(i+1)%4].Sub(start)
+		length := side.Norm()
+		direction := side.Mul(1 / length)
+		outward := r2.Point{X: direction.Y, Y: -direction.X}
+
+		var onSide []r2.Point
+		for _, p := range points {
+			along := p.Sub(start).Dot(direction)
+			if along > 0.1*length && along < 0.9*length && math.Abs(p.Sub(start).Dot(outward)) < edgeFitDistance {
+				onSide = append(onSide, p)
+			}
+		}
+		if len(onSide) < 4 {
+			return quad
+		}
+
+		var mean r2.Point
+		for _, p := range onSide {
+			mean = mean.Add(p)
+		}
+		mean = mean.Mul(1 / float64(len(onSide)))
+		var sxx, sxy, syy float64
+		for _, p := range onSide {
+			dp := p.Sub(mean)
+			sxx += dp.X * dp.X
+			sxy += dp.X * dp.Y
+			syy += dp.Y * dp.Y
+		}
+		angle := math.Atan2(2*sxy, sxx-syy) / 2
+		fitted := r2.Point{X: math.Cos(angle), Y: math.Sin(angle)}
+		if fitted.Dot(direction) < 0 {
+			fitted = fitted.Mul(-1)
+		}
+		// the edge is half a pixel out from the centers of the border's pixels
+		lines[i] = line{mean.Add(r2.Point{X: fitted.Y, Y: -fitted.X}.Mul(0.5)), fitted}
+	}
+
+	refined := quad
+	for i := range quad {
+		prev, next := lines[(i+3)%4], lines[i]
+		denominator := prev.direction.Cross(next.direction)
+		if math.Abs(denominator) < 1e-6 {
+			return quad
+		}
+		s := next.point.Sub(prev.point).Cross(next.direction) / denominator
+		refined[i] = prev.point.Add(prev.direction.Mul(s))
+		if refined[i].Sub(quad[i]).Norm() > 2*edgeFitDistance {
+			return quad
+		}
+	}
+	return refined
+}
+
+// decode reads the bits inside the quadrilateral and identifies the marker they belong to,
+// checking each of its four orientations.
+func decode(gray *image.Gray, quad [4]r2.Point, dict Dictionary) (marker, bool) {
+	cells := dict.Bits() + 2
+	size := float64(cells)
+	grid := []r2.Point{{X: 0, Y: 0}, {X: size, Y: 0}, {X: size, Y: size}, {X: 0, Y: size}}
+	homography, err := transform.EstimateExactHomographyFrom8Points(grid, quad[:], false)
+	if err != nil {
+		return marker{}, false
+	}
+
+	values := make([]float64, cells*cells)
+	var black float64
+	for row := 0; row < cells; row++ {
+		for col := 0; col < cells; col++ {
+			var sum float64
+			for _, dy := range []float64{-cellSampleOffset, 0, cellSampleOffset} {
+				for _, dx := range []float64{-cellSampleOffset, 0, cellSampleOffset} {
+					v, _ := sample(gray, homography.Apply(r2.Point{X: float64(col) + 0.5 + dx, Y: float64(row) + 0.5 + dy}))
+					sum += v
+				}
+			}
+			values[row*cells+col] = sum / 9
+			if isBorder(row, col, cells) {
+				black += values[row*cells+col]
+			}
+		}
+	}
+	black /= float64(4 * (cells - 1))
+
+	var white float64
+	var count int
+	for i := 0; i < cells; i++ {
+		along := float64(i) + 0.5
+		for _, p := range []r2.Point{
+			{X: along, Y: -quietZoneOffset},
+			{X: along, Y: size + quietZoneOffset},
+			{X: -quietZoneOffset, Y: along},
+			{X: size + quietZoneOffset, Y: along},
+		} {
+			if v, ok := sample(gray, homography.Apply(p)); ok {
+				white += v
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return marker{}, false
+	}
+	white /= float64(count)
+	if white-black < minContrast {
+		return marker{}, false
+	}
+
+	cutoff := (white + black) / 2
+	bits := make([]bool, len(values))
+	for i, v := range values {
+		bits[i] = v >= cutoff
+		if bits[i] && isBorder(i/cells, i%cells, cells) {
+			return marker{}, false
+		}
+	}
+
+	found := false
+	var best marker
+	bestDistance := 0
+	for rotation := 0; rotation < 4; rotation++ {
+		if id, distance, ok := dict.Identify(interior(bits, cells)); ok && (!found || distance < bestDistance) {
+			found, bestDistance = true, distance
+			best.id = id
+			for i := range best.corners {
+				best.corners[i] = quad[(i+rotation)%4]
+			}
+		}
+		bits = rotate(bits, cells)
+	}
+	return best, found
+}
+
+func isBorder(row, col, cells int) bool {
+	return row == 0 || col == 0 || row == cells-1 || col == cells-1
+}
+
+// interior returns the bits of the grid inside of its border.
+func interior(bits []bool, cells int) []bool {
+	inner := make([]bool, 0, (cells-2)*(cells-2))
+	for row := 1; row < cells-1; row++ {
+		inner = append(inner, bits[row*cells+1:(row+1)*cells-1]...)
+	}
+	return inner
+}
+
+// rotate returns the grid as read with its origin at the next corner clockwise.
+func rotate(bits []bool, cells int) []bool {
+	rotated := make([]bool, len(bits))
+	for row := 0; row < cells; row++ {
+		for col := 0; col < cells; col++ {
+			rotated[row*cells+col] = bits[col*cells+cells-1-row]
+		}
+	}
+	return rotated
+}
+
+// sample returns the bilinearly interpolated value of the image at the point, where pixel centers
+// are at half coordinates, or false if the point is outside of the image.
+func sample(gray *image.Gray, p r2.Point) (float64, bool) {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	x, y := p.X-0.5, p.Y-0.5
+	if x < 0 || y < 0 || x > float64(width-1) || y > float64(height-1) {
+		return 0, false
+	}
+	x0, y0 := minInt(int(x), width-2), minInt(int(y), height-2)
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(x, y int) float64 {
+		return float64(gray.Pix[y*gray.Stride+x])
+	}
+	top := at(x0, y0)*(1-fx) + at(x0+1, y0)*fx
+	bottom := at(x0, y0+1)*(1-fx) + at(x0+1, y0+1)*fx
+	return top*(1-fy) + bottom*fy, true
+}
+
+// convexHull returns the convex hull of the points using Andrew's monotone chain.
+func convexHull(points []r2.Point) []r2.Point {
+	sorted := append([]r2.Point(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	if len(sorted) < 3 {
+		return sorted
+	}
+	hull := make([]r2.Point, 0, 2*len(sorted))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, p := range sorted {
+			for len(hull) >= start+2 && hull[len(hull)-1].Sub(hull[len(hull)-2]).Cross(p.Sub(hull[len(hull)-2])) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		// the last point is the first of the other chain
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+	}
+	return hull
+}
+
+// polygonArea returns the signed area of the polygon, positive if it is clockwise in the image.
+func polygonArea(polygon []r2.Point) float64 {
+	var area float64
+	for i, p := range polygon {
+		area += p.Cross(polygon[(i+1)%len(polygon)])
+	}
+	return area / 2
+}
+
+// farthest returns the point with the largest distance.
+func farthest(points []r2.Point, distance func(r2.Point) float64) r2.Point {
+	best, bestDistance := points[0], distance(points[0])
+	for _, p := range points[1:] {
+		if d := distance(p); d > bestDistance {
+			best, bestDistance = p, d
+		}
+	}
+	return best
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
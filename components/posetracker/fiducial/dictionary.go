@@ -0,0 +1,172 @@
+package fiducial
+
+import (
+	"math/bits"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// A Dictionary is a family of square markers, each a grid of bits inside a black border one bit
+// wide.
+type Dictionary interface {
+	// Bits is the width of the grid of bits, not counting the border.
+	Bits() int
+	// Identify returns the ID of the marker whose bits are closest to the given ones, read row by
+	// row from the top left with white as true, along with how many bits differ from it. It returns
+	// false if no marker is close enough.
+	Identify(bits []bool) (id, distance int, ok bool)
+}
+
+// The dictionaries included.
+const (
+	DictionaryArucoOriginal = "aruco_original"
+	DictionaryAprilTag36h11 = "apriltag_36h11"
+	DictionaryCustom        = "custom"
+)
+
+// arucoOriginalWords are the words each row of an original ArUco marker is one of. A row's word
+// encodes two bits of the ID in its second and fourth bits, the rest are parity.
+var arucoOriginalWords = [4]int{0x10, 0x17, 0x09, 0x0e}
+
+// arucoOriginal is the dictionary of the original ArUco library, 1024 markers of 5x5 bits.
+type arucoOriginal struct{}
+
+func (arucoOriginal) Bits() int {
+	return 5
+}
+
+func (arucoOriginal) Identify(grid []bool) (int, int, bool) {
+	id := 0
+	for row := 0; row < 5; row++ {
+		word := 0
+		for col := 0; col < 5; col++ {
+			word <<= 1
+			if grid[row*5+col] {
+				word |= 1
+			}
+		}
+		index := -1
+		for i, w := range arucoOriginalWords {
+			if w == word {
+				index = i
+			}
+		}
+		if index < 0 {
+			return 0, 0, false
+		}
+		id = id<<2 | index
+	}
+	return id, 0, true
+}
+
+// arucoOriginalBits returns the bits of the original ArUco marker with the given ID.
+func arucoOriginalBits(id int) []bool {
+	grid := make([]bool, 25)
+	for row := 0; row < 5; row++ {
+		word := arucoOriginalWords[id>>(2*(4-row))&3]
+		for col := 0; col < 5; col++ {
+			grid[row*5+col] = word>>(4-col)&1 == 1
+		}
+	}
+	return grid
+}
+
+// aprilTag36h11Codes are the codes of the AprilTag 36h11 family, read row by row like a custom
+// dictionary's. Only IDs 0 through 72 are included; every other marker of the family differs from
+// all of them by at least 11 bits, so it is never mistaken for one of them, only not detected.
+var aprilTag36h11Codes = []uint64{
+	0xd5d628584, 0xd97f18b49, 0xdd280910e, 0xe479e9c98, 0xebcbca822, 0xf31dab3ac,
+	0x056a5d085, 0x10652e1d4, 0x22b1dfead, 0x265ad0472, 0x34fe91b86, 0x3ff962cd5,
+	0x43a25329a, 0x474b4385f, 0x4e9d243e9, 0x5246149ae, 0x5997f5538, 0x683bb6c4c,
+	0x6be4a7211, 0x7e3158eea, 0x81da494af, 0x858339a74, 0x8cd51a5fe, 0x9f21cc2d7,
+	0xa2cabc89c, 0xadc58d9eb, 0xb16e7dfb0, 0xb8c05eb3a, 0xd25ef139d, 0xd607e1962,
+	0xe4aba3076, 0x2dde6a3da, 0x43d40c678, 0x5620be351, 0x64c47fa65, 0x686d7002a,
+	0x6c16605ef, 0x6fbf50bb4, 0x8d06d39dc, 0x9f53856b5, 0xadf746dc9, 0xbc9b084dd,
+	0xd290aa77b, 0xd9e28b305, 0xe4dd5c454, 0xfad2fe6f2, 0x181a8151a, 0x26be42c2e,
+	0x2e10237b8, 0x405cd5491, 0x7742eab1c, 0x85e6ac230, 0x8d388cdba, 0x9f853ea93,
+	0xc41ea2445, 0xcf1973594, 0x14a34a333, 0x31eacd15b, 0x6c79d2dab, 0x73cbb3935,
+	0x89c155bd3, 0x8d6a46198, 0x91133675d, 0xa708d89fb, 0xae5ab9585, 0xb9558a6d4,
+	0xb98743ab2, 0xd6cec68da, 0x1506bcaef, 0x4becd217a, 0x4f95c273f, 0x658b649dd,
+	0xa76c4b1b7,
+}
+
+// aprilTag36h11 returns the dictionary of AprilTag 36h11 markers, correcting up to 2 bits as the
+// AprilTag library does by default.
+func aprilTag36h11() Dictionary {
+	return &codeDictionary{bits: 6, codes: aprilTag36h11Codes, maxCorrection: 2}
+}
+
+// CustomDictionaryConfig describes a dictionary of markers by their codes, as used by AprilTag
+// families and the predefined ArUco dictionaries.
+type CustomDictionaryConfig struct {
+	// Bits is the width of the grid of bits of each marker, not counting the border.
+	Bits int `json:"bits"`
+	// Codes are the bits of each marker in order of ID, read row by row from the top left with the
+	// first bit most significant and white as 1, as decimal or 0x prefixed hex strings.
+	Codes []string `json:"codes"`
+	// MaxCorrectionBits is how many bits a marker may differ from its code and still be detected.
+	MaxCorrectionBits int `json:"max_correction_bits,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *CustomDictionaryConfig) Validate(path string) error {
+	_, err := NewCodeDictionary(cfg)
+	return err
+}
+
+// codeDictionary is a dictionary of markers given by their codes.
+type codeDictionary struct {
+	bits          int
+	codes         []uint64
+	maxCorrection int
+}
+
+// NewCodeDictionary returns the dictionary of markers described by the config.
+func NewCodeDictionary(cfg *CustomDictionaryConfig) (Dictionary, error) {
+	if cfg.Bits < 2 || cfg.Bits > 8 {
+		return nil, errors.Errorf("bits must be between 2 and 8, got %d", cfg.Bits)
+	}
+	if len(cfg.Codes) == 0 {
+		return nil, errors.New("need at least one code")
+	}
+	if cfg.MaxCorrectionBits < 0 {
+		return nil, errors.New("max_correction_bits can't be negative")
+	}
+	d := &codeDictionary{bits: cfg.Bits, maxCorrection: cfg.MaxCorrectionBits}
+	for i, s := range cfg.Codes {
+		code, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad code %d", i)
+		}
+		if cfg.Bits < 8 && code>>(cfg.Bits*cfg.Bits) != 0 {
+			return nil, errors.Errorf("code %d (%s) has more than %d bits", i, s, cfg.Bits*cfg.Bits)
+		}
+		d.codes = append(d.codes, code)
+	}
+	return d, nil
+}
+
+func (d *codeDictionary) Bits() int {
+	return d.bits
+}
+
+func (d *codeDictionary) Identify(grid []bool) (int, int, bool) {
+	var code uint64
+	for _, bit := range grid {
+		code <<= 1
+		if bit {
+			code |= 1
+		}
+	}
+	best, bestDistance := -1, d.maxCorrection+1
+	for id, c := range d.codes {
+		if distance := bits.OnesCount64(c ^ code); distance < bestDistance {
+			best, bestDistance = id, distance
+		}
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+	return best, bestDistance, true
+}
@@ -0,0 +1,214 @@
+// Package fiducial implements a pose tracker that finds square fiducial markers in a camera's
+// images and estimates their poses from the camera's intrinsics.
+//
+// The original ArUco dictionary is built in. Other ArUco dictionaries and AprilTag families can
+// be configured as custom dictionaries by listing their codes, which are not bundled here.
+package fiducial
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("fiducial")
+
+// Config is used for converting config attributes of a fiducial pose tracker.
+type Config struct {
+	Camera string `json:"camera"`
+	// Dictionary is the family of markers to find, aruco_original by default, apriltag_36h11, or
+	// custom to use the custom dictionary.
+	Dictionary       string                  `json:"dictionary,omitempty"`
+	CustomDictionary *CustomDictionaryConfig `json:"custom_dictionary,omitempty"`
+	// MarkerSizeMM is the length of the sides of markers, at the outside of their black border.
+	MarkerSizeMM float64 `json:"marker_size_mm"`
+	// MarkerSizesMM overrides the size of markers by their ID.
+	MarkerSizesMM map[string]float64 `json:"marker_sizes_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Camera == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+	if cfg.MarkerSizeMM == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "marker_size_mm")
+	}
+	if cfg.MarkerSizeMM < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("marker_size_mm must be positive"))
+	}
+	for id, size := range cfg.MarkerSizesMM {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("marker_sizes_mm: bad marker ID %q", id))
+		}
+		if size <= 0 {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("marker_sizes_mm: size of marker %s must be positive", id))
+		}
+	}
+	if _, err := cfg.dictionary(); err != nil {
+		return nil, utils.NewConfigValidationError(path, err)
+	}
+	return []string{cfg.Camera}, nil
+}
+
+func (cfg *Config) dictionary() (Dictionary, error) {
+	switch cfg.Dictionary {
+	case "", DictionaryArucoOriginal:
+		return arucoOriginal{}, nil
+	case DictionaryAprilTag36h11:
+		return aprilTag36h11(), nil
+	case DictionaryCustom:
+		if cfg.CustomDictionary == nil {
+			return nil, errors.New("custom dictionary needs custom_dictionary")
+		}
+		dict, err := NewCodeDictionary(cfg.CustomDictionary)
+		if err != nil {
+			return nil, errors.Wrap(err, "custom_dictionary")
+		}
+		return dict, nil
+	default:
+		return nil, errors.Errorf("unknown dictionary %q", cfg.Dictionary)
+	}
+}
+
+func init() {
+	registry.RegisterComponent(
+		posetracker.Subtype,
+		model,
+		registry.Component{
+			Constructor: func(
+				ctx context.Context,
+				deps registry.Dependencies,
+				config config.Component,
+				logger golog.Logger,
+			) (interface{}, error) {
+				return newFiducialTracker(deps, config, logger)
+			},
+		})
+	config.RegisterComponentAttributeMapConverter(
+		posetracker.Subtype,
+		model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf Config
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&Config{})
+}
+
+// BodyName returns the name of the body the marker with the ID is tracked as.
+func BodyName(id int) string {
+	return fmt.Sprintf("marker_%d", id)
+}
+
+type fiducialTracker struct {
+	generic.Unimplemented
+	cameraName  string
+	cam         camera.Camera
+	dictionary  Dictionary
+	markerSize  float64
+	markerSizes map[int]float64
+	logger      golog.Logger
+}
+
+func newFiducialTracker(
+	deps registry.Dependencies,
+	cfg config.Component,
+	logger golog.Logger,
+) (posetracker.PoseTracker, error) {
+	conf, ok := cfg.ConvertedAttributes.(*Config)
+	if !ok {
+		return nil, rdkutils.NewUnexpectedTypeError(conf, cfg.ConvertedAttributes)
+	}
+	cam, err := camera.FromDependencies(deps, conf.Camera)
+	if err != nil {
+		return nil, err
+	}
+	dict, err := conf.dictionary()
+	if err != nil {
+		return nil, err
+	}
+	markerSizes := map[int]float64{}
+	for id, size := range conf.MarkerSizesMM {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.Errorf("bad marker ID %q", id)
+		}
+		markerSizes[n] = size
+	}
+	return &fiducialTracker{
+		cameraName:  conf.Camera,
+		cam:         cam,
+		dictionary:  dict,
+		markerSize:  conf.MarkerSizeMM,
+		markerSizes: markerSizes,
+		logger:      logger,
+	}, nil
+}
+
+// Poses returns the poses of the markers in the camera's next image, in the camera's frame, where
+// z is forward and y is down. A marker's z axis points out of its face and its y axis to its top.
+// All markers seen are returned if no body names are given.
+func (ft *fiducialTracker) Poses(
+	ctx context.Context, bodyNames []string, extra map[string]interface{},
+) (posetracker.BodyToPoseInFrame, error) {
+	props, err := ft.cam.Properties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if props.IntrinsicParams == nil {
+		return nil, errors.Errorf("camera %q has no intrinsic parameters to estimate marker poses with", ft.cameraName)
+	}
+	img, release, err := camera.ReadImage(ctx, ft.cam)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	intrinsics := props.IntrinsicParams
+	if bounds := img.Bounds(); intrinsics.Width != 0 && (bounds.Dx() != intrinsics.Width || bounds.Dy() != intrinsics.Height) {
+		return nil, errors.Errorf(
+			"camera %q image is %dx%d but its intrinsics are for %dx%d",
+			ft.cameraName, bounds.Dx(), bounds.Dy(), intrinsics.Width, intrinsics.Height,
+		)
+	}
+
+	wanted := make(map[string]bool, len(bodyNames))
+	for _, name := range bodyNames {
+		wanted[name] = true
+	}
+	poses := posetracker.BodyToPoseInFrame{}
+	for _, m := range detectMarkers(img, ft.dictionary) {
+		name := BodyName(m.id)
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		size, ok := ft.markerSizes[m.id]
+		if !ok {
+			size = ft.markerSize
+		}
+		pose, err := estimatePose(m.corners, size, intrinsics, props.DistortionParams)
+		if err != nil {
+			ft.logger.Debugw("could not estimate marker pose", "marker", name, "error", err)
+			continue
+		}
+		poses[name] = referenceframe.NewPoseInFrame(ft.cameraName, pose)
+	}
+	return poses, nil
+}
+
+// Readings returns the poses of all markers in view.
+func (ft *fiducialTracker) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return posetracker.Readings(ctx, ft)
+}
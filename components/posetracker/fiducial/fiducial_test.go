@@ -0,0 +1,361 @@
+package fiducial
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+var testIntrinsics = &transform.PinholeCameraIntrinsics{
+	Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240,
+}
+
+// facingCamera is the rotation of an upright marker facing the camera.
+var facingCamera = rotation{{1, 0, 0}, {0, -1, 0}, {0, 0, -1}}
+
+type placedMarker struct {
+	bits []bool
+	size float64
+	r    rotation
+	t    r3.Vector
+}
+
+// renderMarkers renders the markers on a white background by casting a ray through each of
+// several samples of every pixel.
+func renderMarkers(markers []placedMarker, distortion transform.Distorter) *image.Gray {
+	const samples = 4
+	img := image.NewGray(image.Rect(0, 0, testIntrinsics.Width, testIntrinsics.Height))
+	for v := 0; v < testIntrinsics.Height; v++ {
+		for u := 0; u < testIntrinsics.Width; u++ {
+			var sum float64
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					pixel := r2.Point{X: float64(u) + (float64(sx)+0.5)/samples, Y: float64(v) + (float64(sy)+0.5)/samples}
+					ray := undistort(pixel, testIntrinsics, distortion)
+					sum += shade(markers, r3.Vector{X: ray.X, Y: ray.Y, Z: 1})
+				}
+			}
+			img.SetGray(u, v, color.Gray{uint8(sum / (samples * samples))})
+		}
+	}
+	return img
+}
+
+func shade(markers []placedMarker, ray r3.Vector) float64 {
+	const black, white = 30, 220
+	for _, m := range markers {
+		// the point along the ray in the marker's plane, in the marker's frame
+		var inverse rotation
+		for i := range inverse {
+			for j := range inverse[i] {
+				inverse[i][j] = m.r[j][i]
+			}
+		}
+		rtRay, rtT := inverse.apply(ray), inverse.apply(m.t)
+		s := rtT.Z / rtRay.Z
+		p := rtRay.Mul(s).Sub(rtT)
+		cells := int(math.Sqrt(float64(len(m.bits)))) + 2
+		col := int(math.Floor((p.X + m.size/2) / m.size * float64(cells)))
+		row := int(math.Floor((m.size/2 - p.Y) / m.size * float64(cells)))
+		if s <= 0 || col < 0 || row < 0 || col >= cells || row >= cells {
+			continue
+		}
+		if isBorder(row, col, cells) || !m.bits[(row-1)*(cells-2)+col-1] {
+			return black
+		}
+		return white
+	}
+	return white
+}
+
+// testCorners returns where the corners of the marker are in the camera's frame.
+func testCorners(m placedMarker) []r3.Vector {
+	var corners []r3.Vector
+	for _, c := range markerCorners(m.size) {
+		corners = append(corners, m.r.apply(r3.Vector{X: c.X, Y: c.Y}).Add(m.t))
+	}
+	return corners
+}
+
+func checkPose(t *testing.T, pose spatialmath.Pose, m placedMarker, tolerance float64) {
+	t.Helper()
+	for i, c := range markerCorners(m.size) {
+		estimated := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(r3.Vector{X: c.X, Y: c.Y})).Point()
+		expected := testCorners(m)[i]
+		test.That(t, estimated.Sub(expected).Norm(), test.ShouldBeLessThan, tolerance)
+	}
+}
+
+func TestArucoOriginal(t *testing.T) {
+	dict := arucoOriginal{}
+	for _, id := range []int{0, 1, 300, 1023} {
+		found, distance, ok := dict.Identify(arucoOriginalBits(id))
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, found, test.ShouldEqual, id)
+		test.That(t, distance, test.ShouldEqual, 0)
+	}
+	bits := arucoOriginalBits(300)
+	bits[7] = !bits[7]
+	_, _, ok := dict.Identify(bits)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestCodeDictionary(t *testing.T) {
+	dict, err := NewCodeDictionary(&CustomDictionaryConfig{Bits: 3, Codes: []string{"0x1ff", "0b000011111", "7"}, MaxCorrectionBits: 1})
+	test.That(t, err, test.ShouldBeNil)
+	bits := func(code uint) []bool {
+		grid := make([]bool, 9)
+		for i := range grid {
+			grid[i] = code>>(8-i)&1 == 1
+		}
+		return grid
+	}
+	id, distance, ok := dict.Identify(bits(0x1ff))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 0)
+	test.That(t, distance, test.ShouldEqual, 0)
+	id, distance, ok = dict.Identify(bits(0x01b))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 1)
+	test.That(t, distance, test.ShouldEqual, 1)
+	_, _, ok = dict.Identify(bits(0x0c0))
+	test.That(t, ok, test.ShouldBeFalse)
+
+	_, err = NewCodeDictionary(&CustomDictionaryConfig{Bits: 3, Codes: []string{"0x200"}})
+	test.That(t, err, test.ShouldBeError, "code 0 (0x200) has more than 9 bits")
+	_, err = NewCodeDictionary(&CustomDictionaryConfig{Bits: 3, Codes: []string{"zz"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewCodeDictionary(&CustomDictionaryConfig{Bits: 1, Codes: []string{"1"}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestAprilTag36h11(t *testing.T) {
+	cfg := Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: DictionaryAprilTag36h11}
+	dict, err := cfg.dictionary()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dict.Bits(), test.ShouldEqual, 6)
+	bits := func(id int) []bool {
+		grid := make([]bool, 36)
+		for i := range grid {
+			grid[i] = aprilTag36h11Codes[id]>>(35-i)&1 == 1
+		}
+		return grid
+	}
+
+	noisy := bits(40)
+	noisy[3], noisy[20] = !noisy[3], !noisy[20]
+	id, distance, ok := dict.Identify(noisy)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 40)
+	test.That(t, distance, test.ShouldEqual, 2)
+
+	markers := []placedMarker{
+		{bits: bits(7), size: 80, r: rodrigues(r3.Vector{X: 0.3, Z: 0.8}).mul(facingCamera), t: r3.Vector{X: -90, Y: 10, Z: 450}},
+		{bits: bits(72), size: 80, r: rodrigues(r3.Vector{Y: -0.4, Z: -2.5}).mul(facingCamera), t: r3.Vector{X: 90, Y: -20, Z: 420}},
+	}
+	found := detectMarkers(renderMarkers(markers, nil), dict)
+	test.That(t, found, test.ShouldHaveLength, 2)
+	for _, m := range markers {
+		want, _, _ := dict.Identify(m.bits)
+		var detected *marker
+		for i := range found {
+			if found[i].id == want {
+				detected = &found[i]
+			}
+		}
+		test.That(t, detected, test.ShouldNotBeNil)
+		pose, err := estimatePose(detected.corners, m.size, testIntrinsics, nil)
+		test.That(t, err, test.ShouldBeNil)
+		checkPose(t, pose, m, 3)
+	}
+}
+
+func TestUndistort(t *testing.T) {
+	distortion, err := transform.NewBrownConrady([]float64{-0.2, 0.05, 0, 0.001, -0.002})
+	test.That(t, err, test.ShouldBeNil)
+	x, y := distortion.Transform(0.3, -0.2)
+	pixel := r2.Point{X: x*testIntrinsics.Fx + testIntrinsics.Ppx, Y: y*testIntrinsics.Fy + testIntrinsics.Ppy}
+	undistorted := undistort(pixel, testIntrinsics, distortion)
+	test.That(t, undistorted.X, test.ShouldAlmostEqual, 0.3, 1e-6)
+	test.That(t, undistorted.Y, test.ShouldAlmostEqual, -0.2, 1e-6)
+}
+
+func TestDetectAndEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		markers    []placedMarker
+		distortion transform.Distorter
+	}{
+		{
+			name: "facing",
+			markers: []placedMarker{
+				{bits: arucoOriginalBits(123), size: 100, r: facingCamera, t: r3.Vector{X: 30, Y: -20, Z: 400}},
+			},
+		},
+		{
+			name: "tilted and turned",
+			markers: []placedMarker{{
+				bits: arucoOriginalBits(700),
+				size: 80,
+				r:    rodrigues(r3.Vector{X: 0.4, Y: -0.3, Z: 2}).mul(facingCamera),
+				t:    r3.Vector{X: -40, Y: 25, Z: 350},
+			}},
+		},
+		{
+			name: "two markers",
+			markers: []placedMarker{
+				{bits: arucoOriginalBits(5), size: 60, r: rodrigues(r3.Vector{Z: math.Pi}).mul(facingCamera), t: r3.Vector{X: -80, Z: 500}},
+				{bits: arucoOriginalBits(1000), size: 60, r: rodrigues(r3.Vector{Y: 0.5}).mul(facingCamera), t: r3.Vector{X: 90, Y: 30, Z: 450}},
+			},
+		},
+		{
+			name: "distorted",
+			markers: []placedMarker{
+				{bits: arucoOriginalBits(42), size: 100, r: rodrigues(r3.Vector{X: -0.3}).mul(facingCamera), t: r3.Vector{X: 120, Y: 80, Z: 450}},
+			},
+			distortion: &transform.BrownConrady{RadialK1: -0.15, RadialK2: 0.05},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			found := detectMarkers(renderMarkers(tc.markers, tc.distortion), arucoOriginal{})
+			test.That(t, found, test.ShouldHaveLength, len(tc.markers))
+			for _, m := range tc.markers {
+				id, _, _ := arucoOriginal{}.Identify(m.bits)
+				var detected *marker
+				for i := range found {
+					if found[i].id == id {
+						detected = &found[i]
+					}
+				}
+				test.That(t, detected, test.ShouldNotBeNil)
+
+				// corners are found to a fraction of a pixel
+				for i, c := range testCorners(m) {
+					x, y := c.X/c.Z, c.Y/c.Z
+					if tc.distortion != nil {
+						x, y = tc.distortion.Transform(x, y)
+					}
+					expected := r2.Point{X: x*testIntrinsics.Fx + testIntrinsics.Ppx, Y: y*testIntrinsics.Fy + testIntrinsics.Ppy}
+					test.That(t, detected.corners[i].Sub(expected).Norm(), test.ShouldBeLessThan, 0.5)
+				}
+
+				pose, err := estimatePose(detected.corners, m.size, testIntrinsics, tc.distortion)
+				test.That(t, err, test.ShouldBeNil)
+				checkPose(t, pose, m, 3)
+			}
+		})
+	}
+}
+
+func TestEstimatePose(t *testing.T) {
+	m := placedMarker{size: 100, r: rodrigues(r3.Vector{X: 0.2, Y: 0.5, Z: -1}).mul(facingCamera), t: r3.Vector{X: 10, Y: 20, Z: 600}}
+	var corners [4]r2.Point
+	for i, c := range testCorners(m) {
+		corners[i] = r2.Point{X: c.X/c.Z*testIntrinsics.Fx + testIntrinsics.Ppx, Y: c.Y/c.Z*testIntrinsics.Fy + testIntrinsics.Ppy}
+	}
+	pose, err := estimatePose(corners, m.size, testIntrinsics, nil)
+	test.That(t, err, test.ShouldBeNil)
+	checkPose(t, pose, m, 1e-6)
+	test.That(t, pose.Point().Sub(m.t).Norm(), test.ShouldBeLessThan, 1e-6)
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := Config{Camera: "cam", MarkerSizeMM: 50}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	for _, tc := range []struct {
+		cfg Config
+		err string
+	}{
+		{Config{MarkerSizeMM: 50}, `"camera" is required`},
+		{Config{Camera: "cam"}, `"marker_size_mm" is required`},
+		{Config{Camera: "cam", MarkerSizeMM: -1}, "marker_size_mm must be positive"},
+		{Config{Camera: "cam", MarkerSizeMM: 50, MarkerSizesMM: map[string]float64{"one": 20}}, `bad marker ID "one"`},
+		{Config{Camera: "cam", MarkerSizeMM: 50, MarkerSizesMM: map[string]float64{"1": 0}}, "size of marker 1 must be positive"},
+		{Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: "apriltag"}, `unknown dictionary "apriltag"`},
+		{Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: DictionaryCustom}, "custom dictionary needs custom_dictionary"},
+		{
+			Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: DictionaryCustom, CustomDictionary: &CustomDictionaryConfig{Bits: 4}},
+			"custom_dictionary: need at least one code",
+		},
+	} {
+		_, err := tc.cfg.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+	}
+}
+
+func TestFiducialTracker(t *testing.T) {
+	ctx := context.Background()
+	markers := []placedMarker{
+		{bits: arucoOriginalBits(1), size: 60, r: facingCamera, t: r3.Vector{X: -80, Z: 500}},
+		{bits: arucoOriginalBits(2), size: 90, r: facingCamera, t: r3.Vector{X: 90, Z: 500}},
+	}
+	img := renderMarkers(markers, nil)
+	cam := &inject.Camera{}
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			return img, func() {}, nil
+		})), nil
+	}
+	props := camera.Properties{IntrinsicParams: testIntrinsics}
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return props, nil
+	}
+
+	tracker, err := newFiducialTracker(
+		registry.Dependencies{camera.Named("cam"): cam},
+		config.Component{ConvertedAttributes: &Config{
+			Camera:        "cam",
+			MarkerSizeMM:  60,
+			MarkerSizesMM: map[string]float64{"2": 90},
+		}},
+		golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldBeNil)
+
+	poses, err := tracker.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poses, test.ShouldHaveLength, 2)
+	for i, name := range []string{"marker_1", "marker_2"} {
+		test.That(t, poses[name].Parent(), test.ShouldEqual, "cam")
+		checkPose(t, poses[name].Pose(), markers[i], 3)
+	}
+
+	poses, err = tracker.Poses(ctx, []string{"marker_2", "marker_3"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poses, test.ShouldHaveLength, 1)
+	test.That(t, poses, test.ShouldContainKey, "marker_2")
+
+	readings, err := tracker.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldHaveLength, 2)
+
+	props = camera.Properties{IntrinsicParams: &transform.PinholeCameraIntrinsics{Width: 1280, Height: 720, Fx: 600, Fy: 600}}
+	_, err = tracker.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeError, `camera "cam" image is 640x480 but its intrinsics are for 1280x720`)
+
+	props = camera.Properties{}
+	_, err = tracker.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeError, `camera "cam" has no intrinsic parameters to estimate marker poses with`)
+
+	_, err = newFiducialTracker(registry.Dependencies{}, config.Component{ConvertedAttributes: &Config{Camera: "cam"}}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,229 @@
+package fiducial
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// undistortIterations is how many fixed point iterations undistorting a point takes.
+	undistortIterations = 20
+	// maxRefineIterations is how many iterations refining a pose may take.
+	maxRefineIterations = 50
+)
+
+// rotation is a 3x3 rotation matrix, by row.
+type rotation [3][3]float64
+
+func (r rotation) mul(o rotation) rotation {
+	var m rotation
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += r[i][k] * o[k][j]
+			}
+		}
+	}
+	return m
+}
+
+func (r rotation) apply(v r3.Vector) r3.Vector {
+	return r3.Vector{
+		X: r[0][0]*v.X + r[0][1]*v.Y + r[0][2]*v.Z,
+		Y: r[1][0]*v.X + r[1][1]*v.Y + r[1][2]*v.Z,
+		Z: r[2][0]*v.X + r[2][1]*v.Y + r[2][2]*v.Z,
+	}
+}
+
+// rodrigues returns the rotation about the axis of the vector by its length in radians.
+func rodrigues(v r3.Vector) rotation {
+	angle := v.Norm()
+	if angle < 1e-12 {
+		return rotation{{1, -v.Z, v.Y}, {v.Z, 1, -v.X}, {-v.Y, v.X, 1}}
+	}
+	k := v.Mul(1 / angle)
+	s, c := math.Sin(angle), math.Cos(angle)
+	t := 1 - c
+	return rotation{
+		{c + k.X*k.X*t, k.X*k.Y*t - k.Z*s, k.X*k.Z*t + k.Y*s},
+		{k.Y*k.X*t + k.Z*s, c + k.Y*k.Y*t, k.Y*k.Z*t - k.X*s},
+		{k.Z*k.X*t - k.Y*s, k.Z*k.Y*t + k.X*s, c + k.Z*k.Z*t},
+	}
+}
+
+// nearestRotation returns the rotation closest to the matrix whose columns are given.
+func nearestRotation(x, y, z r3.Vector) (rotation, error) {
+	m := mat.NewDense(3, 3, []float64{x.X, y.X, z.X, x.Y, y.Y, z.Y, x.Z, y.Z, z.Z})
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDFull) {
+		return rotation{}, errors.New("could not factorize rotation")
+	}
+	var u, v, uvt mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	uvt.Mul(&u, v.T())
+	if mat.Det(&uvt) < 0 {
+		for i := 0; i < 3; i++ {
+			u.Set(i, 2, -u.At(i, 2))
+		}
+		uvt.Mul(&u, v.T())
+	}
+	var r rotation
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = uvt.At(i, j)
+		}
+	}
+	return r, nil
+}
+
+// markerCorners returns the corners of a marker of the given size in its own frame, whose origin
+// is its center, with x to its right, y up and z out of its face. They are in the same order as a
+// marker's image corners.
+func markerCorners(size float64) []r2.Point {
+	half := size / 2
+	return []r2.Point{{X: -half, Y: half}, {X: half, Y: half}, {X: half, Y: -half}, {X: -half, Y: -half}}
+}
+
+// undistort returns the normalized image coordinates of the pixel with the distortion removed.
+func undistort(p r2.Point, intrinsics *transform.PinholeCameraIntrinsics, distortion transform.Distorter) r2.Point {
+	distorted := r2.Point{X: (p.X - intrinsics.Ppx) / intrinsics.Fx, Y: (p.Y - intrinsics.Ppy) / intrinsics.Fy}
+	if distortion == nil {
+		return distorted
+	}
+	undistorted := distorted
+	for i := 0; i < undistortIterations; i++ {
+		x, y := distortion.Transform(undistorted.X, undistorted.Y)
+		undistorted = undistorted.Add(distorted.Sub(r2.Point{X: x, Y: y}))
+	}
+	return undistorted
+}
+
+// estimatePose returns the pose in the camera frame of a square marker of the given size, with x
+// to the right of the image, y down and z forward, from the pixels of its corners.
+func estimatePose(
+	corners [4]r2.Point,
+	size float64,
+	intrinsics *transform.PinholeCameraIntrinsics,
+	distortion transform.Distorter,
+) (spatialmath.Pose, error) {
+	observed := make([]r2.Point, len(corners))
+	for i, c := range corners {
+		observed[i] = undistort(c, intrinsics, distortion)
+	}
+	object := markerCorners(size)
+
+	// the homography from the marker's plane to the image is the first two columns of its rotation
+	// and its translation, up to scale
+	homography, err := transform.EstimateExactHomographyFrom8Points(object, observed, false)
+	if err != nil {
+		return nil, err
+	}
+	column := func(c int) r3.Vector {
+		return r3.Vector{X: homography.At(0, c), Y: homography.At(1, c), Z: homography.At(2, c)}
+	}
+	x, y, t := column(0), column(1), column(2)
+	scale := 2 / (x.Norm() + y.Norm())
+	if t.Z < 0 {
+		scale = -scale
+	}
+	x, y, t = x.Mul(scale), y.Mul(scale), t.Mul(scale)
+	r, err := nearestRotation(x, y, x.Cross(y))
+	if err != nil {
+		return nil, err
+	}
+	r, t = refinePose(r, t, object, observed)
+
+	// spatialmath's rotation matrices are stored transposed
+	o, err := spatialmath.NewRotationMatrix([]float64{
+		r[0][0], r[1][0], r[2][0],
+		r[0][1], r[1][1], r[2][1],
+		r[0][2], r[1][2], r[2][2],
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPose(t, o), nil
+}
+
+// reprojectionErrors returns the differences between where the points of the marker's plane are
+// projected to with the pose and where they were observed.
+func reprojectionErrors(r rotation, t r3.Vector, object, observed []r2.Point) []float64 {
+	errs := make([]float64, 0, 2*len(object))
+	for i, p := range object {
+		c := r.apply(r3.Vector{X: p.X, Y: p.Y}).Add(t)
+		errs = append(errs, c.X/c.Z-observed[i].X, c.Y/c.Z-observed[i].Y)
+	}
+	return errs
+}
+
+func sumOfSquares(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v * v
+	}
+	return sum
+}
+
+// refinePose minimizes the reprojection error of the pose with Levenberg-Marquardt.
+func refinePose(r rotation, t r3.Vector, object, observed []r2.Point) (rotation, r3.Vector) {
+	const epsilon = 1e-7
+	update := func(r rotation, t r3.Vector, delta []float64) (rotation, r3.Vector) {
+		return r.mul(rodrigues(r3.Vector{X: delta[0], Y: delta[1], Z: delta[2]})),
+			t.Add(r3.Vector{X: delta[3], Y: delta[4], Z: delta[5]})
+	}
+
+	errs := reprojectionErrors(r, t, object, observed)
+	cost := sumOfSquares(errs)
+	damping := 1e-3
+	for i := 0; i < maxRefineIterations && damping < 1e10; i++ {
+		jacobian := mat.NewDense(len(errs), 6, nil)
+		for p := 0; p < 6; p++ {
+			delta := make([]float64, 6)
+			delta[p] = epsilon
+			perturbedR, perturbedT := update(r, t, delta)
+			perturbed := reprojectionErrors(perturbedR, perturbedT, object, observed)
+			for e := range errs {
+				jacobian.Set(e, p, (perturbed[e]-errs[e])/epsilon)
+			}
+		}
+
+		var jtj mat.Dense
+		jtj.Mul(jacobian.T(), jacobian)
+		for p := 0; p < 6; p++ {
+			jtj.Set(p, p, jtj.At(p, p)*(1+damping))
+		}
+		var jte, step mat.VecDense
+		jte.MulVec(jacobian.T(), mat.NewVecDense(len(errs), errs))
+		if err := step.SolveVec(&jtj, &jte); err != nil {
+			damping *= 10
+			continue
+		}
+		delta := make([]float64, 6)
+		for p := range delta {
+			delta[p] = -step.AtVec(p)
+		}
+
+		nextR, nextT := update(r, t, delta)
+		nextErrs := reprojectionErrors(nextR, nextT, object, observed)
+		nextCost := sumOfSquares(nextErrs)
+		if nextCost >= cost {
+			damping *= 10
+			continue
+		}
+		converged := cost-nextCost < 1e-12*cost
+		r, t, errs, cost = nextR, nextT, nextErrs, nextCost
+		damping /= 10
+		if converged {
+			break
+		}
+	}
+	return r, t
+}
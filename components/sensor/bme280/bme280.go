@@ -464,12 +464,11 @@ func (s *bme280) setOverSample(ctx context.Context, addr, offset, val byte) erro
 	if err != nil {
 		return err
 	}
-	err = s.setMode(ctx, mode)
-	if err != nil {
+	// the handle locks the bus, so it has to be closed before setMode opens another
+	if err := handle.Close(); err != nil {
 		return err
 	}
-
-	return handle.Close()
+	return s.setMode(ctx, mode)
 }
 
 // setupCalibration sets up all calibration data for the chip.
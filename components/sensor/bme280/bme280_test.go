@@ -0,0 +1,54 @@
+package bme280
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	bus := simdevice.NewI2CBus()
+	chip := simdevice.NewBME280(simdevice.DefaultBME280Calibration)
+	// the raw readings from the compensation examples of the datasheet
+	chip.SetRawMeasurements(415148, 519888, 30000)
+	bus.Attach(defaultI2Caddr, chip)
+	deps := registry.Dependencies{
+		board.Named("board"): &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) {
+			return bus, name == "main"
+		}},
+	}
+
+	s, err := newSensor(ctx, deps, "bme", &AttrConfig{Board: "board", I2CBus: "main"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chip.Mode(), test.ShouldEqual, activeMode)
+	humidity, pressure, temperature := chip.Oversampling()
+	test.That(t, humidity, test.ShouldEqual, 1)
+	test.That(t, pressure, test.ShouldEqual, 1)
+	test.That(t, temperature, test.ShouldEqual, 1)
+	test.That(t, bus.Transactions()[0], test.ShouldResemble, simdevice.I2CTransaction{
+		Addr: defaultI2Caddr, Write: true, Data: []byte{bme280RSTReg, 0xB6},
+	})
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temperature_celsius"], test.ShouldAlmostEqual, 25.08, 0.05)
+	test.That(t, readings["pressure_mpa"], test.ShouldAlmostEqual, 1006.53, 0.05)
+	test.That(t, readings["relative_humidity_pct"], test.ShouldAlmostEqual, 55, 0.01)
+	test.That(t, readings["temperature_fahrenheit"], test.ShouldAlmostEqual, 77.14, 0.1)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+
+	bus.Detach(defaultI2Caddr)
+	_, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no I2C device at address 0x77")
+}
@@ -0,0 +1,286 @@
+// Package can implements a sensor that decodes signals out of the frames on a CAN bus,
+// described the way a DBC file would describe them.
+package can
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("can")
+
+// SignalConfig describes where a value lives in a CAN frame. Values are reported as
+// raw * scale + offset.
+type SignalConfig struct {
+	Name     string `json:"name"`
+	FrameID  uint32 `json:"frame_id"`
+	Extended bool   `json:"extended,omitempty"`
+	// StartBit is the least significant bit of little endian (Intel) signals and the most
+	// significant bit of big endian (Motorola) signals, numbered as in a DBC file.
+	StartBit  uint    `json:"start_bit"`
+	Length    uint    `json:"length"`
+	BigEndian bool    `json:"big_endian,omitempty"`
+	Signed    bool    `json:"signed,omitempty"`
+	Scale     float64 `json:"scale,omitempty"`
+	Offset    float64 `json:"offset,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *SignalConfig) Validate(path string) error {
+	if conf.Name == "" {
+		return utils.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	frame := board.CANFrame{ID: conf.FrameID, Extended: conf.Extended}
+	if err := frame.Validate(); err != nil {
+		return utils.NewConfigValidationError(path, err)
+	}
+	if conf.Length == 0 || conf.Length > 64 {
+		return utils.NewConfigValidationError(path, errors.New("length must be between 1 and 64 bits"))
+	}
+	if conf.StartBit > 63 {
+		return utils.NewConfigValidationError(path, errors.New("start_bit must be between 0 and 63"))
+	}
+	if _, ok := conf.lastByte(); !ok {
+		return utils.NewConfigValidationError(path, errors.Errorf("signal %q does not fit in a CAN frame", conf.Name))
+	}
+	return nil
+}
+
+// lastByte returns the highest frame byte the signal touches.
+func (conf *SignalConfig) lastByte() (uint, bool) {
+	if !conf.BigEndian {
+		end := conf.StartBit + conf.Length - 1
+		return end / 8, end < 64
+	}
+	// Motorola signals run from the start bit towards bit 0 of its byte, then on to bit 7 of the next byte.
+	pos := conf.StartBit
+	for i := uint(1); i < conf.Length; i++ {
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+		if pos > 63 {
+			return 0, false
+		}
+	}
+	return pos / 8, true
+}
+
+// Decode extracts the scaled value of the signal from the frame data.
+func (conf *SignalConfig) Decode(data []byte) (float64, error) {
+	last, _ := conf.lastByte()
+	if int(last) >= len(data) {
+		return 0, errors.Errorf("signal %q needs %d bytes of data, frame has %d", conf.Name, last+1, len(data))
+	}
+	var raw uint64
+	if conf.BigEndian {
+		pos := conf.StartBit
+		for i := uint(0); i < conf.Length; i++ {
+			raw = raw<<1 | uint64(data[pos/8]>>(pos%8)&1)
+			if pos%8 == 0 {
+				pos += 15
+			} else {
+				pos--
+			}
+		}
+	} else {
+		for i := conf.Length; i > 0; i-- {
+			pos := conf.StartBit + i - 1
+			raw = raw<<1 | uint64(data[pos/8]>>(pos%8)&1)
+		}
+	}
+	scale := conf.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	value := float64(raw)
+	if conf.Signed {
+		// sign extend by moving the top bit of the signal to the top of the word
+		shift := 64 - conf.Length
+		value = float64(int64(raw<<shift) >> shift)
+	}
+	return value*scale + conf.Offset, nil
+}
+
+// AttrConfig is used for converting config attributes.
+type AttrConfig struct {
+	Board   string         `json:"board"`
+	CANBus  string         `json:"can_bus"`
+	Signals []SignalConfig `json:"signals"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *AttrConfig) Validate(path string) ([]string, error) {
+	if conf.Board == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if conf.CANBus == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "can_bus")
+	}
+	if len(conf.Signals) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "signals")
+	}
+	names := map[string]bool{}
+	for idx, sig := range conf.Signals {
+		sigPath := fmt.Sprintf("%s.%s.%d", path, "signals", idx)
+		if err := sig.Validate(sigPath); err != nil {
+			return nil, err
+		}
+		if names[sig.Name] {
+			return nil, utils.NewConfigValidationError(sigPath, errors.Errorf("duplicate signal name %q", sig.Name))
+		}
+		names[sig.Name] = true
+	}
+	return []string{conf.Board}, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		sensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			attr, ok := config.ConvertedAttributes.(*AttrConfig)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attr, config.ConvertedAttributes)
+			}
+			b, err := board.FromDependencies(deps, attr.Board)
+			if err != nil {
+				return nil, err
+			}
+			return newSensor(b, attr, config.Name, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(sensor.Subtype, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		}, &AttrConfig{})
+}
+
+func newSensor(b board.Board, attr *AttrConfig, name string, logger golog.Logger) (sensor.Sensor, error) {
+	cb, ok := b.(board.CANBoard)
+	if !ok {
+		return nil, errors.Errorf("board %s has no CAN buses", attr.Board)
+	}
+	bus, ok := cb.CANByName(attr.CANBus)
+	if !ok {
+		return nil, errors.Errorf("can't find CAN bus (%s) requested by sensor (%s)", attr.CANBus, name)
+	}
+
+	// only receive the frames carrying configured signals
+	var filters []board.CANFilter
+	seen := map[board.CANFilter]bool{}
+	for _, sig := range attr.Signals {
+		f := board.CANFilter{ID: sig.FrameID, Mask: 0x7FF, Extended: sig.Extended}
+		if sig.Extended {
+			f.Mask = 0x1FFFFFFF
+		}
+		if !seen[f] {
+			seen[f] = true
+			filters = append(filters, f)
+		}
+	}
+	handle, err := bus.OpenHandle(filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &canSensor{
+		signals: attr.Signals,
+		handle:  handle,
+		values:  map[string]float64{},
+		cancel:  cancel,
+		logger:  logger,
+	}
+	s.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		s.receive(ctx)
+	}, s.activeBackgroundWorkers.Done)
+	return s, nil
+}
+
+// canSensor keeps the latest value of every signal seen on the bus.
+type canSensor struct {
+	generic.Unimplemented
+	signals []SignalConfig
+	handle  board.CANHandle
+	logger  golog.Logger
+
+	mu     sync.Mutex
+	values map[string]float64
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func (s *canSensor) receive(ctx context.Context) {
+	for {
+		frame, err := s.handle.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Errorw("failed to receive CAN frame", "error", err)
+			if !utils.SelectContextOrWait(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+		if frame.Remote {
+			continue
+		}
+		for i := range s.signals {
+			sig := &s.signals[i]
+			if sig.FrameID != frame.ID || sig.Extended != frame.Extended {
+				continue
+			}
+			v, err := sig.Decode(frame.Data)
+			if err != nil {
+				s.logger.Debugw("failed to decode CAN signal", "error", err)
+				continue
+			}
+			s.mu.Lock()
+			s.values[sig.Name] = v
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Readings returns the latest value of every signal received so far, keyed by signal name.
+func (s *canSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	readings := make(map[string]interface{}, len(s.values))
+	for name, v := range s.values {
+		readings[name] = v
+	}
+	return readings, nil
+}
+
+// Close stops listening to the bus.
+func (s *canSensor) Close() error {
+	s.cancel()
+	s.activeBackgroundWorkers.Wait()
+	return s.handle.Close()
+}
@@ -0,0 +1,145 @@
+package can
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+)
+
+func TestSignalDecode(t *testing.T) {
+	data := []byte{0x34, 0x12, 0xFE, 0xFF, 0x80, 0x01, 0x00, 0x00}
+
+	sig := SignalConfig{Name: "a", StartBit: 0, Length: 16}
+	v, err := sig.Decode(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0x1234)
+
+	sig = SignalConfig{Name: "b", StartBit: 16, Length: 16, Signed: true, Scale: 0.5, Offset: 10}
+	v, err = sig.Decode(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 9)
+
+	sig = SignalConfig{Name: "c", StartBit: 4, Length: 8}
+	v, err = sig.Decode(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0x23)
+
+	// Motorola: starts at the most significant bit of byte 4 and runs into byte 5
+	sig = SignalConfig{Name: "d", StartBit: 39, Length: 16, BigEndian: true}
+	v, err = sig.Decode(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, 0x8001)
+
+	sig = SignalConfig{Name: "e", StartBit: 3, Length: 8, BigEndian: true, Signed: true}
+	v, err = sig.Decode(data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, int8(0x41))
+
+	sig = SignalConfig{Name: "f", StartBit: 0, Length: 64, Signed: true}
+	v, err = sig.Decode([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v, test.ShouldEqual, -1)
+
+	sig = SignalConfig{Name: "g", StartBit: 8, Length: 16}
+	_, err = sig.Decode([]byte{1, 2})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs 3 bytes")
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := AttrConfig{}
+	_, err := conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"board" is required`)
+
+	conf.Board = "b"
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"can_bus" is required`)
+
+	conf.CANBus = "can0"
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"signals" is required`)
+
+	conf.Signals = []SignalConfig{{FrameID: 1, Length: 8}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"name" is required`)
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 0x800, Length: 8}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not fit in 11 bits")
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 1}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "length must be")
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 1, StartBit: 60, Length: 8}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not fit in a CAN frame")
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 1, StartBit: 59, Length: 16, BigEndian: true}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not fit in a CAN frame")
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 1, Length: 8}, {Name: "a", FrameID: 2, Length: 8}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "duplicate signal name")
+
+	conf.Signals = []SignalConfig{{Name: "a", FrameID: 1, Length: 8}, {Name: "b", FrameID: 0x12345, Extended: true, Length: 8}}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"b"})
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	bus := fakeboard.NewCAN()
+	b := &fakeboard.Board{CANs: map[string]*fakeboard.CAN{"can0": bus}}
+	attr := &AttrConfig{
+		Board:  "b",
+		CANBus: "can0",
+		Signals: []SignalConfig{
+			{Name: "voltage", FrameID: 0x100, StartBit: 0, Length: 16, Scale: 0.01},
+			{Name: "current", FrameID: 0x100, StartBit: 16, Length: 16, Signed: true, Scale: 0.1},
+			{Name: "soc", FrameID: 0x18FF0001, Extended: true, StartBit: 7, Length: 8, BigEndian: true},
+		},
+	}
+	_, err := newSensor(&fakeboard.Board{}, attr, "bms", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "can't find CAN bus")
+
+	s, err := newSensor(b, attr, "bms", golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.(*canSensor).Close(), test.ShouldBeNil)
+	}()
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldBeEmpty)
+
+	h, err := bus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, h.Close(), test.ShouldBeNil)
+	}()
+	// 0x100 as an extended id is a different frame and ignored
+	test.That(t, h.Send(ctx, board.CANFrame{ID: 0x100, Extended: true, Data: []byte{0xFF, 0xFF, 0xFF, 0xFF}}), test.ShouldBeNil)
+	test.That(t, h.Send(ctx, board.CANFrame{ID: 0x100, Data: []byte{0xE8, 0x03, 0x9C, 0xFF}}), test.ShouldBeNil)
+	test.That(t, h.Send(ctx, board.CANFrame{ID: 0x18FF0001, Extended: true, Data: []byte{87}}), test.ShouldBeNil)
+
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 100, func(tb testing.TB) {
+		tb.Helper()
+		readings, err := s.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, readings, test.ShouldHaveLength, 3)
+		test.That(tb, readings["voltage"], test.ShouldAlmostEqual, 10.0)
+		test.That(tb, readings["current"], test.ShouldAlmostEqual, -10.0)
+		test.That(tb, readings["soc"], test.ShouldEqual, 87.0)
+	})
+}
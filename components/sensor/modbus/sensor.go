@@ -0,0 +1,117 @@
+// Package modbus implements a sensor that reports values read from the registers of a Modbus device.
+package modbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edaniels/golog"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	mb "go.viam.com/rdk/components/board/modbus"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("modbus")
+
+// AttrConfig is used for converting config attributes. The connection attributes
+// are given alongside the register map.
+type AttrConfig struct {
+	mb.Config
+	Registers []mb.RegisterConfig `json:"registers"`
+}
+
+// Validate ensures all parts of the config are valid and returns the board it depends on, if any.
+func (conf *AttrConfig) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	if len(conf.Registers) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "registers")
+	}
+	names := map[string]bool{}
+	for idx, reg := range conf.Registers {
+		regPath := fmt.Sprintf("%s.%s.%d", path, "registers", idx)
+		if err := reg.Validate(regPath); err != nil {
+			return nil, err
+		}
+		if names[reg.Name] {
+			return nil, utils.NewConfigValidationError(regPath, errors.Errorf("duplicate register name %q", reg.Name))
+		}
+		names[reg.Name] = true
+	}
+	return conf.Config.Dependencies(), nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		sensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			attr, ok := config.ConvertedAttributes.(*AttrConfig)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attr, config.ConvertedAttributes)
+			}
+			return newSensor(deps, attr, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(sensor.Subtype, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: &conf})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(attributes); err != nil {
+				return nil, err
+			}
+			return &conf, nil
+		}, &AttrConfig{})
+}
+
+func newSensor(deps registry.Dependencies, attr *AttrConfig, logger golog.Logger) (sensor.Sensor, error) {
+	client, err := mb.NewClientFromDependencies(deps, attr.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &modbusSensor{client: client, registers: attr.Registers, logger: logger}, nil
+}
+
+// modbusSensor reports every configured register as a reading.
+type modbusSensor struct {
+	generic.Unimplemented
+	client    mb.Client
+	registers []mb.RegisterConfig
+	logger    golog.Logger
+}
+
+// Readings returns the current value of every register, keyed by register name.
+func (s *modbusSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings := make(map[string]interface{}, len(s.registers))
+	for i := range s.registers {
+		reg := &s.registers[i]
+		v, err := reg.Read(ctx, s.client)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read register %q", reg.Name)
+		}
+		readings[reg.Name] = v
+	}
+	return readings, nil
+}
+
+// Close releases the connection to the device.
+func (s *modbusSensor) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,98 @@
+package modbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	mb "go.viam.com/rdk/components/board/modbus"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+)
+
+func TestConfig(t *testing.T) {
+	var conv config.AttributeMapConverter
+	for _, reg := range config.RegisteredComponentAttributeMapConverters() {
+		if reg.Subtype == sensor.Subtype && reg.Model == modelname {
+			conv = reg.Conv
+		}
+	}
+	test.That(t, conv, test.ShouldNotBeNil)
+	attrs, err := conv(config.AttributeMap{
+		"protocol": "tcp",
+		"address":  "localhost:502",
+		"unit_id":  3,
+		"registers": []interface{}{
+			map[string]interface{}{"name": "temp", "table": "input", "address": 1, "type": "int16", "scale": 0.1},
+		},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	conf := attrs.(*AttrConfig)
+	test.That(t, conf.Protocol, test.ShouldEqual, "tcp")
+	test.That(t, conf.UnitID, test.ShouldEqual, 3)
+	test.That(t, conf.Registers, test.ShouldResemble, []mb.RegisterConfig{
+		{Name: "temp", Table: "input", Address: 1, Type: "int16", Scale: 0.1},
+	})
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	conf.Registers = nil
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"registers" is required`)
+
+	conf.Registers = []mb.RegisterConfig{{Name: "a", Table: "coil"}, {Name: "a", Table: "input"}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "path.registers.1")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "duplicate")
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	server, err := mb.NewServer("localhost:0", logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, server.Close(), test.ShouldBeNil)
+	}()
+
+	server.SetInputRegister(1, 0xff38) // -200
+	server.SetHoldingRegister(10, 0x0001)
+	server.SetHoldingRegister(11, 0x86a0) // 100000
+	server.SetDiscreteInput(4, true)
+
+	reg := registry.ComponentLookup(sensor.Subtype, modelname)
+	test.That(t, reg, test.ShouldNotBeNil)
+	res, err := reg.Constructor(ctx, nil, config.Component{
+		Name: "vfd",
+		ConvertedAttributes: &AttrConfig{
+			Config: mb.Config{Protocol: mb.ProtocolTCP, Address: server.Addr()},
+			Registers: []mb.RegisterConfig{
+				{Name: "temperature_celsius", Table: mb.TableInputRegister, Address: 1, Type: mb.TypeInt16, Scale: 0.1},
+				{Name: "energy_wh", Table: mb.TableHoldingRegister, Address: 10, Type: mb.TypeUint32},
+				{Name: "fault", Table: mb.TableDiscreteInput, Address: 4},
+			},
+		},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	s := res.(sensor.Sensor)
+	defer func() {
+		test.That(t, s.(*modbusSensor).Close(), test.ShouldBeNil)
+	}()
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temperature_celsius"], test.ShouldAlmostEqual, -20.0)
+	test.That(t, readings["energy_wh"], test.ShouldEqual, 100000.0)
+	test.That(t, readings["fault"], test.ShouldEqual, true)
+
+	server.SetDiscreteInput(4, false)
+	readings, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["fault"], test.ShouldEqual, false)
+}
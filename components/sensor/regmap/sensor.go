@@ -0,0 +1,98 @@
+// Package regmap implements a sensor that reports the values of an I2C or SPI chip described by
+// a map of its registers.
+package regmap
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/mitchellh/mapstructure"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/regmap"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var modelname = resource.NewDefaultModel("register-map")
+
+// AttrConfig is used for converting config attributes. The register map is given directly in
+// the attributes.
+type AttrConfig struct {
+	regmap.Config
+}
+
+// Validate ensures all parts of the config are valid and returns the board it depends on.
+func (conf *AttrConfig) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	return []string{conf.Board}, nil
+}
+
+func init() {
+	registry.RegisterComponent(
+		sensor.Subtype,
+		modelname,
+		registry.Component{Constructor: func(
+			ctx context.Context,
+			deps registry.Dependencies,
+			config config.Component,
+			logger golog.Logger,
+		) (interface{}, error) {
+			attr, ok := config.ConvertedAttributes.(*AttrConfig)
+			if !ok {
+				return nil, rdkutils.NewUnexpectedTypeError(attr, config.ConvertedAttributes)
+			}
+			b, err := board.FromDependencies(deps, attr.Board)
+			if err != nil {
+				return nil, err
+			}
+			return newSensor(ctx, b, attr, logger)
+		}})
+
+	config.RegisterComponentAttributeMapConverter(sensor.Subtype, modelname,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf AttrConfig
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: &conf})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(attributes); err != nil {
+				return nil, err
+			}
+			return &conf, nil
+		}, &AttrConfig{})
+}
+
+func newSensor(ctx context.Context, b board.Board, attr *AttrConfig, logger golog.Logger) (sensor.Sensor, error) {
+	device, err := regmap.NewDevice(ctx, b, &attr.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &regmapSensor{device: device, logger: logger}, nil
+}
+
+// regmapSensor reports every field and derived value as a reading.
+type regmapSensor struct {
+	generic.Unimplemented
+	device *regmap.Device
+	logger golog.Logger
+}
+
+// Readings returns the current value of every field and derived value, keyed by name.
+func (s *regmapSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	values, err := s.device.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readings := make(map[string]interface{}, len(values))
+	for name, v := range values {
+		readings[name] = v
+	}
+	return readings, nil
+}
@@ -0,0 +1,71 @@
+package regmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/simdevice"
+)
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	// an SHT3x style chip: a command starts a measurement, then temperature and humidity are read
+	bus := simdevice.NewI2CBus()
+	chip := &simdevice.Registers{}
+	bus.Attach(0x44, chip)
+	chip.Set(0x00, 0x66, 0x66, 0x00, 0x80, 0x00)
+	b := &inject.Board{I2CByNameFunc: func(name string) (board.I2C, bool) { return bus, true }}
+
+	var conv config.AttributeMapConverter
+	for _, reg := range config.RegisteredComponentAttributeMapConverters() {
+		if reg.Subtype == sensor.Subtype && reg.Model == modelname {
+			conv = reg.Conv
+		}
+	}
+	test.That(t, conv, test.ShouldNotBeNil)
+	attrs, err := conv(config.AttributeMap{
+		"board":    "b",
+		"i2c_bus":  "main",
+		"i2c_addr": 0x44,
+		"init":     []interface{}{map[string]interface{}{"register": 0x24, "values": []interface{}{0x00}}},
+		"fields": []interface{}{
+			map[string]interface{}{"name": "temp_raw", "register": 0x00, "length": 2},
+			map[string]interface{}{"name": "humidity", "register": 0x03, "length": 2, "scale": 100.0 / 65535},
+		},
+		"derived": []interface{}{
+			map[string]interface{}{"name": "temp", "formula": "(- (/ (* temp_raw 175) 65535) 45)"},
+		},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	attr := attrs.(*AttrConfig)
+	test.That(t, attr.I2CAddr, test.ShouldEqual, 0x44)
+	deps, err := attr.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"b"})
+
+	s, err := newSensor(ctx, b, attr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bus.Transactions(), test.ShouldResemble, []simdevice.I2CTransaction{
+		{Addr: 0x44, Write: true, Data: []byte{0x24, 0x00}},
+	})
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temp_raw"], test.ShouldEqual, 0x6666)
+	test.That(t, readings["temp"], test.ShouldAlmostEqual, 25, 0.01)
+	test.That(t, readings["humidity"], test.ShouldAlmostEqual, 50, 0.01)
+
+	attr.Fields = nil
+	_, err = attr.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"fields" is required`)
+}
@@ -86,6 +86,20 @@ var (
 	_ = resource.Reconfigurable(&reconfigurableLocalServo{})
 )
 
+// FromDependencies is a helper for getting the named servo from a collection of
+// dependencies.
+func FromDependencies(deps registry.Dependencies, name string) (Servo, error) {
+	res, ok := deps[Named(name)]
+	if !ok {
+		return nil, utils.DependencyNotFoundError(name)
+	}
+	part, ok := res.(Servo)
+	if !ok {
+		return nil, DependencyTypeError(name, res)
+	}
+	return part, nil
+}
+
 // NewUnimplementedInterfaceError is used when there is a failed interface check.
 func NewUnimplementedInterfaceError(actual interface{}) error {
 	return utils.NewUnimplementedInterfaceError((*Servo)(nil), actual)
@@ -96,6 +110,11 @@ func NewUnimplementedLocalInterfaceError(actual interface{}) error {
 	return utils.NewUnimplementedInterfaceError((*LocalServo)(nil), actual)
 }
 
+// DependencyTypeError is used when a resource doesn't implement the expected interface.
+func DependencyTypeError(name string, actual interface{}) error {
+	return utils.DependencyTypeError(name, (*Servo)(nil), actual)
+}
+
 // FromRobot is a helper for getting the named servo from the given Robot.
 func FromRobot(r robot.Robot, name string) (Servo, error) {
 	return robot.ResourceFromRobot[Servo](r, Named(name))
@@ -468,6 +468,9 @@ type NetworkConfigData struct {
 
 	// Sessions configures session management.
 	Sessions SessionsConfig `json:"sessions"`
+
+	// CameraStreams configures re-streaming cameras over RTSP and MJPEG.
+	CameraStreams CameraStreamsConfig `json:"camera_streams"`
 }
 
 // MarshalJSON marshals out this config.
@@ -500,7 +503,10 @@ func (nc *NetworkConfig) Validate(path string) error {
 		return utils.NewConfigValidationError(path, errors.New("must provide both tls_cert_file and tls_key_file"))
 	}
 
-	return nc.Sessions.Validate(path + ".sessions")
+	if err := nc.Sessions.Validate(path + ".sessions"); err != nil {
+		return err
+	}
+	return nc.CameraStreams.Validate(path + ".camera_streams")
 }
 
 // SessionsConfig configures various parameters used in session management.
@@ -556,6 +562,62 @@ func (sc *SessionsConfig) Validate(path string) error {
 	return nil
 }
 
+// CameraStreamsConfig configures publishing cameras for clients that cannot use WebRTC, such as
+// NVRs. Clients authenticate with HTTP basic auth using any username and one of Passwords as the
+// password. The robot's own keys and secrets are never accepted, since RTSP sends the password in
+// the clear and NVRs store it in their configs.
+type CameraStreamsConfig struct {
+	// Cameras are the names of the cameras to publish.
+	Cameras []string `json:"cameras"`
+
+	// MJPEG serves each camera as motion JPEG over HTTP at /stream/mjpeg/<name> on the web server.
+	MJPEG bool `json:"mjpeg"`
+
+	// RTSPBindAddress, if set, is the address an RTSP server publishing each camera at
+	// rtsp://<address>/<name> listens on. Only TCP transport is offered.
+	RTSPBindAddress string `json:"rtsp_bind_address"`
+
+	// FPS is the highest rate frames are published at, DefaultCameraStreamFPS if not set.
+	FPS float64 `json:"fps"`
+
+	// Passwords are the stream-only passwords clients may watch with.
+	Passwords []string `json:"passwords"`
+
+	// Unauthenticated lets anyone who can reach the robot watch without a password. It must be set
+	// explicitly when there are no Passwords.
+	Unauthenticated bool `json:"unauthenticated"`
+}
+
+// DefaultCameraStreamFPS is the rate camera streams are published at when not specified.
+const DefaultCameraStreamFPS = 10
+
+// Validate ensures all parts of the config are valid.
+func (csc *CameraStreamsConfig) Validate(path string) error {
+	if csc.RTSPBindAddress != "" {
+		if _, _, err := net.SplitHostPort(csc.RTSPBindAddress); err != nil {
+			return utils.NewConfigValidationError(path, errors.Wrap(err, "error validating rtsp_bind_address"))
+		}
+	}
+	if csc.FPS < 0 {
+		return utils.NewConfigValidationError(path, errors.New("fps cannot be negative"))
+	}
+	for idx, password := range csc.Passwords {
+		if password == "" {
+			return utils.NewConfigValidationError(path, errors.Errorf("passwords.%d cannot be empty", idx))
+		}
+	}
+	if len(csc.Cameras) == 0 || (!csc.MJPEG && csc.RTSPBindAddress == "") {
+		return nil
+	}
+	if len(csc.Passwords) == 0 && !csc.Unauthenticated {
+		return utils.NewConfigValidationError(path, errors.New("must set passwords, or unauthenticated to publish without them"))
+	}
+	if len(csc.Passwords) != 0 && csc.Unauthenticated {
+		return utils.NewConfigValidationError(path, errors.New("may only set one of passwords or unauthenticated"))
+	}
+	return nil
+}
+
 // AuthConfig describes authentication and authorization settings for the web server.
 type AuthConfig struct {
 	Handlers        []AuthHandlerConfig `json:"handlers"`
@@ -936,6 +936,33 @@ func keysetToAttributeMap(t *testing.T, keyset jwks.KeySet) config.AttributeMap
 	return jwksAsInterface
 }
 
+func TestCameraStreamsConfigValidate(t *testing.T) {
+	conf := config.CameraStreamsConfig{Cameras: []string{"camera1"}, MJPEG: true}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must set passwords")
+
+	conf.Unauthenticated = true
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Passwords = []string{"streamsecret"}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "only set one of")
+
+	conf.Unauthenticated = false
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Passwords = []string{""}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "passwords.0")
+
+	// nothing is published without cameras
+	empty := config.CameraStreamsConfig{MJPEG: true}
+	test.That(t, empty.Validate("path"), test.ShouldBeNil)
+}
+
 func TestGetPackageReference(t *testing.T) {
 	t.Run("non reference", func(t *testing.T) {
 		test.That(t, config.GetPackageReference("/a/path"), test.ShouldBeNil)
@@ -1,10 +1,12 @@
 package motionplan
 
 import (
+	"context"
 	"errors"
 	"math"
 	"sort"
 
+	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/referenceframe"
 )
 
@@ -36,6 +38,16 @@ func NewDubins(radius, pointSeparation float64) (*Dubins, error) {
 	return dubins, nil
 }
 
+// DubinsFromBase returns the Dubins parameters for planning paths that the base is able to drive,
+// with points on the paths pointSeparationMM apart.
+func DubinsFromBase(ctx context.Context, b base.Base, pointSeparationMM float64) (*Dubins, error) {
+	constraints, err := base.KinematicConstraintsFromBase(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	return NewDubins(constraints.MinTurningRadiusMM, pointSeparationMM)
+}
+
 func (d *Dubins) findCenter(point []float64, isLeft bool) []float64 {
 	angle := point[2]
 	if isLeft {
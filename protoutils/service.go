@@ -0,0 +1,94 @@
+package protoutils
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MethodDescription describes a method of a gRPC service that has no generated code.
+type MethodDescription struct {
+	Name string
+	// Input and Output are the request and response messages, google.protobuf.Struct if nil.
+	Input         proto.Message
+	Output        proto.Message
+	ServerStreams bool
+}
+
+var serviceDescriptorMu sync.Mutex
+
+// RegisterServiceDescriptor describes a gRPC service that go.viam.com/api has no generated code
+// for in the global proto registry, as the file fileName, so that reflection, remotes and
+// modules can treat it like a generated service. serviceName is the full name of the service,
+// like "viam.service.mlmodel.v1.MLModelService". Registering a file that is already registered
+// does nothing.
+func RegisterServiceDescriptor(fileName, serviceName string, methods []MethodDescription) error {
+	serviceDescriptorMu.Lock()
+	defer serviceDescriptorMu.Unlock()
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(fileName); err == nil {
+		return nil
+	}
+	dot := strings.LastIndex(serviceName, ".")
+	if dot <= 0 {
+		return errors.Errorf("service name %q has no package", serviceName)
+	}
+	// the registry panics on conflicting names, so they are checked for first
+	if desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName)); err == nil {
+		return errors.Errorf("service %s is already described by %s", serviceName, desc.ParentFile().Path())
+	}
+
+	var dependencies []string
+	messageType := func(msg proto.Message) string {
+		if msg == nil {
+			msg = &structpb.Struct{}
+		}
+		desc := msg.ProtoReflect().Descriptor()
+		path := desc.ParentFile().Path()
+		known := false
+		for _, dep := range dependencies {
+			known = known || dep == path
+		}
+		if !known {
+			dependencies = append(dependencies, path)
+		}
+		return "." + string(desc.FullName())
+	}
+	methodProtos := make([]*descriptorpb.MethodDescriptorProto, 0, len(methods))
+	for _, method := range methods {
+		methodProto := &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(method.Name),
+			InputType:  proto.String(messageType(method.Input)),
+			OutputType: proto.String(messageType(method.Output)),
+		}
+		if method.ServerStreams {
+			methodProto.ServerStreaming = proto.Bool(true)
+		}
+		methodProtos = append(methodProtos, methodProto)
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String(fileName),
+		Package:    proto.String(serviceName[:dot]),
+		Dependency: dependencies,
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String(serviceName[dot+1:]),
+			Method: methodProtos,
+		}},
+		Syntax: proto.String("proto3"),
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		return errors.Wrapf(err, "failed to describe service %s", serviceName)
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		return errors.Wrapf(err, "failed to register service %s", serviceName)
+	}
+	return nil
+}
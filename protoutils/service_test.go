@@ -0,0 +1,35 @@
+package protoutils
+
+import (
+	"testing"
+
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestRegisterServiceDescriptor(t *testing.T) {
+	err := RegisterServiceDescriptor("test/v1/bad.proto", "NoPackage", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "has no package")
+
+	methods := []MethodDescription{
+		{Name: "Get"},
+		{Name: "Watch", ServerStreams: true},
+		{Name: "DoCommand", Input: &commonpb.DoCommandRequest{}, Output: &commonpb.DoCommandResponse{}},
+	}
+	test.That(t, RegisterServiceDescriptor("test/v1/test.proto", "viam.test.v1.TestService", methods), test.ShouldBeNil)
+	// registering it again does nothing, instead of clashing with the first registration
+	test.That(t, RegisterServiceDescriptor("test/v1/test.proto", "viam.test.v1.TestService", methods), test.ShouldBeNil)
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName("viam.test.v1.TestService.Watch")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, desc.FullName(), test.ShouldEqual, "viam.test.v1.TestService.Watch")
+	desc, err = protoregistry.GlobalFiles.FindDescriptorByName("viam.test.v1.TestService")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, desc.ParentFile().Imports().Len(), test.ShouldEqual, 2)
+
+	// a different file can't describe a service that is already described
+	err = RegisterServiceDescriptor("test/v1/other.proto", "viam.test.v1.TestService", methods)
+	test.That(t, err, test.ShouldNotBeNil)
+}
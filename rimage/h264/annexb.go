@@ -0,0 +1,121 @@
+package h264
+
+import (
+	"bufio"
+	"io"
+
+	h264codec "github.com/aler9/gortsplib/v2/pkg/codecs/h264"
+	"github.com/pkg/errors"
+)
+
+// AccessUnitReader splits an Annex-B byte stream, like the raw H.264 that ffmpeg writes, into
+// access units.
+type AccessUnitReader struct {
+	r *bufio.Reader
+	// inNALU is set once a start code has been read, so that the bytes after it are a NALU.
+	inNALU bool
+	au     [][]byte
+	// sawSlice is set once the access unit being read has a slice, after which the next one may start.
+	sawSlice bool
+}
+
+// NewAccessUnitReader returns a reader of the access units in the byte stream.
+func NewAccessUnitReader(r io.Reader) *AccessUnitReader {
+	return &AccessUnitReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the NALUs of the next access unit. An access unit is only known to be over once the
+// first NALU of the one after it is read, or the stream ends.
+func (ar *AccessUnitReader) Next() ([][]byte, error) {
+	for {
+		nalu, err := ar.nextNALU()
+		if errors.Is(err, io.EOF) && len(ar.au) != 0 {
+			au := ar.au
+			ar.au = nil
+			ar.sawSlice = false
+			return au, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(nalu) == 0 {
+			continue
+		}
+		if ar.sawSlice && startsAccessUnit(nalu) {
+			au := ar.au
+			ar.au = [][]byte{nalu}
+			ar.sawSlice = isSlice(nalu)
+			return au, nil
+		}
+		ar.au = append(ar.au, nalu)
+		ar.sawSlice = ar.sawSlice || isSlice(nalu)
+	}
+}
+
+// nextNALU reads up to the next start code, or the end of the stream, and returns the NALU before it.
+func (ar *AccessUnitReader) nextNALU() ([]byte, error) {
+	var nalu []byte
+	var zeros int
+	for {
+		b, err := ar.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) && ar.inNALU {
+				ar.inNALU = false
+				return trimZeros(nalu), nil
+			}
+			return nil, err
+		}
+		if b == 1 && zeros >= 2 {
+			// a start code, whose zeros, and any trailing ones before it, are not part of the NALU
+			wasInNALU := ar.inNALU
+			ar.inNALU = true
+			if wasInNALU {
+				return trimZeros(nalu), nil
+			}
+			nalu, zeros = nalu[:0], 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		if ar.inNALU {
+			nalu = append(nalu, b)
+		}
+	}
+}
+
+func trimZeros(nalu []byte) []byte {
+	for len(nalu) != 0 && nalu[len(nalu)-1] == 0 {
+		nalu = nalu[:len(nalu)-1]
+	}
+	return nalu
+}
+
+func isSlice(nalu []byte) bool {
+	typ := h264codec.NALUType(nalu[0] & 0x1F)
+	return typ == h264codec.NALUTypeNonIDR || typ == h264codec.NALUTypeIDR
+}
+
+// startsAccessUnit returns whether the NALU, coming after a slice, is the first of a new access
+// unit. Slices start one when they are the first slice of a picture, whose first macroblock is 0;
+// it is coded as a single 1 bit.
+func startsAccessUnit(nalu []byte) bool {
+	switch h264codec.NALUType(nalu[0] & 0x1F) {
+	case h264codec.NALUTypeAccessUnitDelimiter,
+		h264codec.NALUTypeSPS,
+		h264codec.NALUTypePPS,
+		h264codec.NALUTypeSEI,
+		h264codec.NALUTypePrefix,
+		h264codec.NALUTypeSubsetSPS,
+		h264codec.NALUTypeReserved16,
+		h264codec.NALUTypeReserved17,
+		h264codec.NALUTypeReserved18:
+		return true
+	case h264codec.NALUTypeNonIDR, h264codec.NALUTypeIDR:
+		return len(nalu) > 1 && nalu[1]&0x80 != 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,43 @@
+package h264
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestAccessUnitReader(t *testing.T) {
+	// a second slice of the same picture, whose first macroblock is not 0
+	secondSlice := []byte{0x41, 0x20, 0x01}
+	sei := []byte{0x06, 0x05, 0x01}
+
+	var stream bytes.Buffer
+	// start codes may have three or four bytes
+	for _, nalu := range [][]byte{testSPS, testPPS, sei, testIDR(0)} {
+		stream.Write([]byte{0x00, 0x00, 0x00, 0x01})
+		stream.Write(nalu)
+	}
+	for _, nalu := range [][]byte{testNonIDR(1), secondSlice, testAUD, testNonIDR(2)} {
+		stream.Write([]byte{0x00, 0x00, 0x01})
+		stream.Write(nalu)
+	}
+	// trailing zeros before a start code are not part of the NALU
+	stream.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x01})
+	stream.Write(testNonIDR(3))
+
+	reader := NewAccessUnitReader(&stream)
+	for _, expected := range [][][]byte{
+		{testSPS, testPPS, sei, testIDR(0)},
+		{testNonIDR(1), secondSlice},
+		{testAUD, testNonIDR(2)},
+		{testNonIDR(3)},
+	} {
+		au, err := reader.Next()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, au, test.ShouldResemble, expected)
+	}
+	_, err := reader.Next()
+	test.That(t, err, test.ShouldEqual, io.EOF)
+}
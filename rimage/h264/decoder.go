@@ -0,0 +1,219 @@
+package h264
+
+import (
+	"image"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"go.viam.com/utils"
+)
+
+// frameDecodeTimeout is how long the decoder may take to put out a frame before it is stopped.
+const frameDecodeTimeout = 5 * time.Second
+
+// accessUnitDelimiter is written after the access units given to the decoder. A decoder of an
+// Annex-B byte stream only knows an access unit is over once the next one starts, so without it
+// each frame would only come out once the next one is given.
+var accessUnitDelimiter = []byte{0x00, 0x00, 0x00, 0x01, 0x09, 0xf0}
+
+// A decoder decodes the frames of a sequence with one long-lived decoder process that is given the
+// access units as their frames are needed. Frames read in order, like the latest frame of a camera,
+// are then each decoded once, instead of decoding their group of pictures up to them every time.
+type decoder struct {
+	mu     sync.Mutex
+	closed bool
+	proc   *decoderProcess
+	bounds image.Rectangle
+	// last is the last frame given to proc.
+	last *Frame
+}
+
+// decode returns the image of the frame, giving the decoder what it needs to show it since the
+// last frame it was given.
+func (d *decoder) decode(f *Frame) (image.Image, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		proc, err := newDecoderProcess()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			utils.UncheckedError(proc.stop())
+		}()
+		return proc.decode(nil, f)
+	}
+
+	// a decoder puts out frames of the size it started with, so it starts over when the size changes
+	if d.proc != nil && d.bounds != f.Bounds() {
+		d.stopProcess()
+	}
+	if d.proc == nil {
+		proc, err := newDecoderProcess()
+		if err != nil {
+			return nil, err
+		}
+		d.proc = proc
+		d.bounds = f.Bounds()
+	}
+	img, err := d.proc.decode(d.last, f)
+	if err != nil {
+		d.stopProcess()
+		return nil, err
+	}
+	d.last = f
+	return img, nil
+}
+
+func (d *decoder) stopProcess() {
+	utils.UncheckedError(d.proc.stop())
+	d.proc = nil
+	d.last = nil
+}
+
+func (d *decoder) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.proc == nil {
+		return nil
+	}
+	err := d.proc.stop()
+	d.proc = nil
+	d.last = nil
+	return err
+}
+
+// A decoderProcess decodes an Annex-B byte stream written to in into raw RGBA frames read from out.
+type decoderProcess struct {
+	in   io.Writer
+	out  io.Reader
+	stop func() error
+}
+
+// newDecoderProcess starts a decoder process. It is a variable so that tests can run without ffmpeg.
+var newDecoderProcess = newFFmpegDecoderProcess
+
+// newFFmpegDecoderProcess starts ffmpeg decoding with as little delay as it can, so that each frame
+// comes out as soon as its access unit is in.
+func newFFmpegDecoderProcess() (*decoderProcess, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, errors.Wrap(err, "decoding h264 needs ffmpeg")
+	}
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	stderr := &tailWriter{}
+	cmd := ffmpeg.Input("pipe:", ffmpeg.KwArgs{
+		"format":          "h264",
+		"flags":           "low_delay",
+		"threads":         1,
+		"probesize":       32,
+		"analyzeduration": 0,
+	}).
+		Output("pipe:", ffmpeg.KwArgs{
+			"vsync":         "passthrough",
+			"format":        "rawvideo",
+			"pix_fmt":       "rgba",
+			"flush_packets": 1,
+		}).
+		GlobalArgs("-loglevel", "error").
+		WithInput(inReader).
+		WithOutput(outWriter).
+		WithErrorOutput(stderr).
+		Compile()
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "error starting h264 decoder")
+	}
+	exited := make(chan error, 1)
+	utils.PanicCapturingGo(func() {
+		err := cmd.Wait()
+		if err == nil {
+			err = io.EOF
+		} else {
+			err = errors.Wrapf(err, "h264 decoder exited: %s", stderr.String())
+		}
+		utils.UncheckedError(outWriter.CloseWithError(err))
+		exited <- err
+	})
+
+	var stopOnce sync.Once
+	return &decoderProcess{
+		in:  inWriter,
+		out: outReader,
+		stop: func() error {
+			stopOnce.Do(func() {
+				// the process only stops reading its input once the pipe is closed
+				utils.UncheckedError(inWriter.Close())
+				utils.UncheckedError(cmd.Process.Kill())
+				<-exited
+			})
+			return nil
+		},
+	}, nil
+}
+
+// decode gives the process the access units after prev up to the frame, or the frame's group of
+// pictures up to it if prev is not an earlier frame of the group, and returns the last frame out.
+func (p *decoderProcess) decode(prev, f *Frame) (image.Image, error) {
+	from := 0
+	if prev != nil && prev.gop == f.gop && prev.index < f.index {
+		from = prev.index + 1
+	}
+	data, err := f.gop.annexB(from, f.index)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, accessUnitDelimiter...)
+
+	// frames are read while the access units are written, so that neither side waits on the other
+	written := make(chan error, 1)
+	utils.PanicCapturingGo(func() {
+		_, err := p.in.Write(data)
+		written <- err
+	})
+	img := image.NewRGBA(f.Bounds())
+	for i := from; i <= f.index; i++ {
+		timeout := time.AfterFunc(frameDecodeTimeout, func() {
+			utils.UncheckedError(p.stop())
+		})
+		_, err := io.ReadFull(p.out, img.Pix)
+		timeout.Stop()
+		if err != nil {
+			utils.UncheckedError(p.stop())
+			<-written
+			return nil, errors.Wrap(err, "error decoding h264 frame")
+		}
+	}
+	if err := <-written; err != nil {
+		return nil, errors.Wrap(err, "error writing to h264 decoder")
+	}
+	return img, nil
+}
+
+// tailWriter keeps the end of what is written to it, for errors of long-lived processes.
+type tailWriter struct {
+	mu   sync.Mutex
+	tail []byte
+}
+
+const maxTailLength = 1024
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > maxTailLength {
+		w.tail = append([]byte(nil), w.tail[len(w.tail)-maxTailLength:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.tail)
+}
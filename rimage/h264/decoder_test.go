@@ -0,0 +1,167 @@
+package h264
+
+import (
+	"bufio"
+	"bytes"
+	"image/color"
+	"io"
+	"sync"
+	"testing"
+
+	h264codec "github.com/aler9/gortsplib/v2/pkg/codecs/h264"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+)
+
+// fakeDecoder decodes the test NALUs into frames filled with their byte, putting out each frame once
+// the next access unit starts, like ffmpeg does.
+type fakeDecoder struct {
+	mu        sync.Mutex
+	processes int
+	running   int
+	frames    int
+}
+
+func (fd *fakeDecoder) start(t *testing.T, size int) {
+	t.Helper()
+	prev := newDecoderProcess
+	newDecoderProcess = func() (*decoderProcess, error) {
+		fd.mu.Lock()
+		fd.processes++
+		fd.running++
+		fd.mu.Unlock()
+		inReader, inWriter := io.Pipe()
+		outReader, outWriter := io.Pipe()
+		exited := make(chan struct{})
+		utils.PanicCapturingGo(func() {
+			defer close(exited)
+			utils.UncheckedError(outWriter.CloseWithError(fd.run(inReader, outWriter, size)))
+		})
+		var stopOnce sync.Once
+		return &decoderProcess{
+			in:  inWriter,
+			out: outReader,
+			stop: func() error {
+				stopOnce.Do(func() {
+					utils.UncheckedError(inWriter.Close())
+					utils.UncheckedError(outReader.Close())
+					<-exited
+					fd.mu.Lock()
+					fd.running--
+					fd.mu.Unlock()
+				})
+				return nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() {
+		newDecoderProcess = prev
+	})
+}
+
+func (fd *fakeDecoder) run(in io.Reader, out io.Writer, size int) error {
+	r := bufio.NewReader(in)
+	var zeros int
+	var naluType h264codec.NALUType
+	// pos is the position in the NALU, counting the header as 0
+	pos := -1
+	var fill byte
+	var pending bool
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == 1 && zeros >= 2 {
+			zeros = 0
+			pos = -1
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		pos++
+		if pos == 0 {
+			naluType = h264codec.NALUType(b & 0x1F)
+			// each test access unit has one slice, so the next slice starts a new one too
+			if pending && (naluType == h264codec.NALUTypeAccessUnitDelimiter || isSlice([]byte{b})) {
+				fd.mu.Lock()
+				fd.frames++
+				fd.mu.Unlock()
+				if _, err := out.Write(bytes.Repeat([]byte{fill}, size)); err != nil {
+					return err
+				}
+				pending = false
+			}
+			continue
+		}
+		if isSlice([]byte{byte(naluType)}) && pos == 2 {
+			fill = b
+			pending = true
+		}
+	}
+}
+
+func (fd *fakeDecoder) counts() (processes, running, frames int) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	return fd.processes, fd.running, fd.frames
+}
+
+func TestDecoder(t *testing.T) {
+	var fd fakeDecoder
+	fd.start(t, 352*288*4)
+
+	var seq Sequence
+	frame1, err := seq.Add([][]byte{testSPS, testPPS, testIDR(1)})
+	test.That(t, err, test.ShouldBeNil)
+	frame2, err := seq.Add([][]byte{testNonIDR(2)})
+	test.That(t, err, test.ShouldBeNil)
+	frame3, err := seq.Add([][]byte{testNonIDR(3)})
+	test.That(t, err, test.ShouldBeNil)
+
+	// the first frame decoded needs its group of pictures up to it
+	img, err := frame2.Decode()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.At(1, 1), test.ShouldResemble, color.RGBA{2, 2, 2, 2})
+	processes, _, frames := fd.counts()
+	test.That(t, processes, test.ShouldEqual, 1)
+	test.That(t, frames, test.ShouldEqual, 2)
+
+	// later frames only need what came since, and are decoded once
+	test.That(t, frame3.At(1, 1), test.ShouldResemble, color.RGBA{3, 3, 3, 3})
+	test.That(t, frame3.At(2, 2), test.ShouldResemble, color.RGBA{3, 3, 3, 3})
+	processes, _, frames = fd.counts()
+	test.That(t, processes, test.ShouldEqual, 1)
+	test.That(t, frames, test.ShouldEqual, 3)
+
+	// an earlier frame is decoded from the start of its group again
+	test.That(t, frame1.At(1, 1), test.ShouldResemble, color.RGBA{1, 1, 1, 1})
+	_, _, frames = fd.counts()
+	test.That(t, frames, test.ShouldEqual, 4)
+
+	// a new group of pictures starts at its IDR frame on the same decoder
+	frame4, err := seq.Add([][]byte{testIDR(4)})
+	test.That(t, err, test.ShouldBeNil)
+	frame5, err := seq.Add([][]byte{testNonIDR(5)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame4.At(1, 1), test.ShouldResemble, color.RGBA{4, 4, 4, 4})
+	test.That(t, frame5.At(1, 1), test.ShouldResemble, color.RGBA{5, 5, 5, 5})
+	processes, running, frames := fd.counts()
+	test.That(t, processes, test.ShouldEqual, 1)
+	test.That(t, running, test.ShouldEqual, 1)
+	test.That(t, frames, test.ShouldEqual, 6)
+
+	// once the sequence is closed, frames are decoded on their own
+	frame6, err := seq.Add([][]byte{testNonIDR(6)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, seq.Close(), test.ShouldBeNil)
+	_, running, _ = fd.counts()
+	test.That(t, running, test.ShouldEqual, 0)
+	test.That(t, frame6.At(1, 1), test.ShouldResemble, color.RGBA{6, 6, 6, 6})
+	processes, running, _ = fd.counts()
+	test.That(t, processes, test.ShouldEqual, 2)
+	test.That(t, running, test.ShouldEqual, 0)
+}
@@ -0,0 +1,185 @@
+// Package h264 keeps H.264 video from cameras that already encode it as it arrives, so that it can
+// be streamed without being re-encoded and is only decoded when its pixels are needed.
+package h264
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	h264codec "github.com/aler9/gortsplib/v2/pkg/codecs/h264"
+	"github.com/pkg/errors"
+)
+
+// MIMEType is the MIME type of H.264 video, as used by WebRTC tracks.
+const MIMEType = "video/H264"
+
+// maxGOPLength bounds how many access units are kept for a group of pictures, in case a camera
+// rarely sends IDR frames. Frames past it are dropped until the next IDR frame.
+const maxGOPLength = 1000
+
+// A Sequence groups the access units of a video into frames. Every frame keeps the access units
+// of its group of pictures, which starts at an IDR frame, since they are all needed to decode it.
+// A Sequence is not safe for concurrent use, but the frames it returns are.
+type Sequence struct {
+	sps, pps      []byte
+	width, height int
+	gop           *gop
+	dec           *decoder
+}
+
+// Close stops the decoder of the sequence. Frames of the sequence that are decoded after it are
+// each decoded on their own.
+func (s *Sequence) Close() error {
+	if s.dec == nil {
+		return nil
+	}
+	return s.dec.close()
+}
+
+// Add adds the next access unit, given as NALUs without start codes, to the video and returns its
+// frame. Parameter sets may come on their own or with the access units, and no frame is returned
+// until the first IDR frame after them.
+func (s *Sequence) Add(au [][]byte) (*Frame, error) {
+	var idr bool
+	var nalus [][]byte
+	for _, nalu := range au {
+		if len(nalu) == 0 {
+			continue
+		}
+		switch h264codec.NALUType(nalu[0] & 0x1F) {
+		case h264codec.NALUTypeSPS:
+			var sps h264codec.SPS
+			if err := sps.Unmarshal(nalu); err != nil {
+				return nil, errors.Wrap(err, "invalid SPS")
+			}
+			s.sps = append([]byte(nil), nalu...)
+			s.width, s.height = sps.Width(), sps.Height()
+			continue
+		case h264codec.NALUTypePPS:
+			s.pps = append([]byte(nil), nalu...)
+			continue
+		case h264codec.NALUTypeAccessUnitDelimiter:
+			continue
+		case h264codec.NALUTypeIDR:
+			idr = true
+		default:
+		}
+		nalus = append(nalus, append([]byte(nil), nalu...))
+	}
+	if len(nalus) == 0 {
+		return nil, nil
+	}
+	if idr {
+		if s.sps == nil || s.pps == nil {
+			return nil, errors.New("got an IDR frame before the SPS and PPS")
+		}
+		if s.dec == nil {
+			s.dec = &decoder{}
+		}
+		s.gop = &gop{sps: s.sps, pps: s.pps, width: s.width, height: s.height, dec: s.dec}
+	}
+	if s.gop == nil {
+		return nil, nil
+	}
+	return s.gop.add(nalus), nil
+}
+
+// gop is a group of pictures, the access units from an IDR frame up to the next one, stored
+// without parameter sets.
+type gop struct {
+	sps, pps      []byte
+	width, height int
+	dec           *decoder
+
+	mu  sync.Mutex
+	aus [][][]byte
+}
+
+func (g *gop) add(au [][]byte) *Frame {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.aus) >= maxGOPLength {
+		return nil
+	}
+	g.aus = append(g.aus, au)
+	return &Frame{gop: g, index: len(g.aus) - 1}
+}
+
+// annexB returns the access units of the group from the given index up to and including the one at
+// to as an Annex-B byte stream, starting with the parameter sets if it starts at the IDR frame.
+func (g *gop) annexB(from, to int) ([]byte, error) {
+	g.mu.Lock()
+	aus := g.aus[from : to+1]
+	g.mu.Unlock()
+
+	var nalus [][]byte
+	if from == 0 {
+		nalus = append(nalus, g.sps, g.pps)
+	}
+	for _, au := range aus {
+		nalus = append(nalus, au...)
+	}
+	return h264codec.AnnexBMarshal(nalus)
+}
+
+// Frame is a frame of H.264 video. It is an image that is decoded the first time its pixels are
+// read.
+// NOTE: Usage of a frame that would fail to decode causes a lazy panic, like with
+// rimage.LazyEncodedImage.
+type Frame struct {
+	gop   *gop
+	index int
+
+	decodeOnce sync.Once
+	decodeErr  error
+	decoded    image.Image
+}
+
+// AnnexBSince returns, as an Annex-B byte stream, what a decoder last given prev needs to show
+// this frame. That is the access units after prev if it is from the same group of pictures, or
+// else the group up to this frame starting with its parameter sets. It returns nil if prev is this
+// frame or a later one.
+func (f *Frame) AnnexBSince(prev *Frame) ([]byte, error) {
+	from := 0
+	if prev != nil && prev.gop == f.gop {
+		if prev.index >= f.index {
+			return nil, nil
+		}
+		from = prev.index + 1
+	}
+	return f.gop.annexB(from, f.index)
+}
+
+// Decode decodes the frame with its sequence's decoder. The video is assumed to have no B-frames,
+// as is the case for the profiles used with WebRTC, so that each access unit given to the decoder
+// comes out as the next frame.
+func (f *Frame) Decode() (image.Image, error) {
+	f.decodeOnce.Do(func() {
+		f.decoded, f.decodeErr = f.gop.dec.decode(f)
+	})
+	return f.decoded, f.decodeErr
+}
+
+func (f *Frame) mustDecode() image.Image {
+	img, err := f.Decode()
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+// ColorModel returns the Image's color model.
+func (f *Frame) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the size of the video, which is known without decoding the frame.
+func (f *Frame) Bounds() image.Rectangle {
+	return image.Rect(0, 0, f.gop.width, f.gop.height)
+}
+
+// At returns the color of the pixel at (x, y), decoding the frame if it has not been yet.
+func (f *Frame) At(x, y int) color.Color {
+	return f.mustDecode().At(x, y)
+}
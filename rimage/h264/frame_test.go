@@ -0,0 +1,159 @@
+package h264
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os/exec"
+	"testing"
+
+	h264codec "github.com/aler9/gortsplib/v2/pkg/codecs/h264"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"go.viam.com/test"
+)
+
+var (
+	// a 352x288 SPS
+	testSPS = []byte{
+		0x67, 0x64, 0x00, 0x0c, 0xac, 0x3b, 0x50, 0xb0, 0x4b, 0x42, 0x00, 0x00,
+		0x03, 0x00, 0x02, 0x00, 0x00, 0x03, 0x00, 0x3d, 0x08,
+	}
+	testPPS = []byte{0x68, 0xee, 0x3c, 0x80}
+	testAUD = []byte{0x09, 0xf0}
+)
+
+// NALUs end with a stop bit, so they never end with a zero byte.
+func testIDR(b byte) []byte {
+	return []byte{0x65, 0x88, b, 0x80}
+}
+
+func testNonIDR(b byte) []byte {
+	return []byte{0x41, 0x9a, b, 0x80}
+}
+
+func TestSequence(t *testing.T) {
+	var seq Sequence
+
+	// frames before an IDR frame are dropped
+	frame, err := seq.Add([][]byte{testNonIDR(0)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldBeNil)
+	_, err = seq.Add([][]byte{testIDR(0)})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "before the SPS and PPS")
+
+	// parameter sets on their own are not a frame
+	frame, err = seq.Add([][]byte{testSPS, testPPS})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldBeNil)
+
+	frame1, err := seq.Add([][]byte{testAUD, testIDR(1)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame1, test.ShouldNotBeNil)
+	test.That(t, frame1.Bounds(), test.ShouldResemble, image.Rect(0, 0, 352, 288))
+	test.That(t, frame1.ColorModel(), test.ShouldEqual, color.RGBAModel)
+
+	frame2, err := seq.Add([][]byte{testNonIDR(2)})
+	test.That(t, err, test.ShouldBeNil)
+	frame3, err := seq.Add([][]byte{testNonIDR(3)})
+	test.That(t, err, test.ShouldBeNil)
+
+	annexB := func(nalus ...[]byte) []byte {
+		t.Helper()
+		data, err := h264codec.AnnexBMarshal(nalus)
+		test.That(t, err, test.ShouldBeNil)
+		return data
+	}
+
+	// a new decoder needs the whole group of pictures, without the delimiter
+	data, err := frame3.AnnexBSince(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(testSPS, testPPS, testIDR(1), testNonIDR(2), testNonIDR(3)))
+
+	data, err = frame3.AnnexBSince(frame1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(testNonIDR(2), testNonIDR(3)))
+
+	data, err = frame2.AnnexBSince(frame3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldBeNil)
+	data, err = frame3.AnnexBSince(frame3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldBeNil)
+
+	// a frame from a new group of pictures starts over
+	frame4, err := seq.Add([][]byte{testIDR(4)})
+	test.That(t, err, test.ShouldBeNil)
+	data, err = frame4.AnnexBSince(frame3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(testSPS, testPPS, testIDR(4)))
+
+	// earlier frames are not changed by later ones
+	data, err = frame2.AnnexBSince(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(testSPS, testPPS, testIDR(1), testNonIDR(2)))
+}
+
+func TestSequenceMaxGOPLength(t *testing.T) {
+	var seq Sequence
+	frame, err := seq.Add([][]byte{testSPS, testPPS, testIDR(0)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldNotBeNil)
+	for i := 1; i < maxGOPLength; i++ {
+		frame, err = seq.Add([][]byte{testNonIDR(byte(i))})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, frame, test.ShouldNotBeNil)
+	}
+	frame, err = seq.Add([][]byte{testNonIDR(0)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldBeNil)
+
+	frame, err = seq.Add([][]byte{testIDR(1)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame, test.ShouldNotBeNil)
+}
+
+func TestFrameDecode(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is not installed")
+	}
+
+	var encoded, stderr bytes.Buffer
+	err := ffmpeg.Input("testsrc=size=64x48:rate=10", ffmpeg.KwArgs{"f": "lavfi"}).
+		Output("pipe:", ffmpeg.KwArgs{
+			"frames:v": 10,
+			"vcodec":   "libx264",
+			"bf":       0,
+			"g":        5,
+			"pix_fmt":  "yuv420p",
+			"format":   "h264",
+		}).
+		WithOutput(&encoded).
+		WithErrorOutput(&stderr).
+		Run()
+	test.That(t, err, test.ShouldBeNil)
+
+	var seq Sequence
+	var frames []*Frame
+	reader := NewAccessUnitReader(&encoded)
+	for {
+		au, err := reader.Next()
+		if err != nil {
+			break
+		}
+		frame, err := seq.Add(au)
+		test.That(t, err, test.ShouldBeNil)
+		if frame != nil {
+			frames = append(frames, frame)
+		}
+	}
+	test.That(t, frames, test.ShouldHaveLength, 10)
+
+	for _, frame := range []*Frame{frames[0], frames[3], frames[9]} {
+		img, err := frame.Decode()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 64, 48))
+		test.That(t, frame.Bounds(), test.ShouldResemble, img.Bounds())
+		test.That(t, frame.At(1, 1), test.ShouldResemble, img.At(1, 1))
+	}
+}
@@ -0,0 +1,310 @@
+package rimage
+
+import (
+	"image"
+	"math"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// DisparityMethod is the way the pixels of a stereo pair are matched to each other.
+type DisparityMethod string
+
+// The disparity methods.
+const (
+	// BlockMatching matches each pixel on its own by comparing the blocks around it. It is fast,
+	// but leaves holes where there isn't much texture.
+	BlockMatching = DisparityMethod("block_matching")
+	// SemiGlobalMatching smooths the block matching costs along the rows and columns of the image
+	// so that neighboring pixels agree on a disparity. It is slower, but fills in more of the image.
+	SemiGlobalMatching = DisparityMethod("sgm")
+)
+
+const (
+	censusRadius  = 2
+	censusMaxCost = (2*censusRadius+1)*(2*censusRadius+1) - 1
+	maxBlockSize  = 15
+	maxMatchCost  = math.MaxUint16
+)
+
+// DisparityConfig configures how a rectified stereo pair is matched.
+type DisparityConfig struct {
+	Method DisparityMethod
+	// MaxDisparity is the largest disparity searched for, in pixels. Nearer objects have larger
+	// disparities.
+	MaxDisparity int
+	// BlockSize is the odd width of the block compared around each pixel.
+	BlockSize int
+	// UniquenessRatio is how much better, as a fraction, the best match must be than the next best
+	// match that isn't next to it for the pixel to have a disparity.
+	UniquenessRatio float64
+}
+
+// DisparityMap holds, for each pixel of the left image of a rectified stereo pair, how many pixels
+// to the left the same point is in the right image. Pixels without a match have a disparity of 0.
+type DisparityMap struct {
+	width, height int
+	data          []float64
+}
+
+// Width returns the width of the disparity map.
+func (dm *DisparityMap) Width() int {
+	return dm.width
+}
+
+// Height returns the height of the disparity map.
+func (dm *DisparityMap) Height() int {
+	return dm.height
+}
+
+// Get returns the disparity at the pixel, or 0 if the pixel has no match.
+func (dm *DisparityMap) Get(x, y int) float64 {
+	return dm.data[y*dm.width+x]
+}
+
+// ComputeDisparity matches the pixels of the left image of a rectified stereo pair to the right
+// image. The pixels are compared by the census transform of their neighborhood, so the two cameras
+// do not need to agree on brightness, and matches that the right image doesn't agree with are
+// dropped.
+func ComputeDisparity(left, right *image.Gray, conf DisparityConfig) (*DisparityMap, error) {
+	if !left.Bounds().Size().Eq(right.Bounds().Size()) {
+		return nil, errors.Errorf("left image is %v but right image is %v", left.Bounds().Size(), right.Bounds().Size())
+	}
+	if conf.MaxDisparity < 1 {
+		return nil, errors.Errorf("max disparity must be positive, got %d", conf.MaxDisparity)
+	}
+	if conf.BlockSize < 1 || conf.BlockSize > maxBlockSize || conf.BlockSize%2 == 0 {
+		return nil, errors.Errorf("block size must be odd and between 1 and %d, got %d", maxBlockSize, conf.BlockSize)
+	}
+	if conf.UniquenessRatio < 0 || conf.UniquenessRatio >= 1 {
+		return nil, errors.Errorf("uniqueness ratio must be in [0, 1), got %v", conf.UniquenessRatio)
+	}
+	width, height := left.Bounds().Dx(), left.Bounds().Dy()
+	numDisp := conf.MaxDisparity + 1
+	costs := blockCosts(census(left), census(right), width, height, numDisp, conf.BlockSize)
+	switch conf.Method {
+	case BlockMatching:
+	case SemiGlobalMatching:
+		area := uint16(conf.BlockSize * conf.BlockSize)
+		costs = aggregateCosts(costs, width, height, numDisp, area, 4*area)
+	default:
+		return nil, errors.Errorf("no such disparity method %q", conf.Method)
+	}
+	return selectDisparities(costs, width, height, numDisp, conf.UniquenessRatio), nil
+}
+
+// census describes each pixel by which of its neighbors are darker than it.
+func census(img *image.Gray) []uint32 {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	at := func(x, y int) uint8 {
+		x = clampInt(x, 0, width-1)
+		y = clampInt(y, 0, height-1)
+		return img.GrayAt(b.Min.X+x, b.Min.Y+y).Y
+	}
+	out := make([]uint32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			center := at(x, y)
+			var desc uint32
+			for dy := -censusRadius; dy <= censusRadius; dy++ {
+				for dx := -censusRadius; dx <= censusRadius; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+					desc <<= 1
+					if at(x+dx, y+dy) < center {
+						desc |= 1
+					}
+				}
+			}
+			out[y*width+x] = desc
+		}
+	}
+	return out
+}
+
+// blockCosts returns the cost volume, indexed by (y*width+x)*numDisp+d, of matching each pixel of the
+// left image to the pixel d to its left in the right image, summed over the block around the pixel.
+// Disparities that would fall off the right image cost the most.
+func blockCosts(left, right []uint32, width, height, numDisp, blockSize int) []uint16 {
+	costs := make([]uint16, width*height*numDisp)
+	hamming := make([]int32, width*height)
+	integral := make([]int32, (width+1)*(height+1))
+	r := blockSize / 2
+	for d := 0; d < numDisp; d++ {
+		for i := range hamming {
+			x := i % width
+			if x < d {
+				hamming[i] = censusMaxCost
+				continue
+			}
+			hamming[i] = int32(bits.OnesCount32(left[i] ^ right[i-d]))
+		}
+		for y := 0; y < height; y++ {
+			var rowSum int32
+			for x := 0; x < width; x++ {
+				rowSum += hamming[y*width+x]
+				integral[(y+1)*(width+1)+x+1] = integral[y*(width+1)+x+1] + rowSum
+			}
+		}
+		for y := 0; y < height; y++ {
+			y0, y1 := clampInt(y-r, 0, height-1), clampInt(y+r, 0, height-1)+1
+			for x := 0; x < width; x++ {
+				x0, x1 := clampInt(x-r, 0, width-1), clampInt(x+r, 0, width-1)+1
+				sum := integral[y1*(width+1)+x1] - integral[y0*(width+1)+x1] - integral[y1*(width+1)+x0] + integral[y0*(width+1)+x0]
+				// scale blocks cut off by the border up to the full block
+				sum = sum * int32(blockSize*blockSize) / int32((x1-x0)*(y1-y0))
+				costs[(y*width+x)*numDisp+d] = uint16(sum)
+			}
+		}
+	}
+	return costs
+}
+
+// aggregateCosts smooths the cost volume along the four paths across the image, left to right,
+// right to left, top to bottom and bottom to top, penalizing changes of disparity by small for a
+// change of one and large for any more.
+func aggregateCosts(costs []uint16, width, height, numDisp int, small, large uint16) []uint16 {
+	sum := make([]uint16, len(costs))
+	prev := make([]uint16, numDisp)
+	cur := make([]uint16, numDisp)
+	// each path is walked along its lines, with each pixel depending on the one before it
+	paths := []struct {
+		lines, steps int
+		pixel        func(line, step int) int
+	}{
+		{height, width, func(y, x int) int { return y*width + x }},
+		{height, width, func(y, x int) int { return y*width + width - 1 - x }},
+		{width, height, func(x, y int) int { return y*width + x }},
+		{width, height, func(x, y int) int { return (height-1-y)*width + x }},
+	}
+	for _, path := range paths {
+		for line := 0; line < path.lines; line++ {
+			for step := 0; step < path.steps; step++ {
+				p := path.pixel(line, step)
+				c := costs[p*numDisp : (p+1)*numDisp]
+				if step == 0 {
+					copy(cur, c)
+				} else {
+					smoothStep(cur, c, prev, small, large)
+				}
+				for d, v := range cur {
+					sum[p*numDisp+d] = saturatingAdd(sum[p*numDisp+d], v)
+				}
+				prev, cur = cur, prev
+			}
+		}
+	}
+	return sum
+}
+
+// smoothStep sets l to the cost of each disparity at the pixel given the costs of the pixel before it
+// on the path.
+func smoothStep(l, c, before []uint16, small, large uint16) {
+	minBefore := before[0]
+	for _, v := range before[1:] {
+		if v < minBefore {
+			minBefore = v
+		}
+	}
+	for d := range l {
+		best := uint32(before[d])
+		if d > 0 && uint32(before[d-1])+uint32(small) < best {
+			best = uint32(before[d-1]) + uint32(small)
+		}
+		if d < len(l)-1 && uint32(before[d+1])+uint32(small) < best {
+			best = uint32(before[d+1]) + uint32(small)
+		}
+		if uint32(minBefore)+uint32(large) < best {
+			best = uint32(minBefore) + uint32(large)
+		}
+		// subtracting the smallest cost before keeps the costs from growing along the path
+		v := uint32(c[d]) + best - uint32(minBefore)
+		if v > maxMatchCost {
+			v = maxMatchCost
+		}
+		l[d] = uint16(v)
+	}
+}
+
+func saturatingAdd(a, b uint16) uint16 {
+	if s := uint32(a) + uint32(b); s < maxMatchCost {
+		return uint16(s)
+	}
+	return maxMatchCost
+}
+
+// selectDisparities picks the cheapest disparity of each pixel to subpixel precision, and drops the
+// ones that are ambiguous or that the right image would match to a different disparity.
+func selectDisparities(costs []uint16, width, height, numDisp int, uniqueness float64) *DisparityMap {
+	out := &DisparityMap{width: width, height: height, data: make([]float64, width*height)}
+	rightBest := make([]int, width)
+	for y := 0; y < height; y++ {
+		row := costs[y*width*numDisp : (y+1)*width*numDisp]
+		// the best disparity of each pixel of the right image, for the consistency check
+		for xr := 0; xr < width; xr++ {
+			best, bestCost := -1, uint16(maxMatchCost)
+			for d := 0; d < numDisp && xr+d < width; d++ {
+				if c := row[(xr+d)*numDisp+d]; c < bestCost {
+					best, bestCost = d, c
+				}
+			}
+			rightBest[xr] = best
+		}
+		for x := 0; x < width; x++ {
+			c := row[x*numDisp : (x+1)*numDisp]
+			maxD := minIntValue(numDisp-1, x)
+			best := 0
+			for d := 1; d <= maxD; d++ {
+				if c[d] < c[best] {
+					best = d
+				}
+			}
+			if best == 0 {
+				// too far to tell from no match at all
+				continue
+			}
+			secondCost := uint16(maxMatchCost)
+			for d := 0; d <= maxD; d++ {
+				if (d < best-1 || d > best+1) && c[d] < secondCost {
+					secondCost = c[d]
+				}
+			}
+			if float64(c[best]) > (1-uniqueness)*float64(secondCost) {
+				continue
+			}
+			if rd := rightBest[x-best]; rd < best-1 || rd > best+1 {
+				continue
+			}
+			disp := float64(best)
+			if best < maxD {
+				// fit a parabola through the costs around the best disparity
+				c0, c1, c2 := float64(c[best-1]), float64(c[best]), float64(c[best+1])
+				if denom := c0 - 2*c1 + c2; denom > 0 {
+					disp += (c0 - c2) / (2 * denom)
+				}
+			}
+			out.data[y*width+x] = disp
+		}
+	}
+	return out
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func minIntValue(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,111 @@
+package rimage
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+// stereoPair makes a rectified stereo pair of a textured background with a disparity of 8 and a
+// textured square in front of it with a disparity of 20.
+func stereoPair(width, height int) (*image.Gray, *image.Gray, image.Rectangle) {
+	rng := rand.New(rand.NewSource(1))
+	texture := func() [][]uint8 {
+		tex := make([][]uint8, height)
+		for y := range tex {
+			tex[y] = make([]uint8, width+40)
+			for x := range tex[y] {
+				tex[y][x] = uint8(rng.Intn(256))
+			}
+		}
+		return tex
+	}
+	background, foreground := texture(), texture()
+	square := image.Rect(width/3, height/3, 2*width/3, 2*height/3)
+	left, right := image.NewGray(image.Rect(0, 0, width, height)), image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if image.Pt(x, y).In(square) {
+				left.SetGray(x, y, color.Gray{foreground[y][x]})
+			} else {
+				left.SetGray(x, y, color.Gray{background[y][x]})
+			}
+			// the right camera sees everything shifted left by its disparity
+			if image.Pt(x+20, y).In(square) {
+				right.SetGray(x, y, color.Gray{foreground[y][x+20]})
+			} else {
+				right.SetGray(x, y, color.Gray{background[y][x+8]})
+			}
+		}
+	}
+	return left, right, square
+}
+
+// disparityAccuracy returns the fraction of pixels in the region with a disparity within half a
+// pixel of the expected one.
+func disparityAccuracy(dm *DisparityMap, region image.Rectangle, expected float64) float64 {
+	var good int
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			if math.Abs(dm.Get(x, y)-expected) < 0.5 {
+				good++
+			}
+		}
+	}
+	return float64(good) / float64(region.Dx()*region.Dy())
+}
+
+func TestComputeDisparity(t *testing.T) {
+	left, right, square := stereoPair(160, 120)
+	inside := square.Inset(4)
+	// background away from the square and the left edge, which the right camera doesn't see
+	background := image.Rect(30, 4, 160, square.Min.Y-4)
+
+	for _, method := range []DisparityMethod{BlockMatching, SemiGlobalMatching} {
+		t.Run(string(method), func(t *testing.T) {
+			dm, err := ComputeDisparity(left, right, DisparityConfig{
+				Method:          method,
+				MaxDisparity:    32,
+				BlockSize:       5,
+				UniquenessRatio: 0.05,
+			})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, dm.Width(), test.ShouldEqual, 160)
+			test.That(t, dm.Height(), test.ShouldEqual, 120)
+			test.That(t, disparityAccuracy(dm, inside, 20), test.ShouldBeGreaterThan, 0.95)
+			test.That(t, disparityAccuracy(dm, background, 8), test.ShouldBeGreaterThan, 0.95)
+		})
+	}
+
+	t.Run("no texture", func(t *testing.T) {
+		flat := image.NewGray(image.Rect(0, 0, 80, 60))
+		for i := range flat.Pix {
+			flat.Pix[i] = 128
+		}
+		dm, err := ComputeDisparity(flat, flat, DisparityConfig{Method: BlockMatching, MaxDisparity: 16, BlockSize: 5})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, disparityAccuracy(dm, flat.Bounds(), 0), test.ShouldEqual, 1)
+	})
+
+	t.Run("bad config", func(t *testing.T) {
+		good := DisparityConfig{Method: SemiGlobalMatching, MaxDisparity: 16, BlockSize: 5}
+		for _, modify := range []func(*DisparityConfig){
+			func(c *DisparityConfig) { c.Method = "nope" },
+			func(c *DisparityConfig) { c.MaxDisparity = 0 },
+			func(c *DisparityConfig) { c.BlockSize = 4 },
+			func(c *DisparityConfig) { c.BlockSize = 17 },
+			func(c *DisparityConfig) { c.UniquenessRatio = 1 },
+		} {
+			conf := good
+			modify(&conf)
+			_, err := ComputeDisparity(left, right, conf)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+		_, err := ComputeDisparity(left, image.NewGray(image.Rect(0, 0, 10, 10)), good)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,431 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// MinIntrinsicCalibrationViews is the fewest views of a calibration target that the intrinsic
+	// parameters can be solved from.
+	MinIntrinsicCalibrationViews = 3
+	// maxCalibrationIterations is how many iterations refining a calibration may take.
+	maxCalibrationIterations = 100
+	// intrinsicParamCount is the number of parameters the camera itself has: fx, fy, ppx, ppy and
+	// the five Brown-Conrady terms.
+	intrinsicParamCount = 9
+)
+
+// IntrinsicCalibration is the result of calibrating the intrinsic parameters and lens distortion of
+// a camera from views of a planar target.
+type IntrinsicCalibration struct {
+	Intrinsics *PinholeCameraIntrinsics `json:"intrinsic_parameters"`
+	Distortion *BrownConrady            `json:"distortion_parameters"`
+	// ReprojectionError is the root mean square distance in pixels between where the target points
+	// were observed and where the calibrated camera projects them.
+	ReprojectionError float64 `json:"reprojection_error_px"`
+	// ViewErrors is the root mean square reprojection error of each view.
+	ViewErrors []float64 `json:"view_reprojection_errors_px"`
+}
+
+// CheckerboardPoints returns the inner corners of a checkerboard with rows by cols inner corners and
+// squares of the given size, in row-major order, in the plane of the board with the first corner
+// at the origin.
+func CheckerboardPoints(rows, cols int, squareSize float64) []r2.Point {
+	pts := make([]r2.Point, 0, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			pts = append(pts, r2.Point{X: float64(c) * squareSize, Y: float64(r) * squareSize})
+		}
+	}
+	return pts
+}
+
+// CalibratePinholeIntrinsics finds the intrinsic parameters and modified Brown-Conrady distortion
+// of a width by height camera from at least three views of a planar target, such as a
+// checkerboard, held at different angles. targetPoints are the points of the target in its own
+// plane, and each view lists the pixels they were observed at, in the same order.
+// The closed form solution of Zhang's method ("A Flexible New Technique for Camera Calibration",
+// 2000), which assumes no distortion, is refined along with the distortion by minimizing the
+// reprojection error with Levenberg-Marquardt.
+func CalibratePinholeIntrinsics(targetPoints []r2.Point, views [][]r2.Point, width, height int) (*IntrinsicCalibration, error) {
+	if len(views) < MinIntrinsicCalibrationViews {
+		return nil, errors.Errorf("need at least %d views to calibrate intrinsics, only have %d",
+			MinIntrinsicCalibrationViews, len(views))
+	}
+	if len(targetPoints) < 4 {
+		return nil, errors.Errorf("need at least 4 target points to calibrate intrinsics, only have %d", len(targetPoints))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.Errorf("invalid image dimensions (%d, %d)", width, height)
+	}
+	homographies := make([]*mat.Dense, 0, len(views))
+	for i, view := range views {
+		if len(view) != len(targetPoints) {
+			return nil, errors.Errorf("view %d has %d points but the target has %d", i, len(view), len(targetPoints))
+		}
+		h, err := fitHomography(targetPoints, view)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		homographies = append(homographies, h)
+	}
+
+	intrinsics, err := closedFormIntrinsics(homographies, width, height)
+	if err != nil {
+		return nil, err
+	}
+	params := make([]float64, intrinsicParamCount, intrinsicParamCount+6*len(views))
+	params[0], params[1], params[2], params[3] = intrinsics.Fx, intrinsics.Fy, intrinsics.Ppx, intrinsics.Ppy
+	poses := make([]viewPose, 0, len(views))
+	for i, h := range homographies {
+		pose, err := viewPoseFromHomography(intrinsics, h)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		poses = append(poses, pose)
+	}
+
+	params, poses = refineCalibration(params, poses, targetPoints, views)
+
+	cal := &IntrinsicCalibration{
+		Intrinsics: &PinholeCameraIntrinsics{
+			Width:  width,
+			Height: height,
+			Fx:     params[0],
+			Fy:     params[1],
+			Ppx:    params[2],
+			Ppy:    params[3],
+		},
+		Distortion: distortionFromParams(params),
+		ViewErrors: make([]float64, 0, len(views)),
+	}
+	var total float64
+	for i, view := range views {
+		sum := sumOfSquares(viewResiduals(params, poses[i], targetPoints, view))
+		total += sum
+		cal.ViewErrors = append(cal.ViewErrors, math.Sqrt(sum/float64(len(view))))
+	}
+	cal.ReprojectionError = math.Sqrt(total / float64(len(views)*len(targetPoints)))
+	return cal, nil
+}
+
+// fitHomography finds the homography from the source to the destination points that minimizes
+// the algebraic error, with the points normalized as in Multiple View Geometry. Richard Hartley and
+// Andrew Zisserman. Alg 4.2 p109.
+func fitHomography(src, dst []r2.Point) (*mat.Dense, error) {
+	srcPts, srcNorm := normalizePoints(src)
+	dstPts, dstNorm := normalizePoints(dst)
+	a := mat.NewDense(2*len(src), 9, nil)
+	for i := range srcPts {
+		x, y := srcPts[i].X, srcPts[i].Y
+		u, v := dstPts[i].X, dstPts[i].Y
+		a.SetRow(2*i, []float64{x, y, 1, 0, 0, 0, -u * x, -u * y, -u})
+		a.SetRow(2*i+1, []float64{0, 0, 0, x, y, 1, -v * x, -v * y, -v})
+	}
+	h, err := nullVector(a)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fit homography")
+	}
+	normalized := mat.NewDense(3, 3, h)
+	var dstDenorm, denormalized, out mat.Dense
+	if err := dstDenorm.Inverse(dstNorm); err != nil {
+		return nil, errors.Wrap(err, "points are degenerate")
+	}
+	denormalized.Mul(&dstDenorm, normalized)
+	out.Mul(&denormalized, srcNorm)
+	return &out, nil
+}
+
+// nullVector returns the right singular vector of the smallest singular value of the matrix.
+func nullVector(a *mat.Dense) ([]float64, error) {
+	var svd mat.SVD
+	if !svd.Factorize(a, mat.SVDFull) {
+		return nil, errors.New("failed to factorize matrix")
+	}
+	var v mat.Dense
+	svd.VTo(&v)
+	_, cols := v.Dims()
+	return mat.Col(nil, cols-1, &v), nil
+}
+
+// closedFormIntrinsics solves for the intrinsics with zero skew from the homographies of each view,
+// as in Zhang's method. The homographies are first conditioned to map to image coordinates that
+// are centered and scaled to about unit size.
+func closedFormIntrinsics(homographies []*mat.Dense, width, height int) (*PinholeCameraIntrinsics, error) {
+	scale := 2 / float64(width+height)
+	cx, cy := float64(width)/2, float64(height)/2
+	condition := mat.NewDense(3, 3, []float64{scale, 0, -scale * cx, 0, scale, -scale * cy, 0, 0, 1})
+
+	// each homography gives two constraints on B = K^-T K^-1, stored as
+	// b = [B11, B12, B22, B13, B23, B33], and a final row asks for zero skew
+	v := mat.NewDense(2*len(homographies)+1, 6, nil)
+	for i, h := range homographies {
+		var conditioned mat.Dense
+		conditioned.Mul(condition, h)
+		v12 := zhangConstraint(&conditioned, 0, 1)
+		v11 := zhangConstraint(&conditioned, 0, 0)
+		v22 := zhangConstraint(&conditioned, 1, 1)
+		for j := range v11 {
+			v11[j] -= v22[j]
+		}
+		v.SetRow(2*i, v12)
+		v.SetRow(2*i+1, v11)
+	}
+	v.SetRow(2*len(homographies), []float64{0, 1, 0, 0, 0, 0})
+	b, err := nullVector(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not solve for intrinsics")
+	}
+	b11, b12, b22, b13, b23, b33 := b[0], b[1], b[2], b[3], b[4], b[5]
+
+	// Zhang's Appendix B
+	denom := b11*b22 - b12*b12
+	if b11 == 0 || denom == 0 {
+		return nil, errors.New("views are degenerate, hold the target at more varied angles")
+	}
+	v0 := (b12*b13 - b11*b23) / denom
+	lambda := b33 - (b13*b13+v0*(b12*b13-b11*b23))/b11
+	alphaSq := lambda / b11
+	betaSq := lambda * b11 / denom
+	if alphaSq <= 0 || betaSq <= 0 {
+		return nil, errors.New("views are degenerate, hold the target at more varied angles")
+	}
+	alpha, beta := math.Sqrt(alphaSq), math.Sqrt(betaSq)
+	u0 := -b13 * alphaSq / lambda
+
+	return &PinholeCameraIntrinsics{
+		Width:  width,
+		Height: height,
+		Fx:     alpha / scale,
+		Fy:     beta / scale,
+		Ppx:    u0/scale + cx,
+		Ppy:    v0/scale + cy,
+	}, nil
+}
+
+// zhangConstraint returns v_ij such that h_i^T B h_j = v_ij^T b for columns i and j of the homography.
+func zhangConstraint(h *mat.Dense, i, j int) []float64 {
+	hi1, hi2, hi3 := h.At(0, i), h.At(1, i), h.At(2, i)
+	hj1, hj2, hj3 := h.At(0, j), h.At(1, j), h.At(2, j)
+	return []float64{
+		hi1 * hj1,
+		hi1*hj2 + hi2*hj1,
+		hi2 * hj2,
+		hi3*hj1 + hi1*hj3,
+		hi3*hj2 + hi2*hj3,
+		hi3 * hj3,
+	}
+}
+
+// viewPose is the rotation and translation of the target in the camera frame for one view.
+type viewPose struct {
+	rotation    [3][3]float64
+	translation r3.Vector
+}
+
+// viewPoseFromHomography recovers the pose of the target from the homography of its view.
+func viewPoseFromHomography(k *PinholeCameraIntrinsics, h *mat.Dense) (viewPose, error) {
+	column := func(c int) r3.Vector {
+		u, v, w := h.At(0, c), h.At(1, c), h.At(2, c)
+		return r3.Vector{X: (u - k.Ppx*w) / k.Fx, Y: (v - k.Ppy*w) / k.Fy, Z: w}
+	}
+	r1, r2, t := column(0), column(1), column(2)
+	scale := 1 / r1.Norm()
+	if t.Z < 0 {
+		scale = -scale
+	}
+	r1, r2, t = r1.Mul(scale), r2.Mul(scale), t.Mul(scale)
+	r, err := nearestRotation(r1, r2, r1.Cross(r2))
+	if err != nil {
+		return viewPose{}, err
+	}
+	return viewPose{rotation: r, translation: t}, nil
+}
+
+// nearestRotation returns the rotation closest to the matrix whose columns are given.
+func nearestRotation(x, y, z r3.Vector) ([3][3]float64, error) {
+	m := mat.NewDense(3, 3, []float64{x.X, y.X, z.X, x.Y, y.Y, z.Y, x.Z, y.Z, z.Z})
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDFull) {
+		return [3][3]float64{}, errors.New("could not factorize rotation")
+	}
+	var u, v, uvt mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	uvt.Mul(&u, v.T())
+	if mat.Det(&uvt) < 0 {
+		for i := 0; i < 3; i++ {
+			u.Set(i, 2, -u.At(i, 2))
+		}
+		uvt.Mul(&u, v.T())
+	}
+	var r [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = uvt.At(i, j)
+		}
+	}
+	return r, nil
+}
+
+// update returns the pose moved by a rotation about the axis of delta[0:3], by its length in
+// radians, and a translation by delta[3:6].
+func (p viewPose) update(delta []float64) viewPose {
+	rot := rodrigues(r3.Vector{X: delta[0], Y: delta[1], Z: delta[2]})
+	var r [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += p.rotation[i][k] * rot[k][j]
+			}
+		}
+	}
+	return viewPose{rotation: r, translation: p.translation.Add(r3.Vector{X: delta[3], Y: delta[4], Z: delta[5]})}
+}
+
+// rodrigues returns the rotation about the axis of the vector by its length in radians.
+func rodrigues(v r3.Vector) [3][3]float64 {
+	angle := v.Norm()
+	if angle < 1e-12 {
+		return [3][3]float64{{1, -v.Z, v.Y}, {v.Z, 1, -v.X}, {-v.Y, v.X, 1}}
+	}
+	k := v.Mul(1 / angle)
+	s, c := math.Sin(angle), math.Cos(angle)
+	t := 1 - c
+	return [3][3]float64{
+		{c + k.X*k.X*t, k.X*k.Y*t - k.Z*s, k.X*k.Z*t + k.Y*s},
+		{k.Y*k.X*t + k.Z*s, c + k.Y*k.Y*t, k.Y*k.Z*t - k.X*s},
+		{k.Z*k.X*t - k.Y*s, k.Z*k.Y*t + k.X*s, c + k.Z*k.Z*t},
+	}
+}
+
+func distortionFromParams(params []float64) *BrownConrady {
+	return &BrownConrady{
+		RadialK1:     params[4],
+		RadialK2:     params[5],
+		RadialK3:     params[6],
+		TangentialP1: params[7],
+		TangentialP2: params[8],
+	}
+}
+
+// viewResiduals returns the differences in pixels between where the camera described by the
+// intrinsic parameters projects the target points from the pose and where they were observed.
+func viewResiduals(params []float64, pose viewPose, targetPoints, observed []r2.Point) []float64 {
+	fx, fy, ppx, ppy := params[0], params[1], params[2], params[3]
+	distortion := distortionFromParams(params)
+	r, t := pose.rotation, pose.translation
+	res := make([]float64, 0, 2*len(targetPoints))
+	for i, p := range targetPoints {
+		x := r[0][0]*p.X + r[0][1]*p.Y + t.X
+		y := r[1][0]*p.X + r[1][1]*p.Y + t.Y
+		z := r[2][0]*p.X + r[2][1]*p.Y + t.Z
+		dx, dy := distortion.Transform(x/z, y/z)
+		res = append(res, dx*fx+ppx-observed[i].X, dy*fy+ppy-observed[i].Y)
+	}
+	return res
+}
+
+func sumOfSquares(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v * v
+	}
+	return sum
+}
+
+// refineCalibration minimizes the reprojection error over the intrinsic parameters and the pose of
+// every view with Levenberg-Marquardt. The Jacobian is found by finite differences, using that
+// each pose only moves the residuals of its own view.
+func refineCalibration(
+	params []float64,
+	poses []viewPose,
+	targetPoints []r2.Point,
+	views [][]r2.Point,
+) ([]float64, []viewPose) {
+	const epsilon = 1e-7
+	nParams := intrinsicParamCount + 6*len(poses)
+	viewLen := 2 * len(targetPoints)
+	nResiduals := viewLen * len(views)
+
+	residuals := func(params []float64, poses []viewPose) []float64 {
+		res := make([]float64, 0, nResiduals)
+		for i, view := range views {
+			res = append(res, viewResiduals(params, poses[i], targetPoints, view)...)
+		}
+		return res
+	}
+	apply := func(params []float64, poses []viewPose, delta []float64) ([]float64, []viewPose) {
+		nextParams := make([]float64, len(params))
+		for i := range params {
+			nextParams[i] = params[i] + delta[i]
+		}
+		nextPoses := make([]viewPose, len(poses))
+		for i, pose := range poses {
+			nextPoses[i] = pose.update(delta[intrinsicParamCount+6*i : intrinsicParamCount+6*i+6])
+		}
+		return nextParams, nextPoses
+	}
+
+	errs := residuals(params, poses)
+	cost := sumOfSquares(errs)
+	damping := 1e-3
+	for iter := 0; iter < maxCalibrationIterations && damping < 1e10; iter++ {
+		jacobian := mat.NewDense(nResiduals, nParams, nil)
+		for p := 0; p < intrinsicParamCount; p++ {
+			step := epsilon * math.Max(1, math.Abs(params[p]))
+			perturbed := append([]float64(nil), params...)
+			perturbed[p] += step
+			perturbedErrs := residuals(perturbed, poses)
+			for e := range errs {
+				jacobian.Set(e, p, (perturbedErrs[e]-errs[e])/step)
+			}
+		}
+		for v, pose := range poses {
+			for p := 0; p < 6; p++ {
+				delta := make([]float64, 6)
+				delta[p] = epsilon
+				perturbedErrs := viewResiduals(params, pose.update(delta), targetPoints, views[v])
+				for e := range perturbedErrs {
+					jacobian.Set(v*viewLen+e, intrinsicParamCount+6*v+p, (perturbedErrs[e]-errs[v*viewLen+e])/epsilon)
+				}
+			}
+		}
+
+		var jtj mat.Dense
+		jtj.Mul(jacobian.T(), jacobian)
+		for p := 0; p < nParams; p++ {
+			jtj.Set(p, p, jtj.At(p, p)*(1+damping))
+		}
+		var jte, step mat.VecDense
+		jte.MulVec(jacobian.T(), mat.NewVecDense(nResiduals, errs))
+		if err := step.SolveVec(&jtj, &jte); err != nil {
+			damping *= 10
+			continue
+		}
+		delta := make([]float64, nParams)
+		for p := range delta {
+			delta[p] = -step.AtVec(p)
+		}
+
+		nextParams, nextPoses := apply(params, poses, delta)
+		nextErrs := residuals(nextParams, nextPoses)
+		nextCost := sumOfSquares(nextErrs)
+		if nextCost >= cost || math.IsNaN(nextCost) {
+			damping *= 10
+			continue
+		}
+		converged := cost-nextCost < 1e-12*cost
+		params, poses, errs, cost = nextParams, nextPoses, nextErrs, nextCost
+		damping /= 10
+		if converged {
+			break
+		}
+	}
+	return params, poses
+}
@@ -0,0 +1,138 @@
+package transform
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+// projectTarget returns where the camera sees the target points with the target rotated by the
+// rotation vector and translated.
+func projectTarget(
+	intrinsics *PinholeCameraIntrinsics,
+	distortion *BrownConrady,
+	targetPoints []r2.Point,
+	rotation, translation r3.Vector,
+	noise float64,
+	rng *rand.Rand,
+) []r2.Point {
+	params := []float64{
+		intrinsics.Fx, intrinsics.Fy, intrinsics.Ppx, intrinsics.Ppy,
+		distortion.RadialK1, distortion.RadialK2, distortion.RadialK3, distortion.TangentialP1, distortion.TangentialP2,
+	}
+	pose := viewPose{rotation: rodrigues(rotation), translation: translation}
+	zeros := make([]r2.Point, len(targetPoints))
+	residuals := viewResiduals(params, pose, targetPoints, zeros)
+	pts := make([]r2.Point, len(targetPoints))
+	for i := range pts {
+		pts[i] = r2.Point{X: residuals[2*i] + noise*rng.NormFloat64(), Y: residuals[2*i+1] + noise*rng.NormFloat64()}
+	}
+	return pts
+}
+
+func TestCheckerboardPoints(t *testing.T) {
+	pts := CheckerboardPoints(2, 3, 25)
+	test.That(t, pts, test.ShouldResemble, []r2.Point{
+		{X: 0, Y: 0}, {X: 25, Y: 0}, {X: 50, Y: 0},
+		{X: 0, Y: 25}, {X: 25, Y: 25}, {X: 50, Y: 25},
+	})
+}
+
+func TestCalibratePinholeIntrinsics(t *testing.T) {
+	intrinsics := &PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 610, Fy: 605, Ppx: 322, Ppy: 243}
+	distortion := &BrownConrady{RadialK1: -0.21, RadialK2: 0.08, TangentialP1: 0.001, TangentialP2: -0.0015}
+	target := CheckerboardPoints(6, 9, 25)
+	// the target is centered in front of the camera and tilted differently in each view
+	center := r3.Vector{X: -100, Y: -62.5}
+	poses := []struct{ rotation, translation r3.Vector }{
+		{r3.Vector{X: 0.3, Y: 0.1}, r3.Vector{Z: 450}},
+		{r3.Vector{X: -0.25, Y: 0.35, Z: 0.1}, r3.Vector{X: 20, Z: 500}},
+		{r3.Vector{X: 0.1, Y: -0.4, Z: -0.2}, r3.Vector{Y: -15, Z: 420}},
+		{r3.Vector{X: -0.4, Y: -0.2, Z: 1.6}, r3.Vector{X: -10, Y: 10, Z: 480}},
+		{r3.Vector{X: 0.2, Y: 0.45, Z: 3.1}, r3.Vector{Z: 550}},
+		{r3.Vector{Y: 0.05}, r3.Vector{X: 60, Y: 40, Z: 380}},
+	}
+
+	t.Run("exact points", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		views := make([][]r2.Point, 0, len(poses))
+		for _, p := range poses {
+			translation := p.translation.Add(rodriguesApply(p.rotation, center))
+			views = append(views, projectTarget(intrinsics, distortion, target, p.rotation, translation, 0, rng))
+		}
+		cal, err := CalibratePinholeIntrinsics(target, views, 640, 480)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cal.Intrinsics.Width, test.ShouldEqual, 640)
+		test.That(t, cal.Intrinsics.Height, test.ShouldEqual, 480)
+		test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, intrinsics.Fx, 0.01)
+		test.That(t, cal.Intrinsics.Fy, test.ShouldAlmostEqual, intrinsics.Fy, 0.01)
+		test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, intrinsics.Ppx, 0.01)
+		test.That(t, cal.Intrinsics.Ppy, test.ShouldAlmostEqual, intrinsics.Ppy, 0.01)
+		test.That(t, cal.Distortion.RadialK1, test.ShouldAlmostEqual, distortion.RadialK1, 1e-4)
+		test.That(t, cal.Distortion.RadialK2, test.ShouldAlmostEqual, distortion.RadialK2, 1e-3)
+		test.That(t, cal.Distortion.TangentialP1, test.ShouldAlmostEqual, distortion.TangentialP1, 1e-5)
+		test.That(t, cal.Distortion.TangentialP2, test.ShouldAlmostEqual, distortion.TangentialP2, 1e-5)
+		test.That(t, cal.ReprojectionError, test.ShouldBeLessThan, 1e-3)
+		test.That(t, cal.ViewErrors, test.ShouldHaveLength, len(poses))
+	})
+
+	t.Run("noisy points", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		views := make([][]r2.Point, 0, len(poses))
+		for _, p := range poses {
+			translation := p.translation.Add(rodriguesApply(p.rotation, center))
+			views = append(views, projectTarget(intrinsics, distortion, target, p.rotation, translation, 0.2, rng))
+		}
+		cal, err := CalibratePinholeIntrinsics(target, views, 640, 480)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, intrinsics.Fx, 5)
+		test.That(t, cal.Intrinsics.Fy, test.ShouldAlmostEqual, intrinsics.Fy, 5)
+		test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, intrinsics.Ppx, 5)
+		test.That(t, cal.Intrinsics.Ppy, test.ShouldAlmostEqual, intrinsics.Ppy, 5)
+		// with noise the radial terms trade off against each other, but they should distort alike
+		x, y := cal.Distortion.Transform(0.4, 0.3)
+		expX, expY := distortion.Transform(0.4, 0.3)
+		test.That(t, x, test.ShouldAlmostEqual, expX, 2e-3)
+		test.That(t, y, test.ShouldAlmostEqual, expY, 2e-3)
+		test.That(t, cal.ReprojectionError, test.ShouldBeBetween, 0.1, 0.3)
+	})
+
+	t.Run("bad input", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(3))
+		views := make([][]r2.Point, 0, len(poses))
+		for _, p := range poses {
+			views = append(views, projectTarget(intrinsics, distortion, target, p.rotation, p.translation, 0, rng))
+		}
+		_, err := CalibratePinholeIntrinsics(target, views[:2], 640, 480)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 views")
+
+		_, err = CalibratePinholeIntrinsics(target, [][]r2.Point{views[0], views[1], views[2][:10]}, 640, 480)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "view 2 has 10 points")
+
+		_, err = CalibratePinholeIntrinsics(target, views, 0, 480)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// views of the target straight on can't tell the focal length from the distance
+		flat := make([][]r2.Point, 0, 3)
+		for _, z := range []float64{400, 500, 600} {
+			flat = append(flat, projectTarget(intrinsics, &BrownConrady{}, target, r3.Vector{}, r3.Vector{X: -100, Y: -60, Z: z}, 0, rng))
+		}
+		_, err = CalibratePinholeIntrinsics(target, flat, 640, 480)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "degenerate")
+	})
+}
+
+func rodriguesApply(rotation, v r3.Vector) r3.Vector {
+	r := rodrigues(rotation)
+	return r3.Vector{
+		X: r[0][0]*v.X + r[0][1]*v.Y + r[0][2]*v.Z,
+		Y: r[1][0]*v.X + r[1][1]*v.Y + r[1][2]*v.Z,
+		Z: r[2][0]*v.X + r[2][1]*v.Y + r[2][2]*v.Z,
+	}
+}
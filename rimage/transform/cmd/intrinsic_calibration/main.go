@@ -0,0 +1,159 @@
+// Finds the intrinsic parameters and Brown-Conrady distortion of a camera from images of a
+// checkerboard held at varied angles, and prints them as the intrinsic_parameters and
+// distortion_parameters of a camera config.
+// The images are either read from a directory, or captured from a camera on a running robot:
+// $./intrinsic_calibration -rows=6 -cols=9 -square=25 -images=/path/to/images
+// $./intrinsic_calibration -rows=6 -cols=9 -square=25 -robot=localhost:8080 -camera=cam
+// rows and cols count the inner corners of the checkerboard, where its squares meet.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/vision/chess"
+)
+
+func main() {
+	imagesPtr := flag.String("images", "", "directory of checkerboard images to calibrate from")
+	robotPtr := flag.String("robot", "", "address of the robot to capture checkerboard images from")
+	cameraPtr := flag.String("camera", "", "name of the camera on the robot to calibrate")
+	framesPtr := flag.Int("frames", 15, "number of images to capture from the camera")
+	intervalPtr := flag.Duration("interval", 2*time.Second, "time to move the checkerboard between captures")
+	rowsPtr := flag.Int("rows", 6, "number of inner corners along the checkerboard's rows")
+	colsPtr := flag.Int("cols", 9, "number of inner corners along the checkerboard's columns")
+	squarePtr := flag.Float64("square", 25, "size of the checkerboard's squares in mm")
+	flag.Parse()
+	logger := golog.NewLogger("intrinsic_calibration")
+	ctx := context.Background()
+
+	var imgs []image.Image
+	var err error
+	switch {
+	case *imagesPtr != "":
+		imgs, err = readImages(*imagesPtr)
+	case *robotPtr != "" && *cameraPtr != "":
+		imgs, err = captureImages(ctx, *robotPtr, *cameraPtr, *framesPtr, *intervalPtr, logger)
+	default:
+		err = errors.New("need either -images, or -robot and -camera")
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+	cal, err := calibrate(imgs, *rowsPtr, *colsPtr, *squarePtr, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"intrinsic_parameters":  cal.Intrinsics,
+		"distortion_parameters": cal.Distortion,
+	}, "", "  ")
+	if err != nil {
+		logger.Fatal(err)
+	}
+	fmt.Println(string(b))
+	os.Exit(0)
+}
+
+// calibrate finds the checkerboard in each image, skipping the images it isn't in, and calibrates
+// the camera from the views of it.
+func calibrate(imgs []image.Image, rows, cols int, squareSize float64, logger golog.Logger) (*transform.IntrinsicCalibration, error) {
+	if len(imgs) == 0 {
+		return nil, errors.New("no images to calibrate from")
+	}
+	width, height := imgs[0].Bounds().Dx(), imgs[0].Bounds().Dy()
+	views := make([][]r2.Point, 0, len(imgs))
+	for i, img := range imgs {
+		if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			return nil, errors.Errorf("image %d is %dx%d but image 0 is %dx%d",
+				i, img.Bounds().Dx(), img.Bounds().Dy(), width, height)
+		}
+		corners, err := chess.FindCheckerboardCorners(img, rows, cols)
+		if err != nil {
+			logger.Warnf("skipping image %d: %v", i, err)
+			continue
+		}
+		views = append(views, corners)
+	}
+	cal, err := transform.CalibratePinholeIntrinsics(transform.CheckerboardPoints(rows, cols, squareSize), views, width, height)
+	if err != nil {
+		return nil, err
+	}
+	for i, viewErr := range cal.ViewErrors {
+		logger.Debugf("view %d reprojection error: %.3f px", i, viewErr)
+	}
+	logger.Infof("calibrated from %d of %d images, reprojection error: %.3f px", len(views), len(imgs), cal.ReprojectionError)
+	return cal, nil
+}
+
+// readImages reads every png and jpeg image in the directory, in the order of their names.
+func readImages(dir string) ([]image.Image, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	imgs := make([]image.Image, 0, len(names))
+	for _, name := range names {
+		img, err := rimage.NewImageFromFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "path=%q", name)
+		}
+		imgs = append(imgs, img)
+	}
+	return imgs, nil
+}
+
+// captureImages captures images from the camera, waiting between them for the checkerboard to be
+// moved to a new angle.
+func captureImages(
+	ctx context.Context, address, name string, frames int, interval time.Duration, logger golog.Logger,
+) ([]image.Image, error) {
+	robotClient, err := client.New(ctx, address, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(func() error { return robotClient.Close(ctx) })
+	cam, err := camera.FromRobot(robotClient, name)
+	if err != nil {
+		return nil, err
+	}
+	imgs := make([]image.Image, 0, frames)
+	for i := 0; i < frames; i++ {
+		if !utils.SelectContextOrWait(ctx, interval) {
+			return nil, ctx.Err()
+		}
+		img, release, err := camera.ReadImage(ctx, cam)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, rimage.CloneImage(img))
+		release()
+		logger.Infof("captured image %d of %d, move the checkerboard to a new angle", i+1, frames)
+	}
+	return imgs, nil
+}
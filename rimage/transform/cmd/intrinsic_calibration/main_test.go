@@ -0,0 +1,105 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/rimage"
+)
+
+const (
+	testFocal  = 500.
+	testSquare = 25.
+)
+
+// renderBoard draws a checkerboard with 6 by 9 inner corners as a 640x480 camera with no
+// distortion sees it, rotated about x, y and then z by the angles and 450mm away.
+func renderBoard(angles r3.Vector) image.Image {
+	sx, cx := math.Sincos(angles.X)
+	sy, cy := math.Sincos(angles.Y)
+	sz, cz := math.Sincos(angles.Z)
+	r := [3][3]float64{
+		{cz * cy, cz*sy*sx - sz*cx, cz*sy*cx + sz*sx},
+		{sz * cy, sz*sy*sx + cz*cx, sz*sy*cx - cz*sx},
+		{-sy, cy * sx, cy * cx},
+	}
+	normal := r3.Vector{X: r[0][2], Y: r[1][2], Z: r[2][2]}
+	// the first inner corner, with the board centered in front of the camera
+	ox, oy := -4*testSquare, -2.5*testSquare
+	origin := r3.Vector{
+		X: r[0][0]*ox + r[0][1]*oy,
+		Y: r[1][0]*ox + r[1][1]*oy,
+		Z: r[2][0]*ox + r[2][1]*oy + 450,
+	}
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			var sum float64
+			for s := 0; s < 4; s++ {
+				u := float64(x) - 0.25 + float64(s%2)*0.5
+				v := float64(y) - 0.25 + float64(s/2)*0.5
+				ray := r3.Vector{X: (u - 320) / testFocal, Y: (v - 240) / testFocal, Z: 1}
+				hit := ray.Mul(origin.Dot(normal) / ray.Dot(normal)).Sub(origin)
+				col := math.Floor((hit.X*r[0][0] + hit.Y*r[1][0] + hit.Z*r[2][0]) / testSquare)
+				row := math.Floor((hit.X*r[0][1] + hit.Y*r[1][1] + hit.Z*r[2][1]) / testSquare)
+				switch {
+				case col < -2 || row < -2 || col > 9 || row > 6:
+					sum += 120
+				case col < -1 || row < -1 || col > 8 || row > 5 || int(col+row)%2 != 0:
+					sum += 235
+				default:
+					sum += 20
+				}
+			}
+			img.SetGray(x, y, color.Gray{uint8(sum / 4)})
+		}
+	}
+	return img
+}
+
+func TestMainCalibrate(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	imgs := []image.Image{
+		renderBoard(r3.Vector{X: 0.35, Y: 0.1}),
+		renderBoard(r3.Vector{X: -0.3, Y: 0.3, Z: 0.1}),
+		renderBoard(r3.Vector{X: 0.1, Y: -0.35, Z: -0.15}),
+		renderBoard(r3.Vector{X: -0.2, Y: -0.25, Z: 0.2}),
+		// the board isn't in this one
+		image.NewGray(image.Rect(0, 0, 640, 480)),
+	}
+
+	// the images can be read from a directory
+	dir := testutils.TempDirT(t, "", "transform_cmd_intrinsic_calibration")
+	for i, img := range imgs {
+		test.That(t, rimage.WriteImageToFile(dir+"/"+string(rune('a'+i))+".png", img), test.ShouldBeNil)
+	}
+	read, err := readImages(dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, read, test.ShouldHaveLength, len(imgs))
+
+	cal, err := calibrate(read, 6, 9, testSquare, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cal.Intrinsics.Width, test.ShouldEqual, 640)
+	test.That(t, cal.Intrinsics.Height, test.ShouldEqual, 480)
+	test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, testFocal, 5)
+	test.That(t, cal.Intrinsics.Fy, test.ShouldAlmostEqual, testFocal, 5)
+	test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, 320, 5)
+	test.That(t, cal.Intrinsics.Ppy, test.ShouldAlmostEqual, 240, 5)
+	test.That(t, cal.ViewErrors, test.ShouldHaveLength, 4)
+	test.That(t, cal.ReprojectionError, test.ShouldBeLessThan, 0.3)
+
+	_, err = calibrate(nil, 6, 9, testSquare, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = calibrate(imgs[:2], 6, 9, testSquare, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 views")
+	_, err = calibrate([]image.Image{imgs[0], image.NewGray(image.Rect(0, 0, 320, 240))}, 6, 9, testSquare, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+}
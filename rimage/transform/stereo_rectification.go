@@ -0,0 +1,217 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// StereoCalibration holds the intrinsics and distortion of the two cameras of a stereo pair, and the
+// pose transformation that transforms a point from the reference frame of the left camera to the
+// reference frame of the right camera.
+type StereoCalibration struct {
+	Left            PinholeCameraIntrinsics `json:"left_intrinsic_parameters"`
+	LeftDistortion  *BrownConrady           `json:"left_distortion_parameters,omitempty"`
+	Right           PinholeCameraIntrinsics `json:"right_intrinsic_parameters"`
+	RightDistortion *BrownConrady           `json:"right_distortion_parameters,omitempty"`
+	// Rotation is the row-major rotation matrix and Translation the translation in mm from the left
+	// camera to the right camera.
+	Rotation    []float64 `json:"rotation_rads"`
+	Translation []float64 `json:"translation_mm"`
+}
+
+// CheckValid checks if the fields for StereoCalibration have valid inputs.
+func (sc *StereoCalibration) CheckValid() error {
+	if sc == nil {
+		return errors.New("pointer to StereoCalibration is nil")
+	}
+	if err := sc.Left.CheckValid(); err != nil {
+		return errors.Wrap(err, "left camera")
+	}
+	if err := sc.Right.CheckValid(); err != nil {
+		return errors.Wrap(err, "right camera")
+	}
+	if sc.Left.Width != sc.Right.Width || sc.Left.Height != sc.Right.Height {
+		return errors.Errorf("left camera is %dx%d but right camera is %dx%d",
+			sc.Left.Width, sc.Left.Height, sc.Right.Width, sc.Right.Height)
+	}
+	if len(sc.Rotation) != 9 {
+		return errors.Errorf("length of rotation is %d, should be 9", len(sc.Rotation))
+	}
+	if len(sc.Translation) != 3 {
+		return errors.Errorf("length of translation is %d, should be 3", len(sc.Translation))
+	}
+	if (r3.Vector{X: sc.Translation[0], Y: sc.Translation[1], Z: sc.Translation[2]}).Norm() == 0 {
+		return errors.New("the cameras of a stereo pair can't be in the same place")
+	}
+	return nil
+}
+
+// StereoRectifier warps the images of a stereo pair so that they look like they were taken by two
+// identical cameras side by side, pointing the same way, which puts each point of the scene on the
+// same row of both images.
+type StereoRectifier struct {
+	intrinsics PinholeCameraIntrinsics
+	baseline   float64
+	// where each pixel of the rectified images comes from in the original images
+	leftMap, rightMap []r2.Point
+}
+
+// NewStereoRectifier computes the rectification of the stereo pair. The rectified left camera is
+// in the same place as the original one, with its x axis pointing at the right camera.
+func NewStereoRectifier(cal *StereoCalibration) (*StereoRectifier, error) {
+	if err := cal.CheckValid(); err != nil {
+		return nil, err
+	}
+	rotation, err := spatialmath.NewRotationMatrix(cal.Rotation)
+	if err != nil {
+		return nil, err
+	}
+	translation := r3.Vector{X: cal.Translation[0], Y: cal.Translation[1], Z: cal.Translation[2]}
+
+	// the right camera's center and optical axis in the left camera's frame
+	rightCenter := transposeMul(rotation, translation.Mul(-1))
+	rightAxis := transposeMul(rotation, r3.Vector{Z: 1})
+	// the rectified frame's x axis runs between the cameras, and its z axis is as close as it can
+	// be to the average of the two optical axes
+	xAxis := rightCenter.Normalize()
+	yAxis := r3.Vector{Z: 1}.Add(rightAxis).Cross(xAxis).Normalize()
+	zAxis := xAxis.Cross(yAxis)
+	if math.IsNaN(yAxis.X) || zAxis.Z < 0.5 {
+		return nil, errors.New("the cameras of the stereo pair must be side by side and point the same way")
+	}
+
+	focal := (cal.Left.Fx + cal.Left.Fy) / 2
+	rect := PinholeCameraIntrinsics{
+		Width:  cal.Left.Width,
+		Height: cal.Left.Height,
+		Fx:     focal,
+		Fy:     focal,
+		Ppx:    cal.Left.Ppx,
+		Ppy:    cal.Left.Ppy,
+	}
+	sr := &StereoRectifier{
+		intrinsics: rect,
+		baseline:   rightCenter.Norm(),
+		leftMap:    make([]r2.Point, rect.Width*rect.Height),
+		rightMap:   make([]r2.Point, rect.Width*rect.Height),
+	}
+	for v := 0; v < rect.Height; v++ {
+		for u := 0; u < rect.Width; u++ {
+			x, y := (float64(u)-rect.Ppx)/focal, (float64(v)-rect.Ppy)/focal
+			// the ray through the rectified pixel, in the left camera's frame
+			ray := xAxis.Mul(x).Add(yAxis.Mul(y)).Add(zAxis)
+			sr.leftMap[v*rect.Width+u] = distortedPixel(&cal.Left, cal.LeftDistortion, ray)
+			sr.rightMap[v*rect.Width+u] = distortedPixel(&cal.Right, cal.RightDistortion, rotation.Mul(ray))
+		}
+	}
+	return sr, nil
+}
+
+// transposeMul multiplies the vector by the inverse of the rotation.
+func transposeMul(rotation *spatialmath.RotationMatrix, v r3.Vector) r3.Vector {
+	return r3.Vector{X: rotation.Col(0).Dot(v), Y: rotation.Col(1).Dot(v), Z: rotation.Col(2).Dot(v)}
+}
+
+// distortedPixel returns the pixel where the camera sees along the ray.
+func distortedPixel(intrinsics *PinholeCameraIntrinsics, distortion *BrownConrady, ray r3.Vector) r2.Point {
+	if ray.Z <= 0 {
+		return r2.Point{X: -1, Y: -1}
+	}
+	x, y := ray.X/ray.Z, ray.Y/ray.Z
+	if distortion != nil {
+		x, y = distortion.Transform(x, y)
+	}
+	return r2.Point{X: x*intrinsics.Fx + intrinsics.Ppx, Y: y*intrinsics.Fy + intrinsics.Ppy}
+}
+
+// Intrinsics returns the intrinsics shared by the rectified cameras, which have no distortion.
+func (sr *StereoRectifier) Intrinsics() *PinholeCameraIntrinsics {
+	intrinsics := sr.intrinsics
+	return &intrinsics
+}
+
+// Baseline returns the distance between the cameras in mm.
+func (sr *StereoRectifier) Baseline() float64 {
+	return sr.baseline
+}
+
+// Rectify warps the left and right images with a bilinear interpolation. Pixels that neither
+// camera sees are black.
+func (sr *StereoRectifier) Rectify(left, right *rimage.Image) (*rimage.Image, *rimage.Image, error) {
+	for _, img := range []*rimage.Image{left, right} {
+		if img == nil {
+			return nil, nil, errors.New("input image is nil")
+		}
+		if img.Width() != sr.intrinsics.Width || img.Height() != sr.intrinsics.Height {
+			return nil, nil, errors.Errorf("img dimension and intrinsics don't match Image(%d,%d) != Intrinsics(%d,%d)",
+				img.Width(), img.Height(), sr.intrinsics.Width, sr.intrinsics.Height)
+		}
+	}
+	return remapImage(left, sr.leftMap), remapImage(right, sr.rightMap), nil
+}
+
+func remapImage(img *rimage.Image, sources []r2.Point) *rimage.Image {
+	width, height := img.Width(), img.Height()
+	out := rimage.NewImage(width, height)
+	for i, pt := range sources {
+		if pt.X < 0 || pt.Y < 0 || pt.X > float64(width-1) || pt.Y > float64(height-1) {
+			continue
+		}
+		x0, y0 := int(pt.X), int(pt.Y)
+		x1, y1 := x0+1, y0+1
+		if x1 == width {
+			x1 = x0
+		}
+		if y1 == height {
+			y1 = y0
+		}
+		fx, fy := pt.X-float64(x0), pt.Y-float64(y0)
+		var r, g, b float64
+		for _, c := range []struct {
+			x, y int
+			w    float64
+		}{
+			{x0, y0, (1 - fx) * (1 - fy)},
+			{x1, y0, fx * (1 - fy)},
+			{x0, y1, (1 - fx) * fy},
+			{x1, y1, fx * fy},
+		} {
+			cr, cg, cb := img.GetXY(c.x, c.y).RGB255()
+			r += c.w * float64(cr)
+			g += c.w * float64(cg)
+			b += c.w * float64(cb)
+		}
+		out.SetXY(i%width, i/width, rimage.NewColor(uint8(r+0.5), uint8(g+0.5), uint8(b+0.5)))
+	}
+	return out
+}
+
+// DisparityToDepth returns the depth of each pixel of the rectified left image from its disparity.
+// Pixels without a disparity have no depth.
+func (sr *StereoRectifier) DisparityToDepth(disparity *rimage.DisparityMap) (*rimage.DepthMap, error) {
+	if disparity.Width() != sr.intrinsics.Width || disparity.Height() != sr.intrinsics.Height {
+		return nil, errors.Errorf("disparity map is %dx%d but the rectified images are %dx%d",
+			disparity.Width(), disparity.Height(), sr.intrinsics.Width, sr.intrinsics.Height)
+	}
+	dm := rimage.NewEmptyDepthMap(disparity.Width(), disparity.Height())
+	for y := 0; y < dm.Height(); y++ {
+		for x := 0; x < dm.Width(); x++ {
+			d := disparity.Get(x, y)
+			if d <= 0 {
+				continue
+			}
+			depth := sr.intrinsics.Fx * sr.baseline / d
+			if depth > math.MaxUint16 {
+				continue
+			}
+			dm.Set(x, y, rimage.Depth(depth))
+		}
+	}
+	return dm, nil
+}
@@ -0,0 +1,125 @@
+package transform
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const stereoPlaneDepth = 1000.
+
+// planeTexture is a smooth random texture on the plane, with cells of 15mm.
+func planeTexture(x, y float64) float64 {
+	hash := func(i, j int) float64 {
+		h := uint32(i*73856093) ^ uint32(j*19349663)
+		h ^= h >> 13
+		h *= 0x5bd1e995
+		h ^= h >> 15
+		return float64(h % 256)
+	}
+	x, y = x/15, y/15
+	i, j := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(i), y-float64(j)
+	return hash(i, j)*(1-fx)*(1-fy) + hash(i+1, j)*fx*(1-fy) + hash(i, j+1)*(1-fx)*fy + hash(i+1, j+1)*fx*fy
+}
+
+// renderPlane draws what the camera, at the center and with the rotation from the left camera's
+// frame, sees of a textured plane facing the left camera.
+func renderPlane(intrinsics *PinholeCameraIntrinsics, distortion *BrownConrady, rotation []float64, center r3.Vector) *rimage.Image {
+	rm, err := spatialmath.NewRotationMatrix(rotation)
+	if err != nil {
+		panic(err)
+	}
+	img := rimage.NewImage(intrinsics.Width, intrinsics.Height)
+	for v := 0; v < intrinsics.Height; v++ {
+		for u := 0; u < intrinsics.Width; u++ {
+			xd, yd := (float64(u)-intrinsics.Ppx)/intrinsics.Fx, (float64(v)-intrinsics.Ppy)/intrinsics.Fy
+			// undo the distortion by fixed point iteration
+			x, y := xd, yd
+			for i := 0; i < 20; i++ {
+				tx, ty := distortion.Transform(x, y)
+				x, y = x+xd-tx, y+yd-ty
+			}
+			ray := transposeMul(rm, r3.Vector{X: x, Y: y, Z: 1})
+			hit := center.Add(ray.Mul((stereoPlaneDepth - center.Z) / ray.Z))
+			shade := uint8(planeTexture(hit.X, hit.Y))
+			img.SetXY(u, v, rimage.NewColor(shade, shade, shade))
+		}
+	}
+	return img
+}
+
+func TestStereoRectifier(t *testing.T) {
+	// the right camera is 60mm to the right, turned slightly in, and a little different
+	angle := 0.03
+	rotation := []float64{math.Cos(angle), 0, math.Sin(angle), 0, 1, 0, -math.Sin(angle), 0, math.Cos(angle)}
+	rm, err := spatialmath.NewRotationMatrix(rotation)
+	test.That(t, err, test.ShouldBeNil)
+	rightCenter := r3.Vector{X: 60}
+	translation := rm.Mul(rightCenter).Mul(-1)
+	cal := &StereoCalibration{
+		Left:            PinholeCameraIntrinsics{Width: 200, Height: 150, Fx: 180, Fy: 178, Ppx: 101, Ppy: 74},
+		LeftDistortion:  &BrownConrady{RadialK1: -0.08, RadialK2: 0.01},
+		Right:           PinholeCameraIntrinsics{Width: 200, Height: 150, Fx: 176, Fy: 177, Ppx: 98, Ppy: 76},
+		RightDistortion: &BrownConrady{RadialK1: -0.05, TangentialP1: 0.002},
+		Rotation:        rotation,
+		Translation:     []float64{translation.X, translation.Y, translation.Z},
+	}
+	sr, err := NewStereoRectifier(cal)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sr.Baseline(), test.ShouldAlmostEqual, 60, 1e-9)
+	test.That(t, sr.Intrinsics().Fx, test.ShouldEqual, sr.Intrinsics().Fy)
+
+	left := renderPlane(&cal.Left, cal.LeftDistortion, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}, r3.Vector{})
+	right := renderPlane(&cal.Right, cal.RightDistortion, rotation, rightCenter)
+	rectLeft, rectRight, err := sr.Rectify(left, right)
+	test.That(t, err, test.ShouldBeNil)
+
+	disparity, err := rimage.ComputeDisparity(rimage.MakeGray(rectLeft), rimage.MakeGray(rectRight), rimage.DisparityConfig{
+		Method:       rimage.SemiGlobalMatching,
+		MaxDisparity: 24,
+		BlockSize:    5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	dm, err := sr.DisparityToDepth(disparity)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the rectified left camera points almost the same way, so the plane is at almost the same depth
+	var depths []float64
+	for y := 20; y < 130; y++ {
+		for x := 40; x < 180; x++ {
+			if d := dm.GetDepth(x, y); d > 0 {
+				depths = append(depths, float64(d))
+			}
+		}
+	}
+	test.That(t, len(depths), test.ShouldBeGreaterThan, 110*140*8/10)
+	sort.Float64s(depths)
+	test.That(t, depths[len(depths)/2], test.ShouldAlmostEqual, stereoPlaneDepth, 30)
+
+	_, _, err = sr.Rectify(left, rimage.NewImage(10, 10))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = sr.DisparityToDepth(&rimage.DisparityMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	t.Run("bad calibration", func(t *testing.T) {
+		for _, modify := range []func(*StereoCalibration){
+			func(c *StereoCalibration) { c.Right.Width = 100 },
+			func(c *StereoCalibration) { c.Rotation = c.Rotation[:8] },
+			func(c *StereoCalibration) { c.Translation = []float64{0, 0, 0} },
+			// one camera in front of the other
+			func(c *StereoCalibration) { c.Translation = []float64{0, 0, -60} },
+		} {
+			bad := *cal
+			modify(&bad)
+			_, err := NewStereoRectifier(&bad)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,380 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aler9/gortsplib/v2"
+	"github.com/aler9/gortsplib/v2/pkg/base"
+	"github.com/aler9/gortsplib/v2/pkg/format"
+	"github.com/aler9/gortsplib/v2/pkg/headers"
+	"github.com/aler9/gortsplib/v2/pkg/liberrors"
+	"github.com/aler9/gortsplib/v2/pkg/media"
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"goji.io/pat"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/robot"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// cameraStreamRealm is the realm clients of the camera streams are asked to authenticate to.
+const cameraStreamRealm = "viam"
+
+// cameraStreamAuth checks the passwords that clients of the camera streams send against the
+// stream-only passwords of the config.
+type cameraStreamAuth struct {
+	// unauthenticated is set when the config lets anyone watch.
+	unauthenticated bool
+	passwords       [][]byte
+}
+
+func newCameraStreamAuth(conf config.CameraStreamsConfig) cameraStreamAuth {
+	csa := cameraStreamAuth{unauthenticated: conf.Unauthenticated}
+	for _, password := range conf.Passwords {
+		csa.passwords = append(csa.passwords, []byte(password))
+	}
+	return csa
+}
+
+// check reports whether a client sending the password, if it sent any, may watch.
+func (csa cameraStreamAuth) check(password string, ok bool) bool {
+	if csa.unauthenticated {
+		return true
+	}
+	if !ok {
+		return false
+	}
+	var matched bool
+	for _, expected := range csa.passwords {
+		if subtle.ConstantTimeCompare(expected, []byte(password)) == 1 {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// frameInterval is the time between published frames.
+func frameInterval(conf config.CameraStreamsConfig) time.Duration {
+	fps := conf.FPS
+	if fps <= 0 {
+		fps = config.DefaultCameraStreamFPS
+	}
+	return time.Duration(float64(time.Second) / fps)
+}
+
+// mjpegHandler serves the published cameras as motion JPEG over HTTP, which browsers and most NVRs
+// can show without any plugins.
+type mjpegHandler struct {
+	// ctx is done when the web service stops, so that streams don't hold up shutting down.
+	ctx      context.Context
+	r        robot.Robot
+	cameras  map[string]bool
+	auth     cameraStreamAuth
+	interval time.Duration
+	logger   golog.Logger
+}
+
+func (h *mjpegHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, password, ok := r.BasicAuth()
+	if !h.auth.check(password, ok) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="`+cameraStreamRealm+`"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	name := pat.Param(r, "name")
+	if !h.cameras[name] {
+		http.NotFound(w, r)
+		return
+	}
+	cam, err := camera.FromRobot(h.r, name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := utils.MergeContext(h.ctx, r.Context())
+	defer cancel()
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mw.Boundary())
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		data, err := readJPEG(ctx, cam, false)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Debugw("error reading mjpeg frame", "camera", name, "error", err)
+			}
+			return
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":   []string{rutils.MimeTypeJPEG},
+			"Content-Length": []string{strconv.Itoa(len(data))},
+		})
+		if err != nil {
+			return
+		}
+		if _, err := part.Write(data); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readJPEG reads an image from the camera and encodes it as a JPEG. RTP can only carry baseline
+// JPEGs with chroma subsampling that are a multiple of 8 pixels in each direction, so for RTSP the
+// image is cropped and grayscale images are converted to color.
+func readJPEG(ctx context.Context, cam camera.Camera, forRTP bool) ([]byte, error) {
+	img, release, err := camera.ReadImage(ctx, cam)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if !forRTP {
+		return rimage.EncodeImage(ctx, img, rutils.MimeTypeJPEG)
+	}
+
+	bounds := img.Bounds()
+	cropped := image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+bounds.Dx()/8*8, bounds.Min.Y+bounds.Dy()/8*8)
+	if cropped.Empty() {
+		return nil, errors.Errorf("image of size %v is too small to stream", bounds.Size())
+	}
+	if _, ok := img.(*image.YCbCr); !ok || cropped != bounds {
+		rgba := image.NewRGBA(image.Rect(0, 0, cropped.Dx(), cropped.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), img, cropped.Min, draw.Src)
+		img = rgba
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rtspStream is the stream of one camera published over RTSP.
+type rtspStream struct {
+	name   string
+	stream *gortsplib.ServerStream
+	media  *media.Media
+	format *format.MJPEG
+	// readers are the sessions playing the stream. Frames are only read while there are any.
+	readers map[*gortsplib.ServerSession]struct{}
+}
+
+// rtspServer publishes the cameras over RTSP as motion JPEG, for NVRs and other tools that only
+// take RTSP. Clients authenticate with basic auth, like the MJPEG handler.
+type rtspServer struct {
+	server   *gortsplib.Server
+	r        robot.Robot
+	auth     cameraStreamAuth
+	interval time.Duration
+	logger   golog.Logger
+
+	mu      sync.Mutex
+	streams map[string]*rtspStream
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newRTSPServer(
+	r robot.Robot,
+	conf config.CameraStreamsConfig,
+	auth cameraStreamAuth,
+	logger golog.Logger,
+) *rtspServer {
+	rs := &rtspServer{
+		r:        r,
+		auth:     auth,
+		interval: frameInterval(conf),
+		logger:   logger,
+		streams:  make(map[string]*rtspStream, len(conf.Cameras)),
+	}
+	for _, name := range conf.Cameras {
+		forma := &format.MJPEG{}
+		medi := &media.Media{Type: media.TypeVideo, Formats: []format.Format{forma}}
+		rs.streams[name] = &rtspStream{
+			name:    name,
+			stream:  gortsplib.NewServerStream(media.Medias{medi}),
+			media:   medi,
+			format:  forma,
+			readers: map[*gortsplib.ServerSession]struct{}{},
+		}
+	}
+	rs.server = &gortsplib.Server{Handler: rs, RTSPAddress: conf.RTSPBindAddress}
+	return rs
+}
+
+// Start listens for clients and starts publishing frames.
+func (rs *rtspServer) Start(ctx context.Context) error {
+	if err := rs.server.Start(); err != nil {
+		return errors.Wrap(err, "error starting rtsp server")
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	rs.cancel = cancel
+	for _, st := range rs.streams {
+		st := st
+		rs.activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			rs.publish(cancelCtx, st)
+		}, rs.activeBackgroundWorkers.Done)
+	}
+	return nil
+}
+
+// Close stops publishing and disconnects all clients.
+func (rs *rtspServer) Close() error {
+	if rs.cancel != nil {
+		rs.cancel()
+	}
+	rs.activeBackgroundWorkers.Wait()
+	err := rs.server.Close()
+	if errors.Is(err, liberrors.ErrServerTerminated{}) {
+		err = nil
+	}
+	for _, st := range rs.streams {
+		err = multierr.Combine(err, st.stream.Close())
+	}
+	return err
+}
+
+func (rs *rtspServer) hasReaders(st *rtspStream) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return len(st.readers) != 0
+}
+
+// publish reads frames from the camera while the stream has readers and writes them to them.
+func (rs *rtspServer) publish(ctx context.Context, st *rtspStream) {
+	enc := st.format.CreateEncoder()
+	start := time.Now()
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !rs.hasReaders(st) {
+			continue
+		}
+		cam, err := camera.FromRobot(rs.r, st.name)
+		if err != nil {
+			rs.logger.Debugw("cannot find camera to publish over rtsp", "camera", st.name, "error", err)
+			continue
+		}
+		data, err := readJPEG(ctx, cam, true)
+		if err != nil {
+			if ctx.Err() == nil {
+				rs.logger.Debugw("error reading rtsp frame", "camera", st.name, "error", err)
+			}
+			continue
+		}
+		pkts, err := enc.Encode(data, time.Since(start))
+		if err != nil {
+			rs.logger.Debugw("error encoding rtsp frame", "camera", st.name, "error", err)
+			continue
+		}
+		for _, pkt := range pkts {
+			st.stream.WritePacketRTP(st.media, pkt)
+		}
+	}
+}
+
+// authorize returns the response refusing the request, if its credentials are not accepted.
+func (rs *rtspServer) authorize(req *base.Request) *base.Response {
+	var auth headers.Authorization
+	err := auth.Unmarshal(req.Header["Authorization"])
+	ok := err == nil && auth.Method == headers.AuthBasic
+	if rs.auth.check(auth.BasicPass, ok) {
+		return nil
+	}
+	realm := cameraStreamRealm
+	return &base.Response{
+		StatusCode: base.StatusUnauthorized,
+		Header: base.Header{
+			"WWW-Authenticate": headers.Authenticate{Method: headers.AuthBasic, Realm: &realm}.Marshal(),
+		},
+	}
+}
+
+func (rs *rtspServer) lookup(path string) *rtspStream {
+	return rs.streams[strings.Trim(path, "/")]
+}
+
+// OnDescribe authorizes the client and describes the camera's stream.
+func (rs *rtspServer) OnDescribe(ctx *gortsplib.ServerHandlerOnDescribeCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	if res := rs.authorize(ctx.Request); res != nil {
+		return res, nil, nil
+	}
+	st := rs.lookup(ctx.Path)
+	if st == nil {
+		return &base.Response{StatusCode: base.StatusNotFound}, nil, nil
+	}
+	return &base.Response{StatusCode: base.StatusOK}, st.stream, nil
+}
+
+// OnSetup authorizes the client and sets it up to read the camera's stream.
+func (rs *rtspServer) OnSetup(ctx *gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	if res := rs.authorize(ctx.Request); res != nil {
+		return res, nil, nil
+	}
+	st := rs.lookup(ctx.Path)
+	if st == nil {
+		return &base.Response{StatusCode: base.StatusNotFound}, nil, nil
+	}
+	ctx.Session.SetUserData(st)
+	return &base.Response{StatusCode: base.StatusOK}, st.stream, nil
+}
+
+// OnPlay starts reading frames for the client.
+func (rs *rtspServer) OnPlay(ctx *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+	if res := rs.authorize(ctx.Request); res != nil {
+		return res, nil
+	}
+	st, ok := ctx.Session.UserData().(*rtspStream)
+	if !ok {
+		return &base.Response{StatusCode: base.StatusBadRequest}, nil
+	}
+	rs.mu.Lock()
+	st.readers[ctx.Session] = struct{}{}
+	rs.mu.Unlock()
+	return &base.Response{StatusCode: base.StatusOK}, nil
+}
+
+// OnSessionClose stops reading frames for the client once no one else is watching.
+func (rs *rtspServer) OnSessionClose(ctx *gortsplib.ServerHandlerOnSessionCloseCtx) {
+	st, ok := ctx.Session.UserData().(*rtspStream)
+	if !ok {
+		return
+	}
+	rs.mu.Lock()
+	delete(st.readers, ctx.Session)
+	rs.mu.Unlock()
+}
@@ -0,0 +1,156 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/aler9/gortsplib/v2"
+	"github.com/aler9/gortsplib/v2/pkg/url"
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/rtsp"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/robot/web"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/robottestutils"
+)
+
+func TestWebCameraStreams(t *testing.T) {
+	const (
+		camera1Key = "camera1"
+		camera2Key = "camera2"
+		apiKey     = "sosecret"
+		password   = "streamsecret"
+	)
+
+	// an image that is not a multiple of 8 pixels in size, so it is cropped for RTSP
+	img := rimage.NewImage(60, 44)
+	newCamera := func() *inject.Camera {
+		cam := &inject.Camera{}
+		cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+			return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(
+				func(ctx context.Context) (image.Image, func(), error) {
+					return img, func() {}, nil
+				},
+			)), nil
+		}
+		return cam
+	}
+	robot := &inject.Robot{}
+	robot.MockResourcesFromMap(map[resource.Name]interface{}{
+		camera.Named(camera1Key): newCamera(),
+		camera.Named(camera2Key): newCamera(),
+	})
+
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	robot.LoggerFunc = func() golog.Logger { return logger }
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{Type: rpc.CredentialsTypeAPIKey, Config: config.AttributeMap{"key": apiKey}},
+	}
+	rtspPort, err := utils.TryReserveRandomPort()
+	test.That(t, err, test.ShouldBeNil)
+	rtspAddr := fmt.Sprintf("localhost:%d", rtspPort)
+	options.Network.CameraStreams = config.CameraStreamsConfig{
+		Cameras:         []string{camera1Key},
+		MJPEG:           true,
+		RTSPBindAddress: rtspAddr,
+		FPS:             20,
+		Passwords:       []string{password},
+	}
+	svc := web.New(ctx, robot, logger)
+	err = svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("mjpeg", func(t *testing.T) {
+		get := func(name, password string) *http.Response {
+			t.Helper()
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/stream/mjpeg/%s", addr, name), nil)
+			test.That(t, err, test.ShouldBeNil)
+			if password != "" {
+				req.SetBasicAuth("nvr", password)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			test.That(t, err, test.ShouldBeNil)
+			return resp
+		}
+
+		resp := get(camera1Key, "")
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, resp.Header.Get("WWW-Authenticate"), test.ShouldContainSubstring, "Basic")
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		resp = get(camera1Key, "wrong")
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		// the robot's own keys do not grant watching
+		resp = get(camera1Key, apiKey)
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+		// only configured cameras are published
+		resp = get(camera2Key, password)
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusNotFound)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+		resp = get(camera1Key, password)
+		defer func() {
+			test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		}()
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+		mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mediaType, test.ShouldEqual, "multipart/x-mixed-replace")
+		parts := multipart.NewReader(resp.Body, params["boundary"])
+		for i := 0; i < 2; i++ {
+			part, err := parts.NextPart()
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, part.Header.Get("Content-Type"), test.ShouldEqual, "image/jpeg")
+			frame, err := jpeg.Decode(part)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, frame.Bounds(), test.ShouldResemble, image.Rect(0, 0, 60, 44))
+		}
+	})
+
+	t.Run("rtsp", func(t *testing.T) {
+		u, err := url.Parse(fmt.Sprintf("rtsp://%s/%s", rtspAddr, camera1Key))
+		test.That(t, err, test.ShouldBeNil)
+		client := &gortsplib.Client{}
+		test.That(t, client.Start(u.Scheme, u.Host), test.ShouldBeNil)
+		_, _, _, err = client.Describe(u)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "401")
+		utils.UncheckedError(client.Close())
+
+		_, err = rtsp.NewRTSPCamera(ctx, &rtsp.Attrs{
+			Address: fmt.Sprintf("rtsp://nvr:%s@%s/%s", apiKey, rtspAddr, camera1Key),
+		}, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// the RTSP camera can watch the published camera
+		rtspCam, err := rtsp.NewRTSPCamera(ctx, &rtsp.Attrs{
+			Address: fmt.Sprintf("rtsp://nvr:%s@%s/%s", password, rtspAddr, camera1Key),
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		frame, release, err := camera.ReadImage(ctx, rtspCam)
+		test.That(t, err, test.ShouldBeNil)
+		release()
+		test.That(t, frame.Bounds(), test.ShouldResemble, image.Rect(0, 0, 56, 40))
+		test.That(t, rtspCam.Close(ctx), test.ShouldBeNil)
+	})
+
+	test.That(t, svc.Close(), test.ShouldBeNil)
+}
@@ -0,0 +1,182 @@
+package webstream
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Quality is the quality a peer prefers a video stream to be sent at. A stream is sent at the
+// lowest quality that any of the peers watching it prefer or that their links can take.
+type Quality int
+
+// The qualities a peer can prefer.
+const (
+	// QualityAuto sends the stream at the best quality the link can take.
+	QualityAuto Quality = iota
+	// QualityLow sends the stream at a quarter of its size and 5 frames per second.
+	QualityLow
+	// QualityMedium sends the stream at half of its size and 15 frames per second.
+	QualityMedium
+	// QualityHigh sends the stream as it is.
+	QualityHigh
+)
+
+// String returns the name of the quality.
+func (q Quality) String() string {
+	switch q {
+	case QualityAuto:
+		return "auto"
+	case QualityLow:
+		return "low"
+	case QualityMedium:
+		return "medium"
+	case QualityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// QualityFromString returns the quality of the given name.
+func QualityFromString(name string) (Quality, error) {
+	for _, q := range []Quality{QualityAuto, QualityLow, QualityMedium, QualityHigh} {
+		if q.String() == name {
+			return q, nil
+		}
+	}
+	return QualityAuto, errors.Errorf("unknown stream quality %q", name)
+}
+
+// maxLevel returns the index of the highest level in qualityLevels the quality allows.
+func (q Quality) maxLevel() int {
+	if q == QualityAuto {
+		return len(qualityLevels) - 1
+	}
+	return int(q) - 1
+}
+
+// qualityLevel is how a video stream is sent. The bitrate a stream takes follows from its size and
+// frame rate, since the stream's encoder is made with a fixed bitrate it cannot be told to change.
+type qualityLevel struct {
+	// scale is the fraction of the video's size frames are sent at.
+	scale float64
+	// maxFrameRate is the most frames sent per second, or 0 for as many as the stream takes.
+	maxFrameRate float64
+}
+
+// qualityLevels are the levels streams are sent at, from the lowest to the highest, as indexed
+// by Quality.maxLevel.
+var qualityLevels = []qualityLevel{
+	{scale: 0.25, maxFrameRate: 5},
+	{scale: 0.5, maxFrameRate: 15},
+	{scale: 1},
+}
+
+const (
+	// lossToLower is the fraction of packets lost, as reported by a peer, above which its link is
+	// taken to be congested.
+	lossToLower = 0.1
+	// lossToRaise is the fraction of packets lost at or below which a link may be raised.
+	lossToRaise = 0.02
+	// rembToLower is the fraction of the bitrate a stream is sent at below which a peer's estimate
+	// of its maximum bitrate means its link is congested.
+	rembToLower = 0.85
+	// lowerInterval is the least time between lowering a link, so that the feedback shows the
+	// effect of lowering it before it is lowered again.
+	lowerInterval = time.Second
+	// raiseInterval is how long a link has to go without congestion before it is raised. It is
+	// doubled, up to maxRaiseInterval, every time a link is lowered soon after being raised so that
+	// a link that cannot take a higher level is not raised over and over.
+	raiseInterval    = 5 * time.Second
+	maxRaiseInterval = 2 * time.Minute
+)
+
+// peerLink is the link to a peer watching a stream, whose level follows the congestion the peer
+// reports. Since a peer's estimate of the bitrate its link can take does not go far above the
+// bitrate it receives, a link is raised to probe whether it can take the next level, and lowered
+// again if it cannot.
+type peerLink struct {
+	level     int
+	preferred Quality
+
+	// remb is the last estimate of the maximum bitrate the peer can receive, or 0 if there is none.
+	remb float64
+	// loss is the last fraction of packets the peer reported as lost.
+	loss float64
+
+	lastLowered   time.Time
+	lastRaised    time.Time
+	lastCongested time.Time
+	raiseWait     time.Duration
+}
+
+func newPeerLink() *peerLink {
+	return &peerLink{
+		level:     QualityAuto.maxLevel(),
+		preferred: QualityAuto,
+		raiseWait: raiseInterval,
+	}
+}
+
+// congested returns whether the link is congested, given the bitrate, in bits per second, the
+// stream is sent at or 0 if it is not known.
+func (l *peerLink) congested(sent float64) bool {
+	return l.loss > lossToLower || (l.remb > 0 && sent > 0 && l.remb < sent*rembToLower)
+}
+
+// update moves the link's level after new feedback or a new preference, and returns whether the
+// level changed.
+func (l *peerLink) update(now time.Time, sent float64) bool {
+	if maxLevel := l.preferred.maxLevel(); l.level > maxLevel {
+		l.level = maxLevel
+		return true
+	}
+	if l.congested(sent) {
+		l.lastCongested = now
+		if l.level == 0 || now.Sub(l.lastLowered) < lowerInterval {
+			return false
+		}
+		if !l.lastRaised.IsZero() && now.Sub(l.lastRaised) < l.raiseWait {
+			l.raiseWait *= 2
+			if l.raiseWait > maxRaiseInterval {
+				l.raiseWait = maxRaiseInterval
+			}
+		} else {
+			l.raiseWait = raiseInterval
+		}
+		l.level--
+		l.lastLowered = now
+		return true
+	}
+	if l.level >= l.preferred.maxLevel() || l.loss > lossToRaise ||
+		now.Sub(l.lastCongested) < l.raiseWait || now.Sub(l.lastRaised) < l.raiseWait {
+		return false
+	}
+	l.level++
+	l.lastRaised = now
+	return true
+}
+
+// meterWindow is how long a bitrateMeter counts bytes for before working out the bitrate.
+const meterWindow = time.Second
+
+// bitrateMeter measures the bitrate a stream is sent at.
+type bitrateMeter struct {
+	start   time.Time
+	bytes   int
+	bitrate float64
+}
+
+// add counts bytes sent at the given time.
+func (m *bitrateMeter) add(now time.Time, n int) {
+	if m.start.IsZero() {
+		m.start = now
+	}
+	m.bytes += n
+	if elapsed := now.Sub(m.start); elapsed >= meterWindow {
+		m.bitrate = float64(m.bytes*8) / elapsed.Seconds()
+		m.start = now
+		m.bytes = 0
+	}
+}
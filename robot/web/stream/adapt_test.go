@@ -0,0 +1,254 @@
+package webstream
+
+import (
+	"context"
+	"image"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/edaniels/gostream/codec"
+	"github.com/pion/interceptor"
+	"github.com/pion/mediadevices/pkg/prop"
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/rimage/h264"
+)
+
+func TestQualityFromString(t *testing.T) {
+	for _, q := range []Quality{QualityAuto, QualityLow, QualityMedium, QualityHigh} {
+		parsed, err := QualityFromString(q.String())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, parsed, test.ShouldEqual, q)
+	}
+	_, err := QualityFromString("ultra")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown stream quality")
+}
+
+func TestPeerLink(t *testing.T) {
+	start := time.Now()
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+	const sent = 1_000_000
+
+	link := newPeerLink()
+	top := len(qualityLevels) - 1
+	test.That(t, link.level, test.ShouldEqual, top)
+	test.That(t, link.update(at(0), sent), test.ShouldBeFalse)
+
+	// loss lowers the link, but not again until the feedback can show the effect
+	link.loss = 0.2
+	test.That(t, link.update(at(0), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, top-1)
+	test.That(t, link.update(at(lowerInterval/2), sent), test.ShouldBeFalse)
+	test.That(t, link.update(at(lowerInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, top-2)
+	test.That(t, link.update(at(3*lowerInterval), sent), test.ShouldBeFalse)
+	test.That(t, link.level, test.ShouldEqual, 0)
+
+	// the link is raised once it has been clear for long enough
+	link.loss = 0.01
+	last := 3 * lowerInterval
+	test.That(t, link.update(at(last+raiseInterval/2), sent), test.ShouldBeFalse)
+	test.That(t, link.update(at(last+raiseInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, 1)
+
+	// an estimate below what is sent lowers it again, and it waits longer before trying again
+	last += raiseInterval
+	link.remb = sent / 2
+	test.That(t, link.update(at(last+lowerInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, 0)
+	test.That(t, link.raiseWait, test.ShouldEqual, 2*raiseInterval)
+	last += lowerInterval
+	link.remb = sent
+	test.That(t, link.update(at(last+raiseInterval), sent), test.ShouldBeFalse)
+	test.That(t, link.update(at(last+2*raiseInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, 1)
+
+	// loss that is not low enough keeps it from being raised, without lowering it
+	last += 2 * raiseInterval
+	link.loss = 0.05
+	test.That(t, link.update(at(last+maxRaiseInterval), sent), test.ShouldBeFalse)
+	test.That(t, link.level, test.ShouldEqual, 1)
+
+	// the preferred quality caps the link
+	link.loss = 0
+	link.preferred = QualityLow
+	test.That(t, link.update(at(last+maxRaiseInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, 0)
+	test.That(t, link.update(at(last+2*maxRaiseInterval), sent), test.ShouldBeFalse)
+	link.preferred = QualityMedium
+	test.That(t, link.update(at(last+2*maxRaiseInterval), sent), test.ShouldBeTrue)
+	test.That(t, link.level, test.ShouldEqual, 1)
+	test.That(t, link.update(at(last+3*maxRaiseInterval), sent), test.ShouldBeFalse)
+}
+
+func TestBitrateMeter(t *testing.T) {
+	var meter bitrateMeter
+	start := time.Now()
+	meter.add(start, 1000)
+	meter.add(start.Add(meterWindow/2), 1000)
+	test.That(t, meter.bitrate, test.ShouldEqual, 0)
+	meter.add(start.Add(meterWindow), 500)
+	test.That(t, meter.bitrate, test.ShouldEqual, float64(2500*8)/meterWindow.Seconds())
+}
+
+type fakeRTCPReader struct {
+	pkts chan []rtcp.Packet
+}
+
+func (r *fakeRTCPReader) ReadRTCP() ([]rtcp.Packet, interceptor.Attributes, error) {
+	pkts, ok := <-r.pkts
+	if !ok {
+		return nil, nil, io.EOF
+	}
+	return pkts, nil, nil
+}
+
+type fakeEncoderFactory struct{}
+
+func (fakeEncoderFactory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	return fakeEncoder{}, nil
+}
+
+func (fakeEncoderFactory) MIMEType() string {
+	return "video/H264"
+}
+
+type fakeEncoder struct{}
+
+func (fakeEncoder) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	return make([]byte, 100), nil
+}
+
+func TestAdapter(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	adapter := NewAdapter(logger)
+	const name = "camera"
+
+	factory := adapter.EncoderFactory(name, fakeEncoderFactory{})
+	test.That(t, factory.MIMEType(), test.ShouldEqual, "video/H264")
+	stream, err := gostream.NewStream(gostream.StreamConfig{Name: name, VideoEncoderFactory: factory})
+	test.That(t, err, test.ShouldBeNil)
+
+	source := adapter.VideoSource(stream, gostream.NewVideoSource(gostream.VideoReaderFunc(
+		func(ctx context.Context) (image.Image, func(), error) {
+			return image.NewRGBA(image.Rect(0, 0, 64, 48)), func() {}, nil
+		},
+	), prop.Video{}))
+	defer func() {
+		test.That(t, source.Close(ctx), test.ShouldBeNil)
+	}()
+	bounds := func() image.Rectangle {
+		t.Helper()
+		img, release, err := gostream.ReadImage(ctx, source)
+		test.That(t, err, test.ShouldBeNil)
+		release()
+		return img.Bounds()
+	}
+	test.That(t, bounds(), test.ShouldResemble, image.Rect(0, 0, 64, 48))
+
+	// what the stream sends is measured
+	encoder, err := factory.New(64, 48, 30, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = encoder.Encode(ctx, image.NewRGBA(image.Rect(0, 0, 64, 48)))
+	test.That(t, err, test.ShouldBeNil)
+	adapter.mu.Lock()
+	test.That(t, adapter.streams[name].sent.bytes, test.ShouldEqual, 100)
+	adapter.mu.Unlock()
+
+	pc1, pc2 := &webrtc.PeerConnection{}, &webrtc.PeerConnection{}
+	err = adapter.SetQuality(pc1, name, QualityLow)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not active")
+	err = adapter.SetQuality(pc1, "other", QualityLow)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no video stream")
+
+	reader1 := &fakeRTCPReader{make(chan []rtcp.Packet)}
+	reader2 := &fakeRTCPReader{make(chan []rtcp.Packet)}
+	adapter.watchLink(pc1, name, reader1)
+	adapter.watchLink(pc2, name, reader2)
+
+	// the stream follows its most congested peer
+	reader1.pkts <- []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{FractionLost: 64}}}}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, adapter.level(name), test.ShouldResemble, qualityLevels[1])
+	})
+	test.That(t, bounds(), test.ShouldResemble, image.Rect(0, 0, 32, 24))
+
+	test.That(t, adapter.SetQuality(pc2, name, QualityLow), test.ShouldBeNil)
+	test.That(t, adapter.level(name), test.ShouldResemble, qualityLevels[0])
+	test.That(t, bounds(), test.ShouldResemble, image.Rect(0, 0, 16, 12))
+
+	// frames are dropped down to the frame rate of the level
+	start := time.Now()
+	bounds()
+	test.That(t, time.Since(start), test.ShouldBeGreaterThan, time.Second/10)
+
+	// peers that stop watching no longer hold the stream back
+	close(reader2.pkts)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, adapter.level(name), test.ShouldResemble, qualityLevels[1])
+	})
+	close(reader1.pkts)
+	adapter.Close()
+	test.That(t, adapter.level(name), test.ShouldResemble, qualityLevels[len(qualityLevels)-1])
+}
+
+func TestAdapterPassthrough(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	adapter := NewAdapter(logger)
+	defer adapter.Close()
+	const name = "camera"
+
+	factory := adapter.EncoderFactory(name, fakeEncoderFactory{})
+	stream, err := gostream.NewStream(gostream.StreamConfig{Name: name, VideoEncoderFactory: factory})
+	test.That(t, err, test.ShouldBeNil)
+
+	var seq h264.Sequence
+	sps := []byte{
+		0x67, 0x64, 0x00, 0x0c, 0xac, 0x3b, 0x50, 0xb0, 0x4b, 0x42, 0x00, 0x00,
+		0x03, 0x00, 0x02, 0x00, 0x00, 0x03, 0x00, 0x3d, 0x08,
+	}
+	pps := []byte{0x68, 0xee, 0x3c, 0x80}
+	frame, err := seq.Add([][]byte{sps, pps, {0x65, 0x88, 0x80}})
+	test.That(t, err, test.ShouldBeNil)
+	source := adapter.VideoSource(stream, gostream.NewVideoSource(gostream.VideoReaderFunc(
+		func(ctx context.Context) (image.Image, func(), error) {
+			return frame, func() {}, nil
+		},
+	), prop.Video{}))
+	defer func() {
+		test.That(t, source.Close(ctx), test.ShouldBeNil)
+	}()
+
+	pc := &webrtc.PeerConnection{}
+	reader := &fakeRTCPReader{make(chan []rtcp.Packet)}
+	defer close(reader.pkts)
+	adapter.watchLink(pc, name, reader)
+	test.That(t, adapter.SetQuality(pc, name, QualityLow), test.ShouldBeNil)
+	test.That(t, adapter.level(name), test.ShouldResemble, qualityLevels[0])
+
+	// frames passed through are neither decoded to be scaled nor dropped at a lowered level
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		img, release, err := gostream.ReadImage(ctx, source)
+		test.That(t, err, test.ShouldBeNil)
+		release()
+		test.That(t, img, test.ShouldEqual, frame)
+	}
+	interval := time.Duration(float64(time.Second) / qualityLevels[0].maxFrameRate)
+	test.That(t, time.Since(start), test.ShouldBeLessThan, interval)
+}
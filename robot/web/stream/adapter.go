@@ -0,0 +1,362 @@
+package webstream
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/edaniels/gostream/codec"
+	streampb "github.com/edaniels/gostream/proto/stream/v1"
+	"github.com/pion/interceptor"
+	"github.com/pion/mediadevices/pkg/prop"
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+	"golang.org/x/image/draw"
+
+	"go.viam.com/rdk/rimage/h264"
+)
+
+// An Adapter adapts the video of streams to the links of the peers watching them, lowering the
+// size and frame rate of a stream when a peer's RTCP feedback shows its link is congested and
+// raising them again once it clears, and to the quality the peers prefer.
+type Adapter struct {
+	mu      sync.Mutex
+	streams map[string]*adaptiveStream
+
+	logger                  golog.Logger
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// adaptiveStream is a video stream and the links of the peers watching it.
+type adaptiveStream struct {
+	name  string
+	track webrtc.TrackLocal
+	sent  bitrateMeter
+	links map[*webrtc.PeerConnection]*peerLink
+	level int
+	// h264 is set when the stream is encoded as H.264, so that frames of cameras that already
+	// encode H.264 are passed through as they are.
+	h264 bool
+}
+
+// NewAdapter returns an adapter with no streams.
+func NewAdapter(logger golog.Logger) *Adapter {
+	return &Adapter{streams: map[string]*adaptiveStream{}, logger: logger}
+}
+
+// stream returns the named stream, adding it if it is new. The adapter must be locked.
+func (a *Adapter) stream(name string) *adaptiveStream {
+	s, ok := a.streams[name]
+	if !ok {
+		s = &adaptiveStream{
+			name:  name,
+			links: map[*webrtc.PeerConnection]*peerLink{},
+			level: QualityAuto.maxLevel(),
+		}
+		a.streams[name] = s
+	}
+	return s
+}
+
+// level returns the level the named stream is sent at.
+func (a *Adapter) level(name string) qualityLevel {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return qualityLevels[a.stream(name).level]
+}
+
+// update updates the level of the link and then that of its stream, which is the lowest level
+// of its links. The adapter must be locked.
+func (a *Adapter) update(s *adaptiveStream, link *peerLink) {
+	now := time.Now()
+	if link != nil && !link.update(now, s.sent.bitrate) {
+		return
+	}
+	level := QualityAuto.maxLevel()
+	for _, link := range s.links {
+		if link.level < level {
+			level = link.level
+		}
+	}
+	if level != s.level {
+		a.logger.Infow(
+			"adapting stream to its peers",
+			"name", s.name,
+			"scale", qualityLevels[level].scale,
+			"max_frame_rate", qualityLevels[level].maxFrameRate,
+			"bitrate", s.sent.bitrate,
+		)
+		s.level = level
+	}
+}
+
+// passesThrough returns whether the named stream's frame, from its source, is sent as it is.
+func (a *Adapter) passesThrough(name string, img image.Image) bool {
+	if _, ok := img.(*h264.Frame); !ok {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stream(name).h264
+}
+
+// EncoderFactory wraps the factory of the named stream's encoder to measure the bitrate the
+// stream is sent at, which a peer's estimate of the bitrate it can receive is compared with.
+func (a *Adapter) EncoderFactory(name string, factory codec.VideoEncoderFactory) codec.VideoEncoderFactory {
+	if factory == nil {
+		return nil
+	}
+	a.mu.Lock()
+	a.stream(name).h264 = factory.MIMEType() == h264.MIMEType
+	a.mu.Unlock()
+	return &meteredEncoderFactory{factory, a, name}
+}
+
+type meteredEncoderFactory struct {
+	codec.VideoEncoderFactory
+	adapter *Adapter
+	name    string
+}
+
+func (f *meteredEncoderFactory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	encoder, err := f.VideoEncoderFactory.New(width, height, keyFrameInterval, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &meteredEncoder{encoder, f.adapter, f.name}, nil
+}
+
+type meteredEncoder struct {
+	codec.VideoEncoder
+	adapter *Adapter
+	name    string
+}
+
+func (e *meteredEncoder) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	data, err := e.VideoEncoder.Encode(ctx, img)
+	if err == nil && len(data) != 0 {
+		e.adapter.mu.Lock()
+		e.adapter.stream(e.name).sent.add(time.Now(), len(data))
+		e.adapter.mu.Unlock()
+	}
+	return data, err
+}
+
+// VideoSource returns the source of the stream's video, with frames scaled down and dropped
+// to the level the stream is sent at. H.264 frames of cameras that already encode their video
+// are not adapted when the stream is H.264 too, since they are passed through as they are:
+// scaling them would mean decoding and encoding them again, just when the robot should do less,
+// and dropping them saves nothing, as the frames in between are sent along with the next one.
+func (a *Adapter) VideoSource(stream gostream.Stream, source gostream.VideoSource) gostream.VideoSource {
+	name := stream.Name()
+	if track, ok := stream.VideoTrackLocal(); ok {
+		a.mu.Lock()
+		a.stream(name).track = track
+		a.mu.Unlock()
+	}
+	reader := &adaptiveReader{
+		adapter: a,
+		name:    name,
+		stream:  gostream.NewEmbeddedVideoStream(source),
+	}
+	return gostream.NewVideoSource(reader, prop.Video{})
+}
+
+type adaptiveReader struct {
+	adapter   *Adapter
+	name      string
+	stream    gostream.VideoStream
+	lastFrame time.Time
+	// passthrough is set when the last frame was passed through, so the next one likely is too.
+	passthrough bool
+}
+
+func (r *adaptiveReader) Read(ctx context.Context) (image.Image, func(), error) {
+	level := r.adapter.level(r.name)
+	if level.maxFrameRate > 0 && !r.passthrough {
+		interval := time.Duration(float64(time.Second) / level.maxFrameRate)
+		if wait := time.Until(r.lastFrame.Add(interval)); wait > 0 {
+			if !utils.SelectContextOrWait(ctx, wait) {
+				return nil, nil, ctx.Err()
+			}
+		}
+	}
+	img, release, err := r.stream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.lastFrame = time.Now()
+	r.passthrough = r.adapter.passesThrough(r.name, img)
+	if level.scale >= 1 || r.passthrough {
+		return img, release, nil
+	}
+	if release != nil {
+		defer release()
+	}
+	return scaleImage(img, level.scale), func() {}, nil
+}
+
+func (r *adaptiveReader) Close(ctx context.Context) error {
+	return r.stream.Close(ctx)
+}
+
+// scaleImage scales the image down by the given fraction, to an even size as encoders of 4:2:0
+// video need.
+func scaleImage(img image.Image, scale float64) image.Image {
+	bounds := img.Bounds()
+	width := int(float64(bounds.Dx())*scale) &^ 1
+	height := int(float64(bounds.Dy())*scale) &^ 1
+	if width < 2 {
+		width = 2
+	}
+	if height < 2 {
+		height = 2
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// ServiceServer wraps the stream service so that the RTCP feedback of the peers that add video
+// streams is read to adapt the streams to their links.
+func (a *Adapter) ServiceServer(server streampb.StreamServiceServer) streampb.StreamServiceServer {
+	return &adaptiveStreamServer{server, a}
+}
+
+type adaptiveStreamServer struct {
+	streampb.StreamServiceServer
+	adapter *Adapter
+}
+
+func (srv *adaptiveStreamServer) AddStream(ctx context.Context, req *streampb.AddStreamRequest) (*streampb.AddStreamResponse, error) {
+	resp, err := srv.StreamServiceServer.AddStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if pc, ok := rpc.ContextPeerConnection(ctx); ok {
+		srv.adapter.watchPeer(pc, req.Name)
+	}
+	return resp, nil
+}
+
+// watchPeer starts reading the feedback of the peer on the named stream's video track, once the
+// peer has added the stream.
+func (a *Adapter) watchPeer(pc *webrtc.PeerConnection, name string) {
+	a.mu.Lock()
+	s, ok := a.streams[name]
+	var track webrtc.TrackLocal
+	if ok {
+		track = s.track
+	}
+	a.mu.Unlock()
+	if track == nil {
+		return
+	}
+	for _, sender := range pc.GetSenders() {
+		if sender.Track() == track {
+			a.watchLink(pc, name, sender)
+			return
+		}
+	}
+}
+
+// rtcpReader reads the RTCP packets a peer sends about a track, like a webrtc.RTPSender.
+type rtcpReader interface {
+	ReadRTCP() ([]rtcp.Packet, interceptor.Attributes, error)
+}
+
+// watchLink adds the link to the peer and reads its feedback until the track stops being sent,
+// when the link is removed.
+func (a *Adapter) watchLink(pc *webrtc.PeerConnection, name string, reader rtcpReader) {
+	link := newPeerLink()
+	a.mu.Lock()
+	s := a.stream(name)
+	s.links[pc] = link
+	a.update(s, nil)
+	a.mu.Unlock()
+
+	a.activeBackgroundWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer a.activeBackgroundWorkers.Done()
+		defer func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			if s.links[pc] == link {
+				delete(s.links, pc)
+				a.update(s, nil)
+			}
+		}()
+		for {
+			pkts, _, err := reader.ReadRTCP()
+			if err != nil {
+				return
+			}
+			a.mu.Lock()
+			if feedback(link, pkts) {
+				a.update(s, link)
+			}
+			a.mu.Unlock()
+		}
+	})
+}
+
+// feedback stores the congestion that the packets report in the link, and returns whether they
+// reported any.
+func feedback(link *peerLink, pkts []rtcp.Packet) bool {
+	var reported bool
+	for _, pkt := range pkts {
+		switch pkt := pkt.(type) {
+		case *rtcp.ReceiverEstimatedMaximumBitrate:
+			link.remb = float64(pkt.Bitrate)
+			reported = true
+		case *rtcp.ReceiverReport:
+			if len(pkt.Reports) == 0 {
+				continue
+			}
+			var loss float64
+			for _, report := range pkt.Reports {
+				if fraction := float64(report.FractionLost) / 256; fraction > loss {
+					loss = fraction
+				}
+			}
+			link.loss = loss
+			reported = true
+		}
+	}
+	return reported
+}
+
+// SetQuality sets the quality the peer prefers the named stream at, which the peer's link is set
+// to and only adapted down from. The peer has to have added the stream. Streams of frames that are
+// passed through are sent as they are at any quality.
+func (a *Adapter) SetQuality(pc *webrtc.PeerConnection, name string, quality Quality) error {
+	if quality.String() == "unknown" {
+		return errors.Errorf("unknown stream quality %d", quality)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.streams[name]
+	if !ok {
+		return errors.Errorf("no video stream for %q", name)
+	}
+	link, ok := s.links[pc]
+	if !ok {
+		return errors.Errorf("stream %q is not active", name)
+	}
+	link.preferred = quality
+	link.level = quality.maxLevel()
+	a.update(s, nil)
+	return nil
+}
+
+// Close waits for the feedback of peers to stop being read, which happens once their tracks
+// stop being sent or their connections are closed.
+func (a *Adapter) Close() {
+	a.activeBackgroundWorkers.Wait()
+}
@@ -0,0 +1,71 @@
+package webstream
+
+import (
+	"context"
+	"image"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream/codec"
+
+	"go.viam.com/rdk/rimage/h264"
+)
+
+// NewPassthroughEncoderFactory wraps the factory of the encoder of H.264 streams so that frames
+// from cameras that already encode their video as H.264 go into the stream as they are, rather
+// than being decoded and encoded again. Other images are encoded by the wrapped factory's encoder,
+// which is only made once one is seen. Factories for other codecs are returned as they are.
+//
+// The stream takes a frame at its target frame rate, so when a camera sends frames faster than
+// that, the ones in between are sent along with it.
+func NewPassthroughEncoderFactory(factory codec.VideoEncoderFactory) codec.VideoEncoderFactory {
+	if factory == nil || factory.MIMEType() != h264.MIMEType {
+		return factory
+	}
+	return &passthroughEncoderFactory{factory}
+}
+
+type passthroughEncoderFactory struct {
+	codec.VideoEncoderFactory
+}
+
+func (f *passthroughEncoderFactory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	return &passthroughEncoder{
+		factory:          f.VideoEncoderFactory,
+		width:            width,
+		height:           height,
+		keyFrameInterval: keyFrameInterval,
+		logger:           logger,
+	}, nil
+}
+
+type passthroughEncoder struct {
+	factory                         codec.VideoEncoderFactory
+	width, height, keyFrameInterval int
+	logger                          golog.Logger
+
+	encoder codec.VideoEncoder
+	// last is the last frame passed through, so that the next one carries on from it.
+	last *h264.Frame
+}
+
+func (e *passthroughEncoder) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	if frame, ok := img.(*h264.Frame); ok {
+		data, err := frame.AnnexBSince(e.last)
+		if err != nil || data == nil {
+			return nil, err
+		}
+		e.last = frame
+		return data, nil
+	}
+
+	// the next frame passed through has to start from its IDR frame
+	e.last = nil
+	if e.encoder == nil {
+		encoder, err := e.factory.New(e.width, e.height, e.keyFrameInterval, e.logger)
+		if err != nil {
+			return nil, err
+		}
+		e.encoder = encoder
+	}
+	return e.encoder.Encode(ctx, img)
+}
@@ -0,0 +1,98 @@
+package webstream_test
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	h264codec "github.com/aler9/gortsplib/v2/pkg/codecs/h264"
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream/codec"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/h264"
+	webstream "go.viam.com/rdk/robot/web/stream"
+)
+
+type fakeEncoderFactory struct {
+	mimeType string
+	made     int
+}
+
+func (f *fakeEncoderFactory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	f.made++
+	return fakeEncoder{}, nil
+}
+
+func (f *fakeEncoderFactory) MIMEType() string {
+	return f.mimeType
+}
+
+type fakeEncoder struct{}
+
+func (fakeEncoder) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	return []byte("encoded"), nil
+}
+
+func TestPassthroughEncoder(t *testing.T) {
+	vp8Factory := &fakeEncoderFactory{mimeType: "video/VP8"}
+	test.That(t, webstream.NewPassthroughEncoderFactory(vp8Factory), test.ShouldEqual, vp8Factory)
+
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	h264Factory := &fakeEncoderFactory{mimeType: h264.MIMEType}
+	factory := webstream.NewPassthroughEncoderFactory(h264Factory)
+	test.That(t, factory.MIMEType(), test.ShouldEqual, h264.MIMEType)
+	encoder, err := factory.New(352, 288, 30, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, h264Factory.made, test.ShouldEqual, 0)
+
+	sps := []byte{
+		0x67, 0x64, 0x00, 0x0c, 0xac, 0x3b, 0x50, 0xb0, 0x4b, 0x42, 0x00, 0x00,
+		0x03, 0x00, 0x02, 0x00, 0x00, 0x03, 0x00, 0x3d, 0x08,
+	}
+	pps := []byte{0x68, 0xee, 0x3c, 0x80}
+	idr := []byte{0x65, 0x88, 0x80}
+	nonIDR1 := []byte{0x41, 0x9a, 0x01, 0x80}
+	nonIDR2 := []byte{0x41, 0x9a, 0x02, 0x80}
+	annexB := func(nalus ...[]byte) []byte {
+		t.Helper()
+		data, err := h264codec.AnnexBMarshal(nalus)
+		test.That(t, err, test.ShouldBeNil)
+		return data
+	}
+
+	var seq h264.Sequence
+	frame1, err := seq.Add([][]byte{sps, pps, idr})
+	test.That(t, err, test.ShouldBeNil)
+	frame2, err := seq.Add([][]byte{nonIDR1})
+	test.That(t, err, test.ShouldBeNil)
+	frame3, err := seq.Add([][]byte{nonIDR2})
+	test.That(t, err, test.ShouldBeNil)
+
+	data, err := encoder.Encode(ctx, frame1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(sps, pps, idr))
+
+	// the same frame again is skipped
+	data, err = encoder.Encode(ctx, frame1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldBeNil)
+
+	// frames in between are sent along
+	data, err = encoder.Encode(ctx, frame3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(nonIDR1, nonIDR2))
+	data, err = encoder.Encode(ctx, frame2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldBeNil)
+
+	// other images are encoded, after which frames start from their IDR frame
+	data, err = encoder.Encode(ctx, image.NewRGBA(image.Rect(0, 0, 352, 288)))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte("encoded"))
+	test.That(t, h264Factory.made, test.ShouldEqual, 1)
+	data, err = encoder.Encode(ctx, frame3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, annexB(sps, pps, idr, nonIDR1, nonIDR2))
+}
@@ -0,0 +1,95 @@
+package webstream
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/protoutils"
+)
+
+// The stream service has no way for a peer to ask for a quality, so the gRPC service it is asked
+// with is described here. Requests and responses are google.protobuf.Struct messages with the
+// fields listed on each method below.
+const (
+	qualityProtoFileName    = "stream/v1/quality.proto"
+	qualityProtoServiceName = "viam.stream.v1.StreamQualityService"
+
+	setStreamQualityMethod = "/" + qualityProtoServiceName + "/SetStreamQuality"
+)
+
+// DescribeQualityService registers the descriptor of the stream quality gRPC service, which
+// has to be done before QualityServiceDesc is registered with a server.
+func DescribeQualityService() error {
+	return protoutils.RegisterServiceDescriptor(qualityProtoFileName, qualityProtoServiceName, []protoutils.MethodDescription{
+		{Name: "SetStreamQuality"},
+	})
+}
+
+// QualityServiceServer is the server API for the stream quality gRPC service.
+type QualityServiceServer interface {
+	// SetStreamQuality takes {"name", "quality"}, where quality is one of "auto", "low", "medium"
+	// and "high", and returns {}. It has to be called over the WebRTC connection of the peer
+	// watching the stream.
+	SetStreamQuality(context.Context, *structpb.Struct) (*structpb.Struct, error)
+}
+
+// QualityServiceDesc is the grpc.ServiceDesc for the stream quality gRPC service.
+var QualityServiceDesc = grpc.ServiceDesc{
+	ServiceName: qualityProtoServiceName,
+	HandlerType: (*QualityServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SetStreamQuality", Handler: setStreamQualityHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: qualityProtoFileName,
+}
+
+func setStreamQualityHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QualityServiceServer).SetStreamQuality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: setStreamQualityMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QualityServiceServer).SetStreamQuality(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SetStreamQuality sets the quality the calling peer prefers the named stream at.
+func (a *Adapter) SetStreamQuality(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	pc, ok := rpc.ContextPeerConnection(ctx)
+	if !ok {
+		return nil, errors.New("can only set the quality of a stream over a WebRTC based connection")
+	}
+	quality, err := QualityFromString(req.Fields["quality"].GetStringValue())
+	if err != nil {
+		return nil, err
+	}
+	if err := a.SetQuality(pc, req.Fields["name"].GetStringValue(), quality); err != nil {
+		return nil, err
+	}
+	return &structpb.Struct{}, nil
+}
+
+// SetStreamQuality asks the robot on the other end of the connection, which has to be a WebRTC
+// connection that the stream was added on, to send the named stream at the given quality.
+func SetStreamQuality(ctx context.Context, conn grpc.ClientConnInterface, name string, quality Quality) error {
+	req, err := structpb.NewStruct(map[string]interface{}{"name": name, "quality": quality.String()})
+	if err != nil {
+		return err
+	}
+	return conn.Invoke(ctx, setStreamQualityMethod, req, &structpb.Struct{})
+}
@@ -0,0 +1,18 @@
+package webstream
+
+import (
+	"testing"
+
+	"github.com/jhump/protoreflect/grpcreflect"
+	"go.viam.com/test"
+)
+
+func TestDescribeQualityService(t *testing.T) {
+	test.That(t, DescribeQualityService(), test.ShouldBeNil)
+	// every web service that starts describes it again
+	test.That(t, DescribeQualityService(), test.ShouldBeNil)
+
+	desc, err := grpcreflect.LoadServiceDescriptor(&QualityServiceDesc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, desc.FindMethodByName("SetStreamQuality"), test.ShouldNotBeNil)
+}
@@ -249,6 +249,10 @@ func New(ctx context.Context, r robot.Robot, logger golog.Logger, opts ...Option
 	for _, opt := range opts {
 		opt.apply(&wOpts)
 	}
+	if wOpts.streamConfig != nil {
+		// cameras that already encode H.264 are streamed without being re-encoded
+		wOpts.streamConfig.VideoEncoderFactory = webstream.NewPassthroughEncoderFactory(wOpts.streamConfig.VideoEncoderFactory)
+	}
 	webSvc := &webService{
 		r:            r,
 		logger:       logger,
@@ -266,10 +270,12 @@ type webService struct {
 	rpcServer    rpc.Server
 	modServer    rpc.Server
 	streamServer *StreamServer
-	services     map[resource.Subtype]subtype.Service
-	opts         options
-	addr         string
-	modAddr      string
+	// streamAdapter adapts the video streams to the links of the peers watching them.
+	streamAdapter *webstream.Adapter
+	services      map[resource.Subtype]subtype.Service
+	opts          options
+	addr          string
+	modAddr       string
 
 	logger                  golog.Logger
 	cancelFunc              func()
@@ -501,6 +507,7 @@ func (svc *webService) addNewStreams(ctx context.Context) error {
 		// Configure new stream
 		config := *svc.opts.streamConfig
 		config.Name = name
+		config.VideoEncoderFactory = svc.streamAdapter.EncoderFactory(name, config.VideoEncoderFactory)
 		stream, err := svc.streamServer.Server.NewStream(config)
 
 		// Skip if stream is already registered, otherwise raise any other errors
@@ -561,6 +568,7 @@ func (svc *webService) makeStreamServer(ctx context.Context) (*StreamServer, err
 		config.Name = name
 		if isVideo {
 			config.AudioEncoderFactory = nil
+			config.VideoEncoderFactory = svc.streamAdapter.EncoderFactory(name, config.VideoEncoderFactory)
 
 			if runtime.GOOS == "windows" {
 				// TODO(RSDK-1771): support video on windows
@@ -631,8 +639,12 @@ func (svc *webService) startStream(streamFunc func(opts *webstream.BackoffTuning
 
 func (svc *webService) startImageStream(ctx context.Context, source gostream.VideoSource, stream gostream.Stream) {
 	ctxWithJPEGHint := gostream.WithMIMETypeHint(ctx, rutils.WithLazyMIMEType(rutils.MimeTypeJPEG))
+	adaptedSource := svc.streamAdapter.VideoSource(stream, source)
 	svc.startStream(func(opts *webstream.BackoffTuningOptions) error {
-		return webstream.StreamVideoSource(ctxWithJPEGHint, source, stream, opts)
+		defer func() {
+			utils.UncheckedError(adaptedSource.Close(ctx))
+		}()
+		return webstream.StreamVideoSource(ctxWithJPEGHint, adaptedSource, stream, opts)
 	})
 }
 
@@ -730,6 +742,7 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		return err
 	}
 
+	svc.streamAdapter = webstream.NewAdapter(svc.logger)
 	svc.streamServer, err = svc.makeStreamServer(ctx)
 	if err != nil {
 		return err
@@ -737,11 +750,21 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 	if err := svc.rpcServer.RegisterServiceServer(
 		ctx,
 		&streampb.StreamService_ServiceDesc,
-		svc.streamServer.Server.ServiceServer(),
+		svc.streamAdapter.ServiceServer(svc.streamServer.Server.ServiceServer()),
 		streampb.RegisterStreamServiceHandlerFromEndpoint,
 	); err != nil {
 		return err
 	}
+	if err := webstream.DescribeQualityService(); err != nil {
+		return err
+	}
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&webstream.QualityServiceDesc,
+		svc.streamAdapter,
+	); err != nil {
+		return err
+	}
 	if svc.streamServer.HasStreams {
 		// force WebRTC template rendering
 		options.WebRTC = true
@@ -758,13 +781,19 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		}
 	}
 
-	httpServer, err := svc.initHTTPServer(listenerTCPAddr, options)
+	httpServer, err := svc.initHTTPServer(ctx, listenerTCPAddr, options)
+	if err != nil {
+		return err
+	}
+
+	rtspServer, err := svc.initRTSPServer(ctx, options)
 	if err != nil {
 		return err
 	}
 
 	// Serve
 
+	streamAdapter := svc.streamAdapter
 	svc.activeBackgroundWorkers.Add(1)
 	utils.PanicCapturingGo(func() {
 		defer svc.activeBackgroundWorkers.Done()
@@ -774,6 +803,16 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 				svc.logger.Errorw("error shutting down", "error", err)
 			}
 		}()
+		defer func() {
+			if rtspServer == nil {
+				return
+			}
+			if err := rtspServer.Close(); err != nil {
+				svc.logger.Errorw("error closing rtsp server", "error", err)
+			}
+		}()
+		// the feedback of peers stops being read once the rpc server closes their connections
+		defer streamAdapter.Close()
 		defer func() {
 			if err := svc.rpcServer.Stop(); err != nil {
 				svc.logger.Errorw("error stopping rpc server", "error", err)
@@ -1040,8 +1079,12 @@ func (svc *webService) initSubtypeServices(ctx context.Context, mod bool) error
 }
 
 // Initialize HTTP server.
-func (svc *webService) initHTTPServer(listenerTCPAddr *net.TCPAddr, options weboptions.Options) (*http.Server, error) {
-	mux, err := svc.initMux(options)
+func (svc *webService) initHTTPServer(
+	ctx context.Context,
+	listenerTCPAddr *net.TCPAddr,
+	options weboptions.Options,
+) (*http.Server, error) {
+	mux, err := svc.initMux(ctx, options)
 	if err != nil {
 		return nil, err
 	}
@@ -1059,8 +1102,25 @@ func (svc *webService) initHTTPServer(listenerTCPAddr *net.TCPAddr, options webo
 	return httpServer, nil
 }
 
+// Initialize the RTSP server re-streaming cameras, if one is configured.
+func (svc *webService) initRTSPServer(ctx context.Context, options weboptions.Options) (*rtspServer, error) {
+	streamsConf := options.Network.CameraStreams
+	if streamsConf.RTSPBindAddress == "" || len(streamsConf.Cameras) == 0 {
+		return nil, nil
+	}
+	rtspServer := newRTSPServer(svc.r, streamsConf, newCameraStreamAuth(streamsConf), svc.logger)
+	if err := rtspServer.Start(ctx); err != nil {
+		return nil, err
+	}
+	svc.logger.Infow("serving cameras over rtsp", "address", streamsConf.RTSPBindAddress, "cameras", streamsConf.Cameras)
+	if streamsConf.Unauthenticated {
+		svc.logger.Warnw("anyone who can reach the rtsp server can watch its cameras", "cameras", streamsConf.Cameras)
+	}
+	return rtspServer, nil
+}
+
 // Initialize multiplexer between http handlers.
-func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
+func (svc *webService) initMux(ctx context.Context, options weboptions.Options) (*goji.Mux, error) {
 	mux := goji.NewMux()
 	if err := svc.installWeb(mux, svc.r, options); err != nil {
 		return nil, err
@@ -1074,6 +1134,24 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 		mux.HandleFunc(pat.New("/debug/pprof/trace"), pprof.Trace)
 	}
 
+	if streamsConf := options.Network.CameraStreams; streamsConf.MJPEG && len(streamsConf.Cameras) != 0 {
+		if streamsConf.Unauthenticated {
+			svc.logger.Warnw("anyone who can reach the web server can watch its mjpeg cameras", "cameras", streamsConf.Cameras)
+		}
+		cameras := make(map[string]bool, len(streamsConf.Cameras))
+		for _, name := range streamsConf.Cameras {
+			cameras[name] = true
+		}
+		mux.Handle(pat.Get("/stream/mjpeg/:name"), &mjpegHandler{
+			ctx:      ctx,
+			r:        svc.r,
+			cameras:  cameras,
+			auth:     newCameraStreamAuth(streamsConf),
+			interval: frameInterval(streamsConf),
+			logger:   svc.logger,
+		})
+	}
+
 	prefix := "/viam"
 	addPrefix := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
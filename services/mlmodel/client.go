@@ -0,0 +1,79 @@
+// Package mlmodel contains a gRPC based ML model service client.
+package mlmodel
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	rprotoutils "go.viam.com/rdk/protoutils"
+)
+
+// client implements Service by calling the ML model gRPC service.
+type client struct {
+	name   string
+	conn   rpc.ClientConn
+	client *mlModelServiceClient
+	logger golog.Logger
+}
+
+// NewClientFromConn constructs a new Client from connection passed in.
+func NewClientFromConn(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) Service {
+	c := &client{
+		name:   name,
+		conn:   conn,
+		client: &mlModelServiceClient{cc: conn},
+		logger: logger,
+	}
+	return c
+}
+
+func (c *client) Infer(ctx context.Context, input Tensors, extra map[string]interface{}) (Tensors, error) {
+	rawInput, err := TensorsToMap(input)
+	if err != nil {
+		return nil, err
+	}
+	req, err := structpb.NewStruct(map[string]interface{}{
+		"name":          c.name,
+		"input_tensors": rawInput,
+		"extra":         extra,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Infer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rawOutput, ok := resp.AsMap()["output_tensors"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("infer response is missing output_tensors")
+	}
+	return TensorsFromMap(rawOutput)
+}
+
+func (c *client) Metadata(ctx context.Context, extra map[string]interface{}) (MLMetadata, error) {
+	req, err := structpb.NewStruct(map[string]interface{}{
+		"name":  c.name,
+		"extra": extra,
+	})
+	if err != nil {
+		return MLMetadata{}, err
+	}
+	resp, err := c.client.Metadata(ctx, req)
+	if err != nil {
+		return MLMetadata{}, err
+	}
+	rawMD, ok := resp.AsMap()["metadata"].(map[string]interface{})
+	if !ok {
+		return MLMetadata{}, errors.New("metadata response is missing metadata")
+	}
+	return metadataFromMap(rawMD)
+}
+
+func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return rprotoutils.DoFromResourceClient(ctx, c.client, c.name, cmd)
+}
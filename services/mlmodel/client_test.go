@@ -0,0 +1,129 @@
+package mlmodel_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/generic"
+	viamgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/subtype"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestClient(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	listener1, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	rpcServer, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	md := mlmodel.MLMetadata{
+		ModelName: "fake",
+		ModelType: "fake_type",
+		Inputs:    []mlmodel.TensorInfo{{Name: "input", DataType: "float32", Shape: []int{1, 2}}},
+		Outputs: []mlmodel.TensorInfo{{
+			Name:            "scores",
+			DataType:        "float32",
+			Shape:           []int{-1},
+			AssociatedFiles: []mlmodel.File{{Name: "labels.txt", LabelType: mlmodel.LabelTypeTensorAxis}},
+			Extra:           map[string]interface{}{"labels": []interface{}{"a", "b"}},
+		}},
+	}
+	var extraOptions map[string]interface{}
+	injectMLModel := &inject.MLModelService{}
+	injectMLModel.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		extraOptions = extra
+		return md, nil
+	}
+	injectMLModel.InferFunc = func(
+		ctx context.Context,
+		input mlmodel.Tensors,
+		extra map[string]interface{},
+	) (mlmodel.Tensors, error) {
+		extraOptions = extra
+		in, ok := input["input"]
+		if !ok {
+			return nil, errors.New("no input tensor")
+		}
+		values, err := in.Float64s()
+		if err != nil {
+			return nil, err
+		}
+		scores, err := mlmodel.NewTensor([]float32{float32(values[0] * 2), float32(values[1] * 2)})
+		if err != nil {
+			return nil, err
+		}
+		return mlmodel.Tensors{"scores": scores}, nil
+	}
+	injectMLModel.DoCommandFunc = generic.EchoFunc
+
+	svc, err := subtype.New(map[resource.Name]interface{}{
+		mlmodel.Named(testSvcName1): injectMLModel,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	resourceSubtype := registry.ResourceSubtypeLookup(mlmodel.Subtype)
+	resourceSubtype.RegisterRPCService(context.Background(), rpcServer, svc)
+
+	go rpcServer.Serve(listener1)
+	defer rpcServer.Stop()
+
+	t.Run("failing client", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := viamgrpc.Dial(cancelCtx, listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "canceled")
+	})
+
+	conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	client := mlmodel.NewClientFromConn(context.Background(), conn, testSvcName1, logger)
+
+	t.Run("metadata", func(t *testing.T) {
+		extra := map[string]interface{}{"foo": "Metadata"}
+		gotMD, err := client.Metadata(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, gotMD, test.ShouldResemble, md)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+	})
+
+	t.Run("infer", func(t *testing.T) {
+		input, err := mlmodel.NewTensor([]float32{1.5, -2}, 1, 2)
+		test.That(t, err, test.ShouldBeNil)
+		extra := map[string]interface{}{"foo": "Infer"}
+		out, err := client.Infer(context.Background(), mlmodel.Tensors{"input": input}, extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, out["scores"].Data, test.ShouldResemble, []float32{3, -4})
+		test.That(t, out["scores"].Shape, test.ShouldResemble, []int{2})
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+
+		_, err = client.Infer(context.Background(), mlmodel.Tensors{}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no input tensor")
+	})
+
+	t.Run("do command", func(t *testing.T) {
+		resp, err := client.DoCommand(context.Background(), generic.TestCommand)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["command"], test.ShouldEqual, generic.TestCommand["command"])
+		test.That(t, resp["data"], test.ShouldEqual, generic.TestCommand["data"])
+	})
+
+	t.Run("missing service", func(t *testing.T) {
+		missing := mlmodel.NewClientFromConn(context.Background(), conn, testSvcName2, logger)
+		_, err := missing.Metadata(context.Background(), nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "not found")
+	})
+
+	test.That(t, conn.Close(), test.ShouldBeNil)
+}
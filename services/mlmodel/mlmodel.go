@@ -0,0 +1,200 @@
+// Package mlmodel defines a service that takes a map of named input tensors, passes them
+// through a machine learning inference engine, and returns a map of named output tensors.
+package mlmodel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edaniels/golog"
+	goutils "go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/subtype"
+	"go.viam.com/rdk/utils"
+)
+
+func init() {
+	if err := describeService(); err != nil {
+		// without its description the subtype can't be registered, but nothing else depends on it
+		golog.Global().Errorw("cannot register the ML model service", "error", err)
+		return
+	}
+	registry.RegisterResourceSubtype(Subtype, registry.ResourceSubtype{
+		RegisterRPCService: func(ctx context.Context, rpcServer rpc.Server, subtypeSvc subtype.Service) error {
+			return rpcServer.RegisterServiceServer(
+				ctx,
+				&ServiceDesc,
+				NewServer(subtypeSvc),
+			)
+		},
+		RPCServiceDesc: &ServiceDesc,
+		RPCClient: func(ctx context.Context, conn rpc.ClientConn, name string, logger golog.Logger) interface{} {
+			return NewClientFromConn(ctx, conn, name, logger)
+		},
+		Reconfigurable: WrapWithReconfigurable,
+		MaxInstance:    resource.DefaultMaxInstance,
+	})
+}
+
+// A Service runs inference on a machine learning model. Inputs and outputs are keyed by the
+// tensor names the model's Metadata reports.
+type Service interface {
+	Infer(ctx context.Context, input Tensors, extra map[string]interface{}) (Tensors, error)
+	Metadata(ctx context.Context, extra map[string]interface{}) (MLMetadata, error)
+	resource.Generic
+}
+
+// MLMetadata describes the model and the tensors it consumes and produces.
+type MLMetadata struct {
+	ModelName        string       `json:"model_name"`
+	ModelType        string       `json:"model_type"`
+	ModelDescription string       `json:"model_description"`
+	Inputs           []TensorInfo `json:"inputs"`
+	Outputs          []TensorInfo `json:"outputs"`
+}
+
+// TensorInfo describes a single input or output tensor of a model. A shape entry of -1 means
+// that dimension is not fixed by the model.
+type TensorInfo struct {
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	DataType        string                 `json:"data_type"`
+	Shape           []int                  `json:"shape"`
+	AssociatedFiles []File                 `json:"associated_files"`
+	Extra           map[string]interface{} `json:"extra"`
+}
+
+// File describes a file that accompanies a tensor, such as the labels for a classifier output.
+type File struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LabelType   LabelType `json:"label_type"`
+}
+
+// LabelType describes how the labels in an associated File map onto tensor values.
+type LabelType string
+
+// The known label types. LabelTypeTensorValue labels are indexed by the tensor's values,
+// LabelTypeTensorAxis labels are indexed by position along the tensor's last axis.
+const (
+	LabelTypeUnspecified = LabelType("UNSPECIFIED")
+	LabelTypeTensorValue = LabelType("TENSOR_VALUE")
+	LabelTypeTensorAxis  = LabelType("TENSOR_AXIS")
+)
+
+var (
+	_ = Service(&reconfigurableMLModel{})
+	_ = resource.Reconfigurable(&reconfigurableMLModel{})
+	_ = goutils.ContextCloser(&reconfigurableMLModel{})
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = resource.SubtypeName("mlmodel")
+
+// Subtype is a constant that identifies the ML model service resource subtype.
+var Subtype = resource.NewSubtype(
+	resource.ResourceNamespaceRDK,
+	resource.ResourceTypeService,
+	SubtypeName,
+)
+
+// Named is a helper for getting the named ML model service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NameFromSubtype(Subtype, name)
+}
+
+// NewUnimplementedInterfaceError is used when there is a failed interface check.
+func NewUnimplementedInterfaceError(actual interface{}) error {
+	return utils.NewUnimplementedInterfaceError((*Service)(nil), actual)
+}
+
+// FromRobot is a helper for getting the named ML model service from the given Robot.
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromDependencies is a helper for getting the named ML model service from a collection of dependencies.
+func FromDependencies(deps registry.Dependencies, name string) (Service, error) {
+	res, ok := deps[Named(name)]
+	if !ok {
+		return nil, utils.DependencyNotFoundError(name)
+	}
+	svc, ok := res.(Service)
+	if !ok {
+		return nil, NewUnimplementedInterfaceError(res)
+	}
+	return svc, nil
+}
+
+type reconfigurableMLModel struct {
+	mu     sync.RWMutex
+	name   resource.Name
+	actual Service
+}
+
+func (svc *reconfigurableMLModel) Name() resource.Name {
+	return svc.name
+}
+
+func (svc *reconfigurableMLModel) Infer(
+	ctx context.Context,
+	input Tensors,
+	extra map[string]interface{},
+) (Tensors, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.Infer(ctx, input, extra)
+}
+
+func (svc *reconfigurableMLModel) Metadata(ctx context.Context, extra map[string]interface{}) (MLMetadata, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.Metadata(ctx, extra)
+}
+
+func (svc *reconfigurableMLModel) DoCommand(ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return svc.actual.DoCommand(ctx, cmd)
+}
+
+func (svc *reconfigurableMLModel) Close(ctx context.Context) error {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return goutils.TryClose(ctx, svc.actual)
+}
+
+// Reconfigure replaces the old ML model service with a new one.
+func (svc *reconfigurableMLModel) Reconfigure(ctx context.Context, newSvc resource.Reconfigurable) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	rSvc, ok := newSvc.(*reconfigurableMLModel)
+	if !ok {
+		return utils.NewUnexpectedTypeError(svc, newSvc)
+	}
+	if err := goutils.TryClose(ctx, svc.actual); err != nil {
+		golog.Global().Errorw("error closing old", "error", err)
+	}
+	svc.actual = rSvc.actual
+	return nil
+}
+
+// WrapWithReconfigurable wraps an ML model service as a Reconfigurable.
+func WrapWithReconfigurable(s interface{}, name resource.Name) (resource.Reconfigurable, error) {
+	svc, ok := s.(Service)
+	if !ok {
+		return nil, NewUnimplementedInterfaceError(s)
+	}
+
+	if reconfigurable, ok := s.(*reconfigurableMLModel); ok {
+		return reconfigurable, nil
+	}
+
+	return &reconfigurableMLModel{name: name, actual: svc}, nil
+}
@@ -0,0 +1,107 @@
+package mlmodel_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
+)
+
+const (
+	testSvcName1 = "svc1"
+	testSvcName2 = "svc2"
+)
+
+func TestRegisteredReconfigurable(t *testing.T) {
+	s := registry.ResourceSubtypeLookup(mlmodel.Subtype)
+	test.That(t, s, test.ShouldNotBeNil)
+	test.That(t, s.Reconfigurable, test.ShouldNotBeNil)
+	test.That(t, s.ReflectRPCServiceDesc, test.ShouldNotBeNil)
+	test.That(t, s.ReflectRPCServiceDesc.GetFullyQualifiedName(), test.ShouldEqual, mlmodel.ServiceDesc.ServiceName)
+}
+
+func TestWrapWithReconfigurable(t *testing.T) {
+	svc := &inject.MLModelService{}
+	reconfSvc1, err := mlmodel.WrapWithReconfigurable(svc, mlmodel.Named(testSvcName1))
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = mlmodel.WrapWithReconfigurable(nil, resource.Name{})
+	test.That(t, err, test.ShouldBeError, mlmodel.NewUnimplementedInterfaceError(nil))
+
+	reconfSvc2, err := mlmodel.WrapWithReconfigurable(reconfSvc1, resource.Name{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reconfSvc2, test.ShouldEqual, reconfSvc1)
+}
+
+func TestReconfigurable(t *testing.T) {
+	actualSvc1 := &inject.MLModelService{}
+	actualSvc1.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{ModelName: testSvcName1}, nil
+	}
+	reconfSvc1, err := mlmodel.WrapWithReconfigurable(actualSvc1, mlmodel.Named(testSvcName1))
+	test.That(t, err, test.ShouldBeNil)
+
+	actualSvc2 := &inject.MLModelService{}
+	actualSvc2.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{ModelName: testSvcName2}, nil
+	}
+	reconfSvc2, err := mlmodel.WrapWithReconfigurable(actualSvc2, mlmodel.Named(testSvcName1))
+	test.That(t, err, test.ShouldBeNil)
+
+	err = reconfSvc1.Reconfigure(context.Background(), reconfSvc2)
+	test.That(t, err, test.ShouldBeNil)
+	md, err := reconfSvc1.(mlmodel.Service).Metadata(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.ModelName, test.ShouldEqual, testSvcName2)
+
+	err = reconfSvc1.Reconfigure(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, rutils.NewUnexpectedTypeError(reconfSvc1, nil))
+}
+
+func TestNewTensor(t *testing.T) {
+	tensor, err := mlmodel.NewTensor([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tensor.DataType(), test.ShouldEqual, "float32")
+	test.That(t, tensor.Size(), test.ShouldEqual, 6)
+	floats, err := tensor.Float64s()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, floats, test.ShouldResemble, []float64{1, 2, 3, 4, 5, 6})
+
+	tensor, err = mlmodel.NewTensor([]uint8{1, 2, 3})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tensor.Shape, test.ShouldResemble, []int{3})
+
+	_, err = mlmodel.NewTensor([]float32{1, 2, 3}, 2, 2)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "holds 4 elements")
+
+	_, err = mlmodel.NewTensor([]string{"a"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported")
+}
+
+func TestTensorsMapRoundTrip(t *testing.T) {
+	floats, err := mlmodel.NewTensor([]float32{0.5, -1.25, 3e-7, 1e9}, 1, 4)
+	test.That(t, err, test.ShouldBeNil)
+	ints, err := mlmodel.NewTensor([]int64{-1, 1 << 40})
+	test.That(t, err, test.ShouldBeNil)
+	bytes, err := mlmodel.NewTensor([]uint8{0, 127, 255}, 1, 1, 3)
+	test.That(t, err, test.ShouldBeNil)
+	in := mlmodel.Tensors{"floats": floats, "ints": ints, "bytes": bytes}
+
+	m, err := mlmodel.TensorsToMap(in)
+	test.That(t, err, test.ShouldBeNil)
+	out, err := mlmodel.TensorsFromMap(m)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldResemble, in)
+
+	_, err = mlmodel.TensorsFromMap(map[string]interface{}{"bad": map[string]interface{}{"data_type": "float32"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "missing its shape")
+}
@@ -0,0 +1,149 @@
+package mlmodel
+
+import (
+	"context"
+
+	commonpb "go.viam.com/api/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/protoutils"
+)
+
+// The API this version of go.viam.com/api ships has no ML model service, so its gRPC service is
+// described here. Requests and responses are google.protobuf.Struct messages with the fields
+// listed on each method below; describing it lets reflection, remotes and modules treat it like
+// any generated service.
+const (
+	protoFileName    = "service/mlmodel/v1/mlmodel.proto"
+	protoServiceName = "viam.service.mlmodel.v1.MLModelService"
+
+	inferMethod     = "/" + protoServiceName + "/Infer"
+	metadataMethod  = "/" + protoServiceName + "/Metadata"
+	doCommandMethod = "/" + protoServiceName + "/DoCommand"
+)
+
+// describeService registers the description of the gRPC service, which the subtype
+// registration loads.
+func describeService() error {
+	return protoutils.RegisterServiceDescriptor(protoFileName, protoServiceName, []protoutils.MethodDescription{
+		{Name: "Infer"},
+		{Name: "Metadata"},
+		{Name: "DoCommand", Input: &commonpb.DoCommandRequest{}, Output: &commonpb.DoCommandResponse{}},
+	})
+}
+
+// MLModelServiceServer is the server API for the ML model gRPC service.
+type MLModelServiceServer interface {
+	// Infer takes {"name", "input_tensors", "extra"} and returns {"output_tensors"}.
+	Infer(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	// Metadata takes {"name", "extra"} and returns {"metadata"}.
+	Metadata(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	DoCommand(context.Context, *commonpb.DoCommandRequest) (*commonpb.DoCommandResponse, error)
+}
+
+// ServiceDesc is the grpc.ServiceDesc for the ML model gRPC service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: protoServiceName,
+	HandlerType: (*MLModelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Infer", Handler: inferHandler},
+		{MethodName: "Metadata", Handler: metadataHandler},
+		{MethodName: "DoCommand", Handler: doCommandHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: protoFileName,
+}
+
+func inferHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MLModelServiceServer).Infer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: inferMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MLModelServiceServer).Infer(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func metadataHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MLModelServiceServer).Metadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: metadataMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MLModelServiceServer).Metadata(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func doCommandHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(commonpb.DoCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MLModelServiceServer).DoCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: doCommandMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MLModelServiceServer).DoCommand(ctx, req.(*commonpb.DoCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// mlModelServiceClient calls the ML model gRPC service over a connection.
+type mlModelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func (c *mlModelServiceClient) Infer(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, inferMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mlModelServiceClient) Metadata(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, metadataMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mlModelServiceClient) DoCommand(
+	ctx context.Context,
+	in *commonpb.DoCommandRequest,
+	opts ...grpc.CallOption,
+) (*commonpb.DoCommandResponse, error) {
+	out := new(commonpb.DoCommandResponse)
+	if err := c.cc.Invoke(ctx, doCommandMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
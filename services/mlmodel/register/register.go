@@ -0,0 +1,7 @@
+// Package register registers all relevant ML model services and also subtype specific functions
+package register
+
+import (
+	// for ML model service models.
+	_ "go.viam.com/rdk/services/mlmodel/tflitecpu"
+)
@@ -0,0 +1,114 @@
+// Package mlmodel contains a gRPC based ML model service server.
+package mlmodel
+
+import (
+	"context"
+	"encoding/json"
+
+	commonpb "go.viam.com/api/common/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/subtype"
+	"go.viam.com/rdk/utils"
+)
+
+// subtypeServer implements the MLModelService described in proto.go.
+type subtypeServer struct {
+	subtypeSvc subtype.Service
+}
+
+// NewServer constructs an ML model gRPC service server.
+func NewServer(s subtype.Service) MLModelServiceServer {
+	return &subtypeServer{subtypeSvc: s}
+}
+
+func (server *subtypeServer) service(serviceName string) (Service, error) {
+	resource := server.subtypeSvc.Resource(serviceName)
+	if resource == nil {
+		return nil, utils.NewResourceNotFoundError(Named(serviceName))
+	}
+	svc, ok := resource.(Service)
+	if !ok {
+		return nil, NewUnimplementedInterfaceError(resource)
+	}
+	return svc, nil
+}
+
+func (server *subtypeServer) Infer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fields := req.AsMap()
+	name, _ := fields["name"].(string)
+	svc, err := server.service(name)
+	if err != nil {
+		return nil, err
+	}
+	rawInput, _ := fields["input_tensors"].(map[string]interface{})
+	input, err := TensorsFromMap(rawInput)
+	if err != nil {
+		return nil, err
+	}
+	extra, _ := fields["extra"].(map[string]interface{})
+	output, err := svc.Infer(ctx, input, extra)
+	if err != nil {
+		return nil, err
+	}
+	rawOutput, err := TensorsToMap(output)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(map[string]interface{}{"output_tensors": rawOutput})
+}
+
+func (server *subtypeServer) Metadata(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fields := req.AsMap()
+	name, _ := fields["name"].(string)
+	svc, err := server.service(name)
+	if err != nil {
+		return nil, err
+	}
+	extra, _ := fields["extra"].(map[string]interface{})
+	md, err := svc.Metadata(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	rawMD, err := metadataToMap(md)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(map[string]interface{}{"metadata": rawMD})
+}
+
+// DoCommand receives arbitrary commands.
+func (server *subtypeServer) DoCommand(ctx context.Context,
+	req *commonpb.DoCommandRequest,
+) (*commonpb.DoCommandResponse, error) {
+	svc, err := server.service(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return protoutils.DoFromResourceServer(ctx, svc, req)
+}
+
+// metadataToMap encodes metadata by its json field names.
+func metadataToMap(md MLMetadata) (map[string]interface{}, error) {
+	b, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// metadataFromMap decodes metadata encoded by metadataToMap.
+func metadataFromMap(m map[string]interface{}) (MLMetadata, error) {
+	var md MLMetadata
+	b, err := json.Marshal(m)
+	if err != nil {
+		return md, err
+	}
+	err = json.Unmarshal(b, &md)
+	return md, err
+}
@@ -0,0 +1,74 @@
+package mlmodel_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/subtype"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func newServer(m map[resource.Name]interface{}) (mlmodel.MLModelServiceServer, error) {
+	svc, err := subtype.New(m)
+	if err != nil {
+		return nil, err
+	}
+	return mlmodel.NewServer(svc), nil
+}
+
+func TestServerNotFound(t *testing.T) {
+	server, err := newServer(map[resource.Name]interface{}{})
+	test.That(t, err, test.ShouldBeNil)
+	req, err := structpb.NewStruct(map[string]interface{}{"name": testSvcName1})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = server.Metadata(context.Background(), req)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not found")
+}
+
+func TestServerFailures(t *testing.T) {
+	server, err := newServer(map[resource.Name]interface{}{
+		mlmodel.Named(testSvcName1): "not an ml model",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	req, err := structpb.NewStruct(map[string]interface{}{"name": testSvcName1})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = server.Infer(context.Background(), req)
+	test.That(t, err, test.ShouldBeError, mlmodel.NewUnimplementedInterfaceError("not an ml model"))
+}
+
+func TestServerInfer(t *testing.T) {
+	injectMLModel := &inject.MLModelService{}
+	var received mlmodel.Tensors
+	injectMLModel.InferFunc = func(
+		ctx context.Context,
+		input mlmodel.Tensors,
+		extra map[string]interface{},
+	) (mlmodel.Tensors, error) {
+		received = input
+		return input, nil
+	}
+	server, err := newServer(map[resource.Name]interface{}{
+		mlmodel.Named(testSvcName1): injectMLModel,
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	tensor, err := mlmodel.NewTensor([]int32{7, 8, 9}, 3, 1)
+	test.That(t, err, test.ShouldBeNil)
+	rawInput, err := mlmodel.TensorsToMap(mlmodel.Tensors{"x": tensor})
+	test.That(t, err, test.ShouldBeNil)
+	req, err := structpb.NewStruct(map[string]interface{}{"name": testSvcName1, "input_tensors": rawInput})
+	test.That(t, err, test.ShouldBeNil)
+
+	resp, err := server.Infer(context.Background(), req)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, received, test.ShouldResemble, mlmodel.Tensors{"x": tensor})
+	out, err := mlmodel.TensorsFromMap(resp.AsMap()["output_tensors"].(map[string]interface{}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldResemble, mlmodel.Tensors{"x": tensor})
+}
@@ -0,0 +1,268 @@
+package mlmodel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// Tensors maps tensor names to tensors.
+type Tensors map[string]Tensor
+
+// A Tensor is an n-dimensional array stored flat in row-major order. Data is one of []float32,
+// []float64, []int8, []int16, []int32, []int64, []uint8, []uint16, []uint32 or []uint64.
+type Tensor struct {
+	Shape []int
+	Data  interface{}
+}
+
+// NewTensor returns a tensor over the given data. If no shape is given the tensor is
+// one-dimensional; otherwise the shape must account for every element of data.
+func NewTensor(data interface{}, shape ...int) (Tensor, error) {
+	n, err := dataLen(data)
+	if err != nil {
+		return Tensor{}, err
+	}
+	if len(shape) == 0 {
+		shape = []int{n}
+	}
+	t := Tensor{Shape: shape, Data: data}
+	if size := t.Size(); size != n {
+		return Tensor{}, errors.Errorf("shape %v holds %d elements but data has %d", shape, size, n)
+	}
+	return t, nil
+}
+
+// Size returns the number of elements the tensor's shape describes.
+func (t Tensor) Size() int {
+	size := 1
+	for _, d := range t.Shape {
+		size *= d
+	}
+	return size
+}
+
+// DataType returns the name of the tensor's element type, e.g. "float32".
+func (t Tensor) DataType() string {
+	switch t.Data.(type) {
+	case []float32:
+		return "float32"
+	case []float64:
+		return "float64"
+	case []int8:
+		return "int8"
+	case []int16:
+		return "int16"
+	case []int32:
+		return "int32"
+	case []int64:
+		return "int64"
+	case []uint8:
+		return "uint8"
+	case []uint16:
+		return "uint16"
+	case []uint32:
+		return "uint32"
+	case []uint64:
+		return "uint64"
+	default:
+		return ""
+	}
+}
+
+// Float64s returns a copy of the tensor's data converted to float64.
+func (t Tensor) Float64s() ([]float64, error) {
+	switch data := t.Data.(type) {
+	case []float32:
+		return toFloat64s(data), nil
+	case []float64:
+		return append([]float64(nil), data...), nil
+	case []int8:
+		return toFloat64s(data), nil
+	case []int16:
+		return toFloat64s(data), nil
+	case []int32:
+		return toFloat64s(data), nil
+	case []int64:
+		return toFloat64s(data), nil
+	case []uint8:
+		return toFloat64s(data), nil
+	case []uint16:
+		return toFloat64s(data), nil
+	case []uint32:
+		return toFloat64s(data), nil
+	case []uint64:
+		return toFloat64s(data), nil
+	default:
+		return nil, newUnsupportedDataError(t.Data)
+	}
+}
+
+func toFloat64s[T float32 | float64 | int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64](data []T) []float64 {
+	out := make([]float64, len(data))
+	for i, v := range data {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func dataLen(data interface{}) (int, error) {
+	switch data := data.(type) {
+	case []float32:
+		return len(data), nil
+	case []float64:
+		return len(data), nil
+	case []int8:
+		return len(data), nil
+	case []int16:
+		return len(data), nil
+	case []int32:
+		return len(data), nil
+	case []int64:
+		return len(data), nil
+	case []uint8:
+		return len(data), nil
+	case []uint16:
+		return len(data), nil
+	case []uint32:
+		return len(data), nil
+	case []uint64:
+		return len(data), nil
+	default:
+		return 0, newUnsupportedDataError(data)
+	}
+}
+
+// makeData allocates an n element slice of the named data type.
+func makeData(dataType string, n int) (interface{}, error) {
+	switch dataType {
+	case "float32":
+		return make([]float32, n), nil
+	case "float64":
+		return make([]float64, n), nil
+	case "int8":
+		return make([]int8, n), nil
+	case "int16":
+		return make([]int16, n), nil
+	case "int32":
+		return make([]int32, n), nil
+	case "int64":
+		return make([]int64, n), nil
+	case "uint8":
+		return make([]uint8, n), nil
+	case "uint16":
+		return make([]uint16, n), nil
+	case "uint32":
+		return make([]uint32, n), nil
+	case "uint64":
+		return make([]uint64, n), nil
+	default:
+		return nil, errors.Errorf("unsupported tensor data type %q", dataType)
+	}
+}
+
+func newUnsupportedDataError(data interface{}) error {
+	return errors.Errorf("unsupported tensor data of type %T", data)
+}
+
+// tensorToMap encodes a tensor for the wire. The data is sent as base64 little-endian bytes so
+// that large tensors stay compact and keep their exact values.
+func tensorToMap(t Tensor) (map[string]interface{}, error) {
+	dataType := t.DataType()
+	if dataType == "" {
+		return nil, newUnsupportedDataError(t.Data)
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, t.Data); err != nil {
+		return nil, err
+	}
+	shape := make([]interface{}, 0, len(t.Shape))
+	for _, d := range t.Shape {
+		shape = append(shape, d)
+	}
+	return map[string]interface{}{
+		"data_type": dataType,
+		"shape":     shape,
+		"data":      base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// tensorFromMap decodes a tensor encoded by tensorToMap.
+func tensorFromMap(m map[string]interface{}) (Tensor, error) {
+	dataType, ok := m["data_type"].(string)
+	if !ok {
+		return Tensor{}, errors.New("tensor is missing its data_type")
+	}
+	rawShape, ok := m["shape"].([]interface{})
+	if !ok {
+		return Tensor{}, errors.New("tensor is missing its shape")
+	}
+	shape := make([]int, 0, len(rawShape))
+	for _, d := range rawShape {
+		// dimensions are ints until they have been through a protobuf Struct
+		switch d := d.(type) {
+		case int:
+			shape = append(shape, d)
+		case float64:
+			shape = append(shape, int(d))
+		default:
+			return Tensor{}, errors.Errorf("invalid tensor dimension %v", d)
+		}
+	}
+	encoded, ok := m["data"].(string)
+	if !ok {
+		return Tensor{}, errors.New("tensor is missing its data")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Tensor{}, err
+	}
+	zero, err := makeData(dataType, 1)
+	if err != nil {
+		return Tensor{}, err
+	}
+	elemSize := binary.Size(zero)
+	if len(raw)%elemSize != 0 {
+		return Tensor{}, errors.Errorf("%d bytes is not a whole number of %s elements", len(raw), dataType)
+	}
+	data, err := makeData(dataType, len(raw)/elemSize)
+	if err != nil {
+		return Tensor{}, err
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, data); err != nil {
+		return Tensor{}, err
+	}
+	return NewTensor(data, shape...)
+}
+
+// TensorsToMap encodes named tensors into a map that can be carried in a protobuf Struct.
+func TensorsToMap(tensors Tensors) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(tensors))
+	for name, t := range tensors {
+		m, err := tensorToMap(t)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tensor %q", name)
+		}
+		out[name] = m
+	}
+	return out, nil
+}
+
+// TensorsFromMap decodes named tensors encoded by TensorsToMap.
+func TensorsFromMap(m map[string]interface{}) (Tensors, error) {
+	out := make(Tensors, len(m))
+	for name, v := range m {
+		tm, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("tensor %q is not a map", name)
+		}
+		t, err := tensorFromMap(tm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tensor %q", name)
+		}
+		out[name] = t
+	}
+	return out, nil
+}
@@ -0,0 +1,338 @@
+//go:build !arm && !windows
+
+// Package tflitecpu runs tflite models on the local CPU through the ML model service API.
+package tflitecpu
+
+import (
+	"bufio"
+	"context"
+	"os"
+	fp "path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/config"
+	inf "go.viam.com/rdk/ml/inference"
+	"go.viam.com/rdk/ml/inference/tflite_metadata"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the model name of the tflite CPU backend.
+var Model = resource.NewDefaultModel("tflite_cpu")
+
+func init() {
+	registry.RegisterService(mlmodel.Subtype, Model, registry.Service{
+		Constructor: func(ctx context.Context, deps registry.Dependencies, c config.Service, logger golog.Logger) (interface{}, error) {
+			attrs, ok := c.ConvertedAttributes.(*TFLiteConfig)
+			if !ok {
+				return nil, utils.NewUnexpectedTypeError(attrs, c.ConvertedAttributes)
+			}
+			return NewTFLiteCPUModel(ctx, attrs, c.Name)
+		},
+	})
+	config.RegisterServiceAttributeMapConverter(mlmodel.Subtype, Model,
+		func(attributes config.AttributeMap) (interface{}, error) {
+			var conf TFLiteConfig
+			return config.TransformAttributeMapToStruct(&conf, attributes)
+		},
+		&TFLiteConfig{},
+	)
+}
+
+// TFLiteConfig describes how to load a tflite model.
+type TFLiteConfig struct {
+	ModelPath  string `json:"model_path"`
+	NumThreads int    `json:"num_threads"`
+	LabelPath  string `json:"label_path"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *TFLiteConfig) Validate(path string) error {
+	if conf.ModelPath == "" {
+		return goutils.NewConfigValidationFieldRequiredError(path, "model_path")
+	}
+	if conf.NumThreads < 0 {
+		return goutils.NewConfigValidationError(path, errors.New("num_threads cannot be negative"))
+	}
+	return nil
+}
+
+// TFLiteCPUModel runs a tflite model on the CPU.
+type TFLiteCPUModel struct {
+	generic.Unimplemented
+	name   string
+	labels []string
+	// tfMeta is the metadata embedded in the model file, or nil if it has none.
+	tfMeta *tflite_metadata.ModelMetadataT
+
+	mu    sync.Mutex
+	model *inf.TFLiteStruct
+}
+
+// NewTFLiteCPUModel loads the model at the configured path. If num_threads is not set the
+// interpreter uses every CPU.
+func NewTFLiteCPUModel(ctx context.Context, conf *TFLiteConfig, name string) (*TFLiteCPUModel, error) {
+	ctx, span := trace.StartSpan(ctx, "service::mlmodel::tflitecpu::NewTFLiteCPUModel")
+	defer span.End()
+
+	var numThreads *int
+	if conf.NumThreads > 0 {
+		numThreads = &conf.NumThreads
+	}
+	model, err := addTFLiteModel(ctx, conf.ModelPath, numThreads)
+	if err != nil {
+		return nil, errors.Wrap(err, "something wrong with adding the model")
+	}
+
+	m := &TFLiteCPUModel{name: name, model: model}
+	if tfMeta, err := model.Metadata(); err == nil {
+		m.tfMeta = tfMeta
+	}
+	if conf.LabelPath != "" {
+		m.labels, err = loadLabels(conf.LabelPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load labels from %s", conf.LabelPath)
+		}
+	}
+	return m, nil
+}
+
+// addTFLiteModel uses the loader (default or otherwise) from the inference package
+// to register a tflite model. Default is chosen if there's no numThreads given.
+func addTFLiteModel(ctx context.Context, filepath string, numThreads *int) (*inf.TFLiteStruct, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::tflitecpu::addTFLiteModel")
+	defer span.End()
+	var model *inf.TFLiteStruct
+	var loader *inf.TFLiteModelLoader
+	var err error
+
+	if numThreads == nil {
+		loader, err = inf.NewDefaultTFLiteModelLoader()
+	} else {
+		loader, err = inf.NewTFLiteModelLoader(*numThreads)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get loader")
+	}
+
+	fullpath, err2 := fp.Abs(filepath)
+	if err2 != nil {
+		model, err = loader.Load(filepath)
+	} else {
+		model, err = loader.Load(fullpath)
+	}
+
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to load") {
+			if err2 != nil {
+				return nil, errors.Wrapf(err, "file not found at %s", filepath)
+			}
+			return nil, errors.Wrapf(err, "file not found at %s", fullpath)
+		}
+		return nil, errors.Wrap(err, "loader could not load model")
+	}
+
+	return model, nil
+}
+
+// Infer runs the model on its single input tensor. The input may be given under any name.
+// Output tensors are named as in Metadata.
+func (m *TFLiteCPUModel) Infer(
+	ctx context.Context,
+	input mlmodel.Tensors,
+	extra map[string]interface{},
+) (mlmodel.Tensors, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::tflitecpu::Infer")
+	defer span.End()
+
+	if len(input) != 1 {
+		return nil, errors.Errorf("tflite models take exactly one input tensor, got %d", len(input))
+	}
+	var in mlmodel.Tensor
+	for _, t := range input {
+		in = t
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.model == nil {
+		return nil, errors.New("model is closed")
+	}
+	outTensors, err := m.model.Infer(in.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't infer from model")
+	}
+	out := make(mlmodel.Tensors, len(outTensors))
+	for i, data := range outTensors {
+		if b, ok := data.([]bool); ok {
+			data = boolsToUInt8s(b)
+		}
+		t, err := mlmodel.NewTensor(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "output tensor %d", i)
+		}
+		out[outputName(m.tfMeta, i)] = t
+	}
+	return out, nil
+}
+
+// Metadata describes the model from the metadata embedded in the tflite file when there is
+// any, and from the interpreter's tensors otherwise.
+func (m *TFLiteCPUModel) Metadata(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.model == nil {
+		return mlmodel.MLMetadata{}, errors.New("model is closed")
+	}
+	info := m.model.Info
+	tfMeta := m.tfMeta
+
+	md := mlmodel.MLMetadata{
+		ModelName: m.name,
+		ModelType: "tflite_cpu",
+	}
+	if tfMeta != nil {
+		if tfMeta.Name != "" {
+			md.ModelName = tfMeta.Name
+		}
+		md.ModelDescription = tfMeta.Description
+	}
+
+	in := mlmodel.TensorInfo{
+		Name:     "input",
+		DataType: strings.ToLower(string(info.InputTensorType)),
+		Shape:    info.InputShape,
+	}
+	if tensorMeta := subgraphTensor(tfMeta, true, 0); tensorMeta != nil {
+		in.Name = tensorMeta.Name
+		in.Description = tensorMeta.Description
+	}
+	md.Inputs = []mlmodel.TensorInfo{in}
+
+	for i := 0; i < info.OutputTensorCount; i++ {
+		out := mlmodel.TensorInfo{Name: outputName(tfMeta, i), Shape: []int{-1}}
+		if i < len(info.OutputTensorTypes) {
+			out.DataType = strings.ToLower(info.OutputTensorTypes[i])
+		}
+		if tensorMeta := subgraphTensor(tfMeta, false, i); tensorMeta != nil {
+			out.Description = tensorMeta.Description
+			out.AssociatedFiles = associatedFiles(tensorMeta.AssociatedFiles)
+			if order := boxOrder(tensorMeta); order != nil {
+				out.Extra = map[string]interface{}{"box_order": order}
+			}
+		}
+		if len(m.labels) > 0 {
+			if out.Extra == nil {
+				out.Extra = map[string]interface{}{}
+			}
+			out.Extra["labels"] = m.labels
+		}
+		md.Outputs = append(md.Outputs, out)
+	}
+	return md, nil
+}
+
+// Close frees the interpreter and model.
+func (m *TFLiteCPUModel) Close(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.model == nil {
+		return nil
+	}
+	err := m.model.Close()
+	m.model = nil
+	return err
+}
+
+// subgraphTensor returns the metadata of the i-th input or output tensor of the first subgraph,
+// or nil if the model does not describe it.
+func subgraphTensor(m *tflite_metadata.ModelMetadataT, input bool, i int) *tflite_metadata.TensorMetadataT {
+	if m == nil || len(m.SubgraphMetadata) == 0 {
+		return nil
+	}
+	tensors := m.SubgraphMetadata[0].OutputTensorMetadata
+	if input {
+		tensors = m.SubgraphMetadata[0].InputTensorMetadata
+	}
+	if i >= len(tensors) {
+		return nil
+	}
+	return tensors[i]
+}
+
+// outputName names the i-th output tensor after its metadata, falling back to its position.
+func outputName(m *tflite_metadata.ModelMetadataT, i int) string {
+	if tensorMeta := subgraphTensor(m, false, i); tensorMeta != nil && tensorMeta.Name != "" {
+		return tensorMeta.Name
+	}
+	return "output" + strconv.Itoa(i)
+}
+
+func associatedFiles(files []*tflite_metadata.AssociatedFileT) []mlmodel.File {
+	out := make([]mlmodel.File, 0, len(files))
+	for _, f := range files {
+		labelType := mlmodel.LabelTypeUnspecified
+		switch f.Type {
+		case tflite_metadata.AssociatedFileTypeTENSOR_VALUE_LABELS:
+			labelType = mlmodel.LabelTypeTensorValue
+		case tflite_metadata.AssociatedFileTypeTENSOR_AXIS_LABELS:
+			labelType = mlmodel.LabelTypeTensorAxis
+		default:
+		}
+		out = append(out, mlmodel.File{Name: f.Name, Description: f.Description, LabelType: labelType})
+	}
+	return out
+}
+
+// boxOrder returns the bounding box index list from a location tensor's metadata, which gives
+// the position of each box coordinate, or nil if the tensor does not hold bounding boxes.
+func boxOrder(t *tflite_metadata.TensorMetadataT) []int {
+	if t.Content == nil || t.Content.ContentProperties == nil {
+		return nil
+	}
+	props, ok := t.Content.ContentProperties.Value.(*tflite_metadata.BoundingBoxPropertiesT)
+	if !ok {
+		return nil
+	}
+	order := make([]int, 0, len(props.Index))
+	for _, o := range props.Index {
+		order = append(order, int(o))
+	}
+	return order
+}
+
+func boolsToUInt8s(b []bool) []uint8 {
+	out := make([]uint8, len(b))
+	for i, v := range b {
+		if v {
+			out[i] = 1
+		}
+	}
+	return out
+}
+
+// loadLabels reads a labelmap.txt file from filename and returns a slice of the labels
+// (stolen from https:// github.com/mattn/go-tflite).
+func loadLabels(filename string) ([]string, error) {
+	labels := []string{}
+	f, err := os.Open(filename) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		labels = append(labels, scanner.Text())
+	}
+	return labels, scanner.Err()
+}
@@ -0,0 +1,27 @@
+//go:build arm || windows
+
+// Package tflitecpu runs tflite models on the local CPU through the ML model service API.
+package tflitecpu
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/registry"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+// Model is the model name of the tflite CPU backend.
+var Model = resource.NewDefaultModel("tflite_cpu")
+
+func init() {
+	registry.RegisterService(mlmodel.Subtype, Model, registry.Service{
+		Constructor: func(ctx context.Context, deps registry.Dependencies, c config.Service, logger golog.Logger) (interface{}, error) {
+			return nil, errors.New("not supported on 32 bit ARM or Windows")
+		},
+	})
+}
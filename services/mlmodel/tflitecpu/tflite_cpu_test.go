@@ -0,0 +1,76 @@
+//go:build !arm && !windows
+
+package tflitecpu
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/artifact"
+
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/utils"
+)
+
+func TestTFLiteCPUModel(t *testing.T) {
+	ctx := context.Background()
+	conf := &TFLiteConfig{ModelPath: utils.ResolveFile("ml/inference/testing_files/model_with_metadata.tflite"), NumThreads: 1}
+	model, err := NewTFLiteCPUModel(ctx, conf, "detector")
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, model.Close(ctx), test.ShouldBeNil)
+	}()
+
+	md, err := model.Metadata(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.ModelType, test.ShouldEqual, "tflite_cpu")
+	test.That(t, md.Inputs, test.ShouldHaveLength, 1)
+	test.That(t, md.Inputs[0].DataType, test.ShouldEqual, "float32")
+	test.That(t, md.Inputs[0].Shape, test.ShouldResemble, []int{1, 640, 640, 3})
+	test.That(t, md.Outputs, test.ShouldHaveLength, 4)
+
+	input, err := mlmodel.NewTensor(make([]float32, 640*640*3), md.Inputs[0].Shape...)
+	test.That(t, err, test.ShouldBeNil)
+	out, err := model.Infer(ctx, mlmodel.Tensors{md.Inputs[0].Name: input}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldHaveLength, 4)
+	for _, info := range md.Outputs {
+		tensor, ok := out[info.Name]
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, tensor.DataType(), test.ShouldEqual, info.DataType)
+	}
+
+	_, err = model.Infer(ctx, mlmodel.Tensors{}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "exactly one input tensor")
+}
+
+func TestTFLiteCPUModelBadPath(t *testing.T) {
+	conf := &TFLiteConfig{ModelPath: "very/fake/path.tflite"}
+	model, err := NewTFLiteCPUModel(context.Background(), conf, "nofile")
+	test.That(t, model, test.ShouldBeNil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "file not found")
+}
+
+func TestTFLiteConfigValidate(t *testing.T) {
+	conf := &TFLiteConfig{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "model_path")
+
+	conf = &TFLiteConfig{ModelPath: "model.tflite", NumThreads: -1}
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	conf = &TFLiteConfig{ModelPath: "model.tflite", NumThreads: 2}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+}
+
+func TestLabelReader(t *testing.T) {
+	inputfile := artifact.MustPath("vision/tflite/fakelabels.txt")
+	got, err := loadLabels(inputfile)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got[0], test.ShouldResemble, "this")
+	test.That(t, len(got), test.ShouldEqual, 12)
+}
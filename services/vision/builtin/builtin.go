@@ -54,7 +54,7 @@ func NewBuiltIn(ctx context.Context, r robot.Robot, config config.Service, logge
 		if !ok {
 			return nil, utils.NewUnexpectedTypeError(attrs, config.ConvertedAttributes)
 		}
-		err := registerNewVisModels(ctx, modMap, attrs, logger)
+		err := registerNewVisModels(ctx, modMap, attrs, r, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -102,7 +102,7 @@ func (vs *builtIn) AddDetector(ctx context.Context, cfg vision.VisModelConfig, e
 	ctx, span := trace.StartSpan(ctx, "service::vision::AddDetector")
 	defer span.End()
 	attrs := &vision.Attributes{ModelRegistry: []vision.VisModelConfig{cfg}}
-	err := registerNewVisModels(ctx, vs.modReg, attrs, vs.logger)
+	err := registerNewVisModels(ctx, vs.modReg, attrs, vs.r, vs.logger)
 	if err != nil {
 		return err
 	}
@@ -186,7 +186,7 @@ func (vs *builtIn) AddClassifier(ctx context.Context, cfg vision.VisModelConfig,
 	ctx, span := trace.StartSpan(ctx, "service::vision::AddClassifier")
 	defer span.End()
 	attrs := &vision.Attributes{ModelRegistry: []vision.VisModelConfig{cfg}}
-	err := registerNewVisModels(ctx, vs.modReg, attrs, vs.logger)
+	err := registerNewVisModels(ctx, vs.modReg, attrs, vs.r, vs.logger)
 	if err != nil {
 		return err
 	}
@@ -269,7 +269,7 @@ func (vs *builtIn) AddSegmenter(ctx context.Context, cfg vision.VisModelConfig,
 	ctx, span := trace.StartSpan(ctx, "service::vision::AddSegmenter")
 	defer span.End()
 	attrs := &vision.Attributes{ModelRegistry: []vision.VisModelConfig{cfg}}
-	return registerNewVisModels(ctx, vs.modReg, attrs, vs.logger)
+	return registerNewVisModels(ctx, vs.modReg, attrs, vs.r, vs.logger)
 }
 
 // RemoveSegmenter removes a segmenter from the registry.
@@ -0,0 +1,297 @@
+//go:build !arm && !windows
+
+package builtin
+
+import (
+	"context"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/edaniels/golog"
+	"github.com/nfnt/resize"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/vision/classification"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// modelCloser closes the ML model behind a vision model when the vision model is removed.
+type modelCloser struct {
+	model mlmodel.Service
+}
+
+func (c modelCloser) Close() error {
+	return goutils.TryClose(context.Background(), c.model)
+}
+
+// modelInput describes the image tensor an ML model expects.
+type modelInput struct {
+	name              string
+	dataType          string
+	inHeight, inWidth uint
+}
+
+// getModelInput reads the image input dimensions and type from the model's metadata. Shapes are
+// either NHWC or, when the second dimension is 3, NCHW.
+func getModelInput(md mlmodel.MLMetadata) (modelInput, error) {
+	if len(md.Inputs) == 0 {
+		return modelInput{}, errors.New("model has no input tensors")
+	}
+	in := md.Inputs[0]
+	shape := in.Shape
+	if len(shape) < 4 {
+		return modelInput{}, errors.Errorf("expected a 4 dimensional image input tensor, got shape %v", shape)
+	}
+	var inHeight, inWidth uint
+	if getIndex(shape, 3) == 1 {
+		inHeight, inWidth = uint(shape[2]), uint(shape[3])
+	} else {
+		inHeight, inWidth = uint(shape[1]), uint(shape[2])
+	}
+	return modelInput{name: in.Name, dataType: in.DataType, inHeight: inHeight, inWidth: inWidth}, nil
+}
+
+// infer resizes the image to the model's input size, converts it to the model's input type
+// and runs it through the model.
+func (mi modelInput) infer(ctx context.Context, model mlmodel.Service, img image.Image) (mlmodel.Tensors, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::infer")
+	defer span.End()
+
+	resizedImg := resize.Resize(mi.inHeight, mi.inWidth, img, resize.Bilinear)
+	h, w := resizedImg.Bounds().Dy(), resizedImg.Bounds().Dx()
+
+	// Converts the image to bytes before sending it off
+	var data interface{}
+	switch mi.dataType {
+	case "uint8":
+		data = ImageToUInt8Buffer(resizedImg)
+	case "float32":
+		data = ImageToFloatBuffer(resizedImg)
+	default:
+		return nil, errors.New("invalid input type. try uint8 or float32")
+	}
+	in, err := mlmodel.NewTensor(data, 1, h, w, 3)
+	if err != nil {
+		return nil, err
+	}
+	out, err := model.Infer(ctx, mlmodel.Tensors{mi.name: in}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't infer from model")
+	}
+	return out, nil
+}
+
+// attemptToBuildDetector wraps an ML model as a detector. The roles of the output tensors
+// are read from their names when the model describes them and guessed otherwise.
+func attemptToBuildDetector(
+	ctx context.Context,
+	model mlmodel.Service,
+	logger golog.Logger,
+) (objectdetection.Detector, error) {
+	md, err := model.Metadata(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get any metadata")
+	}
+	input, err := getModelInput(md)
+	if err != nil {
+		return nil, err
+	}
+	labelMap := outputLabels(md)
+	if labelMap == nil {
+		logger.Warn("did not retrieve class labels")
+	}
+	var boxOrder []int
+	for _, out := range md.Outputs {
+		if order, ok := intsFromExtra(out.Extra, "box_order"); ok {
+			boxOrder = order
+		}
+	}
+
+	// This function to be returned is the detector.
+	return func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+		origW, origH := img.Bounds().Dx(), img.Bounds().Dy()
+		outMap, err := input.infer(ctx, model, img)
+		if err != nil {
+			return nil, err
+		}
+		tensors, err := orderedOutputs(md, outMap)
+		if err != nil {
+			return nil, err
+		}
+		detections := unpackTensors(ctx, tensors, outputNames(md), boxOrder, labelMap, logger, origW, origH)
+		return detections, nil
+	}, nil
+}
+
+// attemptToBuildClassifier wraps an ML model whose first output holds a confidence per class
+// as a classifier.
+func attemptToBuildClassifier(ctx context.Context, model mlmodel.Service) (classification.Classifier, error) {
+	md, err := model.Metadata(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get any metadata")
+	}
+	input, err := getModelInput(md)
+	if err != nil {
+		return nil, err
+	}
+	if len(md.Outputs) == 0 {
+		return nil, errors.New("model has no output tensors")
+	}
+	labels := outputLabels(md)
+
+	// This function that gets returned should be the Classifier
+	return func(ctx context.Context, img image.Image) (classification.Classifications, error) {
+		outMap, err := input.infer(ctx, model, img)
+		if err != nil {
+			return nil, err
+		}
+		outTensor, ok := outMap[md.Outputs[0].Name]
+		if !ok {
+			return nil, errors.Errorf("model did not return output tensor %q", md.Outputs[0].Name)
+		}
+		return unpackClassificationTensor(ctx, outTensor, labels)
+	}, nil
+}
+
+func unpackClassificationTensor(ctx context.Context, tensor mlmodel.Tensor,
+	labels []string,
+) (classification.Classifications, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::unpackClassificationTensor")
+	defer span.End()
+
+	var outConf []float64
+	switch data := tensor.Data.(type) {
+	case []uint8:
+		for _, t := range data {
+			outConf = append(outConf, float64(t)/float64(256))
+		}
+	case []float32:
+		for _, t := range data {
+			outConf = append(outConf, float64(t))
+		}
+	default:
+		return nil, errors.New("output type not valid. try uint8 or float32")
+	}
+	out := make(classification.Classifications, 0, len(outConf))
+	if len(labels) > 0 {
+		for i, c := range outConf {
+			if i >= len(labels) {
+				return nil, errors.Errorf("cannot label output %d, only %d labels", i, len(labels))
+			}
+			out = append(out, classification.NewClassification(c, labels[i]))
+		}
+	} else {
+		for i, c := range outConf {
+			out = append(out, classification.NewClassification(c, strconv.Itoa(i)))
+		}
+	}
+	return out, nil
+}
+
+// orderedOutputs returns the model's output tensors as float32 slices in metadata order.
+func orderedOutputs(md mlmodel.MLMetadata, outMap mlmodel.Tensors) ([][]float32, error) {
+	tensors := make([][]float32, 0, len(md.Outputs))
+	for _, info := range md.Outputs {
+		t, ok := outMap[info.Name]
+		if !ok {
+			return nil, errors.Errorf("model did not return output tensor %q", info.Name)
+		}
+		data, ok := t.Data.([]float32)
+		if !ok {
+			floats, err := t.Float64s()
+			if err != nil {
+				return nil, err
+			}
+			data = make([]float32, len(floats))
+			for i, f := range floats {
+				data[i] = float32(f)
+			}
+		}
+		tensors = append(tensors, data)
+	}
+	return tensors, nil
+}
+
+func outputNames(md mlmodel.MLMetadata) []string {
+	names := make([]string, 0, len(md.Outputs))
+	for _, out := range md.Outputs {
+		names = append(names, out.Name)
+	}
+	return names
+}
+
+// outputLabels returns the class labels the model attaches to its outputs, if any.
+func outputLabels(md mlmodel.MLMetadata) []string {
+	for _, out := range md.Outputs {
+		switch labels := out.Extra["labels"].(type) {
+		case []string:
+			return labels
+		case []interface{}:
+			strs := make([]string, 0, len(labels))
+			for _, l := range labels {
+				s, ok := l.(string)
+				if !ok {
+					return nil
+				}
+				strs = append(strs, s)
+			}
+			return strs
+		default:
+		}
+	}
+	return nil
+}
+
+// intsFromExtra reads an int list from a tensor's extra info, which holds []interface{} of
+// float64 once it has been sent over the network.
+func intsFromExtra(extra map[string]interface{}, key string) ([]int, bool) {
+	switch v := extra[key].(type) {
+	case []int:
+		return v, true
+	case []interface{}:
+		ints := make([]int, 0, len(v))
+		for _, i := range v {
+			f, ok := i.(float64)
+			if !ok {
+				return nil, false
+			}
+			ints = append(ints, int(f))
+		}
+		return ints, true
+	default:
+		return nil, false
+	}
+}
+
+// getTensorOrder reads the order of the output tensors from their names,
+// returned as []int where 0=bounding box location, 1=class/category/label, 2= confidence score.
+func getTensorOrder(names []string) ([]int, []bool) {
+	tensorOrder := make([]int, 3) // location = 0 , category = 1, score = 2
+
+	found := make([]bool, 3)
+
+	for i, n := range names {
+		if i >= len(tensorOrder) {
+			break
+		}
+		switch name := strings.ToLower(n); name {
+		case "location", "locations":
+			tensorOrder[i] = 0
+			found[0] = true
+		case "category", "class", "classes":
+			tensorOrder[i] = 1
+			found[1] = true
+		case "score", "scores":
+			tensorOrder[i] = 2
+			found[2] = true
+		default:
+			continue
+		}
+	}
+
+	return tensorOrder, found
+}
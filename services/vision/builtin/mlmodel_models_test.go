@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestMLModelDetector(t *testing.T) {
+	ctx := context.Background()
+	fakeModel := &inject.MLModelService{}
+	fakeModel.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{
+			Inputs: []mlmodel.TensorInfo{{Name: "image", DataType: "uint8", Shape: []int{1, 4, 4, 3}}},
+			Outputs: []mlmodel.TensorInfo{
+				{Name: "location", DataType: "float32", Extra: map[string]interface{}{
+					"box_order": []interface{}{1.0, 0.0, 3.0, 2.0},
+					"labels":    []interface{}{"cat", "dog"},
+				}},
+				{Name: "category", DataType: "float32"},
+				{Name: "score", DataType: "float32"},
+			},
+		}, nil
+	}
+	var gotInput mlmodel.Tensors
+	fakeModel.InferFunc = func(
+		ctx context.Context,
+		input mlmodel.Tensors,
+		extra map[string]interface{},
+	) (mlmodel.Tensors, error) {
+		gotInput = input
+		location, err := mlmodel.NewTensor([]float32{0.1, 0.2, 0.5, 0.6}, 1, 1, 4)
+		if err != nil {
+			return nil, err
+		}
+		category, err := mlmodel.NewTensor([]float32{1})
+		if err != nil {
+			return nil, err
+		}
+		score, err := mlmodel.NewTensor([]float32{0.9})
+		if err != nil {
+			return nil, err
+		}
+		return mlmodel.Tensors{"location": location, "category": category, "score": score}, nil
+	}
+
+	detector, err := attemptToBuildDetector(ctx, fakeModel, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	detections, err := detector(ctx, image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, gotInput["image"].Shape, test.ShouldResemble, []int{1, 4, 4, 3})
+	test.That(t, gotInput["image"].DataType(), test.ShouldEqual, "uint8")
+	test.That(t, detections, test.ShouldHaveLength, 1)
+	test.That(t, detections[0].Label(), test.ShouldEqual, "dog")
+	test.That(t, detections[0].Score(), test.ShouldAlmostEqual, 0.9, 1e-6)
+	test.That(t, detections[0].BoundingBox(), test.ShouldResemble, &image.Rectangle{image.Pt(20, 10), image.Pt(60, 50)})
+
+	fakeModel.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{Inputs: []mlmodel.TensorInfo{{Name: "audio", Shape: []int{16000}}}}, nil
+	}
+	_, err = attemptToBuildDetector(ctx, fakeModel, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "4 dimensional")
+}
+
+func TestMLModelClassifier(t *testing.T) {
+	ctx := context.Background()
+	fakeModel := &inject.MLModelService{}
+	fakeModel.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{
+			Inputs:  []mlmodel.TensorInfo{{Name: "image", DataType: "float32", Shape: []int{1, 3, 2, 2}}},
+			Outputs: []mlmodel.TensorInfo{{Name: "probability", DataType: "uint8"}},
+		}, nil
+	}
+	fakeModel.InferFunc = func(
+		ctx context.Context,
+		input mlmodel.Tensors,
+		extra map[string]interface{},
+	) (mlmodel.Tensors, error) {
+		if input["image"].DataType() != "float32" {
+			return nil, errors.New("expected a float32 image")
+		}
+		probability, err := mlmodel.NewTensor([]uint8{64, 192})
+		if err != nil {
+			return nil, err
+		}
+		return mlmodel.Tensors{"probability": probability}, nil
+	}
+
+	classifier, err := attemptToBuildClassifier(ctx, fakeModel)
+	test.That(t, err, test.ShouldBeNil)
+	classifications, err := classifier(ctx, image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	test.That(t, err, test.ShouldBeNil)
+	best, err := classifications.TopN(1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, best[0].Label(), test.ShouldEqual, "1")
+	test.That(t, best[0].Score(), test.ShouldAlmostEqual, 0.75)
+}
+
+func TestMLModelFromRobot(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	fakeModel := &inject.MLModelService{}
+	fakeModel.MetadataFunc = func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{
+			Inputs: []mlmodel.TensorInfo{{Name: "image", DataType: "uint8", Shape: []int{1, 4, 4, 3}}},
+			Outputs: []mlmodel.TensorInfo{
+				{Name: "location", DataType: "float32"},
+				{Name: "category", DataType: "float32"},
+				{Name: "score", DataType: "float32"},
+			},
+		}, nil
+	}
+	r := &inject.Robot{}
+	r.MockResourcesFromMap(map[resource.Name]interface{}{mlmodel.Named("mymodel"): fakeModel})
+
+	conf := &vision.Attributes{
+		ModelRegistry: []vision.VisModelConfig{
+			{
+				Name:       "detector",
+				Type:       string(TFLiteDetector),
+				Parameters: config.AttributeMap{"ml_model_name": "mymodel"},
+			},
+		},
+	}
+	deps, err := conf.Validate("services.0.attributes")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"mymodel"})
+
+	reg := make(modelMap)
+	err = registerNewVisModels(ctx, reg, conf, r, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reg.DetectorNames(), test.ShouldContain, "detector")
+	// the robot owns the ML model service, so the vision service must not close it
+	test.That(t, reg["detector"].Closer, test.ShouldBeNil)
+
+	reg = make(modelMap)
+	err = registerNewVisModels(ctx, reg, conf, nil, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "without a robot")
+
+	conf.ModelRegistry[0].Parameters["ml_model_name"] = 3
+	_, err = conf.Validate("services.0.attributes")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "ml_model_name")
+}
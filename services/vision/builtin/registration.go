@@ -10,6 +10,7 @@ import (
 	"go.opencensus.io/trace"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/utils"
 	objdet "go.viam.com/rdk/vision/objectdetection"
@@ -42,33 +43,45 @@ func registerColorDetector(ctx context.Context, mm modelMap, conf *vision.VisMod
 	return mm.RegisterVisModel(conf.Name, &regModel, logger)
 }
 
-func registerTfliteClassifier(ctx context.Context, mm modelMap, conf *vision.VisModelConfig, logger golog.Logger) error {
+func registerTfliteClassifier(
+	ctx context.Context,
+	mm modelMap,
+	conf *vision.VisModelConfig,
+	r robot.Robot,
+	logger golog.Logger,
+) error {
 	ctx, span := trace.StartSpan(ctx, "service::vision::registerTfliteClassifier")
 	defer span.End()
 	if conf == nil {
 		return errors.New("object detection config for tflite classifier cannot be nil")
 	}
-	classifier, model, err := NewTFLiteClassifier(ctx, conf, logger)
+	classifier, closer, err := NewTFLiteClassifier(ctx, conf, r, logger)
 	if err != nil {
 		return errors.Wrapf(err, "could not register tflite classifier %s", conf.Name)
 	}
 
-	regModel := registeredModel{Model: classifier, ModelType: TFLiteClassifier, Closer: model}
+	regModel := registeredModel{Model: classifier, ModelType: TFLiteClassifier, Closer: closer}
 	return mm.RegisterVisModel(conf.Name, &regModel, logger)
 }
 
-func registerTfliteDetector(ctx context.Context, mm modelMap, conf *vision.VisModelConfig, logger golog.Logger) error {
+func registerTfliteDetector(
+	ctx context.Context,
+	mm modelMap,
+	conf *vision.VisModelConfig,
+	r robot.Robot,
+	logger golog.Logger,
+) error {
 	ctx, span := trace.StartSpan(ctx, "service::vision::registerTfliteDetector")
 	defer span.End()
 	if conf == nil {
 		return errors.New("object detection config for tflite detector cannot be nil")
 	}
-	detector, model, err := NewTFLiteDetector(ctx, conf, logger)
+	detector, closer, err := NewTFLiteDetector(ctx, conf, r, logger)
 	if err != nil {
 		return errors.Wrapf(err, "could not register tflite detector %s", conf.Name)
 	}
 
-	regModel := registeredModel{Model: detector, ModelType: TFLiteDetector, Closer: model}
+	regModel := registeredModel{Model: detector, ModelType: TFLiteDetector, Closer: closer}
 	return mm.RegisterVisModel(conf.Name, &regModel, logger)
 }
 
@@ -119,3 +132,38 @@ func registerSegmenterFromDetector(ctx context.Context, mm modelMap, conf *visio
 	regModel := registeredModel{Model: segmenter, ModelType: DetectorSegmenter, Closer: nil}
 	return mm.RegisterVisModel(conf.Name, &regModel, logger)
 }
+
+// registerTrackingDetector wraps a registered detector with a tracker, so its detections are
+// TrackedDetections. Detections from different cameras should use different tracking detectors.
+func registerTrackingDetector(ctx context.Context, mm modelMap, conf *vision.VisModelConfig, logger golog.Logger) error {
+	_, span := trace.StartSpan(ctx, "service::vision::registerTrackingDetector")
+	defer span.End()
+	if conf == nil {
+		return errors.New("config for tracking detector cannot be nil")
+	}
+	var p objdet.TrackerConfig
+	attrs, err := config.TransformAttributeMapToStruct(&p, conf.Parameters)
+	if err != nil {
+		return errors.Wrapf(err, "register tracking detector %s", conf.Name)
+	}
+	params, ok := attrs.(*objdet.TrackerConfig)
+	if !ok {
+		err := utils.NewUnexpectedTypeError(params, attrs)
+		return errors.Wrapf(err, "register tracking detector %s", conf.Name)
+	}
+	// check if detector name is in registry
+	d, err := mm.modelLookup(params.DetectorName)
+	if err != nil {
+		return errors.Wrapf(err, "register tracking detector %s", conf.Name)
+	}
+	detector, err := d.toDetector()
+	if err != nil {
+		return errors.Wrapf(err, "register tracking detector %s", conf.Name)
+	}
+	tracker, err := objdet.NewTracker(params)
+	if err != nil {
+		return errors.Wrapf(err, "register tracking detector %s", conf.Name)
+	}
+	regModel := registeredModel{Model: objdet.NewTrackingDetector(detector, tracker), ModelType: TrackingDetector, Closer: nil}
+	return mm.RegisterVisModel(conf.Name, &regModel, logger)
+}
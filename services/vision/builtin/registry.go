@@ -12,6 +12,7 @@ import (
 	"go.opencensus.io/trace"
 	"go.uber.org/multierr"
 
+	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/vision/classification"
 	"go.viam.com/rdk/vision/objectdetection"
@@ -30,6 +31,7 @@ const (
 	TFClassifier      = vision.VisModelType("tf_classifier")
 	RCSegmenter       = vision.VisModelType("radius_clustering_segmenter")
 	DetectorSegmenter = vision.VisModelType("detector_segmenter")
+	TrackingDetector  = vision.VisModelType("tracking_detector")
 )
 
 // registeredModelParameterSchemas maps the vision model types to the necessary parameters needed to create them.
@@ -39,6 +41,7 @@ var registeredModelParameterSchemas = map[vision.VisModelType]*jsonschema.Schema
 	TFLiteClassifier:  jsonschema.Reflect(&TFLiteClassifierConfig{}),
 	RCSegmenter:       jsonschema.Reflect(&segmentation.RadiusClusteringConfig{}),
 	DetectorSegmenter: jsonschema.Reflect(&segmentation.DetectionSegmenterConfig{}),
+	TrackingDetector:  jsonschema.Reflect(&objectdetection.TrackerConfig{}),
 }
 
 // The set of operations supported by the vision model types.
@@ -57,6 +60,7 @@ var visModelToOpMap = map[vision.VisModelType]VisOperation{
 	TFClassifier:      VisClassification,
 	RCSegmenter:       VisSegmentation,
 	DetectorSegmenter: VisSegmentation,
+	TrackingDetector:  VisDetection,
 }
 
 // newVisModelTypeNotImplemented is used when the model type is not implemented.
@@ -207,8 +211,14 @@ func (mm modelMap) RegisterVisModel(name string, m *registeredModel, logger golo
 }
 
 // registerNewVisModels take an attributes struct and parses each element by type to create an RDK Detector
-// and register it to the detector map.
-func registerNewVisModels(ctx context.Context, mm modelMap, attrs *vision.Attributes, logger golog.Logger) error {
+// and register it to the detector map. Models that run on an ML model service get it from the robot.
+func registerNewVisModels(
+	ctx context.Context,
+	mm modelMap,
+	attrs *vision.Attributes,
+	r robot.Robot,
+	logger golog.Logger,
+) error {
 	_, span := trace.StartSpan(ctx, "service::vision::registerNewVisModels")
 	defer span.End()
 	var err error
@@ -216,9 +226,9 @@ func registerNewVisModels(ctx context.Context, mm modelMap, attrs *vision.Attrib
 		logger.Debugf("adding vision model %q of type %q", attr.Name, attr.Type)
 		switch vision.VisModelType(attr.Type) {
 		case TFLiteDetector:
-			multierr.AppendInto(&err, registerTfliteDetector(ctx, mm, &attr, logger))
+			multierr.AppendInto(&err, registerTfliteDetector(ctx, mm, &attr, r, logger))
 		case TFLiteClassifier:
-			multierr.AppendInto(&err, registerTfliteClassifier(ctx, mm, &attr, logger))
+			multierr.AppendInto(&err, registerTfliteClassifier(ctx, mm, &attr, r, logger))
 		case TFDetector:
 			multierr.AppendInto(&err, newVisModelTypeNotImplemented(attr.Type))
 		case TFClassifier:
@@ -229,6 +239,8 @@ func registerNewVisModels(ctx context.Context, mm modelMap, attrs *vision.Attrib
 			multierr.AppendInto(&err, registerRCSegmenter(ctx, mm, &attr, logger))
 		case DetectorSegmenter:
 			multierr.AppendInto(&err, registerSegmenterFromDetector(ctx, mm, &attr, logger))
+		case TrackingDetector:
+			multierr.AppendInto(&err, registerTrackingDetector(ctx, mm, &attr, logger))
 		default:
 			multierr.AppendInto(&err, newVisModelTypeNotImplemented(attr.Type))
 		}
@@ -82,10 +82,10 @@ func TestDetectorRemoval(t *testing.T) {
 		return []objdet.Detection{objdet.NewDetection(image.Rectangle{}, 0.0, "")}, nil
 	}
 	ctx := context.Background()
-	closer, err := addTFLiteModel(ctx, artifact.MustPath("vision/tflite/effdet0.tflite"), nil)
-	test.That(t, err, test.ShouldBeNil)
-	d := registeredModel{Model: fakeDetectFn, Closer: closer, ModelType: TFLiteDetector}
 	testlog := golog.NewTestLogger(t)
+	model, err := addTFLiteModel(ctx, "x", artifact.MustPath("vision/tflite/effdet0.tflite"), 0, nil, testlog)
+	test.That(t, err, test.ShouldBeNil)
+	d := registeredModel{Model: fakeDetectFn, Closer: modelCloser{model}, ModelType: TFLiteDetector}
 	reg := make(modelMap)
 	err = reg.RegisterVisModel("x", &d, testlog)
 	test.That(t, err, test.ShouldBeNil)
@@ -117,7 +117,7 @@ func TestRegisterTFLiteDetector(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeNil)
 }
 
@@ -132,7 +132,7 @@ func TestRegisterTensorFlowDetector(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeError, newVisModelTypeNotImplemented("tf_detector"))
 }
 
@@ -151,17 +151,60 @@ func TestRegisterColorDetector(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeNil)
 	_, err = reg.modelLookup("my_color_det")
 	test.That(t, err, test.ShouldBeNil)
 
 	// error from bad config
 	conf.ModelRegistry[0].Parameters = nil
-	err = registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err = registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err.Error(), test.ShouldContainSubstring, "unexpected EOF")
 }
 
+func TestRegisterTrackingDetector(t *testing.T) {
+	conf := &vision.Attributes{
+		ModelRegistry: []vision.VisModelConfig{
+			{
+				Name: "my_color_det",
+				Type: "color_detector",
+				Parameters: config.AttributeMap{
+					"segment_size_px":   150000,
+					"hue_tolerance_pct": 0.44,
+					"detect_color":      "#4F3815",
+				},
+			},
+			{
+				Name: "my_tracker",
+				Type: "tracking_detector",
+				Parameters: config.AttributeMap{
+					"detector_name":     "my_color_det",
+					"max_missed_frames": 10,
+				},
+			},
+		},
+	}
+	reg := make(modelMap)
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reg.DetectorNames(), test.ShouldContain, "my_tracker")
+	m, err := reg.modelLookup("my_tracker")
+	test.That(t, err, test.ShouldBeNil)
+	detector, err := m.toDetector()
+	test.That(t, err, test.ShouldBeNil)
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	dets, err := detector(context.Background(), img)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+
+	// the detector has to be registered first
+	conf.ModelRegistry = conf.ModelRegistry[1:]
+	conf.ModelRegistry[0].Parameters["detector_name"] = "not_there"
+	err = registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `no such vision model with name "not_there"`)
+}
+
 func TestRegisterUnknown(t *testing.T) {
 	conf := &vision.Attributes{
 		ModelRegistry: []vision.VisModelConfig{
@@ -173,7 +216,7 @@ func TestRegisterUnknown(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeError, newVisModelTypeNotImplemented("not_real"))
 }
 
@@ -218,10 +261,10 @@ func TestClassifierRemoval(t *testing.T) {
 		return []classification.Classification{classification.NewClassification(0.0, "nothing")}, nil
 	}
 	ctx := context.Background()
-	closer, err := addTFLiteModel(ctx, artifact.MustPath("vision/tflite/effnet0.tflite"), nil)
-	test.That(t, err, test.ShouldBeNil)
-	d := registeredModel{Model: fakeClassifyFn, Closer: closer, ModelType: TFLiteClassifier}
 	testlog := golog.NewTestLogger(t)
+	model, err := addTFLiteModel(ctx, "x", artifact.MustPath("vision/tflite/effnet0.tflite"), 0, nil, testlog)
+	test.That(t, err, test.ShouldBeNil)
+	d := registeredModel{Model: fakeClassifyFn, Closer: modelCloser{model}, ModelType: TFLiteClassifier}
 	reg := make(modelMap)
 	err = reg.RegisterVisModel("x", &d, testlog)
 	test.That(t, err, test.ShouldBeNil)
@@ -253,7 +296,7 @@ func TestRegisterTFLiteClassifier(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeNil)
 }
 
@@ -268,7 +311,7 @@ func TestRegisterTensorFlowClassifier(t *testing.T) {
 		},
 	}
 	reg := make(modelMap)
-	err := registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	err := registerNewVisModels(context.Background(), reg, conf, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeError, newVisModelTypeNotImplemented("tf_classifier"))
 }
 
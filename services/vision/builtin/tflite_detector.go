@@ -3,23 +3,22 @@
 package builtin
 
 import (
-	"bufio"
 	"context"
 	"image"
+	"io"
 	"os"
-	fp "path/filepath"
 	"runtime"
 	"strconv"
-	"strings"
 
 	"github.com/edaniels/golog"
-	"github.com/nfnt/resize"
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/config"
-	inf "go.viam.com/rdk/ml/inference"
-	"go.viam.com/rdk/ml/inference/tflite_metadata"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/services/mlmodel/tflitecpu"
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/utils"
 	"go.viam.com/rdk/vision/objectdetection"
@@ -32,16 +31,22 @@ type TFLiteDetectorConfig struct {
 	NumThreads int     `json:"num_threads"`
 	LabelPath  *string `json:"label_path"`
 	ServiceURL *string `json:"service_url"`
+	// MLModelName is the name of an ML model service on the robot to detect with, instead of
+	// loading the model at ModelPath.
+	MLModelName string `json:"ml_model_name,omitempty"`
 }
 
 // NewTFLiteDetector creates an RDK detector given a DetectorConfig. In other words, this
-// function returns a function from image-->[]objectdetection.Detection. It does this by making calls to
-// an inference package and wrapping the result.
+// function returns a function from image-->[]objectdetection.Detection. It does this by wrapping
+// the output tensors of the robot's ML model service named in the config, or of the model at the
+// config's path loaded as a tflite_cpu ML model. The returned closer closes a loaded model, and
+// is nil when the model belongs to the robot.
 func NewTFLiteDetector(
 	ctx context.Context,
 	cfg *vision.VisModelConfig,
+	r robot.Robot,
 	logger golog.Logger,
-) (objectdetection.Detector, *inf.TFLiteStruct, error) {
+) (objectdetection.Detector, io.Closer, error) {
 	ctx, span := trace.StartSpan(ctx, "service::vision::NewTFLiteDetector")
 	defer span.End()
 
@@ -56,111 +61,71 @@ func NewTFLiteDetector(
 		err := utils.NewUnexpectedTypeError(params, tfParams)
 		return nil, nil, errors.Wrapf(err, "register tflite detector %s", cfg.Name)
 	}
-	// Secret but hard limit on num_threads
-	if params.NumThreads > runtime.NumCPU()/4 {
-		params.NumThreads = runtime.NumCPU() / 4
-	}
 
-	// Add the model
-	model, err := addTFLiteModel(ctx, params.ModelPath, &params.NumThreads)
+	model, closer, err := mlModelFromParams(ctx, cfg.Name, params.MLModelName, params.ModelPath, params.NumThreads,
+		params.LabelPath, r, logger)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "something wrong with adding the model")
-	}
-
-	var inHeight, inWidth uint
-
-	if shape := model.Info.InputShape; getIndex(shape, 3) == 1 {
-		inHeight, inWidth = uint(shape[2]), uint(shape[3])
-	} else {
-		inHeight, inWidth = uint(shape[1]), uint(shape[2])
-	}
-
-	if params.LabelPath == nil {
-		blank := ""
-		params.LabelPath = &blank
+		return nil, nil, err
 	}
-
-	labelMap, err := loadLabels(*params.LabelPath)
+	detector, err := attemptToBuildDetector(ctx, model, logger)
 	if err != nil {
-		logger.Warn("did not retrieve class labels")
+		if closer != nil {
+			err = multierr.Combine(err, closer.Close())
+		}
+		return nil, nil, err
 	}
+	return detector, closer, nil
+}
 
-	// This function to be returned is the detector.
-	return func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
-		origW, origH := img.Bounds().Dx(), img.Bounds().Dy()
-		resizedImg := resize.Resize(inHeight, inWidth, img, resize.Bilinear)
-		outTensors, err := tfliteInfer(ctx, model, resizedImg)
+// mlModelFromParams returns the robot's ML model service named mlModelName or, when that is
+// empty, loads the model at modelPath as a tflite_cpu ML model, along with a closer for it.
+func mlModelFromParams(
+	ctx context.Context,
+	name, mlModelName, modelPath string,
+	numThreads int,
+	labelPath *string,
+	r robot.Robot,
+	logger golog.Logger,
+) (mlmodel.Service, io.Closer, error) {
+	if mlModelName != "" {
+		if r == nil {
+			return nil, nil, errors.Errorf("cannot use ML model service %q without a robot", mlModelName)
+		}
+		model, err := mlmodel.FromRobot(r, mlModelName)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		detections := unpackTensors(ctx, outTensors, model, labelMap, logger, origW, origH)
-		return detections, nil
-	}, model, nil
-}
-
-// addTFLiteModel uses the loader (default or otherwise) from the inference package
-// to register a tflite model. Default is chosen if there's no numThreads given.
-func addTFLiteModel(ctx context.Context, filepath string, numThreads *int) (*inf.TFLiteStruct, error) {
-	_, span := trace.StartSpan(ctx, "service::vision::addTFLiteModel")
-	defer span.End()
-	var model *inf.TFLiteStruct
-	var loader *inf.TFLiteModelLoader
-	var err error
-
-	if numThreads == nil {
-		loader, err = inf.NewDefaultTFLiteModelLoader()
-	} else {
-		loader, err = inf.NewTFLiteModelLoader(*numThreads)
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get loader")
-	}
-
-	fullpath, err2 := fp.Abs(filepath)
-	if err2 != nil {
-		model, err = loader.Load(filepath)
-	} else {
-		model, err = loader.Load(fullpath)
+		return model, nil, nil
 	}
-
+	model, err := addTFLiteModel(ctx, name, modelPath, numThreads, labelPath, logger)
 	if err != nil {
-		if strings.Contains(err.Error(), "failed to load") {
-			if err2 != nil {
-				return nil, errors.Wrapf(err, "file not found at %s", filepath)
-			}
-			return nil, errors.Wrapf(err, "file not found at %s", fullpath)
-		}
-		return nil, errors.Wrap(err, "loader could not load model")
+		return nil, nil, err
 	}
-
-	return model, nil
+	return model, modelCloser{model}, nil
 }
 
-// tfliteInfer first converts an input image to a buffer using the imageToBuffer func
-// and then uses the Infer function form the inference package to return the output tensors from the model.
-func tfliteInfer(ctx context.Context, model *inf.TFLiteStruct, image image.Image) ([]interface{}, error) {
-	_, span := trace.StartSpan(ctx, "service::vision::tfliteInfer")
-	defer span.End()
-
-	// Converts the image to bytes before sending it off
-	switch model.Info.InputTensorType {
-	case inf.UInt8:
-		imgBuff := ImageToUInt8Buffer(image)
-		out, err := model.Infer(imgBuff)
-		if err != nil {
-			return nil, errors.Wrap(err, "couldn't infer from model")
-		}
-		return out, nil
-	case inf.Float32:
-		imgBuff := ImageToFloatBuffer(image)
-		out, err := model.Infer(imgBuff)
-		if err != nil {
-			return nil, errors.Wrap(err, "couldn't infer from model")
+// addTFLiteModel loads a tflite model as an ML model service. A label file that cannot be read
+// only produces a warning, so that the model can still be used with numeric labels.
+func addTFLiteModel(
+	ctx context.Context,
+	name, modelPath string,
+	numThreads int,
+	labelPath *string,
+	logger golog.Logger,
+) (*tflitecpu.TFLiteCPUModel, error) {
+	// Secret but hard limit on num_threads
+	if numThreads > runtime.NumCPU()/4 {
+		numThreads = runtime.NumCPU() / 4
+	}
+	conf := &tflitecpu.TFLiteConfig{ModelPath: modelPath, NumThreads: numThreads}
+	if labelPath != nil && *labelPath != "" {
+		if _, err := os.Stat(*labelPath); err != nil {
+			logger.Warn("did not retrieve class labels")
+		} else {
+			conf.LabelPath = *labelPath
 		}
-		return out, nil
-	default:
-		return nil, errors.New("invalid input type. try uint8 or float32")
 	}
+	return tflitecpu.NewTFLiteCPUModel(ctx, conf, name)
 }
 
 // ImageToUInt8Buffer reads an image into a byte slice in the most common sense way.
@@ -209,30 +174,15 @@ func rgbaTo8Bit(r, g, b, a uint32) (rr, gg, bb, aa uint8) {
 }
 
 // unpackTensors takes the model's output tensors as input and reshapes them into objdet.Detections.
-func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLiteStruct,
+func unpackTensors(ctx context.Context, tensors [][]float32, names []string, boxOrder []int,
 	labelMap []string, logger golog.Logger, origW, origH int,
 ) []objectdetection.Detection {
 	_, span := trace.StartSpan(ctx, "service::vision::unpackTensors")
 	defer span.End()
-	var hasMetadata bool
-	var boxOrder []int
-
-	// Read metadata
-	m, err := model.Metadata()
 
-	if err != nil {
-		hasMetadata = false
-	} else {
-		hasMetadata = true
-	}
-
-	// Figure out bounding box order. If we can't find it, should be empty [] (not nil)
-	if hasMetadata {
-		boxOrder, err = getBboxOrder(m)
-		if err != nil {
-			logger.Warn("assuming bounding box tensor is in the default order: [x x y y]")
-		}
-	}
+	// The model describes its tensors if it names the location tensor
+	tensorOrder, found := getTensorOrder(names)
+	hasMetadata := found[0]
 
 	var labels []int
 	var bboxes []float64
@@ -242,10 +192,10 @@ func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLite
 	// Based on the number of output tensors and their content, make a guess about which output tensors
 	// are bounding boxes, which are labels, and which are scores
 	if !hasMetadata {
-		switch model.Info.OutputTensorCount {
+		switch len(tensors) {
 		case 1:
 			// There's only one thing so assume it's bounding boxes
-			T0, _ := tensors[0].([]float32)
+			T0 := tensors[0]
 			for _, b := range T0 {
 				bboxes = append(bboxes, float64(b))
 			}
@@ -256,8 +206,7 @@ func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLite
 			// to determine whether score/label
 			var guessedBboxes []float32
 			var guessedScoresLabels []float32
-			T0, _ := tensors[0].([]float32)
-			T1, _ := tensors[1].([]float32)
+			T0, T1 := tensors[0], tensors[1]
 			if len(T0) > len(T1) {
 				guessedBboxes = T0
 				guessedScoresLabels = T1
@@ -284,9 +233,7 @@ func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLite
 			var guessedBboxes []float32
 			var guessedScores []float32
 			var guessedLabels []float32
-			T0, _ := tensors[0].([]float32)
-			T1, _ := tensors[1].([]float32)
-			T2, _ := tensors[2].([]float32)
+			T0, T1, T2 := tensors[0], tensors[1], tensors[2]
 			if (len(T0) > len(T1)) && (len(T0) > len(T2)) { // T0 is bboxes
 				guessedBboxes = T0
 				guessedScores = T1
@@ -323,23 +270,22 @@ func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLite
 			}
 		}
 	} else { // if we do have metadata, just read the tensor order from there.
-		tensorOrder, found := getTensorOrder(m)
 		if found[0] {
-			for _, b := range tensors[getIndex(tensorOrder, 0)].([]float32) {
+			for _, b := range tensors[getIndex(tensorOrder, 0)] {
 				bboxes = append(bboxes, float64(b))
 			}
 		}
 		if found[1] {
-			for _, l := range tensors[getIndex(tensorOrder, 1)].([]float32) {
+			for _, l := range tensors[getIndex(tensorOrder, 1)] {
 				labels = append(labels, int(l))
 			}
 		}
 		if found[2] {
-			for _, s := range tensors[getIndex(tensorOrder, 2)].([]float32) {
+			for _, s := range tensors[getIndex(tensorOrder, 2)] {
 				scores = append(scores, float64(s))
 			}
 		}
-		count = len(tensors[getIndex(tensorOrder, 0)].([]float32)) / 4
+		count = len(tensors[getIndex(tensorOrder, 0)]) / 4
 	}
 
 	// If we don't know the bounding box order, assume the first two are x-values
@@ -396,29 +342,6 @@ func unpackTensors(ctx context.Context, tensors []interface{}, model *inf.TFLite
 	return detections
 }
 
-// loadLabels reads a labelmap.txt file from filename and returns a slice of the labels
-// (stolen from https:// github.com/mattn/go-tflite).
-func loadLabels(filename string) ([]string, error) {
-	if filename == "" {
-		return nil, errors.New("no labelpath")
-	}
-	labels := []string{}
-	f, err := os.Open(filename) //nolint:gosec
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			panic(err)
-		}
-	}()
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		labels = append(labels, scanner.Text())
-	}
-	return labels, nil
-}
-
 // getIndex just returns the index of an int in an array of ints
 // Will return -1 if it's not there.
 func getIndex(s []int, num int) int {
@@ -429,52 +352,3 @@ func getIndex(s []int, num int) int {
 	}
 	return -1
 }
-
-// getBboxOrder checks the metadata and looks for the bounding box order
-// returned as []int, where 0=xmin, 1=xmax, 2=ymin, 3=ymax.
-func getBboxOrder(m *tflite_metadata.ModelMetadataT) ([]int, error) {
-	// tensorData should be a []TensorMetadataT from the metadata telling me about each tensor in order
-	tensorData := m.SubgraphMetadata[0].OutputTensorMetadata
-	for _, t := range tensorData {
-		if !strings.HasPrefix(t.Name, "location") {
-			continue
-		}
-
-		bboxOrder := make([]int, 4)
-		order := t.Content.ContentProperties.Value.(*tflite_metadata.BoundingBoxPropertiesT).Index
-		for i, o := range order {
-			bboxOrder[i] = int(o)
-		}
-		return bboxOrder, nil
-	}
-
-	return nil, errors.New("cannot find location in getBboxOrder")
-}
-
-// getTensorOrder checks the metadata for the order of the output tensors
-// returned as []int where 0=bounding box location, 1=class/category/label, 2= confidence score.
-func getTensorOrder(m *tflite_metadata.ModelMetadataT) ([]int, []bool) {
-	tensorOrder := make([]int, 3) // location = 0 , category = 1, score = 2
-
-	tensorData := m.SubgraphMetadata[0].OutputTensorMetadata
-
-	found := make([]bool, 3)
-
-	for i, t := range tensorData {
-		switch name := strings.ToLower(t.Name); name {
-		case "location", "locations":
-			tensorOrder[i] = 0
-			found[0] = true
-		case "category", "class", "classes":
-			tensorOrder[i] = 1
-			found[1] = true
-		case "score", "scores":
-			tensorOrder[i] = 2
-			found[2] = true
-		default:
-			continue
-		}
-	}
-
-	return tensorOrder, found
-}
@@ -27,7 +27,7 @@ func BenchmarkAddTFLiteDetector(b *testing.B) {
 	logger := golog.NewLogger("benchmark")
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _, err := NewTFLiteDetector(ctx, &cfg, logger)
+		_, _, err := NewTFLiteDetector(ctx, &cfg, nil, logger)
 		test.That(b, err, test.ShouldBeNil)
 	}
 }
@@ -46,7 +46,7 @@ func BenchmarkGetTFLiteDetections(b *testing.B) {
 	}
 	ctx := context.Background()
 	logger := golog.NewLogger("benchmark")
-	det, model, err := NewTFLiteDetector(ctx, &cfg, logger)
+	det, model, err := NewTFLiteDetector(ctx, &cfg, nil, logger)
 	test.That(b, model, test.ShouldNotBeNil)
 	test.That(b, err, test.ShouldBeNil)
 
@@ -62,7 +62,7 @@ func TestNewTfLiteDetector(t *testing.T) {
 	// Test that empty config gives error about loading model
 	emptyCfg := vision.VisModelConfig{}
 	ctx := context.Background()
-	got, model, err := NewTFLiteDetector(ctx, &emptyCfg, golog.NewTestLogger(t))
+	got, model, err := NewTFLiteDetector(ctx, &emptyCfg, nil, golog.NewTestLogger(t))
 	test.That(t, model, test.ShouldBeNil)
 	test.That(t, got, test.ShouldBeNil)
 	test.That(t, err.Error(), test.ShouldContainSubstring, "something wrong with adding the model")
@@ -80,7 +80,7 @@ func TestNewTfLiteDetector(t *testing.T) {
 		},
 	}
 
-	got2, model, err := NewTFLiteDetector(ctx, &cfg, golog.NewTestLogger(t))
+	got2, model, err := NewTFLiteDetector(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, model, test.ShouldNotBeNil)
 	test.That(t, err, test.ShouldBeNil)
 
@@ -110,7 +110,7 @@ func TestMoreDetectorModels(t *testing.T) {
 			"num_threads": 2,
 		},
 	}
-	outSSD, outSSDModel, err := NewTFLiteDetector(ctx, &cfg, golog.NewTestLogger(t))
+	outSSD, outSSDModel, err := NewTFLiteDetector(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, outSSDModel, test.ShouldNotBeNil)
 	test.That(t, err, test.ShouldBeNil)
 
@@ -132,7 +132,7 @@ func TestMoreDetectorModels(t *testing.T) {
 		},
 	}
 
-	outMNet, outMNetModel, err := NewTFLiteDetector(ctx, &cfg, golog.NewTestLogger(t))
+	outMNet, outMNetModel, err := NewTFLiteDetector(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, outMNetModel, test.ShouldNotBeNil)
 	test.That(t, err, test.ShouldBeNil)
 
@@ -144,14 +144,6 @@ func TestMoreDetectorModels(t *testing.T) {
 	test.That(t, got2[1].Score(), test.ShouldBeGreaterThan, 0.89)
 }
 
-func TestLabelReader(t *testing.T) {
-	inputfile := artifact.MustPath("vision/tflite/fakelabels.txt")
-	got, err := loadLabels(inputfile)
-	test.That(t, err, test.ShouldBeNil)
-	test.That(t, got[0], test.ShouldResemble, "this")
-	test.That(t, len(got), test.ShouldEqual, 12)
-}
-
 func TestFileNotFound(t *testing.T) {
 
 	// Build SSD detector
@@ -165,7 +157,7 @@ func TestFileNotFound(t *testing.T) {
 			"num_threads": 2,
 		},
 	}
-	outDet, outModel, err := NewTFLiteDetector(ctx, &cfg, golog.NewTestLogger(t))
+	outDet, outModel, err := NewTFLiteDetector(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, outDet, test.ShouldBeNil)
 	test.That(t, outModel, test.ShouldBeNil)
 	test.That(t, err, test.ShouldNotBeNil)
@@ -177,7 +169,7 @@ func TestNewTfLiteClassifier(t *testing.T) {
 	// Test that empty config gives error about loading model
 	emptyCfg := vision.VisModelConfig{}
 	ctx := context.Background()
-	got, model, err := NewTFLiteClassifier(ctx, &emptyCfg, golog.NewTestLogger(t))
+	got, model, err := NewTFLiteClassifier(ctx, &emptyCfg, nil, golog.NewTestLogger(t))
 	test.That(t, model, test.ShouldBeNil)
 	test.That(t, got, test.ShouldBeNil)
 	test.That(t, err.Error(), test.ShouldContainSubstring, "something wrong with adding the model")
@@ -195,7 +187,7 @@ func TestNewTfLiteClassifier(t *testing.T) {
 		},
 	}
 
-	got2, model, err := NewTFLiteClassifier(ctx, &cfg, golog.NewTestLogger(t))
+	got2, model, err := NewTFLiteClassifier(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, model, test.ShouldNotBeNil)
 	test.That(t, err, test.ShouldBeNil)
 
@@ -223,7 +215,7 @@ func TestMoreClassifierModels(t *testing.T) {
 			"num_threads": 2,
 		},
 	}
-	got, _, err := NewTFLiteClassifier(ctx, &cfg, golog.NewTestLogger(t))
+	got, _, err := NewTFLiteClassifier(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeNil)
 	classifications, err := got(ctx, pic)
 	test.That(t, err, test.ShouldBeNil)
@@ -245,7 +237,7 @@ func TestMoreClassifierModels(t *testing.T) {
 			"num_threads": 2,
 		},
 	}
-	got2, _, err := NewTFLiteClassifier(ctx, &cfg, golog.NewTestLogger(t))
+	got2, _, err := NewTFLiteClassifier(ctx, &cfg, nil, golog.NewTestLogger(t))
 	test.That(t, err, test.ShouldBeNil)
 	classifications, err = got2(ctx, pic)
 	test.That(t, err, test.ShouldBeNil)
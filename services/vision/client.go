@@ -14,6 +14,8 @@ import (
 	pb "go.viam.com/api/service/vision/v1"
 	"go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"go.viam.com/rdk/pointcloud"
 	rprotoutils "go.viam.com/rdk/protoutils"
@@ -137,12 +139,13 @@ func (c *client) DetectionsFromCamera(
 	if err != nil {
 		return nil, err
 	}
+	var header metadata.MD
 	resp, err := c.client.GetDetectionsFromCamera(ctx, &pb.GetDetectionsFromCameraRequest{
 		Name:         c.name,
 		CameraName:   cameraName,
 		DetectorName: detectorName,
 		Extra:        ext,
-	})
+	}, grpc.Header(&header))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +158,7 @@ func (c *client) DetectionsFromCamera(
 		det := objdet.NewDetection(box, d.Confidence, d.ClassName)
 		detections = append(detections, det)
 	}
-	return detections, nil
+	return withTracks(detections, header)
 }
 
 func (c *client) Detections(ctx context.Context, img image.Image, detectorName string, extra map[string]interface{},
@@ -171,6 +174,7 @@ func (c *client) Detections(ctx context.Context, img image.Image, detectorName s
 	if err != nil {
 		return nil, err
 	}
+	var header metadata.MD
 	resp, err := c.client.GetDetections(ctx, &pb.GetDetectionsRequest{
 		Name:         c.name,
 		Image:        imgBytes,
@@ -179,7 +183,7 @@ func (c *client) Detections(ctx context.Context, img image.Image, detectorName s
 		MimeType:     mimeType,
 		DetectorName: detectorName,
 		Extra:        ext,
-	})
+	}, grpc.Header(&header))
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +196,7 @@ func (c *client) Detections(ctx context.Context, img image.Image, detectorName s
 		det := objdet.NewDetection(box, d.Confidence, d.ClassName)
 		detections = append(detections, det)
 	}
-	return detections, nil
+	return withTracks(detections, header)
 }
 
 func (c *client) ClassifierNames(ctx context.Context, extra map[string]interface{}) ([]string, error) {
@@ -5,6 +5,7 @@ import (
 	"image"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	servicepb "go.viam.com/api/service/vision/v1"
@@ -29,6 +30,7 @@ import (
 	"go.viam.com/rdk/testutils"
 	"go.viam.com/rdk/testutils/inject"
 	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/segmentation"
 )
 
@@ -356,6 +358,53 @@ func TestInjectedServiceClient(t *testing.T) {
 		test.That(t, utils.TryClose(context.Background(), workingDialedClient), test.ShouldBeNil)
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
+	t.Run("tracked detections", func(t *testing.T) {
+		tracker, err := objectdetection.NewTracker(&objectdetection.TrackerConfig{})
+		test.That(t, err, test.ShouldBeNil)
+		now := time.Now()
+		tracker.Update([]objectdetection.Detection{
+			objectdetection.NewDetection(image.Rect(0, 0, 10, 10), 0.5, "cat"),
+		}, now)
+		tracked := tracker.Update([]objectdetection.Detection{
+			objectdetection.NewDetection(image.Rect(5, 0, 15, 10), 0.5, "cat"),
+		}, now.Add(time.Second))
+		test.That(t, tracked, test.ShouldHaveLength, 1)
+
+		var extraOptions map[string]interface{}
+		injectVision.DetectionsFunc = func(ctx context.Context, img image.Image, detectorName string, extra map[string]interface{},
+		) ([]objectdetection.Detection, error) {
+			extraOptions = extra
+			return []objectdetection.Detection{
+				tracked[0],
+				// labels are sent as they are, whatever they contain
+				objectdetection.NewDetection(image.Rect(0, 0, 5, 5), 0.9, "dog#track:1:0:0:0"),
+			}, nil
+		}
+		conn, err := viamgrpc.Dial(context.Background(), listener1.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		client := vision.NewClientFromConn(context.Background(), conn, testVisionServiceName, logger)
+
+		extra := map[string]interface{}{"foo": "Detections"}
+		dets, err := client.Detections(context.Background(), image.NewRGBA(image.Rect(0, 0, 20, 20)), "tracker", extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+		test.That(t, dets, test.ShouldHaveLength, 2)
+
+		got, ok := dets[0].(objectdetection.TrackedDetection)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, got.Label(), test.ShouldEqual, "cat")
+		test.That(t, got.TrackID(), test.ShouldEqual, tracked[0].TrackID())
+		test.That(t, got.Velocity(), test.ShouldResemble, tracked[0].Velocity())
+		test.That(t, got.Age(), test.ShouldEqual, time.Second)
+		test.That(t, got.BoundingBox(), test.ShouldResemble, tracked[0].BoundingBox())
+
+		_, ok = dets[1].(objectdetection.TrackedDetection)
+		test.That(t, ok, test.ShouldBeFalse)
+		test.That(t, dets[1].Label(), test.ShouldEqual, "dog#track:1:0:0:0")
+
+		test.That(t, utils.TryClose(context.Background(), client), test.ShouldBeNil)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
 	t.Run("test segmentation", func(t *testing.T) {
 		params := config.AttributeMap{
 			"min_points_in_plane":   100,
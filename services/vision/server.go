@@ -162,6 +162,9 @@ func (server *subtypeServer) GetDetections(
 		}
 		protoDets = append(protoDets, d)
 	}
+	if err := setTracksHeader(ctx, detections); err != nil {
+		return nil, err
+	}
 	return &pb.GetDetectionsResponse{
 		Detections: protoDets,
 	}, nil
@@ -201,6 +204,9 @@ func (server *subtypeServer) GetDetectionsFromCamera(
 		}
 		protoDets = append(protoDets, d)
 	}
+	if err := setTracksHeader(ctx, detections); err != nil {
+		return nil, err
+	}
 	return &pb.GetDetectionsFromCameraResponse{
 		Detections: protoDets,
 	}, nil
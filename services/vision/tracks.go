@@ -0,0 +1,91 @@
+package vision
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	objdet "go.viam.com/rdk/vision/objectdetection"
+)
+
+// TracksMetadataKey is the key of the response header that carries the tracks of the tracked
+// detections of a GetDetections or GetDetectionsFromCamera response, since the vision API has no
+// fields for them. Its value is a JSON array of objects with the index of the detection in the
+// response, "track_id", "velocity_x" and "velocity_y" in pixels per second, and "age_ns". Clients
+// that do not know the header still get the detections, untracked.
+const TracksMetadataKey = "viam-detection-tracks"
+
+// detectionTrack is the track of the detection at Index of a response.
+type detectionTrack struct {
+	Index     int     `json:"index"`
+	TrackID   int     `json:"track_id"`
+	VelocityX float64 `json:"velocity_x"`
+	VelocityY float64 `json:"velocity_y"`
+	AgeNanos  int64   `json:"age_ns"`
+}
+
+// setTracksHeader sends the tracks of the tracked detections, if there are any, in the header of
+// the response.
+func setTracksHeader(ctx context.Context, detections []objdet.Detection) error {
+	var tracks []detectionTrack
+	for i, d := range detections {
+		tracked, ok := d.(objdet.TrackedDetection)
+		if !ok {
+			continue
+		}
+		velocity := tracked.Velocity()
+		tracks = append(tracks, detectionTrack{
+			Index:     i,
+			TrackID:   tracked.TrackID(),
+			VelocityX: velocity.X,
+			VelocityY: velocity.Y,
+			AgeNanos:  tracked.Age().Nanoseconds(),
+		})
+	}
+	if len(tracks) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(tracks)
+	if err != nil {
+		return err
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(TracksMetadataKey, string(data))); err != nil {
+		// there is no header to set when the server is called directly, rather than over grpc
+		if s, ok := status.FromError(err); !ok || s.Code() != codes.Internal {
+			goutils.UncheckedError(err)
+		}
+	}
+	return nil
+}
+
+// withTracks returns the detections of a response with those the header has tracks for tracked.
+func withTracks(detections []objdet.Detection, header metadata.MD) ([]objdet.Detection, error) {
+	values := header.Get(TracksMetadataKey)
+	if len(values) == 0 {
+		return detections, nil
+	}
+	var tracks []detectionTrack
+	if err := json.Unmarshal([]byte(values[0]), &tracks); err != nil {
+		return nil, errors.Wrap(err, "invalid detection tracks")
+	}
+	for _, track := range tracks {
+		if track.Index < 0 || track.Index >= len(detections) {
+			return nil, errors.Errorf("track of detection %d but got %d detections", track.Index, len(detections))
+		}
+		detections[track.Index] = objdet.NewTrackedDetection(
+			detections[track.Index],
+			track.TrackID,
+			r2.Point{X: track.VelocityX, Y: track.VelocityY},
+			time.Duration(track.AgeNanos),
+		)
+	}
+	return detections, nil
+}
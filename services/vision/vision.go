@@ -9,6 +9,7 @@ import (
 
 	"github.com/edaniels/golog"
 	"github.com/invopop/jsonschema"
+	"github.com/pkg/errors"
 	servicepb "go.viam.com/api/service/vision/v1"
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/rpc"
@@ -140,6 +141,26 @@ type Attributes struct {
 	ModelRegistry []VisModelConfig `json:"register_models"`
 }
 
+// Validate returns the ML model services that the models run on, which the vision service
+// depends on.
+func (attrs *Attributes) Validate(path string) ([]string, error) {
+	var deps []string
+	for i, model := range attrs.ModelRegistry {
+		name, ok := model.Parameters["ml_model_name"]
+		if !ok {
+			continue
+		}
+		mlModelName, ok := name.(string)
+		if !ok {
+			return nil, errors.Errorf("%s.register_models.%d: expected ml_model_name to be a string but got %T", path, i, name)
+		}
+		if mlModelName != "" {
+			deps = append(deps, mlModelName)
+		}
+	}
+	return deps, nil
+}
+
 type reconfigurableVision struct {
 	mu     sync.RWMutex
 	name   resource.Name
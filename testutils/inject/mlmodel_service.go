@@ -0,0 +1,47 @@
+package inject
+
+import (
+	"context"
+
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+// MLModelService represents a fake instance of an ML model service.
+type MLModelService struct {
+	mlmodel.Service
+	InferFunc func(ctx context.Context, input mlmodel.Tensors,
+		extra map[string]interface{}) (mlmodel.Tensors, error)
+	MetadataFunc  func(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error)
+	DoCommandFunc func(ctx context.Context,
+		cmd map[string]interface{}) (map[string]interface{}, error)
+}
+
+// Infer calls the injected InferFunc or the real version.
+func (s *MLModelService) Infer(
+	ctx context.Context,
+	input mlmodel.Tensors,
+	extra map[string]interface{},
+) (mlmodel.Tensors, error) {
+	if s.InferFunc == nil {
+		return s.Service.Infer(ctx, input, extra)
+	}
+	return s.InferFunc(ctx, input, extra)
+}
+
+// Metadata calls the injected MetadataFunc or the real version.
+func (s *MLModelService) Metadata(ctx context.Context, extra map[string]interface{}) (mlmodel.MLMetadata, error) {
+	if s.MetadataFunc == nil {
+		return s.Service.Metadata(ctx, extra)
+	}
+	return s.MetadataFunc(ctx, extra)
+}
+
+// DoCommand calls the injected DoCommand or the real variant.
+func (s *MLModelService) DoCommand(ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, error) {
+	if s.DoCommandFunc == nil {
+		return s.Service.DoCommand(ctx, cmd)
+	}
+	return s.DoCommandFunc(ctx, cmd)
+}
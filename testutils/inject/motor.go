@@ -18,6 +18,7 @@ type Motor struct {
 	PropertiesFunc        func(ctx context.Context, extra map[string]interface{}) (map[motor.Feature]bool, error)
 	StopFunc              func(ctx context.Context, extra map[string]interface{}) error
 	IsPoweredFunc         func(ctx context.Context, extra map[string]interface{}) (bool, float64, error)
+	DiagnosticsFunc       func(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error)
 }
 
 // SetPower calls the injected Power or the real version.
@@ -92,6 +93,14 @@ func (m *Motor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[
 	return m.DoFunc(ctx, cmd)
 }
 
+// Diagnostics calls the injected Diagnostics or the real version.
+func (m *Motor) Diagnostics(ctx context.Context, extra map[string]interface{}) (*motor.Diagnostics, error) {
+	if m.DiagnosticsFunc == nil {
+		return motor.DiagnosticsFromMotor(ctx, m.Motor, "(name unavailable)", extra)
+	}
+	return m.DiagnosticsFunc(ctx, extra)
+}
+
 // LocalMotor is an injected motor that supports additional features provided by RDK
 // (e.g. GoTillStop).
 type LocalMotor struct {
@@ -1,6 +1,8 @@
 package inject
 
 import (
+	"context"
+
 	"go.viam.com/rdk/components/board"
 )
 
@@ -17,3 +19,26 @@ func (s *SPI) OpenHandle() (board.SPIHandle, error) {
 	}
 	return s.OpenHandleFunc()
 }
+
+// SPIHandle is an injected SPIHandle.
+type SPIHandle struct {
+	board.SPIHandle
+	XferFunc  func(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error)
+	CloseFunc func() error
+}
+
+// Xfer calls the injected Xfer or the real version.
+func (h *SPIHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+	if h.XferFunc == nil {
+		return h.SPIHandle.Xfer(ctx, baud, chipSelect, mode, tx)
+	}
+	return h.XferFunc(ctx, baud, chipSelect, mode, tx)
+}
+
+// Close calls the injected Close or the real version.
+func (h *SPIHandle) Close() error {
+	if h.CloseFunc == nil {
+		return h.SPIHandle.Close()
+	}
+	return h.CloseFunc()
+}
@@ -0,0 +1,104 @@
+package simdevice
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// ADS1x15 registers.
+const (
+	ads1x15ConversionReg = 0x00
+	ads1x15ConfigReg     = 0x01
+	ads1x15StartBit      = 1 << 15
+	ads1x15DefaultConfig = 0x8583
+)
+
+// ads1x15FullScale is the full scale range in volts of each gain setting.
+var ads1x15FullScale = [8]float64{6.144, 4.096, 2.048, 1.024, 0.512, 0.256, 0.256, 0.256}
+
+// ads1x15Inputs are the positive and negative inputs of each multiplexer setting, where -1 is
+// ground.
+var ads1x15Inputs = [8][2]int{{0, 1}, {0, 3}, {1, 3}, {2, 3}, {0, -1}, {1, -1}, {2, -1}, {3, -1}}
+
+// ADS1x15 simulates a TI ADS1015 or ADS1115 ADC, whose registers are 16 bits wide. Single shot
+// conversions finish as soon as they are started.
+type ADS1x15 struct {
+	mu         sync.Mutex
+	bits       uint
+	pointer    byte
+	config     uint16
+	conversion uint16
+	volts      [4]float64
+}
+
+// NewADS1015 returns a 12 bit ADS1015 as it is after power on.
+func NewADS1015() *ADS1x15 {
+	return &ADS1x15{bits: 12, config: ads1x15DefaultConfig}
+}
+
+// NewADS1115 returns a 16 bit ADS1115 as it is after power on.
+func NewADS1115() *ADS1x15 {
+	return &ADS1x15{bits: 16, config: ads1x15DefaultConfig}
+}
+
+// SetVoltage sets the voltage on one of the four inputs.
+func (s *ADS1x15) SetVoltage(input int, volts float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volts[input] = volts
+}
+
+// Config returns the config register.
+func (s *ADS1x15) Config() uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+// Write sets the register pointer and writes the register if a value follows.
+func (s *ADS1x15) Write(tx []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(tx) == 0 {
+		return nil
+	}
+	s.pointer = tx[0] & 0x03
+	if len(tx) < 3 || s.pointer != ads1x15ConfigReg {
+		return nil
+	}
+	s.config = binary.BigEndian.Uint16(tx[1:])
+	if s.config&ads1x15StartBit != 0 {
+		s.convert()
+	}
+	// the start bit reads back as set once the conversion is done
+	s.config |= ads1x15StartBit
+	return nil
+}
+
+func (s *ADS1x15) convert() {
+	inputs := ads1x15Inputs[s.config>>12&0x07]
+	volts := s.volts[inputs[0]]
+	if inputs[1] >= 0 {
+		volts -= s.volts[inputs[1]]
+	}
+	fullScale := ads1x15FullScale[s.config>>9&0x07]
+	maxCount := float64(int(1) << (s.bits - 1))
+	count := math.Max(-maxCount, math.Min(maxCount-1, math.Round(volts/fullScale*maxCount)))
+	s.conversion = uint16(int16(count) << (16 - s.bits))
+}
+
+// Read reads the register at the pointer.
+func (s *ADS1x15) Read(count int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value := s.conversion
+	if s.pointer == ads1x15ConfigReg {
+		value = s.config
+	}
+	out := make([]byte, count)
+	var reg [2]byte
+	binary.BigEndian.PutUint16(reg[:], value)
+	copy(out, reg[:])
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package simdevice
+
+import "encoding/binary"
+
+// BME280 registers.
+const (
+	bme280ChipIDReg       = 0xD0
+	bme280ResetReg        = 0xE0
+	bme280CtrlHumReg      = 0xF2
+	bme280CtrlMeasReg     = 0xF4
+	bme280ConfigReg       = 0xF5
+	bme280PressureReg     = 0xF7
+	bme280ResetCommand    = 0xB6
+	bme280ChipID          = 0x60
+	bme280CalibrationTReg = 0x88
+	bme280CalibrationH1   = 0xA1
+	bme280CalibrationH2   = 0xE1
+)
+
+// BME280Calibration holds the trimming parameters stored on a BME280.
+type BME280Calibration struct {
+	T1                             uint16
+	T2, T3                         int16
+	P1                             uint16
+	P2, P3, P4, P5, P6, P7, P8, P9 int16
+	H1                             uint8
+	H2                             int16
+	H3                             uint8
+	H4, H5                         int16 // 12 bits each
+	H6                             int8
+}
+
+// DefaultBME280Calibration is the calibration used in the compensation examples of the
+// Bosch datasheets, with typical humidity parameters.
+var DefaultBME280Calibration = BME280Calibration{
+	T1: 27504, T2: 26435, T3: -1000,
+	P1: 36477, P2: -10685, P3: 3024, P4: 2855, P5: 140, P6: -7, P7: 15500, P8: -14600, P9: 6000,
+	H1: 75, H2: 362, H3: 0, H4: 313, H5: 50, H6: 30,
+}
+
+// BME280 simulates a Bosch BME280 temperature, pressure and humidity sensor. Measurements are
+// set as raw ADC values, which the driver compensates with the calibration.
+type BME280 struct {
+	Registers
+}
+
+// NewBME280 returns a BME280 with the given calibration, as it is after power on.
+func NewBME280(calibration BME280Calibration) *BME280 {
+	s := &BME280{}
+	s.Set(bme280ChipIDReg, bme280ChipID)
+
+	c := calibration
+	words := []uint16{
+		c.T1, uint16(c.T2), uint16(c.T3),
+		c.P1, uint16(c.P2), uint16(c.P3), uint16(c.P4), uint16(c.P5), uint16(c.P6), uint16(c.P7), uint16(c.P8), uint16(c.P9),
+	}
+	calib := make([]byte, len(words)*2)
+	for i, w := range words {
+		binary.LittleEndian.PutUint16(calib[i*2:], w)
+	}
+	s.Set(bme280CalibrationTReg, calib...)
+	s.Set(bme280CalibrationH1, c.H1)
+	h2 := make([]byte, 2)
+	binary.LittleEndian.PutUint16(h2, uint16(c.H2))
+	s.Set(bme280CalibrationH2, h2[0], h2[1], c.H3,
+		byte(c.H4>>4), byte(c.H4&0x0F)|byte(c.H5&0x0F)<<4, byte(c.H5>>4), byte(c.H6))
+	return s
+}
+
+// Write handles the soft reset command as well as register writes.
+func (s *BME280) Write(tx []byte) error {
+	if len(tx) == 2 && tx[0] == bme280ResetReg {
+		if tx[1] == bme280ResetCommand {
+			s.Set(bme280CtrlHumReg, 0, 0, 0, 0)
+		}
+		return s.Registers.Write(tx[:1])
+	}
+	return s.Registers.Write(tx)
+}
+
+// SetRawMeasurements sets the 20 bit pressure and temperature and 16 bit humidity readings.
+func (s *BME280) SetRawMeasurements(pressure, temperature, humidity int) {
+	s.Set(bme280PressureReg,
+		byte(pressure>>12), byte(pressure>>4), byte(pressure<<4),
+		byte(temperature>>12), byte(temperature>>4), byte(temperature<<4),
+		byte(humidity>>8), byte(humidity))
+}
+
+// Mode returns the power mode, which is 3 when measuring continuously.
+func (s *BME280) Mode() byte {
+	return s.Get(bme280CtrlMeasReg, 1)[0] & 0b11
+}
+
+// Oversampling returns the humidity, pressure and temperature oversampling settings.
+func (s *BME280) Oversampling() (humidity, pressure, temperature byte) {
+	ctrlHum := s.Get(bme280CtrlHumReg, 1)[0]
+	ctrlMeas := s.Get(bme280CtrlMeasReg, 1)[0]
+	return ctrlHum & 0b111, (ctrlMeas >> 2) & 0b111, ctrlMeas >> 5
+}
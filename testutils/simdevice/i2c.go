@@ -0,0 +1,239 @@
+// Package simdevice simulates chips on I2C and SPI buses at the register level, so that drivers
+// can be tested end to end without hardware. Buses log every transaction they carry, and
+// simulators for the chips we have drivers for are included.
+package simdevice
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// An I2CDevice is a simulated chip on an I2CBus. Each call is one transaction addressed to it.
+type I2CDevice interface {
+	Write(tx []byte) error
+	Read(count int) ([]byte, error)
+}
+
+// I2CTransaction is a single transaction carried by an I2CBus.
+type I2CTransaction struct {
+	Addr  byte
+	Write bool
+	// Data is what was written, or what was read.
+	Data []byte
+}
+
+// I2CBus is a board.I2C that passes transactions on to the simulated devices attached to it.
+// Like the Linux buses, an open handle locks the bus until it is closed.
+type I2CBus struct {
+	handleMu     sync.Mutex
+	mu           sync.Mutex
+	devices      map[byte]I2CDevice
+	transactions []I2CTransaction
+	openHandles  int
+}
+
+var _ = board.I2C(&I2CBus{})
+
+// NewI2CBus returns an I2C bus with no devices attached.
+func NewI2CBus() *I2CBus {
+	return &I2CBus{devices: map[byte]I2CDevice{}}
+}
+
+// Attach puts a device on the bus at the given address, replacing any device already there.
+func (b *I2CBus) Attach(addr byte, d I2CDevice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.devices[addr] = d
+}
+
+// Detach removes the device at the given address, as if it were unplugged.
+func (b *I2CBus) Detach(addr byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.devices, addr)
+}
+
+// Transactions returns every transaction carried so far, in order.
+func (b *I2CBus) Transactions() []I2CTransaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]I2CTransaction{}, b.transactions...)
+}
+
+// ClearTransactions forgets the transactions carried so far.
+func (b *I2CBus) ClearTransactions() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.transactions = nil
+}
+
+// OpenHandles returns how many handles have been opened and not closed yet.
+func (b *I2CBus) OpenHandles() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openHandles
+}
+
+// OpenHandle locks the bus and opens a handle to the given address. Like on Linux, nothing
+// needs to be at the address until a transaction is made.
+func (b *I2CBus) OpenHandle(addr byte) (board.I2CHandle, error) {
+	b.handleMu.Lock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openHandles++
+	return &i2cHandle{bus: b, addr: addr}, nil
+}
+
+func (b *I2CBus) write(addr byte, tx []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	d, ok := b.devices[addr]
+	if !ok {
+		return errors.Errorf("no I2C device at address %#x", addr)
+	}
+	b.transactions = append(b.transactions, I2CTransaction{Addr: addr, Write: true, Data: append([]byte{}, tx...)})
+	return d.Write(tx)
+}
+
+func (b *I2CBus) read(addr byte, count int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	d, ok := b.devices[addr]
+	if !ok {
+		return nil, errors.Errorf("no I2C device at address %#x", addr)
+	}
+	rx, err := d.Read(count)
+	if err != nil {
+		return nil, err
+	}
+	b.transactions = append(b.transactions, I2CTransaction{Addr: addr, Data: append([]byte{}, rx...)})
+	return rx, nil
+}
+
+type i2cHandle struct {
+	bus      *I2CBus
+	addr     byte
+	mu       sync.Mutex
+	isClosed bool
+}
+
+func (h *i2cHandle) checkOpen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isClosed {
+		return errors.New("I2C handle is closed")
+	}
+	return nil
+}
+
+func (h *i2cHandle) Write(ctx context.Context, tx []byte) error {
+	if err := h.checkOpen(); err != nil {
+		return err
+	}
+	return h.bus.write(h.addr, tx)
+}
+
+func (h *i2cHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	if err := h.checkOpen(); err != nil {
+		return nil, err
+	}
+	return h.bus.read(h.addr, count)
+}
+
+func (h *i2cHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	data, err := h.ReadBlockData(ctx, register, 1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+func (h *i2cHandle) WriteByteData(ctx context.Context, register, data byte) error {
+	return h.Write(ctx, []byte{register, data})
+}
+
+// ReadBlockData writes the register and reads from it, as the Linux I2C handles do.
+func (h *i2cHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+	if err := h.Write(ctx, []byte{register}); err != nil {
+		return nil, err
+	}
+	return h.Read(ctx, int(numBytes))
+}
+
+func (h *i2cHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
+	return h.Write(ctx, append([]byte{register}, data...))
+}
+
+func (h *i2cHandle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isClosed {
+		return nil
+	}
+	h.isClosed = true
+	h.bus.mu.Lock()
+	h.bus.openHandles--
+	h.bus.mu.Unlock()
+	h.bus.handleMu.Unlock()
+	return nil
+}
+
+// Registers is an I2CDevice made of 256 byte-wide registers, the way most sensor chips work:
+// a write sets the register pointer with its first byte and writes the rest from there, and
+// reads carry on from the pointer. Simulators of particular chips build on it.
+type Registers struct {
+	mu      sync.Mutex
+	values  [256]byte
+	pointer byte
+}
+
+// Write sets the register pointer and writes any following bytes from there.
+func (r *Registers) Write(tx []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(tx) == 0 {
+		return nil
+	}
+	r.pointer = tx[0]
+	for _, v := range tx[1:] {
+		r.values[r.pointer] = v
+		r.pointer++
+	}
+	return nil
+}
+
+// Read reads from the register pointer onwards.
+func (r *Registers) Read(count int) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = r.values[r.pointer]
+		r.pointer++
+	}
+	return out, nil
+}
+
+// Set sets consecutive registers starting at the given one.
+func (r *Registers) Set(register byte, data ...byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range data {
+		r.values[register+byte(i)] = v
+	}
+}
+
+// Get returns consecutive registers starting at the given one.
+func (r *Registers) Get(register byte, count int) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = r.values[register+byte(i)]
+	}
+	return out
+}
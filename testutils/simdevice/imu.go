@@ -0,0 +1,113 @@
+package simdevice
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// standard gravity in mm/s^2, the unit of linear acceleration.
+const gravity = 9.81 * 1000
+
+// scale converts a value in a full scale range of +/- maxValue to a signed count of the given
+// number of bits.
+func scale(value, maxValue float64, bits uint) int16 {
+	counts := math.Round(value / maxValue * float64(int(1)<<(bits-1)))
+	limit := float64(int(1)<<(bits-1)) - 1
+	return int16(math.Max(-limit-1, math.Min(limit, counts)))
+}
+
+// MPU6050 registers.
+const (
+	mpu6050AccelReg    = 0x3B
+	mpu6050PowerReg    = 0x6B
+	mpu6050WhoAmIReg   = 0x75
+	mpu6050WhoAmI      = 0x68
+	mpu6050SleepBit    = 1 << 6
+	mpu6050MaxAccel    = 2 * gravity // default full scale range
+	mpu6050MaxRotation = 250         // degrees per second, by default
+)
+
+// MPU6050 simulates an InvenSense MPU-6050 accelerometer and gyroscope in its default full
+// scale ranges.
+type MPU6050 struct {
+	Registers
+}
+
+// NewMPU6050 returns an MPU6050 as it is after power on, asleep.
+func NewMPU6050() *MPU6050 {
+	s := &MPU6050{}
+	s.Set(mpu6050WhoAmIReg, mpu6050WhoAmI)
+	s.Set(mpu6050PowerReg, mpu6050SleepBit)
+	return s
+}
+
+func (s *MPU6050) setVector(register byte, v r3.Vector, maxValue float64) {
+	data := make([]byte, 6)
+	for i, value := range []float64{v.X, v.Y, v.Z} {
+		binary.BigEndian.PutUint16(data[i*2:], uint16(scale(value, maxValue, 16)))
+	}
+	s.Set(register, data...)
+}
+
+// SetLinearAcceleration sets the measured acceleration in mm/s^2.
+func (s *MPU6050) SetLinearAcceleration(accel r3.Vector) {
+	s.setVector(mpu6050AccelReg, accel, mpu6050MaxAccel)
+}
+
+// SetAngularVelocity sets the measured rotation in degrees per second.
+func (s *MPU6050) SetAngularVelocity(vel spatialmath.AngularVelocity) {
+	s.setVector(mpu6050AccelReg+8, r3.Vector(vel), mpu6050MaxRotation)
+}
+
+// SetTemperature sets the measured temperature in degrees celsius.
+func (s *MPU6050) SetTemperature(celsius float64) {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(int16(math.Round((celsius-36.53)*340))))
+	s.Set(mpu6050AccelReg+6, data...)
+}
+
+// Sleeping returns whether the chip is in sleep mode.
+func (s *MPU6050) Sleeping() bool {
+	return s.Get(mpu6050PowerReg, 1)[0]&mpu6050SleepBit != 0
+}
+
+// ADXL345 registers.
+const (
+	adxl345DeviceIDReg = 0x00
+	adxl345DeviceID    = 0xE5
+	adxl345PowerReg    = 0x2D
+	adxl345DataReg     = 0x32
+	adxl345MeasureBit  = 1 << 3
+	adxl345MaxAccel    = 2 * gravity // default range
+)
+
+// ADXL345 simulates an Analog Devices ADXL345 accelerometer in its default range of +/- 2g,
+// where it has ten bits of resolution.
+type ADXL345 struct {
+	Registers
+}
+
+// NewADXL345 returns an ADXL345 as it is after power on, in standby.
+func NewADXL345() *ADXL345 {
+	s := &ADXL345{}
+	s.Set(adxl345DeviceIDReg, adxl345DeviceID)
+	return s
+}
+
+// SetLinearAcceleration sets the measured acceleration in mm/s^2.
+func (s *ADXL345) SetLinearAcceleration(accel r3.Vector) {
+	data := make([]byte, 6)
+	for i, value := range []float64{accel.X, accel.Y, accel.Z} {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(scale(value, adxl345MaxAccel, 10)))
+	}
+	s.Set(adxl345DataReg, data...)
+}
+
+// Measuring returns whether the chip is in measurement mode rather than standby.
+func (s *ADXL345) Measuring() bool {
+	return s.Get(adxl345PowerReg, 1)[0]&adxl345MeasureBit != 0
+}
@@ -0,0 +1,55 @@
+package simdevice
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MCP3008 simulates a Microchip MCP3008 eight channel 10 bit ADC, read with the three byte
+// transfers from its datasheet.
+type MCP3008 struct {
+	mu       sync.Mutex
+	channels [8]int
+}
+
+// NewMCP3008 returns an MCP3008 with every channel reading zero.
+func NewMCP3008() *MCP3008 {
+	return &MCP3008{}
+}
+
+// SetChannel sets the 10 bit reading of a channel.
+func (s *MCP3008) SetChannel(channel, value int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel] = value & 0x3FF
+}
+
+// Xfer answers a conversion request. The first byte holds the start bit, and the top nibble
+// of the second whether the conversion is single ended and the channel.
+func (s *MCP3008) Xfer(mode uint, tx []byte) ([]byte, error) {
+	if mode != 0 && mode != 3 {
+		return nil, errors.Errorf("MCP3008 does not support SPI mode %d", mode)
+	}
+	rx := make([]byte, len(tx))
+	if len(tx) < 3 || tx[0]&0x01 == 0 {
+		return rx, nil
+	}
+	singleEnded := tx[1]&0x80 != 0
+	channel := int(tx[1]>>4) & 0x07
+
+	s.mu.Lock()
+	value := s.channels[channel]
+	if !singleEnded {
+		// channels are paired up, with the odd bit picking which is the positive input
+		value = s.channels[channel] - s.channels[channel^1]
+		if value < 0 {
+			value = 0
+		}
+	}
+	s.mu.Unlock()
+
+	rx[1] = byte(value >> 8)
+	rx[2] = byte(value)
+	return rx, nil
+}
@@ -0,0 +1,66 @@
+package simdevice
+
+// PCA9685 registers.
+const (
+	pca9685Mode1Reg     = 0x00
+	pca9685LEDReg       = 0x06
+	pca9685PrescaleReg  = 0xFE
+	pca9685SleepBit     = 1 << 4
+	pca9685FullBit      = 1 << 4
+	pca9685Oscillator   = 25000000
+	pca9685Steps        = 4096
+	pca9685DefaultMode1 = 0x11
+	pca9685DefaultScale = 0x1E
+)
+
+// PCA9685 simulates an NXP PCA9685 16 channel PWM controller running off its internal
+// oscillator. As on the chip, the prescaler can only be written while it sleeps. Registers
+// always auto increment.
+type PCA9685 struct {
+	Registers
+}
+
+// NewPCA9685 returns a PCA9685 as it is after power on: asleep, at 200Hz, with all outputs off.
+func NewPCA9685() *PCA9685 {
+	s := &PCA9685{}
+	s.Set(pca9685Mode1Reg, pca9685DefaultMode1)
+	s.Set(pca9685PrescaleReg, pca9685DefaultScale)
+	for ch := 0; ch < 16; ch++ {
+		s.Set(pca9685LEDReg+byte(ch*4)+3, pca9685FullBit)
+	}
+	return s
+}
+
+// Write ignores writes to the prescaler while the chip is awake.
+func (s *PCA9685) Write(tx []byte) error {
+	if len(tx) > 1 && tx[0] == pca9685PrescaleReg && !s.Sleeping() {
+		return s.Registers.Write(tx[:1])
+	}
+	return s.Registers.Write(tx)
+}
+
+// Sleeping returns whether the oscillator is off.
+func (s *PCA9685) Sleeping() bool {
+	return s.Get(pca9685Mode1Reg, 1)[0]&pca9685SleepBit != 0
+}
+
+// Frequency returns the PWM frequency in Hz.
+func (s *PCA9685) Frequency() float64 {
+	prescale := s.Get(pca9685PrescaleReg, 1)[0]
+	return pca9685Oscillator / (pca9685Steps * (float64(prescale) + 1))
+}
+
+// DutyCycle returns the fraction of the time the given channel is on.
+func (s *PCA9685) DutyCycle(channel int) float64 {
+	regs := s.Get(pca9685LEDReg+byte(channel*4), 4)
+	switch {
+	case regs[3]&pca9685FullBit != 0:
+		// full off takes precedence over full on
+		return 0
+	case regs[1]&pca9685FullBit != 0:
+		return 1
+	}
+	on := int(regs[0]) | int(regs[1]&0x0F)<<8
+	off := int(regs[2]) | int(regs[3]&0x0F)<<8
+	return float64((off-on+pca9685Steps)%pca9685Steps) / pca9685Steps
+}
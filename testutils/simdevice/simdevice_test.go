@@ -0,0 +1,134 @@
+package simdevice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+)
+
+func TestI2CBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewI2CBus()
+	chip := &Registers{}
+	chip.Set(0x10, 1, 2, 3)
+	bus.Attach(0x40, chip)
+
+	handle, err := bus.OpenHandle(0x40)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 1)
+
+	data, err := handle.ReadBlockData(ctx, 0x10, 3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte{1, 2, 3})
+	test.That(t, handle.WriteBlockData(ctx, 0x11, []byte{5, 6}), test.ShouldBeNil)
+	test.That(t, chip.Get(0x10, 3), test.ShouldResemble, []byte{1, 5, 6})
+	b, err := handle.ReadByteData(ctx, 0x12)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b, test.ShouldEqual, 6)
+
+	test.That(t, bus.Transactions(), test.ShouldResemble, []I2CTransaction{
+		{Addr: 0x40, Write: true, Data: []byte{0x10}},
+		{Addr: 0x40, Data: []byte{1, 2, 3}},
+		{Addr: 0x40, Write: true, Data: []byte{0x11, 5, 6}},
+		{Addr: 0x40, Write: true, Data: []byte{0x12}},
+		{Addr: 0x40, Data: []byte{6}},
+	})
+	bus.ClearTransactions()
+	test.That(t, bus.Transactions(), test.ShouldBeEmpty)
+
+	// the bus is locked until the handle is closed
+	opened := make(chan board.I2CHandle)
+	go func() {
+		other, _ := bus.OpenHandle(0x40)
+		opened <- other
+	}()
+	select {
+	case <-opened:
+		t.Fatal("opened a second handle while the bus was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+	test.That(t, handle.Close(), test.ShouldBeNil)
+	test.That(t, handle.Close(), test.ShouldBeNil)
+	test.That(t, (<-opened).Close(), test.ShouldBeNil)
+	test.That(t, bus.OpenHandles(), test.ShouldEqual, 0)
+	_, err = handle.Read(ctx, 1)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	handle, err = bus.OpenHandle(0x41)
+	test.That(t, err, test.ShouldBeNil)
+	defer handle.Close()
+	err = handle.Write(ctx, []byte{0})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no I2C device at address 0x41")
+}
+
+func TestScriptedSPI(t *testing.T) {
+	ctx := context.Background()
+	bus := NewSPIBus()
+	script := NewScriptedSPI(
+		SPIStep{Tx: []byte{0x8F, 0}, Rx: []byte{0, 0x33}},
+		SPIStep{Rx: []byte{0xFF}},
+	)
+	bus.Attach("cs", script)
+	handle, err := bus.OpenHandle()
+	test.That(t, err, test.ShouldBeNil)
+	defer handle.Close()
+
+	rx, err := handle.Xfer(ctx, 1000, "cs", 3, []byte{0x8F, 0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rx, test.ShouldResemble, []byte{0, 0x33})
+	rx, err = handle.Xfer(ctx, 1000, "cs", 3, []byte{1, 2, 3})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rx, test.ShouldResemble, []byte{0xFF, 0, 0})
+	test.That(t, script.Remaining(), test.ShouldEqual, 0)
+
+	_, err = handle.Xfer(ctx, 1000, "cs", 3, []byte{1})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "end of the script")
+
+	script.Add(SPIStep{Tx: []byte{0x0F}})
+	_, err = handle.Xfer(ctx, 1000, "cs", 3, []byte{0x8F})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "expected SPI transfer 0f, got 8f")
+	test.That(t, bus.Transfers(), test.ShouldHaveLength, 2)
+}
+
+func TestPCA9685Prescale(t *testing.T) {
+	chip := NewPCA9685()
+	test.That(t, chip.Frequency(), test.ShouldAlmostEqual, 200, 5)
+	test.That(t, chip.DutyCycle(0), test.ShouldEqual, 0)
+
+	// the prescaler can't change while the oscillator runs
+	test.That(t, chip.Write([]byte{pca9685Mode1Reg, 0x01}), test.ShouldBeNil)
+	test.That(t, chip.Write([]byte{pca9685PrescaleReg, 0x79}), test.ShouldBeNil)
+	test.That(t, chip.Get(pca9685PrescaleReg, 1), test.ShouldResemble, []byte{pca9685DefaultScale})
+
+	test.That(t, chip.Write([]byte{pca9685Mode1Reg, 0x11}), test.ShouldBeNil)
+	test.That(t, chip.Write([]byte{pca9685PrescaleReg, 0x79}), test.ShouldBeNil)
+	test.That(t, chip.Frequency(), test.ShouldAlmostEqual, 50, 0.1)
+
+	// on at step 1024, off at step 3072
+	test.That(t, chip.Write([]byte{pca9685LEDReg, 0x00, 0x04, 0x00, 0x0C}), test.ShouldBeNil)
+	test.That(t, chip.DutyCycle(0), test.ShouldEqual, 0.5)
+}
+
+func TestMCP3008Differential(t *testing.T) {
+	adc := NewMCP3008()
+	adc.SetChannel(2, 600)
+	adc.SetChannel(3, 200)
+
+	rx, err := adc.Xfer(0, []byte{0x01, 0x20, 0x00})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, int(rx[1])<<8|int(rx[2]), test.ShouldEqual, 400)
+
+	rx, err = adc.Xfer(0, []byte{0x01, 0x30, 0x00})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, int(rx[1])<<8|int(rx[2]), test.ShouldEqual, 0)
+
+	_, err = adc.Xfer(1, []byte{0x01, 0x30, 0x00})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,178 @@
+package simdevice
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// An SPIDevice is a simulated chip on an SPIBus. Each call is one transfer with its chip
+// selected, and must return as many bytes as it was given.
+type SPIDevice interface {
+	Xfer(mode uint, tx []byte) ([]byte, error)
+}
+
+// SPITransfer is a single transfer carried by an SPIBus.
+type SPITransfer struct {
+	ChipSelect string
+	Baud       uint
+	Mode       uint
+	Tx         []byte
+	Rx         []byte
+}
+
+// SPIBus is a board.SPI that passes transfers on to the simulated devices attached to it.
+type SPIBus struct {
+	mu          sync.Mutex
+	devices     map[string]SPIDevice
+	transfers   []SPITransfer
+	openHandles int
+}
+
+var _ = board.SPI(&SPIBus{})
+
+// NewSPIBus returns an SPI bus with no devices attached.
+func NewSPIBus() *SPIBus {
+	return &SPIBus{devices: map[string]SPIDevice{}}
+}
+
+// Attach puts a device on the bus behind the given chip select.
+func (b *SPIBus) Attach(chipSelect string, d SPIDevice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.devices[chipSelect] = d
+}
+
+// Transfers returns every transfer carried so far, in order.
+func (b *SPIBus) Transfers() []SPITransfer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]SPITransfer{}, b.transfers...)
+}
+
+// ClearTransfers forgets the transfers carried so far.
+func (b *SPIBus) ClearTransfers() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.transfers = nil
+}
+
+// OpenHandles returns how many handles have been opened and not closed yet.
+func (b *SPIBus) OpenHandles() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openHandles
+}
+
+// OpenHandle opens a handle to the bus.
+func (b *SPIBus) OpenHandle() (board.SPIHandle, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openHandles++
+	return &spiHandle{bus: b}, nil
+}
+
+type spiHandle struct {
+	bus      *SPIBus
+	mu       sync.Mutex
+	isClosed bool
+}
+
+func (h *spiHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+	h.mu.Lock()
+	closed := h.isClosed
+	h.mu.Unlock()
+	if closed {
+		return nil, errors.New("SPI handle is closed")
+	}
+
+	h.bus.mu.Lock()
+	defer h.bus.mu.Unlock()
+	d, ok := h.bus.devices[chipSelect]
+	if !ok {
+		return nil, errors.Errorf("no SPI device on chip select %s", chipSelect)
+	}
+	rx, err := d.Xfer(mode, append([]byte{}, tx...))
+	if err != nil {
+		return nil, err
+	}
+	if len(rx) != len(tx) {
+		return nil, errors.Errorf("SPI device on chip select %s returned %d bytes for a %d byte transfer", chipSelect, len(rx), len(tx))
+	}
+	h.bus.transfers = append(h.bus.transfers, SPITransfer{
+		ChipSelect: chipSelect,
+		Baud:       baud,
+		Mode:       mode,
+		Tx:         append([]byte{}, tx...),
+		Rx:         append([]byte{}, rx...),
+	})
+	return rx, nil
+}
+
+func (h *spiHandle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isClosed {
+		return nil
+	}
+	h.isClosed = true
+	h.bus.mu.Lock()
+	h.bus.openHandles--
+	h.bus.mu.Unlock()
+	return nil
+}
+
+// SPIStep is one expected transfer of a ScriptedSPI and the response to it.
+type SPIStep struct {
+	// Tx is the expected transfer. A nil Tx matches any transfer.
+	Tx []byte
+	// Rx is the response, which is padded with zeros to the length of the transfer.
+	Rx []byte
+}
+
+// ScriptedSPI is an SPIDevice that answers transfers from a script, for chips too simple or
+// too complicated to be worth simulating.
+type ScriptedSPI struct {
+	mu    sync.Mutex
+	steps []SPIStep
+}
+
+// NewScriptedSPI returns a device that expects the given steps in order.
+func NewScriptedSPI(steps ...SPIStep) *ScriptedSPI {
+	return &ScriptedSPI{steps: steps}
+}
+
+// Add appends steps to the script.
+func (s *ScriptedSPI) Add(steps ...SPIStep) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, steps...)
+}
+
+// Remaining returns how many steps have not been carried out yet.
+func (s *ScriptedSPI) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.steps)
+}
+
+// Xfer answers a transfer with the next step of the script.
+func (s *ScriptedSPI) Xfer(mode uint, tx []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.steps) == 0 {
+		return nil, errors.Errorf("unexpected SPI transfer % x after the end of the script", tx)
+	}
+	step := s.steps[0]
+	if step.Tx != nil && !bytes.Equal(step.Tx, tx) {
+		return nil, errors.Errorf("expected SPI transfer % x, got % x", step.Tx, tx)
+	}
+	s.steps = s.steps[1:]
+	rx := make([]byte, len(tx))
+	copy(rx, step.Rx)
+	return rx, nil
+}
@@ -0,0 +1,422 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ringRadius is the radius in pixels of the ring sampled around each pixel to score it as a
+	// checkerboard corner, so squares must be larger than this in the image.
+	ringRadius = 5
+	// cornerSuppressRadius is how far apart two corner candidates must be.
+	cornerSuppressRadius = 3
+	// minCornerResponse is the fraction of the strongest corner response a candidate needs.
+	minCornerResponse = 0.1
+	// subpixelRadius is the half size of the window corners are refined in.
+	subpixelRadius = 4
+	// subpixelIterations is how many iterations refining a corner may take.
+	subpixelIterations = 10
+	// gridTolerance is how far a corner may be from where the grid predicts it, as a fraction of
+	// the distance between corners.
+	gridTolerance = 0.3
+	// maxGridSeeds is how many of the strongest corners are tried as the start of the grid.
+	maxGridSeeds = 20
+)
+
+// ringOffsets are 16 points evenly spaced on a circle of ringRadius, where each point's opposite
+// is 8 ahead of it and each point's perpendicular is 4 ahead of it.
+var ringOffsets = [16]image.Point{
+	image.Pt(5, 0), image.Pt(5, 2), image.Pt(4, 4), image.Pt(2, 5),
+	image.Pt(0, 5), image.Pt(-2, 5), image.Pt(-4, 4), image.Pt(-5, 2),
+	image.Pt(-5, 0), image.Pt(-5, -2), image.Pt(-4, -4), image.Pt(-2, -5),
+	image.Pt(0, -5), image.Pt(2, -5), image.Pt(4, -4), image.Pt(5, -2),
+}
+
+// grayImage is an image's luminance as floats.
+type grayImage struct {
+	width, height int
+	pix           []float64
+}
+
+func (g *grayImage) at(x, y int) float64 {
+	return g.pix[y*g.width+x]
+}
+
+// FindCheckerboardCorners finds the inner corners of a checkerboard with rows by cols inner corners
+// in the image, to subpixel accuracy. The board needs a light border around it and its squares
+// must be at least about a dozen pixels wide. The corners are returned in row-major order, so they
+// match transform.CheckerboardPoints, starting from the corner nearest the top left of the image.
+func FindCheckerboardCorners(img image.Image, rows, cols int) ([]r2.Point, error) {
+	if rows < 2 || cols < 2 {
+		return nil, errors.Errorf("a checkerboard needs at least 2x2 inner corners, got %dx%d", rows, cols)
+	}
+	gray := smooth(toGray(img))
+	candidates := findCornerCandidates(gray)
+	if len(candidates) < rows*cols {
+		return nil, errors.Errorf("could not find a %dx%d checkerboard, only found %d corners", rows, cols, len(candidates))
+	}
+	points := make([]r2.Point, len(candidates))
+	for i, c := range candidates {
+		points[i] = refineCorner(gray, c.point)
+	}
+
+	var lastErr error
+	for seed := 0; seed < len(candidates) && seed < maxGridSeeds; seed++ {
+		grid, err := growGrid(points, seed, rows, cols)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return orderGrid(grid, rows, cols), nil
+	}
+	return nil, errors.Wrapf(lastErr, "could not find a %dx%d checkerboard", rows, cols)
+}
+
+func toGray(img image.Image) *grayImage {
+	bounds := img.Bounds()
+	g := &grayImage{width: bounds.Dx(), height: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			c, _ := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			g.pix[y*g.width+x] = float64(c.Y)
+		}
+	}
+	return g
+}
+
+// smooth blurs the image with a 3x3 binomial kernel, leaving the outermost pixels as they are.
+func smooth(g *grayImage) *grayImage {
+	horizontal := append([]float64(nil), g.pix...)
+	for y := 0; y < g.height; y++ {
+		for x := 1; x < g.width-1; x++ {
+			horizontal[y*g.width+x] = (g.at(x-1, y) + 2*g.at(x, y) + g.at(x+1, y)) / 4
+		}
+	}
+	out := &grayImage{width: g.width, height: g.height, pix: append([]float64(nil), horizontal...)}
+	for y := 1; y < g.height-1; y++ {
+		for x := 0; x < g.width; x++ {
+			i := y*g.width + x
+			out.pix[i] = (horizontal[i-g.width] + 2*horizontal[i] + horizontal[i+g.width]) / 4
+		}
+	}
+	return out
+}
+
+// cornerResponse scores how much the pixel looks like the meeting point of four squares of a
+// checkerboard with the ChESS detector (Bennett and Lasenby, "ChESS - Quick and Robust Detection
+// of Chess-board Features", 2013). Opposite points on a ring around a corner match and
+// perpendicular points differ, while across an edge opposite points differ.
+func cornerResponse(g *grayImage, x, y int) float64 {
+	var ring [16]float64
+	var ringSum float64
+	for i, o := range ringOffsets {
+		ring[i] = g.at(x+o.X, y+o.Y)
+		ringSum += ring[i]
+	}
+	var sumResponse, diffResponse float64
+	for n := 0; n < 4; n++ {
+		sumResponse += math.Abs(ring[n] + ring[n+8] - ring[n+4] - ring[n+12])
+	}
+	for n := 0; n < 8; n++ {
+		diffResponse += math.Abs(ring[n] - ring[n+8])
+	}
+	localMean := (g.at(x, y) + g.at(x-1, y) + g.at(x+1, y) + g.at(x, y-1) + g.at(x, y+1)) / 5
+	meanResponse := math.Abs(ringSum/16 - localMean)
+	return sumResponse - diffResponse - 16*meanResponse
+}
+
+type cornerCandidate struct {
+	point    r2.Point
+	response float64
+}
+
+// findCornerCandidates returns the local maxima of the corner response that are strong enough,
+// strongest first.
+func findCornerCandidates(g *grayImage) []cornerCandidate {
+	response := make([]float64, len(g.pix))
+	maxResponse := 0.
+	for y := ringRadius; y < g.height-ringRadius; y++ {
+		for x := ringRadius; x < g.width-ringRadius; x++ {
+			r := cornerResponse(g, x, y)
+			response[y*g.width+x] = r
+			maxResponse = math.Max(maxResponse, r)
+		}
+	}
+	if maxResponse == 0 {
+		return nil
+	}
+	threshold := minCornerResponse * maxResponse
+	var candidates []cornerCandidate
+	for y := ringRadius; y < g.height-ringRadius; y++ {
+		for x := ringRadius; x < g.width-ringRadius; x++ {
+			r := response[y*g.width+x]
+			if r < threshold || !isLocalMax(response, g.width, g.height, x, y) {
+				continue
+			}
+			candidates = append(candidates, cornerCandidate{r2.Point{X: float64(x), Y: float64(y)}, r})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].response > candidates[j].response
+	})
+	return candidates
+}
+
+// isLocalMax returns whether the response at the pixel is the largest within cornerSuppressRadius,
+// with ties going to the first pixel in raster order.
+func isLocalMax(response []float64, width, height, x, y int) bool {
+	r := response[y*width+x]
+	for dy := -cornerSuppressRadius; dy <= cornerSuppressRadius; dy++ {
+		for dx := -cornerSuppressRadius; dx <= cornerSuppressRadius; dx++ {
+			nx, ny := x+dx, y+dy
+			if (dx == 0 && dy == 0) || nx < 0 || ny < 0 || nx >= width || ny >= height {
+				continue
+			}
+			other := response[ny*width+nx]
+			if other > r || (other == r && (dy < 0 || (dy == 0 && dx < 0))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// refineCorner moves the corner to the point that every image gradient around it points away
+// from, since the gradients along the edges of the squares are perpendicular to the lines from
+// the corner. It returns the corner unchanged if it can't be refined.
+func refineCorner(g *grayImage, corner r2.Point) r2.Point {
+	const sigma = subpixelRadius / 2.
+	p := corner
+	for iter := 0; iter < subpixelIterations; iter++ {
+		cx, cy := int(math.Round(p.X)), int(math.Round(p.Y))
+		if cx-subpixelRadius < 1 || cy-subpixelRadius < 1 ||
+			cx+subpixelRadius >= g.width-1 || cy+subpixelRadius >= g.height-1 {
+			return corner
+		}
+		var a11, a12, a22, b1, b2 float64
+		for y := cy - subpixelRadius; y <= cy+subpixelRadius; y++ {
+			for x := cx - subpixelRadius; x <= cx+subpixelRadius; x++ {
+				dx, dy := float64(x)-p.X, float64(y)-p.Y
+				w := math.Exp(-(dx*dx + dy*dy) / (2 * sigma * sigma))
+				gx := (g.at(x+1, y) - g.at(x-1, y)) / 2
+				gy := (g.at(x, y+1) - g.at(x, y-1)) / 2
+				gxx, gxy, gyy := w*gx*gx, w*gx*gy, w*gy*gy
+				a11 += gxx
+				a12 += gxy
+				a22 += gyy
+				b1 += gxx*float64(x) + gxy*float64(y)
+				b2 += gxy*float64(x) + gyy*float64(y)
+			}
+		}
+		det := a11*a22 - a12*a12
+		if det <= 1e-9*(a11+a22)*(a11+a22) {
+			return corner
+		}
+		next := r2.Point{X: (a22*b1 - a12*b2) / det, Y: (a11*b2 - a12*b1) / det}
+		if next.Sub(corner).Norm() > subpixelRadius {
+			return corner
+		}
+		moved := next.Sub(p).Norm()
+		p = next
+		if moved < 0.01 {
+			break
+		}
+	}
+	return p
+}
+
+// gridCell is the column and row of a corner in the checkerboard's grid.
+type gridCell struct {
+	col, row int
+}
+
+// growGrid starts a grid at the seed corner and its two nearest perpendicular neighbors, and grows
+// it one corner at a time to where each next corner is predicted by the corners already found.
+// It returns the points of the grid if it has rows by cols corners, in either orientation.
+func growGrid(points []r2.Point, seed, rows, cols int) (map[gridCell]r2.Point, error) {
+	nearest := make([]int, 0, len(points)-1)
+	for i := range points {
+		if i != seed {
+			nearest = append(nearest, i)
+		}
+	}
+	seedPt := points[seed]
+	sort.Slice(nearest, func(i, j int) bool {
+		return points[nearest[i]].Sub(seedPt).Norm() < points[nearest[j]].Sub(seedPt).Norm()
+	})
+	first := points[nearest[0]].Sub(seedPt)
+	second := -1
+	for _, n := range nearest[1:] {
+		d := points[n].Sub(seedPt)
+		if d.Norm() > 2*first.Norm() {
+			break
+		}
+		if math.Abs(d.Dot(first))/(d.Norm()*first.Norm()) < 0.5 {
+			second = n
+			break
+		}
+	}
+	if second < 0 {
+		return nil, errors.New("corners do not form a grid")
+	}
+
+	cells := map[gridCell]int{{0, 0}: seed, {1, 0}: nearest[0], {0, 1}: second}
+	used := map[int]bool{seed: true, nearest[0]: true, second: true}
+	queue := []gridCell{{0, 0}, {1, 0}, {0, 1}}
+	maxSide := rows
+	if cols > maxSide {
+		maxSide = cols
+	}
+	directions := []gridCell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, dir := range directions {
+			next := gridCell{cell.col + dir.col, cell.row + dir.row}
+			if _, ok := cells[next]; ok {
+				continue
+			}
+			step, ok := gridStep(points, cells, cell, dir)
+			if !ok {
+				continue
+			}
+			predicted := points[cells[cell]].Add(step)
+			match, bestDist := -1, gridTolerance*step.Norm()
+			for i, p := range points {
+				if used[i] {
+					continue
+				}
+				if d := p.Sub(predicted).Norm(); d < bestDist {
+					match, bestDist = i, d
+				}
+			}
+			if match < 0 {
+				continue
+			}
+			cells[next] = match
+			used[match] = true
+			queue = append(queue, next)
+			if len(cells) > maxSide*maxSide {
+				return nil, errors.New("found more corners than the checkerboard has")
+			}
+		}
+	}
+
+	minCol, maxCol, minRow, maxRow := 0, 0, 0, 0
+	for cell := range cells {
+		minCol, maxCol = minInt(minCol, cell.col), maxInt(maxCol, cell.col)
+		minRow, maxRow = minInt(minRow, cell.row), maxInt(maxRow, cell.row)
+	}
+	width, height := maxCol-minCol+1, maxRow-minRow+1
+	if len(cells) != rows*cols || !((width == cols && height == rows) || (width == rows && height == cols)) {
+		return nil, errors.Errorf("found a grid of %d corners spanning %dx%d, expected %dx%d",
+			len(cells), height, width, rows, cols)
+	}
+	grid := make(map[gridCell]r2.Point, len(cells))
+	for cell, i := range cells {
+		grid[gridCell{cell.col - minCol, cell.row - minRow}] = points[i]
+	}
+	return grid, nil
+}
+
+// gridStep predicts the offset from the cell to its neighbor in the direction from the spacing of
+// the corners already in the grid.
+func gridStep(points []r2.Point, cells map[gridCell]int, cell, dir gridCell) (r2.Point, bool) {
+	at := func(c gridCell) (r2.Point, bool) {
+		i, ok := cells[c]
+		if !ok {
+			return r2.Point{}, false
+		}
+		return points[i], true
+	}
+	here, _ := at(cell)
+	if back, ok := at(gridCell{cell.col - dir.col, cell.row - dir.row}); ok {
+		return here.Sub(back), true
+	}
+	// otherwise use the spacing of a neighboring line of the grid
+	for _, side := range []gridCell{{dir.row, dir.col}, {-dir.row, -dir.col}} {
+		beside := gridCell{cell.col + side.col, cell.row + side.row}
+		from, ok := at(beside)
+		if !ok {
+			continue
+		}
+		if to, ok := at(gridCell{beside.col + dir.col, beside.row + dir.row}); ok {
+			return to.Sub(from), true
+		}
+		if to, ok := at(gridCell{beside.col - dir.col, beside.row - dir.row}); ok {
+			return from.Sub(to), true
+		}
+	}
+	return r2.Point{}, false
+}
+
+// orderGrid lays out the grid in rows of cols corners, starting from the corner nearest the top
+// left of the image.
+func orderGrid(grid map[gridCell]r2.Point, rows, cols int) []r2.Point {
+	at := func(row, col int) r2.Point {
+		return grid[gridCell{col, row}]
+	}
+	if _, ok := grid[gridCell{cols - 1, rows - 1}]; !ok || (rows == cols && isMoreVertical(grid, rows, cols)) {
+		at = func(row, col int) r2.Point {
+			return grid[gridCell{row, col}]
+		}
+	}
+	// start from whichever corner of the grid is nearest the top left
+	var flipRows, flipCols bool
+	best := math.Inf(1)
+	for _, flip := range [][2]bool{{false, false}, {false, true}, {true, false}, {true, true}} {
+		row, col := 0, 0
+		if flip[0] {
+			row = rows - 1
+		}
+		if flip[1] {
+			col = cols - 1
+		}
+		if p := at(row, col); p.X+p.Y < best {
+			best = p.X + p.Y
+			flipRows, flipCols = flip[0], flip[1]
+		}
+	}
+	out := make([]r2.Point, 0, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			row, col := r, c
+			if flipRows {
+				row = rows - 1 - r
+			}
+			if flipCols {
+				col = cols - 1 - c
+			}
+			out = append(out, at(row, col))
+		}
+	}
+	return out
+}
+
+// isMoreVertical returns whether the grid's columns run more horizontally than its rows do.
+func isMoreVertical(grid map[gridCell]r2.Point, rows, cols int) bool {
+	origin := grid[gridCell{0, 0}]
+	alongRow := grid[gridCell{cols - 1, 0}].Sub(origin)
+	alongCol := grid[gridCell{0, rows - 1}].Sub(origin)
+	return math.Abs(alongRow.X) < math.Abs(alongCol.X)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,175 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+const (
+	boardFocal       = 600.
+	boardSquare      = 25.
+	boardSupersample = 4
+)
+
+// boardPose places a checkerboard in front of a 640x480 camera, rotated about x, y and then z by
+// the angles in radians, with its center at the given point.
+type boardPose struct {
+	angles r3.Vector
+	center r3.Vector
+}
+
+func (p boardPose) rotation() [3][3]float64 {
+	sx, cx := math.Sincos(p.angles.X)
+	sy, cy := math.Sincos(p.angles.Y)
+	sz, cz := math.Sincos(p.angles.Z)
+	return [3][3]float64{
+		{cz * cy, cz*sy*sx - sz*cx, cz*sy*cx + sz*sx},
+		{sz * cy, sz*sy*sx + cz*cx, sz*sy*cx - cz*sx},
+		{-sy, cy * sx, cy * cx},
+	}
+}
+
+// toCamera returns where the point of the board, with the first inner corner at its origin, is in
+// the camera frame.
+func (p boardPose) toCamera(rows, cols int, pt r2.Point) r3.Vector {
+	r := p.rotation()
+	x := pt.X - float64(cols-1)*boardSquare/2
+	y := pt.Y - float64(rows-1)*boardSquare/2
+	return r3.Vector{
+		X: r[0][0]*x + r[0][1]*y + p.center.X,
+		Y: r[1][0]*x + r[1][1]*y + p.center.Y,
+		Z: r[2][0]*x + r[2][1]*y + p.center.Z,
+	}
+}
+
+func project(v r3.Vector) r2.Point {
+	return r2.Point{X: boardFocal*v.X/v.Z + 320, Y: boardFocal*v.Y/v.Z + 240}
+}
+
+// renderBoard draws a checkerboard with rows by cols inner corners and a white border one square
+// wide on a gray background, as the camera sees it, and returns the pixels of its inner corners.
+func renderBoard(rows, cols int, pose boardPose) (image.Image, []r2.Point) {
+	r := pose.rotation()
+	normal := r3.Vector{X: r[0][2], Y: r[1][2], Z: r[2][2]}
+	origin := pose.toCamera(rows, cols, r2.Point{})
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			var sum float64
+			for sy := 0; sy < boardSupersample; sy++ {
+				for sx := 0; sx < boardSupersample; sx++ {
+					// pixel centers are at whole coordinates
+					u := float64(x) - 0.5 + (float64(sx)+0.5)/boardSupersample
+					v := float64(y) - 0.5 + (float64(sy)+0.5)/boardSupersample
+					ray := r3.Vector{X: (u - 320) / boardFocal, Y: (v - 240) / boardFocal, Z: 1}
+					hit := ray.Mul(origin.Dot(normal) / ray.Dot(normal)).Sub(origin)
+					bx := hit.X*r[0][0] + hit.Y*r[1][0] + hit.Z*r[2][0]
+					by := hit.X*r[0][1] + hit.Y*r[1][1] + hit.Z*r[2][1]
+					sum += boardShade(rows, cols, bx, by)
+				}
+			}
+			img.SetGray(x, y, color.Gray{uint8(sum / (boardSupersample * boardSupersample))})
+		}
+	}
+	corners := make([]r2.Point, 0, rows*cols)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			pt := r2.Point{X: float64(col) * boardSquare, Y: float64(row) * boardSquare}
+			corners = append(corners, project(pose.toCamera(rows, cols, pt)))
+		}
+	}
+	return img, corners
+}
+
+func boardShade(rows, cols int, bx, by float64) float64 {
+	col, row := math.Floor(bx/boardSquare), math.Floor(by/boardSquare)
+	switch {
+	case col < -2 || row < -2 || col > float64(cols) || row > float64(rows):
+		return 120
+	case col < -1 || row < -1 || col > float64(cols-1) || row > float64(rows-1):
+		return 235
+	case int(col+row)%2 == 0:
+		return 20
+	default:
+		return 235
+	}
+}
+
+func TestFindCheckerboardCorners(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		rows, cols int
+		pose       boardPose
+		// upright boards have their rows running across the image
+		upright bool
+	}{
+		{"straight on", 6, 9, boardPose{center: r3.Vector{Z: 500}}, true},
+		{"tilted", 6, 9, boardPose{angles: r3.Vector{X: 0.4, Y: -0.3, Z: 0.1}, center: r3.Vector{X: 10, Y: -5, Z: 450}}, true},
+		{"upside down", 6, 9, boardPose{angles: r3.Vector{Y: 0.3, Z: math.Pi - 0.15}, center: r3.Vector{Z: 500}}, true},
+		{"sideways", 5, 7, boardPose{angles: r3.Vector{X: -0.3, Z: math.Pi / 2}, center: r3.Vector{Y: 10, Z: 420}}, false},
+		{"square", 6, 6, boardPose{angles: r3.Vector{X: 0.2, Y: 0.35, Z: 1.2}, center: r3.Vector{Z: 450}}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img, expected := renderBoard(tc.rows, tc.cols, tc.pose)
+			corners, err := FindCheckerboardCorners(img, tc.rows, tc.cols)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, corners, test.ShouldHaveLength, tc.rows*tc.cols)
+
+			// the corners start at the top left of the image, and upright boards run along rows to the right
+			for _, i := range []int{tc.cols - 1, len(corners) - tc.cols, len(corners) - 1} {
+				test.That(t, corners[i].X+corners[i].Y, test.ShouldBeGreaterThan, corners[0].X+corners[0].Y)
+			}
+			if tc.upright {
+				test.That(t, corners[tc.cols-1].X, test.ShouldBeGreaterThan, corners[0].X)
+			}
+
+			// and match the rendered corners in some orientation of the board
+			var maxErr float64
+			for i, c := range corners {
+				maxErr = math.Max(maxErr, c.Sub(nearestPoint(expected, c)).Norm())
+				if i > 0 && i%tc.cols != 0 {
+					spacing := c.Sub(corners[i-1]).Norm()
+					test.That(t, spacing, test.ShouldBeBetween, 15, 60)
+				}
+			}
+			test.That(t, maxErr, test.ShouldBeLessThan, 0.2)
+		})
+	}
+
+	t.Run("wrong size", func(t *testing.T) {
+		img, _ := renderBoard(6, 9, boardPose{center: r3.Vector{Z: 500}})
+		_, err := FindCheckerboardCorners(img, 5, 9)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "could not find a 5x9 checkerboard")
+
+		_, err = FindCheckerboardCorners(img, 1, 9)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("no board", func(t *testing.T) {
+		img := image.NewGray(image.Rect(0, 0, 640, 480))
+		for y := 0; y < 480; y++ {
+			for x := 0; x < 640; x++ {
+				img.SetGray(x, y, color.Gray{uint8(x / 3)})
+			}
+		}
+		_, err := FindCheckerboardCorners(img, 6, 9)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func nearestPoint(pts []r2.Point, p r2.Point) r2.Point {
+	best := pts[0]
+	for _, q := range pts[1:] {
+		if q.Sub(p).Norm() < best.Sub(p).Norm() {
+			best = q
+		}
+	}
+	return best
+}
@@ -23,12 +23,16 @@ func Overlay(img image.Image, dets []Detection) (image.Image, error) {
 	return gimg.Image(), nil
 }
 
-// drawDetection overlays text of the image label and score in the upper left hand of the bounding box.
+// drawDetection overlays text of the image label, track ID if it is tracked, and score in the upper
+// left hand of the bounding box.
 func drawDetection(img *gg.Context, d Detection) {
 	red := &color.NRGBA{255, 0, 0, 255}
 	box := d.BoundingBox()
 	rimage.DrawRectangleEmpty(img, *box, red, 2.0)
 	text := fmt.Sprintf("%s: %.2f", d.Label(), d.Score())
+	if tracked, ok := d.(TrackedDetection); ok {
+		text = fmt.Sprintf("%s #%d: %.2f", d.Label(), tracked.TrackID(), d.Score())
+	}
 	rimage.DrawString(img, text, image.Point{box.Min.X, box.Min.Y}, red, 30)
 }
 
@@ -0,0 +1,317 @@
+package objectdetection
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+)
+
+// Tracker defaults.
+const (
+	DefaultTrackerIOUThreshold    = 0.3
+	DefaultTrackerMaxMissedFrames = 5
+	DefaultTrackerMinHits         = 1
+)
+
+// Kalman filter noise of a track's center, in pixels.
+const (
+	trackerAccelerationStdDev = 200 // per second squared
+	trackerMeasurementStdDev  = 2
+	trackerInitialSpeedStdDev = 100 // per second
+)
+
+// TrackedDetection is a detection of an object that is followed across images.
+type TrackedDetection interface {
+	Detection
+	// TrackID identifies the object for as long as it is tracked.
+	TrackID() int
+	// Velocity is how fast the center of the object's bounding box moves, in pixels per second.
+	Velocity() r2.Point
+	// Age is how long the object has been tracked.
+	Age() time.Duration
+}
+
+// TrackerConfig is the attribute struct for trackers.
+type TrackerConfig struct {
+	// DetectorName is the name of the detector whose detections are tracked.
+	DetectorName string `json:"detector_name"`
+	// IOUThreshold is the least intersection over union of a detection's bounding box with a
+	// track's predicted one for the detection to continue the track.
+	IOUThreshold float64 `json:"iou_threshold,omitempty"`
+	// MaxMissedFrames is how many images in a row a track may go undetected before it is dropped.
+	MaxMissedFrames int `json:"max_missed_frames,omitempty"`
+	// MinHits is how many times an object must be detected before it is reported.
+	MinHits int `json:"min_hits,omitempty"`
+}
+
+// Tracker follows detections across images in a stream, SORT style: each track predicts where
+// its object is with a constant velocity Kalman filter, and detections are greedily matched to the
+// tracks whose predictions they overlap most with the same label. Unmatched detections start new
+// tracks. A tracker should follow a single stream of images.
+type Tracker struct {
+	iouThreshold    float64
+	maxMissedFrames int
+	minHits         int
+
+	mu     sync.Mutex
+	tracks []*track
+	nextID int
+	last   time.Time
+}
+
+// NewTracker returns a tracker with the given config, using defaults for unset fields.
+func NewTracker(cfg *TrackerConfig) (*Tracker, error) {
+	t := &Tracker{
+		iouThreshold:    DefaultTrackerIOUThreshold,
+		maxMissedFrames: DefaultTrackerMaxMissedFrames,
+		minHits:         DefaultTrackerMinHits,
+		nextID:          1,
+	}
+	if cfg.IOUThreshold < 0 || cfg.IOUThreshold > 1 {
+		return nil, errors.Errorf("iou_threshold must be between 0 and 1, got %v", cfg.IOUThreshold)
+	}
+	if cfg.MaxMissedFrames < 0 {
+		return nil, errors.New("max_missed_frames can't be negative")
+	}
+	if cfg.MinHits < 0 {
+		return nil, errors.New("min_hits can't be negative")
+	}
+	if cfg.IOUThreshold != 0 {
+		t.iouThreshold = cfg.IOUThreshold
+	}
+	if cfg.MaxMissedFrames != 0 {
+		t.maxMissedFrames = cfg.MaxMissedFrames
+	}
+	if cfg.MinHits != 0 {
+		t.minHits = cfg.MinHits
+	}
+	return t, nil
+}
+
+// NewTrackingDetector returns a detector whose detections are tracked by the tracker, as
+// TrackedDetections.
+func NewTrackingDetector(det Detector, tracker *Tracker) Detector {
+	return func(ctx context.Context, img image.Image) ([]Detection, error) {
+		dets, err := det(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		tracked := tracker.Update(dets, time.Now())
+		out := make([]Detection, 0, len(tracked))
+		for _, d := range tracked {
+			out = append(out, d)
+		}
+		return out, nil
+	}
+}
+
+// Update matches the detections of an image taken at the given time to the tracks, and returns
+// the detections of tracks detected at least the minimum number of times.
+func (t *Tracker) Update(dets []Detection, now time.Time) []TrackedDetection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var dt float64
+	if !t.last.IsZero() {
+		dt = now.Sub(t.last).Seconds()
+	}
+	t.last = now
+	for _, tr := range t.tracks {
+		tr.predict(dt)
+	}
+
+	type pair struct {
+		track, det int
+		iou        float64
+	}
+	var pairs []pair
+	for i, tr := range t.tracks {
+		predicted := tr.box()
+		for j, d := range dets {
+			if d.Label() != tr.label {
+				continue
+			}
+			if iou := IOU(predicted, *d.BoundingBox()); iou >= t.iouThreshold {
+				pairs = append(pairs, pair{i, j, iou})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].iou > pairs[j].iou })
+
+	matchedTracks := make([]bool, len(t.tracks))
+	matchedDets := make([]*track, len(dets))
+	for _, p := range pairs {
+		if matchedTracks[p.track] || matchedDets[p.det] != nil {
+			continue
+		}
+		matchedTracks[p.track] = true
+		matchedDets[p.det] = t.tracks[p.track]
+		t.tracks[p.track].correct(*dets[p.det].BoundingBox())
+	}
+
+	tracks := t.tracks[:0]
+	for i, tr := range t.tracks {
+		if !matchedTracks[i] {
+			tr.missed++
+			if tr.missed > t.maxMissedFrames {
+				continue
+			}
+		}
+		tracks = append(tracks, tr)
+	}
+	t.tracks = tracks
+
+	out := make([]TrackedDetection, 0, len(dets))
+	for i, d := range dets {
+		tr := matchedDets[i]
+		if tr == nil {
+			tr = newTrack(t.nextID, d, now)
+			t.nextID++
+			t.tracks = append(t.tracks, tr)
+		}
+		if tr.hits >= t.minHits {
+			out = append(out, &trackedDetection{
+				Detection: d,
+				id:        tr.id,
+				velocity:  r2.Point{X: tr.x.velocity, Y: tr.y.velocity},
+				age:       now.Sub(tr.start),
+			})
+		}
+	}
+	return out
+}
+
+// IOU returns the intersection over union of the two rectangles.
+func IOU(a, b image.Rectangle) float64 {
+	intersection := a.Intersect(b)
+	if intersection.Empty() {
+		return 0
+	}
+	area := func(r image.Rectangle) float64 {
+		return float64(r.Dx() * r.Dy())
+	}
+	return area(intersection) / (area(a) + area(b) - area(intersection))
+}
+
+// track is an object being followed.
+type track struct {
+	id            int
+	label         string
+	x, y          kalman1D
+	width, height float64
+	hits, missed  int
+	start         time.Time
+}
+
+func newTrack(id int, d Detection, now time.Time) *track {
+	box := d.BoundingBox()
+	center := rectCenter(*box)
+	return &track{
+		id:     id,
+		label:  d.Label(),
+		x:      newKalman1D(center.X),
+		y:      newKalman1D(center.Y),
+		width:  float64(box.Dx()),
+		height: float64(box.Dy()),
+		hits:   1,
+		start:  now,
+	}
+}
+
+func (tr *track) predict(dt float64) {
+	tr.x.predict(dt)
+	tr.y.predict(dt)
+}
+
+func (tr *track) correct(box image.Rectangle) {
+	center := rectCenter(box)
+	tr.x.correct(center.X)
+	tr.y.correct(center.Y)
+	tr.width, tr.height = float64(box.Dx()), float64(box.Dy())
+	tr.hits++
+	tr.missed = 0
+}
+
+// box returns where the track predicts its object's bounding box is.
+func (tr *track) box() image.Rectangle {
+	return image.Rect(
+		int(tr.x.position-tr.width/2), int(tr.y.position-tr.height/2),
+		int(tr.x.position+tr.width/2), int(tr.y.position+tr.height/2),
+	)
+}
+
+func rectCenter(r image.Rectangle) r2.Point {
+	return r2.Point{X: float64(r.Min.X+r.Max.X) / 2, Y: float64(r.Min.Y+r.Max.Y) / 2}
+}
+
+// kalman1D is a constant velocity Kalman filter of a position.
+type kalman1D struct {
+	position, velocity float64
+	// covariance of the position and velocity
+	pp, pv, vv float64
+}
+
+func newKalman1D(position float64) kalman1D {
+	return kalman1D{
+		position: position,
+		pp:       trackerMeasurementStdDev * trackerMeasurementStdDev,
+		vv:       trackerInitialSpeedStdDev * trackerInitialSpeedStdDev,
+	}
+}
+
+func (k *kalman1D) predict(dt float64) {
+	const q = trackerAccelerationStdDev * trackerAccelerationStdDev
+	k.position += k.velocity * dt
+	k.pp += 2*dt*k.pv + dt*dt*k.vv + q*dt*dt*dt*dt/4
+	k.pv += dt*k.vv + q*dt*dt*dt/2
+	k.vv += q * dt * dt
+}
+
+func (k *kalman1D) correct(position float64) {
+	const r = trackerMeasurementStdDev * trackerMeasurementStdDev
+	innovation := position - k.position
+	s := k.pp + r
+	gainP, gainV := k.pp/s, k.pv/s
+	k.position += gainP * innovation
+	k.velocity += gainV * innovation
+	k.vv -= gainV * k.pv
+	k.pv -= gainV * k.pp
+	k.pp -= gainP * k.pp
+}
+
+// NewTrackedDetection returns the detection as belonging to the given track, as it is when the
+// detection is received from a tracker on another robot.
+func NewTrackedDetection(d Detection, trackID int, velocity r2.Point, age time.Duration) TrackedDetection {
+	return &trackedDetection{Detection: d, id: trackID, velocity: velocity, age: age}
+}
+
+// trackedDetection is a detection with the track it belongs to.
+type trackedDetection struct {
+	Detection
+	id       int
+	velocity r2.Point
+	age      time.Duration
+}
+
+func (d *trackedDetection) TrackID() int {
+	return d.id
+}
+
+func (d *trackedDetection) Velocity() r2.Point {
+	return d.velocity
+}
+
+func (d *trackedDetection) Age() time.Duration {
+	return d.age
+}
+
+// String turns the detection into a string.
+func (d *trackedDetection) String() string {
+	return fmt.Sprintf("Track: %d, %v", d.id, d.Detection)
+}
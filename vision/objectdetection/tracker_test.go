@@ -0,0 +1,113 @@
+package objectdetection
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+func TestIOU(t *testing.T) {
+	test.That(t, IOU(image.Rect(0, 0, 10, 10), image.Rect(0, 0, 10, 10)), test.ShouldEqual, 1)
+	test.That(t, IOU(image.Rect(0, 0, 10, 10), image.Rect(5, 0, 15, 10)), test.ShouldAlmostEqual, 1./3)
+	test.That(t, IOU(image.Rect(0, 0, 10, 10), image.Rect(20, 20, 30, 30)), test.ShouldEqual, 0)
+}
+
+func TestTracker(t *testing.T) {
+	tracker, err := NewTracker(&TrackerConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	start := time.Now()
+	frame := func(i int) time.Time {
+		return start.Add(time.Duration(i) * 100 * time.Millisecond)
+	}
+
+	// a person walking right at 100 pixels per second, and a dog standing still
+	var personID, dogID int
+	for i := 0; i < 10; i++ {
+		x := 10 * i
+		tracked := tracker.Update([]Detection{
+			NewDetection(image.Rect(x, 0, x+40, 80), 0.9, "person"),
+			NewDetection(image.Rect(200, 100, 260, 140), 0.8, "dog"),
+		}, frame(i))
+		test.That(t, tracked, test.ShouldHaveLength, 2)
+		test.That(t, tracked[0].Label(), test.ShouldEqual, "person")
+		test.That(t, tracked[0].Score(), test.ShouldEqual, 0.9)
+		if i == 0 {
+			personID, dogID = tracked[0].TrackID(), tracked[1].TrackID()
+			test.That(t, personID, test.ShouldNotEqual, dogID)
+		}
+		test.That(t, tracked[0].TrackID(), test.ShouldEqual, personID)
+		test.That(t, tracked[1].TrackID(), test.ShouldEqual, dogID)
+		test.That(t, tracked[0].Age(), test.ShouldEqual, frame(i).Sub(start))
+	}
+	tracked := tracker.Update([]Detection{NewDetection(image.Rect(100, 0, 140, 80), 0.9, "person")}, frame(10))
+	test.That(t, tracked, test.ShouldHaveLength, 1)
+	test.That(t, tracked[0].Velocity().X, test.ShouldAlmostEqual, 100, 5)
+	test.That(t, tracked[0].Velocity().Y, test.ShouldAlmostEqual, 0, 5)
+
+	// the person is predicted through missed frames, up to a point
+	for i := 11; i < 11+DefaultTrackerMaxMissedFrames; i++ {
+		test.That(t, tracker.Update(nil, frame(i)), test.ShouldBeEmpty)
+	}
+	x := 10 * (11 + DefaultTrackerMaxMissedFrames)
+	tracked = tracker.Update([]Detection{NewDetection(image.Rect(x, 0, x+40, 80), 0.9, "person")}, frame(16))
+	test.That(t, tracked[0].TrackID(), test.ShouldEqual, personID)
+
+	// an object with a different label doesn't continue a track
+	tracked = tracker.Update([]Detection{NewDetection(image.Rect(x+10, 0, x+50, 80), 0.9, "cat")}, frame(17))
+	test.That(t, tracked[0].TrackID(), test.ShouldBeGreaterThan, dogID)
+	test.That(t, tracked[0].TrackID(), test.ShouldNotEqual, personID)
+	test.That(t, tracked[0].Velocity().X, test.ShouldEqual, 0)
+}
+
+func TestTrackerMinHits(t *testing.T) {
+	tracker, err := NewTracker(&TrackerConfig{MinHits: 3, MaxMissedFrames: 1})
+	test.That(t, err, test.ShouldBeNil)
+	now := time.Now()
+	det := NewDetection(image.Rect(0, 0, 10, 10), 1, "a")
+	test.That(t, tracker.Update([]Detection{det}, now), test.ShouldBeEmpty)
+	test.That(t, tracker.Update([]Detection{det}, now), test.ShouldBeEmpty)
+	test.That(t, tracker.Update([]Detection{det}, now), test.ShouldHaveLength, 1)
+
+	// dropped after missing two frames, so starts over
+	test.That(t, tracker.Update(nil, now), test.ShouldBeEmpty)
+	test.That(t, tracker.Update(nil, now), test.ShouldBeEmpty)
+	test.That(t, tracker.Update([]Detection{det}, now), test.ShouldBeEmpty)
+
+	_, err = NewTracker(&TrackerConfig{IOUThreshold: 2})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewTracker(&TrackerConfig{MinHits: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestTrackingDetector(t *testing.T) {
+	x := 0
+	det := func(ctx context.Context, img image.Image) ([]Detection, error) {
+		x += 5
+		return []Detection{NewDetection(image.Rect(x, 10, x+20, 30), 1, "a")}, nil
+	}
+	tracker, err := NewTracker(&TrackerConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	tracking := NewTrackingDetector(det, tracker)
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	var id int
+	for i := 0; i < 3; i++ {
+		dets, err := tracking(context.Background(), img)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, dets, test.ShouldHaveLength, 1)
+		tracked, ok := dets[0].(TrackedDetection)
+		test.That(t, ok, test.ShouldBeTrue)
+		if i == 0 {
+			id = tracked.TrackID()
+		}
+		test.That(t, tracked.TrackID(), test.ShouldEqual, id)
+	}
+
+	dets, err := tracking(context.Background(), img)
+	test.That(t, err, test.ShouldBeNil)
+	overlaid, err := Overlay(img, dets)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, overlaid.Bounds(), test.ShouldResemble, img.Bounds())
+}